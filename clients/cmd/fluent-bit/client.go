@@ -11,5 +11,14 @@ func NewClient(cfg *config, logger log.Logger, metrics *client.Metrics) (client.
 	if cfg.bufferConfig.buffer {
 		return NewBuffer(cfg, logger, metrics)
 	}
+	return newUnbufferedClient(cfg, logger, metrics)
+}
+
+// newUnbufferedClient creates the client pushing to Loki with the configured
+// protocol.
+func newUnbufferedClient(cfg *config, logger log.Logger, metrics *client.Metrics) (client.Client, error) {
+	if cfg.protocol == otlpProtocol {
+		return newOTLPClient(metrics, cfg.clientConfig, logger)
+	}
 	return client.New(metrics, cfg.clientConfig, 0, 0, false, logger)
 }
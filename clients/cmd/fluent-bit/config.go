@@ -36,11 +36,24 @@ const (
 	kvPairFormat
 )
 
+type protocol int
+
+const (
+	lokiProtocol protocol = iota
+	otlpProtocol
+)
+
 const (
 	falseStr = "false"
 	trueStr  = "true"
 )
 
+const (
+	defaultLokiURL     = "http://localhost:3100/loki/api/v1/push"
+	defaultOTLPURL     = "http://localhost:3100/otlp/v1/logs"
+	defaultOTLPBodyKey = "log"
+)
+
 type config struct {
 	clientConfig         client.Config
 	bufferConfig         bufferConfig
@@ -51,6 +64,8 @@ type config struct {
 	lineFormat           format
 	dropSingleKey        bool
 	labelMap             map[string]interface{}
+	protocol             protocol
+	otlpBodyKey          string
 }
 
 func parseConfig(cfg ConfigGetter) (*config, error) {
@@ -59,10 +74,27 @@ func parseConfig(cfg ConfigGetter) (*config, error) {
 	res.clientConfig = defaultClientCfg
 	res.bufferConfig = defaultBufferConfig
 
+	switch p := cfg.Get("Protocol"); p {
+	case "loki", "":
+		res.protocol = lokiProtocol
+	case "otlp":
+		res.protocol = otlpProtocol
+	default:
+		return nil, fmt.Errorf("invalid protocol: %s", p)
+	}
+
+	res.otlpBodyKey = cfg.Get("OTLPBodyKey")
+	if res.otlpBodyKey == "" {
+		res.otlpBodyKey = defaultOTLPBodyKey
+	}
+
 	url := cfg.Get("URL")
 	var clientURL flagext.URLValue
 	if url == "" {
-		url = "http://localhost:3100/loki/api/v1/push"
+		url = defaultLokiURL
+		if res.protocol == otlpProtocol {
+			url = defaultOTLPURL
+		}
 	}
 	err := clientURL.Set(url)
 	if err != nil {
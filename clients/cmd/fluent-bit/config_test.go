@@ -130,6 +130,25 @@ func Test_parseConfig(t *testing.T) {
 				},
 			},
 			false},
+		{"otlp",
+			map[string]string{
+				"Protocol": "otlp",
+			},
+			&config{
+				lineFormat: jsonFormat,
+				clientConfig: client.Config{
+					URL:            mustParseURL("http://localhost:3100/otlp/v1/logs"),
+					BatchSize:      defaultClientCfg.BatchSize,
+					BatchWait:      defaultClientCfg.BatchWait,
+					Timeout:        defaultClientCfg.Timeout,
+					ExternalLabels: lokiflag.LabelSet{LabelSet: model.LabelSet{"job": "fluent-bit"}},
+					BackoffConfig:  defaultClientCfg.BackoffConfig,
+				},
+				logLevel:      mustParseLogLevel("info"),
+				dropSingleKey: true,
+				protocol:      otlpProtocol,
+			},
+			false},
 		{"bad url", map[string]string{"URL": "::doh.com"}, nil, true},
 		{"bad BatchWait", map[string]string{"BatchWait": "30sa"}, nil, true},
 		{"bad BatchSize", map[string]string{"BatchSize": "a"}, nil, true},
@@ -142,6 +161,7 @@ func Test_parseConfig(t *testing.T) {
 		{"bad MaxBackoff", map[string]string{"MaxBackoff": "5ma"}, nil, true},
 		{"bad MaxRetries", map[string]string{"MaxRetries": "a"}, nil, true},
 		{"bad labelmap file", map[string]string{"LabelMapPath": "a"}, nil, true},
+		{"bad protocol", map[string]string{"Protocol": "a"}, nil, true},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -200,6 +220,9 @@ func assertConfig(t *testing.T, expected, actual *config) {
 	if !reflect.DeepEqual(expected.labelMap, actual.labelMap) {
 		t.Errorf("incorrect labelMap want:%v got:%v", expected.labelMap, actual.labelMap)
 	}
+	if expected.protocol != actual.protocol {
+		t.Errorf("incorrect protocol want:%v got:%v", expected.protocol, actual.protocol)
+	}
 }
 
 func mustParseURL(u string) flagext.URLValue {
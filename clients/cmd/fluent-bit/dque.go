@@ -15,6 +15,8 @@ import (
 	"github.com/grafana/loki/v3/clients/pkg/promtail/api"
 	"github.com/grafana/loki/v3/clients/pkg/promtail/client"
 
+	"github.com/grafana/loki/pkg/push"
+
 	"github.com/grafana/loki/v3/pkg/logproto"
 )
 
@@ -33,9 +35,10 @@ var defaultDqueConfig = dqueConfig{
 }
 
 type dqueEntry struct {
-	Lbs  model.LabelSet
-	Ts   time.Time
-	Line string
+	Lbs                model.LabelSet
+	Ts                 time.Time
+	Line               string
+	StructuredMetadata push.LabelsAdapter
 }
 
 func dqueEntryBuilder() interface{} {
@@ -73,7 +76,7 @@ func newDque(cfg *config, logger log.Logger, metrics *client.Metrics) (client.Cl
 		_ = q.queue.TurboOn()
 	}
 
-	q.loki, err = client.New(metrics, cfg.clientConfig, 0, 0, false, logger)
+	q.loki, err = newUnbufferedClient(cfg, logger, metrics)
 	if err != nil {
 		return nil, err
 	}
@@ -111,8 +114,9 @@ func (c *dqueClient) dequeuer() {
 		c.loki.Chan() <- api.Entry{
 			Labels: record.Lbs,
 			Entry: logproto.Entry{
-				Timestamp: record.Ts,
-				Line:      record.Line,
+				Timestamp:          record.Ts,
+				Line:               record.Line,
+				StructuredMetadata: record.StructuredMetadata,
 			},
 		}
 	}
@@ -146,7 +150,7 @@ func (c *dqueClient) StopNow() {
 func (c *dqueClient) enqueuer() {
 	defer c.wg.Done()
 	for e := range c.entries {
-		if err := c.queue.Enqueue(&dqueEntry{e.Labels, e.Timestamp, e.Line}); err != nil {
+		if err := c.queue.Enqueue(&dqueEntry{e.Labels, e.Timestamp, e.Line, e.StructuredMetadata}); err != nil {
 			level.Warn(c.logger).Log("msg", fmt.Sprintf("cannot enqueue record %s:", e.Line), "err", err)
 		}
 	}
@@ -50,6 +50,9 @@ func newPlugin(cfg *config, logger log.Logger, metrics *client.Metrics) (*loki,
 func (l *loki) sendRecord(r map[interface{}]interface{}, ts time.Time) error {
 	records := toStringMap(r)
 	level.Debug(l.logger).Log("msg", "processing records", "records", fmt.Sprintf("%+v", records))
+	if l.cfg.protocol == otlpProtocol {
+		return l.sendRecordOTLP(records, ts)
+	}
 	lbs := model.LabelSet{}
 	if l.cfg.autoKubernetesLabels {
 		err := autoLabels(records, lbs)
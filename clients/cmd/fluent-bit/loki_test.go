@@ -14,6 +14,8 @@ import (
 	"github.com/grafana/loki/v3/clients/pkg/promtail/api"
 	"github.com/grafana/loki/v3/clients/pkg/promtail/client/fake"
 
+	"github.com/grafana/loki/pkg/push"
+
 	"github.com/grafana/loki/v3/pkg/logproto"
 )
 
@@ -368,3 +370,70 @@ func Test_AutoKubernetesLabels(t *testing.T) {
 		})
 	}
 }
+
+func Test_loki_sendRecordOTLP(t *testing.T) {
+	record := map[interface{}]interface{}{
+		"log":    "level=info msg=hello",
+		"stream": "stdout",
+		"nested": map[interface{}]interface{}{"foo": "bar"},
+		"drop":   "me",
+		"kubernetes": map[interface{}]interface{}{
+			"namespace_name": "default",
+			"pod_name":       "app-1",
+			"labels":         map[interface{}]interface{}{"app": "app"},
+		},
+	}
+
+	tests := []struct {
+		name string
+		cfg  *config
+		want []api.Entry
+	}{
+		{
+			"body key and attributes",
+			&config{protocol: otlpProtocol, otlpBodyKey: "log", autoKubernetesLabels: true, removeKeys: []string{"drop"}},
+			[]api.Entry{{
+				Labels: model.LabelSet{"k8s.namespace.name": "default", "k8s.pod.name": "app-1"},
+				Entry: logproto.Entry{
+					Timestamp: now,
+					Line:      "level=info msg=hello",
+					StructuredMetadata: push.LabelsAdapter{
+						{Name: "nested", Value: `{"foo":"bar"}`},
+						{Name: "stream", Value: "stdout"},
+					},
+				},
+			}},
+		},
+		{
+			"missing body key",
+			&config{protocol: otlpProtocol, otlpBodyKey: "message", lineFormat: kvPairFormat, removeKeys: []string{"kubernetes", "nested", "drop"}},
+			[]api.Entry{{
+				Labels: model.LabelSet{},
+				Entry:  logproto.Entry{Timestamp: now, Line: `log="level=info msg=hello" stream=stdout`},
+			}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := fake.New(func() {})
+			l := &loki{
+				cfg:    tt.cfg,
+				client: rec,
+				logger: logger,
+			}
+			// sendRecord mutates the record.
+			r := make(map[interface{}]interface{}, len(record))
+			for k, v := range record {
+				r[k] = v
+			}
+			if err := l.sendRecord(r, now); err != nil {
+				t.Fatalf("sendRecord() error = %v", err)
+			}
+			rec.Stop()
+			got := rec.Received()
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("sendRecord() want:%v got:%v", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,387 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/grafana/dskit/backoff"
+	jsoniter "github.com/json-iterator/go"
+	promconfig "github.com/prometheus/common/config"
+	"github.com/prometheus/common/model"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/plog/plogotlp"
+
+	"github.com/grafana/loki/v3/clients/pkg/promtail/api"
+	"github.com/grafana/loki/v3/clients/pkg/promtail/client"
+
+	"github.com/grafana/loki/pkg/push"
+
+	"github.com/grafana/loki/v3/pkg/logproto"
+	lokiutil "github.com/grafana/loki/v3/pkg/util"
+)
+
+const (
+	otlpContentType  = "application/x-protobuf"
+	otlpMaxErrMsgLen = 1024
+)
+
+// kubernetesResourceAttributes maps the metadata added by the fluent-bit
+// kubernetes filter to OpenTelemetry semantic conventions resource attributes,
+// so that Loki's OTLP resource attributes config decides which become labels.
+var kubernetesResourceAttributes = map[string]string{
+	"namespace_name": "k8s.namespace.name",
+	"pod_name":       "k8s.pod.name",
+	"pod_id":         "k8s.pod.uid",
+	"container_name": "k8s.container.name",
+	"host":           "k8s.node.name",
+}
+
+// sendRecordOTLP sends a fluentbit record to loki as an OTLP log record. The
+// body is taken from the configured body key and the remaining keys are sent
+// as log attributes, which Loki stores as structured metadata.
+func (l *loki) sendRecordOTLP(records map[string]interface{}, ts time.Time) error {
+	resource := model.LabelSet{}
+	if l.cfg.autoKubernetesLabels {
+		if err := kubernetesResource(records, resource); err != nil {
+			level.Error(l.logger).Log("msg", err.Error(), "records", fmt.Sprintf("%+v", records))
+		}
+		delete(records, "kubernetes")
+	}
+	removeKeys(records, l.cfg.removeKeys)
+	if len(records) == 0 {
+		return nil
+	}
+
+	body, ok := records[l.cfg.otlpBodyKey]
+	if !ok {
+		line, err := l.createLine(records, l.cfg.lineFormat)
+		if err != nil {
+			return fmt.Errorf("error creating line: %v", err)
+		}
+		l.client.Chan() <- api.Entry{
+			Labels: resource,
+			Entry:  logproto.Entry{Timestamp: ts, Line: line},
+		}
+		return nil
+	}
+	delete(records, l.cfg.otlpBodyKey)
+
+	line, err := attributeValue(body)
+	if err != nil {
+		return fmt.Errorf("error creating line: %v", err)
+	}
+	attributes, err := recordAttributes(records)
+	if err != nil {
+		return fmt.Errorf("error creating attributes: %v", err)
+	}
+	l.client.Chan() <- api.Entry{
+		Labels: resource,
+		Entry: logproto.Entry{
+			Timestamp:          ts,
+			Line:               line,
+			StructuredMetadata: attributes,
+		},
+	}
+	return nil
+}
+
+func kubernetesResource(records map[string]interface{}, resource model.LabelSet) error {
+	kube, ok := records["kubernetes"].(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("kubernetes metadata not found, no resource attributes will be added")
+	}
+	for k, v := range kube {
+		if name, ok := kubernetesResourceAttributes[k]; ok {
+			resource[model.LabelName(name)] = model.LabelValue(fmt.Sprintf("%v", v))
+		}
+	}
+	return nil
+}
+
+// recordAttributes converts the record keys to log attributes sorted by name.
+func recordAttributes(records map[string]interface{}) (push.LabelsAdapter, error) {
+	attributes := make(push.LabelsAdapter, 0, len(records))
+	for k, v := range records {
+		value, err := attributeValue(v)
+		if err != nil {
+			return nil, err
+		}
+		attributes = append(attributes, push.LabelAdapter{Name: k, Value: value})
+	}
+	sort.Slice(attributes, func(i, j int) bool { return attributes[i].Name < attributes[j].Name })
+	return attributes, nil
+}
+
+// attributeValue returns strings and scalars as is, and nested values as JSON.
+func attributeValue(v interface{}) (string, error) {
+	switch t := v.(type) {
+	case string:
+		return t, nil
+	case map[string]interface{}, []interface{}:
+		js, err := jsoniter.ConfigCompatibleWithStandardLibrary.Marshal(t)
+		if err != nil {
+			return "", err
+		}
+		return string(js), nil
+	default:
+		return fmt.Sprintf("%v", t), nil
+	}
+}
+
+// otlpClient pushes entries to Loki's OTLP endpoint. The labels of an entry
+// are sent as resource attributes and its structured metadata as log
+// attributes.
+type otlpClient struct {
+	name    string
+	metrics *client.Metrics
+	logger  log.Logger
+	cfg     client.Config
+	client  *http.Client
+	entries chan api.Entry
+
+	once sync.Once
+	wg   sync.WaitGroup
+
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+func newOTLPClient(metrics *client.Metrics, cfg client.Config, logger log.Logger) (client.Client, error) {
+	if cfg.URL.URL == nil {
+		return nil, fmt.Errorf("client needs target URL")
+	}
+	if metrics == nil {
+		return nil, fmt.Errorf("metrics must be instantiated")
+	}
+
+	httpClient, err := promconfig.NewClientFromConfig(cfg.Client, "fluent-bit", promconfig.WithHTTP2Disabled())
+	if err != nil {
+		return nil, err
+	}
+	httpClient.Timeout = cfg.Timeout
+
+	ctx, cancel := context.WithCancel(context.Background())
+	c := &otlpClient{
+		name:    client.ConfigName(cfg),
+		metrics: metrics,
+		logger:  log.With(logger, "component", "otlp-client", "host", cfg.URL.Host),
+		cfg:     cfg,
+		client:  httpClient,
+		entries: make(chan api.Entry),
+		ctx:     ctx,
+		cancel:  cancel,
+	}
+	metrics.InitHost(cfg.URL.Host)
+
+	c.wg.Add(1)
+	go c.run()
+	return c, nil
+}
+
+func (c *otlpClient) run() {
+	defer c.wg.Done()
+
+	var batch *otlpBatch
+	maxWaitCheck := time.NewTicker(max(c.cfg.BatchWait/10, 10*time.Millisecond))
+	defer maxWaitCheck.Stop()
+
+	for {
+		select {
+		case e, ok := <-c.entries:
+			if !ok {
+				if batch != nil {
+					c.sendBatch(batch)
+				}
+				return
+			}
+			if len(c.cfg.ExternalLabels.LabelSet) > 0 {
+				e.Labels = c.cfg.ExternalLabels.Merge(e.Labels)
+			}
+			if batch == nil {
+				batch = newOTLPBatch()
+			}
+			batch.add(e)
+			if batch.bytes >= c.cfg.BatchSize {
+				c.sendBatch(batch)
+				batch = nil
+			}
+		case <-maxWaitCheck.C:
+			if batch != nil && time.Since(batch.createdAt) >= c.cfg.BatchWait {
+				c.sendBatch(batch)
+				batch = nil
+			}
+		}
+	}
+}
+
+func (c *otlpClient) sendBatch(batch *otlpBatch) {
+	host := c.cfg.URL.Host
+	buf, err := batch.encode()
+	if err != nil {
+		level.Error(c.logger).Log("msg", "error encoding batch, dropping it", "entries", batch.entries, "error", err)
+		// There's no encoded batch, so the size of its entries is recorded instead.
+		c.metrics.ObserveDropped(host, c.cfg.TenantID, client.ReasonGeneric, batch.bytes, batch.entries)
+		return
+	}
+	c.metrics.ObserveEncoded(host, len(buf))
+
+	backoff := backoff.New(c.ctx, c.cfg.BackoffConfig)
+	var status int
+	for {
+		start := time.Now()
+		status, err = c.send(buf)
+		c.metrics.ObserveRequest(host, status, time.Since(start))
+		if err == nil {
+			c.metrics.ObserveSent(host, len(buf), batch.entries)
+			return
+		}
+
+		// Only retry 429s, 500s and connection-level errors.
+		if status > 0 && status != http.StatusTooManyRequests && status/100 != 5 {
+			break
+		}
+
+		level.Warn(c.logger).Log("msg", "error sending batch, will retry", "status", status, "error", err)
+		c.metrics.ObserveRetry(host, c.cfg.TenantID)
+		backoff.Wait()
+		if !backoff.Ongoing() {
+			break
+		}
+	}
+	level.Error(c.logger).Log("msg", "final error sending batch", "status", status, "entries", batch.entries, "error", err)
+	reason := client.ReasonGeneric
+	if status == http.StatusTooManyRequests {
+		reason = client.ReasonRateLimited
+	}
+	c.metrics.ObserveDropped(host, c.cfg.TenantID, reason, len(buf), batch.entries)
+}
+
+func (c *otlpClient) send(buf []byte) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), c.cfg.Timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.cfg.URL.String(), bytes.NewReader(buf))
+	if err != nil {
+		return -1, err
+	}
+	req.Header.Set("Content-Type", otlpContentType)
+	req.Header.Set("Content-Encoding", "gzip")
+	req.Header.Set("User-Agent", client.UserAgent)
+	if c.cfg.TenantID != "" {
+		req.Header.Set("X-Scope-OrgID", c.cfg.TenantID)
+	}
+	for k, v := range c.cfg.Headers {
+		if req.Header.Get(k) == "" {
+			req.Header.Add(k, v)
+		}
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return -1, err
+	}
+	defer lokiutil.LogError("closing response body", resp.Body.Close)
+
+	if resp.StatusCode/100 != 2 {
+		scanner := bufio.NewScanner(io.LimitReader(resp.Body, otlpMaxErrMsgLen))
+		line := ""
+		if scanner.Scan() {
+			line = scanner.Text()
+		}
+		err = fmt.Errorf("server returned HTTP status %s (%d): %s", resp.Status, resp.StatusCode, line)
+	}
+	return resp.StatusCode, err
+}
+
+// Chan returns the channel entries are sent to.
+func (c *otlpClient) Chan() chan<- api.Entry {
+	return c.entries
+}
+
+// Stop the client, sending the pending batch.
+func (c *otlpClient) Stop() {
+	c.once.Do(func() { close(c.entries) })
+	c.wg.Wait()
+}
+
+// StopNow stops the client without retries.
+func (c *otlpClient) StopNow() {
+	c.cancel()
+	c.Stop()
+}
+
+func (c *otlpClient) Name() string {
+	return c.name
+}
+
+// otlpBatch accumulates log records, grouped by resource.
+type otlpBatch struct {
+	logs      plog.Logs
+	resources map[string]plog.ScopeLogs
+	bytes     int
+	entries   int
+	createdAt time.Time
+}
+
+func newOTLPBatch() *otlpBatch {
+	return &otlpBatch{
+		logs:      plog.NewLogs(),
+		resources: map[string]plog.ScopeLogs{},
+		createdAt: time.Now(),
+	}
+}
+
+func (b *otlpBatch) add(e api.Entry) {
+	key := e.Labels.String()
+	scope, ok := b.resources[key]
+	if !ok {
+		rl := b.logs.ResourceLogs().AppendEmpty()
+		for name, value := range e.Labels {
+			rl.Resource().Attributes().PutStr(string(name), string(value))
+		}
+		scope = rl.ScopeLogs().AppendEmpty()
+		b.resources[key] = scope
+	}
+
+	lr := scope.LogRecords().AppendEmpty()
+	lr.SetTimestamp(pcommon.NewTimestampFromTime(e.Timestamp))
+	lr.SetObservedTimestamp(pcommon.NewTimestampFromTime(time.Now()))
+	lr.Body().SetStr(e.Line)
+	for _, attr := range e.StructuredMetadata {
+		lr.Attributes().PutStr(attr.Name, attr.Value)
+	}
+
+	b.entries++
+	b.bytes += len(e.Line)
+	for _, attr := range e.StructuredMetadata {
+		b.bytes += len(attr.Name) + len(attr.Value)
+	}
+}
+
+// encode the batch as a gzip-compressed OTLP export request.
+func (b *otlpBatch) encode() ([]byte, error) {
+	pb, err := plogotlp.NewExportRequestFromLogs(b.logs).MarshalProto()
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	if _, err := gz.Write(pb); err != nil {
+		return nil, err
+	}
+	if err := gz.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
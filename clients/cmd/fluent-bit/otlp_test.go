@@ -0,0 +1,134 @@
+package main
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/grafana/dskit/backoff"
+	"github.com/grafana/dskit/flagext"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/plog/plogotlp"
+
+	"github.com/grafana/loki/v3/clients/pkg/promtail/api"
+	"github.com/grafana/loki/v3/clients/pkg/promtail/client"
+
+	"github.com/grafana/loki/pkg/push"
+
+	"github.com/grafana/loki/v3/pkg/logproto"
+	lokiflag "github.com/grafana/loki/v3/pkg/util/flagext"
+)
+
+func Test_otlpClient(t *testing.T) {
+	received := make(chan plogotlp.ExportRequest, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		require.Equal(t, "tenant", req.Header.Get("X-Scope-OrgID"))
+		require.Equal(t, otlpContentType, req.Header.Get("Content-Type"))
+
+		gz, err := gzip.NewReader(req.Body)
+		require.NoError(t, err)
+		buf, err := io.ReadAll(gz)
+		require.NoError(t, err)
+
+		exportReq := plogotlp.NewExportRequest()
+		require.NoError(t, exportReq.UnmarshalProto(buf))
+		received <- exportReq
+	}))
+	defer server.Close()
+
+	var serverURL flagext.URLValue
+	require.NoError(t, serverURL.Set(server.URL))
+
+	reg := prometheus.NewRegistry()
+	c, err := newOTLPClient(client.NewMetrics(reg), client.Config{
+		Name:           "otlp",
+		URL:            serverURL,
+		TenantID:       "tenant",
+		BatchWait:      time.Hour,
+		BatchSize:      1 << 20,
+		Timeout:        time.Second,
+		BackoffConfig:  backoff.Config{MinBackoff: time.Millisecond, MaxBackoff: time.Millisecond, MaxRetries: 1},
+		ExternalLabels: lokiflag.LabelSet{LabelSet: model.LabelSet{"service.name": "fluent-bit"}},
+	}, log.NewNopLogger())
+	require.NoError(t, err)
+	require.Equal(t, "otlp", c.Name())
+
+	ts := time.Unix(1, 0).UTC()
+	c.Chan() <- api.Entry{
+		Labels: model.LabelSet{"k8s.pod.name": "app-1"},
+		Entry: logproto.Entry{
+			Timestamp:          ts,
+			Line:               "line 1",
+			StructuredMetadata: push.LabelsAdapter{{Name: "stream", Value: "stdout"}},
+		},
+	}
+	c.Chan() <- api.Entry{
+		Labels: model.LabelSet{"k8s.pod.name": "app-1"},
+		Entry:  logproto.Entry{Timestamp: ts, Line: "line 2"},
+	}
+	// Stopping the client sends the pending batch.
+	c.Stop()
+
+	req := <-received
+	resourceLogs := req.Logs().ResourceLogs()
+	require.Equal(t, 1, resourceLogs.Len())
+	require.Equal(t, map[string]any{"k8s.pod.name": "app-1", "service.name": "fluent-bit"}, resourceLogs.At(0).Resource().Attributes().AsRaw())
+
+	records := resourceLogs.At(0).ScopeLogs().At(0).LogRecords()
+	require.Equal(t, 2, records.Len())
+	require.Equal(t, "line 1", records.At(0).Body().Str())
+	require.Equal(t, ts, records.At(0).Timestamp().AsTime())
+	require.Equal(t, map[string]any{"stream": "stdout"}, records.At(0).Attributes().AsRaw())
+	require.Equal(t, "line 2", records.At(1).Body().Str())
+	require.Equal(t, 0, records.At(1).Attributes().Len())
+
+	require.NoError(t, testutil.GatherAndCompare(reg, strings.NewReader(fmt.Sprintf(`
+# HELP promtail_sent_entries_total Number of log entries sent to the ingester.
+# TYPE promtail_sent_entries_total counter
+promtail_sent_entries_total{host="%s"} 2
+`, serverURL.Host)), "promtail_sent_entries_total"))
+}
+
+func Test_otlpClient_Dropped(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+		rw.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	var serverURL flagext.URLValue
+	require.NoError(t, serverURL.Set(server.URL))
+
+	reg := prometheus.NewRegistry()
+	c, err := newOTLPClient(client.NewMetrics(reg), client.Config{
+		URL:           serverURL,
+		TenantID:      "tenant",
+		BatchWait:     time.Hour,
+		BatchSize:     1 << 20,
+		Timeout:       time.Second,
+		BackoffConfig: backoff.Config{MinBackoff: time.Millisecond, MaxBackoff: time.Millisecond, MaxRetries: 1},
+	}, log.NewNopLogger())
+	require.NoError(t, err)
+	// Unnamed clients are named after their config, like the Loki client.
+	require.NotEmpty(t, c.Name())
+
+	c.Chan() <- api.Entry{
+		Labels: model.LabelSet{"k8s.pod.name": "app-1"},
+		Entry:  logproto.Entry{Timestamp: time.Unix(1, 0), Line: "line 1"},
+	}
+	c.Stop()
+
+	require.NoError(t, testutil.GatherAndCompare(reg, strings.NewReader(fmt.Sprintf(`
+# HELP promtail_dropped_entries_total Number of log entries dropped because failed to be sent to the ingester after all retries.
+# TYPE promtail_dropped_entries_total counter
+promtail_dropped_entries_total{host="%[1]s",reason="ingester_error",tenant="tenant"} 1
+`, serverURL.Host)), "promtail_dropped_entries_total"))
+}
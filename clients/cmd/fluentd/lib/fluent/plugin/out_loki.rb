@@ -90,6 +90,22 @@ module Fluent
       desc 'whether or not to include the fluentd_thread label when multiple threads are used for flushing'
       config_param :include_thread_label, :bool, default: true
 
+      desc 'protocol used to push logs: the loki push API or the OTLP logs endpoint'
+      config_param :protocol, :enum, list: %i[loki otlp], default: :loki
+
+      desc 'record key used as the log body when pushing OTLP logs, the other keys are sent as log attributes'
+      config_param :otlp_body_key, :string, default: 'log'
+
+      # Metadata added by the kubernetes_metadata filter, mapped to OpenTelemetry
+      # semantic conventions resource attributes.
+      KUBERNETES_RESOURCE_ATTRIBUTES = {
+        'namespace_name' => 'k8s.namespace.name',
+        'pod_name' => 'k8s.pod.name',
+        'pod_id' => 'k8s.pod.uid',
+        'container_name' => 'k8s.container.name',
+        'host' => 'k8s.node.name'
+      }.freeze
+
       config_section :buffer do
         config_set_default :@type, DEFAULT_BUFFER_TYPE
         config_set_default :chunk_keys, []
@@ -99,7 +115,11 @@ module Fluent
       def configure(conf)
         compat_parameters_convert(conf, :buffer)
         super
-        @uri = URI.parse("#{@url}/loki/api/v1/push")
+        @uri = if @protocol == :otlp
+                 URI.parse("#{@url}/otlp/v1/logs")
+               else
+                 URI.parse("#{@url}/loki/api/v1/push")
+               end
         unless @uri.is_a?(URI::HTTP) || @uri.is_a?(URI::HTTPS)
           raise Fluent::ConfigError, 'URL parameter must have HTTP/HTTPS scheme'
         end
@@ -163,9 +183,12 @@ module Fluent
 
       # flush a chunk to loki
       def write(chunk)
-        # streams by label
-        payload = generic_to_loki(chunk)
-        body = { 'streams' => payload }
+        body = if @protocol == :otlp
+                 generic_to_otlp(chunk)
+               else
+                 # streams by label
+                 { 'streams' => generic_to_loki(chunk) }
+               end
 
         tenant = extract_placeholders(@tenant, chunk) if @tenant
 
@@ -234,6 +257,31 @@ module Fluent
         payload_builder(streams)
       end
 
+      # convert a chunk to an OTLP/JSON logs export request. Labels are sent as
+      # resource attributes so that Loki's OTLP config decides which of them
+      # become stream labels.
+      def generic_to_otlp(chunk)
+        resources = {}
+        chunk.each do |time, record|
+          result = line_to_otlp(record)
+          resources[result[:resource]] = [] if resources[result[:resource]].nil?
+          resources[result[:resource]].push(
+            'timeUnixNano' => to_nano(time).to_s,
+            'body' => { 'stringValue' => result[:line] },
+            'attributes' => otlp_attributes(result[:attributes])
+          )
+        end
+
+        {
+          'resourceLogs' => resources.map do |resource, records|
+            {
+              'resource' => { 'attributes' => otlp_attributes(resource.merge(@extra_labels)) },
+              'scopeLogs' => [{ 'logRecords' => records }]
+            }
+          end
+        }
+      end
+
       private
 
       def loki_http_request(body, tenant)
@@ -383,6 +431,42 @@ module Fluent
       end
       # rubocop:enable Metrics/CyclomaticComplexity, Metrics/PerceivedComplexity
 
+      # convert a record to an OTLP log record body, attributes and resource
+      # rubocop:disable Metrics/AbcSize, Metrics/CyclomaticComplexity, Metrics/PerceivedComplexity
+      def line_to_otlp(record)
+        resource = {}
+        return { line: record.to_s, attributes: {}, resource: resource } unless record.is_a?(Hash)
+
+        @record_accessors&.each do |name, accessor|
+          resource[name] = accessor.call(record)
+          accessor.delete(record)
+        end
+
+        if @extract_kubernetes_labels && record['kubernetes'].is_a?(Hash)
+          record['kubernetes'].each do |k, v|
+            resource[KUBERNETES_RESOURCE_ATTRIBUTES[k]] = v if KUBERNETES_RESOURCE_ATTRIBUTES.key?(k)
+          end
+          record.delete('kubernetes')
+        end
+
+        @remove_keys_accessors&.each do |deleter|
+          deleter.delete(record)
+        end
+
+        return { line: record_to_line(record), attributes: {}, resource: resource } unless record.key?(@otlp_body_key)
+
+        line = record.delete(@otlp_body_key)
+        { line: line.is_a?(String) ? line : Yajl.dump(line), attributes: record, resource: resource }
+      end
+      # rubocop:enable Metrics/AbcSize, Metrics/CyclomaticComplexity, Metrics/PerceivedComplexity
+
+      def otlp_attributes(attributes)
+        attributes.map do |k, v|
+          v = Yajl.dump(v) if v.is_a?(Hash) || v.is_a?(Array)
+          { 'key' => k.to_s, 'value' => { 'stringValue' => v.to_s } }
+        end
+      end
+
       # iterate through each chunk and create a loki stream entry
       def chunk_to_loki(chunk)
         streams = {}
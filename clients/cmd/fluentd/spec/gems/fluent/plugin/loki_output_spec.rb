@@ -319,6 +319,37 @@ RSpec.describe Fluent::Plugin::LokiOutput do
     expect { driver.instance.write(lines) }.to raise_error(described_class::LogPostError)
   end
 
+  it 'converts records to OTLP logs' do
+    config = <<-CONF
+      url     https://logs-us-west1.grafana.net
+      protocol otlp
+      extract_kubernetes_labels true
+      remove_keys drop
+      extra_labels {"service.name":"fluentd"}
+    CONF
+    driver = Fluent::Test::Driver::Output.new(described_class)
+    driver.configure(config)
+    expect(driver.instance.instance_variable_get(:@uri).to_s).to eq 'https://logs-us-west1.grafana.net/otlp/v1/logs'
+    record = {
+      'log' => 'hello',
+      'stream' => 'stdout',
+      'drop' => 'me',
+      'kubernetes' => { 'namespace_name' => 'default', 'pod_name' => 'app-1', 'labels' => { 'app' => 'app' } }
+    }
+    body = driver.instance.generic_to_otlp([[Time.at(1_546_270_458), record]])
+    expect(body['resourceLogs'].count).to eq 1
+    expect(body['resourceLogs'][0]['resource']['attributes']).to eq [
+      { 'key' => 'k8s.namespace.name', 'value' => { 'stringValue' => 'default' } },
+      { 'key' => 'k8s.pod.name', 'value' => { 'stringValue' => 'app-1' } },
+      { 'key' => 'service.name', 'value' => { 'stringValue' => 'fluentd' } }
+    ]
+    log_records = body['resourceLogs'][0]['scopeLogs'][0]['logRecords']
+    expect(log_records.count).to eq 1
+    expect(log_records[0]['timeUnixNano']).to eq '1546270458000000000'
+    expect(log_records[0]['body']).to eq('stringValue' => 'hello')
+    expect(log_records[0]['attributes']).to eq [{ 'key' => 'stream', 'value' => { 'stringValue' => 'stdout' } }]
+  end
+
   context 'when output is multi-thread' do
     let(:thread) do
       class_double(
@@ -120,6 +120,36 @@ func TestCounterExpiration(t *testing.T) {
 	assert.Contains(t, cnt.metrics, lbl2.Fingerprint())
 }
 
+func TestCounterMaxSeries(t *testing.T) {
+	t.Parallel()
+	cfg := CounterConfig{
+		Action: "inc",
+	}
+
+	cnt, err := NewCounters("test1", "HELP ME!!!!!", cfg, 1)
+	assert.Nil(t, err)
+	dropped := 0
+	cnt.SetMaxSeries(1, func() { dropped++ })
+
+	lbl1 := model.LabelSet{"test": "first"}
+	lbl2 := model.LabelSet{"test": "second"}
+	cnt.With(lbl1).Inc()
+	cnt.With(lbl2).Inc()
+
+	// The second series is over the limit and isn't tracked
+	assert.Contains(t, cnt.metrics, lbl1.Fingerprint())
+	assert.NotContains(t, cnt.metrics, lbl2.Fingerprint())
+	assert.Equal(t, 1, dropped)
+
+	time.Sleep(1100 * time.Millisecond) // Wait just past our max idle of 1 sec
+
+	// Once the first series expired, there is room for the second one
+	cnt.With(lbl2).Inc()
+	assert.NotContains(t, cnt.metrics, lbl1.Fingerprint())
+	assert.Contains(t, cnt.metrics, lbl2.Fingerprint())
+	assert.Equal(t, 1, dropped)
+}
+
 func collect(c prometheus.Collector) {
 	done := make(chan struct{})
 	collector := make(chan prometheus.Metric)
@@ -21,6 +21,11 @@ type metricVec struct {
 	mtx       sync.Mutex
 	metrics   map[model.Fingerprint]prometheus.Metric
 	maxAgeSec int64
+	// maxSeries limits the number of series, 0 means no limit.
+	maxSeries int
+	// onDropped is called when a new series isn't tracked because of
+	// maxSeries.
+	onDropped func()
 }
 
 func newMetricVec(factory func(labels map[string]string) prometheus.Metric, maxAgeSec int64) *metricVec {
@@ -45,6 +50,17 @@ func (c *metricVec) Collect(ch chan<- prometheus.Metric) {
 	c.prune()
 }
 
+// SetMaxSeries limits the number of series of the vector, 0 means no limit.
+// Once the limit is reached, the metrics returned for new label sets aren't
+// collected until idle series expire, and onDropped is called instead, if not
+// nil.
+func (c *metricVec) SetMaxSeries(maxSeries int, onDropped func()) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	c.maxSeries = maxSeries
+	c.onDropped = onDropped
+}
+
 // With returns the metric associated with the labelset.
 func (c *metricVec) With(labels model.LabelSet) prometheus.Metric {
 	c.mtx.Lock()
@@ -54,6 +70,15 @@ func (c *metricVec) With(labels model.LabelSet) prometheus.Metric {
 	var metric prometheus.Metric
 	if metric, ok = c.metrics[fp]; !ok {
 		metric = c.factory(util.ModelLabelSetToMap(cleanLabels(labels)))
+		if c.maxSeries > 0 && len(c.metrics) >= c.maxSeries {
+			c.prune()
+			if len(c.metrics) >= c.maxSeries {
+				if c.onDropped != nil {
+					c.onDropped()
+				}
+				return metric
+			}
+		}
 		c.metrics[fp] = metric
 	}
 	return metric
@@ -6,6 +6,7 @@ import (
 	"reflect"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/go-kit/log"
@@ -27,6 +28,7 @@ const (
 	ErrMetricsStageInvalidType = "invalid metric type '%s', metric type must be one of 'counter', 'gauge', or 'histogram'"
 	ErrInvalidIdleDur          = "max_idle_duration could not be parsed as a time.Duration: '%s'"
 	ErrSubSecIdleDur           = "max_idle_duration less than 1s not allowed"
+	ErrNegativeMaxSeries       = "max_series must not be negative"
 )
 
 // MetricConfig is a single metrics configuration.
@@ -37,7 +39,9 @@ type MetricConfig struct {
 	Prefix       string  `mapstructure:"prefix"`
 	IdleDuration *string `mapstructure:"max_idle_duration"`
 	maxIdleSec   int64
-	Config       interface{} `mapstructure:"config"`
+	MaxSeries    int `mapstructure:"max_series"`
+
+	Config interface{} `mapstructure:"config"`
 }
 
 // MetricsConfig is a set of configured metrics.
@@ -63,6 +67,10 @@ func validateMetricsConfig(cfg MetricsConfig) error {
 			return errors.Errorf(ErrMetricsStageInvalidType, config.MetricType)
 		}
 
+		if config.MaxSeries < 0 {
+			return errors.New(ErrNegativeMaxSeries)
+		}
+
 		// Set the idle duration for metrics
 		if config.IdleDuration != nil {
 			d, err := time.ParseDuration(*config.IdleDuration)
@@ -95,9 +103,13 @@ func newMetricStage(logger log.Logger, config interface{}, registry prometheus.R
 	if err != nil {
 		return nil, err
 	}
+	droppedSeries := getDroppedSeriesMetric(registry)
 	metrics := map[string]prometheus.Collector{}
 	for name, cfg := range *cfgs {
-		var collector prometheus.Collector
+		var collector interface {
+			prometheus.Collector
+			SetMaxSeries(int, func())
+		}
 
 		customPrefix := ""
 		if cfg.Prefix != "" {
@@ -124,6 +136,7 @@ func newMetricStage(logger log.Logger, config interface{}, registry prometheus.R
 			}
 		}
 		if collector != nil {
+			collector.SetMaxSeries(cfg.MaxSeries, droppedSeriesFunc(logger, droppedSeries, customPrefix+name, cfg.MaxSeries))
 			registry.MustRegister(collector)
 			metrics[name] = collector
 		}
@@ -135,6 +148,37 @@ func newMetricStage(logger log.Logger, config interface{}, registry prometheus.R
 	}, nil
 }
 
+func getDroppedSeriesMetric(registerer prometheus.Registerer) *prometheus.CounterVec {
+	droppedSeries := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "logentry",
+		Name:      "metrics_dropped_series_total",
+		Help:      "A count of the updates of pipeline metrics discarded because the metric reached its max_series",
+	}, []string{"metric"})
+	err := registerer.Register(droppedSeries)
+	if err != nil {
+		if existing, ok := err.(prometheus.AlreadyRegisteredError); ok {
+			droppedSeries = existing.ExistingCollector.(*prometheus.CounterVec)
+		} else {
+			// Same behavior as MustRegister if the error is not for AlreadyRegistered
+			panic(err)
+		}
+	}
+	return droppedSeries
+}
+
+// droppedSeriesFunc returns the function called when an update of a metric is
+// discarded because of max_series. It counts the update, and logs the first
+// one only to avoid flooding the logs.
+func droppedSeriesFunc(logger log.Logger, droppedSeries *prometheus.CounterVec, name string, maxSeries int) func() {
+	var once sync.Once
+	return func() {
+		droppedSeries.WithLabelValues(name).Inc()
+		once.Do(func() {
+			level.Warn(logger).Log("msg", "metric reached its max_series, updates to new series are discarded", "metric", name, "max_series", maxSeries)
+		})
+	}
+}
+
 // metricStage creates and updates prometheus metrics based on extracted pipeline data
 type metricStage struct {
 	logger  log.Logger
@@ -444,6 +444,31 @@ func TestDefaultIdleDuration(t *testing.T) {
 	assert.Equal(t, int64(5*time.Minute.Seconds()), ms.(*metricStage).cfg["total_keys"].maxIdleSec)
 }
 
+func TestMetricStage_MaxSeries(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	matchAll := true
+	metricsConfig := MetricsConfig{
+		"lines": MetricConfig{
+			MetricType: "Counter",
+			MaxSeries:  1,
+			Config: metric.CounterConfig{
+				MatchAll: &matchAll,
+				Action:   metric.CounterInc,
+			},
+		},
+	}
+	ms, err := New(util_log.Logger, nil, StageTypeMetric, metricsConfig, registry)
+	require.NoError(t, err)
+
+	for _, app := range []string{"a", "b", "c"} {
+		ms.(*metricStage).Process(model.LabelSet{"app": model.LabelValue(app)}, map[string]interface{}{}, nil, nil)
+	}
+
+	// The updates of the series over the limit are counted as dropped.
+	dropped := getDroppedSeriesMetric(registry).WithLabelValues("promtail_custom_lines")
+	require.Equal(t, float64(2), testutil.ToFloat64(dropped))
+}
+
 var (
 	labelFoo = model.LabelSet(map[model.LabelName]model.LabelValue{"foo": "bar", "bar": "foo"})
 	labelFu  = model.LabelSet(map[model.LabelName]model.LabelValue{"fu": "baz", "baz": "fu"})
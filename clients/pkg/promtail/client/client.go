@@ -9,8 +9,11 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"path/filepath"
+	"regexp"
 	"strconv"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/go-kit/log"
@@ -60,6 +63,9 @@ type Metrics struct {
 	mutatedBytes                 *prometheus.CounterVec
 	requestDuration              *prometheus.HistogramVec
 	batchRetries                 *prometheus.CounterVec
+	spoolBytes                   *prometheus.GaugeVec
+	spoolBatches                 *prometheus.GaugeVec
+	spoolDroppedBatches          *prometheus.CounterVec
 	countersWithHost             []*prometheus.CounterVec
 	countersWithHostTenant       []*prometheus.CounterVec
 	countersWithHostTenantReason []*prometheus.CounterVec
@@ -113,6 +119,21 @@ func NewMetrics(reg prometheus.Registerer) *Metrics {
 		Name:      "batch_retries_total",
 		Help:      "Number of times batches has had to be retried.",
 	}, []string{HostLabel, TenantLabel})
+	m.spoolBytes = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "promtail",
+		Name:      "spool_bytes",
+		Help:      "Number of bytes of batches currently persisted in the on-disk spool.",
+	}, []string{HostLabel, ClientLabel})
+	m.spoolBatches = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "promtail",
+		Name:      "spool_batches",
+		Help:      "Number of batches currently persisted in the on-disk spool.",
+	}, []string{HostLabel, ClientLabel})
+	m.spoolDroppedBatches = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "promtail",
+		Name:      "spool_dropped_batches_total",
+		Help:      "Number of spooled batches discarded before they could be sent.",
+	}, []string{HostLabel, ReasonLabel})
 
 	m.countersWithHost = []*prometheus.CounterVec{
 		m.encodedBytes, m.sentBytes, m.sentEntries,
@@ -136,11 +157,52 @@ func NewMetrics(reg prometheus.Registerer) *Metrics {
 		m.mutatedBytes = mustRegisterOrGet(reg, m.mutatedBytes).(*prometheus.CounterVec)
 		m.requestDuration = mustRegisterOrGet(reg, m.requestDuration).(*prometheus.HistogramVec)
 		m.batchRetries = mustRegisterOrGet(reg, m.batchRetries).(*prometheus.CounterVec)
+		m.spoolBytes = mustRegisterOrGet(reg, m.spoolBytes).(*prometheus.GaugeVec)
+		m.spoolBatches = mustRegisterOrGet(reg, m.spoolBatches).(*prometheus.GaugeVec)
+		m.spoolDroppedBatches = mustRegisterOrGet(reg, m.spoolDroppedBatches).(*prometheus.CounterVec)
 	}
 
 	return &m
 }
 
+// InitHost initializes the counters of a host to 0, so that they are exported
+// before they are first incremented.
+func (m *Metrics) InitHost(host string) {
+	for _, counter := range m.countersWithHost {
+		counter.WithLabelValues(host).Add(0)
+	}
+}
+
+// The following methods record the pushes of clients that aren't created by
+// New, such as the OTLP client of the fluent-bit plugin.
+
+// ObserveEncoded records the size of an encoded batch.
+func (m *Metrics) ObserveEncoded(host string, bytes int) {
+	m.encodedBytes.WithLabelValues(host).Add(float64(bytes))
+}
+
+// ObserveRequest records the duration of a push request.
+func (m *Metrics) ObserveRequest(host string, status int, duration time.Duration) {
+	m.requestDuration.WithLabelValues(strconv.Itoa(status), host).Observe(duration.Seconds())
+}
+
+// ObserveSent records a batch that was delivered.
+func (m *Metrics) ObserveSent(host string, bytes, entries int) {
+	m.sentBytes.WithLabelValues(host).Add(float64(bytes))
+	m.sentEntries.WithLabelValues(host).Add(float64(entries))
+}
+
+// ObserveRetry records a push that is retried.
+func (m *Metrics) ObserveRetry(host, tenantID string) {
+	m.batchRetries.WithLabelValues(host, tenantID).Inc()
+}
+
+// ObserveDropped records a batch that won't be delivered.
+func (m *Metrics) ObserveDropped(host, tenantID, reason string, bytes, entries int) {
+	m.droppedBytes.WithLabelValues(host, tenantID, reason).Add(float64(bytes))
+	m.droppedEntries.WithLabelValues(host, tenantID, reason).Add(float64(entries))
+}
+
 func mustRegisterOrGet(reg prometheus.Registerer, c prometheus.Collector) prometheus.Collector {
 	if err := reg.Register(c); err != nil {
 		if are, ok := err.(prometheus.AlreadyRegisteredError); ok {
@@ -179,6 +241,14 @@ type client struct {
 	maxStreams          int
 	maxLineSize         int
 	maxLineSizeTruncate bool
+
+	// spool is nil unless the on-disk spool is enabled. Spooled batches are
+	// replayed by their own goroutine, until stopReplay is closed.
+	spool      *spool
+	stopReplay chan struct{}
+	// failingSince is the time of the first failed push since the last
+	// successful one in unix nanoseconds, zero while pushes succeed.
+	failingSince atomic.Int64
 }
 
 // Tripperware can wrap a roundtripper.
@@ -205,7 +275,7 @@ func newClient(metrics *Metrics, cfg Config, maxStreams, maxLineSize int, maxLin
 		cfg:     cfg,
 		entries: make(chan api.Entry),
 		metrics: metrics,
-		name:    asSha256(cfg),
+		name:    ConfigName(cfg),
 
 		externalLabels:      cfg.ExternalLabels.LabelSet,
 		ctx:                 ctx,
@@ -214,9 +284,6 @@ func newClient(metrics *Metrics, cfg Config, maxStreams, maxLineSize int, maxLin
 		maxLineSize:         maxLineSize,
 		maxLineSizeTruncate: maxLineSizeTruncate,
 	}
-	if cfg.Name != "" {
-		c.name = cfg.Name
-	}
 
 	err := cfg.Client.Validate()
 	if err != nil {
@@ -230,14 +297,23 @@ func newClient(metrics *Metrics, cfg Config, maxStreams, maxLineSize int, maxLin
 
 	c.client.Timeout = cfg.Timeout
 
-	// Initialize counters to 0 so the metrics are exported before the first
-	// occurrence of incrementing to avoid missing metrics.
-	for _, counter := range c.metrics.countersWithHost {
-		counter.WithLabelValues(c.cfg.URL.Host).Add(0)
+	if cfg.Spool.Enabled {
+		name := spoolDirName(cfg)
+		c.spool, err = newSpool(cfg.Spool, filepath.Join(cfg.Spool.Directory, name), cfg.URL.Host, name, metrics, c.logger)
+		if err != nil {
+			return nil, err
+		}
+		c.stopReplay = make(chan struct{})
 	}
 
+	c.metrics.InitHost(c.cfg.URL.Host)
+
 	c.wg.Add(1)
 	go c.run()
+	if c.spool != nil {
+		c.wg.Add(1)
+		go c.runReplay()
+	}
 	return c, nil
 }
 
@@ -362,10 +438,60 @@ func (c *client) run() {
 	}
 }
 
+// runReplay periodically replays the spooled batches, so that new batches
+// are spooled behind them in the meantime rather than waiting for the replay.
+func (c *client) runReplay() {
+	defer c.wg.Done()
+
+	ticker := time.NewTicker(c.spool.cfg.ReplayInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			c.replaySpool()
+		case <-c.stopReplay:
+			return
+		case <-c.ctx.Done():
+			return
+		}
+	}
+}
+
 func (c *client) Chan() chan<- api.Entry {
 	return c.entries
 }
 
+// ConfigName returns the name of the client created with the given config: its
+// configured name, or a hash of the config.
+func ConfigName(cfg Config) string {
+	if cfg.Name != "" {
+		return cfg.Name
+	}
+	return asSha256(cfg)
+}
+
+// spoolDirNameRegexp matches the client names that are used as is for the
+// name of their spool directory. Names starting with a dot, such as "..",
+// don't match.
+var spoolDirNameRegexp = regexp.MustCompile(`^[a-zA-Z0-9_-][a-zA-Z0-9_.-]*$`)
+
+// spoolDirName returns the name of the spool directory of a client. Unnamed
+// clients are identified by their URL and tenant rather than by their whole
+// config, so that spooled batches are still replayed after other settings
+// change. Names that aren't safe to use as a directory name, e.g. because
+// they contain a path separator, are hashed so that the directory stays
+// within the spool directory.
+func spoolDirName(cfg Config) string {
+	if cfg.Name != "" {
+		if spoolDirNameRegexp.MatchString(cfg.Name) {
+			return cfg.Name
+		}
+		return asSha256(cfg.Name)
+	}
+	return asSha256(cfg.URL.String() + "/" + cfg.TenantID)
+}
+
 func asSha256(o interface{}) string {
 	h := sha256.New()
 	fmt.Fprintf(h, "%v", o)
@@ -387,14 +513,17 @@ func (c *client) sendBatch(tenantID string, batch *batch) {
 	bufBytes := float64(len(buf))
 	c.metrics.encodedBytes.WithLabelValues(c.cfg.URL.Host).Add(bufBytes)
 
+	// Batches must be delivered in order, so as long as there are spooled
+	// batches new ones are queued behind them.
+	if c.spool != nil && c.spool.len() > 0 {
+		c.spoolBatch(tenantID, buf, entriesCount)
+		return
+	}
+
 	backoff := backoff.New(c.ctx, c.cfg.BackoffConfig)
 	var status int
 	for {
-		start := time.Now()
-		// send uses `timeout` internally, so `context.Background` is good enough.
-		status, err = c.send(context.Background(), tenantID, buf)
-
-		c.metrics.requestDuration.WithLabelValues(strconv.Itoa(status), c.cfg.URL.Host).Observe(time.Since(start).Seconds())
+		status, err = c.sendAndObserve(tenantID, buf)
 
 		// Immediately drop rate limited batches to avoid HOL blocking for other tenants not experiencing throttling
 		if c.cfg.DropRateLimitedBatches && batchIsRateLimited(status) {
@@ -412,10 +541,17 @@ func (c *client) sendBatch(tenantID string, batch *batch) {
 		}
 
 		// Only retry 429s, 500s and connection-level errors.
-		if status > 0 && !batchIsRateLimited(status) && status/100 != 5 {
+		if !isRetryable(status) {
 			break
 		}
 
+		// Stop retrying in memory once Loki has been unreachable for longer
+		// than the spool threshold.
+		if c.spool != nil && c.failingFor() >= c.spool.cfg.Threshold {
+			c.spoolBatch(tenantID, buf, entriesCount)
+			return
+		}
+
 		level.Warn(c.logger).Log("msg", "error sending batch, will retry", "status", status, "tenant", tenantID, "error", err)
 		c.metrics.batchRetries.WithLabelValues(c.cfg.URL.Host, tenantID).Inc()
 		backoff.Wait()
@@ -426,6 +562,11 @@ func (c *client) sendBatch(tenantID string, batch *batch) {
 		}
 	}
 
+	if err != nil && c.spool != nil && isRetryable(status) {
+		c.spoolBatch(tenantID, buf, entriesCount)
+		return
+	}
+
 	if err != nil {
 		level.Error(c.logger).Log("msg", "final error sending batch", "status", status, "tenant", tenantID, "error", err)
 		// If the reason for the last retry error was rate limiting, count the drops as such, even if the previous errors
@@ -439,6 +580,95 @@ func (c *client) sendBatch(tenantID string, batch *batch) {
 	}
 }
 
+// sendAndObserve sends the encoded batch once, recording the request duration
+// and keeping track of how long pushes have been failing.
+func (c *client) sendAndObserve(tenantID string, buf []byte) (int, error) {
+	start := time.Now()
+	// send uses `timeout` internally, so `context.Background` is good enough.
+	status, err := c.send(context.Background(), tenantID, buf)
+
+	c.metrics.requestDuration.WithLabelValues(strconv.Itoa(status), c.cfg.URL.Host).Observe(time.Since(start).Seconds())
+
+	if err == nil {
+		c.failingSince.Store(0)
+	} else {
+		c.failingSince.CompareAndSwap(0, start.UnixNano())
+	}
+	return status, err
+}
+
+// failingFor returns how long pushes have been failing, zero while they
+// succeed.
+func (c *client) failingFor() time.Duration {
+	since := c.failingSince.Load()
+	if since == 0 {
+		return 0
+	}
+	return time.Since(time.Unix(0, since))
+}
+
+// isRetryable returns whether a push that failed with the given status code
+// may succeed if sent again.
+func isRetryable(status int) bool {
+	return status <= 0 || batchIsRateLimited(status) || status/100 == 5
+}
+
+// spoolBatch persists an encoded batch to the on-disk spool, counting it as
+// dropped if that fails.
+func (c *client) spoolBatch(tenantID string, buf []byte, entriesCount int) {
+	if err := c.spool.append(tenantID, buf, entriesCount); err != nil {
+		level.Error(c.logger).Log("msg", "error spooling batch, dropping it", "tenant", tenantID, "error", err)
+		c.metrics.droppedBytes.WithLabelValues(c.cfg.URL.Host, tenantID, ReasonGeneric).Add(float64(len(buf)))
+		c.metrics.droppedEntries.WithLabelValues(c.cfg.URL.Host, tenantID, ReasonGeneric).Add(float64(entriesCount))
+		return
+	}
+	level.Debug(c.logger).Log("msg", "spooled batch", "tenant", tenantID, "entries", entriesCount)
+}
+
+// replaySpool sends spooled batches in order until the spool is empty or a
+// push fails with a retryable error.
+func (c *client) replaySpool() {
+	c.spool.expire(time.Now())
+
+	for c.spool.len() > 0 && c.ctx.Err() == nil && !c.replayStopped() {
+		seg, buf, err := c.spool.oldest()
+		if err != nil {
+			level.Error(c.logger).Log("msg", "error reading spooled batch", "error", err)
+			c.spool.drop(seg, spoolDropReasonCorrupt)
+			continue
+		}
+
+		status, err := c.sendAndObserve(seg.tenantID, buf)
+		if err == nil {
+			c.metrics.sentBytes.WithLabelValues(c.cfg.URL.Host).Add(float64(len(buf)))
+			c.metrics.sentEntries.WithLabelValues(c.cfg.URL.Host).Add(float64(seg.entries))
+			c.spool.remove(seg)
+			continue
+		}
+
+		if isRetryable(status) {
+			level.Debug(c.logger).Log("msg", "error replaying spooled batch, will retry", "status", status, "tenant", seg.tenantID, "error", err)
+			return
+		}
+
+		level.Error(c.logger).Log("msg", "final error sending spooled batch", "status", status, "tenant", seg.tenantID, "error", err)
+		c.metrics.droppedBytes.WithLabelValues(c.cfg.URL.Host, seg.tenantID, ReasonGeneric).Add(float64(len(buf)))
+		c.metrics.droppedEntries.WithLabelValues(c.cfg.URL.Host, seg.tenantID, ReasonGeneric).Add(float64(seg.entries))
+		c.spool.remove(seg)
+	}
+}
+
+// replayStopped returns whether the client is stopping, so that the replay
+// doesn't hold it up.
+func (c *client) replayStopped() bool {
+	select {
+	case <-c.stopReplay:
+		return true
+	default:
+		return false
+	}
+}
+
 func (c *client) send(ctx context.Context, tenantID string, buf []byte) (int, error) {
 	ctx, cancel := context.WithTimeout(ctx, c.cfg.Timeout)
 	defer cancel()
@@ -501,7 +731,12 @@ func (c *client) getTenantID(labels model.LabelSet) string {
 
 // Stop the client.
 func (c *client) Stop() {
-	c.once.Do(func() { close(c.entries) })
+	c.once.Do(func() {
+		close(c.entries)
+		if c.stopReplay != nil {
+			close(c.stopReplay)
+		}
+	})
 	c.wg.Wait()
 }
 
@@ -1,6 +1,7 @@
 package client
 
 import (
+	"errors"
 	"flag"
 	"time"
 
@@ -19,6 +20,11 @@ const (
 	MaxBackoff     = 5 * time.Minute
 	MaxRetries int = 10
 	Timeout        = 10 * time.Second
+
+	SpoolThreshold            = 1 * time.Minute
+	SpoolMaxSizeBytes   int64 = 1 << 30
+	SpoolMaxAge               = 24 * time.Hour
+	SpoolReplayInterval       = 10 * time.Second
 )
 
 // Config describes configuration for an HTTP pusher client.
@@ -48,6 +54,46 @@ type Config struct {
 	// 429 'Too Many Requests' response from the distributor. Helps
 	// prevent HOL blocking in multitenant deployments.
 	DropRateLimitedBatches bool `yaml:"drop_rate_limited_batches"`
+
+	// Spool configures persisting batches to disk while Loki is unreachable.
+	Spool SpoolConfig `yaml:"spool,omitempty"`
+}
+
+// SpoolConfig describes the on-disk spool used to keep batches that could not
+// be delivered to Loki. Zero values fall back to the Spool* defaults.
+type SpoolConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// Directory where spooled batches are written. Each client uses its own
+	// sub-directory named after the client.
+	Directory string `yaml:"directory"`
+	// How long pushes have to keep failing before batches are spooled
+	// instead of being retried in memory.
+	Threshold time.Duration `yaml:"threshold"`
+	// Maximum total size of the spool. The oldest batches are discarded
+	// when a new batch would exceed it.
+	MaxSizeBytes int64 `yaml:"max_size_bytes"`
+	// Maximum age of a spooled batch before it is discarded.
+	MaxAge time.Duration `yaml:"max_age"`
+	// How often delivery of spooled batches is attempted.
+	ReplayInterval time.Duration `yaml:"replay_interval"`
+}
+
+// withDefaults returns a copy of the config with zero values replaced by the
+// defaults.
+func (c SpoolConfig) withDefaults() SpoolConfig {
+	if c.Threshold == 0 {
+		c.Threshold = SpoolThreshold
+	}
+	if c.MaxSizeBytes == 0 {
+		c.MaxSizeBytes = SpoolMaxSizeBytes
+	}
+	if c.MaxAge == 0 {
+		c.MaxAge = SpoolMaxAge
+	}
+	if c.ReplayInterval == 0 {
+		c.ReplayInterval = SpoolReplayInterval
+	}
+	return c
 }
 
 // RegisterFlags with prefix registers flags where every name is prefixed by
@@ -105,6 +151,10 @@ func (c *Config) UnmarshalYAML(unmarshal func(interface{}) error) error {
 		return err
 	}
 
+	if cfg.Spool.Enabled && cfg.Spool.Directory == "" {
+		return errors.New("spool directory must be set when the spool is enabled")
+	}
+
 	*c = Config(cfg)
 	return nil
 }
@@ -0,0 +1,343 @@
+package client
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/grafana/dskit/tenant"
+)
+
+const (
+	spoolFileExt    = ".batch"
+	spoolTmpFileExt = ".tmp"
+
+	spoolDropReasonFull    = "spool_full"
+	spoolDropReasonExpired = "spool_expired"
+	spoolDropReasonCorrupt = "spool_corrupt"
+)
+
+// spoolSegment is a single encoded batch persisted in the spool.
+type spoolSegment struct {
+	seq       uint64
+	path      string
+	tenantID  string
+	entries   int
+	size      int64 // size of the file
+	bytes     int64 // size of the encoded batch
+	createdAt time.Time
+}
+
+// spool is a bounded, ordered on-disk queue of encoded batches. Each batch is
+// stored in its own file named after a monotonically increasing sequence
+// number, so that the replay order survives restarts.
+//
+// The spool is safe for concurrent use: batches are appended by the client's
+// run loop while the replay goroutine sends and removes them.
+type spool struct {
+	cfg     SpoolConfig
+	dir     string
+	host    string
+	client  string // name of the spool directory, identifying the client in metrics
+	metrics *Metrics
+	logger  log.Logger
+
+	mtx      sync.Mutex
+	segments []spoolSegment
+	size     int64
+	nextSeq  uint64
+}
+
+func newSpool(cfg SpoolConfig, dir, host, client string, metrics *Metrics, logger log.Logger) (*spool, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("creating spool directory: %w", err)
+	}
+
+	s := &spool{
+		cfg:     cfg.withDefaults(),
+		dir:     dir,
+		host:    host,
+		client:  client,
+		metrics: metrics,
+		logger:  log.With(logger, "component", "spool", "dir", dir),
+	}
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+	s.updateMetrics()
+	return s, nil
+}
+
+// load scans the spool directory for batches left over by a previous run.
+func (s *spool) load() error {
+	files, err := os.ReadDir(s.dir)
+	if err != nil {
+		return fmt.Errorf("reading spool directory: %w", err)
+	}
+
+	for _, f := range files {
+		name := f.Name()
+		path := filepath.Join(s.dir, name)
+		if strings.HasSuffix(name, spoolTmpFileExt) {
+			// Leftover of an interrupted write.
+			_ = os.Remove(path)
+			continue
+		}
+		if f.IsDir() || !strings.HasSuffix(name, spoolFileExt) {
+			continue
+		}
+		seq, err := strconv.ParseUint(strings.TrimSuffix(name, spoolFileExt), 10, 64)
+		if err != nil {
+			continue
+		}
+
+		seg, err := readSpoolSegment(path)
+		if err != nil {
+			level.Warn(s.logger).Log("msg", "discarding unreadable spooled batch", "file", name, "error", err)
+			s.metrics.spoolDroppedBatches.WithLabelValues(s.host, spoolDropReasonCorrupt).Inc()
+			_ = os.Remove(path)
+			continue
+		}
+		seg.seq = seq
+		s.segments = append(s.segments, seg)
+		s.size += seg.size
+		if seq >= s.nextSeq {
+			s.nextSeq = seq + 1
+		}
+	}
+
+	slices.SortFunc(s.segments, func(a, b spoolSegment) int {
+		switch {
+		case a.seq < b.seq:
+			return -1
+		case a.seq > b.seq:
+			return 1
+		}
+		return 0
+	})
+
+	if len(s.segments) > 0 {
+		level.Info(s.logger).Log("msg", "found spooled batches", "batches", len(s.segments), "bytes", s.size)
+	}
+	return nil
+}
+
+// len returns the number of spooled batches.
+func (s *spool) len() int {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	return len(s.segments)
+}
+
+// append persists an encoded batch at the end of the spool, discarding the
+// oldest batches if the spool would grow over its maximum size.
+func (s *spool) append(tenantID string, buf []byte, entries int) error {
+	if len(tenantID) > tenant.MaxTenantIDLength {
+		return fmt.Errorf("tenant ID of %d bytes exceeds the maximum of %d bytes", len(tenantID), tenant.MaxTenantIDLength)
+	}
+	createdAt := time.Now()
+
+	data := make([]byte, 0, len(buf)+len(tenantID)+3*binary.MaxVarintLen64)
+	data = binary.AppendUvarint(data, uint64(createdAt.UnixNano()))
+	data = binary.AppendUvarint(data, uint64(entries))
+	data = binary.AppendUvarint(data, uint64(len(tenantID)))
+	data = append(data, tenantID...)
+	data = append(data, buf...)
+
+	size := int64(len(data))
+	if size > s.cfg.MaxSizeBytes {
+		return fmt.Errorf("batch of %d bytes exceeds the spool max size of %d bytes", size, s.cfg.MaxSizeBytes)
+	}
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	for s.size+size > s.cfg.MaxSizeBytes && len(s.segments) > 0 {
+		s.dropLocked(s.segments[0], spoolDropReasonFull)
+	}
+
+	seq := s.nextSeq
+	path := filepath.Join(s.dir, fmt.Sprintf("%020d%s", seq, spoolFileExt))
+	if err := writeFileAtomic(path, data); err != nil {
+		return err
+	}
+	s.nextSeq++
+
+	s.segments = append(s.segments, spoolSegment{
+		seq:       seq,
+		path:      path,
+		tenantID:  tenantID,
+		entries:   entries,
+		size:      size,
+		bytes:     int64(len(buf)),
+		createdAt: createdAt,
+	})
+	s.size += size
+	s.updateMetrics()
+	return nil
+}
+
+// oldest returns the oldest spooled batch together with its encoded payload.
+func (s *spool) oldest() (spoolSegment, []byte, error) {
+	s.mtx.Lock()
+	if len(s.segments) == 0 {
+		s.mtx.Unlock()
+		return spoolSegment{}, nil, errors.New("spool is empty")
+	}
+	seg := s.segments[0]
+	s.mtx.Unlock()
+
+	f, err := os.Open(seg.path)
+	if err != nil {
+		return seg, nil, err
+	}
+	defer f.Close()
+
+	r := bufio.NewReader(f)
+	if _, err := readSpoolHeader(r); err != nil {
+		return seg, nil, err
+	}
+	buf, err := io.ReadAll(r)
+	return seg, buf, err
+}
+
+// remove deletes a delivered batch from the spool.
+func (s *spool) remove(seg spoolSegment) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.removeLocked(seg)
+}
+
+func (s *spool) removeLocked(seg spoolSegment) {
+	// The batch may have been dropped already to make room for a new one
+	// while it was being replayed.
+	i := slices.IndexFunc(s.segments, func(o spoolSegment) bool { return o.seq == seg.seq })
+	if i < 0 {
+		return
+	}
+	if err := os.Remove(seg.path); err != nil && !os.IsNotExist(err) {
+		level.Warn(s.logger).Log("msg", "failed to remove spooled batch", "file", seg.path, "error", err)
+	}
+	s.segments = slices.Delete(s.segments, i, i+1)
+	s.size -= seg.size
+	s.updateMetrics()
+}
+
+// drop discards a batch that won't be delivered.
+func (s *spool) drop(seg spoolSegment, reason string) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.dropLocked(seg, reason)
+}
+
+func (s *spool) dropLocked(seg spoolSegment, reason string) {
+	if !slices.ContainsFunc(s.segments, func(o spoolSegment) bool { return o.seq == seg.seq }) {
+		return
+	}
+	level.Warn(s.logger).Log("msg", "discarding spooled batch", "reason", reason, "tenant", seg.tenantID, "entries", seg.entries, "bytes", seg.size)
+	s.metrics.spoolDroppedBatches.WithLabelValues(s.host, reason).Inc()
+	s.metrics.droppedEntries.WithLabelValues(s.host, seg.tenantID, ReasonGeneric).Add(float64(seg.entries))
+	s.metrics.droppedBytes.WithLabelValues(s.host, seg.tenantID, ReasonGeneric).Add(float64(seg.bytes))
+	s.removeLocked(seg)
+}
+
+// expire discards the batches older than the configured max age.
+func (s *spool) expire(now time.Time) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	for len(s.segments) > 0 && now.Sub(s.segments[0].createdAt) > s.cfg.MaxAge {
+		s.dropLocked(s.segments[0], spoolDropReasonExpired)
+	}
+}
+
+func (s *spool) updateMetrics() {
+	s.metrics.spoolBytes.WithLabelValues(s.host, s.client).Set(float64(s.size))
+	s.metrics.spoolBatches.WithLabelValues(s.host, s.client).Set(float64(len(s.segments)))
+}
+
+func readSpoolSegment(path string) (spoolSegment, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return spoolSegment{}, err
+	}
+	defer f.Close()
+
+	stat, err := f.Stat()
+	if err != nil {
+		return spoolSegment{}, err
+	}
+
+	r := bufio.NewReader(f)
+	seg, err := readSpoolHeader(r)
+	if err != nil {
+		return spoolSegment{}, err
+	}
+	offset, err := f.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return spoolSegment{}, err
+	}
+	seg.path = path
+	seg.size = stat.Size()
+	// The header ends where the buffered reader stopped reading.
+	seg.bytes = seg.size - (offset - int64(r.Buffered()))
+	return seg, nil
+}
+
+func readSpoolHeader(r *bufio.Reader) (spoolSegment, error) {
+	var seg spoolSegment
+
+	createdAt, err := binary.ReadUvarint(r)
+	if err != nil {
+		return seg, fmt.Errorf("reading creation time: %w", err)
+	}
+	entries, err := binary.ReadUvarint(r)
+	if err != nil {
+		return seg, fmt.Errorf("reading entries count: %w", err)
+	}
+	tenantLen, err := binary.ReadUvarint(r)
+	if err != nil {
+		return seg, fmt.Errorf("reading tenant length: %w", err)
+	}
+	if tenantLen > tenant.MaxTenantIDLength {
+		return seg, fmt.Errorf("tenant length %d exceeds the maximum of %d", tenantLen, tenant.MaxTenantIDLength)
+	}
+	tenantID := make([]byte, tenantLen)
+	if _, err := io.ReadFull(r, tenantID); err != nil {
+		return seg, fmt.Errorf("reading tenant: %w", err)
+	}
+
+	seg.createdAt = time.Unix(0, int64(createdAt))
+	seg.entries = int(entries)
+	seg.tenantID = string(tenantID)
+	return seg, nil
+}
+
+func writeFileAtomic(path string, data []byte) error {
+	tmp := path + spoolTmpFileExt
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o640)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
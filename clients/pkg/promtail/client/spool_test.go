@@ -0,0 +1,234 @@
+package client
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/grafana/dskit/backoff"
+	"github.com/grafana/dskit/flagext"
+	"github.com/grafana/dskit/tenant"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/prometheus/common/config"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/atomic"
+
+	"github.com/grafana/loki/v3/pkg/logproto"
+	"github.com/grafana/loki/v3/pkg/util"
+	lokiflag "github.com/grafana/loki/v3/pkg/util/flagext"
+)
+
+func TestSpool(t *testing.T) {
+	dir := t.TempDir()
+	m := NewMetrics(prometheus.NewRegistry())
+
+	s, err := newSpool(SpoolConfig{MaxSizeBytes: 64}, dir, "host", "client", m, log.NewNopLogger())
+	require.NoError(t, err)
+	require.Equal(t, 0, s.len())
+
+	require.NoError(t, s.append("tenant-1", []byte("batch-1"), 1))
+	require.NoError(t, s.append("", []byte("batch-2"), 2))
+	require.Equal(t, 2, s.len())
+
+	// Batches are kept across restarts, in order.
+	s, err = newSpool(SpoolConfig{MaxSizeBytes: 64}, dir, "host", "client", m, log.NewNopLogger())
+	require.NoError(t, err)
+	require.Equal(t, 2, s.len())
+
+	seg, buf, err := s.oldest()
+	require.NoError(t, err)
+	require.Equal(t, "tenant-1", seg.tenantID)
+	require.Equal(t, 1, seg.entries)
+	require.Equal(t, "batch-1", string(buf))
+	s.remove(seg)
+
+	seg, buf, err = s.oldest()
+	require.NoError(t, err)
+	require.Equal(t, "", seg.tenantID)
+	require.Equal(t, 2, seg.entries)
+	require.Equal(t, "batch-2", string(buf))
+
+	// New batches are numbered after the ones found on disk.
+	require.NoError(t, s.append("tenant-3", []byte("batch-3"), 3))
+	require.Equal(t, 2, s.len())
+	require.Equal(t, seg.seq+1, s.segments[1].seq)
+
+	// The oldest batches are discarded once the spool is full.
+	require.NoError(t, s.append("tenant-4", make([]byte, 40), 4))
+	require.Equal(t, 1, s.len())
+	require.Equal(t, "tenant-4", s.segments[0].tenantID)
+	require.Equal(t, float64(2), testutil.ToFloat64(m.spoolDroppedBatches.WithLabelValues("host", spoolDropReasonFull)))
+	require.Equal(t, float64(s.size), testutil.ToFloat64(m.spoolBytes.WithLabelValues("host", "client")))
+	// The batch found on disk and the appended one are counted as dropped.
+	require.Equal(t, float64(len("batch-2")), testutil.ToFloat64(m.droppedBytes.WithLabelValues("host", "", ReasonGeneric)))
+	require.Equal(t, float64(len("batch-3")), testutil.ToFloat64(m.droppedBytes.WithLabelValues("host", "tenant-3", ReasonGeneric)))
+
+	// Batches larger than the spool are rejected.
+	require.Error(t, s.append("tenant-5", make([]byte, 100), 5))
+
+	// Expired batches are discarded.
+	s.expire(time.Now().Add(SpoolMaxAge + time.Minute))
+	require.Equal(t, 0, s.len())
+	require.Equal(t, float64(1), testutil.ToFloat64(m.spoolDroppedBatches.WithLabelValues("host", spoolDropReasonExpired)))
+
+	files, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Empty(t, files)
+}
+
+func TestSpool_CorruptTenantLength(t *testing.T) {
+	dir := t.TempDir()
+	m := NewMetrics(prometheus.NewRegistry())
+
+	// A header claiming a huge tenant ID must not be allocated.
+	var data []byte
+	data = binary.AppendUvarint(data, uint64(time.Now().UnixNano()))
+	data = binary.AppendUvarint(data, 1)
+	data = binary.AppendUvarint(data, math.MaxUint64)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, fmt.Sprintf("%020d%s", 0, spoolFileExt)), data, 0o640))
+
+	s, err := newSpool(SpoolConfig{}, dir, "host", "client", m, log.NewNopLogger())
+	require.NoError(t, err)
+	require.Equal(t, 0, s.len())
+	require.Equal(t, float64(1), testutil.ToFloat64(m.spoolDroppedBatches.WithLabelValues("host", spoolDropReasonCorrupt)))
+
+	require.Error(t, s.append(strings.Repeat("a", tenant.MaxTenantIDLength+1), []byte("batch"), 1))
+}
+
+func TestSpool_MetricsPerClient(t *testing.T) {
+	m := NewMetrics(prometheus.NewRegistry())
+	a, err := newSpool(SpoolConfig{}, t.TempDir(), "host", "a", m, log.NewNopLogger())
+	require.NoError(t, err)
+	b, err := newSpool(SpoolConfig{}, t.TempDir(), "host", "b", m, log.NewNopLogger())
+	require.NoError(t, err)
+
+	// Clients pushing to the same host don't overwrite each other's gauges.
+	require.NoError(t, a.append("tenant-a", []byte("batch"), 1))
+	require.NoError(t, a.append("tenant-a", []byte("batch"), 1))
+	require.NoError(t, b.append("tenant-b", []byte("batch"), 1))
+	require.Equal(t, float64(2), testutil.ToFloat64(m.spoolBatches.WithLabelValues("host", "a")))
+	require.Equal(t, float64(1), testutil.ToFloat64(m.spoolBatches.WithLabelValues("host", "b")))
+	require.Equal(t, float64(a.size), testutil.ToFloat64(m.spoolBytes.WithLabelValues("host", "a")))
+}
+
+func TestSpoolDirName(t *testing.T) {
+	var serverURL flagext.URLValue
+	require.NoError(t, serverURL.Set("http://localhost:3100/loki/api/v1/push"))
+
+	cfg := Config{URL: serverURL, TenantID: "tenant-1", Timeout: time.Second}
+	other := cfg
+	other.Timeout = time.Minute
+	other.BatchSize = 1024
+	// Changing other settings keeps the spooled batches.
+	require.Equal(t, spoolDirName(cfg), spoolDirName(other))
+
+	other.TenantID = "tenant-2"
+	require.NotEqual(t, spoolDirName(cfg), spoolDirName(other))
+
+	other.Name = "named"
+	require.Equal(t, "named", spoolDirName(other))
+
+	// Names that would escape the spool directory are hashed.
+	for _, name := range []string{"..", ".", "../named", "a/b", `a\b`} {
+		other.Name = name
+		require.Equal(t, asSha256(name), spoolDirName(other), name)
+	}
+}
+
+func TestSpool_RemoveDropped(t *testing.T) {
+	m := NewMetrics(prometheus.NewRegistry())
+	s, err := newSpool(SpoolConfig{MaxSizeBytes: 64}, t.TempDir(), "host", "client", m, log.NewNopLogger())
+	require.NoError(t, err)
+
+	require.NoError(t, s.append("tenant", []byte(strings.Repeat("a", 40)), 1))
+	seg, _, err := s.oldest()
+	require.NoError(t, err)
+
+	// The batch being replayed is dropped to make room for a new one, and
+	// removing it once delivered doesn't account for it twice.
+	require.NoError(t, s.append("tenant", []byte(strings.Repeat("b", 40)), 1))
+	s.remove(seg)
+	require.Equal(t, 1, s.len())
+	require.Equal(t, float64(s.size), testutil.ToFloat64(m.spoolBytes.WithLabelValues("host", "client")))
+	require.Equal(t, float64(1), testutil.ToFloat64(m.spoolDroppedBatches.WithLabelValues("host", spoolDropReasonFull)))
+}
+
+func TestClient_Spool(t *testing.T) {
+	var (
+		available = atomic.NewBool(false)
+		received  = make(chan logproto.PushRequest, 10)
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if !available.Load() {
+			rw.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		var pushReq logproto.PushRequest
+		if err := util.ParseProtoReader(req.Context(), req.Body, int(req.ContentLength), math.MaxInt32, &pushReq, util.RawSnappy); err != nil {
+			rw.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		received <- pushReq
+	}))
+	defer server.Close()
+
+	serverURL := flagext.URLValue{}
+	require.NoError(t, serverURL.Set(server.URL))
+
+	dir := t.TempDir()
+	cfg := Config{
+		Name:           "spooling",
+		URL:            serverURL,
+		BatchWait:      10 * time.Millisecond,
+		BatchSize:      5,
+		Client:         config.HTTPClientConfig{},
+		BackoffConfig:  backoff.Config{MinBackoff: 1 * time.Millisecond, MaxBackoff: 2 * time.Millisecond, MaxRetries: 3},
+		ExternalLabels: lokiflag.LabelSet{},
+		Timeout:        1 * time.Second,
+		Spool: SpoolConfig{
+			Enabled:        true,
+			Directory:      dir,
+			Threshold:      time.Millisecond,
+			ReplayInterval: 20 * time.Millisecond,
+		},
+	}
+
+	m := NewMetrics(prometheus.NewRegistry())
+	c, err := New(m, cfg, 0, 0, false, log.NewNopLogger())
+	require.NoError(t, err)
+
+	// While Loki is unavailable, every batch ends up in the spool.
+	for _, e := range logEntries[:3] {
+		c.Chan() <- e
+	}
+	require.Eventually(t, func() bool {
+		files, err := os.ReadDir(filepath.Join(dir, cfg.Name))
+		return err == nil && len(files) == 3
+	}, 5*time.Second, 10*time.Millisecond)
+	require.Equal(t, float64(0), testutil.ToFloat64(m.droppedEntries.WithLabelValues(serverURL.Host, "", ReasonGeneric)))
+
+	// Once Loki is back, spooled batches are replayed in order.
+	available.Store(true)
+	for _, e := range logEntries[:3] {
+		select {
+		case req := <-received:
+			require.Len(t, req.Streams, 1)
+			require.Equal(t, []logproto.Entry{e.Entry}, req.Streams[0].Entries)
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for spooled batches to be replayed")
+		}
+	}
+	c.Stop()
+
+	require.Equal(t, float64(0), testutil.ToFloat64(m.spoolBatches.WithLabelValues(serverURL.Host, cfg.Name)))
+	require.Equal(t, float64(3), testutil.ToFloat64(m.sentEntries.WithLabelValues(serverURL.Host)))
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/grafana/loki/v3/clients/pkg/promtail/client"
 	"github.com/grafana/loki/v3/clients/pkg/promtail/limit"
 	"github.com/grafana/loki/v3/clients/pkg/promtail/positions"
+	"github.com/grafana/loki/v3/clients/pkg/promtail/remotewrite"
 	"github.com/grafana/loki/v3/clients/pkg/promtail/scrapeconfig"
 	"github.com/grafana/loki/v3/clients/pkg/promtail/server"
 	"github.com/grafana/loki/v3/clients/pkg/promtail/targets/file"
@@ -38,6 +39,10 @@ type Config struct {
 	Options         Options               `yaml:"options,omitempty"`
 	Tracing         tracing.Config        `yaml:"tracing"`
 	WAL             wal.Config            `yaml:"wal"`
+
+	// MetricsRemoteWrite pushes the metrics created by the metrics pipeline
+	// stage with Prometheus remote-write.
+	MetricsRemoteWrite remotewrite.Config `yaml:"metrics_remote_write,omitempty"`
 }
 
 // UnmarshalYAML implements the yaml.Unmarshaler interface.
@@ -19,6 +19,7 @@ import (
 	"github.com/grafana/loki/v3/clients/pkg/promtail/api"
 	"github.com/grafana/loki/v3/clients/pkg/promtail/client"
 	"github.com/grafana/loki/v3/clients/pkg/promtail/config"
+	"github.com/grafana/loki/v3/clients/pkg/promtail/remotewrite"
 	"github.com/grafana/loki/v3/clients/pkg/promtail/server"
 	"github.com/grafana/loki/v3/clients/pkg/promtail/targets"
 	"github.com/grafana/loki/v3/clients/pkg/promtail/targets/target"
@@ -68,6 +69,7 @@ func WithRegisterer(reg prometheus.Registerer) Option {
 type Promtail struct {
 	client         client.Client
 	walWriter      *wal.Writer
+	metricsWriter  *remotewrite.Writer
 	entriesFanout  api.EntryHandler
 	targetManagers *targets.TargetManagers
 	server         server.Server
@@ -77,8 +79,11 @@ type Promtail struct {
 	stopped      bool
 	mtx          sync.Mutex
 	configLoaded string
+	// baseConfigLoaded is the loaded config without the scrape configs.
+	baseConfigLoaded string
 	newConfig    func() (*config.Config, error)
 	metrics      *client.Metrics
+	rwMetrics    *remotewrite.Metrics
 	dryRun       bool
 }
 
@@ -108,6 +113,7 @@ func New(cfg config.Config, newConfig func() (*config.Config, error), metrics *c
 	if err != nil {
 		return nil, fmt.Errorf("error register prometheus collector reloadFailTotal :%w", err)
 	}
+	promtail.rwMetrics = remotewrite.NewMetrics(promtail.reg)
 	err = promtail.reloadConfig(&cfg)
 	if err != nil {
 		return nil, err
@@ -133,12 +139,33 @@ func (p *Promtail) reloadConfig(cfg *config.Config) error {
 	newConf := cfg.String()
 	hash := sha3.Sum256([]byte(newConf))
 	level.Info(p.logger).Log("msg", "Reloading configuration file", "sha3sum", fmt.Sprintf("%x", hash))
+
+	baseConf := baseConfig(cfg)
+	if p.targetManagers != nil && p.targetManagers.CanReload() && baseConf == p.baseConfigLoaded {
+		// Only the scrape configs changed: keep the clients and positions,
+		// and only restart the jobs which changed.
+		level.Info(p.logger).Log("msg", "Only scrape configs changed, reloading the changed jobs")
+		cfg.Setup(p.logger)
+		if err := p.targetManagers.Reload(cfg.ScrapeConfig); err != nil {
+			return err
+		}
+		if err := p.reloadServer(cfg); err != nil {
+			return err
+		}
+		p.configLoaded = newConf
+		return nil
+	}
+
 	if p.targetManagers != nil {
 		p.targetManagers.Stop()
 	}
 	if p.client != nil {
 		p.client.Stop()
 	}
+	if p.metricsWriter != nil {
+		p.metricsWriter.Stop()
+		p.metricsWriter = nil
+	}
 
 	cfg.Setup(p.logger)
 	if cfg.LimitsConfig.ReadlineRateEnabled {
@@ -192,6 +219,34 @@ func (p *Promtail) reloadConfig(cfg *config.Config) error {
 	}
 	p.targetManagers = tms
 
+	if cfg.MetricsRemoteWrite.Enabled() && !p.dryRun {
+		gatherer := prometheus.DefaultGatherer
+		if g, ok := p.reg.(prometheus.Gatherer); ok {
+			gatherer = g
+		}
+		p.metricsWriter, err = remotewrite.New(cfg.MetricsRemoteWrite, gatherer, p.rwMetrics, p.logger)
+		if err != nil {
+			return fmt.Errorf("failed to create metrics remote-write: %w", err)
+		}
+	}
+
+	if err := p.reloadServer(cfg); err != nil {
+		return err
+	}
+	p.configLoaded = newConf
+	p.baseConfigLoaded = baseConf
+	return nil
+}
+
+// baseConfig returns the config without the scrape configs, used to find out
+// if only the scrape configs changed on reload.
+func baseConfig(cfg *config.Config) string {
+	base := *cfg
+	base.ScrapeConfig = nil
+	return base.String()
+}
+
+func (p *Promtail) reloadServer(cfg *config.Config) error {
 	promServer := p.server
 	if promServer != nil {
 		promtailServer, ok := promServer.(*server.PromtailServer)
@@ -200,7 +255,6 @@ func (p *Promtail) reloadConfig(cfg *config.Config) error {
 		}
 		promtailServer.ReloadServer(p.targetManagers, cfg.String())
 	}
-	p.configLoaded = newConf
 	return nil
 }
 
@@ -242,6 +296,9 @@ func (p *Promtail) Shutdown() {
 	if p.walWriter != nil {
 		p.walWriter.Stop()
 	}
+	if p.metricsWriter != nil {
+		p.metricsWriter.Stop()
+	}
 	// todo work out the stop.
 	p.client.Stop()
 }
@@ -758,6 +758,57 @@ func Test_Reload(t *testing.T) {
 	require.Equal(t, 1.0, pb.Counter.GetValue())
 }
 
+func Test_Reload_ScrapeConfigsOnly(t *testing.T) {
+	dir := t.TempDir()
+
+	scrapeConfig := func(job string) scrapeconfig.Config {
+		return scrapeconfig.Config{
+			JobName: "file",
+			ServiceDiscoveryConfig: scrapeconfig.ServiceDiscoveryConfig{
+				StaticConfigs: discovery.StaticConfig{
+					&targetgroup.Group{
+						Targets: []model.LabelSet{{"__path__": model.LabelValue(filepath.Join(dir, "*.log"))}},
+						Labels:  model.LabelSet{"job": model.LabelValue(job)},
+					},
+				},
+			},
+		}
+	}
+	newConfig := func(host string, job string) *config.Config {
+		return &config.Config{
+			ServerConfig: pserver.Config{
+				Reload: true,
+				Config: localhostConfig,
+			},
+			ClientConfig: client.Config{URL: flagext.URLValue{URL: &url.URL{Host: host}}},
+			PositionsConfig: positions.Config{
+				PositionsFile: filepath.Join(dir, "positions.yml"),
+				SyncPeriod:    time.Second,
+			},
+			ScrapeConfig: []scrapeconfig.Config{scrapeConfig(job)},
+			TargetConfig: file2.Config{SyncPeriod: time.Second},
+		}
+	}
+
+	prometheus.DefaultRegisterer = prometheus.NewRegistry() // reset registry, otherwise you can't create 2 weavework server.
+	p, err := New(*newConfig("string", "a"), nil, clientMetrics, true, nil)
+	require.NoError(t, err)
+	defer p.Shutdown()
+
+	c, tms := p.client, p.targetManagers
+
+	// Only the scrape configs changed, the client and target managers are kept.
+	require.NoError(t, p.reloadConfig(newConfig("string", "b")))
+	require.Same(t, c, p.client)
+	require.Same(t, tms, p.targetManagers)
+	require.Equal(t, newConfig("string", "b").String(), p.configLoaded)
+
+	// The clients changed, everything is recreated.
+	require.NoError(t, p.reloadConfig(newConfig("reloadtesturl", "b")))
+	require.NotSame(t, c, p.client)
+	require.NotSame(t, tms, p.targetManagers)
+}
+
 func Test_ReloadFail_NotPanic(t *testing.T) {
 	f, err := os.CreateTemp("", "Test_Reload")
 	require.NoError(t, err)
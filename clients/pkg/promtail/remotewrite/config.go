@@ -0,0 +1,71 @@
+package remotewrite
+
+import (
+	"errors"
+	"regexp"
+	"time"
+
+	"github.com/grafana/dskit/flagext"
+	"github.com/prometheus/common/config"
+
+	lokiflag "github.com/grafana/loki/v3/pkg/util/flagext"
+)
+
+const (
+	PushInterval    = 15 * time.Second
+	Timeout         = 10 * time.Second
+	MetricNameRegex = "promtail_custom_.+"
+)
+
+// Config configures pushing the metrics created by the metrics pipeline stage
+// with Prometheus remote-write. Pushing is disabled if no URL is set.
+type Config struct {
+	URL     flagext.URLValue        `yaml:"url"`
+	Client  config.HTTPClientConfig `yaml:",inline"`
+	Headers map[string]string       `yaml:"headers,omitempty"`
+	Timeout time.Duration           `yaml:"remote_timeout"`
+
+	// How often metrics are pushed.
+	PushInterval time.Duration `yaml:"push_interval"`
+	// Only the metrics whose name matches the regular expression are pushed.
+	MetricNameRegex string `yaml:"metric_name_regex"`
+	// Labels removed from the series before pushing them. Series which only
+	// differ by those labels are summed together.
+	AggregateWithout []string `yaml:"aggregate_without,omitempty"`
+	// Labels added to every pushed series.
+	ExternalLabels lokiflag.LabelSet `yaml:"external_labels,omitempty"`
+}
+
+// Enabled returns whether metrics should be pushed.
+func (c *Config) Enabled() bool {
+	return c.URL.URL != nil
+}
+
+// UnmarshalYAML implements the yaml.Unmarshaler interface.
+func (c *Config) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	type raw Config
+	cfg := raw{
+		Client:          config.DefaultHTTPClientConfig,
+		Timeout:         Timeout,
+		PushInterval:    PushInterval,
+		MetricNameRegex: MetricNameRegex,
+	}
+	if err := unmarshal(&cfg); err != nil {
+		return err
+	}
+
+	// explicitly call Validate on HTTPClientConfig as it's UnmarshalYAML
+	// method doesn't get invoked given that it's not a pointer.
+	if err := cfg.Client.Validate(); err != nil {
+		return err
+	}
+	if cfg.PushInterval <= 0 {
+		return errors.New("push_interval must be greater than 0")
+	}
+	if _, err := regexp.Compile(cfg.MetricNameRegex); err != nil {
+		return err
+	}
+
+	*c = Config(cfg)
+	return nil
+}
@@ -0,0 +1,389 @@
+package remotewrite
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"regexp"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/gogo/protobuf/proto"
+	"github.com/golang/snappy"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/config"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/prompb"
+
+	"github.com/grafana/loki/v3/clients/pkg/promtail/client"
+
+	lokiutil "github.com/grafana/loki/v3/pkg/util"
+)
+
+const maxErrMsgLen = 1024
+
+type Metrics struct {
+	sentSamples  prometheus.Counter
+	failedPushes prometheus.Counter
+}
+
+func NewMetrics(reg prometheus.Registerer) *Metrics {
+	m := &Metrics{
+		sentSamples: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "promtail",
+			Name:      "metrics_remote_write_sent_samples_total",
+			Help:      "Number of pipeline metrics samples pushed with remote-write.",
+		}),
+		failedPushes: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "promtail",
+			Name:      "metrics_remote_write_failed_pushes_total",
+			Help:      "Number of failed remote-write pushes of pipeline metrics.",
+		}),
+	}
+	if reg != nil {
+		m.sentSamples = mustRegisterOrGet(reg, m.sentSamples).(prometheus.Counter)
+		m.failedPushes = mustRegisterOrGet(reg, m.failedPushes).(prometheus.Counter)
+	}
+	return m
+}
+
+func mustRegisterOrGet(reg prometheus.Registerer, c prometheus.Collector) prometheus.Collector {
+	if err := reg.Register(c); err != nil {
+		if are, ok := err.(prometheus.AlreadyRegisteredError); ok {
+			return are.ExistingCollector
+		}
+		panic(err)
+	}
+	return c
+}
+
+// Writer periodically gathers the metrics created by the metrics pipeline
+// stage, aggregates them and pushes them with Prometheus remote-write, so
+// that they don't need to be scraped from every Promtail.
+type Writer struct {
+	cfg      Config
+	gatherer prometheus.Gatherer
+	client   *http.Client
+	metrics  *Metrics
+	logger   log.Logger
+	nameRe   *regexp.Regexp
+	without  map[string]struct{}
+	// totals is only accessed from the run loop.
+	totals *counterTotals
+
+	quit chan struct{}
+	done chan struct{}
+}
+
+// New creates a Writer and starts pushing metrics.
+func New(cfg Config, gatherer prometheus.Gatherer, metrics *Metrics, logger log.Logger) (*Writer, error) {
+	nameRe, err := regexp.Compile("^(?:" + cfg.MetricNameRegex + ")$")
+	if err != nil {
+		return nil, fmt.Errorf("invalid metric_name_regex: %w", err)
+	}
+
+	httpClient, err := config.NewClientFromConfig(cfg.Client, "promtail-metrics")
+	if err != nil {
+		return nil, err
+	}
+	httpClient.Timeout = cfg.Timeout
+
+	without := make(map[string]struct{}, len(cfg.AggregateWithout))
+	for _, l := range cfg.AggregateWithout {
+		without[l] = struct{}{}
+	}
+
+	w := &Writer{
+		cfg:      cfg,
+		gatherer: gatherer,
+		client:   httpClient,
+		metrics:  metrics,
+		logger:   log.With(logger, "component", "metrics_remote_write", "host", cfg.URL.Host),
+		nameRe:   nameRe,
+		without:  without,
+		totals:   newCounterTotals(),
+		quit:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	go w.run()
+	return w, nil
+}
+
+func (w *Writer) run() {
+	defer close(w.done)
+
+	ticker := time.NewTicker(w.cfg.PushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			w.push()
+		case <-w.quit:
+			// Push the latest values before stopping.
+			w.push()
+			return
+		}
+	}
+}
+
+// Stop pushes the metrics one last time and stops the Writer.
+func (w *Writer) Stop() {
+	close(w.quit)
+	<-w.done
+}
+
+func (w *Writer) push() {
+	series, err := w.collect(time.Now())
+	if err != nil {
+		level.Error(w.logger).Log("msg", "error gathering metrics", "err", err)
+		return
+	}
+	if len(series) == 0 {
+		return
+	}
+
+	if err := w.send(series); err != nil {
+		w.metrics.failedPushes.Inc()
+		level.Warn(w.logger).Log("msg", "error pushing metrics", "err", err)
+		return
+	}
+	w.metrics.sentSamples.Add(float64(len(series)))
+}
+
+// collect gathers the matching metrics and returns one sample per series,
+// after removing the aggregated labels.
+func (w *Writer) collect(now time.Time) ([]prompb.TimeSeries, error) {
+	families, err := w.gatherer.Gather()
+	if err != nil {
+		return nil, err
+	}
+
+	agg := newAggregator(w.cfg.ExternalLabels.LabelSet, w.without, w.totals, now.UnixMilli())
+	for _, mf := range families {
+		name := mf.GetName()
+		if !w.nameRe.MatchString(name) {
+			continue
+		}
+		for _, m := range mf.GetMetric() {
+			switch mf.GetType() {
+			case dto.MetricType_COUNTER:
+				agg.addCounter(name, m.GetLabel(), "", "", m.GetCounter().GetValue())
+			case dto.MetricType_GAUGE:
+				agg.addGauge(name, m.GetLabel(), m.GetGauge().GetValue())
+			case dto.MetricType_UNTYPED:
+				agg.addGauge(name, m.GetLabel(), m.GetUntyped().GetValue())
+			case dto.MetricType_HISTOGRAM:
+				h := m.GetHistogram()
+				for _, b := range h.GetBucket() {
+					agg.addCounter(name+"_bucket", m.GetLabel(), model.BucketLabel, formatFloat(b.GetUpperBound()), float64(b.GetCumulativeCount()))
+				}
+				agg.addCounter(name+"_bucket", m.GetLabel(), model.BucketLabel, formatFloat(math.Inf(1)), float64(h.GetSampleCount()))
+				agg.addCounter(name+"_sum", m.GetLabel(), "", "", h.GetSampleSum())
+				agg.addCounter(name+"_count", m.GetLabel(), "", "", float64(h.GetSampleCount()))
+			case dto.MetricType_SUMMARY:
+				// Quantiles can't be aggregated, only the sum and count are pushed.
+				s := m.GetSummary()
+				agg.addCounter(name+"_sum", m.GetLabel(), "", "", s.GetSampleSum())
+				agg.addCounter(name+"_count", m.GetLabel(), "", "", float64(s.GetSampleCount()))
+			}
+		}
+	}
+	return agg.series(), nil
+}
+
+func (w *Writer) send(series []prompb.TimeSeries) error {
+	buf, err := proto.Marshal(&prompb.WriteRequest{Timeseries: series})
+	if err != nil {
+		return err
+	}
+	buf = snappy.Encode(nil, buf)
+
+	ctx, cancel := context.WithTimeout(context.Background(), w.cfg.Timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.cfg.URL.String(), bytes.NewReader(buf))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	req.Header.Set("Content-Encoding", "snappy")
+	req.Header.Set("X-Prometheus-Remote-Write-Version", "0.1.0")
+	req.Header.Set("User-Agent", client.UserAgent)
+	for k, v := range w.cfg.Headers {
+		if req.Header.Get(k) == "" {
+			req.Header.Add(k, v)
+		}
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer lokiutil.LogError("closing response body", resp.Body.Close)
+
+	if resp.StatusCode/100 != 2 {
+		scanner := bufio.NewScanner(io.LimitReader(resp.Body, maxErrMsgLen))
+		line := ""
+		if scanner.Scan() {
+			line = scanner.Text()
+		}
+		return fmt.Errorf("server returned HTTP status %s (%d): %s", resp.Status, resp.StatusCode, line)
+	}
+	return nil
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+// totalsTTL is the duration after which the total of an aggregated counter
+// that wasn't collected anymore is dropped. It matches the default lookback
+// delta of Prometheus, after which the series is stale anyway, so the reset
+// of the total when the series comes back doesn't matter.
+const totalsTTL = 5 * time.Minute
+
+// counterTotals keeps the totals of the aggregated counters across
+// collections. A total is increased by the increase of each of its source
+// series since the previous collection, so it never decreases when a source
+// series is reset or expires, which would be seen as a counter reset.
+type counterTotals struct {
+	// last holds the last value of each source series.
+	last map[string]float64
+	// totals holds the running total of each aggregated series.
+	totals map[string]float64
+	// seen holds the time each aggregated series was last collected, in
+	// milliseconds.
+	seen map[string]int64
+}
+
+func newCounterTotals() *counterTotals {
+	return &counterTotals{
+		last:   map[string]float64{},
+		totals: map[string]float64{},
+		seen:   map[string]int64{},
+	}
+}
+
+// prune drops the totals of the aggregated series which weren't collected
+// within totalsTTL.
+func (t *counterTotals) prune(ts int64) {
+	for key, seen := range t.seen {
+		if ts-seen > totalsTTL.Milliseconds() {
+			delete(t.totals, key)
+			delete(t.seen, key)
+		}
+	}
+}
+
+// aggregator sums the values of the series sharing the same labels once the
+// aggregated labels are removed. Gauges are summed as is, counters through
+// their running totals.
+type aggregator struct {
+	external model.LabelSet
+	without  map[string]struct{}
+	builder  *labels.Builder
+	values   map[string]float64
+	labels   map[string]labels.Labels
+
+	totals *counterTotals
+	// last holds the value of the source counters seen in this collection.
+	last map[string]float64
+	// ts is the time of the collection, in milliseconds.
+	ts int64
+}
+
+func newAggregator(external model.LabelSet, without map[string]struct{}, totals *counterTotals, ts int64) *aggregator {
+	return &aggregator{
+		ts:       ts,
+		external: external,
+		without:  without,
+		builder:  labels.NewBuilder(labels.EmptyLabels()),
+		values:   map[string]float64{},
+		labels:   map[string]labels.Labels{},
+		totals:   totals,
+		last:     map[string]float64{},
+	}
+}
+
+func (a *aggregator) addGauge(name string, pairs []*dto.LabelPair, value float64) {
+	key := a.key(name, pairs, "", "", true)
+	a.values[key] += value
+}
+
+func (a *aggregator) addCounter(name string, pairs []*dto.LabelPair, extraName, extraValue string, value float64) {
+	key := a.key(name, pairs, extraName, extraValue, true)
+	source := a.key(name, pairs, extraName, extraValue, false)
+
+	increase := value
+	if last, ok := a.totals.last[source]; ok && value >= last {
+		increase = value - last
+	}
+	a.last[source] = value
+	a.totals.totals[key] += increase
+	a.totals.seen[key] = a.ts
+	a.values[key] = a.totals.totals[key]
+}
+
+// key returns the labels of a series as a string, without the aggregated
+// labels if aggregate is true. The labels of the aggregated series are kept to
+// build the pushed series.
+func (a *aggregator) key(name string, pairs []*dto.LabelPair, extraName, extraValue string, aggregate bool) string {
+	a.builder.Reset(labels.EmptyLabels())
+	for _, p := range pairs {
+		if _, ok := a.without[p.GetName()]; ok && aggregate {
+			continue
+		}
+		a.builder.Set(p.GetName(), p.GetValue())
+	}
+	for k, v := range a.external {
+		a.builder.Set(string(k), string(v))
+	}
+	if extraName != "" {
+		a.builder.Set(extraName, extraValue)
+	}
+	a.builder.Set(model.MetricNameLabel, name)
+
+	lbls := a.builder.Labels()
+	key := lbls.String()
+	if _, ok := a.labels[key]; !ok && aggregate {
+		a.labels[key] = lbls
+	}
+	return key
+}
+
+// series returns the aggregated series. It must be called once all the
+// series of the collection have been added.
+func (a *aggregator) series() []prompb.TimeSeries {
+	// Source counters that are gone are forgotten, if they come back they
+	// start from 0.
+	a.totals.last = a.last
+	a.totals.prune(a.ts)
+
+	keys := make([]string, 0, len(a.values))
+	for k := range a.values {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+
+	series := make([]prompb.TimeSeries, 0, len(keys))
+	for _, k := range keys {
+		var pbLabels []prompb.Label
+		a.labels[k].Range(func(l labels.Label) {
+			pbLabels = append(pbLabels, prompb.Label{Name: l.Name, Value: l.Value})
+		})
+		series = append(series, prompb.TimeSeries{
+			Labels:  pbLabels,
+			Samples: []prompb.Sample{{Value: a.values[k], Timestamp: a.ts}},
+		})
+	}
+	return series
+}
@@ -0,0 +1,186 @@
+package remotewrite
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/gogo/protobuf/proto"
+	"github.com/golang/snappy"
+	"github.com/grafana/dskit/flagext"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/prompb"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v2"
+
+	lokiflag "github.com/grafana/loki/v3/pkg/util/flagext"
+)
+
+func TestConfig(t *testing.T) {
+	var cfg Config
+	require.NoError(t, yaml.Unmarshal([]byte(`
+url: http://localhost:9090/api/v1/write
+aggregate_without: [filename]
+`), &cfg))
+	require.True(t, cfg.Enabled())
+	require.Equal(t, PushInterval, cfg.PushInterval)
+	require.Equal(t, MetricNameRegex, cfg.MetricNameRegex)
+	require.Equal(t, []string{"filename"}, cfg.AggregateWithout)
+
+	require.Error(t, yaml.Unmarshal([]byte(`metric_name_regex: "("`), &cfg))
+	require.Error(t, yaml.Unmarshal([]byte(`push_interval: -1s`), &cfg))
+}
+
+func TestWriter(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	lines := prometheus.NewCounterVec(prometheus.CounterOpts{Name: "promtail_custom_lines_total"}, []string{"job", "filename"})
+	latency := prometheus.NewHistogramVec(prometheus.HistogramOpts{Name: "promtail_custom_latency_seconds", Buckets: []float64{1}}, []string{"filename"})
+	other := prometheus.NewCounter(prometheus.CounterOpts{Name: "promtail_read_lines_total"})
+	reg.MustRegister(lines, latency, other)
+
+	lines.WithLabelValues("app", "a.log").Add(2)
+	lines.WithLabelValues("app", "b.log").Add(3)
+	latency.WithLabelValues("a.log").Observe(0.5)
+	latency.WithLabelValues("b.log").Observe(2)
+	other.Inc()
+
+	received := make(chan prompb.WriteRequest, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, req *http.Request) {
+		compressed, err := io.ReadAll(req.Body)
+		require.NoError(t, err)
+		buf, err := snappy.Decode(nil, compressed)
+		require.NoError(t, err)
+		var writeReq prompb.WriteRequest
+		require.NoError(t, proto.Unmarshal(buf, &writeReq))
+		received <- writeReq
+	}))
+	defer server.Close()
+
+	var serverURL flagext.URLValue
+	require.NoError(t, serverURL.Set(server.URL))
+
+	metrics := NewMetrics(prometheus.NewRegistry())
+	w, err := New(Config{
+		URL:              serverURL,
+		Timeout:          time.Second,
+		PushInterval:     time.Hour,
+		MetricNameRegex:  MetricNameRegex,
+		AggregateWithout: []string{"filename"},
+		ExternalLabels:   lokiflag.LabelSet{LabelSet: model.LabelSet{"cluster": "edge"}},
+	}, reg, metrics, log.NewNopLogger())
+	require.NoError(t, err)
+	// Stopping the writer pushes the metrics one last time.
+	w.Stop()
+
+	req := <-received
+	got := map[string]float64{}
+	for _, ts := range req.Timeseries {
+		got[prompbLabelsString(ts.Labels)] = ts.Samples[0].Value
+	}
+	require.Equal(t, map[string]float64{
+		`{__name__="promtail_custom_latency_seconds_bucket", cluster="edge", le="+Inf"}`: 2,
+		`{__name__="promtail_custom_latency_seconds_bucket", cluster="edge", le="1"}`:    1,
+		`{__name__="promtail_custom_latency_seconds_count", cluster="edge"}`:             2,
+		`{__name__="promtail_custom_latency_seconds_sum", cluster="edge"}`:               2.5,
+		`{__name__="promtail_custom_lines_total", cluster="edge", job="app"}`:            5,
+	}, got)
+	require.Equal(t, float64(5), testutil.ToFloat64(metrics.sentSamples))
+}
+
+func TestWriter_CountersNeverDecrease(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	lines := prometheus.NewCounterVec(prometheus.CounterOpts{Name: "promtail_custom_lines_total"}, []string{"job", "filename"})
+	reg.MustRegister(lines)
+
+	w := &Writer{
+		gatherer: reg,
+		nameRe:   regexp.MustCompile("^(?:" + MetricNameRegex + ")$"),
+		without:  map[string]struct{}{"filename": {}},
+		totals:   newCounterTotals(),
+	}
+	collect := func() float64 {
+		series, err := w.collect(time.Now())
+		require.NoError(t, err)
+		require.Len(t, series, 1)
+		return series[0].Samples[0].Value
+	}
+
+	lines.WithLabelValues("app", "a.log").Add(2)
+	lines.WithLabelValues("app", "b.log").Add(3)
+	require.Equal(t, float64(5), collect())
+
+	// A source series expiring doesn't decrease the total.
+	lines.WithLabelValues("app", "a.log").Add(1)
+	lines.DeleteLabelValues("app", "b.log")
+	require.Equal(t, float64(6), collect())
+
+	// When it comes back, it starts from 0.
+	lines.WithLabelValues("app", "b.log").Add(4)
+	require.Equal(t, float64(10), collect())
+
+	// The total isn't pushed while it has no source series, and it resumes
+	// from where it was once they come back.
+	lines.DeleteLabelValues("app", "a.log")
+	lines.DeleteLabelValues("app", "b.log")
+	series, err := w.collect(time.Now())
+	require.NoError(t, err)
+	require.Empty(t, series)
+	lines.WithLabelValues("app", "a.log").Add(1)
+	require.Equal(t, float64(11), collect())
+}
+
+func TestWriter_StaleTotalsArePruned(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	lines := prometheus.NewCounterVec(prometheus.CounterOpts{Name: "promtail_custom_lines_total"}, []string{"job", "filename"})
+	reg.MustRegister(lines)
+
+	w := &Writer{
+		gatherer: reg,
+		nameRe:   regexp.MustCompile("^(?:" + MetricNameRegex + ")$"),
+		without:  map[string]struct{}{"filename": {}},
+		totals:   newCounterTotals(),
+	}
+	now := time.Now()
+	collect := func() {
+		_, err := w.collect(now)
+		require.NoError(t, err)
+	}
+
+	lines.WithLabelValues("a", "a.log").Add(2)
+	lines.WithLabelValues("b", "b.log").Add(3)
+	collect()
+	require.Len(t, w.totals.totals, 2)
+
+	// The total of a series that isn't collected anymore is kept within the
+	// TTL, and dropped after it.
+	lines.DeleteLabelValues("a", "a.log")
+	now = now.Add(totalsTTL)
+	collect()
+	require.Len(t, w.totals.totals, 2)
+
+	now = now.Add(time.Second)
+	collect()
+	require.Len(t, w.totals.totals, 1)
+	require.Len(t, w.totals.seen, 1)
+
+	// When it comes back, it starts from 0.
+	lines.WithLabelValues("a", "a.log").Add(1)
+	series, err := w.collect(now)
+	require.NoError(t, err)
+	require.Len(t, series, 2)
+	require.Equal(t, float64(1), series[0].Samples[0].Value)
+}
+
+func prompbLabelsString(lbls []prompb.Label) string {
+	ls := model.LabelSet{}
+	for _, l := range lbls {
+		ls[model.LabelName(l.Name)] = model.LabelValue(l.Value)
+	}
+	return ls.String()
+}
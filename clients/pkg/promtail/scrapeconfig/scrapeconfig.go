@@ -33,19 +33,21 @@ import (
 
 // Config describes a job to scrape.
 type Config struct {
-	JobName              string                      `mapstructure:"job_name,omitempty" yaml:"job_name,omitempty"`
-	PipelineStages       stages.PipelineStages       `mapstructure:"pipeline_stages,omitempty" yaml:"pipeline_stages,omitempty"`
-	JournalConfig        *JournalTargetConfig        `mapstructure:"journal,omitempty" yaml:"journal,omitempty"`
-	SyslogConfig         *SyslogTargetConfig         `mapstructure:"syslog,omitempty" yaml:"syslog,omitempty"`
-	GcplogConfig         *GcplogTargetConfig         `mapstructure:"gcplog,omitempty" yaml:"gcplog,omitempty"`
-	PushConfig           *PushTargetConfig           `mapstructure:"loki_push_api,omitempty" yaml:"loki_push_api,omitempty"`
-	WindowsConfig        *WindowsEventsTargetConfig  `mapstructure:"windows_events,omitempty" yaml:"windows_events,omitempty"`
-	KafkaConfig          *KafkaTargetConfig          `mapstructure:"kafka,omitempty" yaml:"kafka,omitempty"`
-	AzureEventHubsConfig *AzureEventHubsTargetConfig `mapstructure:"azure_event_hubs,omitempty" yaml:"azure_event_hubs,omitempty"`
-	GelfConfig           *GelfTargetConfig           `mapstructure:"gelf,omitempty" yaml:"gelf,omitempty"`
-	CloudflareConfig     *CloudflareConfig           `mapstructure:"cloudflare,omitempty" yaml:"cloudflare,omitempty"`
-	HerokuDrainConfig    *HerokuDrainTargetConfig    `mapstructure:"heroku_drain,omitempty" yaml:"heroku_drain,omitempty"`
-	RelabelConfigs       []*relabel.Config           `mapstructure:"relabel_configs,omitempty" yaml:"relabel_configs,omitempty"`
+	JobName                string                        `mapstructure:"job_name,omitempty" yaml:"job_name,omitempty"`
+	PipelineStages         stages.PipelineStages         `mapstructure:"pipeline_stages,omitempty" yaml:"pipeline_stages,omitempty"`
+	JournalConfig          *JournalTargetConfig          `mapstructure:"journal,omitempty" yaml:"journal,omitempty"`
+	SyslogConfig           *SyslogTargetConfig           `mapstructure:"syslog,omitempty" yaml:"syslog,omitempty"`
+	GcplogConfig           *GcplogTargetConfig           `mapstructure:"gcplog,omitempty" yaml:"gcplog,omitempty"`
+	PushConfig             *PushTargetConfig             `mapstructure:"loki_push_api,omitempty" yaml:"loki_push_api,omitempty"`
+	WindowsConfig          *WindowsEventsTargetConfig    `mapstructure:"windows_events,omitempty" yaml:"windows_events,omitempty"`
+	KafkaConfig            *KafkaTargetConfig            `mapstructure:"kafka,omitempty" yaml:"kafka,omitempty"`
+	AzureEventHubsConfig   *AzureEventHubsTargetConfig   `mapstructure:"azure_event_hubs,omitempty" yaml:"azure_event_hubs,omitempty"`
+	GelfConfig             *GelfTargetConfig             `mapstructure:"gelf,omitempty" yaml:"gelf,omitempty"`
+	CloudflareConfig       *CloudflareConfig             `mapstructure:"cloudflare,omitempty" yaml:"cloudflare,omitempty"`
+	HerokuDrainConfig      *HerokuDrainTargetConfig      `mapstructure:"heroku_drain,omitempty" yaml:"heroku_drain,omitempty"`
+	KubernetesEventsConfig *KubernetesEventsTargetConfig `mapstructure:"kubernetes_events,omitempty" yaml:"kubernetes_events,omitempty"`
+	KubernetesAuditConfig  *KubernetesAuditTargetConfig  `mapstructure:"kubernetes_audit,omitempty" yaml:"kubernetes_audit,omitempty"`
+	RelabelConfigs         []*relabel.Config             `mapstructure:"relabel_configs,omitempty" yaml:"relabel_configs,omitempty"`
 	// List of Docker service discovery configurations.
 	DockerSDConfigs        []*moby.DockerSDConfig `mapstructure:"docker_sd_configs,omitempty" yaml:"docker_sd_configs,omitempty"`
 	ServiceDiscoveryConfig ServiceDiscoveryConfig `mapstructure:",squash" yaml:",inline"`
@@ -446,6 +448,37 @@ type HerokuDrainTargetConfig struct {
 	UseIncomingTimestamp bool `yaml:"use_incoming_timestamp"`
 }
 
+// KubernetesEventsTargetConfig describes a scrape config to watch events from the Kubernetes Events API.
+type KubernetesEventsTargetConfig struct {
+	// KubeConfigFile is the path of a kubeconfig file used to connect to the API server.
+	// If empty, the in-cluster configuration is used.
+	KubeConfigFile string `yaml:"kubeconfig_file"`
+
+	// Namespaces to watch events from. If empty, events from all namespaces are watched.
+	Namespaces []string `yaml:"namespaces"`
+
+	// Labels optionally holds labels to associate with each event.
+	Labels model.LabelSet `yaml:"labels"`
+
+	// UseIncomingTimestamp sets the timestamp to the time the event was last observed. If false,
+	// promtail will assign the current timestamp to the log entry when it was processed.
+	UseIncomingTimestamp bool `yaml:"use_incoming_timestamp"`
+}
+
+// KubernetesAuditTargetConfig describes a scrape config to receive audit events from the
+// Kubernetes API server audit webhook backend.
+type KubernetesAuditTargetConfig struct {
+	// Server is the weaveworks server config for listening connections
+	Server server.Config `yaml:"server"`
+
+	// Labels optionally holds labels to associate with each audit event.
+	Labels model.LabelSet `yaml:"labels"`
+
+	// UseIncomingTimestamp sets the timestamp to the stage timestamp of the audit event. If false,
+	// promtail will assign the current timestamp to the log entry when it was processed.
+	UseIncomingTimestamp bool `yaml:"use_incoming_timestamp"`
+}
+
 // PushTargetConfig describes a scrape config that listens for Loki push messages.
 type PushTargetConfig struct {
 	// Server is the weaveworks server config for listening connections
@@ -19,6 +19,7 @@ import (
 	"github.com/prometheus/prometheus/discovery/targetgroup"
 	"github.com/prometheus/prometheus/model/labels"
 	"github.com/prometheus/prometheus/model/relabel"
+	"gopkg.in/yaml.v2"
 
 	"github.com/grafana/loki/v3/clients/pkg/logentry/stages"
 	"github.com/grafana/loki/v3/clients/pkg/promtail/api"
@@ -42,12 +43,26 @@ const (
 type FileTargetManager struct {
 	log     log.Logger
 	quit    context.CancelFunc
-	syncers map[string]*targetSyncer
 	manager *discovery.Manager
 
+	// mtx protects syncers and configs, which are replaced on reload.
+	mtx     sync.RWMutex
+	syncers map[string]*targetSyncer
+	// configs holds the marshalled scrape config of each job, used to find
+	// out which jobs changed on reload.
+	configs map[string]string
+
 	watcher            *fsnotify.Watcher
 	targetEventHandler chan fileTargetEvent
 
+	metrics      *Metrics
+	reg          prometheus.Registerer
+	positions    positions.Positions
+	client       api.EntryHandler
+	targetConfig *Config
+	watchConfig  WatchConfig
+	hostname     string
+
 	wg sync.WaitGroup
 }
 
@@ -76,6 +91,11 @@ func NewFileTargetManager(
 	if err != nil {
 		return nil, err
 	}
+	hostname, err := hostname()
+	if err != nil {
+		return nil, err
+	}
+
 	ctx, quit := context.WithCancel(context.Background())
 	tm := &FileTargetManager{
 		log:                logger,
@@ -83,79 +103,144 @@ func NewFileTargetManager(
 		watcher:            watcher,
 		targetEventHandler: make(chan fileTargetEvent),
 		syncers:            map[string]*targetSyncer{},
+		configs:            map[string]string{},
 		manager: discovery.NewManager(
 			ctx,
 			util_log.SlogFromGoKit(log.With(logger, "component", "discovery")),
 			noopRegistry,
 			noopSdMetrics,
 		),
+		metrics:      metrics,
+		reg:          reg,
+		positions:    positions,
+		client:       client,
+		targetConfig: targetConfig,
+		watchConfig:  watchConfig,
+		hostname:     hostname,
 	}
 
-	hostname, err := hostname()
-	if err != nil {
+	tm.wg.Add(3)
+	go tm.run(ctx)
+	go tm.watchTargetEvents(ctx)
+	go tm.watchFsEvents(ctx)
+
+	go util.LogError("running target manager", tm.manager.Run)
+
+	if err := tm.Reload(scrapeConfigs); err != nil {
+		tm.Stop()
 		return nil, err
 	}
+	return tm, nil
+}
 
-	configs := map[string]discovery.Configs{}
+// Reload applies new scrape configs. Only the jobs which were added, removed or
+// changed are (re)started, the others keep their targets. If a job can't be
+// created, the running jobs are left untouched.
+func (tm *FileTargetManager) Reload(scrapeConfigs []scrapeconfig.Config) error {
+	configs := make(map[string]string, len(scrapeConfigs))
+	added := map[string]*targetSyncer{}
 	for _, cfg := range scrapeConfigs {
 		if !cfg.HasServiceDiscoveryConfig() {
 			continue
 		}
-
-		pipeline, err := stages.NewPipeline(log.With(logger, "component", "file_pipeline"), cfg.PipelineStages, &cfg.JobName, reg)
+		// The config is marshalled before newSyncer sets its defaults, so
+		// that it can be compared with the next reload.
+		out, err := yaml.Marshal(cfg)
 		if err != nil {
-			return nil, err
+			return fmt.Errorf("failed to marshal scrape config %q: %w", cfg.JobName, err)
 		}
+		configs[cfg.JobName] = string(out)
 
-		// Add Source value to the static config target groups for unique identification
-		// within scrape pool. Also, default target label to localhost if target is not
-		// defined in promtail config.
-		// Just to make sure prometheus target group sync works fine.
-		for i, tg := range cfg.ServiceDiscoveryConfig.StaticConfigs {
-			tg.Source = fmt.Sprintf("%d", i)
-			if len(tg.Targets) == 0 {
-				tg.Targets = []model.LabelSet{
-					{model.AddressLabel: "localhost"},
-				}
-			}
+		tm.mtx.RLock()
+		current, ok := tm.configs[cfg.JobName]
+		tm.mtx.RUnlock()
+		if ok && current == configs[cfg.JobName] {
+			continue
 		}
-
-		// Add an additional api-level node filtering, so we only fetch pod metadata for
-		// all the pods from the current node. Without this filtering we will have to
-		// download metadata for all pods running on a cluster, which may be a long operation.
-		for _, kube := range cfg.ServiceDiscoveryConfig.KubernetesSDConfigs {
-			if kube.Role == kubernetes.RolePod {
-				kube.Selectors = tm.fulfillKubePodSelector(kube.Selectors, hostname)
+		s, err := tm.newSyncer(cfg)
+		if err != nil {
+			for _, s := range added {
+				s.stop()
 			}
+			return err
 		}
+		added[cfg.JobName] = s
+	}
 
-		s := &targetSyncer{
-			metrics:           metrics,
-			log:               logger,
-			positions:         positions,
-			relabelConfig:     cfg.RelabelConfigs,
-			targets:           map[string]*FileTarget{},
-			droppedTargets:    []target.Target{},
-			hostname:          hostname,
-			entryHandler:      pipeline.Wrap(client),
-			targetConfig:      targetConfig,
-			watchConfig:       watchConfig,
-			fileEventWatchers: map[string]chan fsnotify.Event{},
-			encoding:          cfg.Encoding,
-			decompressCfg:     cfg.DecompressionCfg,
+	sdConfigs := make(map[string]discovery.Configs, len(configs))
+	var removed []*targetSyncer
+	tm.mtx.Lock()
+	for name, s := range tm.syncers {
+		if _, ok := added[name]; ok {
+			removed = append(removed, s)
+		} else if _, ok := configs[name]; !ok {
+			removed = append(removed, s)
+			delete(tm.syncers, name)
+		}
+	}
+	for name, s := range added {
+		tm.syncers[name] = s
+	}
+	tm.configs = configs
+	for _, cfg := range scrapeConfigs {
+		if _, ok := configs[cfg.JobName]; ok {
+			sdConfigs[cfg.JobName] = cfg.ServiceDiscoveryConfig.Configs()
 		}
-		tm.syncers[cfg.JobName] = s
-		configs[cfg.JobName] = cfg.ServiceDiscoveryConfig.Configs()
 	}
+	tm.mtx.Unlock()
 
-	tm.wg.Add(3)
-	go tm.run(ctx)
-	go tm.watchTargetEvents(ctx)
-	go tm.watchFsEvents(ctx)
+	// The replaced syncers are stopped outside of the lock, since stopping
+	// their targets waits for the watchers.
+	for _, s := range removed {
+		s.stop()
+	}
+	return tm.manager.ApplyConfig(sdConfigs)
+}
 
-	go util.LogError("running target manager", tm.manager.Run)
+// newSyncer creates the target syncer of a scrape config.
+func (tm *FileTargetManager) newSyncer(cfg scrapeconfig.Config) (*targetSyncer, error) {
+	pipeline, err := stages.NewPipeline(log.With(tm.log, "component", "file_pipeline"), cfg.PipelineStages, &cfg.JobName, tm.reg)
+	if err != nil {
+		return nil, err
+	}
 
-	return tm, tm.manager.ApplyConfig(configs)
+	// Add Source value to the static config target groups for unique identification
+	// within scrape pool. Also, default target label to localhost if target is not
+	// defined in promtail config.
+	// Just to make sure prometheus target group sync works fine.
+	for i, tg := range cfg.ServiceDiscoveryConfig.StaticConfigs {
+		tg.Source = fmt.Sprintf("%d", i)
+		if len(tg.Targets) == 0 {
+			tg.Targets = []model.LabelSet{
+				{model.AddressLabel: "localhost"},
+			}
+		}
+	}
+
+	// Add an additional api-level node filtering, so we only fetch pod metadata for
+	// all the pods from the current node. Without this filtering we will have to
+	// download metadata for all pods running on a cluster, which may be a long operation.
+	for _, kube := range cfg.ServiceDiscoveryConfig.KubernetesSDConfigs {
+		if kube.Role == kubernetes.RolePod {
+			kube.Selectors = tm.fulfillKubePodSelector(kube.Selectors, tm.hostname)
+		}
+	}
+
+	return &targetSyncer{
+		metrics:           tm.metrics,
+		log:               tm.log,
+		positions:         tm.positions,
+		relabelConfig:     cfg.RelabelConfigs,
+		targets:           map[string]*FileTarget{},
+		droppedTargets:    []target.Target{},
+		hostname:          tm.hostname,
+		entryHandler:      pipeline.Wrap(tm.client),
+		targetConfig:      tm.targetConfig,
+		watchConfig:       tm.watchConfig,
+		fileEventWatchers: map[string]chan fsnotify.Event{},
+		encoding:          cfg.Encoding,
+		decompressCfg:     cfg.DecompressionCfg,
+	}, nil
 }
 
 func (tm *FileTargetManager) watchTargetEvents(ctx context.Context) {
@@ -191,9 +276,11 @@ func (tm *FileTargetManager) watchFsEvents(ctx context.Context) {
 			// we only care about Create events
 			if event.Op == fsnotify.Create {
 				level.Info(tm.log).Log("msg", "received file watcher event", "name", event.Name, "op", event.Op.String())
+				tm.mtx.RLock()
 				for _, s := range tm.syncers {
 					s.sendFileCreateEvent(event)
 				}
+				tm.mtx.RUnlock()
 			}
 		case err := <-tm.watcher.Errors:
 			level.Error(tm.log).Log("msg", "error from fswatch", "error", err)
@@ -209,9 +296,16 @@ func (tm *FileTargetManager) run(ctx context.Context) {
 	for {
 		select {
 		case targetGroups := <-tm.manager.SyncCh():
+			// The lock is held while syncing so that a syncer isn't synced
+			// after it was replaced on reload.
+			tm.mtx.RLock()
 			for jobName, groups := range targetGroups {
-				tm.syncers[jobName].sync(groups, tm.targetEventHandler)
+				// The groups of a job removed on reload can still be received.
+				if s, ok := tm.syncers[jobName]; ok {
+					s.sync(groups, tm.targetEventHandler)
+				}
 			}
+			tm.mtx.RUnlock()
 		case <-ctx.Done():
 			return
 		}
@@ -220,6 +314,8 @@ func (tm *FileTargetManager) run(ctx context.Context) {
 
 // Ready if there's at least one file target
 func (tm *FileTargetManager) Ready() bool {
+	tm.mtx.RLock()
+	defer tm.mtx.RUnlock()
 	for _, s := range tm.syncers {
 		if s.ready() {
 			return true
@@ -233,6 +329,8 @@ func (tm *FileTargetManager) Stop() {
 	tm.quit()
 	tm.wg.Wait()
 
+	tm.mtx.Lock()
+	defer tm.mtx.Unlock()
 	for _, s := range tm.syncers {
 		s.stop()
 	}
@@ -242,6 +340,8 @@ func (tm *FileTargetManager) Stop() {
 
 // ActiveTargets returns the active targets currently being scraped.
 func (tm *FileTargetManager) ActiveTargets() map[string][]target.Target {
+	tm.mtx.RLock()
+	defer tm.mtx.RUnlock()
 	result := map[string][]target.Target{}
 	for jobName, syncer := range tm.syncers {
 		result[jobName] = append(result[jobName], syncer.ActiveTargets()...)
@@ -251,6 +351,8 @@ func (tm *FileTargetManager) ActiveTargets() map[string][]target.Target {
 
 // AllTargets returns all targets, active and dropped.
 func (tm *FileTargetManager) AllTargets() map[string][]target.Target {
+	tm.mtx.RLock()
+	defer tm.mtx.RUnlock()
 	result := map[string][]target.Target{}
 	for jobName, syncer := range tm.syncers {
 		result[jobName] = append(result[jobName], syncer.ActiveTargets()...)
@@ -661,3 +661,56 @@ func TestFulfillKubePodSelector(t *testing.T) {
 	ftm.Stop()
 	ps.Stop()
 }
+
+func TestFileTargetManager_Reload(t *testing.T) {
+	logDir := newTestLogDirectories(t)
+	logger := log.NewNopLogger()
+	client := fake.New(func() {})
+	defer client.Stop()
+	ps, err := newTestPositions(logger, filepath.Join(logDir, "positions.yml"))
+	require.NoError(t, err)
+	defer ps.Stop()
+
+	scrapeConfig := func(jobName, job string) scrapeconfig.Config {
+		return scrapeconfig.Config{
+			JobName: jobName,
+			ServiceDiscoveryConfig: scrapeconfig.ServiceDiscoveryConfig{
+				StaticConfigs: discovery.StaticConfig{
+					&targetgroup.Group{
+						Targets: []model.LabelSet{{"__path__": model.LabelValue(filepath.Join(logDir, jobName+".log"))}},
+						Labels:  model.LabelSet{"job": model.LabelValue(job)},
+					},
+				},
+			},
+		}
+	}
+
+	ftm, err := NewFileTargetManager(NewMetrics(nil), logger, ps, client, []scrapeconfig.Config{
+		scrapeConfig("unchanged", "a"),
+		scrapeConfig("changed", "b"),
+		scrapeConfig("removed", "c"),
+	}, &Config{SyncPeriod: time.Second}, DefaultWatchConfig)
+	require.NoError(t, err)
+	defer ftm.Stop()
+
+	unchanged := ftm.syncers["unchanged"]
+	changed := ftm.syncers["changed"]
+
+	require.NoError(t, ftm.Reload([]scrapeconfig.Config{
+		scrapeConfig("unchanged", "a"),
+		scrapeConfig("changed", "b2"),
+		scrapeConfig("added", "d"),
+	}))
+	require.Len(t, ftm.syncers, 3)
+	require.Same(t, unchanged, ftm.syncers["unchanged"])
+	require.NotSame(t, changed, ftm.syncers["changed"])
+	require.Contains(t, ftm.syncers, "added")
+	require.NotContains(t, ftm.syncers, "removed")
+
+	// A failing reload leaves the running jobs untouched.
+	invalid := scrapeConfig("invalid", "e")
+	invalid.PipelineStages = []interface{}{map[interface{}]interface{}{"unknown": nil}}
+	require.Error(t, ftm.Reload([]scrapeconfig.Config{scrapeConfig("unchanged", "a"), invalid}))
+	require.Len(t, ftm.syncers, 3)
+	require.Same(t, unchanged, ftm.syncers["unchanged"])
+}
@@ -0,0 +1,236 @@
+package kubernetes
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/grafana/dskit/server"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/model/relabel"
+
+	"github.com/grafana/loki/v3/clients/pkg/promtail/api"
+	lokiClient "github.com/grafana/loki/v3/clients/pkg/promtail/client"
+	"github.com/grafana/loki/v3/clients/pkg/promtail/scrapeconfig"
+	"github.com/grafana/loki/v3/clients/pkg/promtail/targets/serverutils"
+	"github.com/grafana/loki/v3/clients/pkg/promtail/targets/target"
+
+	"github.com/grafana/loki/v3/pkg/logproto"
+	util_log "github.com/grafana/loki/v3/pkg/util/log"
+)
+
+// AuditPath is the path the API server audit webhook backend sends events to.
+const AuditPath = "/kubernetes/api/v1/audit"
+
+// auditEventList is the body sent by the audit webhook backend, an
+// audit.k8s.io/v1 EventList. Events are kept raw to be sent as is.
+type auditEventList struct {
+	Items []json.RawMessage `json:"items"`
+}
+
+// auditEvent holds the fields of an audit.k8s.io/v1 Event used for labels.
+type auditEvent struct {
+	Level      string `json:"level"`
+	Stage      string `json:"stage"`
+	Verb       string `json:"verb"`
+	RequestURI string `json:"requestURI"`
+	User       struct {
+		Username string `json:"username"`
+	} `json:"user"`
+	ObjectRef *struct {
+		Resource    string `json:"resource"`
+		Namespace   string `json:"namespace"`
+		Name        string `json:"name"`
+		APIGroup    string `json:"apiGroup"`
+		Subresource string `json:"subresource"`
+	} `json:"objectRef"`
+	ResponseStatus *struct {
+		Code int `json:"code"`
+	} `json:"responseStatus"`
+	StageTimestamp time.Time `json:"stageTimestamp"`
+}
+
+// AuditTarget receives audit events from the Kubernetes API server audit
+// webhook backend.
+type AuditTarget struct {
+	logger         log.Logger
+	handler        api.EntryHandler
+	config         *scrapeconfig.KubernetesAuditTargetConfig
+	jobName        string
+	server         *server.Server
+	metrics        *Metrics
+	relabelConfigs []*relabel.Config
+}
+
+// NewAuditTarget creates a new Kubernetes audit target, listening for the
+// batches of audit events sent by the API server audit webhook backend.
+func NewAuditTarget(metrics *Metrics, logger log.Logger, handler api.EntryHandler, jobName string, config *scrapeconfig.KubernetesAuditTargetConfig, relabel []*relabel.Config) (*AuditTarget, error) {
+	t := &AuditTarget{
+		metrics:        metrics,
+		logger:         log.With(logger, "component", "kubernetes_audit"),
+		handler:        handler,
+		jobName:        jobName,
+		config:         config,
+		relabelConfigs: relabel,
+	}
+
+	mergedServerConfigs, err := serverutils.MergeWithDefaults(config.Server)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse configs and override defaults when configuring kubernetes audit target: %w", err)
+	}
+	// Set the config to the new combined config.
+	config.Server = mergedServerConfigs
+
+	if err := t.run(); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+func (t *AuditTarget) run() error {
+	level.Info(t.logger).Log("msg", "starting kubernetes audit target", "job", t.jobName)
+
+	// To prevent metric collisions because all metrics are going to be registered in the global Prometheus registry.
+	tentativeServerMetricNamespace := "promtail_kubernetes_audit_target_" + t.jobName
+	if !model.LabelName(tentativeServerMetricNamespace).IsValidLegacy() {
+		return fmt.Errorf("invalid prometheus-compatible job name: %s", t.jobName)
+	}
+	t.config.Server.MetricsNamespace = tentativeServerMetricNamespace
+
+	// We don't want the /debug and /metrics endpoints running, since this is not the main promtail HTTP server.
+	t.config.Server.RegisterInstrumentation = false
+
+	// Wrapping util logger with component-specific key vals, and the expected GoKit logging interface
+	t.config.Server.Log = log.With(util_log.Logger, "component", "kubernetes_audit")
+
+	srv, err := server.New(t.config.Server)
+	if err != nil {
+		return err
+	}
+
+	t.server = srv
+	t.server.HTTP.Path(AuditPath).Methods("POST").Handler(http.HandlerFunc(t.audit))
+
+	go func() {
+		err := srv.Run()
+		if err != nil {
+			level.Error(t.logger).Log("msg", "kubernetes audit target shutdown with error", "err", err)
+		}
+	}()
+
+	return nil
+}
+
+func (t *AuditTarget) audit(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	var list auditEventList
+	if err := json.NewDecoder(r.Body).Decode(&list); err != nil {
+		t.metrics.auditErrors.Inc()
+		level.Warn(t.logger).Log("msg", "failed to read incoming kubernetes audit request", "err", err.Error())
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	tenantIDHeaderValue := r.Header.Get("X-Scope-OrgID")
+	entries := t.handler.Chan()
+	for _, item := range list.Items {
+		var event auditEvent
+		if err := json.Unmarshal(item, &event); err != nil {
+			t.metrics.auditErrors.Inc()
+			level.Warn(t.logger).Log("msg", "failed to parse kubernetes audit event", "err", err.Error())
+			continue
+		}
+
+		lb := labels.NewBuilder(labels.EmptyLabels())
+		lb.Set("__meta_kubernetes_audit_level", event.Level)
+		lb.Set("__meta_kubernetes_audit_stage", event.Stage)
+		lb.Set("__meta_kubernetes_audit_verb", event.Verb)
+		lb.Set("__meta_kubernetes_audit_user", event.User.Username)
+		if event.ObjectRef != nil {
+			lb.Set("__meta_kubernetes_audit_resource", event.ObjectRef.Resource)
+			lb.Set("__meta_kubernetes_audit_subresource", event.ObjectRef.Subresource)
+			lb.Set("__meta_kubernetes_audit_namespace", event.ObjectRef.Namespace)
+			lb.Set("__meta_kubernetes_audit_name", event.ObjectRef.Name)
+			lb.Set("__meta_kubernetes_audit_api_group", event.ObjectRef.APIGroup)
+		}
+		if event.ResponseStatus != nil {
+			lb.Set("__meta_kubernetes_audit_response_code", strconv.Itoa(event.ResponseStatus.Code))
+		}
+		if tenantIDHeaderValue != "" {
+			// If present, first inject the tenant ID in, so it can be relabeled if necessary
+			lb.Set(lokiClient.ReservedLabelTenantID, tenantIDHeaderValue)
+		}
+
+		processed, _ := relabel.Process(lb.Labels(), t.relabelConfigs...)
+
+		// Start with the set of labels fixed in the configuration, and the
+		// namespace so that audit events can be found next to the pod logs.
+		filtered := t.Labels().Clone()
+		if filtered == nil {
+			filtered = model.LabelSet{}
+		}
+		if event.ObjectRef != nil && event.ObjectRef.Namespace != "" {
+			filtered[namespaceLabel] = model.LabelValue(event.ObjectRef.Namespace)
+		}
+		processed.Range(func(lbl labels.Label) {
+			if strings.HasPrefix(lbl.Name, "__") {
+				return
+			}
+			filtered[model.LabelName(lbl.Name)] = model.LabelValue(lbl.Value)
+		})
+
+		// Then, inject it as the reserved label, so it's used by the remote write client
+		if tenantIDHeaderValue != "" {
+			filtered[lokiClient.ReservedLabelTenantID] = model.LabelValue(tenantIDHeaderValue)
+		}
+
+		ts := time.Now()
+		if t.config.UseIncomingTimestamp && !event.StageTimestamp.IsZero() {
+			ts = event.StageTimestamp
+		}
+
+		entries <- api.Entry{
+			Labels: filtered,
+			Entry: logproto.Entry{
+				Timestamp: ts,
+				Line:      string(item),
+			},
+		}
+		t.metrics.auditEntries.Inc()
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+func (t *AuditTarget) Type() target.TargetType {
+	return target.KubernetesAuditTargetType
+}
+
+func (t *AuditTarget) DiscoveredLabels() model.LabelSet {
+	return nil
+}
+
+func (t *AuditTarget) Labels() model.LabelSet {
+	return t.config.Labels
+}
+
+func (t *AuditTarget) Ready() bool {
+	return true
+}
+
+func (t *AuditTarget) Details() interface{} {
+	return map[string]string{}
+}
+
+func (t *AuditTarget) Stop() error {
+	level.Info(t.logger).Log("msg", "stopping kubernetes audit target", "job", t.jobName)
+	t.server.Shutdown()
+	t.handler.Stop()
+	return nil
+}
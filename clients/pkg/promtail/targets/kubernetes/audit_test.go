@@ -0,0 +1,89 @@
+package kubernetes
+
+import (
+	"flag"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/grafana/dskit/server"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/relabel"
+	"github.com/stretchr/testify/require"
+
+	lokiClient "github.com/grafana/loki/v3/clients/pkg/promtail/client"
+	"github.com/grafana/loki/v3/clients/pkg/promtail/client/fake"
+	"github.com/grafana/loki/v3/clients/pkg/promtail/scrapeconfig"
+)
+
+const localhost = "127.0.0.1"
+
+const testAuditEvent = `{"kind":"Event","apiVersion":"audit.k8s.io/v1","level":"Metadata","auditID":"1c4ffb0e","stage":"ResponseComplete","requestURI":"/api/v1/namespaces/default/secrets/db","verb":"get","user":{"username":"system:serviceaccount:default:web"},"objectRef":{"resource":"secrets","namespace":"default","name":"db","apiVersion":"v1"},"responseStatus":{"code":200},"requestReceivedTimestamp":"2024-05-01T10:00:00.000000Z","stageTimestamp":"2024-05-01T10:00:00.012345Z"}`
+
+func TestAuditTarget(t *testing.T) {
+	eh := fake.New(func() {})
+	defer eh.Stop()
+
+	serverConfig, port := getServerConfigWithAvailablePort(t)
+	config := &scrapeconfig.KubernetesAuditTargetConfig{
+		Server:               serverConfig,
+		Labels:               model.LabelSet{"job": "kubernetes_audit"},
+		UseIncomingTimestamp: true,
+	}
+	tg, err := NewAuditTarget(NewMetrics(nil), log.NewNopLogger(), eh, "kubernetes_audit", config, []*relabel.Config{
+		{
+			SourceLabels: model.LabelNames{"__meta_kubernetes_audit_verb"},
+			TargetLabel:  "verb",
+			Replacement:  "$1",
+			Action:       relabel.Replace,
+			Regex:        relabel.MustNewRegexp("(.*)"),
+		},
+	})
+	require.NoError(t, err)
+	defer func() {
+		_ = tg.Stop()
+	}()
+
+	body := fmt.Sprintf(`{"kind":"EventList","apiVersion":"audit.k8s.io/v1","metadata":{},"items":[%s]}`, testAuditEvent)
+	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("http://%s:%d%s", localhost, port, AuditPath), strings.NewReader(body))
+	require.NoError(t, err)
+	req.Header.Set("X-Scope-OrgID", "42")
+	res, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+
+	require.Eventually(t, func() bool { return len(eh.Received()) == 1 }, time.Second, time.Millisecond)
+	entry := eh.Received()[0]
+	require.Equal(t, model.LabelSet{
+		"job":                            "kubernetes_audit",
+		"namespace":                      "default",
+		"verb":                           "get",
+		lokiClient.ReservedLabelTenantID: "42",
+	}, entry.Labels)
+	require.Equal(t, testAuditEvent, entry.Line)
+	require.Equal(t, time.Date(2024, 5, 1, 10, 0, 0, 12345000, time.UTC), entry.Timestamp.UTC())
+
+	res, err = http.Post(fmt.Sprintf("http://%s:%d%s", localhost, port, AuditPath), "application/json", strings.NewReader("{"))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusBadRequest, res.StatusCode)
+}
+
+func getServerConfigWithAvailablePort(t *testing.T) (cfg server.Config, port int) {
+	// Get a randomly available port by open and closing a TCP socket
+	l, err := net.Listen("tcp", localhost+":0")
+	require.NoError(t, err)
+	port = l.Addr().(*net.TCPAddr).Port
+	require.NoError(t, l.Close())
+
+	// Adjust some of the defaults
+	cfg.RegisterFlags(flag.NewFlagSet("empty", flag.ContinueOnError))
+	cfg.HTTPListenAddress = localhost
+	cfg.HTTPListenPort = port
+	cfg.GRPCListenAddress = localhost
+	cfg.GRPCListenPort = 0 // Not testing GRPC, a random port will be assigned
+	return
+}
@@ -0,0 +1,346 @@
+package kubernetes
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/go-logfmt/logfmt"
+	"github.com/grafana/dskit/backoff"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/model/relabel"
+	"go.uber.org/atomic"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+	k8sclient "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+
+	"github.com/grafana/loki/v3/clients/pkg/promtail/api"
+	"github.com/grafana/loki/v3/clients/pkg/promtail/positions"
+	"github.com/grafana/loki/v3/clients/pkg/promtail/scrapeconfig"
+	"github.com/grafana/loki/v3/clients/pkg/promtail/targets/target"
+
+	"github.com/grafana/loki/v3/pkg/logproto"
+)
+
+const namespaceLabel = "namespace"
+
+var watchBackoff = backoff.Config{
+	MinBackoff: 1 * time.Second,
+	MaxBackoff: 30 * time.Second,
+}
+
+// EventsTarget watches the Kubernetes Events API and sends every added or
+// updated event as a log entry. The resource version of the last received
+// event or bookmark is saved in the positions file, so that the watch resumes
+// where it stopped after a restart.
+type EventsTarget struct {
+	logger         log.Logger
+	handler        api.EntryHandler
+	positions      positions.Positions
+	config         *scrapeconfig.KubernetesEventsTargetConfig
+	jobName        string
+	metrics        *Metrics
+	relabelConfigs []*relabel.Config
+	client         k8sclient.Interface
+
+	ctx     context.Context
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+	running *atomic.Bool
+}
+
+// NewEventsTarget creates a new Kubernetes events target. If client is nil, a
+// client is created from the configured kubeconfig file, or from the
+// in-cluster configuration.
+func NewEventsTarget(
+	metrics *Metrics,
+	logger log.Logger,
+	handler api.EntryHandler,
+	position positions.Positions,
+	jobName string,
+	config *scrapeconfig.KubernetesEventsTargetConfig,
+	relabel []*relabel.Config,
+	client k8sclient.Interface,
+) (*EventsTarget, error) {
+	if client == nil {
+		restConfig, err := restConfig(config.KubeConfigFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to configure the kubernetes client: %w", err)
+		}
+		client, err = k8sclient.NewForConfig(restConfig)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create the kubernetes client: %w", err)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t := &EventsTarget{
+		logger:         log.With(logger, "component", "kubernetes_events", "job", jobName),
+		handler:        handler,
+		positions:      position,
+		config:         config,
+		jobName:        jobName,
+		metrics:        metrics,
+		relabelConfigs: relabel,
+		client:         client,
+		ctx:            ctx,
+		cancel:         cancel,
+		running:        atomic.NewBool(false),
+	}
+
+	namespaces := config.Namespaces
+	if len(namespaces) == 0 {
+		namespaces = []string{metav1.NamespaceAll}
+	}
+	for _, namespace := range namespaces {
+		t.wg.Add(1)
+		go t.run(namespace)
+	}
+	return t, nil
+}
+
+func restConfig(kubeConfigFile string) (*rest.Config, error) {
+	if kubeConfigFile != "" {
+		return clientcmd.BuildConfigFromFlags("", kubeConfigFile)
+	}
+	return rest.InClusterConfig()
+}
+
+// positionKey returns the positions key holding the resource version of a
+// watched namespace.
+func (t *EventsTarget) positionKey(namespace string) string {
+	if namespace == metav1.NamespaceAll {
+		namespace = "_all"
+	}
+	return positions.CursorKey(fmt.Sprintf("kubernetes-events-%s-%s", t.jobName, namespace))
+}
+
+func (t *EventsTarget) run(namespace string) {
+	defer t.wg.Done()
+
+	key := t.positionKey(namespace)
+	resourceVersion := t.positions.GetString(key)
+	backoff := backoff.New(t.ctx, watchBackoff)
+
+	for t.ctx.Err() == nil {
+		if resourceVersion == "" {
+			// Start from the current state, events which happened before
+			// the first start aren't sent.
+			list, err := t.client.CoreV1().Events(namespace).List(t.ctx, metav1.ListOptions{Limit: 1})
+			if err != nil {
+				t.metrics.eventsWatchErrors.Inc()
+				level.Error(t.logger).Log("msg", "failed to list kubernetes events", "namespace", namespace, "err", err)
+				backoff.Wait()
+				continue
+			}
+			resourceVersion = list.ResourceVersion
+			t.positions.PutString(key, resourceVersion)
+		}
+
+		last, err := t.watch(namespace, resourceVersion)
+		if last != resourceVersion {
+			resourceVersion = last
+			backoff.Reset()
+		} else if err == nil {
+			// Avoid restarting watches in a tight loop if the API server
+			// keeps closing them without sending anything.
+			backoff.Wait()
+		}
+		if err == nil {
+			continue
+		}
+		if apierrors.IsResourceExpired(err) || apierrors.IsGone(err) {
+			level.Warn(t.logger).Log("msg", "kubernetes events resource version is too old, events may have been missed", "namespace", namespace, "resource_version", resourceVersion)
+			resourceVersion = ""
+			continue
+		}
+		t.metrics.eventsWatchErrors.Inc()
+		level.Error(t.logger).Log("msg", "failed to watch kubernetes events", "namespace", namespace, "err", err)
+		backoff.Wait()
+	}
+}
+
+// watch watches the events of the namespace starting after the given resource
+// version and returns the last one received.
+func (t *EventsTarget) watch(namespace, resourceVersion string) (string, error) {
+	w, err := t.client.CoreV1().Events(namespace).Watch(t.ctx, metav1.ListOptions{
+		ResourceVersion:     resourceVersion,
+		AllowWatchBookmarks: true,
+	})
+	if err != nil {
+		return resourceVersion, err
+	}
+	defer w.Stop()
+	t.running.Store(true)
+
+	key := t.positionKey(namespace)
+	for {
+		select {
+		case <-t.ctx.Done():
+			return resourceVersion, nil
+		case e, ok := <-w.ResultChan():
+			if !ok {
+				// The API server closes watches after a timeout, restart it.
+				return resourceVersion, nil
+			}
+			if e.Type == watch.Error {
+				return resourceVersion, apierrors.FromObject(e.Object)
+			}
+			event, ok := e.Object.(*corev1.Event)
+			if !ok {
+				continue
+			}
+			if e.Type == watch.Added || e.Type == watch.Modified {
+				t.send(event)
+			}
+			resourceVersion = event.ResourceVersion
+			t.positions.PutString(key, resourceVersion)
+		}
+	}
+}
+
+func (t *EventsTarget) send(event *corev1.Event) {
+	lb := labels.NewBuilder(labels.EmptyLabels())
+	lb.Set("__meta_kubernetes_event_namespace", event.Namespace)
+	lb.Set("__meta_kubernetes_event_name", event.Name)
+	lb.Set("__meta_kubernetes_event_type", event.Type)
+	lb.Set("__meta_kubernetes_event_reason", event.Reason)
+	lb.Set("__meta_kubernetes_event_involved_object_kind", event.InvolvedObject.Kind)
+	lb.Set("__meta_kubernetes_event_involved_object_name", event.InvolvedObject.Name)
+	lb.Set("__meta_kubernetes_event_involved_object_namespace", event.InvolvedObject.Namespace)
+	lb.Set("__meta_kubernetes_event_source_component", event.Source.Component)
+	lb.Set("__meta_kubernetes_event_source_host", event.Source.Host)
+	lb.Set("__meta_kubernetes_event_reporting_controller", event.ReportingController)
+
+	processed, _ := relabel.Process(lb.Labels(), t.relabelConfigs...)
+
+	// Start with the set of labels fixed in the configuration, and the
+	// namespace so that events can be found next to the pod logs.
+	filtered := t.Labels().Clone()
+	if filtered == nil {
+		filtered = model.LabelSet{}
+	}
+	if event.InvolvedObject.Namespace != "" {
+		filtered[namespaceLabel] = model.LabelValue(event.InvolvedObject.Namespace)
+	}
+	processed.Range(func(lbl labels.Label) {
+		if strings.HasPrefix(lbl.Name, "__") {
+			return
+		}
+		filtered[model.LabelName(lbl.Name)] = model.LabelValue(lbl.Value)
+	})
+
+	ts := time.Now()
+	if t.config.UseIncomingTimestamp {
+		ts = eventTimestamp(event)
+	}
+
+	line, err := formatEvent(event)
+	if err != nil {
+		level.Warn(t.logger).Log("msg", "failed to format kubernetes event", "event", event.Name, "err", err)
+		return
+	}
+
+	t.handler.Chan() <- api.Entry{
+		Labels: filtered,
+		Entry: logproto.Entry{
+			Timestamp: ts,
+			Line:      line,
+		},
+	}
+	t.metrics.eventsEntries.Inc()
+}
+
+// eventTimestamp returns the last time the event was observed.
+func eventTimestamp(event *corev1.Event) time.Time {
+	switch {
+	case event.Series != nil && !event.Series.LastObservedTime.IsZero():
+		return event.Series.LastObservedTime.Time
+	case !event.LastTimestamp.IsZero():
+		return event.LastTimestamp.Time
+	case !event.EventTime.IsZero():
+		return event.EventTime.Time
+	default:
+		return event.CreationTimestamp.Time
+	}
+}
+
+// formatEvent formats the event as a logfmt line.
+func formatEvent(event *corev1.Event) (string, error) {
+	object := event.InvolvedObject.Kind + "/" + event.InvolvedObject.Name
+	if event.InvolvedObject.Namespace != "" {
+		object = event.InvolvedObject.Kind + "/" + event.InvolvedObject.Namespace + "/" + event.InvolvedObject.Name
+	}
+	source := event.Source.Component
+	if source == "" {
+		source = event.ReportingController
+	}
+	count := event.Count
+	if event.Series != nil {
+		count = event.Series.Count
+	}
+
+	var buf bytes.Buffer
+	enc := logfmt.NewEncoder(&buf)
+	if err := enc.EncodeKeyvals(
+		"type", event.Type,
+		"reason", event.Reason,
+		"object", object,
+		"source", source,
+		"count", count,
+		"msg", strings.TrimSpace(event.Message),
+	); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+func (t *EventsTarget) Type() target.TargetType {
+	return target.KubernetesEventsTargetType
+}
+
+func (t *EventsTarget) DiscoveredLabels() model.LabelSet {
+	return nil
+}
+
+func (t *EventsTarget) Labels() model.LabelSet {
+	return t.config.Labels
+}
+
+func (t *EventsTarget) Ready() bool {
+	return t.running.Load()
+}
+
+func (t *EventsTarget) Details() interface{} {
+	namespaces := t.config.Namespaces
+	if len(namespaces) == 0 {
+		namespaces = []string{metav1.NamespaceAll}
+	}
+	details := make(map[string]string, len(namespaces))
+	for _, namespace := range namespaces {
+		name := namespace
+		if name == metav1.NamespaceAll {
+			name = "all namespaces"
+		}
+		details[name] = t.positions.GetString(t.positionKey(namespace))
+	}
+	return details
+}
+
+func (t *EventsTarget) Stop() error {
+	level.Info(t.logger).Log("msg", "stopping kubernetes events target")
+	t.cancel()
+	t.wg.Wait()
+	t.handler.Stop()
+	return nil
+}
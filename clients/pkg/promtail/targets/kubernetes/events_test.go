@@ -0,0 +1,145 @@
+package kubernetes
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/relabel"
+	"github.com/stretchr/testify/require"
+	k8sclient "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+
+	"github.com/grafana/loki/v3/clients/pkg/promtail/client/fake"
+	"github.com/grafana/loki/v3/clients/pkg/promtail/positions"
+	"github.com/grafana/loki/v3/clients/pkg/promtail/scrapeconfig"
+)
+
+const testEvent = `{"type":"ADDED","object":{"kind":"Event","apiVersion":"v1","metadata":{"name":"web-1.17a","namespace":"default","resourceVersion":"101"},"involvedObject":{"kind":"Pod","namespace":"default","name":"web-1"},"reason":"BackOff","message":"Back-off restarting failed container","source":{"component":"kubelet","host":"node-1"},"lastTimestamp":"2024-05-01T10:00:00Z","count":3,"type":"Warning"}}
+`
+
+const testBookmark = `{"type":"BOOKMARK","object":{"kind":"Event","apiVersion":"v1","metadata":{"resourceVersion":"105"}}}
+`
+
+// fakeAPIServer serves the events list and watch endpoints of a namespace.
+// The first watch sends an event and a bookmark before being closed, the
+// following ones are kept open.
+type fakeAPIServer struct {
+	mtx              sync.Mutex
+	listCalls        int
+	resourceVersions []string
+}
+
+func (s *fakeAPIServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if r.URL.Query().Get("watch") != "true" {
+		s.mtx.Lock()
+		s.listCalls++
+		s.mtx.Unlock()
+		fmt.Fprint(w, `{"kind":"EventList","apiVersion":"v1","metadata":{"resourceVersion":"50"},"items":[]}`)
+		return
+	}
+
+	s.mtx.Lock()
+	s.resourceVersions = append(s.resourceVersions, r.URL.Query().Get("resourceVersion"))
+	first := len(s.resourceVersions) == 1
+	s.mtx.Unlock()
+
+	w.WriteHeader(http.StatusOK)
+	if !first {
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+		return
+	}
+	fmt.Fprint(w, testEvent)
+	fmt.Fprint(w, testBookmark)
+}
+
+func (s *fakeAPIServer) lists() int {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	return s.listCalls
+}
+
+func (s *fakeAPIServer) watched() []string {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	return append([]string(nil), s.resourceVersions...)
+}
+
+func newTestEventsTarget(t *testing.T, pos positions.Positions, relabelConfigs []*relabel.Config) (*fakeAPIServer, *fake.Client, *EventsTarget) {
+	api := &fakeAPIServer{}
+	srv := httptest.NewServer(api)
+	t.Cleanup(srv.Close)
+
+	client, err := k8sclient.NewForConfig(&rest.Config{Host: srv.URL})
+	require.NoError(t, err)
+
+	eh := fake.New(func() {})
+	target, err := NewEventsTarget(NewMetrics(nil), log.NewNopLogger(), eh, pos, "kubernetes_events", &scrapeconfig.KubernetesEventsTargetConfig{
+		Namespaces:           []string{"default"},
+		Labels:               model.LabelSet{"job": "kubernetes_events"},
+		UseIncomingTimestamp: true,
+	}, relabelConfigs, client)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = target.Stop() })
+	return api, eh, target
+}
+
+func newTestPositions(t *testing.T) positions.Positions {
+	pos, err := positions.New(log.NewNopLogger(), positions.Config{
+		SyncPeriod:    10 * time.Second,
+		PositionsFile: filepath.Join(t.TempDir(), "positions.yml"),
+	})
+	require.NoError(t, err)
+	t.Cleanup(pos.Stop)
+	return pos
+}
+
+func TestEventsTarget(t *testing.T) {
+	pos := newTestPositions(t)
+	api, eh, target := newTestEventsTarget(t, pos, []*relabel.Config{
+		{
+			SourceLabels: model.LabelNames{"__meta_kubernetes_event_reason"},
+			TargetLabel:  "reason",
+			Replacement:  "$1",
+			Action:       relabel.Replace,
+			Regex:        relabel.MustNewRegexp("(.*)"),
+		},
+	})
+
+	// The watch is restarted from the bookmark once the server closes it.
+	require.Eventually(t, func() bool { return len(api.watched()) == 2 }, 5*time.Second, 10*time.Millisecond)
+	require.Equal(t, []string{"50", "105"}, api.watched())
+	require.Equal(t, 1, api.lists())
+	require.Equal(t, "105", pos.GetString(target.positionKey("default")))
+	require.True(t, target.Ready())
+
+	entries := eh.Received()
+	require.Len(t, entries, 1)
+	require.Equal(t, model.LabelSet{
+		"job":       "kubernetes_events",
+		"namespace": "default",
+		"reason":    "BackOff",
+	}, entries[0].Labels)
+	require.Equal(t, `type=Warning reason=BackOff object=Pod/default/web-1 source=kubelet count=3 msg="Back-off restarting failed container"`, entries[0].Line)
+	require.Equal(t, time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC), entries[0].Timestamp.UTC())
+}
+
+func TestEventsTarget_ResumeFromPositions(t *testing.T) {
+	pos := newTestPositions(t)
+	pos.PutString(positions.CursorKey("kubernetes-events-kubernetes_events-default"), "100")
+
+	api, eh, _ := newTestEventsTarget(t, pos, nil)
+
+	require.Eventually(t, func() bool { return len(api.watched()) == 2 }, 5*time.Second, 10*time.Millisecond)
+	require.Equal(t, []string{"100", "105"}, api.watched())
+	require.Equal(t, 0, api.lists())
+	require.Len(t, eh.Received(), 1)
+}
@@ -0,0 +1,46 @@
+package kubernetes
+
+import "github.com/prometheus/client_golang/prometheus"
+
+// Metrics holds the metrics of the Kubernetes events and audit targets.
+type Metrics struct {
+	reg prometheus.Registerer
+
+	eventsEntries     prometheus.Counter
+	eventsWatchErrors prometheus.Counter
+	auditEntries      prometheus.Counter
+	auditErrors       prometheus.Counter
+}
+
+// NewMetrics creates a new set of Kubernetes target metrics. If reg is non-nil,
+// the metrics are registered.
+func NewMetrics(reg prometheus.Registerer) *Metrics {
+	var m Metrics
+	m.reg = reg
+
+	m.eventsEntries = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: "promtail",
+		Name:      "kubernetes_events_target_entries_total",
+		Help:      "Number of Kubernetes events read by the Kubernetes events target",
+	})
+	m.eventsWatchErrors = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: "promtail",
+		Name:      "kubernetes_events_target_watch_errors_total",
+		Help:      "Number of errors while listing or watching Kubernetes events",
+	})
+	m.auditEntries = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: "promtail",
+		Name:      "kubernetes_audit_target_entries_total",
+		Help:      "Number of audit events received by the Kubernetes audit target",
+	})
+	m.auditErrors = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: "promtail",
+		Name:      "kubernetes_audit_target_parsing_errors_total",
+		Help:      "Number of parsing errors while receiving Kubernetes audit events",
+	})
+
+	if reg != nil {
+		reg.MustRegister(m.eventsEntries, m.eventsWatchErrors, m.auditEntries, m.auditErrors)
+	}
+	return &m
+}
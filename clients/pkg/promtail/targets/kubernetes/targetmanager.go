@@ -0,0 +1,99 @@
+package kubernetes
+
+import (
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/grafana/loki/v3/clients/pkg/logentry/stages"
+	"github.com/grafana/loki/v3/clients/pkg/promtail/api"
+	"github.com/grafana/loki/v3/clients/pkg/promtail/positions"
+	"github.com/grafana/loki/v3/clients/pkg/promtail/scrapeconfig"
+	"github.com/grafana/loki/v3/clients/pkg/promtail/targets/target"
+)
+
+type kubernetesTarget interface {
+	target.Target
+	Stop() error
+}
+
+// TargetManager manages a series of Kubernetes events and audit targets.
+type TargetManager struct {
+	logger  log.Logger
+	targets map[string]kubernetesTarget
+}
+
+// NewTargetManager creates a new Kubernetes events and audit target manager.
+func NewTargetManager(
+	metrics *Metrics,
+	reg prometheus.Registerer,
+	logger log.Logger,
+	positions positions.Positions,
+	client api.EntryHandler,
+	scrapeConfigs []scrapeconfig.Config,
+) (*TargetManager, error) {
+	tm := &TargetManager{
+		logger:  logger,
+		targets: make(map[string]kubernetesTarget),
+	}
+
+	for _, cfg := range scrapeConfigs {
+		pipeline, err := stages.NewPipeline(log.With(logger, "component", "kubernetes_pipeline_"+cfg.JobName), cfg.PipelineStages, &cfg.JobName, reg)
+		if err != nil {
+			return nil, err
+		}
+
+		var t kubernetesTarget
+		switch {
+		case cfg.KubernetesEventsConfig != nil:
+			t, err = NewEventsTarget(metrics, logger, pipeline.Wrap(client), positions, cfg.JobName, cfg.KubernetesEventsConfig, cfg.RelabelConfigs, nil)
+		case cfg.KubernetesAuditConfig != nil:
+			t, err = NewAuditTarget(metrics, logger, pipeline.Wrap(client), cfg.JobName, cfg.KubernetesAuditConfig, cfg.RelabelConfigs)
+		default:
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		tm.targets[cfg.JobName] = t
+	}
+
+	return tm, nil
+}
+
+// Ready returns true if at least one target is ready.
+func (tm *TargetManager) Ready() bool {
+	for _, t := range tm.targets {
+		if t.Ready() {
+			return true
+		}
+	}
+	return false
+}
+
+func (tm *TargetManager) Stop() {
+	for name, t := range tm.targets {
+		if err := t.Stop(); err != nil {
+			level.Error(tm.logger).Log("event", "failed to stop kubernetes target", "name", name, "cause", err)
+		}
+	}
+}
+
+func (tm *TargetManager) ActiveTargets() map[string][]target.Target {
+	result := make(map[string][]target.Target, len(tm.targets))
+	for k, v := range tm.targets {
+		if v.Ready() {
+			result[k] = []target.Target{v}
+		}
+	}
+	return result
+}
+
+func (tm *TargetManager) AllTargets() map[string][]target.Target {
+	result := make(map[string][]target.Target, len(tm.targets))
+	for k, v := range tm.targets {
+		result[k] = []target.Target{v}
+	}
+	return result
+}
@@ -2,11 +2,14 @@ package targets
 
 import (
 	"fmt"
+	"sort"
+	"sync"
 
 	"github.com/go-kit/log"
 	"github.com/go-kit/log/level"
 	"github.com/pkg/errors"
 	"github.com/prometheus/client_golang/prometheus"
+	"gopkg.in/yaml.v2"
 
 	"github.com/grafana/loki/v3/clients/pkg/promtail/api"
 	"github.com/grafana/loki/v3/clients/pkg/promtail/limit"
@@ -21,6 +24,7 @@ import (
 	"github.com/grafana/loki/v3/clients/pkg/promtail/targets/heroku"
 	"github.com/grafana/loki/v3/clients/pkg/promtail/targets/journal"
 	"github.com/grafana/loki/v3/clients/pkg/promtail/targets/kafka"
+	"github.com/grafana/loki/v3/clients/pkg/promtail/targets/kubernetes"
 	"github.com/grafana/loki/v3/clients/pkg/promtail/targets/lokipush"
 	"github.com/grafana/loki/v3/clients/pkg/promtail/targets/stdin"
 	"github.com/grafana/loki/v3/clients/pkg/promtail/targets/syslog"
@@ -41,6 +45,7 @@ const (
 	DockerSDConfigs             = "dockerSDConfigs"
 	HerokuDrainConfigs          = "herokuDrainConfigs"
 	AzureEventHubsScrapeConfigs = "azureeventhubsScrapeConfigs"
+	KubernetesConfigs           = "kubernetesConfigs"
 )
 
 var (
@@ -52,6 +57,7 @@ var (
 	dockerMetrics      *docker.Metrics
 	journalMetrics     *journal.Metrics
 	herokuDrainMetrics *heroku.Metrics
+	kubernetesMetrics  *kubernetes.Metrics
 )
 
 type targetManager interface {
@@ -61,10 +67,42 @@ type targetManager interface {
 	AllTargets() map[string][]target.Target
 }
 
-// TargetManagers manages a list of target managers.
+// reloadableTargetManager is a target manager which can apply new scrape
+// configs itself, only restarting the jobs which changed.
+type reloadableTargetManager interface {
+	targetManager
+	Reload(scrapeConfigs []scrapeconfig.Config) error
+}
+
+// kindManager is the target manager of a scrape job. Target managers which
+// can reload their scrape configs run all scrape configs of their kind
+// instead, see managerKey.
+type kindManager struct {
+	kind    string
+	configs []scrapeconfig.Config
+	// config is the marshalled scrape configs the manager runs, used to find
+	// out if they changed on reload.
+	config  string
+	manager targetManager
+}
+
+// TargetManagers manages a list of target managers, one per scrape job, or
+// one per kind of scrape config for the target managers which can reload
+// their scrape configs.
 type TargetManagers struct {
+	mtx            sync.Mutex
 	targetManagers []targetManager
-	positions      positions.Positions
+	// kinds holds the target managers by managerKey.
+	kinds     map[string]*kindManager
+	positions positions.Positions
+
+	reg             prometheus.Registerer
+	logger          log.Logger
+	positionsConfig positions.Config
+	client          api.EntryHandler
+	targetConfig    *file.Config
+	watchConfig     file.WatchConfig
+	limitsConfig    *limit.Config
 }
 
 // NewTargetManagers makes a new TargetManagers
@@ -88,213 +126,402 @@ func NewTargetManagers(
 		return &TargetManagers{targetManagers: []targetManager{stdin}}, nil
 	}
 
-	var targetManagers []targetManager
-	targetScrapeConfigs := make(map[string][]scrapeconfig.Config, 4)
+	tm := &TargetManagers{
+		kinds:           map[string]*kindManager{},
+		reg:             reg,
+		logger:          logger,
+		positionsConfig: positionsConfig,
+		client:          client,
+		targetConfig:    targetConfig,
+		watchConfig:     watchConfig,
+		limitsConfig:    limitsConfig,
+	}
+	if err := tm.reload(scrapeConfigs); err != nil {
+		tm.Stop()
+		return nil, err
+	}
+	return tm, nil
+}
 
+// CanReload returns whether the scrape configs can be reloaded without
+// recreating the TargetManagers.
+func (tm *TargetManagers) CanReload() bool {
+	return tm.kinds != nil
+}
+
+// Reload diffs the scrape configs with the ones currently running, and only
+// restarts the jobs which were added, removed or changed. The file target
+// manager reloads its jobs itself, the other kinds run a target manager per
+// job which is restarted. The other jobs keep running with their positions and
+// pipeline state untouched.
+// If a target manager can't be created, the running ones are left untouched
+// and an error is returned.
+func (tm *TargetManagers) Reload(scrapeConfigs []scrapeconfig.Config) error {
+	if !tm.CanReload() {
+		return errors.New("target managers reading from stdin can't be reloaded")
+	}
+	return tm.reload(scrapeConfigs)
+}
+
+func (tm *TargetManagers) reload(scrapeConfigs []scrapeconfig.Config) error {
+	kinds := map[string]*kindManager{}
 	for _, cfg := range scrapeConfigs {
-		switch {
-		case cfg.HasServiceDiscoveryConfig():
-			targetScrapeConfigs[FileScrapeConfigs] = append(targetScrapeConfigs[FileScrapeConfigs], cfg)
-		case cfg.JournalConfig != nil:
-			targetScrapeConfigs[JournalScrapeConfigs] = append(targetScrapeConfigs[JournalScrapeConfigs], cfg)
-		case cfg.SyslogConfig != nil:
-			targetScrapeConfigs[SyslogScrapeConfigs] = append(targetScrapeConfigs[SyslogScrapeConfigs], cfg)
-		case cfg.GcplogConfig != nil:
-			targetScrapeConfigs[GcplogScrapeConfigs] = append(targetScrapeConfigs[GcplogScrapeConfigs], cfg)
-		case cfg.PushConfig != nil:
-			targetScrapeConfigs[PushScrapeConfigs] = append(targetScrapeConfigs[PushScrapeConfigs], cfg)
-		case cfg.WindowsConfig != nil:
-			targetScrapeConfigs[WindowsEventsConfigs] = append(targetScrapeConfigs[WindowsEventsConfigs], cfg)
-		case cfg.KafkaConfig != nil:
-			targetScrapeConfigs[KafkaConfigs] = append(targetScrapeConfigs[KafkaConfigs], cfg)
-		case cfg.AzureEventHubsConfig != nil:
-			targetScrapeConfigs[AzureEventHubsScrapeConfigs] = append(targetScrapeConfigs[AzureEventHubsScrapeConfigs], cfg)
-		case cfg.GelfConfig != nil:
-			targetScrapeConfigs[GelfConfigs] = append(targetScrapeConfigs[GelfConfigs], cfg)
-		case cfg.CloudflareConfig != nil:
-			targetScrapeConfigs[CloudflareConfigs] = append(targetScrapeConfigs[CloudflareConfigs], cfg)
-		case cfg.DockerSDConfigs != nil:
-			targetScrapeConfigs[DockerSDConfigs] = append(targetScrapeConfigs[DockerSDConfigs], cfg)
-		case cfg.HerokuDrainConfig != nil:
-			targetScrapeConfigs[HerokuDrainConfigs] = append(targetScrapeConfigs[HerokuDrainConfigs], cfg)
-		default:
-			return nil, fmt.Errorf("no valid target scrape config defined for %q", cfg.JobName)
+		kind, err := scrapeConfigType(cfg)
+		if err != nil {
+			return err
+		}
+		out, err := yaml.Marshal(cfg)
+		if err != nil {
+			return fmt.Errorf("failed to marshal scrape config %q: %w", cfg.JobName, err)
+		}
+		key := managerKey(kind, cfg.JobName)
+		k, ok := kinds[key]
+		if !ok {
+			k = &kindManager{kind: kind}
+			kinds[key] = k
 		}
+		k.configs = append(k.configs, cfg)
+		k.config += string(out)
 	}
+	keys := make([]string, 0, len(kinds))
+	for key := range kinds {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
 
-	var positionFile positions.Positions
+	tm.mtx.Lock()
+	defer tm.mtx.Unlock()
 
-	// position file is a singleton, we use a function to keep it so.
-	getPositionFile := func() (positions.Positions, error) {
-		if positionFile == nil {
-			var err error
-			positionFile, err = positions.New(logger, positionsConfig)
+	var (
+		// started holds the new target managers of the restarted jobs.
+		started = map[string]targetManager{}
+		// reloaded holds the target managers reloaded in place.
+		reloaded []string
+		// stopped holds the jobs whose running target manager had to be
+		// stopped before the new one could be started.
+		stopped = map[string]bool{}
+	)
+	rollback := func() {
+		for _, m := range started {
+			m.Stop()
+		}
+		for _, key := range reloaded {
+			old := tm.kinds[key]
+			if err := old.manager.(reloadableTargetManager).Reload(old.configs); err != nil {
+				level.Error(tm.logger).Log("msg", "failed to restore target manager", "kind", old.kind, "job", key, "err", err)
+			}
+		}
+		for key := range stopped {
+			old := tm.kinds[key]
+			m, err := tm.newTargetManager(old.kind, old.configs)
 			if err != nil {
-				return nil, err
+				level.Error(tm.logger).Log("msg", "failed to restore target manager", "kind", old.kind, "job", key, "err", err)
+				delete(tm.kinds, key)
+				continue
 			}
+			old.manager = m
 		}
-		return positionFile, nil
 	}
 
-	if len(targetScrapeConfigs[FileScrapeConfigs]) > 0 && fileMetrics == nil {
-		fileMetrics = file.NewMetrics(reg)
-	}
-	if len(targetScrapeConfigs[SyslogScrapeConfigs]) > 0 && syslogMetrics == nil {
-		syslogMetrics = syslog.NewMetrics(reg)
-	}
-	if len(targetScrapeConfigs[GcplogScrapeConfigs]) > 0 && gcplogMetrics == nil {
-		gcplogMetrics = gcplog.NewMetrics(reg)
+	for _, key := range keys {
+		k := kinds[key]
+		old, ok := tm.kinds[key]
+		if ok && old.config == k.config {
+			continue
+		}
+		if ok {
+			if r, ok := old.manager.(reloadableTargetManager); ok {
+				level.Info(tm.logger).Log("msg", "reloading target manager", "kind", k.kind)
+				if err := r.Reload(k.configs); err != nil {
+					rollback()
+					return err
+				}
+				reloaded = append(reloaded, key)
+				continue
+			}
+		}
+
+		level.Info(tm.logger).Log("msg", "starting target manager", "kind", k.kind, "job", key)
+		m, err := tm.newTargetManager(k.kind, k.configs)
+		if err != nil && ok {
+			// The running target manager may hold listeners the new one
+			// needs, retry once it's stopped.
+			level.Warn(tm.logger).Log("msg", "failed to start target manager, retrying after stopping the running one", "kind", k.kind, "job", key, "err", err)
+			old.manager.Stop()
+			stopped[key] = true
+			m, err = tm.newTargetManager(k.kind, k.configs)
+		}
+		if err != nil {
+			rollback()
+			return err
+		}
+		started[key] = m
 	}
-	if len(targetScrapeConfigs[GelfConfigs]) > 0 && gelfMetrics == nil {
-		gelfMetrics = gelf.NewMetrics(reg)
+
+	// Every target manager could be created, swap them.
+	for key, old := range tm.kinds {
+		k, ok := kinds[key]
+		if !ok {
+			level.Info(tm.logger).Log("msg", "stopping target manager", "kind", old.kind, "job", key)
+			old.manager.Stop()
+			delete(tm.kinds, key)
+			continue
+		}
+		if m, ok := started[key]; ok {
+			if !stopped[key] {
+				old.manager.Stop()
+			}
+			old.manager = m
+		}
+		old.configs, old.config = k.configs, k.config
 	}
-	if len(targetScrapeConfigs[CloudflareConfigs]) > 0 && cloudflareMetrics == nil {
-		cloudflareMetrics = cloudflare.NewMetrics(reg)
+	for key, m := range started {
+		if _, ok := tm.kinds[key]; !ok {
+			k := kinds[key]
+			k.manager = m
+			tm.kinds[key] = k
+		}
 	}
-	if (len(targetScrapeConfigs[DockerSDConfigs]) > 0) && dockerMetrics == nil {
-		dockerMetrics = docker.NewMetrics(reg)
+	return nil
+}
+
+// managerKey returns the key of the target manager running a scrape config.
+// The file target manager reloads its scrape configs itself, so it runs all
+// file scrape configs. The other kinds run a target manager per job, so a
+// changed job can be restarted without the others.
+func managerKey(kind, jobName string) string {
+	if kind == FileScrapeConfigs {
+		return kind
 	}
-	if len(targetScrapeConfigs[JournalScrapeConfigs]) > 0 && journalMetrics == nil {
-		journalMetrics = journal.NewMetrics(reg)
+	return kind + "/" + jobName
+}
+
+// scrapeConfigType returns the kind of target manager handling the scrape config.
+func scrapeConfigType(cfg scrapeconfig.Config) (string, error) {
+	switch {
+	case cfg.HasServiceDiscoveryConfig():
+		return FileScrapeConfigs, nil
+	case cfg.JournalConfig != nil:
+		return JournalScrapeConfigs, nil
+	case cfg.SyslogConfig != nil:
+		return SyslogScrapeConfigs, nil
+	case cfg.GcplogConfig != nil:
+		return GcplogScrapeConfigs, nil
+	case cfg.PushConfig != nil:
+		return PushScrapeConfigs, nil
+	case cfg.WindowsConfig != nil:
+		return WindowsEventsConfigs, nil
+	case cfg.KafkaConfig != nil:
+		return KafkaConfigs, nil
+	case cfg.AzureEventHubsConfig != nil:
+		return AzureEventHubsScrapeConfigs, nil
+	case cfg.GelfConfig != nil:
+		return GelfConfigs, nil
+	case cfg.CloudflareConfig != nil:
+		return CloudflareConfigs, nil
+	case cfg.DockerSDConfigs != nil:
+		return DockerSDConfigs, nil
+	case cfg.HerokuDrainConfig != nil:
+		return HerokuDrainConfigs, nil
+	case cfg.KubernetesEventsConfig != nil, cfg.KubernetesAuditConfig != nil:
+		return KubernetesConfigs, nil
+	default:
+		return "", fmt.Errorf("no valid target scrape config defined for %q", cfg.JobName)
 	}
-	if len(targetScrapeConfigs[HerokuDrainConfigs]) > 0 && herokuDrainMetrics == nil {
-		herokuDrainMetrics = heroku.NewMetrics(reg)
+}
+
+// getPositionFile returns the positions file, which is a singleton shared by
+// all the target managers.
+func (tm *TargetManagers) getPositionFile() (positions.Positions, error) {
+	if tm.positions == nil {
+		var err error
+		tm.positions, err = positions.New(tm.logger, tm.positionsConfig)
+		if err != nil {
+			return nil, err
+		}
 	}
+	return tm.positions, nil
+}
 
-	for target, scrapeConfigs := range targetScrapeConfigs {
-		switch target {
-		case FileScrapeConfigs:
-			pos, err := getPositionFile()
-			if err != nil {
-				return nil, err
-			}
-			fileTargetManager, err := file.NewFileTargetManager(
-				fileMetrics,
-				logger,
-				pos,
-				client,
-				scrapeConfigs,
-				targetConfig,
-				watchConfig,
-			)
-			if err != nil {
-				return nil, errors.Wrap(err, "failed to make file target manager")
-			}
-			targetManagers = append(targetManagers, fileTargetManager)
-		case JournalScrapeConfigs:
-			pos, err := getPositionFile()
-			if err != nil {
-				return nil, err
-			}
-			journalTargetManager, err := journal.NewJournalTargetManager(
-				journalMetrics,
-				logger,
-				pos,
-				client,
-				scrapeConfigs,
-			)
-			if err != nil {
-				return nil, errors.Wrap(err, "failed to make journal target manager")
-			}
-			targetManagers = append(targetManagers, journalTargetManager)
-		case SyslogScrapeConfigs:
-			syslogTargetManager, err := syslog.NewSyslogTargetManager(
-				syslogMetrics,
-				logger,
-				client,
-				scrapeConfigs,
-			)
-			if err != nil {
-				return nil, errors.Wrap(err, "failed to make syslog target manager")
-			}
-			targetManagers = append(targetManagers, syslogTargetManager)
-		case GcplogScrapeConfigs:
-			pubsubTargetManager, err := gcplog.NewGcplogTargetManager(
-				gcplogMetrics,
-				logger,
-				client,
-				scrapeConfigs,
-			)
-			if err != nil {
-				return nil, errors.Wrap(err, "failed to make gcplog target manager")
-			}
-			targetManagers = append(targetManagers, pubsubTargetManager)
-		case PushScrapeConfigs:
-			pushTargetManager, err := lokipush.NewPushTargetManager(
-				reg,
-				logger,
-				client,
-				scrapeConfigs,
-			)
-			if err != nil {
-				return nil, errors.Wrap(err, "failed to make Loki Push API target manager")
-			}
-			targetManagers = append(targetManagers, pushTargetManager)
-		case HerokuDrainConfigs:
-			herokuDrainTargetManager, err := heroku.NewHerokuDrainTargetManager(herokuDrainMetrics, reg, logger, client, scrapeConfigs)
-			if err != nil {
-				return nil, errors.Wrap(err, "failed to make Heroku drain target manager")
-			}
-			targetManagers = append(targetManagers, herokuDrainTargetManager)
-		case WindowsEventsConfigs:
-			windowsTargetManager, err := windows.NewTargetManager(reg, logger, client, scrapeConfigs)
-			if err != nil {
-				return nil, errors.Wrap(err, "failed to make windows target manager")
-			}
-			targetManagers = append(targetManagers, windowsTargetManager)
-		case KafkaConfigs:
-			kafkaTargetManager, err := kafka.NewTargetManager(reg, logger, client, scrapeConfigs)
-			if err != nil {
-				return nil, errors.Wrap(err, "failed to make kafka target manager")
-			}
-			targetManagers = append(targetManagers, kafkaTargetManager)
-		case AzureEventHubsScrapeConfigs:
-			azureEventHubsTargetManager, err := azureeventhubs.NewTargetManager(reg, logger, client, scrapeConfigs)
-			if err != nil {
-				return nil, errors.Wrap(err, "failed to make Azure Event Hubs target manager")
-			}
-			targetManagers = append(targetManagers, azureEventHubsTargetManager)
-		case GelfConfigs:
-			gelfTargetManager, err := gelf.NewTargetManager(gelfMetrics, logger, client, scrapeConfigs)
-			if err != nil {
-				return nil, errors.Wrap(err, "failed to make gelf target manager")
-			}
-			targetManagers = append(targetManagers, gelfTargetManager)
-		case CloudflareConfigs:
-			pos, err := getPositionFile()
-			if err != nil {
-				return nil, err
-			}
-			cfTargetManager, err := cloudflare.NewTargetManager(cloudflareMetrics, logger, pos, client, scrapeConfigs)
-			if err != nil {
-				return nil, errors.Wrap(err, "failed to make cloudflare target manager")
-			}
-			targetManagers = append(targetManagers, cfTargetManager)
-		case DockerSDConfigs:
-			pos, err := getPositionFile()
-			if err != nil {
-				return nil, err
-			}
-			cfTargetManager, err := docker.NewTargetManager(dockerMetrics, logger, pos, client, scrapeConfigs, limitsConfig.MaxLineSize.Val())
-			if err != nil {
-				return nil, errors.Wrap(err, "failed to make Docker service discovery target manager")
-			}
-			targetManagers = append(targetManagers, cfTargetManager)
-		default:
-			return nil, errors.New("unknown scrape config")
+// newTargetManager creates the target manager of the scrape configs of a kind.
+func (tm *TargetManagers) newTargetManager(kind string, scrapeConfigs []scrapeconfig.Config) (targetManager, error) {
+	reg, logger, client := tm.reg, tm.logger, tm.client
+
+	switch kind {
+	case FileScrapeConfigs:
+		if fileMetrics == nil {
+			fileMetrics = file.NewMetrics(reg)
+		}
+		pos, err := tm.getPositionFile()
+		if err != nil {
+			return nil, err
+		}
+		fileTargetManager, err := file.NewFileTargetManager(
+			fileMetrics,
+			logger,
+			pos,
+			client,
+			scrapeConfigs,
+			tm.targetConfig,
+			tm.watchConfig,
+		)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to make file target manager")
+		}
+		return fileTargetManager, nil
+	case JournalScrapeConfigs:
+		if journalMetrics == nil {
+			journalMetrics = journal.NewMetrics(reg)
+		}
+		pos, err := tm.getPositionFile()
+		if err != nil {
+			return nil, err
+		}
+		journalTargetManager, err := journal.NewJournalTargetManager(
+			journalMetrics,
+			logger,
+			pos,
+			client,
+			scrapeConfigs,
+		)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to make journal target manager")
+		}
+		return journalTargetManager, nil
+	case SyslogScrapeConfigs:
+		if syslogMetrics == nil {
+			syslogMetrics = syslog.NewMetrics(reg)
+		}
+		syslogTargetManager, err := syslog.NewSyslogTargetManager(
+			syslogMetrics,
+			logger,
+			client,
+			scrapeConfigs,
+		)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to make syslog target manager")
+		}
+		return syslogTargetManager, nil
+	case GcplogScrapeConfigs:
+		if gcplogMetrics == nil {
+			gcplogMetrics = gcplog.NewMetrics(reg)
+		}
+		pubsubTargetManager, err := gcplog.NewGcplogTargetManager(
+			gcplogMetrics,
+			logger,
+			client,
+			scrapeConfigs,
+		)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to make gcplog target manager")
+		}
+		return pubsubTargetManager, nil
+	case PushScrapeConfigs:
+		pushTargetManager, err := lokipush.NewPushTargetManager(
+			reg,
+			logger,
+			client,
+			scrapeConfigs,
+		)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to make Loki Push API target manager")
 		}
+		return pushTargetManager, nil
+	case HerokuDrainConfigs:
+		if herokuDrainMetrics == nil {
+			herokuDrainMetrics = heroku.NewMetrics(reg)
+		}
+		herokuDrainTargetManager, err := heroku.NewHerokuDrainTargetManager(herokuDrainMetrics, reg, logger, client, scrapeConfigs)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to make Heroku drain target manager")
+		}
+		return herokuDrainTargetManager, nil
+	case KubernetesConfigs:
+		if kubernetesMetrics == nil {
+			kubernetesMetrics = kubernetes.NewMetrics(reg)
+		}
+		pos, err := tm.getPositionFile()
+		if err != nil {
+			return nil, err
+		}
+		kubernetesTargetManager, err := kubernetes.NewTargetManager(kubernetesMetrics, reg, logger, pos, client, scrapeConfigs)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to make Kubernetes target manager")
+		}
+		return kubernetesTargetManager, nil
+	case WindowsEventsConfigs:
+		windowsTargetManager, err := windows.NewTargetManager(reg, logger, client, scrapeConfigs)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to make windows target manager")
+		}
+		return windowsTargetManager, nil
+	case KafkaConfigs:
+		kafkaTargetManager, err := kafka.NewTargetManager(reg, logger, client, scrapeConfigs)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to make kafka target manager")
+		}
+		return kafkaTargetManager, nil
+	case AzureEventHubsScrapeConfigs:
+		azureEventHubsTargetManager, err := azureeventhubs.NewTargetManager(reg, logger, client, scrapeConfigs)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to make Azure Event Hubs target manager")
+		}
+		return azureEventHubsTargetManager, nil
+	case GelfConfigs:
+		if gelfMetrics == nil {
+			gelfMetrics = gelf.NewMetrics(reg)
+		}
+		gelfTargetManager, err := gelf.NewTargetManager(gelfMetrics, logger, client, scrapeConfigs)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to make gelf target manager")
+		}
+		return gelfTargetManager, nil
+	case CloudflareConfigs:
+		if cloudflareMetrics == nil {
+			cloudflareMetrics = cloudflare.NewMetrics(reg)
+		}
+		pos, err := tm.getPositionFile()
+		if err != nil {
+			return nil, err
+		}
+		cfTargetManager, err := cloudflare.NewTargetManager(cloudflareMetrics, logger, pos, client, scrapeConfigs)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to make cloudflare target manager")
+		}
+		return cfTargetManager, nil
+	case DockerSDConfigs:
+		if dockerMetrics == nil {
+			dockerMetrics = docker.NewMetrics(reg)
+		}
+		pos, err := tm.getPositionFile()
+		if err != nil {
+			return nil, err
+		}
+		cfTargetManager, err := docker.NewTargetManager(dockerMetrics, logger, pos, client, scrapeConfigs, tm.limitsConfig.MaxLineSize.Val())
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to make Docker service discovery target manager")
+		}
+		return cfTargetManager, nil
+	default:
+		return nil, errors.New("unknown scrape config")
 	}
+}
 
-	return &TargetManagers{
-		targetManagers: targetManagers,
-		positions:      positionFile,
-	}, nil
+// managers returns the running target managers.
+func (tm *TargetManagers) managers() []targetManager {
+	tm.mtx.Lock()
+	defer tm.mtx.Unlock()
+	managers := append([]targetManager(nil), tm.targetManagers...)
+	for _, k := range tm.kinds {
+		managers = append(managers, k.manager)
+	}
+	return managers
 }
 
 // ActiveTargets returns active targets per jobs
 func (tm *TargetManagers) ActiveTargets() map[string][]target.Target {
 	result := map[string][]target.Target{}
-	for _, t := range tm.targetManagers {
+	for _, t := range tm.managers() {
 		for job, targets := range t.ActiveTargets() {
 			result[job] = append(result[job], targets...)
 		}
@@ -305,7 +532,7 @@ func (tm *TargetManagers) ActiveTargets() map[string][]target.Target {
 // AllTargets returns all targets per jobs
 func (tm *TargetManagers) AllTargets() map[string][]target.Target {
 	result := map[string][]target.Target{}
-	for _, t := range tm.targetManagers {
+	for _, t := range tm.managers() {
 		for job, targets := range t.AllTargets() {
 			result[job] = append(result[job], targets...)
 		}
@@ -315,7 +542,7 @@ func (tm *TargetManagers) AllTargets() map[string][]target.Target {
 
 // Ready if there's at least one ready target manager.
 func (tm *TargetManagers) Ready() bool {
-	for _, t := range tm.targetManagers {
+	for _, t := range tm.managers() {
 		if t.Ready() {
 			return true
 		}
@@ -325,7 +552,7 @@ func (tm *TargetManagers) Ready() bool {
 
 // Stop the TargetManagers.
 func (tm *TargetManagers) Stop() {
-	for _, t := range tm.targetManagers {
+	for _, t := range tm.managers() {
 		t.Stop()
 	}
 	if tm.positions != nil {
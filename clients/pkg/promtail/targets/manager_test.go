@@ -0,0 +1,150 @@
+package targets
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/discovery"
+	"github.com/prometheus/prometheus/discovery/targetgroup"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/v3/clients/pkg/promtail/client/fake"
+	"github.com/grafana/loki/v3/clients/pkg/promtail/limit"
+	"github.com/grafana/loki/v3/clients/pkg/promtail/positions"
+	"github.com/grafana/loki/v3/clients/pkg/promtail/scrapeconfig"
+	"github.com/grafana/loki/v3/clients/pkg/promtail/targets/file"
+)
+
+func fileScrapeConfig(jobName, path string, labels model.LabelSet) scrapeconfig.Config {
+	return scrapeconfig.Config{
+		JobName: jobName,
+		ServiceDiscoveryConfig: scrapeconfig.ServiceDiscoveryConfig{
+			StaticConfigs: discovery.StaticConfig{
+				&targetgroup.Group{
+					Targets: []model.LabelSet{{"__path__": model.LabelValue(path)}},
+					Labels:  labels,
+				},
+			},
+		},
+	}
+}
+
+func TestTargetManagers_Reload(t *testing.T) {
+	dir := t.TempDir()
+	client := fake.New(func() {})
+	defer client.Stop()
+
+	tm, err := NewTargetManagers(
+		nil,
+		prometheus.NewRegistry(),
+		log.NewNopLogger(),
+		positions.Config{SyncPeriod: 10 * time.Second, PositionsFile: filepath.Join(dir, "positions.yml")},
+		client,
+		[]scrapeconfig.Config{
+			fileScrapeConfig("unchanged", filepath.Join(dir, "a.log"), model.LabelSet{"job": "a"}),
+			fileScrapeConfig("changed", filepath.Join(dir, "b.log"), model.LabelSet{"job": "b"}),
+			fileScrapeConfig("removed", filepath.Join(dir, "c.log"), model.LabelSet{"job": "c"}),
+		},
+		&file.Config{SyncPeriod: time.Second},
+		file.WatchConfig{MinPollFrequency: 250 * time.Millisecond, MaxPollFrequency: 250 * time.Millisecond},
+		&limit.Config{},
+	)
+	require.NoError(t, err)
+	defer tm.Stop()
+	require.True(t, tm.CanReload())
+
+	fileManager := tm.kinds[FileScrapeConfigs].manager
+	pos := tm.positions
+
+	require.NoError(t, tm.Reload([]scrapeconfig.Config{
+		fileScrapeConfig("unchanged", filepath.Join(dir, "a.log"), model.LabelSet{"job": "a"}),
+		fileScrapeConfig("changed", filepath.Join(dir, "b.log"), model.LabelSet{"job": "b2"}),
+		fileScrapeConfig("added", filepath.Join(dir, "d.log"), model.LabelSet{"job": "d"}),
+	}))
+
+	// The file jobs are reloaded by the running file target manager.
+	require.Len(t, tm.kinds, 1)
+	require.Same(t, fileManager, tm.kinds[FileScrapeConfigs].manager)
+	require.Same(t, pos, tm.positions)
+	jobs := tm.AllTargets()
+	require.Len(t, jobs, 3)
+	require.Contains(t, jobs, "added")
+	require.NotContains(t, jobs, "removed")
+
+	// A failing reload leaves the running target managers untouched.
+	config := tm.kinds[FileScrapeConfigs].config
+	require.Error(t, tm.Reload([]scrapeconfig.Config{
+		fileScrapeConfig("unchanged", filepath.Join(dir, "a.log"), model.LabelSet{"job": "a"}),
+		{
+			JobName:      "syslog",
+			SyslogConfig: &scrapeconfig.SyslogTargetConfig{ListenAddress: "invalid"},
+		},
+	}))
+	require.Len(t, tm.kinds, 1)
+	require.Same(t, fileManager, tm.kinds[FileScrapeConfigs].manager)
+	require.Equal(t, config, tm.kinds[FileScrapeConfigs].config)
+	require.Len(t, tm.AllTargets(), 3)
+
+	require.Error(t, tm.Reload([]scrapeconfig.Config{{JobName: "invalid"}}))
+	require.Len(t, tm.AllTargets(), 3)
+}
+
+func syslogScrapeConfig(jobName string, labels model.LabelSet) scrapeconfig.Config {
+	return scrapeconfig.Config{
+		JobName: jobName,
+		SyslogConfig: &scrapeconfig.SyslogTargetConfig{
+			ListenAddress: "127.0.0.1:0",
+			Labels:        labels,
+		},
+	}
+}
+
+func TestTargetManagers_ReloadPerJob(t *testing.T) {
+	client := fake.New(func() {})
+	defer client.Stop()
+
+	tm, err := NewTargetManagers(
+		nil,
+		prometheus.NewRegistry(),
+		log.NewNopLogger(),
+		positions.Config{SyncPeriod: 10 * time.Second, PositionsFile: filepath.Join(t.TempDir(), "positions.yml")},
+		client,
+		[]scrapeconfig.Config{
+			syslogScrapeConfig("unchanged", model.LabelSet{"job": "a"}),
+			syslogScrapeConfig("changed", model.LabelSet{"job": "b"}),
+			syslogScrapeConfig("removed", model.LabelSet{"job": "c"}),
+		},
+		&file.Config{SyncPeriod: time.Second},
+		file.WatchConfig{},
+		&limit.Config{},
+	)
+	require.NoError(t, err)
+	defer tm.Stop()
+
+	unchanged := tm.kinds[managerKey(SyslogScrapeConfigs, "unchanged")].manager
+	changed := tm.kinds[managerKey(SyslogScrapeConfigs, "changed")].manager
+	removed := tm.kinds[managerKey(SyslogScrapeConfigs, "removed")].manager
+
+	require.NoError(t, tm.Reload([]scrapeconfig.Config{
+		syslogScrapeConfig("unchanged", model.LabelSet{"job": "a"}),
+		syslogScrapeConfig("changed", model.LabelSet{"job": "b2"}),
+		syslogScrapeConfig("added", model.LabelSet{"job": "d"}),
+	}))
+
+	// Only the target managers of the changed jobs are restarted.
+	require.Len(t, tm.kinds, 3)
+	require.Same(t, unchanged, tm.kinds[managerKey(SyslogScrapeConfigs, "unchanged")].manager)
+	require.True(t, unchanged.Ready())
+	require.NotSame(t, changed, tm.kinds[managerKey(SyslogScrapeConfigs, "changed")].manager)
+	require.False(t, changed.Ready())
+	require.False(t, removed.Ready())
+
+	jobs := tm.ActiveTargets()
+	require.Len(t, jobs, 3)
+	require.Contains(t, jobs, "added")
+	require.NotContains(t, jobs, "removed")
+}
@@ -44,6 +44,12 @@ const (
 
 	// HerokuDrainTargetType is a Heroku Logs target
 	HerokuDrainTargetType = TargetType("HerokuDrain")
+
+	// KubernetesEventsTargetType is a Kubernetes Events API target
+	KubernetesEventsTargetType = TargetType("KubernetesEvents")
+
+	// KubernetesAuditTargetType is a Kubernetes audit webhook target
+	KubernetesAuditTargetType = TargetType("KubernetesAudit")
 )
 
 // Target is a promtail scrape target
@@ -20,6 +20,7 @@ import (
 	"github.com/grafana/loki/v3/pkg/logcli/client"
 	"github.com/grafana/loki/v3/pkg/logcli/delete"
 	"github.com/grafana/loki/v3/pkg/logcli/detected"
+	"github.com/grafana/loki/v3/pkg/logcli/explore"
 	"github.com/grafana/loki/v3/pkg/logcli/index"
 	"github.com/grafana/loki/v3/pkg/logcli/labelquery"
 	"github.com/grafana/loki/v3/pkg/logcli/output"
@@ -40,10 +41,10 @@ var (
 		Bool()
 	logLevel   = app.Flag("log.level", "Log level").Default("error").Enum("error", "warning", "info", "debug")
 	statistics = app.Flag("stats", "Show query statistics").Default("false").Bool()
-	outputMode = app.Flag("output", "Specify output mode [default, raw, jsonl]. raw suppresses log labels and timestamp.").
+	outputMode = app.Flag("output", "Specify output mode [default, raw, jsonl, csv, parquet]. raw suppresses log labels and timestamp. csv and parquet write a column per label, structured metadata and parsed label, and only support the query and instant-query commands.").
 			Default("default").
 			Short('o').
-			Enum("default", "raw", "jsonl")
+			Enum("default", "raw", "jsonl", "csv", "parquet")
 	timezone = app.Flag("timezone", "Specify the timezone to use when formatting output timestamps [Local, UTC]").
 			Default("Local").
 			Short('z').
@@ -58,6 +59,12 @@ var (
 			Default("").
 			String()
 	stdin = app.Flag("stdin", "Take input logs from stdin").Bool()
+	files = app.Flag("files", "Run queries on local files instead of Loki. Plain text, gzip, Loki chunk and data object files are supported. Can be repeated, and accepts glob patterns.").
+		Strings()
+	filesLabels = app.Flag("files-label", "Label added to the streams of the files given with --files, in the form name=value. Can be repeated.").
+			StringMap()
+	filesLabelRegex = app.Flag("files-label-regex", "Regular expression extracting labels from the paths of the files given with --files, with its named capture groups, eg '(?P<host>[^/]+)/(?P<app>[^/]+)\\.log'.").
+			Regexp()
 
 	queryClient = newQueryClient(app)
 
@@ -69,6 +76,9 @@ returned in a few output modes:
 	raw: log line
 	default: log timestamp + log labels + log line
 	jsonl: JSON response from Loki API of log line
+	csv: CSV file with a column per label, structured metadata and parsed label
+	parquet: Parquet file with a typed column per label, structured metadata
+	         and parsed label
 
 The output of the log can be specified with the "-o" flag, for
 example, "-o raw" for the raw output format.
@@ -179,7 +189,15 @@ printed to the terminal. The lines will be printed as soon as the next part is
 complete, you don't have to wait for all the parts to download before getting
 output. The --merge-parts flag will remove the part files when it is done
 reading each of them. To change this, you can use the --keep-parts flag, and
-the part files will not be removed.`)
+the part files will not be removed.
+
+The csv and parquet output modes write self-contained files which can't be
+merged, so --merge-parts can't be used with them. They always write part files,
+so --part-path-prefix is required and --parallel-duration can't be greater than
+24h, because the entries of a part are kept in memory until it's written. --limit
+is ignored. Their part files end in ".csv" or ".parquet" instead of ".part", for
+example /tmp/my_query_20210119T193000_20210119T194500.parquet. Running the same
+command again after an interruption only downloads the missing parts.`)
 	rangeQuery = newQuery(false, queryCmd)
 	tail       = queryCmd.Flag("tail", "Tail the logs").Short('t').Default("false").Bool()
 	follow     = queryCmd.Flag("follow", "Alias for --tail").Short('f').Default("false").Bool()
@@ -354,6 +372,24 @@ Example:
 	logcli delete cancel --request-id="abc123" --force
 `)
 	deleteCancelQuery = newDeleteCancelQuery(deleteCancelCmd)
+
+	exploreCmd = app.Command("explore", `Explore logs interactively in a terminal UI.
+
+The "explore" command starts from the label names: pick a label, then one of
+its values to add a matcher to the stream selector. The number of matching
+streams and a histogram of their volume are shown above their most recent
+logs. More matchers can be added from the label names (tab), and line
+filters or pipeline stages with "f". The last filter or matcher is removed
+with "u".
+
+Use "t" to tail the logs live, and enter to expand the labels, structured
+metadata and parsed labels of the selected line.
+
+Example:
+
+	logcli explore --since=6h '{app="web"}'
+`)
+	exploreQuery = newExploreQuery(exploreCmd)
 )
 
 func main() {
@@ -416,6 +452,28 @@ func main() {
 		rangeQuery.Limit = 0
 	}
 
+	if len(*files) > 0 {
+		c, err := client.NewLocalFilesClient(client.LocalFilesConfig{
+			Paths:      *files,
+			Labels:     *filesLabels,
+			LabelRegex: *filesLabelRegex,
+		})
+		if err != nil {
+			log.Fatalf("Unable to load files: %s", err)
+		}
+		queryClient = c
+		if rangeQuery.Step.Seconds() == 0 {
+			rangeQuery.Step = defaultQueryRangeStep(rangeQuery.Start, rangeQuery.End)
+		}
+
+		// As with `--stdin`, the stream selector is optional and selects all
+		// the files.
+		qs := strings.TrimSpace(rangeQuery.QueryString)
+		if strings.HasPrefix(qs, "|") || strings.HasPrefix(qs, "!") {
+			rangeQuery.QueryString = `{filename=~".+"}` + rangeQuery.QueryString
+		}
+	}
+
 	switch cmd {
 	case queryCmd.FullCommand():
 		location, err := time.LoadLocation(*timezone)
@@ -453,11 +511,18 @@ func main() {
 			log.Fatalf("Unable to create log output: %s", err)
 		}
 
+		requestCategorizedLabels(out)
+
 		if *tail || *follow {
+			if _, ok := out.(output.FileOutput); ok {
+				log.Fatalf("The %s output can't be used with --tail or --follow", *outputMode)
+			}
 			rangeQuery.TailQuery(time.Duration(*delayFor)*time.Second, queryClient, out)
-		} else if rangeQuery.ParallelMaxWorkers == 1 {
+		} else if _, ok := out.(output.FileOutput); !ok && rangeQuery.ParallelMaxWorkers == 1 {
 			rangeQuery.DoQuery(queryClient, out, *statistics)
 		} else {
+			// File outputs always write a part file per --parallel-duration,
+			// so that each worker only buffers a single part in memory.
 			// `--limit` doesn't make sense when using parallelism.
 			rangeQuery.Limit = 0
 			rangeQuery.DoQueryParallel(queryClient, out, *statistics)
@@ -479,6 +544,7 @@ func main() {
 			log.Fatalf("Unable to create log output: %s", err)
 		}
 
+		requestCategorizedLabels(out)
 		instantQuery.DoQuery(queryClient, out, *statistics)
 	case labelsCmd.FullCommand():
 		labelsQuery.DoLabels(queryClient)
@@ -532,6 +598,14 @@ func main() {
 		if err := deleteCancelQuery.CancelQuery(queryClient, deleteCancelQuery.RequestID, deleteCancelQuery.Force); err != nil {
 			log.Fatalf("Error cancelling delete request: %s", err)
 		}
+	case exploreCmd.FullCommand():
+		// Needed to expand the structured metadata and parsed labels of lines.
+		if c, ok := queryClient.(*client.DefaultClient); ok {
+			c.CategorizeLabels = true
+		}
+		if err := exploreQuery.Run(queryClient); err != nil {
+			log.Fatalf("Error running explorer: %s", err)
+		}
 	}
 }
 
@@ -551,6 +625,18 @@ func formatLogQL(r io.Reader, w io.Writer) error {
 	return nil
 }
 
+// requestCategorizedLabels asks Loki to return the structured metadata and
+// parsed labels apart from the stream labels, for the outputs writing them to
+// their own columns.
+func requestCategorizedLabels(out output.LogOutput) {
+	if _, ok := out.(output.FileOutput); !ok {
+		return
+	}
+	if c, ok := queryClient.(*client.DefaultClient); ok {
+		c.CategorizeLabels = true
+	}
+}
+
 func newQueryClient(app *kingpin.Application) client.Client {
 
 	client := &client.DefaultClient{
@@ -880,6 +966,32 @@ func newDeleteListQuery(cmd *kingpin.CmdClause) *delete.Query {
 	return q
 }
 
+func newExploreQuery(cmd *kingpin.CmdClause) *explore.Explore {
+	var selector string
+	q := &explore.Explore{}
+
+	cmd.Action(func(_ *kingpin.ParseContext) error {
+		if selector == "" {
+			return nil
+		}
+		matchers, err := syntax.ParseMatchers(selector, true)
+		if err != nil {
+			return fmt.Errorf("invalid stream selector: %w", err)
+		}
+		for _, m := range matchers {
+			q.Matchers = append(q.Matchers, m.String())
+		}
+		return nil
+	})
+
+	cmd.Arg("selector", "Initial stream selector, eg '{app=\"web\"}'").StringVar(&selector)
+	cmd.Flag("since", "Lookback window of the queries.").Default("1h").DurationVar(&q.Since)
+	cmd.Flag("limit", "Maximum number of lines shown.").Default("100").IntVar(&q.Limit)
+	cmd.Flag("delay-for", "Delay in tailing by number of seconds to accumulate logs for re-ordering").Default("0s").DurationVar(&q.DelayFor)
+
+	return q
+}
+
 func newDeleteCancelQuery(cmd *kingpin.CmdClause) *delete.Query {
 	q := &delete.Query{}
 
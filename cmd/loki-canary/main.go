@@ -4,6 +4,7 @@ import (
 	"crypto/tls"
 	"flag"
 	"fmt"
+	"io"
 	"net/http"
 	"os"
 	"os/signal"
@@ -33,6 +34,10 @@ const (
 type canary struct {
 	lock sync.Mutex
 
+	probes []*probe
+}
+
+type probe struct {
 	writer     *writer.Writer
 	reader     *reader.Reader
 	comparator *comparator.Comparator
@@ -46,6 +51,9 @@ func main() {
 	port := flag.Int("port", 3500, "Port which loki-canary should expose metrics")
 	addr := flag.String("addr", "", "The Loki server URL:Port, e.g. loki:3100")
 	push := flag.Bool("push", false, "Push the logs directly to given Loki address")
+	pushFormat := flag.String("push-format", string(writer.FormatLoki), "The format to push the logs with when -push is set, either 'loki' for the Loki push API or 'otlp' for the OTLP endpoint. "+
+		"With 'otlp', the -labelname and -streamname resource attributes must be stored as index labels by the otlp_config of the tenant")
+	structuredMetadata := flag.Bool("structured-metadata", false, "Attach structured metadata to the log entries pushed when -push is set, and verify it with the fidelity check")
 	useTLS := flag.Bool("tls", false, "Does the loki connection use TLS?")
 	certFile := flag.String("cert-file", "", "Client PEM encoded X.509 certificate for optional use with TLS connection to Loki")
 	keyFile := flag.String("key-file", "", "Client PEM encoded X.509 key for optional use with TLS connection to Loki")
@@ -91,6 +99,12 @@ func main() {
 	logBatchSize := flag.Int("logs-batch-size", writer.DefaultLogBatchSize, "Send logs to Loki in batches of a specified size.  Must be a non-negative value (0 or 1 will disable batching)")
 	logBatchSizeMax := flag.Int("logs-batch-size-max", writer.DefaultLogBatchSizeMax, "Upper bound on -logs-batch-size.  Only increase this value if you have increased memory limits for the canary pods")
 
+	fidelityCheckInterval := flag.Duration("fidelity-check-interval", 0, "Interval that the canary will query Loki for the entries written since the previous check, "+
+		"and verify their line, labels and structured metadata. 0 to disable the fidelity check")
+
+	profilesFile := flag.String("profiles-file", "", "YAML file of the profiles to run, each with its own tenant, push and query addresses, line size, interval and query-append. "+
+		"The flags set the defaults of the profiles, and the metrics are labeled with the name of the profile. Requires -push")
+
 	printVersion := flag.Bool("version", false, "Print this builds version information")
 
 	flag.Parse()
@@ -104,11 +118,16 @@ func main() {
 		*addr = os.Getenv("LOKI_ADDRESS")
 	}
 
-	if *addr == "" {
+	if *addr == "" && *profilesFile == "" {
 		_, _ = fmt.Fprintf(os.Stderr, "Must specify a Loki address with -addr or set the environment variable LOKI_ADDRESS\n")
 		os.Exit(1)
 	}
 
+	if *profilesFile != "" && !*push {
+		_, _ = fmt.Fprintf(os.Stderr, "Must set -push when specifying -profiles-file\n")
+		os.Exit(1)
+	}
+
 	if *outOfOrderPercentage < 0 || *outOfOrderPercentage > 100 {
 		_, _ = fmt.Fprintf(os.Stderr, "Out of order percentage must be between 0 and 100\n")
 		os.Exit(1)
@@ -129,6 +148,16 @@ func main() {
 		os.Exit(1)
 	}
 
+	if *pushFormat != string(writer.FormatLoki) && *pushFormat != string(writer.FormatOTLP) {
+		_, _ = fmt.Fprintf(os.Stderr, "-push-format must be either 'loki' or 'otlp'\n")
+		os.Exit(1)
+	}
+
+	if !*push && (*structuredMetadata || *pushFormat != string(writer.FormatLoki)) {
+		_, _ = fmt.Fprintf(os.Stderr, "Must set -push when specifying -structured-metadata or -push-format\n")
+		os.Exit(1)
+	}
+
 	var tlsConfig *tls.Config
 	tc := config.TLSConfig{}
 	if *certFile != "" || *keyFile != "" || *caFile != "" {
@@ -159,18 +188,37 @@ func main() {
 		}
 	}
 
-	sentChan := make(chan time.Time)
-	receivedChan := make(chan time.Time)
-
 	logger := log.NewLogfmtLogger(log.NewSyncWriter(os.Stderr))
 	logger = log.With(logger, "caller", log.Caller(3))
 
-	c := &canary{}
-	startCanary := func() {
-		c.stop()
+	defaultProfile := profile{
+		TenantID:    *tenantID,
+		PushAddr:    *addr,
+		QueryAddr:   *addr,
+		StreamValue: *sValue,
+		Size:        *size,
+		Interval:    *interval,
+		QueryAppend: *queryAppend,
+	}
+	profiles := []profile{defaultProfile}
+	if *profilesFile != "" {
+		var err error
+		profiles, err = loadProfiles(*profilesFile, defaultProfile)
+		if err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "Unable to load profiles: %s\n", err)
+			os.Exit(1)
+		}
+	}
 
-		c.lock.Lock()
-		defer c.lock.Unlock()
+	startProbe := func(p profile) *probe {
+		// Prefix the output of the profiles so that it can be told apart.
+		var out io.Writer = os.Stderr
+		logger, pushLogger := logger, log.NewLogfmtLogger(os.Stderr)
+		if p.Name != "" {
+			out = &prefixWriter{w: os.Stderr, prefix: fmt.Sprintf("profile=%s ", p.Name)}
+			logger = log.With(logger, "profile", p.Name)
+			pushLogger = log.With(pushLogger, "profile", p.Name)
+		}
 
 		var entryWriter writer.EntryWriter
 		if *push {
@@ -181,22 +229,24 @@ func main() {
 			}
 
 			push, err := writer.NewPush(
-				*addr,
-				*tenantID,
+				p.PushAddr,
+				p.TenantID,
 				*writeTimeout,
 				config.DefaultHTTPClientConfig,
 				*lName, *lVal,
-				*sName, *sValue,
+				*sName, p.StreamValue,
 				*useTLS,
 				tlsConfig,
 				*caFile, *certFile, *keyFile,
 				*user, *pass,
 				&backoffCfg,
 				*logBatchSize,
-				log.NewLogfmtLogger(os.Stderr),
+				writer.Format(*pushFormat),
+				*structuredMetadata,
+				pushLogger,
 			)
 			if err != nil {
-				_, _ = fmt.Fprintf(os.Stderr, "Unable to create writer for Loki, check config: %s", err)
+				_, _ = fmt.Fprintf(out, "Unable to create writer for Loki, check config: %s", err)
 				os.Exit(1)
 			}
 
@@ -205,14 +255,31 @@ func main() {
 			entryWriter = writer.NewStreamWriter(os.Stdout, logger)
 		}
 
-		c.writer = writer.NewWriter(entryWriter, sentChan, *interval, *outOfOrderMin, *outOfOrderMax, *outOfOrderPercentage, *size, logger)
+		sentChan := make(chan time.Time)
+		receivedChan := make(chan time.Time)
+
+		pr := &probe{}
+		pr.writer = writer.NewWriter(entryWriter, sentChan, p.Interval, *outOfOrderMin, *outOfOrderMax, *outOfOrderPercentage, p.Size, logger)
 		var err error
-		c.reader, err = reader.NewReader(os.Stderr, receivedChan, *useTLS, tlsConfig, *caFile, *certFile, *keyFile, *addr, *user, *pass, *tenantID, *queryTimeout, *lName, *lVal, *sName, *sValue, *interval, *queryAppend)
+		pr.reader, err = reader.NewReader(out, receivedChan, *useTLS, tlsConfig, *caFile, *certFile, *keyFile, p.QueryAddr, *user, *pass, p.TenantID, *queryTimeout, *lName, *lVal, *sName, p.StreamValue, p.Interval, p.QueryAppend)
 		if err != nil {
-			_, _ = fmt.Fprintf(os.Stderr, "Unable to create reader for Loki querier, check config: %s", err)
+			_, _ = fmt.Fprintf(out, "Unable to create reader for Loki querier, check config: %s", err)
 			os.Exit(1)
 		}
-		c.comparator = comparator.NewComparator(os.Stderr, *wait, *maxWait, *pruneInterval, *spotCheckInterval, *spotCheckMax, *spotCheckQueryRate, *spotCheckWait, *metricTestInterval, *metricTestQueryRange, *cacheTestInterval, *cacheTestQueryRange, *cacheTestQueryNow, *interval, *buckets, sentChan, receivedChan, c.reader, true)
+		pr.comparator = comparator.NewComparator(out, p.Name, *wait, *maxWait, *pruneInterval, *spotCheckInterval, *spotCheckMax, *spotCheckQueryRate, *spotCheckWait, *metricTestInterval, *metricTestQueryRange, *cacheTestInterval, *cacheTestQueryRange, *cacheTestQueryNow, *fidelityCheckInterval, map[string]string{*lName: *lVal, *sName: p.StreamValue}, *structuredMetadata, p.Interval, *buckets, sentChan, receivedChan, pr.reader, true)
+		return pr
+	}
+
+	c := &canary{}
+	startCanary := func() {
+		c.stop()
+
+		c.lock.Lock()
+		defer c.lock.Unlock()
+
+		for _, p := range profiles {
+			c.probes = append(c.probes, startProbe(p))
+		}
 	}
 
 	startCanary()
@@ -255,15 +322,24 @@ func (c *canary) stop() {
 	c.lock.Lock()
 	defer c.lock.Unlock()
 
-	if c.writer == nil || c.reader == nil || c.comparator == nil {
-		return
+	for _, p := range c.probes {
+		p.writer.Stop()
+		p.reader.Stop()
+		p.comparator.Stop()
 	}
+	c.probes = nil
+}
 
-	c.writer.Stop()
-	c.reader.Stop()
-	c.comparator.Stop()
+// prefixWriter prefixes everything written to w, which is written a line at a time.
+type prefixWriter struct {
+	w      io.Writer
+	prefix string
+}
 
-	c.writer = nil
-	c.reader = nil
-	c.comparator = nil
+func (p *prefixWriter) Write(b []byte) (int, error) {
+	// Write the prefix and the line at once, as the reader and comparator of a profile write concurrently.
+	if _, err := p.w.Write(append([]byte(p.prefix), b...)); err != nil {
+		return 0, err
+	}
+	return len(b), nil
 }
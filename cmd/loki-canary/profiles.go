@@ -0,0 +1,95 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+// profile is a probe run by the canary, which pushes its own stream to a tenant and reads it back
+// from a query endpoint. Its name is the value of the profile label of the comparator metrics.
+type profile struct {
+	Name        string        `yaml:"name"`
+	TenantID    string        `yaml:"tenant_id"`
+	PushAddr    string        `yaml:"push_addr"`
+	QueryAddr   string        `yaml:"query_addr"`
+	StreamValue string        `yaml:"stream_value"`
+	Size        int           `yaml:"size"`
+	Interval    time.Duration `yaml:"interval"`
+	QueryAppend string        `yaml:"query_append"`
+}
+
+type profilesConfig struct {
+	Profiles []profile `yaml:"profiles"`
+}
+
+// loadProfiles reads the profiles of the file. The fields which aren't set by a profile are set
+// from the defaults, except its stream value which defaults to its name so that every profile
+// reads back its own entries.
+func loadProfiles(file string, defaults profile) ([]profile, error) {
+	buf, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	var cfg profilesConfig
+	if err := yaml.UnmarshalStrict(buf, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse profiles file %s: %w", file, err)
+	}
+	if len(cfg.Profiles) == 0 {
+		return nil, fmt.Errorf("no profiles defined in %s", file)
+	}
+
+	names := map[string]struct{}{}
+	streams := map[[2]string]string{}
+	for i := range cfg.Profiles {
+		p := &cfg.Profiles[i]
+		if p.Name == "" {
+			return nil, fmt.Errorf("profile %d has no name", i)
+		}
+		if _, ok := names[p.Name]; ok {
+			return nil, fmt.Errorf("duplicate profile %s", p.Name)
+		}
+		names[p.Name] = struct{}{}
+
+		if p.TenantID == "" {
+			p.TenantID = defaults.TenantID
+		}
+		if p.PushAddr == "" {
+			p.PushAddr = defaults.PushAddr
+		}
+		if p.QueryAddr == "" {
+			p.QueryAddr = p.PushAddr
+		}
+		if p.StreamValue == "" {
+			p.StreamValue = p.Name
+		}
+		if p.Size == 0 {
+			p.Size = defaults.Size
+		}
+		if p.Interval == 0 {
+			p.Interval = defaults.Interval
+		}
+		if p.QueryAppend == "" {
+			p.QueryAppend = defaults.QueryAppend
+		}
+
+		if p.PushAddr == "" {
+			return nil, fmt.Errorf("profile %s has no push address, and no default address is set", p.Name)
+		}
+		if p.Interval < 0 || p.Size < 0 {
+			return nil, fmt.Errorf("profile %s must not have a negative interval or size", p.Name)
+		}
+
+		// Profiles writing the same stream of a tenant would read back the entries of each other.
+		stream := [2]string{p.TenantID, p.StreamValue}
+		if other, ok := streams[stream]; ok {
+			return nil, fmt.Errorf("profiles %s and %s write the same stream %q of tenant %q", other, p.Name, p.StreamValue, p.TenantID)
+		}
+		streams[stream] = p.Name
+	}
+
+	return cfg.Profiles, nil
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_loadProfiles(t *testing.T) {
+	defaults := profile{
+		TenantID:    "default",
+		PushAddr:    "loki:3100",
+		QueryAddr:   "loki:3100",
+		StreamValue: "stdout",
+		Size:        100,
+		Interval:    time.Second,
+		QueryAppend: "| logfmt",
+	}
+
+	for name, tc := range map[string]struct {
+		config   string
+		expected []profile
+		err      string
+	}{
+		"defaults": {
+			config: `
+profiles:
+  - name: frontend
+  - name: querier
+    tenant_id: tenant-b
+    push_addr: distributor:3100
+    query_addr: querier:3100
+    stream_value: direct
+    size: 1024
+    interval: 500ms
+    query_append: "| json"
+`,
+			expected: []profile{
+				{Name: "frontend", TenantID: "default", PushAddr: "loki:3100", QueryAddr: "loki:3100", StreamValue: "frontend", Size: 100, Interval: time.Second, QueryAppend: "| logfmt"},
+				{Name: "querier", TenantID: "tenant-b", PushAddr: "distributor:3100", QueryAddr: "querier:3100", StreamValue: "direct", Size: 1024, Interval: 500 * time.Millisecond, QueryAppend: "| json"},
+			},
+		},
+		"query address defaults to the push address": {
+			config: `
+profiles:
+  - name: a
+    push_addr: distributor:3100
+`,
+			expected: []profile{
+				{Name: "a", TenantID: "default", PushAddr: "distributor:3100", QueryAddr: "distributor:3100", StreamValue: "a", Size: 100, Interval: time.Second, QueryAppend: "| logfmt"},
+			},
+		},
+		"no profiles": {
+			config: `profiles: []`,
+			err:    "no profiles defined",
+		},
+		"missing name": {
+			config: `
+profiles:
+  - tenant_id: a
+`,
+			err: "profile 0 has no name",
+		},
+		"duplicate name": {
+			config: `
+profiles:
+  - name: a
+  - name: a
+    tenant_id: b
+`,
+			err: "duplicate profile a",
+		},
+		"same stream": {
+			config: `
+profiles:
+  - name: a
+    stream_value: s
+  - name: b
+    stream_value: s
+`,
+			err: "profiles a and b write the same stream",
+		},
+		"unknown field": {
+			config: `
+profiles:
+  - name: a
+    tenant: b
+`,
+			err: "field tenant not found",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			file := filepath.Join(t.TempDir(), "profiles.yaml")
+			require.NoError(t, os.WriteFile(file, []byte(tc.config), 0o600))
+
+			profiles, err := loadProfiles(file, defaults)
+			if tc.err != "" {
+				require.ErrorContains(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, profiles)
+		})
+	}
+}
@@ -1,9 +1,11 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"os"
-	"time"
+	"os/signal"
+	"syscall"
 
 	"github.com/go-kit/log/level"
 	"github.com/grafana/dskit/log"
@@ -71,6 +73,14 @@ func main() {
 		os.Exit(1)
 	}
 
+	if cfg.ProxyConfig.Replay.File != "" {
+		if err := replay(proxy, cfg.ProxyConfig.Replay.File); err != nil {
+			level.Error(util_log.Logger).Log("msg", "Unable to replay the queries", "err", err.Error())
+			os.Exit(1)
+		}
+		return
+	}
+
 	if err := proxy.Start(); err != nil {
 		level.Error(util_log.Logger).Log("msg", "Unable to start the proxy", "err", err.Error())
 		os.Exit(1)
@@ -79,13 +89,24 @@ func main() {
 	proxy.Await()
 }
 
+func replay(proxy *querytee.Proxy, file string) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	defer proxy.Stop()
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	stats, err := proxy.Replay(ctx, f)
+	level.Info(util_log.Logger).Log("msg", "Replay done", "file", file, "replayed", stats.Replayed, "failed", stats.Failed, "skipped", stats.Skipped)
+	return err
+}
+
 func lokiReadRoutes(cfg Config) []querytee.Route {
-	samplesComparator := querytee.NewSamplesComparator(querytee.SampleComparisonOptions{
-		Tolerance:         cfg.ProxyConfig.ValueComparisonTolerance,
-		UseRelativeError:  cfg.ProxyConfig.UseRelativeError,
-		SkipRecentSamples: cfg.ProxyConfig.SkipRecentSamples,
-		SkipSamplesBefore: time.Time(cfg.ProxyConfig.SkipSamplesBefore),
-	})
+	samplesComparator := querytee.NewSamplesComparator(cfg.ProxyConfig.SampleComparisonOptions())
 
 	return []querytee.Route{
 		{Path: "/loki/api/v1/query_range", RouteName: "api_v1_query_range", Methods: []string{"GET", "POST"}, ResponseComparator: samplesComparator},
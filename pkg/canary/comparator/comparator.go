@@ -5,6 +5,8 @@ import (
 	"io"
 	"math"
 	"math/rand"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -13,6 +15,8 @@ import (
 	"github.com/prometheus/client_golang/prometheus/promauto"
 
 	"github.com/grafana/loki/v3/pkg/canary/reader"
+	"github.com/grafana/loki/v3/pkg/canary/writer"
+	"github.com/grafana/loki/v3/pkg/loghttp"
 )
 
 const (
@@ -25,84 +29,110 @@ const (
 	DebugWebsocketMissingEntry   = "websocket missing entry: %v\n"
 	DebugQueryResult             = "confirmation query result: %v\n"
 	DebugEntryFound              = "missing websocket entry %v was found %v seconds after it was originally sent\n"
+	ErrFidelityCheckMismatch     = "fidelity check found a %s mismatch for entry %v of stream %v: %s\n"
 
 	floatDiffTolerance = 1e-6
 )
 
+// counterVec and gaugeVec are the metrics labeled with the profile of the comparator, which are mocked in tests.
+type counterVec interface {
+	WithLabelValues(lvs ...string) prometheus.Counter
+}
+
+type gaugeVec interface {
+	WithLabelValues(lvs ...string) prometheus.Gauge
+}
+
 var (
-	totalEntries = promauto.NewCounter(prometheus.CounterOpts{
+	totalEntries counterVec = promauto.NewCounterVec(prometheus.CounterOpts{
 		Namespace: "loki_canary",
 		Name:      "entries_total",
 		Help:      "counts log entries written to the file",
-	})
-	outOfOrderEntries = promauto.NewCounter(prometheus.CounterOpts{
+	}, []string{"profile"})
+	outOfOrderEntries counterVec = promauto.NewCounterVec(prometheus.CounterOpts{
 		Namespace: "loki_canary",
 		Name:      "out_of_order_entries_total",
 		Help:      "counts log entries received with a timestamp more recent than the others in the queue",
-	})
-	wsMissingEntries = promauto.NewCounter(prometheus.CounterOpts{
+	}, []string{"profile"})
+	wsMissingEntries counterVec = promauto.NewCounterVec(prometheus.CounterOpts{
 		Namespace: "loki_canary",
 		Name:      "websocket_missing_entries_total",
 		Help:      "counts log entries not received within the wait duration via the websocket connection",
-	})
-	missingEntries = promauto.NewCounter(prometheus.CounterOpts{
+	}, []string{"profile"})
+	missingEntries counterVec = promauto.NewCounterVec(prometheus.CounterOpts{
 		Namespace: "loki_canary",
 		Name:      "missing_entries_total",
 		Help:      "counts log entries not received within the maxWait duration via both websocket and direct query",
-	})
-	spotCheckMissing = promauto.NewCounter(prometheus.CounterOpts{
+	}, []string{"profile"})
+	spotCheckMissing counterVec = promauto.NewCounterVec(prometheus.CounterOpts{
 		Namespace: "loki_canary",
 		Name:      "spot_check_missing_entries_total",
 		Help:      "counts log entries not received when directly queried as part of spot checking",
-	})
-	spotCheckEntries = promauto.NewCounter(prometheus.CounterOpts{
+	}, []string{"profile"})
+	spotCheckEntries counterVec = promauto.NewCounterVec(prometheus.CounterOpts{
 		Namespace: "loki_canary",
 		Name:      "spot_check_entries_total",
 		Help:      "total count of entries pot checked",
-	})
-	unexpectedEntries = promauto.NewCounter(prometheus.CounterOpts{
+	}, []string{"profile"})
+	unexpectedEntries counterVec = promauto.NewCounterVec(prometheus.CounterOpts{
 		Namespace: "loki_canary",
 		Name:      "unexpected_entries_total",
 		Help:      "counts a log entry received which was not expected (e.g. received after reported missing)",
-	})
-	duplicateEntries = promauto.NewCounter(prometheus.CounterOpts{
+	}, []string{"profile"})
+	duplicateEntries counterVec = promauto.NewCounterVec(prometheus.CounterOpts{
 		Namespace: "loki_canary",
 		Name:      "duplicate_entries_total",
 		Help:      "counts a log entry received more than one time",
-	})
-	metricTestExpected = promauto.NewGauge(prometheus.GaugeOpts{
+	}, []string{"profile"})
+	metricTestExpected gaugeVec = promauto.NewGaugeVec(prometheus.GaugeOpts{
 		Namespace: "loki_canary",
 		Name:      "metric_test_expected",
 		Help:      "How many counts were expected by the metric test query",
-	})
-	metricTestActual = promauto.NewGauge(prometheus.GaugeOpts{
+	}, []string{"profile"})
+	metricTestActual gaugeVec = promauto.NewGaugeVec(prometheus.GaugeOpts{
 		Namespace: "loki_canary",
 		Name:      "metric_test_actual",
 		Help:      "How many counts were actually received by the metric test query",
-	})
-	responseLatency   prometheus.Histogram
-	metricTestLatency = promauto.NewHistogram(prometheus.HistogramOpts{
+	}, []string{"profile"})
+	responseLatency   *prometheus.HistogramVec
+	metricTestLatency = promauto.NewHistogramVec(prometheus.HistogramOpts{
 		Namespace: "loki_canary",
 		Name:      "metric_test_request_duration_seconds",
 		Help:      "how long the metric test query execution took in seconds.",
 		Buckets:   instrument.DefBuckets,
-	})
-	spotTestLatency = promauto.NewHistogram(prometheus.HistogramOpts{
+	}, []string{"profile"})
+	spotTestLatency = promauto.NewHistogramVec(prometheus.HistogramOpts{
 		Namespace: "loki_canary",
 		Name:      "spot_check_request_duration_seconds",
 		Help:      "how long the spot check test query execution took in seconds.",
 		Buckets:   instrument.DefBuckets,
-	})
-	queryResultsDiff = promauto.NewCounter(prometheus.CounterOpts{
+	}, []string{"profile"})
+	queryResultsDiff counterVec = promauto.NewCounterVec(prometheus.CounterOpts{
 		Namespace: "loki_canary",
 		Name:      "cache_test_query_results_diff_total",
 		Help:      "counts number of times the query results was different with and without cache ",
-	})
+	}, []string{"profile"})
 	queryResultsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
 		Namespace: "loki_canary",
 		Name:      "cache_test_query_results_total",
 		Help:      "counts number of times the query results test requests are done ",
-	}, []string{"status"}) // status=success/failure
+	}, []string{"profile", "status"}) // status=success/failure
+	fidelityCheckEntries counterVec = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "loki_canary",
+		Name:      "fidelity_check_entries_total",
+		Help:      "counts log entries read back from Loki and verified by the fidelity check",
+	}, []string{"profile"})
+	fidelityCheckMismatches = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "loki_canary",
+		Name:      "fidelity_check_mismatches_total",
+		Help:      "counts log entries read back from Loki which don't match what was written, by the check which failed",
+	}, []string{"profile", "check"}) // check=line/labels/structured_metadata
+	fidelityCheckLatency = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "loki_canary",
+		Name:      "fidelity_check_request_duration_seconds",
+		Help:      "how long the fidelity check query execution took in seconds.",
+		Buckets:   instrument.DefBuckets,
+	}, []string{"profile"})
 )
 
 type Comparator struct {
@@ -113,7 +143,9 @@ type Comparator struct {
 	metTestMtx          sync.Mutex // Locks metricTestRunning for single threaded but async metricTest()
 	cacheTestMtx        sync.Mutex // Locks cacheTestRunning for single threaded but async cacheTest()
 	pruneMtx            sync.Mutex // Locks pruneEntriesRunning for single threaded but async pruneEntries()
+	fidelityMtx         sync.Mutex // Locks fidelityCheckRunning for single threaded but async fidelityCheck()
 	w                   io.Writer
+	profile             string
 	entries             []*time.Time
 	missingEntries      []*time.Time
 	spotCheck           []*time.Time
@@ -143,9 +175,18 @@ type Comparator struct {
 	rdr              reader.LokiReader
 	quit             chan struct{}
 	done             chan struct{}
+
+	// fidelityCheckLabels are the labels expected on the streams read back from Loki, and
+	// structuredMetadata whether the entries are expected to have structured metadata.
+	fidelityCheckInterval time.Duration
+	fidelityCheckLabels   map[string]string
+	fidelityCheckEnd      time.Time
+	fidelityCheckRunning  bool
+	structuredMetadata    bool
 }
 
 func NewComparator(writer io.Writer,
+	profile string,
 	wait time.Duration,
 	maxWait time.Duration,
 	pruneInterval time.Duration,
@@ -155,6 +196,9 @@ func NewComparator(writer io.Writer,
 	cacheTestInterval time.Duration,
 	cacheTestRange time.Duration,
 	cacheTestNow time.Duration,
+	fidelityCheckInterval time.Duration,
+	fidelityCheckLabels map[string]string,
+	structuredMetadata bool,
 	writeInterval time.Duration,
 	buckets int,
 	sentChan chan time.Time,
@@ -162,42 +206,47 @@ func NewComparator(writer io.Writer,
 	reader reader.LokiReader,
 	confirmAsync bool) *Comparator {
 	c := &Comparator{
-		w:                   writer,
-		entries:             []*time.Time{},
-		spotCheck:           []*time.Time{},
-		wait:                wait,
-		maxWait:             maxWait,
-		pruneInterval:       pruneInterval,
-		pruneEntriesRunning: false,
-		spotCheckInterval:   spotCheckInterval,
-		spotCheckMax:        spotCheckMax,
-		spotCheckQueryRate:  spotCheckQueryRate,
-		spotCheckWait:       spotCheckWait,
-		spotCheckRunning:    false,
-		metricTestInterval:  metricTestInterval,
-		metricTestRange:     metricTestRange,
-		metricTestRunning:   false,
-		cacheTestInterval:   cacheTestInterval,
-		cacheTestRange:      cacheTestRange,
-		cacheTestNow:        cacheTestNow,
-		cacheTestRunning:    false,
-		writeInterval:       writeInterval,
-		confirmAsync:        confirmAsync,
-		startTime:           time.Now(),
-		sent:                sentChan,
-		recv:                receivedChan,
-		rdr:                 reader,
-		quit:                make(chan struct{}),
-		done:                make(chan struct{}),
+		w:                     writer,
+		profile:               profile,
+		entries:               []*time.Time{},
+		spotCheck:             []*time.Time{},
+		wait:                  wait,
+		maxWait:               maxWait,
+		pruneInterval:         pruneInterval,
+		pruneEntriesRunning:   false,
+		spotCheckInterval:     spotCheckInterval,
+		spotCheckMax:          spotCheckMax,
+		spotCheckQueryRate:    spotCheckQueryRate,
+		spotCheckWait:         spotCheckWait,
+		spotCheckRunning:      false,
+		metricTestInterval:    metricTestInterval,
+		metricTestRange:       metricTestRange,
+		metricTestRunning:     false,
+		cacheTestInterval:     cacheTestInterval,
+		cacheTestRange:        cacheTestRange,
+		cacheTestNow:          cacheTestNow,
+		cacheTestRunning:      false,
+		fidelityCheckInterval: fidelityCheckInterval,
+		fidelityCheckLabels:   fidelityCheckLabels,
+		structuredMetadata:    structuredMetadata,
+		writeInterval:         writeInterval,
+		confirmAsync:          confirmAsync,
+		startTime:             time.Now(),
+		sent:                  sentChan,
+		recv:                  receivedChan,
+		rdr:                   reader,
+		quit:                  make(chan struct{}),
+		done:                  make(chan struct{}),
 	}
+	c.fidelityCheckEnd = c.startTime
 
 	if responseLatency == nil {
-		responseLatency = promauto.NewHistogram(prometheus.HistogramOpts{
+		responseLatency = promauto.NewHistogramVec(prometheus.HistogramOpts{
 			Namespace: "loki_canary",
 			Name:      "response_latency_seconds",
 			Help:      "is how long it takes for log lines to be returned from Loki in seconds.",
 			Buckets:   prometheus.ExponentialBuckets(0.5, 2, buckets),
-		})
+		}, []string{"profile"})
 	}
 
 	go c.run()
@@ -216,7 +265,7 @@ func (c *Comparator) Stop() {
 func (c *Comparator) entrySent(ts time.Time) {
 	c.entMtx.Lock()
 	c.entries = append(c.entries, &ts)
-	totalEntries.Inc()
+	totalEntries.WithLabelValues(c.profile).Inc()
 	c.entMtx.Unlock()
 	//If this entry equals or exceeds the spot check interval from the last entry in the spot check array, add it.
 	c.spotEntMtx.Lock()
@@ -240,10 +289,10 @@ func (c *Comparator) entryReceived(ts time.Time) {
 			matched = true
 			// If this isn't the first item in the list we received it out of order
 			if i != 0 {
-				outOfOrderEntries.Inc()
+				outOfOrderEntries.WithLabelValues(c.profile).Inc()
 				fmt.Fprintf(c.w, ErrOutOfOrderEntry, t, c.entries[:i])
 			}
-			responseLatency.Observe(time.Since(ts).Seconds())
+			responseLatency.WithLabelValues(c.profile).Observe(time.Since(ts).Seconds())
 			// Put this element in the acknowledged entries list so we can use it to check for duplicates
 			c.ackdEntries = append(c.ackdEntries, c.entries[i])
 		})
@@ -253,14 +302,14 @@ func (c *Comparator) entryReceived(ts time.Time) {
 		for _, e := range c.ackdEntries {
 			if ts.Equal(*e) {
 				duplicate = true
-				duplicateEntries.Inc()
+				duplicateEntries.WithLabelValues(c.profile).Inc()
 				fmt.Fprintf(c.w, ErrDuplicateEntry, ts.UnixNano())
 				break
 			}
 		}
 		if !duplicate {
 			fmt.Fprintf(c.w, ErrUnexpectedEntry, ts.UnixNano())
-			unexpectedEntries.Inc()
+			unexpectedEntries.WithLabelValues(c.profile).Inc()
 		}
 	}
 }
@@ -279,6 +328,13 @@ func (c *Comparator) run() {
 	mt := time.NewTicker(time.Duration(randomGenerator.Int63n(c.metricTestInterval.Nanoseconds())))
 	sc := time.NewTicker(c.spotCheckQueryRate)
 	ct := time.NewTicker(c.cacheTestInterval)
+	// The fidelity check is disabled with a zero interval, in which case fc is never ready.
+	var fc <-chan time.Time
+	if c.fidelityCheckInterval > 0 {
+		ft := time.NewTicker(c.fidelityCheckInterval)
+		defer ft.Stop()
+		fc = ft.C
+	}
 	defer func() {
 		t.Stop()
 		mt.Stop()
@@ -330,6 +386,14 @@ func (c *Comparator) run() {
 				go c.cacheTest(time.Now())
 			}
 			c.cacheTestMtx.Unlock()
+		case <-fc:
+			// Only run one instance of fidelity check at a time.
+			c.fidelityMtx.Lock()
+			if !c.fidelityCheckRunning {
+				c.fidelityCheckRunning = true
+				go c.fidelityCheck(time.Now())
+			}
+			c.fidelityMtx.Unlock()
 
 		case <-c.quit:
 			return
@@ -362,7 +426,7 @@ func (c *Comparator) cacheTest(currTime time.Time) {
 	countCache, err := c.rdr.QueryCountOverTime(rng, queryStartTime, true)
 	if err != nil {
 		fmt.Fprintf(c.w, "error running cache query test with cache: %s\n", err.Error())
-		queryResultsTotal.WithLabelValues("failure").Inc()
+		queryResultsTotal.WithLabelValues(c.profile, "failure").Inc()
 		return
 	}
 
@@ -370,13 +434,13 @@ func (c *Comparator) cacheTest(currTime time.Time) {
 	countNocache, err := c.rdr.QueryCountOverTime(rng, queryStartTime, false)
 	if err != nil {
 		fmt.Fprintf(c.w, "error running cache query test without cache: %s\n", err.Error())
-		queryResultsTotal.WithLabelValues("failure").Inc()
+		queryResultsTotal.WithLabelValues(c.profile, "failure").Inc()
 		return
 	}
 
-	queryResultsTotal.WithLabelValues("success").Inc()
+	queryResultsTotal.WithLabelValues(c.profile, "success").Inc()
 	if math.Abs(countNocache-countCache) > floatDiffTolerance {
-		queryResultsDiff.Inc()
+		queryResultsDiff.WithLabelValues(c.profile).Inc()
 		fmt.Fprintf(c.w, "found a diff in instant query results time: %s, result_with_cache: %v, result_without_cache: %v\n", queryStartTime, countCache, countNocache)
 	}
 }
@@ -399,14 +463,14 @@ func (c *Comparator) metricTest(currTime time.Time) {
 	}
 	begin := time.Now()
 	actualCount, err := c.rdr.QueryCountOverTime(fmt.Sprintf("%.0fs", adjustedRange.Seconds()), begin, true)
-	metricTestLatency.Observe(time.Since(begin).Seconds())
+	metricTestLatency.WithLabelValues(c.profile).Observe(time.Since(begin).Seconds())
 	if err != nil {
 		fmt.Fprintf(c.w, "error running metric query test: %s\n", err.Error())
 		return
 	}
 	expectedCount := float64(adjustedRange.Milliseconds()) / float64(c.writeInterval.Milliseconds())
-	metricTestExpected.Set(expectedCount)
-	metricTestActual.Set(actualCount)
+	metricTestExpected.WithLabelValues(c.profile).Set(expectedCount)
+	metricTestActual.WithLabelValues(c.profile).Set(actualCount)
 }
 
 // spotCheck is used to ensure that log data is actually available after being flushed from the
@@ -441,7 +505,7 @@ func (c *Comparator) spotCheckEntries(currTime time.Time) {
 		if currTime.Sub(*sce) < c.spotCheckWait {
 			continue
 		}
-		spotCheckEntries.Inc()
+		spotCheckEntries.WithLabelValues(c.profile).Inc()
 		// Because we are querying loki timestamps vs the timestamp in the log,
 		// make the range +/- 10 seconds to allow for clock inaccuracies
 		start := *sce
@@ -449,7 +513,7 @@ func (c *Comparator) spotCheckEntries(currTime time.Time) {
 		adjustedEnd := start.Add(10 * time.Second)
 		begin := time.Now()
 		recvd, err := c.rdr.Query(adjustedStart, adjustedEnd)
-		spotTestLatency.Observe(time.Since(begin).Seconds())
+		spotTestLatency.WithLabelValues(c.profile).Observe(time.Since(begin).Seconds())
 		if err != nil {
 			fmt.Fprintf(c.w, "error querying loki: %s\n", err)
 			return
@@ -467,10 +531,95 @@ func (c *Comparator) spotCheckEntries(currTime time.Time) {
 			for _, r := range recvd {
 				fmt.Fprintf(c.w, DebugQueryResult, r.UnixNano())
 			}
-			spotCheckMissing.Inc()
+			spotCheckMissing.WithLabelValues(c.profile).Inc()
+		}
+	}
+
+}
+
+// fidelityCheck is used to ensure that the log entries read back from Loki are the ones which were written,
+// with the same line, the expected stream labels, and the structured metadata attached to them when enabled.
+// Every run checks the entries written since the end of the range checked by the previous run, up to
+// spotCheckWait ago to make sure they are available. The range is queried page by page, since every
+// query returns at most reader.QueryLimit entries.
+func (c *Comparator) fidelityCheck(currTime time.Time) {
+	// Always make sure to set the running state back to false
+	defer func() {
+		c.fidelityMtx.Lock()
+		c.fidelityCheckRunning = false
+		c.fidelityMtx.Unlock()
+	}()
+
+	end := currTime.Add(-c.spotCheckWait)
+	for c.fidelityCheckEnd.Before(end) {
+		begin := time.Now()
+		streams, err := c.rdr.QueryStreams(c.fidelityCheckEnd, end)
+		fidelityCheckLatency.WithLabelValues(c.profile).Observe(time.Since(begin).Seconds())
+		if err != nil {
+			fmt.Fprintf(c.w, "error running fidelity check query: %s\n", err)
+			return
+		}
+
+		var count int
+		var last time.Time
+		for _, stream := range streams {
+			for _, entry := range stream.Entries {
+				count++
+				if entry.Timestamp.After(last) {
+					last = entry.Timestamp
+				}
+				fidelityCheckEntries.WithLabelValues(c.profile).Inc()
+				for check, msg := range c.verifyEntry(stream.Labels, entry) {
+					fidelityCheckMismatches.WithLabelValues(c.profile, check).Inc()
+					fmt.Fprintf(c.w, ErrFidelityCheckMismatch, check, entry.Timestamp.UnixNano(), stream.Labels, msg)
+				}
+			}
+		}
+
+		// Only move the range forward on success, so the entries are checked by the next run otherwise.
+		// If the query hit the limit, the entries after the last returned one are checked by the next page.
+		if count < reader.QueryLimit {
+			c.fidelityCheckEnd = end
+			return
+		}
+		c.fidelityCheckEnd = last.Add(time.Nanosecond)
+	}
+}
+
+// verifyEntry returns the checks failed by an entry read back from Loki, with the reason of the mismatch.
+func (c *Comparator) verifyEntry(lbls loghttp.LabelSet, entry loghttp.Entry) map[string]string {
+	mismatches := map[string]string{}
+
+	// The line is the timestamp of the entry followed by padding.
+	sp := strings.SplitN(entry.Line, " ", 2)
+	if len(sp) != 2 {
+		mismatches["line"] = fmt.Sprintf("invalid line %q", entry.Line)
+	} else if sp[0] != strconv.FormatInt(entry.Timestamp.UnixNano(), 10) {
+		mismatches["line"] = fmt.Sprintf("line timestamp %s doesn't match the entry timestamp", sp[0])
+	} else if pad := strings.TrimSuffix(sp[1], "\n"); strings.Trim(pad, "p") != "" {
+		mismatches["line"] = fmt.Sprintf("invalid line padding %q", pad)
+	}
+
+	for name, value := range c.fidelityCheckLabels {
+		if v, ok := lbls[name]; !ok || v != value {
+			mismatches["labels"] = fmt.Sprintf("expected label %s=%q", name, value)
+			break
+		}
+	}
+
+	if c.structuredMetadata {
+		for _, l := range writer.StructuredMetadata(entry.Timestamp) {
+			if _, ok := lbls[l.Name]; ok {
+				mismatches["labels"] = fmt.Sprintf("structured metadata %s was stored as a label", l.Name)
+			}
+			if v := entry.StructuredMetadata.Get(l.Name); v != l.Value {
+				mismatches["structured_metadata"] = fmt.Sprintf("expected %s=%q, got %q", l.Name, l.Value, v)
+				break
+			}
 		}
 	}
 
+	return mismatches
 }
 
 func (c *Comparator) pruneEntries(currentTime time.Time) {
@@ -491,7 +640,7 @@ func (c *Comparator) pruneEntries(currentTime time.Time) {
 		},
 		func(_ int, t *time.Time) {
 			missing = append(missing, t)
-			wsMissingEntries.Inc()
+			wsMissingEntries.WithLabelValues(c.profile).Inc()
 			fmt.Fprintf(c.w, ErrEntryNotReceivedWs, t.UnixNano(), c.wait.Seconds())
 		})
 
@@ -589,7 +738,7 @@ func (c *Comparator) confirmMissing(currentTime time.Time) {
 
 	// Record the entries which were removed and never received
 	for _, e := range removed {
-		missingEntries.Inc()
+		missingEntries.WithLabelValues(c.profile).Inc()
 		fmt.Fprintf(c.w, ErrEntryNotReceived, e.UnixNano(), c.maxWait.Seconds())
 	}
 }
@@ -3,23 +3,31 @@ package comparator
 import (
 	"bytes"
 	"fmt"
+	"sort"
+	"strconv"
 	"sync"
 	"testing"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	io_prometheus_client "github.com/prometheus/client_model/go"
+	"github.com/prometheus/prometheus/model/labels"
 	"github.com/stretchr/testify/assert"
+
+	"github.com/grafana/loki/v3/pkg/canary/reader"
+	"github.com/grafana/loki/v3/pkg/canary/writer"
+	"github.com/grafana/loki/v3/pkg/loghttp"
 )
 
 func TestComparatorEntryReceivedOutOfOrder(t *testing.T) {
-	outOfOrderEntries = &mockCounter{}
-	wsMissingEntries = &mockCounter{}
-	unexpectedEntries = &mockCounter{}
-	duplicateEntries = &mockCounter{}
+	outOfOrderEntries = &mockCounterVec{}
+	wsMissingEntries = &mockCounterVec{}
+	unexpectedEntries = &mockCounterVec{}
+	duplicateEntries = &mockCounterVec{}
 
 	actual := &bytes.Buffer{}
-	c := NewComparator(actual, 1*time.Hour, 1*time.Hour, 1*time.Hour, 15*time.Minute, 4*time.Hour, 4*time.Hour, 0, 1*time.Minute, 0, 1*time.Hour, 3*time.Hour, 30*time.Minute, 0, 1, make(chan time.Time), make(chan time.Time), nil, false)
+	c := NewComparator(actual, "", 1*time.Hour, 1*time.Hour, 1*time.Hour, 15*time.Minute, 4*time.Hour, 4*time.Hour, 0, 1*time.Minute, 0, 1*time.Hour, 3*time.Hour, 30*time.Minute, 0, nil, false, 0, 1, make(chan time.Time), make(chan time.Time), nil, false)
 
 	t1 := time.Now()
 	t2 := t1.Add(1 * time.Second)
@@ -42,10 +50,10 @@ func TestComparatorEntryReceivedOutOfOrder(t *testing.T) {
 	expected := fmt.Sprintf(ErrOutOfOrderEntry, t4, []time.Time{t2, t3})
 	assert.Equal(t, expected, actual.String())
 
-	assert.Equal(t, 1, outOfOrderEntries.(*mockCounter).count)
-	assert.Equal(t, 0, unexpectedEntries.(*mockCounter).count)
-	assert.Equal(t, 0, wsMissingEntries.(*mockCounter).count)
-	assert.Equal(t, 0, duplicateEntries.(*mockCounter).count)
+	assert.Equal(t, 1, outOfOrderEntries.(*mockCounterVec).count)
+	assert.Equal(t, 0, unexpectedEntries.(*mockCounterVec).count)
+	assert.Equal(t, 0, wsMissingEntries.(*mockCounterVec).count)
+	assert.Equal(t, 0, duplicateEntries.(*mockCounterVec).count)
 
 	// This avoids a panic on subsequent test execution,
 	// seems ugly but was easy, and multiple instantiations
@@ -54,13 +62,13 @@ func TestComparatorEntryReceivedOutOfOrder(t *testing.T) {
 }
 
 func TestComparatorEntryReceivedNotExpected(t *testing.T) {
-	outOfOrderEntries = &mockCounter{}
-	wsMissingEntries = &mockCounter{}
-	unexpectedEntries = &mockCounter{}
-	duplicateEntries = &mockCounter{}
+	outOfOrderEntries = &mockCounterVec{}
+	wsMissingEntries = &mockCounterVec{}
+	unexpectedEntries = &mockCounterVec{}
+	duplicateEntries = &mockCounterVec{}
 
 	actual := &bytes.Buffer{}
-	c := NewComparator(actual, 1*time.Hour, 1*time.Hour, 1*time.Hour, 15*time.Minute, 4*time.Hour, 4*time.Hour, 0, 1*time.Minute, 0, 1*time.Hour, 3*time.Hour, 30*time.Minute, 0, 1, make(chan time.Time), make(chan time.Time), nil, false)
+	c := NewComparator(actual, "", 1*time.Hour, 1*time.Hour, 1*time.Hour, 15*time.Minute, 4*time.Hour, 4*time.Hour, 0, 1*time.Minute, 0, 1*time.Hour, 3*time.Hour, 30*time.Minute, 0, nil, false, 0, 1, make(chan time.Time), make(chan time.Time), nil, false)
 
 	t1 := time.Now()
 	t2 := t1.Add(1 * time.Second)
@@ -83,10 +91,10 @@ func TestComparatorEntryReceivedNotExpected(t *testing.T) {
 	expected := fmt.Sprintf(ErrUnexpectedEntry, t1.UnixNano())
 	assert.Equal(t, expected, actual.String())
 
-	assert.Equal(t, 0, outOfOrderEntries.(*mockCounter).count)
-	assert.Equal(t, 1, unexpectedEntries.(*mockCounter).count)
-	assert.Equal(t, 0, wsMissingEntries.(*mockCounter).count)
-	assert.Equal(t, 0, duplicateEntries.(*mockCounter).count)
+	assert.Equal(t, 0, outOfOrderEntries.(*mockCounterVec).count)
+	assert.Equal(t, 1, unexpectedEntries.(*mockCounterVec).count)
+	assert.Equal(t, 0, wsMissingEntries.(*mockCounterVec).count)
+	assert.Equal(t, 0, duplicateEntries.(*mockCounterVec).count)
 
 	// This avoids a panic on subsequent test execution,
 	// seems ugly but was easy, and multiple instantiations
@@ -95,13 +103,13 @@ func TestComparatorEntryReceivedNotExpected(t *testing.T) {
 }
 
 func TestComparatorEntryReceivedDuplicate(t *testing.T) {
-	outOfOrderEntries = &mockCounter{}
-	wsMissingEntries = &mockCounter{}
-	unexpectedEntries = &mockCounter{}
-	duplicateEntries = &mockCounter{}
+	outOfOrderEntries = &mockCounterVec{}
+	wsMissingEntries = &mockCounterVec{}
+	unexpectedEntries = &mockCounterVec{}
+	duplicateEntries = &mockCounterVec{}
 
 	actual := &bytes.Buffer{}
-	c := NewComparator(actual, 1*time.Hour, 1*time.Hour, 1*time.Hour, 15*time.Minute, 4*time.Hour, 4*time.Hour, 0, 1*time.Minute, 0, 1*time.Hour, 3*time.Hour, 30*time.Minute, 0, 1, make(chan time.Time), make(chan time.Time), nil, false)
+	c := NewComparator(actual, "", 1*time.Hour, 1*time.Hour, 1*time.Hour, 15*time.Minute, 4*time.Hour, 4*time.Hour, 0, 1*time.Minute, 0, 1*time.Hour, 3*time.Hour, 30*time.Minute, 0, nil, false, 0, 1, make(chan time.Time), make(chan time.Time), nil, false)
 
 	t1 := time.Unix(0, 0)
 	t2 := t1.Add(1 * time.Second)
@@ -127,10 +135,10 @@ func TestComparatorEntryReceivedDuplicate(t *testing.T) {
 	expected := fmt.Sprintf(ErrDuplicateEntry, t2.UnixNano())
 	assert.Equal(t, expected, actual.String())
 
-	assert.Equal(t, 0, outOfOrderEntries.(*mockCounter).count)
-	assert.Equal(t, 0, unexpectedEntries.(*mockCounter).count)
-	assert.Equal(t, 0, wsMissingEntries.(*mockCounter).count)
-	assert.Equal(t, 1, duplicateEntries.(*mockCounter).count)
+	assert.Equal(t, 0, outOfOrderEntries.(*mockCounterVec).count)
+	assert.Equal(t, 0, unexpectedEntries.(*mockCounterVec).count)
+	assert.Equal(t, 0, wsMissingEntries.(*mockCounterVec).count)
+	assert.Equal(t, 1, duplicateEntries.(*mockCounterVec).count)
 
 	// This avoids a panic on subsequent test execution,
 	// seems ugly but was easy, and multiple instantiations
@@ -139,11 +147,11 @@ func TestComparatorEntryReceivedDuplicate(t *testing.T) {
 }
 
 func TestEntryNeverReceived(t *testing.T) {
-	outOfOrderEntries = &mockCounter{}
-	wsMissingEntries = &mockCounter{}
-	missingEntries = &mockCounter{}
-	unexpectedEntries = &mockCounter{}
-	duplicateEntries = &mockCounter{}
+	outOfOrderEntries = &mockCounterVec{}
+	wsMissingEntries = &mockCounterVec{}
+	missingEntries = &mockCounterVec{}
+	unexpectedEntries = &mockCounterVec{}
+	duplicateEntries = &mockCounterVec{}
 
 	actual := &bytes.Buffer{}
 
@@ -159,7 +167,7 @@ func TestEntryNeverReceived(t *testing.T) {
 	wait := 60 * time.Second
 	maxWait := 300 * time.Second
 	//We set the prune interval timer to a huge value here so that it never runs, instead we call pruneEntries manually below
-	c := NewComparator(actual, wait, maxWait, 50*time.Hour, 15*time.Minute, 4*time.Hour, 4*time.Hour, 0, 1*time.Minute, 0, 1*time.Hour, 3*time.Hour, 30*time.Minute, 0, 1, make(chan time.Time), make(chan time.Time), mr, false)
+	c := NewComparator(actual, "", wait, maxWait, 50*time.Hour, 15*time.Minute, 4*time.Hour, 4*time.Hour, 0, 1*time.Minute, 0, 1*time.Hour, 3*time.Hour, 30*time.Minute, 0, nil, false, 0, 1, make(chan time.Time), make(chan time.Time), mr, false)
 
 	c.entrySent(t1)
 	c.entrySent(t2)
@@ -205,11 +213,11 @@ func TestEntryNeverReceived(t *testing.T) {
 	assert.Equal(t, expected, actual.String())
 	assert.Equal(t, 0, c.Size())
 
-	assert.Equal(t, 2, outOfOrderEntries.(*mockCounter).count)
-	assert.Equal(t, 0, unexpectedEntries.(*mockCounter).count)
-	assert.Equal(t, 2, wsMissingEntries.(*mockCounter).count)
-	assert.Equal(t, 1, missingEntries.(*mockCounter).count)
-	assert.Equal(t, 0, duplicateEntries.(*mockCounter).count)
+	assert.Equal(t, 2, outOfOrderEntries.(*mockCounterVec).count)
+	assert.Equal(t, 0, unexpectedEntries.(*mockCounterVec).count)
+	assert.Equal(t, 2, wsMissingEntries.(*mockCounterVec).count)
+	assert.Equal(t, 1, missingEntries.(*mockCounterVec).count)
+	assert.Equal(t, 0, duplicateEntries.(*mockCounterVec).count)
 
 	// This avoids a panic on subsequent test execution,
 	// seems ugly but was easy, and multiple instantiations
@@ -232,7 +240,7 @@ func TestConcurrentConfirmMissing(t *testing.T) {
 	wait := 30 * time.Millisecond
 	maxWait := 30 * time.Millisecond
 
-	c := NewComparator(output, wait, maxWait, 50*time.Hour, 15*time.Minute, 4*time.Hour, 4*time.Hour, 0, 1*time.Minute, 0, 1*time.Hour, 3*time.Hour, 30*time.Minute, 0, 1, make(chan time.Time), make(chan time.Time), mr, false)
+	c := NewComparator(output, "", wait, maxWait, 50*time.Hour, 15*time.Minute, 4*time.Hour, 4*time.Hour, 0, 1*time.Minute, 0, 1*time.Hour, 3*time.Hour, 30*time.Minute, 0, nil, false, 0, 1, make(chan time.Time), make(chan time.Time), mr, false)
 
 	for _, t := range found {
 		tCopy := t
@@ -263,7 +271,7 @@ func TestPruneAckdEntires(t *testing.T) {
 	wait := 30 * time.Millisecond
 	maxWait := 30 * time.Millisecond
 	//We set the prune interval timer to a huge value here so that it never runs, instead we call pruneEntries manually below
-	c := NewComparator(actual, wait, maxWait, 50*time.Hour, 15*time.Minute, 4*time.Hour, 4*time.Hour, 0, 1*time.Minute, 0, 1*time.Hour, 3*time.Hour, 30*time.Minute, 0, 1, make(chan time.Time), make(chan time.Time), nil, false)
+	c := NewComparator(actual, "", wait, maxWait, 50*time.Hour, 15*time.Minute, 4*time.Hour, 4*time.Hour, 0, 1*time.Minute, 0, 1*time.Hour, 3*time.Hour, 30*time.Minute, 0, nil, false, 0, 1, make(chan time.Time), make(chan time.Time), nil, false)
 
 	t1 := time.Unix(0, 0)
 	t2 := t1.Add(1 * time.Millisecond)
@@ -298,8 +306,8 @@ func TestPruneAckdEntires(t *testing.T) {
 }
 
 func TestSpotCheck(t *testing.T) {
-	spotCheckMissing = &mockCounter{}
-	spotCheckEntries = &mockCounter{}
+	spotCheckMissing = &mockCounterVec{}
+	spotCheckEntries = &mockCounterVec{}
 
 	actual := &bytes.Buffer{}
 
@@ -320,7 +328,7 @@ func TestSpotCheck(t *testing.T) {
 	spotCheck := 10 * time.Millisecond
 	spotCheckMax := 20 * time.Millisecond
 	//We set the prune interval timer to a huge value here so that it never runs, instead we call spotCheckEntries manually below
-	c := NewComparator(actual, 1*time.Hour, 1*time.Hour, 50*time.Hour, spotCheck, spotCheckMax, 4*time.Hour, 3*time.Millisecond, 1*time.Minute, 0, 1*time.Hour, 3*time.Hour, 30*time.Minute, 0, 1, make(chan time.Time), make(chan time.Time), mr, false)
+	c := NewComparator(actual, "", 1*time.Hour, 1*time.Hour, 50*time.Hour, spotCheck, spotCheckMax, 4*time.Hour, 3*time.Millisecond, 1*time.Minute, 0, 1*time.Hour, 3*time.Hour, 30*time.Minute, 0, nil, false, 0, 1, make(chan time.Time), make(chan time.Time), mr, false)
 
 	// Send all the entries
 	for i := range entries {
@@ -336,7 +344,7 @@ func TestSpotCheck(t *testing.T) {
 	// Run with "current time" 1ms after start which is less than spotCheckWait so nothing should be checked
 	c.spotCheckEntries(time.Unix(0, 2*time.Millisecond.Nanoseconds()))
 	assert.Equal(t, 3, len(c.spotCheck))
-	assert.Equal(t, 0, spotCheckEntries.(*mockCounter).count)
+	assert.Equal(t, 0, spotCheckEntries.(*mockCounterVec).count)
 
 	// Run with "current time" at 25ms, the first entry should be pruned, the second entry should be found, and the last entry should come back as missing
 	c.spotCheckEntries(time.Unix(0, 25*time.Millisecond.Nanoseconds()))
@@ -354,8 +362,8 @@ func TestSpotCheck(t *testing.T) {
 
 	assert.Equal(t, expected, actual.String())
 
-	assert.Equal(t, 2, spotCheckEntries.(*mockCounter).count)
-	assert.Equal(t, 1, spotCheckMissing.(*mockCounter).count)
+	assert.Equal(t, 2, spotCheckEntries.(*mockCounterVec).count)
+	assert.Equal(t, 1, spotCheckMissing.(*mockCounterVec).count)
 
 	prometheus.Unregister(responseLatency)
 }
@@ -368,27 +376,27 @@ func TestCacheTest(t *testing.T) {
 	cacheTestRange := 30 * time.Second
 	cacheTestNow := 2 * time.Second
 
-	c := NewComparator(actual, 1*time.Hour, 1*time.Hour, 50*time.Hour, 0, 0, 4*time.Hour, 0, 10*time.Minute, 0, cacheTestInterval, cacheTestRange, cacheTestNow, 1*time.Hour, 1, make(chan time.Time), make(chan time.Time), mr, false)
+	c := NewComparator(actual, "", 1*time.Hour, 1*time.Hour, 50*time.Hour, 0, 0, 4*time.Hour, 0, 10*time.Minute, 0, cacheTestInterval, cacheTestRange, cacheTestNow, 0, nil, false, 1*time.Hour, 1, make(chan time.Time), make(chan time.Time), mr, false)
 	// Force the start time to a known value
 	c.startTime = time.Unix(10, 0)
 
-	queryResultsDiff = &mockCounter{}
+	queryResultsDiff = &mockCounterVec{}
 	mr.countOverTime = 2.3
 	mr.noCacheCountOvertime = mr.countOverTime // same value for both with and without cache
 	c.cacheTest(now)
-	assert.Equal(t, 0, queryResultsDiff.(*mockCounter).count)
+	assert.Equal(t, 0, queryResultsDiff.(*mockCounterVec).count)
 
-	queryResultsDiff = &mockCounter{} // reset counter
-	mr.countOverTime = 2.3            // value not important
-	mr.noCacheCountOvertime = 2.5     // different than `countOverTime` value.
+	queryResultsDiff = &mockCounterVec{} // reset counter
+	mr.countOverTime = 2.3               // value not important
+	mr.noCacheCountOvertime = 2.5        // different than `countOverTime` value.
 	c.cacheTest(now)
-	assert.Equal(t, 1, queryResultsDiff.(*mockCounter).count)
+	assert.Equal(t, 1, queryResultsDiff.(*mockCounterVec).count)
 
-	queryResultsDiff = &mockCounter{}    // reset counter
+	queryResultsDiff = &mockCounterVec{} // reset counter
 	mr.countOverTime = 2.3               // value not important
 	mr.noCacheCountOvertime = 2.30000005 // different than `countOverTime` value but within tolerance
 	c.cacheTest(now)
-	assert.Equal(t, 0, queryResultsDiff.(*mockCounter).count)
+	assert.Equal(t, 0, queryResultsDiff.(*mockCounterVec).count)
 
 	// This avoids a panic on subsequent test execution,
 	// seems ugly but was easy, and multiple instantiations
@@ -397,8 +405,8 @@ func TestCacheTest(t *testing.T) {
 }
 
 func TestMetricTest(t *testing.T) {
-	metricTestActual = &mockGauge{}
-	metricTestExpected = &mockGauge{}
+	metricTestActual = &mockGaugeVec{}
+	metricTestExpected = &mockGaugeVec{}
 
 	actual := &bytes.Buffer{}
 
@@ -407,7 +415,7 @@ func TestMetricTest(t *testing.T) {
 	mr := &mockReader{}
 	metricTestRange := 30 * time.Second
 	//We set the prune interval timer to a huge value here so that it never runs, instead we call spotCheckEntries manually below
-	c := NewComparator(actual, 1*time.Hour, 1*time.Hour, 50*time.Hour, 0, 0, 4*time.Hour, 0, 10*time.Minute, metricTestRange, 1*time.Hour, 3*time.Hour, 30*time.Minute, writeInterval, 1, make(chan time.Time), make(chan time.Time), mr, false)
+	c := NewComparator(actual, "", 1*time.Hour, 1*time.Hour, 50*time.Hour, 0, 0, 4*time.Hour, 0, 10*time.Minute, metricTestRange, 1*time.Hour, 3*time.Hour, 30*time.Minute, 0, nil, false, writeInterval, 1, make(chan time.Time), make(chan time.Time), mr, false)
 	// Force the start time to a known value
 	c.startTime = time.Unix(10, 0)
 
@@ -417,8 +425,8 @@ func TestMetricTest(t *testing.T) {
 	// We want to look back 30s but have only been running from time 10s to time 20s so the query range should be adjusted to 10s
 	assert.Equal(t, "10s", mr.queryRange)
 	// Should be no deviation we set countOverTime to the runtime/writeinterval which should be what metrictTest expected
-	assert.Equal(t, float64(20), metricTestExpected.(*mockGauge).val)
-	assert.Equal(t, float64(20), metricTestActual.(*mockGauge).val)
+	assert.Equal(t, float64(20), metricTestExpected.(*mockGaugeVec).val)
+	assert.Equal(t, float64(20), metricTestActual.(*mockGaugeVec).val)
 
 	// Run test at time 30s which is 20s after start
 	mr.countOverTime = float64((20 * time.Second).Milliseconds()) / float64(writeInterval.Milliseconds())
@@ -426,8 +434,8 @@ func TestMetricTest(t *testing.T) {
 	// We want to look back 30s but have only been running from time 10s to time 20s so the query range should be adjusted to 10s
 	assert.Equal(t, "20s", mr.queryRange)
 	// Gauge should be equal to the countOverTime value
-	assert.Equal(t, float64(40), metricTestExpected.(*mockGauge).val)
-	assert.Equal(t, float64(40), metricTestActual.(*mockGauge).val)
+	assert.Equal(t, float64(40), metricTestExpected.(*mockGaugeVec).val)
+	assert.Equal(t, float64(40), metricTestActual.(*mockGaugeVec).val)
 
 	// Run test 60s after start, we should now be capping the query range to 30s and expecting only 30s of counts
 	mr.countOverTime = float64((30 * time.Second).Milliseconds()) / float64(writeInterval.Milliseconds())
@@ -435,8 +443,96 @@ func TestMetricTest(t *testing.T) {
 	// We want to look back 30s but have only been running from time 10s to time 20s so the query range should be adjusted to 10s
 	assert.Equal(t, "30s", mr.queryRange)
 	// Gauge should be equal to the countOverTime value
-	assert.Equal(t, float64(60), metricTestExpected.(*mockGauge).val)
-	assert.Equal(t, float64(60), metricTestActual.(*mockGauge).val)
+	assert.Equal(t, float64(60), metricTestExpected.(*mockGaugeVec).val)
+	assert.Equal(t, float64(60), metricTestActual.(*mockGaugeVec).val)
+
+	prometheus.Unregister(responseLatency)
+}
+
+func TestFidelityCheck(t *testing.T) {
+	fidelityCheckEntries = &mockCounterVec{}
+	fidelityCheckMismatches.Reset()
+
+	actual := &bytes.Buffer{}
+
+	entry := func(ts time.Time, line string, structuredMetadata ...string) loghttp.Entry {
+		return loghttp.Entry{Timestamp: ts, Line: line, StructuredMetadata: labels.FromStrings(structuredMetadata...)}
+	}
+	line := func(ts time.Time) string {
+		return fmt.Sprintf(writer.LogEntry, strconv.FormatInt(ts.UnixNano(), 10), "pppp")
+	}
+	t1 := time.Unix(0, 1*time.Second.Nanoseconds())
+	t2 := time.Unix(0, 2*time.Second.Nanoseconds())
+	t3 := time.Unix(0, 3*time.Second.Nanoseconds())
+	t4 := time.Unix(0, 4*time.Second.Nanoseconds())
+	expectedLabels := loghttp.LabelSet{"name": "loki-canary", "stream": "stdout", "service_name": "unknown_service"}
+
+	mr := &mockReader{streams: loghttp.Streams{
+		{
+			Labels: expectedLabels,
+			Entries: []loghttp.Entry{
+				// Valid entry
+				entry(t1, line(t1), writer.StructuredMetadataTimestamp, strconv.FormatInt(t1.UnixNano(), 10), writer.StructuredMetadataCheck, "structured-metadata"),
+				// Line of another entry
+				entry(t2, line(t1), writer.StructuredMetadataTimestamp, strconv.FormatInt(t2.UnixNano(), 10), writer.StructuredMetadataCheck, "structured-metadata"),
+				// Missing structured metadata
+				entry(t3, line(t3), writer.StructuredMetadataTimestamp, strconv.FormatInt(t3.UnixNano(), 10)),
+			},
+		},
+		{
+			// Structured metadata stored as labels
+			Labels: loghttp.LabelSet{"name": "loki-canary", "stream": "stdout", writer.StructuredMetadataTimestamp: strconv.FormatInt(t4.UnixNano(), 10), writer.StructuredMetadataCheck: "structured-metadata"},
+			Entries: []loghttp.Entry{
+				entry(t4, line(t4)),
+			},
+		},
+	}}
+	c := NewComparator(actual, "tenant-a", 1*time.Hour, 1*time.Hour, 50*time.Hour, 15*time.Minute, 4*time.Hour, 4*time.Hour, 10*time.Second, 1*time.Minute, 0, 1*time.Hour, 3*time.Hour, 30*time.Minute, 1*time.Hour, map[string]string{"name": "loki-canary", "stream": "stdout"}, true, 0, 1, make(chan time.Time), make(chan time.Time), mr, false)
+	c.fidelityCheckEnd = time.Unix(0, 0)
+
+	c.fidelityCheck(time.Unix(0, 20*time.Second.Nanoseconds()))
+
+	assert.Equal(t, 4, fidelityCheckEntries.(*mockCounterVec).count)
+	assert.Equal(t, []string{"tenant-a"}, fidelityCheckEntries.(*mockCounterVec).labels)
+	assert.Equal(t, float64(1), testutil.ToFloat64(fidelityCheckMismatches.WithLabelValues("tenant-a", "line")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(fidelityCheckMismatches.WithLabelValues("tenant-a", "labels")))
+	assert.Equal(t, float64(2), testutil.ToFloat64(fidelityCheckMismatches.WithLabelValues("tenant-a", "structured_metadata")))
+	// The next check starts where this one ended, spotCheckWait ago.
+	assert.Equal(t, time.Unix(0, 10*time.Second.Nanoseconds()), c.fidelityCheckEnd)
+
+	// The range isn't checked again.
+	c.fidelityCheck(time.Unix(0, 20*time.Second.Nanoseconds()))
+	assert.Equal(t, 4, fidelityCheckEntries.(*mockCounterVec).count)
+
+	prometheus.Unregister(responseLatency)
+}
+
+func TestFidelityCheck_Pages(t *testing.T) {
+	fidelityCheckEntries = &mockCounterVec{}
+	fidelityCheckMismatches.Reset()
+
+	// More entries than returned by a single query, spread over two streams.
+	entries := make([][]loghttp.Entry, 2)
+	for i := 0; i < 2*reader.QueryLimit+10; i++ {
+		ts := time.Unix(0, int64(i+1)*time.Millisecond.Nanoseconds())
+		line := fmt.Sprintf(writer.LogEntry, strconv.FormatInt(ts.UnixNano(), 10), "pppp")
+		entries[i%2] = append(entries[i%2], loghttp.Entry{Timestamp: ts, Line: line})
+	}
+	lbls := loghttp.LabelSet{"name": "loki-canary", "stream": "stdout"}
+	mr := &mockReader{streams: loghttp.Streams{
+		{Labels: lbls, Entries: entries[0]},
+		{Labels: lbls, Entries: entries[1]},
+	}}
+	c := NewComparator(&bytes.Buffer{}, "tenant-a", 1*time.Hour, 1*time.Hour, 50*time.Hour, 15*time.Minute, 4*time.Hour, 4*time.Hour, 10*time.Second, 1*time.Minute, 0, 1*time.Hour, 3*time.Hour, 30*time.Minute, 1*time.Hour, map[string]string{"name": "loki-canary", "stream": "stdout"}, false, 0, 1, make(chan time.Time), make(chan time.Time), mr, false)
+	c.fidelityCheckEnd = time.Unix(0, 0)
+
+	c.fidelityCheck(time.Unix(0, 20*time.Second.Nanoseconds()))
+
+	// Every entry is checked exactly once.
+	assert.Equal(t, 3, mr.streamsQueries)
+	assert.Equal(t, 2*reader.QueryLimit+10, fidelityCheckEntries.(*mockCounterVec).count)
+	assert.Equal(t, float64(0), testutil.ToFloat64(fidelityCheckMismatches.WithLabelValues("tenant-a", "line")))
+	assert.Equal(t, time.Unix(0, 10*time.Second.Nanoseconds()), c.fidelityCheckEnd)
 
 	prometheus.Unregister(responseLatency)
 }
@@ -528,6 +624,16 @@ func (m *mockCounterVec) Inc() {
 	m.count++
 }
 
+type mockGaugeVec struct {
+	mockGauge
+	labels []string
+}
+
+func (m *mockGaugeVec) WithLabelValues(lvs ...string) prometheus.Gauge {
+	m.labels = lvs
+	return &m.mockGauge
+}
+
 type mockGauge struct {
 	cLck sync.Mutex
 	val  float64
@@ -576,9 +682,12 @@ func (m *mockGauge) SetToCurrentTime() {
 }
 
 type mockReader struct {
-	resp          []time.Time
-	countOverTime float64
-	queryRange    string
+	resp    []time.Time
+	streams loghttp.Streams
+	// streamsQueries counts the calls to QueryStreams.
+	streamsQueries int
+	countOverTime  float64
+	queryRange     string
 
 	// return this value if called without cache.
 	noCacheCountOvertime float64
@@ -588,6 +697,37 @@ func (r *mockReader) Query(_ time.Time, _ time.Time) ([]time.Time, error) {
 	return r.resp, nil
 }
 
+// QueryStreams returns the oldest reader.QueryLimit entries of the streams within the range.
+func (r *mockReader) QueryStreams(start time.Time, end time.Time) (loghttp.Streams, error) {
+	r.streamsQueries++
+
+	type streamEntry struct {
+		stream int
+		entry  loghttp.Entry
+	}
+	var entries []streamEntry
+	for i, stream := range r.streams {
+		for _, entry := range stream.Entries {
+			if !entry.Timestamp.Before(start) && entry.Timestamp.Before(end) {
+				entries = append(entries, streamEntry{stream: i, entry: entry})
+			}
+		}
+	}
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].entry.Timestamp.Before(entries[j].entry.Timestamp) })
+	if len(entries) > reader.QueryLimit {
+		entries = entries[:reader.QueryLimit]
+	}
+
+	streams := make(loghttp.Streams, len(r.streams))
+	for i, stream := range r.streams {
+		streams[i].Labels = stream.Labels
+	}
+	for _, e := range entries {
+		streams[e.stream].Entries = append(streams[e.stream].Entries, e.entry)
+	}
+	return streams, nil
+}
+
 func (r *mockReader) QueryCountOverTime(queryRange string, _ time.Time, cache bool) (float64, error) {
 	r.queryRange = queryRange
 	res := r.countOverTime
@@ -26,6 +26,7 @@ import (
 	"github.com/grafana/loki/v3/pkg/loghttp"
 	"github.com/grafana/loki/v3/pkg/logqlmodel"
 	"github.com/grafana/loki/v3/pkg/util/build"
+	"github.com/grafana/loki/v3/pkg/util/httpreq"
 	"github.com/grafana/loki/v3/pkg/util/unmarshal"
 )
 
@@ -43,9 +44,13 @@ var (
 	userAgent = fmt.Sprintf("loki-canary/%s", build.Version)
 )
 
+// QueryLimit is the maximum number of entries returned by a range query.
+const QueryLimit = 1000
+
 type LokiReader interface {
 	Query(start time.Time, end time.Time) ([]time.Time, error)
 	QueryCountOverTime(queryRange string, now time.Time, cache bool) (float64, error)
+	QueryStreams(start time.Time, end time.Time) (loghttp.Streams, error)
 }
 
 type Reader struct {
@@ -275,6 +280,35 @@ func (r *Reader) QueryCountOverTime(queryRange string, now time.Time, cache bool
 // Query will ask Loki for all canary timestamps in the requested timerange.
 // Query blocks if a previous query has failed until the appropriate backoff time has been reached.
 func (r *Reader) Query(start time.Time, end time.Time) ([]time.Time, error) {
+	streams, err := r.queryRange(start, end, fmt.Sprintf("{%v=\"%v\",%v=\"%v\"} %v", r.sName, r.sValue, r.lName, r.lVal, r.queryAppend), "backward", false)
+	if err != nil {
+		return nil, err
+	}
+
+	tss := []time.Time{}
+	for _, stream := range streams {
+		for _, entry := range stream.Entries {
+			ts, err := parseResponse(&entry)
+			if err != nil {
+				fmt.Fprint(r.w, err)
+				continue
+			}
+			tss = append(tss, *ts)
+		}
+	}
+
+	return tss, nil
+}
+
+// QueryStreams will ask Loki for the canary streams in the requested timerange, without the
+// query-append filters and with the structured metadata of the entries apart from the stream labels.
+// The oldest QueryLimit entries of the timerange are returned.
+// QueryStreams blocks if a previous query has failed until the appropriate backoff time has been reached.
+func (r *Reader) QueryStreams(start time.Time, end time.Time) (loghttp.Streams, error) {
+	return r.queryRange(start, end, fmt.Sprintf("{%v=\"%v\",%v=\"%v\"}", r.sName, r.sValue, r.lName, r.lVal), "forward", true)
+}
+
+func (r *Reader) queryRange(start time.Time, end time.Time, query string, direction string, categorizeLabels bool) (loghttp.Streams, error) {
 	r.backoffMtx.RLock()
 	next := r.nextQuery
 	r.backoffMtx.RUnlock()
@@ -295,8 +329,9 @@ func (r *Reader) Query(start time.Time, end time.Time) ([]time.Time, error) {
 		Host:   r.addr,
 		Path:   "/loki/api/v1/query_range",
 		RawQuery: fmt.Sprintf("start=%d&end=%d", start.UnixNano(), end.UnixNano()) +
-			"&query=" + url.QueryEscape(fmt.Sprintf("{%v=\"%v\",%v=\"%v\"} %v", r.sName, r.sValue, r.lName, r.lVal, r.queryAppend)) +
-			"&limit=1000",
+			"&query=" + url.QueryEscape(query) +
+			"&direction=" + direction +
+			fmt.Sprintf("&limit=%d", QueryLimit),
 	}
 	fmt.Fprintf(r.w, "Querying loki for logs with query: %v\n", u.String())
 
@@ -315,6 +350,9 @@ func (r *Reader) Query(start time.Time, end time.Time) ([]time.Time, error) {
 		req.Header.Set("X-Scope-OrgID", r.tenantID)
 	}
 	req.Header.Set("User-Agent", userAgent)
+	if categorizeLabels {
+		httpreq.AddEncodingFlags(req, httpreq.NewEncodingFlags(httpreq.FlagCategorizeLabels))
+	}
 
 	resp, err := r.httpClient.Do(req)
 	if err != nil {
@@ -344,25 +382,13 @@ func (r *Reader) Query(start time.Time, end time.Time) ([]time.Time, error) {
 		return nil, err
 	}
 
-	tss := []time.Time{}
 	value := decoded.Data.Result
 	switch value.Type() {
 	case logqlmodel.ValueTypeStreams:
-		for _, stream := range value.(loghttp.Streams) {
-			for _, entry := range stream.Entries {
-				ts, err := parseResponse(&entry)
-				if err != nil {
-					fmt.Fprint(r.w, err)
-					continue
-				}
-				tss = append(tss, *ts)
-			}
-		}
+		return value.(loghttp.Streams), nil
 	default:
 		return nil, fmt.Errorf("unexpected result type, expected a log stream result instead received %v", value.Type())
 	}
-
-	return tss, nil
 }
 
 // run uses the established websocket connection to tail logs from Loki
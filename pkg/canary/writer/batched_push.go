@@ -7,8 +7,6 @@ import (
 
 	"github.com/go-kit/log/level"
 	"github.com/grafana/dskit/backoff"
-
-	"github.com/grafana/loki/v3/pkg/logproto"
 )
 
 const (
@@ -37,13 +35,7 @@ type BatchedPush struct {
 // `buildPayload` receives the array of log lines and converts them
 // to a serialized byte array which may be pushed to the loki endpoint.
 func (p *BatchedPush) buildPayload(logs []entry) ([]byte, error) {
-	streams := make([]logproto.Stream, 0, len(logs))
-
-	for _, e := range logs {
-		streams = append(streams, p.pusher.buildStream(e))
-	}
-
-	return p.pusher.serializePayload(&logproto.PushRequest{Streams: streams})
+	return p.pusher.buildPayload(logs)
 }
 
 // implements `EntryWriter.WriteEntry` by delegating to the `Push` reference
@@ -9,6 +9,7 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"strconv"
 	"time"
 
 	"github.com/go-kit/log"
@@ -18,6 +19,11 @@ import (
 	"github.com/grafana/dskit/backoff"
 	"github.com/prometheus/common/config"
 	"github.com/prometheus/common/model"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/plog/plogotlp"
+
+	"github.com/grafana/loki/pkg/push"
 
 	"github.com/grafana/loki/v3/pkg/logproto"
 	"github.com/grafana/loki/v3/pkg/util/build"
@@ -27,9 +33,35 @@ const (
 	defaultContentType         = "application/x-protobuf"
 	defaultMaxReponseBufferLen = 1024
 
-	pushEndpoint = "/loki/api/v1/push"
+	pushEndpoint     = "/loki/api/v1/push"
+	otlpPushEndpoint = "/otlp/v1/logs"
+
+	// StructuredMetadataTimestamp and StructuredMetadataCheck are the names of the structured
+	// metadata attached to the entries when enabled, which are verified when reading them back.
+	StructuredMetadataTimestamp = "canary_ts"
+	StructuredMetadataCheck     = "canary_check"
+	structuredMetadataCheck     = "structured-metadata"
 )
 
+// Format is the format the logs are pushed with.
+type Format string
+
+const (
+	// FormatLoki pushes the logs to the Loki push API.
+	FormatLoki Format = "loki"
+	// FormatOTLP pushes the logs to the OTLP endpoint of Loki, with the labels of the stream as
+	// resource attributes and the structured metadata as log attributes.
+	FormatOTLP Format = "otlp"
+)
+
+// StructuredMetadata returns the structured metadata attached to the entry with the given timestamp.
+func StructuredMetadata(ts time.Time) push.LabelsAdapter {
+	return push.LabelsAdapter{
+		{Name: StructuredMetadataTimestamp, Value: strconv.FormatInt(ts.UnixNano(), 10)},
+		{Name: StructuredMetadataCheck, Value: structuredMetadataCheck},
+	}
+}
+
 var defaultUserAgent = fmt.Sprintf("canary-push/%s", build.GetVersion().Version)
 
 // Push is a io.Writer, that writes given log entries by pushing
@@ -60,6 +92,10 @@ type Push struct {
 
 	// cfg for sending logs in batches
 	logBatchSize int
+
+	// format of the pushed logs, and whether structured metadata is attached to the entries
+	format             Format
+	structuredMetadata bool
 }
 
 // `NewPush` creates an instance of `EntryWriter` which writes logs directly to the given `lokiAddr`
@@ -67,6 +103,9 @@ type Push struct {
 // Depending on the `logBatchSize` passed to this function, the implementing `EntryWriter` instance
 // is either a `Push` instance (which sends each log line immediately to Loki), or a `BatchedPush`
 // instance which sends log lines to Loki in batches.
+//
+// The logs are pushed in the given `format`, with the structured metadata returned by
+// `StructuredMetadata` attached to each entry if `structuredMetadata` is true.
 func NewPush(
 	lokiAddr, tenantID string,
 	timeout time.Duration,
@@ -79,6 +118,8 @@ func NewPush(
 	username, password string,
 	backoffCfg *backoff.Config,
 	logBatchSize int,
+	format Format,
+	structuredMetadata bool,
 	logger log.Logger,
 ) (EntryWriter, error) {
 	client, err := config.NewClientFromConfig(cfg, "canary-push", config.WithHTTP2Disabled())
@@ -90,6 +131,15 @@ func NewPush(
 		return nil, fmt.Errorf("logBatchSize must be >= 0")
 	}
 
+	path := pushEndpoint
+	switch format {
+	case FormatLoki:
+	case FormatOTLP:
+		path = otlpPushEndpoint
+	default:
+		return nil, fmt.Errorf("unsupported push format %q, must be one of %q or %q", format, FormatLoki, FormatOTLP)
+	}
+
 	client.Timeout = timeout
 	scheme := "http"
 
@@ -117,7 +167,7 @@ func NewPush(
 	u := url.URL{
 		Scheme: scheme,
 		Host:   lokiAddr,
-		Path:   pushEndpoint,
+		Path:   path,
 	}
 
 	p := &Push{
@@ -137,6 +187,9 @@ func NewPush(
 		username:    username,
 		password:    password,
 		backoff:     backoffCfg,
+
+		format:             format,
+		structuredMetadata: structuredMetadata,
 	}
 
 	// batch size of 0 or 1 doesn't require actual batching so just
@@ -173,14 +226,18 @@ func (p *Push) Stop() {
 	}
 }
 
-// buildPayload creates the snappy compressed protobuf to send to Loki
-func (p *Push) buildPayload(e entry) ([]byte, error) {
-	req := &logproto.PushRequest{
-		Streams: []logproto.Stream{
-			p.buildStream(e),
-		},
+// buildPayload creates the payload of the entries in the push format, which is
+// a snappy compressed protobuf with a stream per entry for the Loki push API.
+func (p *Push) buildPayload(entries []entry) ([]byte, error) {
+	if p.format == FormatOTLP {
+		return p.buildOTLPPayload(entries)
 	}
-	return p.serializePayload(req)
+
+	streams := make([]logproto.Stream, 0, len(entries))
+	for _, e := range entries {
+		streams = append(streams, p.buildStream(e))
+	}
+	return p.serializePayload(&logproto.PushRequest{Streams: streams})
 }
 
 func (p *Push) buildStream(e entry) logproto.Stream {
@@ -189,16 +246,47 @@ func (p *Push) buildStream(e entry) logproto.Stream {
 		model.LabelName(p.streamName): model.LabelValue(p.streamValue),
 	}
 
+	logEntry := logproto.Entry{
+		Timestamp: e.ts,
+		Line:      e.entry,
+	}
+	if p.structuredMetadata {
+		logEntry.StructuredMetadata = StructuredMetadata(e.ts)
+	}
+
 	return logproto.Stream{
-		Labels: labels.String(),
-		Entries: []logproto.Entry{
-			{
-				Timestamp: e.ts,
-				Line:      e.entry,
-			},
-		},
-		Hash: uint64(labels.Fingerprint()),
+		Labels:  labels.String(),
+		Entries: []logproto.Entry{logEntry},
+		Hash:    uint64(labels.Fingerprint()),
+	}
+}
+
+// buildOTLPPayload creates the OTLP protobuf export request of the entries, with the labels
+// of the stream as resource attributes. The tenant must store these attributes as index labels
+// for the entries to be found by the canary queries.
+func (p *Push) buildOTLPPayload(entries []entry) ([]byte, error) {
+	ld := plog.NewLogs()
+	rl := ld.ResourceLogs().AppendEmpty()
+	rl.Resource().Attributes().PutStr(p.labelName, p.labelValue)
+	rl.Resource().Attributes().PutStr(p.streamName, p.streamValue)
+
+	records := rl.ScopeLogs().AppendEmpty().LogRecords()
+	for _, e := range entries {
+		record := records.AppendEmpty()
+		record.SetTimestamp(pcommon.NewTimestampFromTime(e.ts))
+		record.Body().SetStr(e.entry)
+		if p.structuredMetadata {
+			for _, l := range StructuredMetadata(e.ts) {
+				record.Attributes().PutStr(l.Name, l.Value)
+			}
+		}
 	}
+
+	payload, err := plogotlp.NewExportRequestFromLogs(ld).MarshalProto()
+	if err != nil {
+		return []byte{}, fmt.Errorf("failed to marshal OTLP payload: %w", err)
+	}
+	return payload, nil
 }
 
 func (p *Push) serializePayload(req *logproto.PushRequest) ([]byte, error) {
@@ -225,7 +313,7 @@ func (p *Push) run() {
 			cancel()
 			return
 		case e := <-p.entries:
-			payload, err := p.buildPayload(e)
+			payload, err := p.buildPayload([]entry{e})
 			if err != nil {
 				level.Error(p.logger).Log("msg", "failed to build payload", "err", err)
 				continue
@@ -3,6 +3,7 @@ package writer
 import (
 	"encoding/base64"
 	"fmt"
+	"io"
 	"math"
 	"net/http"
 	"net/http/httptest"
@@ -17,6 +18,7 @@ import (
 	"github.com/prometheus/common/model"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/plog/plogotlp"
 
 	"github.com/grafana/loki/v3/pkg/logproto"
 	"github.com/grafana/loki/v3/pkg/util"
@@ -105,6 +107,86 @@ func Test_Push(t *testing.T) {
 	assertResponse(t, resp, true, labelSet("name", "loki-canary", "pod", "abc"), ts, payload, 1)
 }
 
+func Test_PushStructuredMetadata(t *testing.T) {
+	testCfg := newTestConfig(t)
+	defer func() {
+		testCfg.mock.Close()
+	}()
+
+	push, err := NewPush(testCfg.mock.Listener.Addr().String(), testTenant, 2*time.Second, config.DefaultHTTPClientConfig,
+		"name", "loki-canary", "stream", "stdout", false, nil, "", "", "", "", "", &testCfg.backoff, 1, FormatLoki, true, log.NewNopLogger())
+	require.NoError(t, err)
+	defer push.Stop()
+
+	ts, payload := testPayload()
+	push.WriteEntry(ts, payload)
+	resp := <-testCfg.responses
+	assertResponse(t, resp, false, labelSet("name", "loki-canary", "stream", "stdout"), ts, payload, 1)
+	require.Equal(t, StructuredMetadata(ts), resp.pushReq.Streams[0].Entries[0].StructuredMetadata)
+	require.Equal(t, fmt.Sprint(ts.UnixNano()), resp.pushReq.Streams[0].Entries[0].StructuredMetadata[0].Value)
+}
+
+func Test_OTLPPush(t *testing.T) {
+	requests := make(chan plogotlp.ExportRequest, 1)
+	mock := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.URL.Path != otlpPushEndpoint {
+			rw.WriteHeader(http.StatusNotFound)
+			return
+		}
+		body, err := io.ReadAll(req.Body)
+		if err != nil {
+			rw.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		exportReq := plogotlp.NewExportRequest()
+		if err := exportReq.UnmarshalProto(body); err != nil {
+			rw.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		requests <- exportReq
+		rw.WriteHeader(http.StatusOK)
+	}))
+	defer mock.Close()
+
+	backoffCfg := backoff.Config{MinBackoff: 300 * time.Millisecond, MaxBackoff: time.Second, MaxRetries: 1}
+	push, err := NewPush(mock.Listener.Addr().String(), testTenant, 2*time.Second, config.DefaultHTTPClientConfig,
+		"name", "loki-canary", "stream", "stdout", false, nil, "", "", "", "", "", &backoffCfg, 2, FormatOTLP, true, log.NewNopLogger())
+	require.NoError(t, err)
+	defer push.Stop()
+
+	ts1, payload1 := testPayload()
+	ts2, payload2 := testPayload()
+	push.WriteEntry(ts1, payload1)
+	push.WriteEntry(ts2, payload2)
+
+	logs := (<-requests).Logs()
+	require.Equal(t, 1, logs.ResourceLogs().Len())
+	rl := logs.ResourceLogs().At(0)
+	assert.Equal(t, map[string]any{"name": "loki-canary", "stream": "stdout"}, rl.Resource().Attributes().AsRaw())
+
+	records := rl.ScopeLogs().At(0).LogRecords()
+	require.Equal(t, 2, records.Len())
+	for i, expected := range []struct {
+		ts      time.Time
+		payload string
+	}{{ts1, payload1}, {ts2, payload2}} {
+		record := records.At(i)
+		assert.Equal(t, expected.ts.UnixNano(), record.Timestamp().AsTime().UnixNano())
+		assert.Equal(t, expected.payload, record.Body().Str())
+		assert.Equal(t, map[string]any{
+			StructuredMetadataTimestamp: fmt.Sprint(expected.ts.UnixNano()),
+			StructuredMetadataCheck:     structuredMetadataCheck,
+		}, record.Attributes().AsRaw())
+	}
+}
+
+func Test_CreatePusherInvalidFormat(t *testing.T) {
+	backoffCfg := backoff.Config{}
+	_, err := NewPush("localhost:3100", testTenant, time.Second, config.DefaultHTTPClientConfig,
+		"name", "loki-canary", "stream", "stdout", false, nil, "", "", "", "", "", &backoffCfg, 1, Format("json"), false, log.NewNopLogger())
+	require.Error(t, err)
+}
+
 // test batching log lines and ensure the testing resp contains exactly 10 unique entries
 func Test_BatchedPush(t *testing.T) {
 	testCfg := newTestConfig(t)
@@ -423,6 +505,8 @@ func newPushWithCredentialsAndStreamNameValue(testCfg testConfig, username, pass
 		password,
 		&testCfg.backoff,
 		logBatchSize,
+		FormatLoki,
+		false,
 		log.NewNopLogger(),
 	)
 }
@@ -201,6 +201,7 @@ func (e *Basic) Execute(ctx context.Context, params logql.Params) (logqlmodel.Re
 		cfg := executor.Config{
 			BatchSize:          int64(e.cfg.BatchSize),
 			MergePrefetchCount: e.cfg.MergePrefetchCount,
+			JoinMaxEntries:     e.cfg.JoinMaxEntries,
 			Bucket:             e.bucket,
 		}
 		pipeline := executor.Run(ctx, cfg, physicalPlan, logger)
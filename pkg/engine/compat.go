@@ -2,6 +2,7 @@ package engine
 
 import (
 	"sort"
+	"strconv"
 	"time"
 
 	"github.com/apache/arrow-go/v18/arrow"
@@ -29,6 +30,7 @@ var (
 	_ ResultBuilder = &streamsResultBuilder{}
 	_ ResultBuilder = &vectorResultBuilder{}
 	_ ResultBuilder = &matrixResultBuilder{}
+	_ ResultBuilder = &tableResultBuilder{}
 )
 
 func newStreamsResultBuilder() *streamsResultBuilder {
@@ -351,6 +353,67 @@ func (b *matrixResultBuilder) Len() int {
 	return total
 }
 
+// tableResultBuilder builds the tabular result of a stats stage. Each column
+// of the records becomes a column of the table, named after the short name of
+// the column. NULL values are represented as empty strings.
+type tableResultBuilder struct {
+	data logqlmodel.Table
+}
+
+func newTableResultBuilder() *tableResultBuilder {
+	return &tableResultBuilder{
+		data: logqlmodel.Table{
+			Columns: []string{},
+			Rows:    [][]string{},
+		},
+	}
+}
+
+func (b *tableResultBuilder) CollectRecord(rec arrow.Record) {
+	if len(b.data.Columns) == 0 {
+		for _, field := range rec.Schema().Fields() {
+			ident, err := semconv.ParseFQN(field.Name)
+			if err != nil {
+				b.data.Columns = append(b.data.Columns, field.Name)
+				continue
+			}
+			b.data.Columns = append(b.data.Columns, ident.ShortName())
+		}
+	}
+
+	for i := range int(rec.NumRows()) {
+		row := make([]string, rec.NumCols())
+		for colIdx := range int(rec.NumCols()) {
+			col := rec.Column(colIdx)
+			if col.IsNull(i) || !col.IsValid(i) {
+				continue
+			}
+			switch col := col.(type) {
+			case *array.String:
+				row[colIdx] = col.Value(i)
+			case *array.Float64:
+				row[colIdx] = strconv.FormatFloat(col.Value(i), 'f', -1, 64)
+			default:
+				row[colIdx] = col.ValueStr(i)
+			}
+		}
+		b.data.Rows = append(b.data.Rows, row)
+	}
+}
+
+func (b *tableResultBuilder) Build(s stats.Result, md *metadata.Context) logqlmodel.Result {
+	return logqlmodel.Result{
+		Data:       b.data,
+		Statistics: s,
+		Headers:    md.Headers(),
+		Warnings:   md.Warnings(),
+	}
+}
+
+func (b *tableResultBuilder) Len() int {
+	return len(b.data.Rows)
+}
+
 func collectSamplesFromRow(builder *labels.Builder, rec arrow.Record, i int) (promql.Sample, bool) {
 	var sample promql.Sample
 	builder.Reset(labels.EmptyLabels())
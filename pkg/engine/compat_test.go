@@ -524,6 +524,50 @@ func TestVectorResultBuilder(t *testing.T) {
 	})
 }
 
+func TestTableResultBuilder(t *testing.T) {
+	colStatus := semconv.NewIdentifier("status", types.ColumnTypeAmbiguous, types.Loki.String)
+	colCount := semconv.NewIdentifier("count()", types.ColumnTypeGenerated, types.Loki.Float)
+	colAvg := semconv.NewIdentifier("avg(latency)", types.ColumnTypeGenerated, types.Loki.Float)
+
+	schema := arrow.NewSchema(
+		[]arrow.Field{
+			semconv.FieldFromIdent(colStatus, true),
+			semconv.FieldFromIdent(colCount, true),
+			semconv.FieldFromIdent(colAvg, true),
+		},
+		nil,
+	)
+	rows := arrowtest.Rows{
+		{colStatus.FQN(): "200", colCount.FQN(): float64(10), colAvg.FQN(): 0.25},
+		{colStatus.FQN(): "500", colCount.FQN(): float64(2), colAvg.FQN(): nil},
+		{colStatus.FQN(): nil, colCount.FQN(): float64(1), colAvg.FQN(): 1.5},
+	}
+
+	record := rows.Record(memory.DefaultAllocator, schema)
+
+	pipeline := executor.NewBufferedPipeline(record)
+	defer pipeline.Close()
+
+	builder := newTableResultBuilder()
+	err := collectResult(context.Background(), pipeline, builder)
+
+	require.NoError(t, err)
+	require.Equal(t, 3, builder.Len())
+
+	md, _ := metadata.NewContext(t.Context())
+	result := builder.Build(stats.Result{}, md)
+
+	expected := logqlmodel.Table{
+		Columns: []string{"status", "count()", "avg(latency)"},
+		Rows: [][]string{
+			{"200", "10", "0.25"},
+			{"500", "2", ""},
+			{"", "1", "1.5"},
+		},
+	}
+	require.Equal(t, expected, result.Data)
+}
+
 func TestMatrixResultBuilder(t *testing.T) {
 	t.Run("empty builder returns non-nil result", func(t *testing.T) {
 		builder := newMatrixResultBuilder()
@@ -56,6 +56,9 @@ type ExecutorConfig struct {
 	// MergePrefetchCount controls the number of inputs that are prefetched simultaneously by any Merge node.
 	MergePrefetchCount int `yaml:"merge_prefetch_count" category:"experimental"`
 
+	// JoinMaxEntries is the maximum number of log lines of the joined query that a join stage buffers.
+	JoinMaxEntries int `yaml:"join_max_entries" category:"experimental"`
+
 	// RangeConfig determines how to optimize range reads in the V2 engine.
 	RangeConfig rangeio.Config `yaml:"range_reads" category:"experimental" doc:"description=Configures how to read byte ranges from object storage when using the V2 engine."`
 }
@@ -63,6 +66,7 @@ type ExecutorConfig struct {
 func (cfg *ExecutorConfig) RegisterFlagsWithPrefix(prefix string, f *flag.FlagSet) {
 	f.IntVar(&cfg.BatchSize, prefix+"batch-size", 100, "Experimental: Batch size of the next generation query engine.")
 	f.IntVar(&cfg.MergePrefetchCount, prefix+"merge-prefetch-count", 0, "Experimental: The number of inputs that are prefetched simultaneously by any Merge node. A value of 0 means that only the currently processed input is prefetched, 1 means that only the next input is prefetched, and so on. A negative value means that all inputs are be prefetched in parallel.")
+	f.IntVar(&cfg.JoinMaxEntries, prefix+"join-max-entries", 100000, "Experimental: The maximum number of log lines of the joined query that a join stage buffers in memory. Queries that exceed the limit fail.")
 	cfg.RangeConfig.RegisterFlags(prefix+"range-reads.", f)
 
 	f.DurationVar(&cfg.DataobjStorageLag, prefix+"dataobj-storage-lag", 1*time.Hour, "Amount of time until data objects are available.")
@@ -358,7 +362,11 @@ func (e *Engine) collectResult(ctx context.Context, logger log.Logger, params lo
 	var builder ResultBuilder
 	switch params.GetExpression().(type) {
 	case syntax.LogSelectorExpr:
-		builder = newStreamsResultBuilder()
+		if syntax.ExtractStats(params.GetExpression()) != nil {
+			builder = newTableResultBuilder()
+		} else {
+			builder = newStreamsResultBuilder()
+		}
 	case syntax.SampleExpr:
 		if params.Step() > 0 {
 			builder = newMatrixResultBuilder()
@@ -19,6 +19,7 @@ import (
 
 type groupState struct {
 	value       float64  // aggregated value
+	count       int      // number of aggregated values, used to compute averages
 	labelValues []string // grouping label values
 }
 
@@ -29,6 +30,7 @@ const (
 	aggregationOperationMax
 	aggregationOperationMin
 	aggregationOperationCount
+	aggregationOperationAvg
 )
 
 // aggregator is used to aggregate sample values by a set of grouping keys for each point in time.
@@ -98,8 +100,10 @@ func (a *aggregator) Add(ts time.Time, value float64, labelValues []string) {
 			}
 		case aggregationOperationCount:
 			state.value = state.value + 1
-
+		case aggregationOperationAvg:
+			state.value += value
 		}
+		state.count++
 	} else {
 		v := value
 		if a.operation == aggregationOperationCount {
@@ -111,6 +115,7 @@ func (a *aggregator) Add(ts time.Time, value float64, labelValues []string) {
 			// This applies to queries like `sum(...)`, `sum by () (...)`, `count_over_time by () (...)`.
 			point[key] = &groupState{
 				value: v,
+				count: 1,
 			}
 			return
 		}
@@ -128,6 +133,7 @@ func (a *aggregator) Add(ts time.Time, value float64, labelValues []string) {
 		point[key] = &groupState{
 			labelValues: labelValuesCopy,
 			value:       v,
+			count:       1,
 		}
 	}
 }
@@ -157,7 +163,7 @@ func (a *aggregator) BuildRecord() (arrow.Record, error) {
 
 		for _, entry := range a.points[ts] {
 			rb.Field(0).(*array.TimestampBuilder).Append(tsValue)
-			rb.Field(1).(*array.Float64Builder).Append(entry.value)
+			rb.Field(1).(*array.Float64Builder).Append(a.result(entry))
 
 			for col, val := range entry.labelValues {
 				builder := rb.Field(col + 2) // offset by 2 as the first 2 fields are timestamp and value
@@ -174,6 +180,14 @@ func (a *aggregator) BuildRecord() (arrow.Record, error) {
 	return rb.NewRecord(), nil
 }
 
+// result returns the aggregated value of a group.
+func (a *aggregator) result(state *groupState) float64 {
+	if a.operation == aggregationOperationAvg {
+		return state.value / float64(state.count)
+	}
+	return state.value
+}
+
 func (a *aggregator) Reset() {
 	a.digest.Reset()
 	// keep the timestamps but clear the aggregated values
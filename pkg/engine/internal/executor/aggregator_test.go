@@ -208,6 +208,30 @@ func TestAggregator(t *testing.T) {
 		require.ElementsMatch(t, expect, rows)
 	})
 
+	t.Run("basic AVG aggregation with record building", func(t *testing.T) {
+		agg := newAggregator(groupBy, 10, aggregationOperationAvg)
+
+		ts1 := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+
+		agg.Add(ts1, 10, []string{"prod", "app1"})
+		agg.Add(ts1, 20, []string{"prod", "app1"})
+		agg.Add(ts1, 60, []string{"prod", "app1"})
+		agg.Add(ts1, 30, []string{"dev", "app1"})
+
+		record, err := agg.BuildRecord()
+		require.NoError(t, err)
+
+		expect := arrowtest.Rows{
+			{colTs: ts1, colVal: float64(30), colEnv: "prod", colSvc: "app1"},
+			{colTs: ts1, colVal: float64(30), colEnv: "dev", colSvc: "app1"},
+		}
+
+		rows, err := arrowtest.RecordRows(record)
+		require.NoError(t, err, "should be able to convert record back to rows")
+		require.Equal(t, len(expect), len(rows), "number of rows should match")
+		require.ElementsMatch(t, expect, rows)
+	})
+
 	t.Run("SUM aggregation with empty groupBy", func(t *testing.T) {
 		// Empty groupBy represents sum by () or sum(...) - all values aggregated into single group
 		groupBy := []physical.ColumnExpression{}
@@ -26,6 +26,10 @@ type Config struct {
 
 	MergePrefetchCount int
 
+	// JoinMaxEntries is the maximum number of rows of the joined query that a
+	// join buffers. Defaults to 100000 if zero.
+	JoinMaxEntries int
+
 	// GetExternalInputs is an optional function called for each node in the
 	// plan. If GetExternalInputs returns a non-nil slice of Pipelines, they
 	// will be used as inputs to the pipeline of node.
@@ -37,6 +41,7 @@ func Run(ctx context.Context, cfg Config, plan *physical.Plan, logger log.Logger
 		plan:               plan,
 		batchSize:          cfg.BatchSize,
 		mergePrefetchCount: cfg.MergePrefetchCount,
+		joinMaxEntries:     cfg.JoinMaxEntries,
 		bucket:             cfg.Bucket,
 		logger:             logger,
 		evaluator:          newExpressionEvaluator(),
@@ -64,6 +69,7 @@ type Context struct {
 	getExternalInputs func(ctx context.Context, node physical.Node) []Pipeline
 
 	mergePrefetchCount int
+	joinMaxEntries     int
 }
 
 func (c *Context) execute(ctx context.Context, node physical.Node) Pipeline {
@@ -101,6 +107,12 @@ func (c *Context) execute(ctx context.Context, node physical.Node) Pipeline {
 		return tracePipeline("physical.RangeAggregation", c.executeRangeAggregation(ctx, n, inputs))
 	case *physical.VectorAggregation:
 		return tracePipeline("physical.VectorAggregation", c.executeVectorAggregation(ctx, n, inputs))
+	case *physical.Stats:
+		return tracePipeline("physical.Stats", c.executeStats(ctx, n, inputs))
+	case *physical.Join:
+		return tracePipeline("physical.Join", c.executeJoin(ctx, n, inputs))
+	case *physical.Merge:
+		return tracePipeline("physical.Merge", c.executeMerge(ctx, n, inputs))
 	case *physical.ColumnCompat:
 		return tracePipeline("physical.ColumnCompat", c.executeColumnCompat(ctx, n, inputs))
 	case *physical.Parallelize:
@@ -365,6 +377,55 @@ func (c *Context) executeVectorAggregation(ctx context.Context, plan *physical.V
 	return pipeline
 }
 
+func (c *Context) executeStats(ctx context.Context, plan *physical.Stats, inputs []Pipeline) Pipeline {
+	ctx, span := tracer.Start(ctx, "Context.executeStats", trace.WithAttributes(
+		attribute.Int("num_group_by", len(plan.GroupBy)),
+		attribute.Int("num_aggregations", len(plan.Aggregations)),
+		attribute.Int("num_inputs", len(inputs)),
+	))
+	defer span.End()
+
+	if len(inputs) == 0 {
+		return emptyPipeline()
+	}
+
+	pipeline, err := newStatsPipeline(inputs, plan.GroupBy, plan.Aggregations, c.evaluator)
+	if err != nil {
+		return errorPipeline(ctx, err)
+	}
+
+	return pipeline
+}
+
+func (c *Context) executeJoin(ctx context.Context, plan *physical.Join, inputs []Pipeline) Pipeline {
+	ctx, span := tracer.Start(ctx, "Context.executeJoin", trace.WithAttributes(
+		attribute.Int("num_on", len(plan.On)),
+		attribute.String("window", plan.Window.String()),
+		attribute.Int("num_inputs", len(inputs)),
+	))
+	defer span.End()
+
+	pipeline, err := newJoinPipeline(inputs, plan.On, plan.Window, c.joinMaxEntries, c.evaluator)
+	if err != nil {
+		return errorPipeline(ctx, err)
+	}
+
+	return pipeline
+}
+
+func (c *Context) executeMerge(ctx context.Context, _ *physical.Merge, inputs []Pipeline) Pipeline {
+	if len(inputs) == 0 {
+		return emptyPipeline()
+	}
+
+	pipeline, err := newMergePipeline(inputs, c.mergePrefetchCount)
+	if err != nil {
+		return errorPipeline(ctx, err)
+	}
+
+	return pipeline
+}
+
 func (c *Context) executeColumnCompat(ctx context.Context, compat *physical.ColumnCompat, inputs []Pipeline) Pipeline {
 	if len(inputs) == 0 {
 		return emptyPipeline()
@@ -113,6 +113,10 @@ func init() {
 	// Parse functions
 	variadicFunctions.register(types.VariadicOpParseLogfmt, parseFn(types.VariadicOpParseLogfmt))
 	variadicFunctions.register(types.VariadicOpParseJSON, parseFn(types.VariadicOpParseJSON))
+
+	// Sample functions
+	variadicFunctions.register(types.VariadicOpSampleLine, sampleFn(types.VariadicOpSampleLine))
+	variadicFunctions.register(types.VariadicOpSampleLabels, sampleFn(types.VariadicOpSampleLabels))
 }
 
 type UnaryFunctionRegistry interface {
@@ -0,0 +1,247 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+
+	"github.com/grafana/loki/v3/pkg/engine/internal/arrowagg"
+	"github.com/grafana/loki/v3/pkg/engine/internal/planner/physical"
+	"github.com/grafana/loki/v3/pkg/engine/internal/types"
+	"github.com/grafana/loki/v3/pkg/logqlmodel"
+)
+
+// defaultJoinMaxEntries is the maximum number of rows of the right input that
+// a join buffers if no limit is configured.
+const defaultJoinMaxEntries = 100_000
+
+// joinKeySeparator separates the values of the columns of a join key. It is
+// not valid UTF-8, so it can't be part of a value.
+const joinKeySeparator = "\xff"
+
+// joinPipeline is a pipeline that correlates the rows of two inputs.
+//
+// It reads all rows of the right input into memory, indexed by the values of
+// the columns to join on. Rows that miss a value for any of the columns are
+// skipped, since they can't be matched. Afterwards, each record of the left
+// input is joined with the buffered rows, emitting one row per matched pair
+// whose timestamps are at most the join window apart.
+//
+// The emitted rows contain all columns of the left row, followed by the
+// columns of the right row that the left row doesn't have.
+type joinPipeline struct {
+	left, right Pipeline
+
+	evaluator  expressionEvaluator
+	on         []physical.ColumnExpression
+	window     time.Duration
+	maxEntries int
+
+	rightExhausted bool // indicates if the right input is buffered
+	entries        int
+	index          map[string][]joinEntry
+}
+
+// joinEntry is a reference to a buffered row of the right input.
+type joinEntry struct {
+	record    arrow.Record
+	row       int
+	timestamp time.Time
+}
+
+func newJoinPipeline(inputs []Pipeline, on []physical.ColumnExpression, window time.Duration, maxEntries int, evaluator expressionEvaluator) (*joinPipeline, error) {
+	if len(inputs) != 2 {
+		return nil, fmt.Errorf("join expects exactly two inputs, got %d", len(inputs))
+	}
+	if len(on) == 0 {
+		return nil, fmt.Errorf("join on timestamp: %w", errNotImplemented)
+	}
+	if maxEntries <= 0 {
+		maxEntries = defaultJoinMaxEntries
+	}
+
+	return &joinPipeline{
+		left:       inputs[0],
+		right:      inputs[1],
+		evaluator:  evaluator,
+		on:         on,
+		window:     window,
+		maxEntries: maxEntries,
+		index:      make(map[string][]joinEntry),
+	}, nil
+}
+
+// Read reads the next batch of matched pairs.
+func (j *joinPipeline) Read(ctx context.Context) (arrow.Record, error) {
+	if !j.rightExhausted {
+		if err := j.readRight(ctx); err != nil {
+			return nil, err
+		}
+		j.rightExhausted = true
+	}
+
+	for {
+		record, err := j.left.Read(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		joined, err := j.join(record)
+		if err != nil {
+			return nil, err
+		} else if joined != nil {
+			return joined, nil
+		}
+	}
+}
+
+// readRight buffers all rows of the right input. It returns an error if the
+// number of buffered rows exceeds the maximum number of entries.
+func (j *joinPipeline) readRight(ctx context.Context) error {
+	for {
+		record, err := j.right.Read(ctx)
+		if errors.Is(err, EOF) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		keys, timestamps, err := j.keys(record)
+		if err != nil {
+			return err
+		}
+
+		for row, key := range keys {
+			if key == "" {
+				continue
+			}
+
+			j.entries++
+			if j.entries > j.maxEntries {
+				return fmt.Errorf("%w: join exceeded the maximum number of %d entries of the joined query, narrow its selector or reduce the join window", logqlmodel.ErrLimit, j.maxEntries)
+			}
+			j.index[key] = append(j.index[key], joinEntry{
+				record:    record,
+				row:       row,
+				timestamp: timestamps.Value(row).ToTime(arrow.Nanosecond),
+			})
+		}
+	}
+}
+
+// join joins the rows of a record of the left input with the buffered rows.
+// It returns nil if none of the rows has a match.
+func (j *joinPipeline) join(record arrow.Record) (arrow.Record, error) {
+	keys, timestamps, err := j.keys(record)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		leftRows  = arrowagg.NewRecords(memory.DefaultAllocator)
+		rightRows = arrowagg.NewRecords(memory.DefaultAllocator)
+		matches   int64
+	)
+	for row, key := range keys {
+		if key == "" {
+			continue
+		}
+
+		ts := timestamps.Value(row).ToTime(arrow.Nanosecond)
+		for _, entry := range j.index[key] {
+			if ts.Sub(entry.timestamp).Abs() > j.window {
+				continue
+			}
+			leftRows.AppendSlice(record, int64(row), int64(row)+1)
+			rightRows.AppendSlice(entry.record, int64(entry.row), int64(entry.row)+1)
+			matches++
+		}
+	}
+	if matches == 0 {
+		return nil, nil
+	}
+
+	left, err := leftRows.Aggregate()
+	if err != nil {
+		return nil, err
+	}
+	right, err := rightRows.Aggregate()
+	if err != nil {
+		return nil, err
+	}
+
+	// The columns of the left row take precedence over the columns of the
+	// right row with the same name.
+	fields := slices.Clone(left.Schema().Fields())
+	columns := slices.Clone(left.Columns())
+	for i, field := range right.Schema().Fields() {
+		if left.Schema().HasField(field.Name) {
+			continue
+		}
+		fields = append(fields, field)
+		columns = append(columns, right.Column(i))
+	}
+
+	return array.NewRecord(arrow.NewSchema(fields, nil), columns, matches), nil
+}
+
+// keys returns the join key of each row of the record, and the timestamps of
+// the rows. The key is empty if a row misses a value for any of the columns.
+func (j *joinPipeline) keys(record arrow.Record) ([]string, *array.Timestamp, error) {
+	tsVec, err := j.evaluator.eval(&physical.ColumnExpr{
+		Ref: types.ColumnRef{
+			Column: types.ColumnNameBuiltinTimestamp,
+			Type:   types.ColumnTypeBuiltin,
+		},
+	}, record)
+	if err != nil {
+		return nil, nil, err
+	}
+	timestamps, ok := tsVec.(*array.Timestamp)
+	if !ok {
+		return nil, nil, fmt.Errorf("unsupported datatype for timestamp %s", tsVec.DataType())
+	}
+
+	arrays := make([]*array.String, 0, len(j.on))
+	for _, columnExpr := range j.on {
+		vec, err := j.evaluator.eval(columnExpr, record)
+		if err != nil {
+			return nil, nil, err
+		}
+		if vec.DataType().ID() != types.Arrow.String.ID() {
+			return nil, nil, fmt.Errorf("unsupported datatype for join %s", vec.DataType())
+		}
+		arrays = append(arrays, vec.(*array.String))
+	}
+
+	keys := make([]string, record.NumRows())
+	values := make([]string, len(arrays))
+	for row := range keys {
+		complete := true
+		for col, arr := range arrays {
+			if arr.IsNull(row) || arr.Value(row) == "" {
+				complete = false
+				break
+			}
+			values[col] = arr.Value(row)
+		}
+		if complete {
+			keys[row] = strings.Join(values, joinKeySeparator)
+		}
+	}
+	return keys, timestamps, nil
+}
+
+// Close closes the resources of the pipeline.
+func (j *joinPipeline) Close() {
+	j.left.Close()
+	j.right.Close()
+	clear(j.index)
+}
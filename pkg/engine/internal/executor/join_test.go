@@ -0,0 +1,100 @@
+package executor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/v3/pkg/engine/internal/planner/physical"
+	"github.com/grafana/loki/v3/pkg/engine/internal/semconv"
+	"github.com/grafana/loki/v3/pkg/engine/internal/types"
+	"github.com/grafana/loki/v3/pkg/logqlmodel"
+	"github.com/grafana/loki/v3/pkg/util/arrowtest"
+)
+
+func TestJoinPipeline(t *testing.T) {
+	var (
+		colMsg     = "utf8.builtin.message"
+		colApp     = "utf8.label.app"
+		colRequest = "utf8.parsed.request_id"
+		colStatus  = "utf8.parsed.status"
+	)
+
+	leftSchema := arrow.NewSchema([]arrow.Field{
+		semconv.FieldFromFQN(colTs, false),
+		semconv.FieldFromFQN(colMsg, false),
+		semconv.FieldFromFQN(colApp, false),
+		semconv.FieldFromFQN(colRequest, true),
+	}, nil)
+	rightSchema := arrow.NewSchema([]arrow.Field{
+		semconv.FieldFromFQN(colTs, false),
+		semconv.FieldFromFQN(colMsg, false),
+		semconv.FieldFromFQN(colApp, false),
+		semconv.FieldFromFQN(colRequest, true),
+		semconv.FieldFromFQN(colStatus, true),
+	}, nil)
+
+	left := arrowtest.Rows{
+		{colTs: time.Unix(10, 0).UTC(), colMsg: "a1", colApp: "a", colRequest: "1"},
+		{colTs: time.Unix(20, 0).UTC(), colMsg: "a2", colApp: "a", colRequest: "2"},
+		{colTs: time.Unix(30, 0).UTC(), colMsg: "a3", colApp: "a", colRequest: nil},
+		{colTs: time.Unix(40, 0).UTC(), colMsg: "a4", colApp: "a", colRequest: "4"},
+	}
+	right := arrowtest.Rows{
+		{colTs: time.Unix(15, 0).UTC(), colMsg: "b1", colApp: "b", colRequest: "1", colStatus: "200"},
+		{colTs: time.Unix(5, 0).UTC(), colMsg: "b1", colApp: "b", colRequest: "1", colStatus: "500"},
+		{colTs: time.Unix(60, 0).UTC(), colMsg: "b2", colApp: "b", colRequest: "2", colStatus: "200"}, // outside of window
+		{colTs: time.Unix(30, 0).UTC(), colMsg: "b3", colApp: "b", colRequest: nil, colStatus: "200"}, // without key
+	}
+
+	on := []physical.ColumnExpression{
+		&physical.ColumnExpr{Ref: types.ColumnRef{Column: "request_id", Type: types.ColumnTypeAmbiguous}},
+	}
+
+	t.Run("matched pairs", func(t *testing.T) {
+		pipeline, err := newJoinPipeline(
+			[]Pipeline{NewArrowtestPipeline(leftSchema, left), NewArrowtestPipeline(rightSchema, right)},
+			on, 10*time.Second, 0, newExpressionEvaluator(),
+		)
+		require.NoError(t, err)
+		defer pipeline.Close()
+
+		record, err := pipeline.Read(t.Context())
+		require.NoError(t, err)
+
+		// Columns of the left rows take precedence, and the parsed status of
+		// the right rows is added.
+		expect := arrowtest.Rows{
+			{colTs: time.Unix(10, 0).UTC(), colMsg: "a1", colApp: "a", colRequest: "1", colStatus: "200"},
+			{colTs: time.Unix(10, 0).UTC(), colMsg: "a1", colApp: "a", colRequest: "1", colStatus: "500"},
+		}
+		rows, err := arrowtest.RecordRows(record)
+		require.NoError(t, err)
+		require.Equal(t, expect, rows)
+
+		_, err = pipeline.Read(t.Context())
+		require.ErrorIs(t, err, EOF)
+	})
+
+	t.Run("max entries", func(t *testing.T) {
+		pipeline, err := newJoinPipeline(
+			[]Pipeline{NewArrowtestPipeline(leftSchema, left), NewArrowtestPipeline(rightSchema, right)},
+			on, 10*time.Second, 2, newExpressionEvaluator(),
+		)
+		require.NoError(t, err)
+		defer pipeline.Close()
+
+		_, err = pipeline.Read(t.Context())
+		require.ErrorIs(t, err, logqlmodel.ErrLimit)
+	})
+
+	t.Run("join on timestamp", func(t *testing.T) {
+		_, err := newJoinPipeline(
+			[]Pipeline{NewArrowtestPipeline(leftSchema, left), NewArrowtestPipeline(rightSchema, right)},
+			nil, 0, 0, newExpressionEvaluator(),
+		)
+		require.ErrorIs(t, err, errNotImplemented)
+	})
+}
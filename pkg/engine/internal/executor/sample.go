@@ -0,0 +1,83 @@
+package executor
+
+import (
+	"fmt"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+
+	"github.com/grafana/loki/v3/pkg/engine/internal/types"
+	logqllog "github.com/grafana/loki/v3/pkg/logql/log"
+)
+
+// sampleFn returns a function that returns whether a row is kept by a sample
+// stage. It uses the same hashes as the sample stage of the old engine, so both
+// engines keep the same rows.
+func sampleFn(op types.VariadicOp) VariadicFunction {
+	return VariadicFunctionFunc(func(args ...arrow.Array) (arrow.Array, error) {
+		// Valid signatures:
+		//sample_line(ratio, timestampVec, lineVec)
+		//sample_labels(ratio, labelVec...)
+		if len(args) < 2 {
+			return nil, fmt.Errorf("sample function expected at least 2 arguments, got %d", len(args))
+		}
+		ratioArr, ok := args[0].(*array.Float64)
+		if !ok || ratioArr.Len() == 0 {
+			return nil, fmt.Errorf("sample function expected a float ratio, got %T", args[0])
+		}
+		// The ratio is the same for all rows, so we only need the first one.
+		threshold := logqllog.SampleThreshold(ratioArr.Value(0))
+
+		var hash func(i int) uint64
+		switch op {
+		case types.VariadicOpSampleLine:
+			if len(args) != 3 {
+				return nil, fmt.Errorf("sample function expected 3 arguments, got %d", len(args))
+			}
+			tsCol, ok := args[1].(*array.Timestamp)
+			if !ok {
+				return nil, fmt.Errorf("sample function expected a timestamp column, got %T", args[1])
+			}
+			lineCol, ok := args[2].(*array.String)
+			if !ok {
+				return nil, fmt.Errorf("sample function expected a string column, got %T", args[2])
+			}
+			hash = func(i int) uint64 {
+				return logqllog.SampleLineHash(int64(tsCol.Value(i)), []byte(lineCol.Value(i)))
+			}
+		case types.VariadicOpSampleLabels:
+			cols := make([]*array.String, len(args)-1)
+			for i, arg := range args[1:] {
+				col, ok := arg.(*array.String)
+				if !ok {
+					return nil, fmt.Errorf("sample function expected a string column, got %T", arg)
+				}
+				cols[i] = col
+			}
+			values := make([]string, len(cols))
+			hash = func(i int) uint64 {
+				for j, col := range cols {
+					// Missing labels are treated as empty values.
+					values[j] = ""
+					if col.IsValid(i) {
+						values[j] = col.Value(i)
+					}
+				}
+				return logqllog.SampleLabelsHash(values)
+			}
+		default:
+			return nil, fmt.Errorf("unsupported sample kind: %v", op)
+		}
+
+		builder := array.NewBooleanBuilder(memory.DefaultAllocator)
+		defer builder.Release()
+
+		rows := args[1].Len()
+		builder.Reserve(rows)
+		for i := range rows {
+			builder.Append(logqllog.SampleKeep(hash(i), threshold))
+		}
+		return builder.NewArray(), nil
+	})
+}
@@ -0,0 +1,77 @@
+package executor
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/v3/pkg/engine/internal/types"
+	"github.com/grafana/loki/v3/pkg/logql/log"
+)
+
+func TestSampleFunctions(t *testing.T) {
+	const rows = 1000
+
+	ts := make([]arrow.Timestamp, rows)
+	lines := make([]string, rows)
+	traceIDs := make([]string, rows)
+	ratios := make([]float64, rows)
+	for i := range rows {
+		ts[i] = arrow.Timestamp(i)
+		lines[i] = fmt.Sprintf("line %d", i)
+		traceIDs[i] = fmt.Sprintf("trace-%d", i%100)
+		ratios[i] = 0.3
+	}
+
+	t.Run("line", func(t *testing.T) {
+		fn, err := variadicFunctions.GetForSignature(types.VariadicOpSampleLine)
+		require.NoError(t, err)
+
+		res, err := fn.Evaluate(createFloat64Array(ratios, nil), createTimestampArray(ts, nil), createStringArray(lines, nil))
+		require.NoError(t, err)
+		kept := res.(*array.Boolean)
+		require.Equal(t, rows, kept.Len())
+
+		// The old engine keeps the same lines.
+		stage, err := log.NewSampleStage(0.3, nil)
+		require.NoError(t, err)
+		for i := range rows {
+			_, ok := stage.Process(int64(ts[i]), []byte(lines[i]), nil)
+			require.Equal(t, ok, kept.Value(i), "row %d", i)
+		}
+	})
+
+	t.Run("labels", func(t *testing.T) {
+		fn, err := variadicFunctions.GetForSignature(types.VariadicOpSampleLabels)
+		require.NoError(t, err)
+
+		// The rows with a null value are sampled like the rows with an empty value.
+		nulls := make([]bool, rows)
+		nulls[0] = true
+		traceIDs[1] = ""
+
+		res, err := fn.Evaluate(createFloat64Array(ratios, nil), createStringArray(traceIDs, nulls))
+		require.NoError(t, err)
+		kept := res.(*array.Boolean)
+		require.Equal(t, rows, kept.Len())
+		require.Equal(t, kept.Value(0), kept.Value(1))
+
+		// Either all or none of the rows of a trace are kept.
+		for i := 100; i < rows; i++ {
+			require.Equal(t, kept.Value(i%100), kept.Value(i), "row %d", i)
+		}
+	})
+
+	t.Run("invalid arguments", func(t *testing.T) {
+		fn, err := variadicFunctions.GetForSignature(types.VariadicOpSampleLine)
+		require.NoError(t, err)
+
+		_, err = fn.Evaluate(createFloat64Array(ratios, nil), createStringArray(lines, nil))
+		require.Error(t, err)
+		_, err = fn.Evaluate(createStringArray(lines, nil), createTimestampArray(ts, nil), createStringArray(lines, nil))
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,245 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+
+	"github.com/grafana/loki/v3/pkg/engine/internal/planner/physical"
+	"github.com/grafana/loki/v3/pkg/engine/internal/semconv"
+	"github.com/grafana/loki/v3/pkg/engine/internal/types"
+)
+
+var (
+	statsOperations = map[types.VectorAggregationType]aggregationOperation{
+		types.VectorAggregationTypeSum:   aggregationOperationSum,
+		types.VectorAggregationTypeCount: aggregationOperationCount,
+		types.VectorAggregationTypeMax:   aggregationOperationMax,
+		types.VectorAggregationTypeMin:   aggregationOperationMin,
+		types.VectorAggregationTypeAvg:   aggregationOperationAvg,
+	}
+)
+
+// statsPipeline is a pipeline that performs the aggregations of a stats stage.
+//
+// It reads all rows of its inputs, groups them by the specified columns and
+// applies each aggregation on each group. The result is a single record with
+// one row per group, and one column per grouping label and aggregation.
+//
+// Each aggregation uses its own [aggregator], since rows without a value for
+// the aggregated column are skipped. Values of aggregated columns that can't
+// be converted to a float are skipped as well.
+type statsPipeline struct {
+	inputs          []Pipeline
+	inputsExhausted bool // indicates if all inputs are exhausted
+
+	evaluator    expressionEvaluator
+	groupBy      []physical.ColumnExpression
+	aggregations []physical.StatsAggregation
+	aggregators  []*aggregator
+}
+
+func newStatsPipeline(inputs []Pipeline, groupBy []physical.ColumnExpression, aggregations []physical.StatsAggregation, evaluator expressionEvaluator) (*statsPipeline, error) {
+	if len(inputs) == 0 {
+		return nil, fmt.Errorf("stats expects at least one input")
+	}
+
+	aggregators := make([]*aggregator, len(aggregations))
+	for i, agg := range aggregations {
+		op, ok := statsOperations[agg.Operation]
+		if !ok {
+			return nil, fmt.Errorf("unsupported stats aggregation: %v", agg.Operation)
+		}
+		aggregators[i] = newAggregator(groupBy, 1, op)
+	}
+
+	return &statsPipeline{
+		inputs:       inputs,
+		evaluator:    evaluator,
+		groupBy:      groupBy,
+		aggregations: aggregations,
+		aggregators:  aggregators,
+	}, nil
+}
+
+// Read reads the next value into its state.
+func (s *statsPipeline) Read(ctx context.Context) (arrow.Record, error) {
+	if s.inputsExhausted {
+		return nil, EOF
+	}
+	return s.read(ctx)
+}
+
+func (s *statsPipeline) read(ctx context.Context) (arrow.Record, error) {
+	labelValues := make([]string, len(s.groupBy))
+
+	inputsExhausted := false
+	for !inputsExhausted {
+		inputsExhausted = true
+
+		for _, input := range s.inputs {
+			record, err := input.Read(ctx)
+			if err != nil {
+				if errors.Is(err, EOF) {
+					continue
+				}
+				return nil, err
+			}
+
+			inputsExhausted = false
+
+			// extract all the columns that are used for grouping
+			groupArrays := make([]*array.String, 0, len(s.groupBy))
+			for _, columnExpr := range s.groupBy {
+				vec, err := s.evaluator.eval(columnExpr, record)
+				if err != nil {
+					return nil, err
+				}
+
+				if vec.DataType().ID() != types.Arrow.String.ID() {
+					return nil, fmt.Errorf("unsupported datatype for grouping %s", vec.DataType())
+				}
+				groupArrays = append(groupArrays, vec.(*array.String))
+			}
+
+			// extract all the columns that are aggregated, count() has no column
+			valueArrays := make([]arrow.Array, len(s.aggregations))
+			for i, agg := range s.aggregations {
+				if agg.Column == nil {
+					continue
+				}
+				vec, err := s.evaluator.eval(agg.Column, record)
+				if err != nil {
+					return nil, err
+				}
+				valueArrays[i] = vec
+			}
+
+			for row := range int(record.NumRows()) {
+				// reset for each row
+				clear(labelValues)
+				for col, arr := range groupArrays {
+					labelValues[col] = arr.Value(row)
+				}
+
+				for i, agg := range s.aggregators {
+					value, ok := statsValue(valueArrays[i], row)
+					if !ok {
+						continue
+					}
+					agg.Add(time.Time{}, value, labelValues)
+				}
+			}
+		}
+	}
+
+	s.inputsExhausted = true
+
+	return s.buildRecord()
+}
+
+// statsValue returns the value of the row of an aggregated column as float.
+// It returns false if the value is missing or not a number.
+// A nil array is used by count(), which counts every row.
+func statsValue(arr arrow.Array, row int) (float64, bool) {
+	if arr == nil {
+		return 0, true
+	}
+	if arr.IsNull(row) {
+		return 0, false
+	}
+
+	switch arr := arr.(type) {
+	case *array.Float64:
+		return arr.Value(row), true
+	case *array.Int64:
+		return float64(arr.Value(row)), true
+	case *array.String:
+		v := arr.Value(row)
+		if v == "" {
+			return 0, false
+		}
+		f, err := convertFloat(v)
+		if err != nil {
+			return 0, false
+		}
+		return f, true
+	default:
+		return 0, false
+	}
+}
+
+// buildRecord projects the results of all aggregators into a single record.
+func (s *statsPipeline) buildRecord() (arrow.Record, error) {
+	fields := make([]arrow.Field, 0, len(s.groupBy)+len(s.aggregations))
+	for _, column := range s.groupBy {
+		colExpr, ok := column.(*physical.ColumnExpr)
+		if !ok {
+			return nil, fmt.Errorf("invalid column expression type %T", column)
+		}
+		ident := semconv.NewIdentifier(colExpr.Ref.Column, colExpr.Ref.Type, types.Loki.String)
+		fields = append(fields, semconv.FieldFromIdent(ident, true))
+	}
+	for _, agg := range s.aggregations {
+		ident := semconv.NewIdentifier(agg.Name, types.ColumnTypeGenerated, types.Loki.Float)
+		fields = append(fields, semconv.FieldFromIdent(ident, true))
+	}
+
+	// collect the union of groups of all aggregators, as rows that are
+	// skipped by an aggregation may leave groups without a value.
+	groups := make(map[uint64][]string)
+	for _, agg := range s.aggregators {
+		for key, state := range agg.points[time.Time{}] {
+			groups[key] = state.labelValues
+		}
+	}
+
+	// emit groups in sorted order of their label values
+	keys := make([]uint64, 0, len(groups))
+	for key := range groups {
+		keys = append(keys, key)
+	}
+	slices.SortFunc(keys, func(a, b uint64) int {
+		return slices.Compare(groups[a], groups[b])
+	})
+
+	schema := arrow.NewSchema(fields, nil)
+	rb := array.NewRecordBuilder(memory.NewGoAllocator(), schema)
+	defer rb.Release()
+
+	for _, key := range keys {
+		for col := range s.groupBy {
+			builder := rb.Field(col).(*array.StringBuilder)
+			labelValues := groups[key]
+			if len(labelValues) == 0 || labelValues[col] == "" {
+				builder.AppendNull()
+			} else {
+				builder.Append(labelValues[col])
+			}
+		}
+
+		for i, agg := range s.aggregators {
+			builder := rb.Field(len(s.groupBy) + i).(*array.Float64Builder)
+			if state, ok := agg.points[time.Time{}][key]; ok {
+				builder.Append(agg.result(state))
+			} else {
+				builder.AppendNull()
+			}
+		}
+	}
+
+	return rb.NewRecord(), nil
+}
+
+// Close closes the resources of the pipeline.
+func (s *statsPipeline) Close() {
+	for _, input := range s.inputs {
+		input.Close()
+	}
+}
@@ -0,0 +1,84 @@
+package executor
+
+import (
+	"testing"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/v3/pkg/engine/internal/planner/physical"
+	"github.com/grafana/loki/v3/pkg/engine/internal/semconv"
+	"github.com/grafana/loki/v3/pkg/engine/internal/types"
+	"github.com/grafana/loki/v3/pkg/util/arrowtest"
+)
+
+func TestStatsPipeline(t *testing.T) {
+	colStatus := semconv.NewIdentifier("status", types.ColumnTypeLabel, types.Loki.String).FQN()
+	colLatency := semconv.NewIdentifier("latency", types.ColumnTypeParsed, types.Loki.String).FQN()
+
+	schema := arrow.NewSchema([]arrow.Field{
+		semconv.FieldFromFQN(colStatus, true),
+		semconv.FieldFromFQN(colLatency, true),
+	}, nil)
+
+	input1 := arrowtest.Rows{
+		{colStatus: "200", colLatency: "0.1"},
+		{colStatus: "200", colLatency: "0.3"},
+		{colStatus: "500", colLatency: "not-a-number"},
+	}
+	input2 := arrowtest.Rows{
+		{colStatus: "200", colLatency: "0.2"},
+		{colStatus: "500", colLatency: nil},
+		{colStatus: "404", colLatency: "1.5"},
+	}
+
+	groupBy := []physical.ColumnExpression{
+		&physical.ColumnExpr{Ref: types.ColumnRef{Column: "status", Type: types.ColumnTypeAmbiguous}},
+	}
+	latency := &physical.ColumnExpr{Ref: types.ColumnRef{Column: "latency", Type: types.ColumnTypeAmbiguous}}
+	aggregations := []physical.StatsAggregation{
+		{Name: "count()", Operation: types.VectorAggregationTypeCount},
+		{Name: "avg(latency)", Operation: types.VectorAggregationTypeAvg, Column: latency},
+		{Name: "max(latency)", Operation: types.VectorAggregationTypeMax, Column: latency},
+	}
+
+	pipeline, err := newStatsPipeline(
+		[]Pipeline{NewArrowtestPipeline(schema, input1), NewArrowtestPipeline(schema, input2)},
+		groupBy, aggregations, newExpressionEvaluator(),
+	)
+	require.NoError(t, err)
+	defer pipeline.Close()
+
+	record, err := pipeline.Read(t.Context())
+	require.NoError(t, err)
+
+	colOutStatus := semconv.NewIdentifier("status", types.ColumnTypeAmbiguous, types.Loki.String).FQN()
+	colCount := semconv.NewIdentifier("count()", types.ColumnTypeGenerated, types.Loki.Float).FQN()
+	colAvg := semconv.NewIdentifier("avg(latency)", types.ColumnTypeGenerated, types.Loki.Float).FQN()
+	colMax := semconv.NewIdentifier("max(latency)", types.ColumnTypeGenerated, types.Loki.Float).FQN()
+
+	// Rows are sorted by group. Values that are missing or not a number are
+	// not aggregated, which leaves the 500 group without avg and max.
+	expect := arrowtest.Rows{
+		{colOutStatus: "200", colCount: float64(3), colAvg: 0.2, colMax: 0.3},
+		{colOutStatus: "404", colCount: float64(1), colAvg: 1.5, colMax: 1.5},
+		{colOutStatus: "500", colCount: float64(2), colAvg: nil, colMax: nil},
+	}
+
+	rows, err := arrowtest.RecordRows(record)
+	require.NoError(t, err)
+	require.Len(t, rows, len(expect))
+	for i := range expect {
+		require.Equal(t, expect[i][colOutStatus], rows[i][colOutStatus])
+		require.Equal(t, expect[i][colCount], rows[i][colCount])
+		if expect[i][colAvg] == nil {
+			require.Nil(t, rows[i][colAvg])
+		} else {
+			require.InDelta(t, expect[i][colAvg], rows[i][colAvg], 1e-9)
+		}
+		require.Equal(t, expect[i][colMax], rows[i][colMax])
+	}
+
+	_, err = pipeline.Read(t.Context())
+	require.ErrorIs(t, err, EOF)
+}
@@ -138,6 +138,33 @@ func (b *Builder) VectorAggregation(
 	}
 }
 
+// Stats applies a [Stats] operation to the Builder.
+func (b *Builder) Stats(
+	groupBy []ColumnRef,
+	aggregations []StatsAggregation,
+) *Builder {
+	return &Builder{
+		val: &Stats{
+			Table:        b.val,
+			GroupBy:      groupBy,
+			Aggregations: aggregations,
+		},
+	}
+}
+
+// Join applies a [Join] operation to the Builder, which correlates its rows
+// with the rows of right.
+func (b *Builder) Join(right Value, on []ColumnRef, window time.Duration) *Builder {
+	return &Builder{
+		val: &Join{
+			Left:   b.val,
+			Right:  right,
+			On:     on,
+			Window: window,
+		},
+	}
+}
+
 // Compat applies a [LogQLCompat] operation to the Builder, which is a marker to ensure v1 engine compatible results.
 func (b *Builder) Compat(logqlCompatibility bool) *Builder {
 	if logqlCompatibility {
@@ -47,10 +47,16 @@ func (b *ssaBuilder) process(value Value) (Value, error) {
 		return b.processRangeAggregate(value)
 	case *VectorAggregation:
 		return b.processVectorAggregation(value)
+	case *Stats:
+		return b.processStats(value)
+	case *Join:
+		return b.processJoin(value)
 	case *UnaryOp:
 		return b.processUnaryOp(value)
 	case *BinOp:
 		return b.processBinOp(value)
+	case *FunctionOp:
+		return b.processFunctionOp(value)
 	case *ColumnRef:
 		return b.processColumnRef(value)
 	case *Literal:
@@ -163,6 +169,21 @@ func (b *ssaBuilder) processUnaryOp(value *UnaryOp) (Value, error) {
 	return value, nil
 }
 
+func (b *ssaBuilder) processFunctionOp(value *FunctionOp) (Value, error) {
+	for _, v := range value.Values {
+		if _, err := b.process(v); err != nil {
+			return nil, err
+		}
+	}
+
+	// Only append the first time we see this.
+	if value.id == "" {
+		value.id = fmt.Sprintf("%%%d", b.getID())
+		b.instructions = append(b.instructions, value)
+	}
+	return value, nil
+}
+
 func (b *ssaBuilder) processRangeAggregate(plan *RangeAggregation) (Value, error) {
 	if _, err := b.process(plan.Table); err != nil {
 		return nil, err
@@ -189,6 +210,34 @@ func (b *ssaBuilder) processVectorAggregation(plan *VectorAggregation) (Value, e
 	return plan, nil
 }
 
+func (b *ssaBuilder) processStats(plan *Stats) (Value, error) {
+	if _, err := b.process(plan.Table); err != nil {
+		return nil, err
+	}
+
+	// Only append the first time we see this.
+	if plan.id == "" {
+		plan.id = fmt.Sprintf("%%%d", b.getID())
+		b.instructions = append(b.instructions, plan)
+	}
+	return plan, nil
+}
+
+func (b *ssaBuilder) processJoin(plan *Join) (Value, error) {
+	if _, err := b.process(plan.Left); err != nil {
+		return nil, err
+	} else if _, err := b.process(plan.Right); err != nil {
+		return nil, err
+	}
+
+	// Only append the first time we see this.
+	if plan.id == "" {
+		plan.id = fmt.Sprintf("%%%d", b.getID())
+		b.instructions = append(b.instructions, plan)
+	}
+	return plan, nil
+}
+
 func (b *ssaBuilder) processBinOp(expr *BinOp) (Value, error) {
 	if _, err := b.process(expr.Left); err != nil {
 		return nil, err
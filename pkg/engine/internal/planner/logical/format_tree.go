@@ -33,11 +33,17 @@ func (t *treeFormatter) convert(value Value) *tree.Node {
 		return t.convertRangeAggregation(value)
 	case *VectorAggregation:
 		return t.convertVectorAggregation(value)
+	case *Stats:
+		return t.convertStats(value)
+	case *Join:
+		return t.convertJoin(value)
 
 	case *UnaryOp:
 		return t.convertUnaryOp(value)
 	case *BinOp:
 		return t.convertBinOp(value)
+	case *FunctionOp:
+		return t.convertFunctionOp(value)
 	case *ColumnRef:
 		return t.convertColumnRef(value)
 	case *Literal:
@@ -110,6 +116,16 @@ func (t *treeFormatter) convertUnaryOp(expr *UnaryOp) *tree.Node {
 	return node
 }
 
+func (t *treeFormatter) convertFunctionOp(expr *FunctionOp) *tree.Node {
+	node := tree.NewNode("FunctionOp", expr.Name(),
+		tree.NewProperty("op", false, expr.Op.String()),
+	)
+	for _, v := range expr.Values {
+		node.Children = append(node.Children, t.convert(v))
+	}
+	return node
+}
+
 func (t *treeFormatter) convertBinOp(expr *BinOp) *tree.Node {
 	node := tree.NewNode("BinOp", expr.Name(),
 		tree.NewProperty("op", false, expr.Op.String()),
@@ -186,3 +202,52 @@ func (t *treeFormatter) convertVectorAggregation(v *VectorAggregation) *tree.Nod
 
 	return node
 }
+
+func (t *treeFormatter) convertStats(s *Stats) *tree.Node {
+	aggregations := make([]any, len(s.Aggregations))
+	for i, agg := range s.Aggregations {
+		aggregations[i] = agg.String()
+	}
+
+	properties := []tree.Property{
+		tree.NewProperty("table", false, s.Table.Name()),
+		tree.NewProperty("aggregations", true, aggregations...),
+	}
+
+	if len(s.GroupBy) > 0 {
+		groupBy := make([]any, len(s.GroupBy))
+		for i := range s.GroupBy {
+			groupBy[i] = s.GroupBy[i].Name()
+		}
+
+		properties = append(properties, tree.NewProperty("group_by", true, groupBy...))
+	}
+
+	node := tree.NewNode("Stats", s.Name(), properties...)
+	for _, columnRef := range s.GroupBy {
+		node.Comments = append(node.Comments, t.convert(&columnRef))
+	}
+	node.Children = append(node.Children, t.convert(s.Table))
+
+	return node
+}
+
+func (t *treeFormatter) convertJoin(j *Join) *tree.Node {
+	on := make([]any, len(j.On))
+	for i := range j.On {
+		on[i] = j.On[i].Name()
+	}
+
+	node := tree.NewNode("Join", j.Name(),
+		tree.NewProperty("left", false, j.Left.Name()),
+		tree.NewProperty("right", false, j.Right.Name()),
+		tree.NewProperty("on", true, on...),
+		tree.NewProperty("window", false, j.Window),
+	)
+	for _, columnRef := range j.On {
+		node.Comments = append(node.Comments, t.convert(&columnRef))
+	}
+	node.Children = append(node.Children, t.convert(j.Left), t.convert(j.Right))
+
+	return node
+}
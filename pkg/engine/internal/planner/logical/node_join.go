@@ -0,0 +1,49 @@
+package logical
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Join represents a logical plan node that correlates the rows of two
+// relations. Each row of Left is joined with each row of Right that has the
+// same values for the On columns and whose timestamp is at most Window apart.
+// It is the logical representation of the LogQL `| join` stage.
+type Join struct {
+	id string
+
+	Left  Value // The relation of the query the join stage belongs to.
+	Right Value // The relation of the query to join with.
+
+	// The columns that both rows of a matched pair must have the same values for.
+	On []ColumnRef
+
+	// The maximum distance between the timestamps of a matched pair.
+	Window time.Duration
+}
+
+var (
+	_ Value       = (*Join)(nil)
+	_ Instruction = (*Join)(nil)
+)
+
+// Name returns an identifier for the Join operation.
+func (j *Join) Name() string {
+	if j.id != "" {
+		return j.id
+	}
+	return fmt.Sprintf("%p", j)
+}
+
+// String returns the disassembled SSA form of the Join instruction.
+func (j *Join) String() string {
+	on := make([]string, len(j.On))
+	for i, columnRef := range j.On {
+		on[i] = columnRef.String()
+	}
+	return fmt.Sprintf("JOIN %s %s [on=(%s), window=%s]", j.Left.Name(), j.Right.Name(), strings.Join(on, ", "), j.Window)
+}
+
+func (j *Join) isInstruction() {}
+func (j *Join) isValue()       {}
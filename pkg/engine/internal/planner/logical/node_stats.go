@@ -0,0 +1,85 @@
+package logical
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/grafana/loki/v3/pkg/engine/internal/types"
+)
+
+// Stats represents a logical plan node that aggregates all rows of a table
+// into a tabular result, with one row per group and one column per
+// aggregation. It is the logical representation of the LogQL `| stats` stage.
+type Stats struct {
+	id string
+
+	Table Value // The table relation to aggregate.
+
+	// The columns to group by. If empty, all rows are aggregated into a single row.
+	GroupBy []ColumnRef
+
+	// The aggregations to compute for each group.
+	Aggregations []StatsAggregation
+}
+
+// StatsAggregation is a single aggregation computed by a [Stats] node.
+type StatsAggregation struct {
+	// The type of aggregation operation to perform (e.g., count, sum, avg)
+	Operation types.VectorAggregationType
+
+	// The column to aggregate. It is nil for count, which counts rows.
+	Column *ColumnRef
+}
+
+// Name returns the name of the aggregation as written in LogQL, e.g.
+// avg(latency), which is used as name of the resulting column.
+func (a StatsAggregation) Name() string {
+	if a.Column == nil {
+		return fmt.Sprintf("%s()", a.Operation)
+	}
+	return fmt.Sprintf("%s(%s)", a.Operation, a.Column.Ref.Column)
+}
+
+// String returns the string representation of the aggregation, including
+// the type of the aggregated column.
+func (a StatsAggregation) String() string {
+	if a.Column == nil {
+		return fmt.Sprintf("%s()", a.Operation)
+	}
+	return fmt.Sprintf("%s(%s)", a.Operation, a.Column.String())
+}
+
+var (
+	_ Value       = (*Stats)(nil)
+	_ Instruction = (*Stats)(nil)
+)
+
+// Name returns an identifier for the Stats operation.
+func (s *Stats) Name() string {
+	if s.id != "" {
+		return s.id
+	}
+	return fmt.Sprintf("%p", s)
+}
+
+// String returns the disassembled SSA form of the Stats instruction.
+func (s *Stats) String() string {
+	aggregations := make([]string, len(s.Aggregations))
+	for i, agg := range s.Aggregations {
+		aggregations[i] = agg.String()
+	}
+	props := fmt.Sprintf("aggregations=(%s)", strings.Join(aggregations, ", "))
+
+	if len(s.GroupBy) > 0 {
+		groupBy := make([]string, len(s.GroupBy))
+		for i, columnRef := range s.GroupBy {
+			groupBy[i] = columnRef.String()
+		}
+		props += fmt.Sprintf(", group_by=(%s)", strings.Join(groupBy, ", "))
+	}
+
+	return fmt.Sprintf("STATS %s [%s]", s.Table.Name(), props)
+}
+
+func (s *Stats) isInstruction() {}
+func (s *Stats) isValue()       {}
@@ -65,8 +65,14 @@ func buildPlanForLogQuery(
 		// parse filters to be tracked separately, and not included in maketable predicates
 		predicates          []Value
 		postParsePredicates []Value
+		// sampling predicates can't be pushed down to maketable, but have to be
+		// applied before parsing when they precede a parser
+		preParseSamples []Value
 		hasLogfmtParser     bool
 		hasJSONParser       bool
+
+		stats *syntax.StatsExpr
+		join  *syntax.JoinExpr
 	)
 
 	// TODO(chaudum): Implement a Walk function that can return an error
@@ -143,6 +149,27 @@ func buildPlanForLogQuery(
 				dropCols = append(dropCols, value)
 			}
 			return true
+		case *syntax.SamplingExpr:
+			if e.Extrapolate {
+				err = unimplementedFeature("sample --extrapolate")
+				return false // do not traverse children
+			}
+			// Sampling by labels hashes the labels at the position of the stage,
+			// so the stage must not be moved across a parser.
+			if !hasLogfmtParser && !hasJSONParser {
+				preParseSamples = append(preParseSamples, convertSamplingExpr(e))
+			} else {
+				postParsePredicates = append(postParsePredicates, convertSamplingExpr(e))
+			}
+			return true
+		case *syntax.StatsExpr:
+			// The parser ensures that stats is the last stage of the pipeline.
+			stats = e
+			return false // do not traverse children
+		case *syntax.JoinExpr:
+			// The parser ensures that join is the last stage of the pipeline.
+			join = e
+			return false // do not traverse children
 		default:
 			err = errUnimplemented
 			return false // do not traverse children
@@ -170,7 +197,7 @@ func buildPlanForLogQuery(
 	// builder = builder.ProjectAll(false, false)
 
 	direction := params.Direction()
-	if !isMetricQuery && direction == logproto.FORWARD {
+	if !isMetricQuery && stats == nil && direction == logproto.FORWARD {
 		return nil, fmt.Errorf("forward search log queries are not supported: %w", errUnimplemented)
 	}
 
@@ -185,6 +212,9 @@ func buildPlanForLogQuery(
 	for _, value := range predicates {
 		builder = builder.Select(value)
 	}
+	for _, value := range preParseSamples {
+		builder = builder.Select(value)
+	}
 
 	// TODO: there's a subtle bug here, as it is actually possible to have both a logfmt parser and a json parser
 	// for example, the query `{app="foo"} | json | line_format "{{.nested_json}}" | json ` is valid, and will need
@@ -204,6 +234,29 @@ func buildPlanForLogQuery(
 		builder = builder.ProjectDrop(dropCols...)
 	}
 
+	// JOIN -> Join
+	if join != nil {
+		right, err := buildPlanForJoin(join, params, rangeInterval)
+		if err != nil {
+			return nil, err
+		}
+		on := make([]ColumnRef, len(join.On))
+		for i, name := range join.On {
+			on[i] = *NewColumnRef(name, types.ColumnTypeAmbiguous)
+		}
+		builder = builder.Join(right, on, join.Window)
+	}
+
+	// STATS -> Stats
+	// Stats aggregates all log lines into a table, which is neither sorted nor limited.
+	if stats != nil {
+		groupBy, aggregations, err := convertStatsExpr(stats)
+		if err != nil {
+			return nil, err
+		}
+		return builder.Stats(groupBy, aggregations).Value(), nil
+	}
+
 	// Metric queries do not apply a limit.
 	if !isMetricQuery {
 		// SORT -> SortMerge
@@ -219,6 +272,30 @@ func buildPlanForLogQuery(
 	return builder.Value(), nil
 }
 
+// buildPlanForJoin builds the logical plan of the query of a join stage.
+// Its rows are read from the time range of the query extended by the join
+// window on both sides, so that rows at the edges of the query range can be
+// matched. The joined rows are neither sorted nor limited, which is why the
+// query is built like the input of a metric query.
+func buildPlanForJoin(e *syntax.JoinExpr, params logql.Params, rangeInterval time.Duration) (Value, error) {
+	joinParams := &joinParams{
+		Params: params,
+		start:  params.Start().Add(-e.Window),
+		end:    params.End().Add(e.Window),
+	}
+	return buildPlanForLogQuery(e.Right, joinParams, true, rangeInterval)
+}
+
+// joinParams overrides the time range of [logql.Params] for the query of a
+// join stage.
+type joinParams struct {
+	logql.Params
+	start, end time.Time
+}
+
+func (p *joinParams) Start() time.Time { return p.start }
+func (p *joinParams) End() time.Time   { return p.end }
+
 func walkRangeAggregation(e *syntax.RangeAggregationExpr, params logql.Params) (Value, error) {
 	// offsets are not yet supported.
 	if e.Left.Offset != 0 {
@@ -456,6 +533,43 @@ func convertVectorAggregationType(op string) types.VectorAggregationType {
 	}
 }
 
+func convertStatsExpr(e *syntax.StatsExpr) ([]ColumnRef, []StatsAggregation, error) {
+	var groupBy []ColumnRef
+	if e.Grouping != nil {
+		groupBy = make([]ColumnRef, 0, len(e.Grouping.Groups))
+		for _, group := range e.Grouping.Groups {
+			groupBy = append(groupBy, *NewColumnRef(group, types.ColumnTypeAmbiguous))
+		}
+	}
+
+	aggregations := make([]StatsAggregation, 0, len(e.Aggregations))
+	for _, agg := range e.Aggregations {
+		var op types.VectorAggregationType
+		switch agg.Operation {
+		case syntax.OpTypeCount:
+			op = types.VectorAggregationTypeCount
+		case syntax.OpTypeSum:
+			op = types.VectorAggregationTypeSum
+		case syntax.OpTypeAvg:
+			op = types.VectorAggregationTypeAvg
+		case syntax.OpTypeMin:
+			op = types.VectorAggregationTypeMin
+		case syntax.OpTypeMax:
+			op = types.VectorAggregationTypeMax
+		default:
+			return nil, nil, unimplementedFeature(fmt.Sprintf("stats aggregation %s", agg.Operation))
+		}
+
+		aggregation := StatsAggregation{Operation: op}
+		if agg.Label != "" {
+			aggregation.Column = NewColumnRef(agg.Label, types.ColumnTypeAmbiguous)
+		}
+		aggregations = append(aggregations, aggregation)
+	}
+
+	return groupBy, aggregations, nil
+}
+
 func convertMatcherType(t labels.MatchType) types.BinaryOp {
 	switch t {
 	case labels.MatchEqual:
@@ -493,6 +607,26 @@ func convertLineFilter(filter syntax.LineFilter) Value {
 	}
 }
 
+// convertSamplingExpr converts a sample stage into a function that returns
+// whether a row is kept. The first argument is the ratio, followed by the
+// columns that make up the sample key.
+func convertSamplingExpr(expr *syntax.SamplingExpr) Value {
+	if len(expr.By) == 0 {
+		return &FunctionOp{
+			Op:     types.VariadicOpSampleLine,
+			Values: []Value{NewLiteral(expr.Ratio), timestampColumnRef(), lineColumnRef()},
+		}
+	}
+	values := []Value{NewLiteral(expr.Ratio)}
+	for _, name := range expr.By {
+		values = append(values, NewColumnRef(name, types.ColumnTypeAmbiguous))
+	}
+	return &FunctionOp{
+		Op:     types.VariadicOpSampleLabels,
+		Values: values,
+	}
+}
+
 func convertBinaryArithmeticOp(op string) types.BinaryOp {
 	switch op {
 	case syntax.OpTypeAdd:
@@ -122,6 +122,148 @@ RETURN %21
 	t.Logf("\n%s\n", sb.String())
 }
 
+func TestConvertAST_StatsQuery_Success(t *testing.T) {
+	q := &query{
+		statement: `{cluster="prod"} | logfmt | stats count(), avg(latency) by (status)`,
+		start:     3600,
+		end:       7200,
+		direction: logproto.BACKWARD,
+		limit:     1000,
+	}
+	logicalPlan, err := BuildPlan(q)
+	require.NoError(t, err)
+	t.Logf("\n%s\n", logicalPlan.String())
+
+	expected := `%1 = EQ label.cluster "prod"
+%2 = MAKETABLE [selector=%1, predicates=[], shard=0_of_1]
+%3 = GTE builtin.timestamp 1970-01-01T01:00:00Z
+%4 = SELECT %2 [predicate=%3]
+%5 = LT builtin.timestamp 1970-01-01T02:00:00Z
+%6 = SELECT %4 [predicate=%5]
+%7 = PROJECT %6 [mode=*E, expr=PARSE_LOGFMT(builtin.message)]
+%8 = STATS %7 [aggregations=(count(), avg(ambiguous.latency)), group_by=(ambiguous.status)]
+%9 = LOGQL_COMPAT %8
+RETURN %9
+`
+
+	require.Equal(t, expected, logicalPlan.String())
+
+	var sb strings.Builder
+	PrintTree(&sb, logicalPlan.Value())
+
+	t.Logf("\n%s\n", sb.String())
+}
+
+func TestConvertAST_JoinQuery_Success(t *testing.T) {
+	q := &query{
+		statement: `{app="a"} | logfmt | join on (request_id) [30s] ({app="b"} |= "error")`,
+		start:     3600,
+		end:       7200,
+		direction: logproto.BACKWARD,
+		limit:     1000,
+	}
+	logicalPlan, err := BuildPlan(q)
+	require.NoError(t, err)
+	t.Logf("\n%s\n", logicalPlan.String())
+
+	expected := `%1 = EQ label.app "a"
+%2 = MAKETABLE [selector=%1, predicates=[], shard=0_of_1]
+%3 = GTE builtin.timestamp 1970-01-01T01:00:00Z
+%4 = SELECT %2 [predicate=%3]
+%5 = LT builtin.timestamp 1970-01-01T02:00:00Z
+%6 = SELECT %4 [predicate=%5]
+%7 = PROJECT %6 [mode=*E, expr=PARSE_LOGFMT(builtin.message)]
+%8 = EQ label.app "b"
+%9 = MATCH_STR builtin.message "error"
+%10 = MAKETABLE [selector=%8, predicates=[%9], shard=0_of_1]
+%11 = GTE builtin.timestamp 1970-01-01T00:59:30Z
+%12 = SELECT %10 [predicate=%11]
+%13 = LT builtin.timestamp 1970-01-01T02:00:30Z
+%14 = SELECT %12 [predicate=%13]
+%15 = SELECT %14 [predicate=%9]
+%16 = JOIN %7 %15 [on=(ambiguous.request_id), window=30s]
+%17 = SORT %16 [column=builtin.timestamp, asc=false, nulls_first=false]
+%18 = LIMIT %17 [skip=0, fetch=1000]
+%19 = LOGQL_COMPAT %18
+RETURN %19
+`
+
+	require.Equal(t, expected, logicalPlan.String())
+
+	var sb strings.Builder
+	PrintTree(&sb, logicalPlan.Value())
+
+	t.Logf("\n%s\n", sb.String())
+}
+
+func TestConvertAST_SampleQuery_Success(t *testing.T) {
+	q := &query{
+		statement: `{cluster="prod"} |= "error" | sample 0.1 | logfmt | sample 0.5 by (trace_id)`,
+		start:     3600,
+		end:       7200,
+		direction: logproto.BACKWARD,
+		limit:     1000,
+	}
+	logicalPlan, err := BuildPlan(q)
+	require.NoError(t, err)
+	t.Logf("\n%s\n", logicalPlan.String())
+
+	expected := `%1 = EQ label.cluster "prod"
+%2 = MATCH_STR builtin.message "error"
+%3 = MAKETABLE [selector=%1, predicates=[%2], shard=0_of_1]
+%4 = GTE builtin.timestamp 1970-01-01T01:00:00Z
+%5 = SELECT %3 [predicate=%4]
+%6 = LT builtin.timestamp 1970-01-01T02:00:00Z
+%7 = SELECT %5 [predicate=%6]
+%8 = SELECT %7 [predicate=%2]
+%9 = SAMPLE_LINE(0.1, builtin.timestamp, builtin.message)
+%10 = SELECT %8 [predicate=%9]
+%11 = PROJECT %10 [mode=*E, expr=PARSE_LOGFMT(builtin.message)]
+%12 = SAMPLE_LABELS(0.5, ambiguous.trace_id)
+%13 = SELECT %11 [predicate=%12]
+%14 = SORT %13 [column=builtin.timestamp, asc=false, nulls_first=false]
+%15 = LIMIT %14 [skip=0, fetch=1000]
+%16 = LOGQL_COMPAT %15
+RETURN %16
+`
+
+	require.Equal(t, expected, logicalPlan.String())
+}
+
+func TestConvertAST_SampleQuery_BeforeParser(t *testing.T) {
+	// Sampling by labels before a parser must hash the labels of the stream,
+	// not the labels extracted by the parser.
+	q := &query{
+		statement: `{cluster="prod"} | sample 0.5 by (pod) | logfmt | level="error"`,
+		start:     3600,
+		end:       7200,
+		direction: logproto.BACKWARD,
+		limit:     1000,
+	}
+	logicalPlan, err := BuildPlan(q)
+	require.NoError(t, err)
+	t.Logf("\n%s\n", logicalPlan.String())
+
+	expected := `%1 = EQ label.cluster "prod"
+%2 = MAKETABLE [selector=%1, predicates=[], shard=0_of_1]
+%3 = GTE builtin.timestamp 1970-01-01T01:00:00Z
+%4 = SELECT %2 [predicate=%3]
+%5 = LT builtin.timestamp 1970-01-01T02:00:00Z
+%6 = SELECT %4 [predicate=%5]
+%7 = SAMPLE_LABELS(0.5, ambiguous.pod)
+%8 = SELECT %6 [predicate=%7]
+%9 = PROJECT %8 [mode=*E, expr=PARSE_LOGFMT(builtin.message)]
+%10 = EQ ambiguous.level "error"
+%11 = SELECT %9 [predicate=%10]
+%12 = SORT %11 [column=builtin.timestamp, asc=false, nulls_first=false]
+%13 = LIMIT %12 [skip=0, fetch=1000]
+%14 = LOGQL_COMPAT %13
+RETURN %14
+`
+
+	require.Equal(t, expected, logicalPlan.String())
+}
+
 func TestConvertAST_MetricQuery_Success(t *testing.T) {
 	t.Run("simple metric query", func(t *testing.T) {
 		q := &query{
@@ -322,6 +464,18 @@ func TestCanExecuteQuery(t *testing.T) {
 			statement: `sum by (level) (rate({env="prod"}[1m]))`,
 			expected:  true,
 		},
+		{
+			statement: `{env="prod"} | logfmt | stats count(), sum(bytes), avg(latency), min(latency), max(latency) by (level)`,
+			expected:  true,
+		},
+		{
+			statement: `{env="prod"} | logfmt | join on (request_id) [30s] ({env="dev"} | logfmt)`,
+			expected:  true,
+		},
+		{
+			statement: `sum(count_over_time({env="prod"} | logfmt | join on (request_id) [30s] ({env="dev"} | logfmt) [1m]))`,
+			expected:  true,
+		},
 		{
 			// max is not supported
 			statement: `max by (level) (count_over_time({env="prod"}[1m]))`,
@@ -361,6 +515,18 @@ func TestCanExecuteQuery(t *testing.T) {
 		{
 			statement: `sum(count_over_time({env="prod"} | logfmt | drop __error__=~"Unknown Error: .*" [1m]))`,
 		},
+		{
+			statement: `{env="prod"} | sample 0.1`,
+			expected:  true,
+		},
+		{
+			statement: `sum(count_over_time({env="prod"} | logfmt | sample 0.1 by (trace_id) [1m]))`,
+			expected:  true,
+		},
+		{
+			// extrapolation is not supported
+			statement: `sum(count_over_time({env="prod"} | sample 0.1 --extrapolate [1m]))`,
+		},
 	} {
 		t.Run(tt.statement, func(t *testing.T) {
 			q := &query{
@@ -1,11 +1,28 @@
 package physical
 
-import "fmt"
+import (
+	"fmt"
+	"time"
+)
 
 // Join represents a join operation in the physical plan.
-// For now it is only an inner join on `timestamp`. Will be expanded later.
+//
+// Without On columns it is an inner join on `timestamp`, which is used for
+// binary operations between two aggregations.
+//
+// With On columns it correlates the rows of its left input with the rows of
+// its right input that have the same values for the On columns and whose
+// timestamps are at most Window apart. The right input is buffered in memory,
+// so each input must be the single child of the join, e.g. a [Merge].
 type Join struct {
 	id string
+
+	// On defines the columns that both rows of a matched pair must have the
+	// same values for.
+	On []ColumnExpression
+
+	// Window is the maximum distance between the timestamps of a matched pair.
+	Window time.Duration
 }
 
 // ID implements the [Node] interface.
@@ -19,7 +36,10 @@ func (f *Join) ID() string {
 
 // Clone returns a deep copy of the node (minus its ID).
 func (f *Join) Clone() Node {
-	return &Join{}
+	return &Join{
+		On:     cloneExpressions(f.On),
+		Window: f.Window,
+	}
 }
 
 // Type implements the [Node] interface.
@@ -0,0 +1,32 @@
+package physical
+
+import "fmt"
+
+// Merge represents an operation that combines all rows of its children into
+// a single sequence with no guaranteed order.
+//
+// Merge is a pipeline breaker, which allows nodes with more than one input,
+// such as a [Join], to receive each input as a single stream.
+type Merge struct {
+	id string
+}
+
+// ID implements the [Node] interface.
+// Returns a string that uniquely identifies the node in the plan.
+func (m *Merge) ID() string {
+	if m.id == "" {
+		return fmt.Sprintf("%p", m)
+	}
+	return m.id
+}
+
+// Clone returns a deep copy of the node (minus its ID).
+func (m *Merge) Clone() Node {
+	return &Merge{ /* nothing to clone */ }
+}
+
+// Type implements the [Node] interface.
+// Returns the type of the node.
+func (*Merge) Type() NodeType {
+	return NodeTypeMerge
+}
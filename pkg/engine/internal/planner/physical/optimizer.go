@@ -148,6 +148,10 @@ func (r *limitPushdown) applyToTargets(node Node, limit uint32) bool {
 		// If there is a filter, child nodes may need to read up to all their lines
 		// to successfully apply the filter, so stop applying limit pushdown.
 		return false
+	case *Join:
+		// Any row of the inputs of a join may be part of a matched pair, so stop
+		// applying limit pushdown.
+		return false
 	}
 
 	// Continue to children
@@ -264,6 +268,18 @@ func (r *projectionPushdown) propagateProjections(node Node, projections []Colum
 		// Always project timestamp column even if partitionBy is empty.
 		// Timestamp values are required to perform range aggregation.
 		projections = append(projections, &ColumnExpr{Ref: types.ColumnRef{Column: types.ColumnNameBuiltinTimestamp, Type: types.ColumnTypeBuiltin}})
+	case *Stats:
+		// [Source] Stats requires groupBy columns & aggregated columns.
+		projections = append(projections, node.GroupBy...)
+		for _, agg := range node.Aggregations {
+			if agg.Column != nil {
+				projections = append(projections, agg.Column)
+			}
+		}
+	case *Join:
+		// [Source] Join requires the columns to join on & timestamp.
+		projections = append(projections, node.On...)
+		projections = append(projections, &ColumnExpr{Ref: types.ColumnRef{Column: types.ColumnNameBuiltinTimestamp, Type: types.ColumnTypeBuiltin}})
 	case *Filter:
 		// [Source] Filter nodes require predicate columns.
 		extracted := extractColumnsFromPredicates(node.Predicates)
@@ -486,7 +502,8 @@ func sortProjections(a, b ColumnExpression) int {
 	return 0
 }
 
-// isMetricQuery checks if the plan contains a RangeAggregation or VectorAggregation node, indicating a metric query
+// isMetricQuery checks if the plan contains a RangeAggregation, VectorAggregation or Stats node, indicating a query
+// that only needs a subset of the columns.
 func (r *projectionPushdown) isMetricQuery() bool {
 	for node := range r.plan.graph.Nodes() {
 		if _, ok := node.(*RangeAggregation); ok {
@@ -495,6 +512,9 @@ func (r *projectionPushdown) isMetricQuery() bool {
 		if _, ok := node.(*VectorAggregation); ok {
 			return true
 		}
+		if _, ok := node.(*Stats); ok {
+			return true
+		}
 	}
 	return false
 }
@@ -667,6 +687,10 @@ func extractColumnsFromExpression(expr Expression, columns *[]ColumnExpression)
 		extractColumnsFromExpression(e.Right, columns)
 	case *UnaryExpr:
 		extractColumnsFromExpression(e.Left, columns)
+	case *VariadicExpr:
+		for _, arg := range e.Expressions {
+			extractColumnsFromExpression(arg, columns)
+		}
 	default:
 		// Ignore other expression types
 	}
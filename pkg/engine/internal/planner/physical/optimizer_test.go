@@ -433,6 +433,65 @@ func TestProjectionPushdown(t *testing.T) {
 		require.Equal(t, expected, actual)
 	})
 
+	t.Run("stats groupBy and aggregations -> scanset", func(t *testing.T) {
+		groupBy := []ColumnExpression{
+			&ColumnExpr{Ref: types.ColumnRef{Column: "status", Type: types.ColumnTypeAmbiguous}},
+		}
+		latency := &ColumnExpr{Ref: types.ColumnRef{Column: "latency", Type: types.ColumnTypeAmbiguous}}
+		aggregations := []StatsAggregation{
+			{Name: "count()", Operation: types.VectorAggregationTypeCount},
+			{Name: "avg(latency)", Operation: types.VectorAggregationTypeAvg, Column: latency},
+		}
+
+		plan := &Plan{}
+		{
+			scanset := plan.graph.Add(&ScanSet{
+				id: "set",
+				Targets: []*ScanTarget{
+					{Type: ScanTypeDataObject, DataObject: &DataObjScan{}},
+				},
+			})
+			stats := plan.graph.Add(&Stats{
+				id:           "stats",
+				GroupBy:      groupBy,
+				Aggregations: aggregations,
+			})
+
+			_ = plan.graph.AddEdge(dag.Edge[Node]{Parent: stats, Child: scanset})
+		}
+
+		// apply optimisations
+		optimizations := []*optimization{
+			newOptimization("projection pushdown", plan).withRules(
+				&projectionPushdown{plan: plan},
+			),
+		}
+		o := newOptimizer(plan, optimizations)
+		o.optimize(plan.Roots()[0])
+
+		expectedPlan := &Plan{}
+		{
+			scanset := expectedPlan.graph.Add(&ScanSet{
+				id: "set",
+				Targets: []*ScanTarget{
+					{Type: ScanTypeDataObject, DataObject: &DataObjScan{}},
+				},
+				Projections: []ColumnExpression{latency, groupBy[0]},
+			})
+			stats := expectedPlan.graph.Add(&Stats{
+				id:           "stats",
+				GroupBy:      groupBy,
+				Aggregations: aggregations,
+			})
+
+			_ = expectedPlan.graph.AddEdge(dag.Edge[Node]{Parent: stats, Child: scanset})
+		}
+
+		actual := PrintAsTree(plan)
+		expected := PrintAsTree(expectedPlan)
+		require.Equal(t, expected, actual)
+	})
+
 	t.Run("filter -> scanset", func(t *testing.T) {
 		filterPredicates := []Expression{
 			&BinaryExpr{
@@ -23,6 +23,7 @@ const (
 	NodeTypeParallelize
 	NodeTypeScanSet
 	NodeTypeJoin
+	NodeTypeStats
 )
 
 func (t NodeType) String() string {
@@ -55,6 +56,8 @@ func (t NodeType) String() string {
 		return "ScanSet"
 	case NodeTypeJoin:
 		return "Join"
+	case NodeTypeStats:
+		return "Stats"
 	default:
 		return "Undefined"
 	}
@@ -103,6 +106,8 @@ var _ Node = (*TopK)(nil)
 var _ Node = (*Parallelize)(nil)
 var _ Node = (*ScanSet)(nil)
 var _ Node = (*Join)(nil)
+var _ Node = (*Stats)(nil)
+var _ Node = (*Merge)(nil)
 
 func (*DataObjScan) isNode()       {}
 func (*Projection) isNode()        {}
@@ -115,6 +120,8 @@ func (*TopK) isNode()              {}
 func (*Parallelize) isNode()       {}
 func (*ScanSet) isNode()           {}
 func (*Join) isNode()              {}
+func (*Stats) isNode()             {}
+func (*Merge) isNode()             {}
 
 // Plan represents a physical execution plan as a directed acyclic graph (DAG).
 // It maintains the relationships between nodes, tracking parent-child connections
@@ -160,6 +160,10 @@ func (p *Planner) process(inst logical.Value, ctx *Context) (Node, error) {
 		return p.processRangeAggregation(inst, ctx)
 	case *logical.VectorAggregation:
 		return p.processVectorAggregation(inst, ctx)
+	case *logical.Stats:
+		return p.processStats(inst, ctx)
+	case *logical.Join:
+		return p.processJoin(inst, ctx)
 	case *logical.BinOp:
 		return p.processBinOp(inst, ctx)
 	case *logical.UnaryOp:
@@ -398,6 +402,78 @@ func (p *Planner) processVectorAggregation(lp *logical.VectorAggregation, ctx *C
 	return node, nil
 }
 
+// Convert [logical.Stats] into one [Stats] node.
+func (p *Planner) processStats(lp *logical.Stats, ctx *Context) (Node, error) {
+	groupBy := make([]ColumnExpression, len(lp.GroupBy))
+	for i, col := range lp.GroupBy {
+		groupBy[i] = &ColumnExpr{Ref: col.Ref}
+	}
+
+	aggregations := make([]StatsAggregation, len(lp.Aggregations))
+	for i, agg := range lp.Aggregations {
+		aggregations[i] = StatsAggregation{
+			Name:      agg.Name(),
+			Operation: agg.Operation,
+		}
+		if agg.Column != nil {
+			aggregations[i].Column = &ColumnExpr{Ref: agg.Column.Ref}
+		}
+	}
+
+	node := &Stats{
+		GroupBy:      groupBy,
+		Aggregations: aggregations,
+	}
+	p.plan.graph.Add(node)
+	child, err := p.process(lp.Table, ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.plan.graph.AddEdge(dag.Edge[Node]{Parent: node, Child: child}); err != nil {
+		return nil, err
+	}
+	return node, nil
+}
+
+// Convert [logical.Join] into one [Join] node. Each input of the join is
+// wrapped into a [Merge] node, so the join receives exactly one stream per
+// input. The right input is read from the time range of the query extended by
+// the join window on both sides.
+func (p *Planner) processJoin(lp *logical.Join, ctx *Context) (Node, error) {
+	on := make([]ColumnExpression, len(lp.On))
+	for i, col := range lp.On {
+		on[i] = &ColumnExpr{Ref: col.Ref}
+	}
+
+	node := &Join{
+		On:     on,
+		Window: lp.Window,
+	}
+	p.plan.graph.Add(node)
+
+	inputs := []struct {
+		value logical.Value
+		ctx   *Context
+	}{
+		{value: lp.Left, ctx: ctx},
+		{value: lp.Right, ctx: ctx.WithTimeRange(ctx.from.Add(-lp.Window), ctx.through.Add(lp.Window))},
+	}
+	for _, input := range inputs {
+		child, err := p.process(input.value, input.ctx)
+		if err != nil {
+			return nil, err
+		}
+		merge, err := p.wrapNodeWith(child, &Merge{})
+		if err != nil {
+			return nil, err
+		}
+		if err := p.plan.graph.AddEdge(dag.Edge[Node]{Parent: node, Child: merge}); err != nil {
+			return nil, err
+		}
+	}
+	return node, nil
+}
+
 // collapseMathExpressions traverses over a subtree of math expressions `c` (BinOps, UnaryOps, or Literals) and collapses them
 // into a Projection node with a complex Expression. It may insert a Join node if it finds a BinOp with two obj scan inputs.
 // Parameters:
@@ -836,3 +836,89 @@ func TestPlanner_MakeTable_Ordering(t *testing.T) {
 		require.Equal(t, expected, actual)
 	})
 }
+
+// rangeRecordingCatalog records the time ranges that shard descriptors are
+// resolved for.
+type rangeRecordingCatalog struct {
+	catalog
+	ranges [][2]time.Time
+}
+
+func (c *rangeRecordingCatalog) ResolveShardDescriptorsWithShard(e Expression, p []Expression, shard ShardInfo, from, through time.Time) ([]FilteredShardDescriptor, error) {
+	c.ranges = append(c.ranges, [2]time.Time{from, through})
+	return c.catalog.ResolveShardDescriptorsWithShard(e, p, shard, from, through)
+}
+
+func TestPlanner_Convert_Join(t *testing.T) {
+	// Build a query plan for:
+	// { app="a" } | join on (request_id) [30s] ({ app="b" })
+	makeTable := func(app string) *logical.MakeTable {
+		return &logical.MakeTable{
+			Selector: &logical.BinOp{
+				Left:  logical.NewColumnRef("app", types.ColumnTypeLabel),
+				Right: logical.NewLiteral(app),
+				Op:    types.BinaryOpEq,
+			},
+			Shard: logical.NewShard(0, 1), // no sharding
+		}
+	}
+	right := logical.NewBuilder(makeTable("b")).Value()
+	b := logical.NewBuilder(makeTable("a")).Join(
+		right,
+		[]logical.ColumnRef{*logical.NewColumnRef("request_id", types.ColumnTypeAmbiguous)},
+		30*time.Second,
+	).Sort(
+		*logical.NewColumnRef("timestamp", types.ColumnTypeBuiltin),
+		false,
+		false,
+	).Limit(0, 100)
+
+	logicalPlan, err := b.ToPlan()
+	require.NoError(t, err)
+
+	timeStart := time.Unix(3600, 0)
+	timeEnd := timeStart.Add(time.Hour)
+	catalog := &rangeRecordingCatalog{
+		catalog: catalog{
+			sectionDescriptors: []*metastore.DataobjSectionDescriptor{
+				{SectionKey: metastore.SectionKey{ObjectPath: "obj1", SectionIdx: 0}, StreamIDs: []int64{1, 2}, Start: timeStart, End: timeEnd},
+			},
+		},
+	}
+	planner := NewPlanner(NewContext(timeStart, timeEnd), catalog)
+
+	physicalPlan, err := planner.Build(logicalPlan)
+	require.NoError(t, err)
+	physicalPlan, err = planner.Optimize(physicalPlan)
+	require.NoError(t, err)
+	t.Logf("Optimized plan\n%s\n", PrintAsTree(physicalPlan))
+
+	// The right input is resolved for the time range extended by the window.
+	require.Equal(t, [][2]time.Time{
+		{timeStart, timeEnd},
+		{timeStart.Add(-30 * time.Second), timeEnd.Add(30 * time.Second)},
+	}, catalog.ranges)
+
+	root, err := physicalPlan.Root()
+	require.NoError(t, err)
+	require.Equal(t, NodeTypeLimit, root.Type())
+
+	topk := physicalPlan.Children(root)[0]
+	require.Equal(t, NodeTypeTopK, topk.Type())
+	require.Equal(t, 100, topk.(*TopK).K)
+
+	join := physicalPlan.Children(topk)[0]
+	require.Equal(t, &Join{
+		On:     []ColumnExpression{&ColumnExpr{Ref: types.ColumnRef{Column: "request_id", Type: types.ColumnTypeAmbiguous}}},
+		Window: 30 * time.Second,
+	}, join)
+
+	// Each input of the join is merged into a single stream, and the limit
+	// is not pushed down into the inputs.
+	inputs := physicalPlan.Children(join)
+	require.Len(t, inputs, 2)
+	for _, input := range inputs {
+		require.Equal(t, NodeTypeMerge, input.Type())
+		require.Equal(t, NodeTypeParallelize, physicalPlan.Children(input)[0].Type())
+	}
+}
@@ -77,6 +77,14 @@ func toTreeNode(n Node) *tree.Node {
 			tree.NewProperty("operation", false, node.Operation),
 		}
 
+		if len(node.GroupBy) > 0 {
+			treeNode.Properties = append(treeNode.Properties, tree.NewProperty("group_by", true, toAnySlice(node.GroupBy)...))
+		}
+	case *Stats:
+		treeNode.Properties = []tree.Property{
+			tree.NewProperty("aggregations", true, toAnySlice(node.Aggregations)...),
+		}
+
 		if len(node.GroupBy) > 0 {
 			treeNode.Properties = append(treeNode.Properties, tree.NewProperty("group_by", true, toAnySlice(node.GroupBy)...))
 		}
@@ -93,7 +101,14 @@ func toTreeNode(n Node) *tree.Node {
 			tree.NewProperty("nulls_first", false, node.NullsFirst),
 			tree.NewProperty("k", false, node.K),
 		}
-	case *Parallelize:
+	case *Join:
+		if len(node.On) > 0 {
+			treeNode.Properties = []tree.Property{
+				tree.NewProperty("on", true, toAnySlice(node.On)...),
+				tree.NewProperty("window", false, node.Window),
+			}
+		}
+	case *Parallelize, *Merge:
 		// Nothing to add
 	case *ScanSet:
 		treeNode.Properties = []tree.Property{
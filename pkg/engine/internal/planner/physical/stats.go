@@ -0,0 +1,71 @@
+package physical
+
+import (
+	"fmt"
+
+	"github.com/grafana/loki/v3/pkg/engine/internal/types"
+)
+
+// Stats represents a physical plan node that aggregates all rows of its input
+// into a tabular result, with one row per group and one column per
+// aggregation.
+type Stats struct {
+	id string
+
+	// GroupBy defines the columns to group by. If empty, all rows are aggregated into a single row.
+	GroupBy []ColumnExpression
+
+	// Aggregations defines the aggregations to compute for each group.
+	Aggregations []StatsAggregation
+}
+
+// StatsAggregation is a single aggregation computed by a [Stats] node.
+type StatsAggregation struct {
+	// Name is the name of the output column of the aggregation, e.g. avg(latency).
+	Name string
+
+	// Operation defines the type of aggregation operation to perform (e.g., count, sum, avg)
+	Operation types.VectorAggregationType
+
+	// Column is the aggregated column. It is nil for count, which counts rows.
+	Column ColumnExpression
+}
+
+// String returns the string representation of the aggregation.
+func (a StatsAggregation) String() string {
+	if a.Column == nil {
+		return fmt.Sprintf("%s()", a.Operation)
+	}
+	return fmt.Sprintf("%s(%s)", a.Operation, a.Column.String())
+}
+
+// ID implements the [Node] interface.
+// Returns a string that uniquely identifies the node in the plan.
+func (s *Stats) ID() string {
+	if s.id == "" {
+		return fmt.Sprintf("%p", s)
+	}
+	return s.id
+}
+
+// Clone returns a deep copy of the node (minus its ID).
+func (s *Stats) Clone() Node {
+	aggregations := make([]StatsAggregation, len(s.Aggregations))
+	for i, agg := range s.Aggregations {
+		aggregations[i] = agg
+		if agg.Column != nil {
+			aggregations[i].Column = agg.Column.Clone().(ColumnExpression)
+		}
+	}
+
+	return &Stats{
+		GroupBy:      cloneExpressions(s.GroupBy),
+		Aggregations: aggregations,
+	}
+}
+
+// Type implements the [Node] interface.
+// Returns the type of the node.
+func (*Stats) Type() NodeType {
+	return NodeTypeStats
+}
@@ -297,6 +297,61 @@ VectorAggregation operation=sum group_by=(ambiguous.bar)
                             └── @target type=ScanTypeDataObject location=objects/00/0000000000.dataobj streams=5 section_id=0 projections=()
 						`,
 		},
+		{
+			comment: "stats: aggregate parsed values by group",
+			query:   `{app="foo"} | logfmt | stats count(), avg(latency) by (status)`,
+			expected: `
+Stats aggregations=(count(), avg(ambiguous.latency)) group_by=(ambiguous.status)
+└── Parallelize
+    └── Compat src=parsed dst=parsed collision=label
+        └── Projection all=true expand=(PARSE_LOGFMT(builtin.message, [latency, status]))
+            └── Compat src=metadata dst=metadata collision=label
+                └── ScanSet num_targets=2 projections=(ambiguous.latency, builtin.message, ambiguous.status) predicate[0]=GTE(builtin.timestamp, 2025-01-01T00:00:00Z) predicate[1]=LT(builtin.timestamp, 2025-01-01T01:00:00Z)
+                        ├── @target type=ScanTypeDataObject location=objects/00/0000000000.dataobj streams=5 section_id=1 projections=()
+                        └── @target type=ScanTypeDataObject location=objects/00/0000000000.dataobj streams=5 section_id=0 projections=()
+			`,
+		},
+		{
+			comment: "join: count matched pairs",
+			query:   `sum(count_over_time({app="foo"} | logfmt | join on (request_id) [30s] ({app="foo"} | logfmt) [1m]))`,
+			expected: `
+VectorAggregation operation=sum
+└── RangeAggregation operation=count start=2025-01-01T00:00:00Z end=2025-01-01T01:00:00Z step=0s range=1m0s
+    └── Join on=(ambiguous.request_id) window=30s
+        ├── Merge
+        │   └── Parallelize
+        │       └── Compat src=parsed dst=parsed collision=label
+        │           └── Projection all=true expand=(PARSE_LOGFMT(builtin.message, [request_id]))
+        │               └── Compat src=metadata dst=metadata collision=label
+        │                   └── ScanSet num_targets=2 projections=(builtin.message, ambiguous.request_id, builtin.timestamp) predicate[0]=GTE(builtin.timestamp, 2024-12-31T23:59:00Z) predicate[1]=LT(builtin.timestamp, 2025-01-01T01:00:00Z)
+        │                           ├── @target type=ScanTypeDataObject location=objects/00/0000000000.dataobj streams=5 section_id=1 projections=()
+        │                           └── @target type=ScanTypeDataObject location=objects/00/0000000000.dataobj streams=5 section_id=0 projections=()
+        └── Merge
+            └── Parallelize
+                └── Compat src=parsed dst=parsed collision=label
+                    └── Projection all=true expand=(PARSE_LOGFMT(builtin.message, [request_id]))
+                        └── Compat src=metadata dst=metadata collision=label
+                            └── ScanSet num_targets=2 projections=(builtin.message, ambiguous.request_id, builtin.timestamp) predicate[0]=GTE(builtin.timestamp, 2024-12-31T23:58:30Z) predicate[1]=LT(builtin.timestamp, 2025-01-01T01:00:30Z)
+                                    ├── @target type=ScanTypeDataObject location=objects/00/0000000000.dataobj streams=5 section_id=1 projections=()
+                                    └── @target type=ScanTypeDataObject location=objects/00/0000000000.dataobj streams=5 section_id=0 projections=()
+			`,
+		},
+		{
+			comment: "sample: keep all lines of a sampled trace",
+			query:   `sum(count_over_time({app="foo"} | logfmt | sample 0.1 by (trace_id) [1m]))`,
+			expected: `
+VectorAggregation operation=sum
+└── RangeAggregation operation=count start=2025-01-01T00:00:00Z end=2025-01-01T01:00:00Z step=0s range=1m0s
+    └── Parallelize
+        └── Filter predicate[0]=SAMPLE_LABELS(0.1, ambiguous.trace_id)
+            └── Compat src=parsed dst=parsed collision=label
+                └── Projection all=true expand=(PARSE_LOGFMT(builtin.message, [trace_id]))
+                    └── Compat src=metadata dst=metadata collision=label
+                        └── ScanSet num_targets=2 projections=(builtin.message, builtin.timestamp, ambiguous.trace_id) predicate[0]=GTE(builtin.timestamp, 2024-12-31T23:59:00Z) predicate[1]=LT(builtin.timestamp, 2025-01-01T01:00:00Z)
+                                ├── @target type=ScanTypeDataObject location=objects/00/0000000000.dataobj streams=5 section_id=1 projections=()
+                                └── @target type=ScanTypeDataObject location=objects/00/0000000000.dataobj streams=5 section_id=0 projections=()
+			`,
+		},
 	}
 
 	for _, tc := range testCases {
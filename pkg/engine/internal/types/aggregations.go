@@ -59,6 +59,8 @@ func (op VectorAggregationType) String() string {
 		return "min"
 	case VectorAggregationTypeCount:
 		return "count"
+	case VectorAggregationTypeAvg:
+		return "avg"
 	default:
 		return "invalid"
 	}
@@ -132,8 +132,10 @@ const (
 	// VariadicOpKindInvalid indicates an invalid unary operation.
 	VariadicOpInvalid VariadicOp = iota
 
-	VariadicOpParseLogfmt // Parse logfmt line to set of columns operation (logfmt).
-	VariadicOpParseJSON   // Parse JSON line to set of columns operation (json).
+	VariadicOpParseLogfmt  // Parse logfmt line to set of columns operation (logfmt).
+	VariadicOpParseJSON    // Parse JSON line to set of columns operation (json).
+	VariadicOpSampleLine   // Sample rows by timestamp and line operation (sample).
+	VariadicOpSampleLabels // Sample rows by label values operation (sample by).
 )
 
 // String returns the string representation of the UnaryOp.
@@ -143,6 +145,10 @@ func (t VariadicOp) String() string {
 		return "PARSE_LOGFMT"
 	case VariadicOpParseJSON:
 		return "PARSE_JSON"
+	case VariadicOpSampleLine:
+		return "SAMPLE_LINE"
+	case VariadicOpSampleLabels:
+		return "SAMPLE_LABELS"
 	default:
 		panic(fmt.Sprintf("unknown variadic operator %d", t))
 	}
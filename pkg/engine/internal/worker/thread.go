@@ -35,9 +35,10 @@ type threadJob struct {
 
 // thread represents a worker thread that executes one task at a time.
 type thread struct {
-	BatchSize int64
-	Bucket    objstore.Bucket
-	Logger    log.Logger
+	BatchSize      int64
+	JoinMaxEntries int
+	Bucket         objstore.Bucket
+	Logger         log.Logger
 
 	Ready chan<- readyRequest
 }
@@ -108,8 +109,9 @@ func (t *thread) runJob(ctx context.Context, job *threadJob) {
 	level.Info(logger).Log("msg", "starting task")
 
 	cfg := executor.Config{
-		BatchSize: t.BatchSize,
-		Bucket:    t.Bucket,
+		BatchSize:      t.BatchSize,
+		JoinMaxEntries: t.JoinMaxEntries,
+		Bucket:         t.Bucket,
 
 		GetExternalInputs: func(_ context.Context, node physical.Node) []executor.Pipeline {
 			streams := job.Task.Sources[node]
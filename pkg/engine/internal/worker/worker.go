@@ -41,6 +41,10 @@ type Config struct {
 	// read call of a task pipeline.
 	BatchSize int64
 
+	// JoinMaxEntries is the maximum number of rows of the joined query that a
+	// join buffers.
+	JoinMaxEntries int
+
 	// NumThreads is the number of worker threads to spawn. The number of
 	// threads corresponds to the number of tasks that can be executed
 	// concurrently.
@@ -143,9 +147,10 @@ func (w *Worker) run(ctx context.Context) error {
 	// Spin up worker threads.
 	for i := range numThreads {
 		t := &thread{
-			BatchSize: w.config.BatchSize,
-			Logger:    log.With(w.logger, "thread", i),
-			Bucket:    w.config.Bucket,
+			BatchSize:      w.config.BatchSize,
+			JoinMaxEntries: w.config.JoinMaxEntries,
+			Logger:         log.With(w.logger, "thread", i),
+			Bucket:         w.config.Bucket,
 
 			Ready: w.readyCh,
 		}
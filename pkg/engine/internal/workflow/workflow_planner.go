@@ -218,7 +218,7 @@ func isPipelineBreaker(node physical.Node) bool {
 	// the network, since that might impact how we're able to define this at the
 	// node level.
 	switch node.Type() {
-	case physical.NodeTypeTopK, physical.NodeTypeRangeAggregation, physical.NodeTypeVectorAggregation:
+	case physical.NodeTypeTopK, physical.NodeTypeRangeAggregation, physical.NodeTypeVectorAggregation, physical.NodeTypeStats, physical.NodeTypeMerge:
 		return true
 	}
 
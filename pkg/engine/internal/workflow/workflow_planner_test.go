@@ -117,6 +117,57 @@ RangeAggregation operation=invalid start=0001-01-01T00:00:00Z end=0001-01-01T00:
 		require.Equal(t, strings.TrimSpace(expectOuptut), strings.TrimSpace(actualOutput))
 	})
 
+	t.Run("split on merge", func(t *testing.T) {
+		ulidGen := ulidGenerator{}
+
+		var physicalGraph dag.Graph[physical.Node]
+
+		var (
+			join       = physicalGraph.Add(&physical.Join{})
+			leftMerge  = physicalGraph.Add(&physical.Merge{})
+			leftScan   = physicalGraph.Add(&physical.DataObjScan{Location: "left"})
+			rightMerge = physicalGraph.Add(&physical.Merge{})
+			rightScan  = physicalGraph.Add(&physical.DataObjScan{Location: "right"})
+		)
+
+		_ = physicalGraph.AddEdge(dag.Edge[physical.Node]{Parent: join, Child: leftMerge})
+		_ = physicalGraph.AddEdge(dag.Edge[physical.Node]{Parent: join, Child: rightMerge})
+		_ = physicalGraph.AddEdge(dag.Edge[physical.Node]{Parent: leftMerge, Child: leftScan})
+		_ = physicalGraph.AddEdge(dag.Edge[physical.Node]{Parent: rightMerge, Child: rightScan})
+
+		physicalPlan := physical.FromGraph(physicalGraph)
+
+		graph, err := planWorkflow("", physicalPlan)
+		require.NoError(t, err)
+		require.Equal(t, 3, graph.Len())
+		requireUniqueStreams(t, graph)
+		generateConsistentULIDs(&ulidGen, graph)
+
+		// The join receives one stream per input, in the order of its children.
+		expectOuptut := strings.TrimSpace(`
+Task 00000000000000000000000001
+-------------------------------
+Join
+    ├── @source stream=00000000000000000000000004
+    └── @source stream=00000000000000000000000005
+
+Task 00000000000000000000000002
+-------------------------------
+Merge
+│   └── @sink stream=00000000000000000000000004
+└── DataObjScan location=left streams=0 section_id=0 projections=()
+
+Task 00000000000000000000000003
+-------------------------------
+Merge
+│   └── @sink stream=00000000000000000000000005
+└── DataObjScan location=right streams=0 section_id=0 projections=()
+`)
+
+		actualOutput := Sprint(&Workflow{graph: graph})
+		require.Equal(t, strings.TrimSpace(expectOuptut), strings.TrimSpace(actualOutput))
+	})
+
 	t.Run("split on parallelize", func(t *testing.T) {
 		ulidGen := ulidGenerator{}
 
@@ -48,8 +48,9 @@ func NewWorker(params WorkerParams) (*Worker, error) {
 		Bucket:         params.Bucket,
 		LocalScheduler: params.LocalScheduler.inner,
 
-		BatchSize:  int64(params.Executor.BatchSize),
-		NumThreads: params.Config.WorkerThreads,
+		BatchSize:      int64(params.Executor.BatchSize),
+		JoinMaxEntries: params.Executor.JoinMaxEntries,
+		NumThreads:     params.Config.WorkerThreads,
 	})
 	if err != nil {
 		return nil, err
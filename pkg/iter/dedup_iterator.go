@@ -0,0 +1,190 @@
+package iter
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/prometheus/model/labels"
+
+	"github.com/grafana/loki/v3/pkg/logproto"
+	"github.com/grafana/loki/v3/pkg/logql/syntax"
+)
+
+// DedupCountLabel is the label added to the entries returned by a dedup
+// iterator, with the number of entries each of them replaces.
+const DedupCountLabel = "__dup_count__"
+
+type dedupEntry struct {
+	entryWithLabels
+	key   string
+	count int
+}
+
+type dedupIterator struct {
+	iter      EntryIterator
+	by        []string
+	window    time.Duration
+	exhausted bool
+
+	// pending holds the kept entries in the order of the iterator, until no
+	// more duplicates can follow them.
+	pending []*dedupEntry
+	// kept holds the latest pending entry of each key.
+	kept map[string]*dedupEntry
+	// parsed caches the parsed labels of the streams.
+	parsed map[string]labels.Labels
+
+	cur entryWithLabels
+	err error
+}
+
+// NewDedupIterator returns an iterator which collapses duplicated entries into
+// the first one. Entries are duplicates if they have the same values for the
+// given labels, or the same line if no labels are given, and their timestamps
+// are at most window apart. Entries that lack any of the labels are never
+// duplicates.
+// The returned entries have the DedupCountLabel label. The entries of the
+// wrapped iterator must be sorted by timestamp, in either direction.
+func NewDedupIterator(it EntryIterator, by []string, window time.Duration) EntryIterator {
+	return &dedupIterator{
+		iter:   it,
+		by:     by,
+		window: window,
+		kept:   make(map[string]*dedupEntry),
+		parsed: make(map[string]labels.Labels),
+	}
+}
+
+func (i *dedupIterator) Next() bool {
+	for {
+		// The first pending entry is complete once the iterator has moved past
+		// its window.
+		if len(i.pending) > 0 && (i.exhausted || !i.inWindow(i.pending[0], i.iter.At().Timestamp)) {
+			return i.pop()
+		}
+		if i.exhausted {
+			return false
+		}
+		if !i.iter.Next() {
+			i.exhausted = true
+			if err := i.iter.Err(); err != nil {
+				i.err = err
+				return false
+			}
+			continue
+		}
+
+		entry, streamLabels := i.iter.At(), i.iter.Labels()
+		key, ok, err := i.key(entry, streamLabels)
+		if err != nil {
+			i.err = err
+			return false
+		}
+		if ok {
+			if kept, found := i.kept[key]; found && i.inWindow(kept, entry.Timestamp) {
+				kept.count++
+				continue
+			}
+		}
+
+		pending := &dedupEntry{
+			entryWithLabels: entryWithLabels{Entry: entry, labels: streamLabels, streamHash: i.iter.StreamHash()},
+			key:             key,
+			count:           1,
+		}
+		i.pending = append(i.pending, pending)
+		if ok {
+			i.kept[key] = pending
+		}
+	}
+}
+
+// inWindow returns whether an entry with the given timestamp can be a
+// duplicate of the kept entry.
+func (i *dedupIterator) inWindow(kept *dedupEntry, ts time.Time) bool {
+	d := ts.Sub(kept.Timestamp)
+	if d < 0 {
+		d = -d
+	}
+	return d <= i.window
+}
+
+func (i *dedupIterator) pop() bool {
+	next := i.pending[0]
+	i.pending[0] = nil
+	i.pending = i.pending[1:]
+	if i.kept[next.key] == next {
+		delete(i.kept, next.key)
+	}
+
+	lbs, err := i.parseLabels(next.labels)
+	if err != nil {
+		i.err = err
+		return false
+	}
+	builder := labels.NewBuilder(lbs)
+	builder.Set(DedupCountLabel, strconv.Itoa(next.count))
+
+	i.cur = next.entryWithLabels
+	i.cur.labels = builder.Labels().String()
+	return true
+}
+
+// key returns the key of the duplicates of the entry, and false if the entry
+// lacks any of the labels.
+func (i *dedupIterator) key(entry logproto.Entry, streamLabels string) (string, bool, error) {
+	if len(i.by) == 0 {
+		return entry.Line, true, nil
+	}
+	lbs, err := i.parseLabels(streamLabels)
+	if err != nil {
+		return "", false, err
+	}
+	var sb strings.Builder
+	for j, name := range i.by {
+		value := lbs.Get(name)
+		if value == "" {
+			return "", false, nil
+		}
+		if j > 0 {
+			// Separate the values with a byte that can't be part of valid UTF-8.
+			sb.WriteByte(0xff)
+		}
+		sb.WriteString(value)
+	}
+	return sb.String(), true, nil
+}
+
+func (i *dedupIterator) parseLabels(streamLabels string) (labels.Labels, error) {
+	if lbs, ok := i.parsed[streamLabels]; ok {
+		return lbs, nil
+	}
+	lbs, err := syntax.ParseLabels(streamLabels)
+	if err != nil {
+		return labels.EmptyLabels(), fmt.Errorf("failed to parse series labels to deduplicate entries: %w", err)
+	}
+	i.parsed[streamLabels] = lbs
+	return lbs, nil
+}
+
+func (i *dedupIterator) At() logproto.Entry {
+	return i.cur.Entry
+}
+
+func (i *dedupIterator) Labels() string {
+	return i.cur.labels
+}
+
+func (i *dedupIterator) StreamHash() uint64 {
+	return i.cur.streamHash
+}
+
+func (i *dedupIterator) Err() error {
+	return i.err
+}
+
+func (i *dedupIterator) Close() error {
+	return i.iter.Close()
+}
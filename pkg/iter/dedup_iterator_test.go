@@ -0,0 +1,126 @@
+package iter
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/v3/pkg/logproto"
+)
+
+type dedupResult struct {
+	labels string
+	ts     time.Time
+	line   string
+}
+
+func readDedupIterator(t *testing.T, it EntryIterator) []dedupResult {
+	t.Helper()
+	defer it.Close()
+
+	var res []dedupResult
+	for it.Next() {
+		res = append(res, dedupResult{labels: it.Labels(), ts: it.At().Timestamp, line: it.At().Line})
+	}
+	require.NoError(t, it.Err())
+	return res
+}
+
+func TestDedupIterator_Lines(t *testing.T) {
+	streams := []logproto.Stream{
+		{
+			Labels: `{app="a"}`,
+			Entries: []logproto.Entry{
+				{Timestamp: time.Unix(0, 1), Line: "x"},
+				{Timestamp: time.Unix(0, 2), Line: "y"},
+			},
+		},
+		{
+			Labels: `{app="b"}`,
+			Entries: []logproto.Entry{
+				{Timestamp: time.Unix(0, 1), Line: "x"},
+				{Timestamp: time.Unix(0, 3), Line: "y"},
+			},
+		},
+	}
+
+	for _, tc := range []struct {
+		name     string
+		window   time.Duration
+		expected []dedupResult
+	}{
+		{
+			name:   "same timestamp",
+			window: 0,
+			expected: []dedupResult{
+				{labels: `{__dup_count__="2", app="a"}`, ts: time.Unix(0, 1), line: "x"},
+				{labels: `{__dup_count__="1", app="a"}`, ts: time.Unix(0, 2), line: "y"},
+				{labels: `{__dup_count__="1", app="b"}`, ts: time.Unix(0, 3), line: "y"},
+			},
+		},
+		{
+			name:   "window",
+			window: time.Nanosecond,
+			expected: []dedupResult{
+				{labels: `{__dup_count__="2", app="a"}`, ts: time.Unix(0, 1), line: "x"},
+				{labels: `{__dup_count__="2", app="a"}`, ts: time.Unix(0, 2), line: "y"},
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			it := NewDedupIterator(NewStreamsIterator(streams, logproto.FORWARD), nil, tc.window)
+			require.Equal(t, tc.expected, readDedupIterator(t, it))
+		})
+	}
+}
+
+func TestDedupIterator_By(t *testing.T) {
+	streams := []logproto.Stream{
+		{
+			Labels: `{app="a", request_id="1"}`,
+			Entries: []logproto.Entry{
+				{Timestamp: time.Unix(0, 0), Line: "first"},
+				{Timestamp: time.Unix(5, 0), Line: "third"},
+			},
+		},
+		{
+			Labels: `{app="b", request_id="1"}`,
+			Entries: []logproto.Entry{
+				{Timestamp: time.Unix(2, 0), Line: "second"},
+			},
+		},
+		{
+			// Entries without the label are never duplicates.
+			Labels: `{app="c"}`,
+			Entries: []logproto.Entry{
+				{Timestamp: time.Unix(1, 0), Line: "first"},
+				{Timestamp: time.Unix(1, 0), Line: "first"},
+			},
+		},
+	}
+
+	t.Run("forward", func(t *testing.T) {
+		it := NewDedupIterator(NewStreamsIterator(streams, logproto.FORWARD), []string{"request_id"}, 3*time.Second)
+		require.Equal(t, []dedupResult{
+			{labels: `{__dup_count__="2", app="a", request_id="1"}`, ts: time.Unix(0, 0), line: "first"},
+			{labels: `{__dup_count__="1", app="c"}`, ts: time.Unix(1, 0), line: "first"},
+			{labels: `{__dup_count__="1", app="c"}`, ts: time.Unix(1, 0), line: "first"},
+			{labels: `{__dup_count__="1", app="a", request_id="1"}`, ts: time.Unix(5, 0), line: "third"},
+		}, readDedupIterator(t, it))
+	})
+
+	t.Run("backward", func(t *testing.T) {
+		its := make([]EntryIterator, 0, len(streams))
+		for _, s := range streams {
+			its = append(its, mustReverseStreamIterator(NewStreamIterator(s)))
+		}
+		it := NewDedupIterator(NewSortEntryIterator(its, logproto.BACKWARD), []string{"request_id"}, 3*time.Second)
+		require.Equal(t, []dedupResult{
+			{labels: `{__dup_count__="2", app="a", request_id="1"}`, ts: time.Unix(5, 0), line: "third"},
+			{labels: `{__dup_count__="1", app="c"}`, ts: time.Unix(1, 0), line: "first"},
+			{labels: `{__dup_count__="1", app="c"}`, ts: time.Unix(1, 0), line: "first"},
+			{labels: `{__dup_count__="1", app="a", request_id="1"}`, ts: time.Unix(0, 0), line: "first"},
+		}, readDedupIterator(t, it))
+	})
+}
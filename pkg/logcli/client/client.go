@@ -25,6 +25,7 @@ import (
 	"github.com/grafana/loki/v3/pkg/storage/stores/index/seriesvolume"
 	"github.com/grafana/loki/v3/pkg/util"
 	"github.com/grafana/loki/v3/pkg/util/build"
+	"github.com/grafana/loki/v3/pkg/util/httpreq"
 )
 
 const (
@@ -94,6 +95,9 @@ type DefaultClient struct {
 	BackoffConfig    BackoffConfig
 	Compression      bool
 	EnvironmentProxy bool
+	// CategorizeLabels requests the structured metadata and parsed labels of
+	// the entries to be returned apart from the stream labels.
+	CategorizeLabels bool
 }
 
 // Query uses the /api/v1/query endpoint to execute an instant query
@@ -648,6 +652,10 @@ func (c *DefaultClient) getHTTPRequestHeader() (http.Header, error) {
 		h.Set(HTTPQueryTags, c.QueryTags)
 	}
 
+	if c.CategorizeLabels {
+		h.Set(httpreq.LokiEncodingFlagsHeader, string(httpreq.FlagCategorizeLabels))
+	}
+
 	if (c.Username != "" || c.Password != "") && (len(c.BearerToken) > 0 || len(c.BearerTokenFile) > 0) {
 		return nil, fmt.Errorf("at most one of HTTP basic auth (username/password), bearer-token & bearer-token-file is allowed to be configured")
 	}
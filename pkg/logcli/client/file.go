@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"slices"
 	"sort"
 	"strings"
 	"time"
@@ -17,6 +18,7 @@ import (
 	"github.com/grafana/loki/v3/pkg/logproto"
 	"github.com/grafana/loki/v3/pkg/logql"
 	logqllog "github.com/grafana/loki/v3/pkg/logql/log"
+	"github.com/grafana/loki/v3/pkg/logql/syntax"
 	"github.com/grafana/loki/v3/pkg/util/log"
 	"github.com/grafana/loki/v3/pkg/util/marshal"
 	"github.com/grafana/loki/v3/pkg/util/validation"
@@ -38,11 +40,9 @@ var ErrNotSupported = errors.New("not supported")
 // FileClient is a type of LogCLI client that do LogQL on log lines from
 // the given file directly, instead get log lines from Loki servers.
 type FileClient struct {
-	r           io.ReadCloser
-	labels      []string
-	labelValues []string
-	orgID       string
-	engine      logql.Engine
+	series []labels.Labels
+	orgID  string
+	engine logql.Engine
 }
 
 // NewFileClient returns the new instance of FileClient for the given `io.ReadCloser`
@@ -51,13 +51,15 @@ func NewFileClient(r io.ReadCloser) *FileClient {
 		labels.Label{Name: defaultLabelKey, Value: defaultLabelValue},
 	)
 
-	eng := logql.NewEngine(logql.EngineOpts{}, &querier{r: r, labels: lbs}, &limiter{n: defaultMetricSeriesLimit}, log.Logger)
+	return newFileClient(&querier{r: r, labels: lbs}, []labels.Labels{lbs})
+}
+
+func newFileClient(q logql.Querier, series []labels.Labels) *FileClient {
+	eng := logql.NewEngine(logql.EngineOpts{}, q, &limiter{n: defaultMetricSeriesLimit}, log.Logger)
 	return &FileClient{
-		r:           r,
-		orgID:       defaultOrgID,
-		engine:      eng,
-		labels:      []string{defaultLabelKey},
-		labelValues: []string{defaultLabelValue},
+		orgID:  defaultOrgID,
+		engine: eng,
+		series: series,
 	}
 }
 
@@ -145,41 +147,77 @@ func (f *FileClient) QueryRange(queryStr string, limit int, start, end time.Time
 }
 
 func (f *FileClient) ListLabelNames(_ bool, _, _ time.Time) (*loghttp.LabelResponse, error) {
+	names := map[string]struct{}{}
+	for _, lbs := range f.series {
+		lbs.Range(func(l labels.Label) {
+			names[l.Name] = struct{}{}
+		})
+	}
+
 	return &loghttp.LabelResponse{
 		Status: loghttp.QueryStatusSuccess,
-		Data:   f.labels,
+		Data:   sortedKeys(names),
 	}, nil
 }
 
 func (f *FileClient) ListLabelValues(name string, _ bool, _, _ time.Time) (*loghttp.LabelResponse, error) {
-	i := sort.SearchStrings(f.labels, name)
-	if i < 0 {
-		return &loghttp.LabelResponse{}, nil
+	values := map[string]struct{}{}
+	for _, lbs := range f.series {
+		if v := lbs.Get(name); v != "" {
+			values[v] = struct{}{}
+		}
 	}
 
 	return &loghttp.LabelResponse{
 		Status: loghttp.QueryStatusSuccess,
-		Data:   []string{f.labelValues[i]},
+		Data:   sortedKeys(values),
 	}, nil
 }
 
-func (f *FileClient) Series(_ []string, _, _ time.Time, _ bool) (*loghttp.SeriesResponse, error) {
-	m := len(f.labels)
-	if m > len(f.labelValues) {
-		m = len(f.labelValues)
+func (f *FileClient) Series(matchers []string, _, _ time.Time, _ bool) (*loghttp.SeriesResponse, error) {
+	var selectors [][]*labels.Matcher
+	for _, m := range matchers {
+		ms, err := syntax.ParseMatchers(m, false)
+		if err != nil {
+			return nil, err
+		}
+		selectors = append(selectors, ms)
 	}
 
-	lbs := make(loghttp.LabelSet)
-	for i := 0; i < m; i++ {
-		lbs[f.labels[i]] = f.labelValues[i]
+	var data []loghttp.LabelSet
+	for _, lbs := range f.series {
+		if len(selectors) > 0 && !slices.ContainsFunc(selectors, func(ms []*labels.Matcher) bool {
+			return matchesAll(ms, lbs)
+		}) {
+			continue
+		}
+		data = append(data, loghttp.LabelSet(lbs.Map()))
 	}
 
 	return &loghttp.SeriesResponse{
 		Status: loghttp.QueryStatusSuccess,
-		Data:   []loghttp.LabelSet{lbs},
+		Data:   data,
 	}, nil
 }
 
+func sortedKeys(m map[string]struct{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func matchesAll(matchers []*labels.Matcher, lbs labels.Labels) bool {
+	for _, m := range matchers {
+		if !m.Matches(lbs.Get(m.Name)) {
+			return false
+		}
+	}
+	return true
+}
+
 func (f *FileClient) LiveTailQueryConn(_ string, _ time.Duration, _ int, _ time.Time, _ bool) (*websocket.Conn, error) {
 	return nil, fmt.Errorf("LiveTailQuery: %w", ErrNotSupported)
 }
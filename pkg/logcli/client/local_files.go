@@ -0,0 +1,436 @@
+package client
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cespare/xxhash/v2"
+	"github.com/golang/snappy"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/prometheus/prometheus/model/labels"
+
+	"github.com/grafana/loki/v3/pkg/chunkenc"
+	"github.com/grafana/loki/v3/pkg/dataobj"
+	"github.com/grafana/loki/v3/pkg/dataobj/sections/logs"
+	"github.com/grafana/loki/v3/pkg/dataobj/sections/streams"
+	"github.com/grafana/loki/v3/pkg/iter"
+	"github.com/grafana/loki/v3/pkg/logproto"
+	"github.com/grafana/loki/v3/pkg/logql"
+	logqllog "github.com/grafana/loki/v3/pkg/logql/log"
+	"github.com/grafana/loki/v3/pkg/storage/chunk"
+)
+
+// FilenameLabel is the label holding the path of the file the logs were read from.
+const FilenameLabel = "filename"
+
+var (
+	gzipMagic    = []byte{0x1f, 0x8b}
+	dataobjMagic = []byte("THOR")
+	// snappyMagic starts the snappy framed metadata of a chunk, after its
+	// 4 bytes length.
+	snappyMagic = []byte("\xff\x06\x00\x00sNaPpY")
+
+	castagnoliTable = crc32.MakeTable(crc32.Castagnoli)
+
+	// textTimestampLayouts are the layouts of the timestamps plain text lines
+	// can start with.
+	textTimestampLayouts = []string{time.RFC3339Nano, time.DateTime}
+)
+
+// LocalFilesConfig describes the local files to run queries on.
+type LocalFilesConfig struct {
+	// Paths are the paths or glob patterns of the files.
+	Paths []string
+	// Labels are added to the streams of all the files.
+	Labels map[string]string
+	// LabelRegex extracts labels from the path of the files with its named
+	// capture groups.
+	LabelRegex *regexp.Regexp
+}
+
+// NewLocalFilesClient returns a FileClient running LogQL queries on the
+// local files. Plain text and gzip files are read line by line, and Loki
+// chunks and data objects are decoded along with their stream labels.
+func NewLocalFilesClient(cfg LocalFilesConfig) (*FileClient, error) {
+	var paths []string
+	for _, pattern := range cfg.Paths {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid files pattern %q: %w", pattern, err)
+		}
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("no files match %q", pattern)
+		}
+		paths = append(paths, matches...)
+	}
+
+	s := &localStore{}
+	for _, path := range paths {
+		if err := s.load(path, fileLabels(cfg, path)); err != nil {
+			return nil, fmt.Errorf("reading %s: %w", path, err)
+		}
+	}
+
+	series := make([]labels.Labels, 0, len(s.streams))
+	for _, stream := range s.streams {
+		series = append(series, stream.labels)
+	}
+	slices.SortFunc(series, labels.Compare)
+	return newFileClient(s, series), nil
+}
+
+// fileLabels returns the labels given to the streams of the file. When they
+// have the same name, the labels set with Labels take precedence over the ones
+// extracted with LabelRegex, which take precedence over the filename.
+func fileLabels(cfg LocalFilesConfig, path string) labels.Labels {
+	b := labels.NewBuilder(labels.EmptyLabels())
+	b.Set(FilenameLabel, path)
+	if cfg.LabelRegex != nil {
+		if match := cfg.LabelRegex.FindStringSubmatch(path); match != nil {
+			for i, name := range cfg.LabelRegex.SubexpNames() {
+				if name != "" && match[i] != "" {
+					b.Set(name, match[i])
+				}
+			}
+		}
+	}
+	for name, value := range cfg.Labels {
+		b.Set(name, value)
+	}
+	return b.Labels()
+}
+
+type localStream struct {
+	labels  labels.Labels
+	entries []logproto.Entry
+}
+
+// localStore holds the entries of the local files in memory, and answers the
+// queries of the engine.
+type localStore struct {
+	streams []*localStream
+}
+
+func (s *localStore) load(path string, fileLbls labels.Labels) error {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case bytes.HasPrefix(b, gzipMagic):
+		r, err := gzip.NewReader(bytes.NewReader(b))
+		if err != nil {
+			return err
+		}
+		defer r.Close()
+		if b, err = io.ReadAll(r); err != nil {
+			return err
+		}
+		return s.loadText(path, b, fileLbls)
+	case bytes.HasPrefix(b, dataobjMagic):
+		return s.loadDataObj(b, fileLbls)
+	case len(b) > 4 && bytes.HasPrefix(b[4:], snappyMagic):
+		return s.loadChunk(b, fileLbls)
+	default:
+		return s.loadText(path, b, fileLbls)
+	}
+}
+
+// loadText reads the lines of a plain text file. Lines are timestamped by
+// their leading timestamp, else by the one of the previous line, else by the
+// modification time of the file.
+func (s *localStore) loadText(path string, b []byte, lbls labels.Labels) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	ts := info.ModTime()
+
+	stream := &localStream{labels: lbls}
+	for _, line := range strings.FieldsFunc(string(b), func(r rune) bool {
+		return r == '\n'
+	}) {
+		line = strings.TrimSuffix(line, "\r")
+		if t, ok := parseLineTimestamp(line); ok {
+			ts = t
+		}
+		stream.entries = append(stream.entries, logproto.Entry{Timestamp: ts, Line: line})
+	}
+	s.add(stream)
+	return nil
+}
+
+func parseLineTimestamp(line string) (time.Time, bool) {
+	for _, layout := range textTimestampLayouts {
+		// The layout can't be longer than the timestamp, except for the
+		// fractional seconds and the timezone of RFC3339.
+		n := len(layout) + 10
+		if n > len(line) {
+			n = len(line)
+		}
+		prefix := line[:n]
+		if layout == time.DateTime {
+			// The date and time are separated by a space.
+			if i := strings.IndexByte(prefix, ' '); i >= 0 {
+				if j := strings.IndexByte(prefix[i+1:], ' '); j >= 0 {
+					prefix = prefix[:i+1+j]
+				}
+			}
+		} else if i := strings.IndexByte(prefix, ' '); i >= 0 {
+			prefix = prefix[:i]
+		}
+		if t, err := time.Parse(layout, prefix); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// loadChunk decodes a chunk file as written by Loki to the object storage.
+func (s *localStore) loadChunk(b []byte, fileLbls labels.Labels) error {
+	// Decoding checks the key of the chunk against the one expected, which
+	// is read from its metadata beforehand.
+	var c chunk.Chunk
+	if err := jsoniter.ConfigFastest.NewDecoder(snappy.NewReader(bytes.NewReader(b[4:]))).Decode(&c); err != nil {
+		return fmt.Errorf("decoding chunk metadata: %w", err)
+	}
+	c.Checksum = crc32.Checksum(b, castagnoliTable)
+	if err := c.Decode(chunk.NewDecodeContext(), b); err != nil {
+		return fmt.Errorf("decoding chunk: %w", err)
+	}
+
+	facade, ok := c.Data.(*chunkenc.Facade)
+	if !ok {
+		return fmt.Errorf("unsupported chunk encoding %s", c.Encoding)
+	}
+	lbls := labels.NewBuilder(c.Metric).Del(labels.MetricName).Labels()
+
+	it, err := facade.LokiChunk().Iterator(context.Background(), time.Unix(0, 0), time.Unix(0, math.MaxInt64), logproto.FORWARD, logqllog.NewNoopPipeline().ForStream(lbls))
+	if err != nil {
+		return err
+	}
+	defer it.Close()
+
+	stream := &localStream{labels: mergeLabels(lbls, fileLbls)}
+	for it.Next() {
+		stream.entries = append(stream.entries, it.At())
+	}
+	if err := it.Err(); err != nil {
+		return err
+	}
+	s.add(stream)
+	return nil
+}
+
+// loadDataObj reads the streams and logs sections of a data object.
+func (s *localStore) loadDataObj(b []byte, fileLbls labels.Labels) error {
+	ctx := context.Background()
+	obj, err := dataobj.FromReaderAt(bytes.NewReader(b), int64(len(b)))
+	if err != nil {
+		return err
+	}
+
+	// Stream IDs are only unique within a tenant.
+	type streamKey struct {
+		tenant string
+		id     int64
+	}
+	byID := map[streamKey]*localStream{}
+	for _, section := range obj.Sections().Filter(streams.CheckSection) {
+		sec, err := streams.Open(ctx, section)
+		if err != nil {
+			return err
+		}
+		for res := range streams.IterSection(ctx, sec) {
+			if err := res.Err(); err != nil {
+				return err
+			}
+			stream := res.MustValue()
+			byID[streamKey{section.Tenant, stream.ID}] = &localStream{labels: mergeLabels(stream.Labels.Copy(), fileLbls)}
+		}
+	}
+
+	for _, section := range obj.Sections().Filter(logs.CheckSection) {
+		sec, err := logs.Open(ctx, section)
+		if err != nil {
+			return err
+		}
+		for res := range logs.IterSection(ctx, sec) {
+			if err := res.Err(); err != nil {
+				return err
+			}
+			record := res.MustValue()
+			stream, ok := byID[streamKey{section.Tenant, record.StreamID}]
+			if !ok {
+				return fmt.Errorf("unknown stream %d", record.StreamID)
+			}
+			stream.entries = append(stream.entries, logproto.Entry{
+				Timestamp:          record.Timestamp,
+				Line:               string(record.Line),
+				StructuredMetadata: logproto.FromLabelsToLabelAdapters(record.Metadata.Copy()),
+			})
+		}
+	}
+
+	for _, stream := range byID {
+		s.add(stream)
+	}
+	return nil
+}
+
+// mergeLabels adds the labels of the file which the stream doesn't have.
+func mergeLabels(lbls, fileLbls labels.Labels) labels.Labels {
+	b := labels.NewBuilder(lbls)
+	fileLbls.Range(func(l labels.Label) {
+		if !lbls.Has(l.Name) {
+			b.Set(l.Name, l.Value)
+		}
+	})
+	return b.Labels()
+}
+
+// add stores the stream, and merges it with the stream of the same labels.
+func (s *localStore) add(stream *localStream) {
+	if len(stream.entries) == 0 {
+		return
+	}
+	for _, existing := range s.streams {
+		if labels.Equal(existing.labels, stream.labels) {
+			existing.entries = append(existing.entries, stream.entries...)
+			sortEntries(existing.entries)
+			return
+		}
+	}
+	sortEntries(stream.entries)
+	s.streams = append(s.streams, stream)
+}
+
+func sortEntries(entries []logproto.Entry) {
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Timestamp.Before(entries[j].Timestamp)
+	})
+}
+
+// matching returns the streams matching all the matchers.
+func (s *localStore) matching(matchers []*labels.Matcher) []*localStream {
+	var matched []*localStream
+	for _, stream := range s.streams {
+		if matchesAll(matchers, stream.labels) {
+			matched = append(matched, stream)
+		}
+	}
+	return matched
+}
+
+func (s *localStore) SelectLogs(_ context.Context, params logql.SelectLogParams) (iter.EntryIterator, error) {
+	expr, err := params.LogSelector()
+	if err != nil {
+		return nil, fmt.Errorf("failed to extract selector for logs: %w", err)
+	}
+	pipeline, err := expr.Pipeline()
+	if err != nil {
+		return nil, fmt.Errorf("failed to extract pipeline for logs: %w", err)
+	}
+
+	byLabels := map[string]*logproto.Stream{}
+	for _, stream := range s.matching(expr.Matchers()) {
+		sp := pipeline.ForStream(stream.labels)
+		for _, e := range stream.entries {
+			if e.Timestamp.Before(params.Start) || !e.Timestamp.Before(params.End) {
+				continue
+			}
+			line, parsed, matches := sp.ProcessString(e.Timestamp.UnixNano(), e.Line, logproto.FromLabelAdaptersToLabels(e.StructuredMetadata))
+			if !matches {
+				continue
+			}
+			key := parsed.String()
+			out, ok := byLabels[key]
+			if !ok {
+				out = &logproto.Stream{Labels: key, Hash: parsed.Hash()}
+				byLabels[key] = out
+			}
+			out.Entries = append(out.Entries, logproto.Entry{
+				Timestamp:          e.Timestamp,
+				Line:               line,
+				StructuredMetadata: logproto.FromLabelsToLabelAdapters(parsed.StructuredMetadata()),
+				Parsed:             logproto.FromLabelsToLabelAdapters(parsed.Parsed()),
+			})
+		}
+	}
+
+	its := make([]iter.EntryIterator, 0, len(byLabels))
+	for _, stream := range byLabels {
+		it := iter.NewStreamIterator(*stream)
+		if params.Direction == logproto.BACKWARD {
+			if it, err = iter.NewEntryReversedIter(it); err != nil {
+				return nil, err
+			}
+		}
+		its = append(its, it)
+	}
+	return iter.NewSortEntryIterator(its, params.Direction), nil
+}
+
+func (s *localStore) SelectSamples(_ context.Context, params logql.SelectSampleParams) (iter.SampleIterator, error) {
+	selector, err := params.LogSelector()
+	if err != nil {
+		return nil, fmt.Errorf("failed to extract selector for samples: %w", err)
+	}
+	expr, err := params.Expr()
+	if err != nil {
+		return nil, err
+	}
+	extractors, err := expr.Extractors()
+	if err != nil {
+		return nil, fmt.Errorf("failed to extract sample extractors: %w", err)
+	}
+
+	bySeries := map[string]*logproto.Series{}
+	for _, stream := range s.matching(selector.Matchers()) {
+		for _, extractor := range extractors {
+			se := extractor.ForStream(stream.labels)
+			for _, e := range stream.entries {
+				if e.Timestamp.Before(params.Start) || !e.Timestamp.Before(params.End) {
+					continue
+				}
+				samples, ok := se.ProcessString(e.Timestamp.UnixNano(), e.Line, logproto.FromLabelAdaptersToLabels(e.StructuredMetadata))
+				if !ok {
+					continue
+				}
+				for _, sample := range samples {
+					key := sample.Labels.String()
+					series, ok := bySeries[key]
+					if !ok {
+						series = &logproto.Series{Labels: key, StreamHash: se.BaseLabels().Hash()}
+						bySeries[key] = series
+					}
+					series.Samples = append(series.Samples, logproto.Sample{
+						Timestamp: e.Timestamp.UnixNano(),
+						Value:     sample.Value,
+						Hash:      xxhash.Sum64String(e.Line),
+					})
+				}
+			}
+		}
+	}
+
+	series := make([]logproto.Series, 0, len(bySeries))
+	for _, s := range bySeries {
+		sort.Sort(s)
+		series = append(series, *s)
+	}
+	return iter.NewMultiSeriesIterator(series), nil
+}
@@ -0,0 +1,241 @@
+package client
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/v3/pkg/chunkenc"
+	"github.com/grafana/loki/v3/pkg/compression"
+	"github.com/grafana/loki/v3/pkg/dataobj/consumer/logsobj"
+	"github.com/grafana/loki/v3/pkg/loghttp"
+	"github.com/grafana/loki/v3/pkg/logproto"
+	"github.com/grafana/loki/v3/pkg/storage/chunk"
+)
+
+func writeFile(t *testing.T, path string, b []byte) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+	require.NoError(t, os.WriteFile(path, b, 0o644))
+}
+
+func gzipped(t *testing.T, s string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	_, err := w.Write([]byte(s))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	return buf.Bytes()
+}
+
+func streamLines(t *testing.T, resp *loghttp.QueryResponse) map[string][]string {
+	t.Helper()
+	streams, ok := resp.Data.Result.(loghttp.Streams)
+	require.True(t, ok, "unexpected result type %s", resp.Data.ResultType)
+	lines := map[string][]string{}
+	for _, s := range streams {
+		for _, e := range s.Entries {
+			lines[s.Labels.String()] = append(lines[s.Labels.String()], e.Line)
+		}
+	}
+	return lines
+}
+
+func TestLocalFilesClient_TextFiles(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "host-a", "app.log"), []byte(
+		"2024-05-01T10:00:00Z level=info msg=started\n"+
+			"2024-05-01T10:00:01Z level=error msg=failed\n"+
+			"  continued stack trace\n"))
+	writeFile(t, filepath.Join(dir, "host-b", "app.log.gz"), gzipped(t,
+		"2024-05-01 10:00:02 level=error msg=timeout\n"+
+			"2024-05-01 10:00:03 level=info msg=retried\n"))
+
+	c, err := NewLocalFilesClient(LocalFilesConfig{
+		Paths:      []string{filepath.Join(dir, "*", "app.log*")},
+		Labels:     map[string]string{"env": "prod"},
+		LabelRegex: regexp.MustCompile(`(?P<host>host-[a-z])/`),
+	})
+	require.NoError(t, err)
+
+	start := time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)
+	end := start.Add(2 * time.Hour)
+
+	resp, err := c.QueryRange(`{env="prod"} |= "error"`, 10, start, end, logproto.FORWARD, 0, 0, true)
+	require.NoError(t, err)
+	require.Equal(t, map[string][]string{
+		`{env="prod", filename="` + filepath.Join(dir, "host-a", "app.log") + `", host="host-a"}`:    {"2024-05-01T10:00:01Z level=error msg=failed"},
+		`{env="prod", filename="` + filepath.Join(dir, "host-b", "app.log.gz") + `", host="host-b"}`: {"2024-05-01 10:00:02 level=error msg=timeout"},
+	}, streamLines(t, resp))
+
+	// Lines without a timestamp have the one of the previous line.
+	resp, err = c.QueryRange(`{host="host-a"} |= "stack"`, 10, start.Add(time.Hour+time.Second), end, logproto.FORWARD, 0, 0, true)
+	require.NoError(t, err)
+	require.Len(t, streamLines(t, resp), 1)
+
+	// Metric queries are evaluated by the engine.
+	resp, err = c.Query(`sum by (host) (count_over_time({env="prod"} | logfmt | level="error" [1h]))`, 10, end, logproto.FORWARD, true)
+	require.NoError(t, err)
+	vector, ok := resp.Data.Result.(loghttp.Vector)
+	require.True(t, ok)
+	require.Len(t, vector, 2)
+	for _, s := range vector {
+		require.Equal(t, model.SampleValue(1), s.Value)
+	}
+
+	names, err := c.ListLabelNames(true, start, end)
+	require.NoError(t, err)
+	require.Equal(t, []string{"env", "filename", "host"}, names.Data)
+
+	values, err := c.ListLabelValues("host", true, start, end)
+	require.NoError(t, err)
+	require.Equal(t, []string{"host-a", "host-b"}, values.Data)
+
+	series, err := c.Series([]string{`{host="host-b"}`}, start, end, true)
+	require.NoError(t, err)
+	require.Len(t, series.Data, 1)
+	require.Equal(t, "prod", series.Data[0]["env"])
+}
+
+func TestLocalFilesClient_NoMatchingFiles(t *testing.T) {
+	_, err := NewLocalFilesClient(LocalFilesConfig{Paths: []string{filepath.Join(t.TempDir(), "*.log")}})
+	require.ErrorContains(t, err, "no files match")
+}
+
+func TestFileLabels(t *testing.T) {
+	cfg := LocalFilesConfig{
+		Labels:     map[string]string{"env": "prod"},
+		LabelRegex: regexp.MustCompile(`(?P<env>[a-z]+)/(?P<filename>[a-z]+)\.log`),
+	}
+	// Labels take precedence over the regex groups, which take precedence
+	// over the filename.
+	require.Equal(t, labels.FromStrings("env", "prod", "filename", "app"), fileLabels(cfg, "dev/app.log"))
+
+	cfg.Labels = map[string]string{"filename": "custom"}
+	require.Equal(t, labels.FromStrings("env", "dev", "filename", "custom"), fileLabels(cfg, "dev/app.log"))
+
+	// Without a match, only the labels and the filename are set.
+	require.Equal(t, labels.FromStrings("filename", "custom"), fileLabels(cfg, "app.txt"))
+	cfg.Labels = nil
+	require.Equal(t, labels.FromStrings("filename", "app.txt"), fileLabels(cfg, "app.txt"))
+}
+
+func TestLocalFilesClient_Chunk(t *testing.T) {
+	ts := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	mem := chunkenc.NewMemChunk(chunkenc.ChunkFormatV4, compression.Snappy, chunkenc.UnorderedWithStructuredMetadataHeadBlockFmt, 256*1024, 0)
+	for i, line := range []string{"first", "second", "third"} {
+		_, err := mem.Append(&logproto.Entry{
+			Timestamp:          ts.Add(time.Duration(i) * time.Second),
+			Line:               line,
+			StructuredMetadata: logproto.FromLabelsToLabelAdapters(labels.FromStrings("trace_id", line)),
+		})
+		require.NoError(t, err)
+	}
+	require.NoError(t, mem.Close())
+
+	from, through := mem.Bounds()
+	c := chunk.NewChunk("fake", model.Fingerprint(1), labels.FromStrings(labels.MetricName, "logs", "app", "api"),
+		chunkenc.NewFacade(mem, 0, 0), model.TimeFromUnixNano(from.UnixNano()), model.TimeFromUnixNano(through.UnixNano()))
+	require.NoError(t, c.Encode())
+	b, err := c.Encoded()
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "chunk")
+	writeFile(t, path, b)
+
+	client, err := NewLocalFilesClient(LocalFilesConfig{Paths: []string{path}})
+	require.NoError(t, err)
+
+	resp, err := client.QueryRange(`{app="api"} | trace_id!="second"`, 10, ts, ts.Add(time.Minute), logproto.BACKWARD, 0, 0, true)
+	require.NoError(t, err)
+	// As with Loki, the structured metadata is part of the labels of the result.
+	require.Equal(t, map[string][]string{
+		`{app="api", filename="` + path + `", trace_id="first"}`: {"first"},
+		`{app="api", filename="` + path + `", trace_id="third"}`: {"third"},
+	}, streamLines(t, resp))
+}
+
+func TestLocalFilesClient_DataObj(t *testing.T) {
+	builder, err := logsobj.NewBuilder(logsobj.BuilderConfig{
+		TargetPageSize:          2048,
+		MaxPageRows:             10,
+		TargetObjectSize:        1 << 22,
+		TargetSectionSize:       1 << 22,
+		BufferSize:              2048 * 8,
+		SectionStripeMergeLimit: 2,
+	}, nil)
+	require.NoError(t, err)
+
+	ts := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	for _, stream := range []logproto.Stream{
+		{Labels: `{app="api"}`, Entries: []logproto.Entry{
+			{Timestamp: ts, Line: "GET /users 200", StructuredMetadata: logproto.FromLabelsToLabelAdapters(labels.FromStrings("trace_id", "abc"))},
+			{Timestamp: ts.Add(time.Second), Line: "GET /users 500"},
+		}},
+		{Labels: `{app="web"}`, Entries: []logproto.Entry{
+			{Timestamp: ts, Line: "GET / 200"},
+		}},
+	} {
+		require.NoError(t, builder.Append("tenant", stream))
+	}
+	obj, closer, err := builder.Flush()
+	require.NoError(t, err)
+	defer closer.Close()
+
+	r, err := obj.Reader(t.Context())
+	require.NoError(t, err)
+	defer r.Close()
+	b, err := io.ReadAll(r)
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "object")
+	writeFile(t, path, b)
+
+	client, err := NewLocalFilesClient(LocalFilesConfig{Paths: []string{path}})
+	require.NoError(t, err)
+
+	resp, err := client.QueryRange(`{filename=~".+"} | trace_id="abc"`, 10, ts, ts.Add(time.Minute), logproto.FORWARD, 0, 0, true)
+	require.NoError(t, err)
+	require.Equal(t, map[string][]string{
+		`{app="api", filename="` + path + `", trace_id="abc"}`: {"GET /users 200"},
+	}, streamLines(t, resp))
+
+	resp, err = client.Query(`sum by (app) (count_over_time({filename=~".+"} |= "GET" [1m]))`, 10, ts.Add(30*time.Second), logproto.FORWARD, true)
+	require.NoError(t, err)
+	vector, ok := resp.Data.Result.(loghttp.Vector)
+	require.True(t, ok)
+	counts := map[string]model.SampleValue{}
+	for _, s := range vector {
+		counts[string(s.Metric["app"])] = s.Value
+	}
+	require.Equal(t, map[string]model.SampleValue{"api": 2, "web": 1}, counts)
+}
+
+func TestParseLineTimestamp(t *testing.T) {
+	for line, expected := range map[string]time.Time{
+		"2024-05-01T10:00:00.5Z msg":       time.Date(2024, 5, 1, 10, 0, 0, 5e8, time.UTC),
+		"2024-05-01T10:00:00+02:00 msg":    time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC),
+		"2024-05-01 10:00:00 msg":          time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC),
+		"2024-05-01 10:00:00.123 msg=done": time.Date(2024, 5, 1, 10, 0, 0, 123e6, time.UTC),
+		"2024-05-01T10:00:00Z":             time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC),
+	} {
+		ts, ok := parseLineTimestamp(line)
+		require.True(t, ok, line)
+		require.True(t, expected.Equal(ts), "%s: %s", line, ts)
+	}
+
+	for _, line := range []string{"", "msg=started", "10:00:00 msg", "2024-05-01"} {
+		_, ok := parseLineTimestamp(line)
+		require.False(t, ok, line)
+	}
+}
@@ -0,0 +1,253 @@
+package explore
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/grafana/loki/v3/pkg/logcli/client"
+	"github.com/grafana/loki/v3/pkg/logcli/query"
+	"github.com/grafana/loki/v3/pkg/logcli/volume"
+	"github.com/grafana/loki/v3/pkg/loghttp"
+	"github.com/grafana/loki/v3/pkg/logproto"
+	"github.com/grafana/loki/v3/pkg/logql/syntax"
+)
+
+// volumeBuckets is the number of bars of the volume histogram.
+const volumeBuckets = 60
+
+// Explore contains the settings of the interactive explorer.
+type Explore struct {
+	// Matchers are the initial label matchers, eg `app="web"`.
+	Matchers []string
+	Since    time.Duration
+	Limit    int
+	DelayFor time.Duration
+}
+
+// Run starts the explorer in the terminal until it's exited.
+func (e *Explore) Run(c client.Client) error {
+	// Logs written by the client would break the terminal UI.
+	w := log.Writer()
+	log.SetOutput(io.Discard)
+	defer log.SetOutput(w)
+
+	p := tea.NewProgram(newModel(e, c), tea.WithAltScreen())
+	_, err := p.Run()
+	return err
+}
+
+// logEntry is a log line along with the labels of its stream.
+type logEntry struct {
+	labels loghttp.LabelSet
+	entry  loghttp.Entry
+}
+
+// Messages sent by the commands querying Loki.
+type (
+	labelNamesMsg  []string
+	labelValuesMsg struct {
+		name   string
+		values []string
+	}
+	seriesMsg int
+	volumeMsg []float64
+	logsMsg   []logEntry
+	tailMsg   struct {
+		entry logEntry
+		tail  *tailStream
+	}
+	tailStoppedMsg struct {
+		tail *tailStream
+		err  error
+	}
+	errMsg struct{ err error }
+)
+
+// selector returns the stream selector matching all the matchers.
+func selector(matchers []string) string {
+	return "{" + strings.Join(matchers, ", ") + "}"
+}
+
+// buildQuery returns the log query of the stream selector and line filters.
+func buildQuery(matchers, filters []string) string {
+	if len(filters) == 0 {
+		return selector(matchers)
+	}
+	return selector(matchers) + " " + strings.Join(filters, " ")
+}
+
+// matcher returns the equality matcher of the label value.
+func matcher(name, value string) string {
+	return name + "=" + strconv.Quote(value)
+}
+
+// parseFilter converts the input of the filter prompt into a pipeline stage.
+// Plain text is a line contains filter, and is negated when it starts with
+// "!". Input starting with "|" is used as is.
+func parseFilter(matchers []string, input string) (string, error) {
+	input = strings.TrimSpace(input)
+	var stage string
+	switch {
+	case input == "":
+		return "", fmt.Errorf("empty filter")
+	case strings.HasPrefix(input, "|"):
+		stage = input
+	case strings.HasPrefix(input, "!"):
+		stage = "!= " + strconv.Quote(input[1:])
+	default:
+		stage = "|= " + strconv.Quote(input)
+	}
+	if _, err := syntax.ParseLogSelector(buildQuery(matchers, []string{stage}), true); err != nil {
+		return "", err
+	}
+	return stage, nil
+}
+
+func fetchLabelNames(c client.Client, since time.Duration) tea.Cmd {
+	return func() tea.Msg {
+		end := time.Now()
+		resp, err := c.ListLabelNames(true, end.Add(-since), end)
+		if err != nil {
+			return errMsg{fmt.Errorf("listing labels: %w", err)}
+		}
+		return labelNamesMsg(resp.Data)
+	}
+}
+
+func fetchLabelValues(c client.Client, since time.Duration, name string) tea.Cmd {
+	return func() tea.Msg {
+		end := time.Now()
+		resp, err := c.ListLabelValues(name, true, end.Add(-since), end)
+		if err != nil {
+			return errMsg{fmt.Errorf("listing values of %s: %w", name, err)}
+		}
+		return labelValuesMsg{name: name, values: resp.Data}
+	}
+}
+
+func fetchSeries(c client.Client, since time.Duration, matchers []string) tea.Cmd {
+	return func() tea.Msg {
+		end := time.Now()
+		resp, err := c.Series([]string{selector(matchers)}, end.Add(-since), end, true)
+		if err != nil {
+			return errMsg{fmt.Errorf("listing series: %w", err)}
+		}
+		return seriesMsg(len(resp.Data))
+	}
+}
+
+func fetchVolume(c client.Client, since time.Duration, matchers []string) tea.Cmd {
+	return func() tea.Msg {
+		end := time.Now()
+		start := end.Add(-since)
+		step := since / volumeBuckets
+		if step < time.Second {
+			step = time.Second
+		}
+		resp, err := c.GetVolumeRange(&volume.Query{
+			QueryString: selector(matchers),
+			Start:       start,
+			End:         end,
+			Step:        step,
+			Quiet:       true,
+			Limit:       100,
+		})
+		if err != nil {
+			return errMsg{fmt.Errorf("querying volume: %w", err)}
+		}
+		return volumeMsg(volumeHistogram(resp.Data.Result, start, step))
+	}
+}
+
+// volumeHistogram sums the volume of all the series of the result by step.
+func volumeHistogram(result loghttp.ResultValue, start time.Time, step time.Duration) []float64 {
+	buckets := make([]float64, volumeBuckets)
+	matrix, ok := result.(loghttp.Matrix)
+	if !ok {
+		return buckets
+	}
+	for _, series := range matrix {
+		for _, sample := range series.Values {
+			i := int(sample.Timestamp.Time().Sub(start) / step)
+			if i < 0 || i >= len(buckets) {
+				continue
+			}
+			buckets[i] += float64(sample.Value)
+		}
+	}
+	return buckets
+}
+
+func fetchLogs(c client.Client, since time.Duration, limit int, queryString string) tea.Cmd {
+	return func() tea.Msg {
+		end := time.Now()
+		resp, err := c.QueryRange(queryString, limit, end.Add(-since), end, logproto.BACKWARD, 0, 0, true)
+		if err != nil {
+			return errMsg{fmt.Errorf("querying logs: %w", err)}
+		}
+		streams, ok := resp.Data.Result.(loghttp.Streams)
+		if !ok {
+			return errMsg{fmt.Errorf("unexpected result type %s", resp.Data.ResultType)}
+		}
+
+		var entries []logEntry
+		for _, s := range streams {
+			for _, e := range s.Entries {
+				entries = append(entries, logEntry{labels: s.Labels, entry: e})
+			}
+		}
+		sort.SliceStable(entries, func(i, j int) bool {
+			return entries[i].entry.Timestamp.After(entries[j].entry.Timestamp)
+		})
+		if len(entries) > limit {
+			entries = entries[:limit]
+		}
+		return logsMsg(entries)
+	}
+}
+
+// tailStream holds the entries received by a tail.
+type tailStream struct {
+	entries chan logEntry
+	// err is set before entries is closed.
+	err error
+}
+
+// startTail tails the query until stop is closed. The entries are received
+// one at a time by waitForTail.
+func startTail(c client.Client, delayFor time.Duration, limit int, queryString string, stop <-chan struct{}) *tailStream {
+	t := &tailStream{entries: make(chan logEntry)}
+	go func() {
+		defer close(t.entries)
+		q := &query.Query{
+			QueryString: queryString,
+			Start:       time.Now(),
+			Limit:       limit,
+			Quiet:       true,
+		}
+		t.err = q.TailEntries(delayFor, c, stop, func(labels loghttp.LabelSet, entry loghttp.Entry) {
+			select {
+			case t.entries <- logEntry{labels: labels, entry: entry}:
+			case <-stop:
+			}
+		})
+	}()
+	return t
+}
+
+func waitForTail(t *tailStream) tea.Cmd {
+	return func() tea.Msg {
+		entry, ok := <-t.entries
+		if !ok {
+			return tailStoppedMsg{tail: t, err: t.err}
+		}
+		return tailMsg{entry: entry, tail: t}
+	}
+}
@@ -0,0 +1,233 @@
+package explore
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/gorilla/websocket"
+	prom_model "github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/v3/pkg/logcli/client"
+	"github.com/grafana/loki/v3/pkg/logcli/volume"
+	"github.com/grafana/loki/v3/pkg/loghttp"
+	"github.com/grafana/loki/v3/pkg/logproto"
+)
+
+// fakeClient answers the queries of the explorer and records the log
+// queries it receives.
+type fakeClient struct {
+	client.Client
+
+	mtx     sync.Mutex
+	queries []string
+	tailURL string
+}
+
+func (c *fakeClient) ListLabelNames(_ bool, _, _ time.Time) (*loghttp.LabelResponse, error) {
+	return &loghttp.LabelResponse{Data: []string{"app", "env"}}, nil
+}
+
+func (c *fakeClient) ListLabelValues(name string, _ bool, _, _ time.Time) (*loghttp.LabelResponse, error) {
+	if name != "app" {
+		return nil, fmt.Errorf("unexpected label %s", name)
+	}
+	return &loghttp.LabelResponse{Data: []string{"api", "web"}}, nil
+}
+
+func (c *fakeClient) Series(_ []string, _, _ time.Time, _ bool) (*loghttp.SeriesResponse, error) {
+	return &loghttp.SeriesResponse{Data: []loghttp.LabelSet{{"app": "web", "env": "prod"}, {"app": "web", "env": "dev"}}}, nil
+}
+
+func (c *fakeClient) GetVolumeRange(q *volume.Query) (*loghttp.QueryResponse, error) {
+	return &loghttp.QueryResponse{Data: loghttp.QueryResponseData{
+		ResultType: loghttp.ResultTypeMatrix,
+		Result: loghttp.Matrix{{
+			Values: []prom_model.SamplePair{{Timestamp: prom_model.TimeFromUnixNano(q.Start.Add(q.Step).UnixNano()), Value: 2048}},
+		}},
+	}}, nil
+}
+
+func (c *fakeClient) QueryRange(queryStr string, _ int, _, _ time.Time, _ logproto.Direction, _, _ time.Duration, _ bool) (*loghttp.QueryResponse, error) {
+	c.mtx.Lock()
+	c.queries = append(c.queries, queryStr)
+	c.mtx.Unlock()
+	return &loghttp.QueryResponse{Data: loghttp.QueryResponseData{
+		ResultType: loghttp.ResultTypeStream,
+		Result: loghttp.Streams{{
+			Labels: loghttp.LabelSet{"app": "web"},
+			Entries: []loghttp.Entry{
+				{Timestamp: time.Unix(1, 0), Line: "older"},
+				{Timestamp: time.Unix(2, 0), Line: "newer", StructuredMetadata: labels.FromStrings("trace_id", "abc")},
+			},
+		}},
+	}}, nil
+}
+
+func (c *fakeClient) LiveTailQueryConn(_ string, _ time.Duration, _ int, _ time.Time, _ bool) (*websocket.Conn, error) {
+	conn, _, err := websocket.DefaultDialer.Dial(c.tailURL, nil)
+	return conn, err
+}
+
+// run executes the command and the commands it returns, and sends their
+// messages to the model.
+func run(t *testing.T, m *model, cmd tea.Cmd) {
+	t.Helper()
+	if cmd == nil {
+		return
+	}
+	switch msg := cmd().(type) {
+	case tea.BatchMsg:
+		for _, c := range msg {
+			run(t, m, c)
+		}
+	case nil:
+	default:
+		_, next := m.Update(msg)
+		run(t, m, next)
+	}
+}
+
+func press(t *testing.T, m *model, keys ...string) {
+	t.Helper()
+	for _, k := range keys {
+		var msg tea.KeyMsg
+		switch k {
+		case "enter":
+			msg = tea.KeyMsg{Type: tea.KeyEnter}
+		case "down":
+			msg = tea.KeyMsg{Type: tea.KeyDown}
+		default:
+			msg = tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)}
+		}
+		_, cmd := m.Update(msg)
+		// The blinking of the filter prompt cursor never ends.
+		if k != "f" {
+			run(t, m, cmd)
+		}
+	}
+}
+
+func TestParseFilter(t *testing.T) {
+	matchers := []string{`app="web"`}
+	for input, expected := range map[string]string{
+		"error":         `|= "error"`,
+		` "quoted" `:    `|= "\"quoted\""`,
+		"!debug":        `!= "debug"`,
+		"| json":        "| json",
+		`| level="err"`: `| level="err"`,
+	} {
+		stage, err := parseFilter(matchers, input)
+		require.NoError(t, err, input)
+		require.Equal(t, expected, stage, input)
+	}
+
+	for _, input := range []string{"", "| unknown_stage", `|= "unclosed`} {
+		_, err := parseFilter(matchers, input)
+		require.Error(t, err, input)
+	}
+}
+
+func TestVolumeHistogram(t *testing.T) {
+	start := time.Unix(0, 0)
+	buckets := volumeHistogram(loghttp.Matrix{
+		{Values: []prom_model.SamplePair{{Timestamp: 0, Value: 1}, {Timestamp: 60000, Value: 2}}},
+		{Values: []prom_model.SamplePair{{Timestamp: 60000, Value: 3}, {Timestamp: prom_model.Time(volumeBuckets * 60000), Value: 4}}},
+	}, start, time.Minute)
+	require.Len(t, buckets, volumeBuckets)
+	require.Equal(t, float64(1), buckets[0])
+	require.Equal(t, float64(5), buckets[1])
+}
+
+func TestModel(t *testing.T) {
+	c := &fakeClient{}
+	m := newModel(&Explore{Since: time.Hour, Limit: 10}, c)
+	_, _ = m.Update(tea.WindowSizeMsg{Width: 120, Height: 20})
+	run(t, m, m.Init())
+	require.Equal(t, labelsPane, m.pane)
+	require.Len(t, m.list.Items(), 2)
+	require.Contains(t, m.View(), "pick a label and a value")
+
+	// Pick app="web".
+	press(t, m, "enter")
+	require.Equal(t, valuesPane, m.pane)
+	require.Equal(t, "app", m.label)
+	press(t, m, "down", "enter")
+	require.Equal(t, logsPane, m.pane)
+	require.Equal(t, []string{`app="web"`}, m.matchers)
+	require.Equal(t, 2, m.streams)
+	require.Len(t, m.entries, 2)
+	require.Equal(t, "newer", m.entries[0].entry.Line)
+	view := m.View()
+	require.Contains(t, view, `Query: {app="web"}`)
+	require.Contains(t, view, "streams: 2")
+	require.Contains(t, view, "2.0 kB in the last 1h0m0s")
+
+	// Add a line filter.
+	press(t, m, "f", "e", "r", "r", "enter")
+	require.Equal(t, []string{`|= "err"`}, m.filters)
+	require.Equal(t, `{app="web"} |= "err"`, c.queries[len(c.queries)-1])
+
+	// Expand the structured metadata of the selected line.
+	press(t, m, "enter")
+	require.Contains(t, m.View(), `structured metadata: {trace_id="abc"}`)
+
+	// Undo the filter, then the matcher.
+	press(t, m, "u")
+	require.Empty(t, m.filters)
+	require.Equal(t, `{app="web"}`, c.queries[len(c.queries)-1])
+	press(t, m, "u")
+	require.Empty(t, m.matchers)
+	require.Empty(t, m.entries)
+}
+
+func TestModel_Tail(t *testing.T) {
+	var upgrader websocket.Upgrader
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		_ = conn.WriteMessage(websocket.TextMessage, []byte(`{"streams":[{"stream":{"app":"web"},"values":[["3000000000","tailed"]]}]}`))
+		// Wait for the client to close the connection.
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}))
+	defer srv.Close()
+
+	c := &fakeClient{tailURL: "ws" + strings.TrimPrefix(srv.URL, "http")}
+	m := newModel(&Explore{Matchers: []string{`app="web"`}, Since: time.Hour, Limit: 10}, c)
+	run(t, m, m.Init())
+	require.Len(t, m.entries, 2)
+
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("t")})
+	require.NotNil(t, m.tail)
+	require.Empty(t, m.entries)
+
+	// Receive the tailed entry, and wait for the next one.
+	_, cmd = m.Update(cmd())
+	require.Len(t, m.entries, 1)
+	require.Equal(t, "tailed", m.entries[0].entry.Line)
+	require.Contains(t, m.View(), "tailing")
+
+	tail := m.tail
+	press(t, m, "t")
+	require.Nil(t, m.tail)
+	// The stopped tail's messages are ignored.
+	_, _ = m.Update(cmd())
+	require.Nil(t, m.tail)
+	require.Len(t, m.entries, 1)
+	_, ok := <-tail.entries
+	require.False(t, ok)
+}
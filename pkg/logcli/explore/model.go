@@ -0,0 +1,490 @@
+package explore
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/dustin/go-humanize"
+
+	"github.com/grafana/loki/v3/pkg/logcli/client"
+)
+
+// pane identifies the part of the explorer having the focus.
+type pane int
+
+const (
+	labelsPane pane = iota
+	valuesPane
+	logsPane
+)
+
+var (
+	headerStyle   = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("12"))
+	volumeStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("62"))
+	selectedStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("12")).Bold(true)
+	detailsStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("243")).PaddingLeft(4)
+	helpStyle     = lipgloss.NewStyle().Foreground(lipgloss.Color("241"))
+	errorStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("9"))
+	tailingStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("42"))
+)
+
+var volumeBars = []rune(" ▁▂▃▄▅▆▇█")
+
+// item is a label name or value of the list.
+type item string
+
+func (i item) FilterValue() string { return string(i) }
+
+type itemDelegate struct{}
+
+func (itemDelegate) Height() int                             { return 1 }
+func (itemDelegate) Spacing() int                            { return 0 }
+func (itemDelegate) Update(_ tea.Msg, _ *list.Model) tea.Cmd { return nil }
+
+func (itemDelegate) Render(w io.Writer, m list.Model, index int, listItem list.Item) {
+	str := "  " + string(listItem.(item))
+	if index == m.Index() {
+		str = selectedStyle.Render("> " + string(listItem.(item)))
+	}
+	fmt.Fprint(w, str)
+}
+
+type model struct {
+	client   client.Client
+	since    time.Duration
+	limit    int
+	delayFor time.Duration
+
+	matchers []string
+	filters  []string
+
+	pane   pane
+	list   list.Model
+	label  string
+	filter textinput.Model
+
+	streams  int
+	volume   []float64
+	entries  []logEntry
+	cursor   int
+	expanded bool
+
+	tail     *tailStream
+	stopTail chan struct{}
+
+	err    error
+	width  int
+	height int
+}
+
+func newModel(e *Explore, c client.Client) *model {
+	l := list.New(nil, itemDelegate{}, 0, 0)
+	l.SetShowHelp(false)
+	l.SetShowStatusBar(false)
+	l.DisableQuitKeybindings()
+
+	filter := textinput.New()
+	filter.Prompt = "filter> "
+	filter.Placeholder = `text, !text or a pipeline stage like | json`
+
+	m := &model{
+		client:   c,
+		since:    e.Since,
+		limit:    e.Limit,
+		delayFor: e.DelayFor,
+		matchers: append([]string(nil), e.Matchers...),
+		list:     l,
+		filter:   filter,
+	}
+	if len(m.matchers) > 0 {
+		m.pane = logsPane
+	}
+	m.setListTitle()
+	return m
+}
+
+func (m *model) Init() tea.Cmd {
+	return tea.Batch(fetchLabelNames(m.client, m.since), m.refresh())
+}
+
+// query returns the log query built from the matchers and filters.
+func (m *model) query() string {
+	return buildQuery(m.matchers, m.filters)
+}
+
+// refresh queries the series, volume and logs of the current query, and
+// restarts the tail if it's running.
+func (m *model) refresh() tea.Cmd {
+	if len(m.matchers) == 0 {
+		m.streams, m.volume, m.entries = 0, nil, nil
+		return nil
+	}
+	cmds := []tea.Cmd{
+		fetchSeries(m.client, m.since, m.matchers),
+		fetchVolume(m.client, m.since, m.matchers),
+	}
+	if m.tail != nil {
+		m.stopTailing()
+		m.entries = nil
+		cmds = append(cmds, m.startTailing())
+	} else {
+		cmds = append(cmds, fetchLogs(m.client, m.since, m.limit, m.query()))
+	}
+	return tea.Batch(cmds...)
+}
+
+func (m *model) startTailing() tea.Cmd {
+	m.stopTail = make(chan struct{})
+	m.tail = startTail(m.client, m.delayFor, m.limit, m.query(), m.stopTail)
+	return waitForTail(m.tail)
+}
+
+func (m *model) stopTailing() {
+	if m.tail == nil {
+		return
+	}
+	close(m.stopTail)
+	m.tail, m.stopTail = nil, nil
+}
+
+func (m *model) setListTitle() {
+	if m.pane == valuesPane {
+		m.list.Title = "Values of " + m.label
+		return
+	}
+	m.list.Title = "Labels"
+}
+
+func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+		m.list.SetSize(msg.Width, m.paneHeight())
+		m.filter.Width = msg.Width - len(m.filter.Prompt) - 1
+		return m, nil
+
+	case labelNamesMsg:
+		if m.pane == labelsPane {
+			m.setItems(msg)
+		}
+		return m, nil
+
+	case labelValuesMsg:
+		if m.pane == valuesPane && m.label == msg.name {
+			m.setItems(msg.values)
+		}
+		return m, nil
+
+	case seriesMsg:
+		m.streams = int(msg)
+		return m, nil
+
+	case volumeMsg:
+		m.volume = msg
+		return m, nil
+
+	case logsMsg:
+		m.entries = msg
+		m.cursor, m.expanded = 0, false
+		return m, nil
+
+	case tailMsg:
+		if msg.tail != m.tail {
+			return m, nil
+		}
+		m.entries = append([]logEntry{msg.entry}, m.entries...)
+		if len(m.entries) > m.limit {
+			m.entries = m.entries[:m.limit]
+		}
+		if m.cursor > 0 {
+			// Keep the selected entry when new ones are added on top.
+			m.cursor = min(m.cursor+1, len(m.entries)-1)
+		}
+		return m, waitForTail(m.tail)
+
+	case tailStoppedMsg:
+		if msg.tail == m.tail {
+			m.tail, m.stopTail = nil, nil
+			m.err = msg.err
+		}
+		return m, nil
+
+	case errMsg:
+		m.err = msg.err
+		return m, nil
+
+	case tea.KeyMsg:
+		return m.handleKey(msg)
+	}
+	return m, nil
+}
+
+func (m *model) setItems(values []string) {
+	items := make([]list.Item, len(values))
+	for i, v := range values {
+		items[i] = item(v)
+	}
+	m.list.ResetFilter()
+	m.list.Select(0)
+	m.list.SetItems(items)
+}
+
+func (m *model) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if msg.String() == "ctrl+c" {
+		m.stopTailing()
+		return m, tea.Quit
+	}
+
+	// The filter prompt gets all the keys while it's focused.
+	if m.filter.Focused() {
+		switch msg.String() {
+		case "esc":
+			m.filter.Blur()
+			m.filter.Reset()
+			return m, nil
+		case "enter":
+			stage, err := parseFilter(m.matchers, m.filter.Value())
+			if err != nil {
+				m.err = err
+				return m, nil
+			}
+			m.err = nil
+			m.filters = append(m.filters, stage)
+			m.filter.Blur()
+			m.filter.Reset()
+			return m, m.refresh()
+		}
+		var cmd tea.Cmd
+		m.filter, cmd = m.filter.Update(msg)
+		return m, cmd
+	}
+
+	// So does the list while filtering its items.
+	if m.pane != logsPane && m.list.FilterState() == list.Filtering {
+		var cmd tea.Cmd
+		m.list, cmd = m.list.Update(msg)
+		return m, cmd
+	}
+
+	switch msg.String() {
+	case "q":
+		m.stopTailing()
+		return m, tea.Quit
+	case "tab":
+		if m.pane == logsPane {
+			m.pane = labelsPane
+			m.setListTitle()
+			return m, fetchLabelNames(m.client, m.since)
+		}
+		m.pane = logsPane
+		return m, nil
+	case "f":
+		if len(m.matchers) == 0 {
+			m.err = fmt.Errorf("pick a label value before adding filters")
+			return m, nil
+		}
+		m.filter.Focus()
+		return m, textinput.Blink
+	case "u":
+		// Undo the last filter, or the last matcher.
+		m.err = nil
+		if len(m.filters) > 0 {
+			m.filters = m.filters[:len(m.filters)-1]
+		} else if len(m.matchers) > 0 {
+			m.matchers = m.matchers[:len(m.matchers)-1]
+		}
+		if len(m.matchers) == 0 {
+			m.stopTailing()
+		}
+		return m, m.refresh()
+	case "r":
+		m.err = nil
+		return m, m.refresh()
+	case "t":
+		if m.tail != nil {
+			m.stopTailing()
+			return m, nil
+		}
+		if len(m.matchers) == 0 {
+			m.err = fmt.Errorf("pick a label value before tailing")
+			return m, nil
+		}
+		m.err = nil
+		m.entries, m.cursor, m.expanded = nil, 0, false
+		m.pane = logsPane
+		return m, m.startTailing()
+	}
+
+	switch m.pane {
+	case labelsPane, valuesPane:
+		return m.handleListKey(msg)
+	default:
+		return m.handleLogsKey(msg)
+	}
+}
+
+func (m *model) handleListKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		if m.pane == valuesPane && m.list.FilterState() == list.Unfiltered {
+			m.pane = labelsPane
+			m.setListTitle()
+			return m, fetchLabelNames(m.client, m.since)
+		}
+	case "enter":
+		selected, ok := m.list.SelectedItem().(item)
+		if !ok {
+			return m, nil
+		}
+		if m.pane == labelsPane {
+			m.pane = valuesPane
+			m.label = string(selected)
+			m.setListTitle()
+			m.setItems(nil)
+			return m, fetchLabelValues(m.client, m.since, m.label)
+		}
+		m.matchers = append(m.matchers, matcher(m.label, string(selected)))
+		m.pane = logsPane
+		m.err = nil
+		return m, m.refresh()
+	}
+	var cmd tea.Cmd
+	m.list, cmd = m.list.Update(msg)
+	return m, cmd
+}
+
+func (m *model) handleLogsKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "up", "k":
+		if m.cursor > 0 {
+			m.cursor--
+			m.expanded = false
+		}
+	case "down", "j":
+		if m.cursor < len(m.entries)-1 {
+			m.cursor++
+			m.expanded = false
+		}
+	case "enter", " ":
+		m.expanded = !m.expanded
+	}
+	return m, nil
+}
+
+// paneHeight is the height left to the focused pane, below the header and
+// volume histogram, and above the prompt and help.
+func (m *model) paneHeight() int {
+	return max(m.height-5, 1)
+}
+
+func (m *model) View() string {
+	var b strings.Builder
+
+	header := "Query: " + m.query()
+	if len(m.matchers) == 0 {
+		header = "Query: pick a label and a value to start"
+	}
+	b.WriteString(headerStyle.Render(header))
+	if len(m.matchers) > 0 {
+		fmt.Fprintf(&b, "  streams: %d", m.streams)
+	}
+	if m.tail != nil {
+		b.WriteString("  " + tailingStyle.Render("tailing"))
+	}
+	b.WriteString("\n")
+	b.WriteString(m.volumeView() + "\n")
+
+	switch m.pane {
+	case labelsPane, valuesPane:
+		b.WriteString(m.list.View())
+	default:
+		b.WriteString(m.logsView())
+	}
+	b.WriteString("\n")
+
+	switch {
+	case m.filter.Focused():
+		b.WriteString(m.filter.View())
+	case m.err != nil:
+		b.WriteString(errorStyle.Render("error: " + m.err.Error()))
+	}
+	b.WriteString("\n")
+	b.WriteString(helpStyle.Render("tab: labels/logs • enter: select/expand • f: add filter • u: undo • t: tail • r: refresh • q: quit"))
+	return b.String()
+}
+
+// volumeView renders the volume histogram of the selected streams.
+func (m *model) volumeView() string {
+	if len(m.volume) == 0 {
+		return ""
+	}
+	var total, highest float64
+	for _, v := range m.volume {
+		total += v
+		highest = math.Max(highest, v)
+	}
+	bars := make([]rune, len(m.volume))
+	for i, v := range m.volume {
+		idx := 0
+		if highest > 0 {
+			idx = int(math.Ceil(v / highest * float64(len(volumeBars)-1)))
+		}
+		bars[i] = volumeBars[idx]
+	}
+	return volumeStyle.Render(string(bars)) + fmt.Sprintf("  %s in the last %s", humanize.Bytes(uint64(total)), m.since)
+}
+
+// logsView renders the entries around the cursor, with the structured
+// metadata and parsed labels of the selected entry when it's expanded.
+func (m *model) logsView() string {
+	if len(m.entries) == 0 {
+		if m.tail != nil {
+			return "waiting for logs..."
+		}
+		return "no logs"
+	}
+
+	var details []string
+	if m.expanded {
+		e := m.entries[m.cursor]
+		details = append(details, "labels: "+e.labels.String())
+		if e.entry.StructuredMetadata.Len() > 0 {
+			details = append(details, "structured metadata: "+e.entry.StructuredMetadata.String())
+		}
+		if e.entry.Parsed.Len() > 0 {
+			details = append(details, "parsed: "+e.entry.Parsed.String())
+		}
+	}
+
+	height := max(m.paneHeight()-len(details), 1)
+	start := 0
+	if m.cursor >= height {
+		start = m.cursor - height + 1
+	}
+	end := min(start+height, len(m.entries))
+
+	lines := make([]string, 0, end-start+len(details))
+	for i := start; i < end; i++ {
+		e := m.entries[i]
+		line := e.entry.Timestamp.Format(time.RFC3339) + " " + strings.TrimRight(e.entry.Line, "\n")
+		if m.width > 0 && len(line) > m.width-2 {
+			line = line[:max(m.width-2, 0)]
+		}
+		if i == m.cursor {
+			lines = append(lines, selectedStyle.Render("> "+line))
+			for _, d := range details {
+				lines = append(lines, detailsStyle.Render(d))
+			}
+			continue
+		}
+		lines = append(lines, "  "+line)
+	}
+	return strings.Join(lines, "\n")
+}
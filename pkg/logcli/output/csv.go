@@ -0,0 +1,76 @@
+package output
+
+import (
+	"encoding/csv"
+	"io"
+	"time"
+
+	"github.com/prometheus/prometheus/model/labels"
+
+	"github.com/grafana/loki/v3/pkg/loghttp"
+)
+
+// CSVOutput writes logs as CSV, with a column per stream label, structured
+// metadata and parsed label.
+type CSVOutput struct {
+	w       io.Writer
+	options *LogOutputOptions
+	table   *table
+}
+
+// FormatAndPrintln adds a log entry to the CSV output
+func (o *CSVOutput) FormatAndPrintln(ts time.Time, lbls loghttp.LabelSet, _ int, line string) {
+	o.table.add(ts, lbls, line, labels.EmptyLabels(), labels.EmptyLabels())
+}
+
+// FormatAndPrintEntry adds a log entry with its structured metadata and parsed
+// labels to the CSV output
+func (o *CSVOutput) FormatAndPrintEntry(lbls loghttp.LabelSet, entry loghttp.Entry) {
+	o.table.add(entry.Timestamp, lbls, entry.Line, entry.StructuredMetadata, entry.Parsed)
+}
+
+// Close writes the header and the buffered entries
+func (o *CSVOutput) Close() error {
+	defer o.table.reset()
+
+	columns := o.table.columns()
+	w := csv.NewWriter(o.w)
+
+	record := make([]string, 0, len(columns)+2)
+	record = append(record, timestampColumn, lineColumn)
+	for _, c := range columns {
+		record = append(record, c.name)
+	}
+	if err := w.Write(record); err != nil {
+		return err
+	}
+
+	for _, r := range o.table.rows {
+		record = record[:0]
+		record = append(record, r.timestamp.In(o.options.Timezone).Format(time.RFC3339Nano), r.line)
+		for _, c := range columns {
+			v, _ := r.value(c)
+			record = append(record, v)
+		}
+		if err := w.Write(record); err != nil {
+			return err
+		}
+	}
+
+	w.Flush()
+	return w.Error()
+}
+
+// Extension returns the extension of CSV files
+func (o *CSVOutput) Extension() string {
+	return "csv"
+}
+
+// WithWriter returns a copy of the LogOutput with the writer set to the given writer
+func (o CSVOutput) WithWriter(w io.Writer) LogOutput {
+	return &CSVOutput{
+		w:       w,
+		options: o.options,
+		table:   &table{options: o.options},
+	}
+}
@@ -0,0 +1,42 @@
+package output
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/v3/pkg/loghttp"
+)
+
+func TestCSVOutput(t *testing.T) {
+	t.Parallel()
+
+	ts := time.Date(2024, 5, 1, 10, 0, 0, 500, time.UTC)
+	buf := &bytes.Buffer{}
+	out, err := NewLogOutput(nil, "csv", &LogOutputOptions{Timezone: time.UTC})
+	require.NoError(t, err)
+	out = out.WithWriter(buf)
+	fo := out.(FileOutput)
+
+	fo.FormatAndPrintEntry(loghttp.LabelSet{"app": "web", "level": "info"}, loghttp.Entry{
+		Timestamp:          ts,
+		Line:               "GET /index.html",
+		StructuredMetadata: labels.FromStrings("trace_id", "abc"),
+		Parsed:             labels.FromStrings("level", "debug", "status", "200"),
+	})
+	fo.FormatAndPrintEntry(loghttp.LabelSet{"app": "api"}, loghttp.Entry{
+		Timestamp: ts.Add(time.Second),
+		Line:      `POST "/v1"`,
+		Parsed:    labels.FromStrings("status", "500"),
+	})
+	require.NoError(t, fo.Close())
+
+	require.Equal(t, `timestamp,line,app,level,trace_id,level_parsed,status
+2024-05-01T10:00:00.0000005Z,GET /index.html,web,info,abc,debug,200
+2024-05-01T10:00:01.0000005Z,"POST ""/v1""",api,,,,500
+`, buf.String())
+	require.Equal(t, "csv", fo.Extension())
+}
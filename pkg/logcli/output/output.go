@@ -67,6 +67,18 @@ func NewLogOutput(w io.Writer, mode string, options *LogOutputOptions) (LogOutpu
 			w:       w,
 			options: options,
 		}, nil
+	case "csv":
+		return &CSVOutput{
+			w:       w,
+			options: options,
+			table:   &table{options: options},
+		}, nil
+	case "parquet":
+		return &ParquetOutput{
+			w:       w,
+			options: options,
+			table:   &table{options: options},
+		}, nil
 	default:
 		return nil, fmt.Errorf("unknown log output mode '%s'", mode)
 	}
@@ -23,6 +23,14 @@ func TestNewLogOutput(t *testing.T) {
 	assert.NoError(t, err)
 	assert.IsType(t, &RawOutput{nil, options}, out)
 
+	out, err = NewLogOutput(nil, "csv", options)
+	assert.NoError(t, err)
+	assert.IsType(t, &CSVOutput{}, out)
+
+	out, err = NewLogOutput(nil, "parquet", options)
+	assert.NoError(t, err)
+	assert.IsType(t, &ParquetOutput{}, out)
+
 	out, err = NewLogOutput(nil, "unknown", options)
 	assert.Error(t, err)
 	assert.Nil(t, out)
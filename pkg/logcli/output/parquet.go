@@ -0,0 +1,123 @@
+package output
+
+import (
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+	"github.com/prometheus/prometheus/model/labels"
+
+	"github.com/grafana/loki/v3/pkg/loghttp"
+)
+
+// ParquetOutput writes logs as a Parquet file, with a typed column per stream
+// label, structured metadata and parsed label.
+type ParquetOutput struct {
+	w       io.Writer
+	options *LogOutputOptions
+	table   *table
+}
+
+// FormatAndPrintln adds a log entry to the Parquet output
+func (o *ParquetOutput) FormatAndPrintln(ts time.Time, lbls loghttp.LabelSet, _ int, line string) {
+	o.table.add(ts, lbls, line, labels.EmptyLabels(), labels.EmptyLabels())
+}
+
+// FormatAndPrintEntry adds a log entry with its structured metadata and parsed
+// labels to the Parquet output
+func (o *ParquetOutput) FormatAndPrintEntry(lbls loghttp.LabelSet, entry loghttp.Entry) {
+	o.table.add(entry.Timestamp, lbls, entry.Line, entry.StructuredMetadata, entry.Parsed)
+}
+
+// Close writes the buffered entries and the footer of the Parquet file
+func (o *ParquetOutput) Close() error {
+	defer o.table.reset()
+
+	columns := o.table.columns()
+	group := parquet.Group{
+		timestampColumn: parquet.Timestamp(parquet.Nanosecond),
+		lineColumn:      parquet.Compressed(parquet.String(), &parquet.Lz4Raw),
+	}
+	for _, c := range columns {
+		group[c.name] = parquet.Optional(parquetNode(c.typ))
+	}
+	schema := parquet.NewSchema("logs", group)
+
+	// Rows hold the values in the order of the columns of the schema, which
+	// sorts them by name.
+	timestampIdx := columnIndex(schema, timestampColumn)
+	lineIdx := columnIndex(schema, lineColumn)
+	indexes := make([]int, len(columns))
+	for i, c := range columns {
+		indexes[i] = columnIndex(schema, c.name)
+	}
+
+	writer := parquet.NewWriter(o.w, schema)
+	for _, r := range o.table.rows {
+		row := make(parquet.Row, len(columns)+2)
+		row[timestampIdx] = parquet.Int64Value(r.timestamp.UnixNano()).Level(0, 0, timestampIdx)
+		row[lineIdx] = parquet.ByteArrayValue([]byte(r.line)).Level(0, 0, lineIdx)
+		for i, c := range columns {
+			v, ok := r.value(c)
+			if !ok {
+				row[indexes[i]] = parquet.NullValue().Level(0, 0, indexes[i])
+				continue
+			}
+			row[indexes[i]] = parquetValue(c.typ, v).Level(0, 1, indexes[i])
+		}
+		if _, err := writer.WriteRows([]parquet.Row{row}); err != nil {
+			return err
+		}
+	}
+	return writer.Close()
+}
+
+func columnIndex(schema *parquet.Schema, name string) int {
+	leaf, _ := schema.Lookup(name)
+	return leaf.ColumnIndex
+}
+
+func parquetNode(typ columnType) parquet.Node {
+	switch typ {
+	case typeInt64:
+		return parquet.Int(64)
+	case typeFloat64:
+		return parquet.Leaf(parquet.DoubleType)
+	case typeBool:
+		return parquet.Leaf(parquet.BooleanType)
+	default:
+		return parquet.String()
+	}
+}
+
+// parquetValue converts the value to the type of the column. The type was
+// inferred from the values, so the conversion can't fail.
+func parquetValue(typ columnType, v string) parquet.Value {
+	switch typ {
+	case typeInt64:
+		i, _ := strconv.ParseInt(v, 10, 64)
+		return parquet.Int64Value(i)
+	case typeFloat64:
+		f, _ := strconv.ParseFloat(v, 64)
+		return parquet.DoubleValue(f)
+	case typeBool:
+		return parquet.BooleanValue(v == "true")
+	default:
+		return parquet.ByteArrayValue([]byte(v))
+	}
+}
+
+// Extension returns the extension of Parquet files
+func (o *ParquetOutput) Extension() string {
+	return "parquet"
+}
+
+// WithWriter returns a copy of the LogOutput with the writer set to the given writer
+func (o ParquetOutput) WithWriter(w io.Writer) LogOutput {
+	return &ParquetOutput{
+		w:       w,
+		options: o.options,
+		table:   &table{options: o.options},
+	}
+}
@@ -0,0 +1,66 @@
+package output
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/v3/pkg/loghttp"
+)
+
+func TestParquetOutput(t *testing.T) {
+	t.Parallel()
+
+	ts := time.Date(2024, 5, 1, 10, 0, 0, 500, time.UTC)
+	buf := &bytes.Buffer{}
+	out, err := NewLogOutput(nil, "parquet", &LogOutputOptions{Timezone: time.UTC})
+	require.NoError(t, err)
+	out = out.WithWriter(buf)
+	fo := out.(FileOutput)
+
+	fo.FormatAndPrintEntry(loghttp.LabelSet{"app": "web"}, loghttp.Entry{
+		Timestamp:          ts,
+		Line:               "GET /index.html",
+		StructuredMetadata: labels.FromStrings("trace_id", "abc"),
+		Parsed:             labels.FromStrings("cached", "true", "duration", "0.25", "status", "200"),
+	})
+	fo.FormatAndPrintEntry(loghttp.LabelSet{"app": "api"}, loghttp.Entry{
+		Timestamp: ts.Add(time.Second),
+		Line:      "POST /v1",
+		Parsed:    labels.FromStrings("duration", "1", "status", "500"),
+	})
+	require.NoError(t, fo.Close())
+	require.Equal(t, "parquet", fo.Extension())
+
+	type row struct {
+		Timestamp int64    `parquet:"timestamp"`
+		Line      string   `parquet:"line"`
+		App       *string  `parquet:"app"`
+		TraceID   *string  `parquet:"trace_id"`
+		Cached    *bool    `parquet:"cached"`
+		Duration  *float64 `parquet:"duration"`
+		Status    *int64   `parquet:"status"`
+	}
+	rows, err := parquet.Read[row](bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+	require.Len(t, rows, 2)
+
+	str := func(s string) *string { return &s }
+	b := true
+	d1, d2 := 0.25, 1.0
+	s1, s2 := int64(200), int64(500)
+	require.Equal(t, []row{
+		{Timestamp: ts.UnixNano(), Line: "GET /index.html", App: str("web"), TraceID: str("abc"), Cached: &b, Duration: &d1, Status: &s1},
+		{Timestamp: ts.Add(time.Second).UnixNano(), Line: "POST /v1", App: str("api"), Duration: &d2, Status: &s2},
+	}, rows)
+
+	f, err := parquet.OpenFile(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+	status, ok := f.Schema().Lookup("status")
+	require.True(t, ok)
+	require.Equal(t, parquet.Int64Type.Kind(), status.Node.Type().Kind())
+}
@@ -0,0 +1,198 @@
+package output
+
+import (
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/prometheus/model/labels"
+
+	"github.com/grafana/loki/v3/pkg/loghttp"
+)
+
+// FileOutput is implemented by the output modes writing self-contained files,
+// which can't be concatenated. The entries are buffered until Close is called,
+// because the columns are only known once all the entries have been received.
+type FileOutput interface {
+	LogOutput
+	// FormatAndPrintEntry adds an entry along with its structured metadata
+	// and parsed labels.
+	FormatAndPrintEntry(lbls loghttp.LabelSet, entry loghttp.Entry)
+	// Close writes the buffered entries.
+	Close() error
+	// Extension returns the extension of the written files.
+	Extension() string
+}
+
+const (
+	timestampColumn = "timestamp"
+	lineColumn      = "line"
+)
+
+type columnSource int
+
+const (
+	sourceStreamLabel columnSource = iota
+	sourceStructuredMetadata
+	sourceParsed
+)
+
+// suffix is appended to the name of a column colliding with another one.
+func (s columnSource) suffix() string {
+	switch s {
+	case sourceStructuredMetadata:
+		return "_structured_metadata"
+	case sourceParsed:
+		return "_parsed"
+	default:
+		return "_label"
+	}
+}
+
+type columnType int
+
+const (
+	typeString columnType = iota
+	typeInt64
+	typeFloat64
+	typeBool
+)
+
+type column struct {
+	name   string
+	key    string
+	source columnSource
+	typ    columnType
+}
+
+type tableRow struct {
+	timestamp          time.Time
+	line               string
+	labels             loghttp.LabelSet
+	structuredMetadata labels.Labels
+	parsed             labels.Labels
+}
+
+// table buffers entries to write them with a column per stream label,
+// structured metadata and parsed label.
+type table struct {
+	options *LogOutputOptions
+	rows    []tableRow
+}
+
+func (t *table) add(ts time.Time, lbls loghttp.LabelSet, line string, structuredMetadata, parsed labels.Labels) {
+	if t.options.NoLabels {
+		lbls = nil
+	}
+	t.rows = append(t.rows, tableRow{
+		timestamp:          ts,
+		line:               line,
+		labels:             lbls,
+		structuredMetadata: structuredMetadata,
+		parsed:             parsed,
+	})
+}
+
+func (t *table) reset() {
+	t.rows = nil
+}
+
+// value returns the value of the column in the row, and false if the row
+// doesn't have it.
+func (r tableRow) value(c column) (string, bool) {
+	switch c.source {
+	case sourceStructuredMetadata:
+		return labelValue(r.structuredMetadata, c.key)
+	case sourceParsed:
+		return labelValue(r.parsed, c.key)
+	default:
+		v, ok := r.labels[c.key]
+		return v, ok
+	}
+}
+
+func labelValue(lbls labels.Labels, name string) (string, bool) {
+	v := lbls.Get(name)
+	if v == "" {
+		return "", lbls.Has(name)
+	}
+	return v, true
+}
+
+// columns returns the stream labels, structured metadata and parsed labels
+// columns, in this order and sorted by name within each source. Stream labels
+// are always strings, the type of the other columns is inferred from their
+// values.
+func (t *table) columns() []column {
+	seen := [3]map[string]struct{}{{}, {}, {}}
+	for _, r := range t.rows {
+		for name := range r.labels {
+			seen[sourceStreamLabel][name] = struct{}{}
+		}
+		r.structuredMetadata.Range(func(l labels.Label) {
+			seen[sourceStructuredMetadata][l.Name] = struct{}{}
+		})
+		r.parsed.Range(func(l labels.Label) {
+			seen[sourceParsed][l.Name] = struct{}{}
+		})
+	}
+
+	used := map[string]struct{}{timestampColumn: {}, lineColumn: {}}
+	var columns []column
+	for source, keys := range seen {
+		names := make([]string, 0, len(keys))
+		for name := range keys {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, key := range names {
+			c := column{name: key, key: key, source: columnSource(source)}
+			if _, ok := used[c.name]; ok {
+				c.name = key + c.source.suffix()
+			}
+			used[c.name] = struct{}{}
+			if c.source != sourceStreamLabel {
+				c.typ = t.inferType(c)
+			}
+			columns = append(columns, c)
+		}
+	}
+	return columns
+}
+
+// inferType returns the narrowest type all the values of the column can be
+// converted to without losing their original representation.
+func (t *table) inferType(c column) columnType {
+	isInt, isFloat, isBool := true, true, true
+	for _, r := range t.rows {
+		v, ok := r.value(c)
+		if !ok {
+			continue
+		}
+		if isInt {
+			i, err := strconv.ParseInt(v, 10, 64)
+			isInt = err == nil && strconv.FormatInt(i, 10) == v
+		}
+		if isFloat {
+			f, err := strconv.ParseFloat(v, 64)
+			isFloat = err == nil && strconv.FormatFloat(f, 'g', -1, 64) == v
+		}
+		if isBool {
+			isBool = v == "true" || v == "false"
+		}
+		if !isInt && !isFloat && !isBool {
+			return typeString
+		}
+	}
+	switch {
+	case isInt:
+		return typeInt64
+	case isFloat:
+		return typeFloat64
+	case isBool:
+		return typeBool
+	default:
+		return typeString
+	}
+}
@@ -3,6 +3,7 @@ package print
 import (
 	"encoding/json"
 	"fmt"
+	"io"
 	"log"
 	"os"
 	"sort"
@@ -55,6 +56,8 @@ func (r *QueryResultPrinter) PrintResult(value loghttp.ResultValue, out output.L
 		printMatrix(value.(loghttp.Matrix))
 	case loghttp.ResultTypeVector:
 		printVector(value.(loghttp.Vector))
+	case loghttp.ResultTypeTable:
+		printTable(os.Stdout, value.(loghttp.Table))
 	default:
 		log.Fatalf("Unable to print unsupported type: %v", value.Type())
 	}
@@ -149,7 +152,11 @@ func (r *QueryResultPrinter) printStream(streams loghttp.Streams, out output.Log
 				continue
 			}
 		}
-		out.FormatAndPrintln(e.entry.Timestamp, e.labels, maxLabelsLen, e.entry.Line)
+		if fo, ok := out.(output.FileOutput); ok {
+			fo.FormatAndPrintEntry(e.labels, e.entry)
+		} else {
+			out.FormatAndPrintln(e.entry.Timestamp, e.labels, maxLabelsLen, e.entry.Line)
+		}
 		printed++
 	}
 
@@ -200,6 +207,19 @@ func printScalar(scalar loghttp.Scalar) {
 	fmt.Print(string(bytes))
 }
 
+// printTable prints the result of a stats stage as aligned columns, with the
+// column names as header.
+func printTable(w io.Writer, table loghttp.Table) {
+	writer := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(writer, strings.Join(table.Columns, "\t"))
+	for _, row := range table.Rows {
+		fmt.Fprintln(writer, strings.Join(row, "\t"))
+	}
+	if err := writer.Flush(); err != nil {
+		log.Fatalf("Error printing table: %v", err)
+	}
+}
+
 type kvLogger struct {
 	*tabwriter.Writer
 }
@@ -1,6 +1,7 @@
 package print
 
 import (
+	"bytes"
 	"reflect"
 	"testing"
 
@@ -148,3 +149,19 @@ func mustParseLabels(t *testing.T, s string) loghttp.LabelSet {
 
 	return l
 }
+
+func Test_printTable(t *testing.T) {
+	var buf bytes.Buffer
+	printTable(&buf, loghttp.Table{
+		Columns: []string{"status", "count()", "avg(latency)"},
+		Rows: [][]string{
+			{"200", "1024", "0.25"},
+			{"500", "3", ""},
+		},
+	})
+
+	expected := "status  count()  avg(latency)\n" +
+		"200     1024     0.25\n" +
+		"500     3        \n"
+	require.Equal(t, expected, buf.String())
+}
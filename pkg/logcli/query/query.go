@@ -36,6 +36,10 @@ import (
 
 const schemaConfigFilename = "schemaconfig"
 
+// MaxFileOutputPartDuration is the longest time range of the part files
+// written by file outputs, which buffer the entries of a part in memory.
+const MaxFileOutputPartDuration = 24 * time.Hour
+
 // Query contains all necessary fields to execute instant and range queries and print the results.
 type Query struct {
 	QueryString            string
@@ -85,6 +89,11 @@ type Query struct {
 
 // DoQuery executes the query and prints out the results
 func (q *Query) DoQuery(c client.Client, out output.LogOutput, statistics bool) {
+	// File outputs write a column per label, so common labels are kept.
+	if _, ok := out.(output.FileOutput); ok {
+		q.IncludeCommonLabels = true
+	}
+
 	if q.LocalConfig != "" {
 		orgID := c.GetOrgID()
 		if orgID == "" {
@@ -93,6 +102,9 @@ func (q *Query) DoQuery(c client.Client, out output.LogOutput, statistics bool)
 		if err := q.DoLocalQuery(out, statistics, orgID, q.FetchSchemaFromStorage); err != nil {
 			log.Fatalf("Query failed: %+v", err)
 		}
+		if err := closeOutput(out); err != nil {
+			log.Fatalf("Query failed: %+v", err)
+		}
 		return
 	}
 
@@ -104,7 +116,7 @@ func (q *Query) DoQuery(c client.Client, out output.LogOutput, statistics bool)
 	var partFile *PartFile
 	if q.PartPathPrefix != "" {
 		var shouldSkip bool
-		partFile, shouldSkip = q.createPartFile()
+		partFile, shouldSkip = q.createPartFile(out)
 
 		// createPartFile will return true if the part file exists and
 		// OverwriteCompleted is false, therefor, we should exit the function
@@ -202,6 +214,10 @@ func (q *Query) DoQuery(c client.Client, out output.LogOutput, statistics bool)
 		}
 	}
 
+	if err := closeOutput(out); err != nil {
+		log.Fatalf("Query failed: %+v", err)
+	}
+
 	if partFile != nil {
 		if err := partFile.Finalize(); err != nil {
 			log.Fatalln(err)
@@ -209,19 +225,32 @@ func (q *Query) DoQuery(c client.Client, out output.LogOutput, statistics bool)
 	}
 }
 
-func (q *Query) outputFilename() string {
+func (q *Query) outputFilename(out output.LogOutput) string {
+	ext := "part"
+	if fo, ok := out.(output.FileOutput); ok {
+		ext = fo.Extension()
+	}
 	return fmt.Sprintf(
-		"%s_%s_%s.part",
+		"%s_%s_%s.%s",
 		q.PartPathPrefix,
 		q.Start.UTC().Format("20060102T150405"),
 		q.End.UTC().Format("20060102T150405"),
+		ext,
 	)
 }
 
+// closeOutput writes the entries buffered by file outputs.
+func closeOutput(out output.LogOutput) error {
+	if fo, ok := out.(output.FileOutput); ok {
+		return fo.Close()
+	}
+	return nil
+}
+
 // createPartFile returns a PartFile.
 // The bool value shows if the part file already exists, and this range should be skipped.
-func (q *Query) createPartFile() (*PartFile, bool) {
-	partFile := NewPartFile(q.outputFilename())
+func (q *Query) createPartFile(out output.LogOutput) (*PartFile, bool) {
+	partFile := NewPartFile(q.outputFilename(out))
 
 	if !q.OverwriteCompleted {
 		// If we already have the completed file, no need to download it again.
@@ -308,7 +337,7 @@ func (q *Query) mergeJobs(jobs []*parallelJob) error {
 		// wait for the next job to finish
 		<-job.done
 
-		f, err := os.Open(job.q.outputFilename())
+		f, err := os.Open(job.q.outputFilename(nil))
 		if err != nil {
 			return fmt.Errorf("open file error: %w", err)
 		}
@@ -320,7 +349,7 @@ func (q *Query) mergeJobs(jobs []*parallelJob) error {
 		}
 
 		if !q.KeepParts {
-			err := os.Remove(job.q.outputFilename())
+			err := os.Remove(job.q.outputFilename(nil))
 			if err != nil {
 				return fmt.Errorf("removing file error: %w", err)
 			}
@@ -371,6 +400,10 @@ func (q *Query) DoQueryParallel(c client.Client, out output.LogOutput, statistic
 		log.Fatalf("Parallel duration has to be a positive value\n")
 	}
 
+	if err := q.validateFileOutput(out); err != nil {
+		log.Fatalf("%s\n", err)
+	}
+
 	jobs := q.parallelJobs()
 
 	wg := q.startWorkers(jobs, c, out, statistics)
@@ -382,6 +415,25 @@ func (q *Query) DoQueryParallel(c client.Client, out output.LogOutput, statistic
 	wg.Wait()
 }
 
+// validateFileOutput checks the parts of file outputs are written to their own
+// bounded files. File outputs buffer the entries of a part in memory to find
+// out its columns, and the files they write can't be concatenated.
+func (q *Query) validateFileOutput(out output.LogOutput) error {
+	if _, ok := out.(output.FileOutput); !ok {
+		return nil
+	}
+	if q.PartPathPrefix == "" {
+		return errors.New("the --part-path-prefix flag is required when using a file output")
+	}
+	if q.ParallelDuration > MaxFileOutputPartDuration {
+		return fmt.Errorf("the --parallel-duration flag can't be greater than %s when using a file output", MaxFileOutputPartDuration)
+	}
+	if q.MergeParts {
+		return errors.New("the --merge-parts flag can't be used with a file output")
+	}
+	return nil
+}
+
 func minTime(t1, t2 time.Time) time.Time {
 	if t1.Before(t2) {
 		return t1
@@ -750,6 +750,90 @@ func cmpParallelJobSlice(t *testing.T, expected, actual []*parallelJob) {
 	}
 }
 
+func TestDoQuery_FileOutputPartFile(t *testing.T) {
+	tc := newTestQueryClient(logproto.Stream{
+		Labels: `{app="web", env="prod"}`,
+		Entries: []logproto.Entry{
+			{Timestamp: time.Unix(1, 0), Line: "line1"},
+			{Timestamp: time.Unix(2, 0), Line: "line2"},
+		},
+	})
+	out, err := output.NewLogOutput(nil, "csv", &output.LogOutputOptions{Timezone: time.UTC})
+	require.NoError(t, err)
+
+	q := Query{
+		QueryString:    `{app="web"}`,
+		Start:          time.Unix(0, 0),
+		End:            time.Unix(10, 0),
+		Limit:          10,
+		BatchSize:      10,
+		Forward:        true,
+		Quiet:          true,
+		PartPathPrefix: filepath.Join(t.TempDir(), "export"),
+	}
+	q.DoQuery(tc, out, false)
+
+	// The part file is named after the output and keeps the common labels.
+	data, err := os.ReadFile(q.PartPathPrefix + "_19700101T000000_19700101T000010.csv")
+	require.NoError(t, err)
+	require.Equal(t, `timestamp,line,app,env
+1970-01-01T00:00:01Z,line1,web,prod
+1970-01-01T00:00:02Z,line2,web,prod
+`, string(data))
+}
+
+func TestValidateFileOutput(t *testing.T) {
+	csv, err := output.NewLogOutput(nil, "csv", &output.LogOutputOptions{Timezone: time.UTC})
+	require.NoError(t, err)
+	raw, err := output.NewLogOutput(nil, "raw", &output.LogOutputOptions{Timezone: time.UTC})
+	require.NoError(t, err)
+
+	for _, tc := range []struct {
+		name    string
+		out     output.LogOutput
+		q       Query
+		wantErr string
+	}{
+		{
+			name: "not a file output",
+			out:  raw,
+			q:    Query{ParallelDuration: 7 * 24 * time.Hour},
+		},
+		{
+			name: "valid",
+			out:  csv,
+			q:    Query{PartPathPrefix: "/tmp/export", ParallelDuration: time.Hour},
+		},
+		{
+			name:    "missing part path prefix",
+			out:     csv,
+			q:       Query{ParallelDuration: time.Hour},
+			wantErr: "the --part-path-prefix flag is required when using a file output",
+		},
+		{
+			name:    "unbounded parallel duration",
+			out:     csv,
+			q:       Query{PartPathPrefix: "/tmp/export", ParallelDuration: 7 * 24 * time.Hour},
+			wantErr: "the --parallel-duration flag can't be greater than 24h0m0s when using a file output",
+		},
+		{
+			name:    "merge parts",
+			out:     csv,
+			q:       Query{PartPathPrefix: "/tmp/export", ParallelDuration: time.Hour, MergeParts: true},
+			wantErr: "the --merge-parts flag can't be used with a file output",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.q.validateFileOutput(tc.out)
+			if tc.wantErr == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.EqualError(t, err, tc.wantErr)
+		})
+	}
+}
+
 func TestParallelJobs(t *testing.T) {
 	mkQuery := func(start, end string, d time.Duration, forward bool) *Query {
 		return &Query{
@@ -2,10 +2,13 @@ package query
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log"
 	"os"
 	"os/signal"
 	"strings"
+	"sync"
 	"syscall"
 	"time"
 
@@ -22,19 +25,12 @@ import (
 
 // TailQuery connects to the Loki websocket endpoint and tails logs
 func (q *Query) TailQuery(delayFor time.Duration, c client.Client, out output.LogOutput) {
-	conn, err := c.LiveTailQueryConn(q.QueryString, delayFor, q.Limit, q.Start, q.Quiet)
-	if err != nil {
-		log.Fatalf("Tailing logs failed: %+v", err)
-	}
-
+	stop := make(chan struct{})
 	go func() {
 		stopChan := make(chan os.Signal, 1)
 		signal.Notify(stopChan, os.Interrupt, syscall.SIGTERM)
 		<-stopChan
-		if err := conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, "")); err != nil {
-			log.Println("Error closing websocket:", err)
-		}
-		os.Exit(0)
+		close(stop)
 	}()
 
 	if len(q.IgnoreLabelsKey) > 0 && !q.Quiet {
@@ -45,11 +41,62 @@ func (q *Query) TailQuery(delayFor time.Duration, c client.Client, out output.Lo
 		log.Println("Print only labels key:", color.RedString(strings.Join(q.ShowLabelsKey, ",")))
 	}
 
+	err := q.TailEntries(delayFor, c, stop, func(labels loghttp.LabelSet, entry loghttp.Entry) {
+		out.FormatAndPrintln(entry.Timestamp, labels, 0, entry.Line)
+	})
+	if errors.Is(err, errTailConnect) {
+		log.Fatalf("Tailing logs failed: %+v", err)
+	}
+	if err != nil {
+		log.Println(err)
+	}
+}
+
+var errTailConnect = errors.New("tailing logs failed")
+
+// TailEntries connects to the Loki websocket endpoint and calls handler for
+// every received entry, until stop is closed or the connection can't be
+// re-established. The connection is closed when stop is closed.
+func (q *Query) TailEntries(delayFor time.Duration, c client.Client, stop <-chan struct{}, handler func(labels loghttp.LabelSet, entry loghttp.Entry)) error {
+	conn, err := c.LiveTailQueryConn(q.QueryString, delayFor, q.Limit, q.Start, q.Quiet)
+	if err != nil {
+		return fmt.Errorf("%w: %w", errTailConnect, err)
+	}
+
+	// The connection is replaced when it's re-established. Once stop is
+	// closed, ctx is cancelled to abort reconnecting, and a connection that is
+	// established afterwards is closed right away.
+	var (
+		mtx     sync.Mutex
+		stopped bool
+	)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-stop:
+		case <-done:
+			return
+		}
+		cancel()
+		mtx.Lock()
+		defer mtx.Unlock()
+		stopped = true
+		closeTailConn(conn)
+	}()
+
 	lastReceivedTimestamp := q.Start
 
 	for {
 		tailResponse := new(loghttp.TailResponse)
 		err := unmarshal.ReadTailResponseJSON(tailResponse, conn)
+		select {
+		case <-stop:
+			return nil
+		default:
+		}
 		if err != nil {
 			// Check if the websocket connection closed unexpectedly. If so, retry.
 			// The connection might close unexpectedly if the querier handling the tail request
@@ -59,20 +106,31 @@ func (q *Query) TailQuery(delayFor time.Duration, c client.Client, out output.Lo
 				log.Printf("Remote websocket connection closed unexpectedly (%+v). Connecting again.", err)
 
 				// Close previous connection. If it fails to close the connection it should be fine as it is already broken.
+				mtx.Lock()
 				if err = conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, "")); err != nil {
 					log.Printf("Error closing websocket: %+v", err)
 				}
+				mtx.Unlock()
 
 				// Try to re-establish the connection up to 5 times.
-				backoff := backoff.New(context.Background(), backoff.Config{
+				backoff := backoff.New(ctx, backoff.Config{
 					MinBackoff: 1 * time.Second,
 					MaxBackoff: 10 * time.Second,
 					MaxRetries: 5,
 				})
 
 				for backoff.Ongoing() {
-					conn, err = c.LiveTailQueryConn(q.QueryString, delayFor, q.Limit, lastReceivedTimestamp, q.Quiet)
+					var newConn *websocket.Conn
+					newConn, err = c.LiveTailQueryConn(q.QueryString, delayFor, q.Limit, lastReceivedTimestamp, q.Quiet)
 					if err == nil {
+						mtx.Lock()
+						if stopped {
+							mtx.Unlock()
+							closeTailConn(newConn)
+							return nil
+						}
+						conn = newConn
+						mtx.Unlock()
 						break
 					}
 
@@ -81,15 +139,16 @@ func (q *Query) TailQuery(delayFor time.Duration, c client.Client, out output.Lo
 				}
 
 				if err = backoff.Err(); err != nil {
-					log.Println("Error recreating tailing connection:", err)
-					return
+					if ctx.Err() != nil {
+						return nil
+					}
+					return fmt.Errorf("error recreating tailing connection: %w", err)
 				}
 
 				continue
 			}
 
-			log.Println("Error reading stream:", err)
-			return
+			return fmt.Errorf("error reading stream: %w", err)
 		}
 
 		labels := loghttp.LabelSet{}
@@ -115,7 +174,7 @@ func (q *Query) TailQuery(delayFor time.Duration, c client.Client, out output.Lo
 			}
 
 			for _, entry := range stream.Entries {
-				out.FormatAndPrintln(entry.Timestamp, labels, 0, entry.Line)
+				handler(labels, entry)
 				lastReceivedTimestamp = entry.Timestamp
 			}
 
@@ -129,6 +188,14 @@ func (q *Query) TailQuery(delayFor time.Duration, c client.Client, out output.Lo
 	}
 }
 
+// closeTailConn sends a close message and closes conn.
+func closeTailConn(conn *websocket.Conn) {
+	if err := conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, "")); err != nil {
+		log.Println("Error closing websocket:", err)
+	}
+	conn.Close()
+}
+
 func matchLabels(on bool, l loghttp.LabelSet, names []string) loghttp.LabelSet {
 	return util.MatchLabels(on, l, names)
 }
@@ -0,0 +1,157 @@
+package logproto
+
+import (
+	"math"
+	"slices"
+
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/histogram"
+	"github.com/prometheus/prometheus/prompb"
+)
+
+// SampleHistogram is a native histogram with its timestamp, encoded as the
+// prometheus.Histogram message of the remote write protocol.
+// It is used as gogoproto customtype, since the generated code of the
+// message lacks methods that are required by Loki's protos.
+type SampleHistogram prompb.Histogram
+
+// NewSampleHistogram returns the SampleHistogram of a float histogram at the
+// timestamp t in milliseconds.
+func NewSampleHistogram(t int64, h *histogram.FloatHistogram) SampleHistogram {
+	return SampleHistogram(prompb.FromFloatHistogram(t, h))
+}
+
+// FloatHistogram returns the native histogram of the sample.
+func (m SampleHistogram) FloatHistogram() *histogram.FloatHistogram {
+	return prompb.Histogram(m).ToFloatHistogram()
+}
+
+func (m *SampleHistogram) Marshal() ([]byte, error) {
+	return (*prompb.Histogram)(m).Marshal()
+}
+
+func (m *SampleHistogram) MarshalTo(dAtA []byte) (int, error) {
+	return (*prompb.Histogram)(m).MarshalTo(dAtA)
+}
+
+func (m *SampleHistogram) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	return (*prompb.Histogram)(m).MarshalToSizedBuffer(dAtA)
+}
+
+func (m *SampleHistogram) Unmarshal(dAtA []byte) error {
+	return (*prompb.Histogram)(m).Unmarshal(dAtA)
+}
+
+func (m *SampleHistogram) Size() int {
+	return (*prompb.Histogram)(m).Size()
+}
+
+func (m *SampleHistogram) Equal(other SampleHistogram) bool {
+	return m.Timestamp == other.Timestamp && m.FloatHistogram().Equals(other.FloatHistogram())
+}
+
+// FromFloatHistogramToModel converts a native histogram into the
+// representation of the Prometheus HTTP API, which lists the boundaries of
+// each bucket.
+func FromFloatHistogramToModel(h *histogram.FloatHistogram) *model.SampleHistogram {
+	buckets := model.HistogramBuckets{}
+	it := h.AllBucketIterator()
+	for it.Next() {
+		b := it.At()
+		if b.Count == 0 {
+			continue
+		}
+		// 0: open left, closed right; 1: closed left, open right;
+		// 2: open on both sides; 3: closed on both sides.
+		boundaries := int32(2)
+		switch {
+		case b.LowerInclusive && b.UpperInclusive:
+			boundaries = 3
+		case b.LowerInclusive:
+			boundaries = 1
+		case b.UpperInclusive:
+			boundaries = 0
+		}
+		buckets = append(buckets, &model.HistogramBucket{
+			Boundaries: boundaries,
+			Lower:      model.FloatString(b.Lower),
+			Upper:      model.FloatString(b.Upper),
+			Count:      model.FloatString(b.Count),
+		})
+	}
+	return &model.SampleHistogram{
+		Count:   model.FloatString(h.Count),
+		Sum:     model.FloatString(h.Sum),
+		Buckets: buckets,
+	}
+}
+
+// FromModelToFloatHistogram is the reverse of FromFloatHistogramToModel for
+// histograms with an exponential schema. The schema is derived from the
+// width of the buckets, which all grow by the same factor.
+func FromModelToFloatHistogram(h *model.SampleHistogram) *histogram.FloatHistogram {
+	fh := &histogram.FloatHistogram{
+		CounterResetHint: histogram.GaugeType,
+		Count:            float64(h.Count),
+		Sum:              float64(h.Sum),
+	}
+	positive := map[int32]float64{}
+	negative := map[int32]float64{}
+	schemaKnown := false
+	for _, b := range h.Buckets {
+		lower, upper := float64(b.Lower), float64(b.Upper)
+		if lower <= 0 && upper >= 0 {
+			fh.ZeroThreshold = upper
+			fh.ZeroCount = float64(b.Count)
+			continue
+		}
+		bound := upper
+		if lower < 0 {
+			bound = -lower
+		}
+		if !schemaKnown {
+			growth := math.Abs(upper / lower)
+			if growth < 1 {
+				growth = 1 / growth
+			}
+			fh.Schema = int32(math.Round(-math.Log2(math.Log2(growth))))
+			schemaKnown = true
+		}
+		idx := int32(math.Round(math.Log2(bound) * math.Exp2(float64(fh.Schema))))
+		if lower > 0 {
+			positive[idx] += float64(b.Count)
+		} else {
+			negative[idx] += float64(b.Count)
+		}
+	}
+	fh.PositiveSpans, fh.PositiveBuckets = HistogramSpans(positive)
+	fh.NegativeSpans, fh.NegativeBuckets = HistogramSpans(negative)
+	return fh
+}
+
+// HistogramSpans converts the counts by bucket index of an exponential schema
+// into the spans and buckets of a native histogram.
+func HistogramSpans(counts map[int32]float64) ([]histogram.Span, []float64) {
+	if len(counts) == 0 {
+		return nil, nil
+	}
+	keys := make([]int32, 0, len(counts))
+	for k := range counts {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+
+	var spans []histogram.Span
+	buckets := make([]float64, 0, len(keys))
+	for i, k := range keys {
+		switch {
+		case i == 0:
+			spans = append(spans, histogram.Span{Offset: k})
+		case k != keys[i-1]+1:
+			spans = append(spans, histogram.Span{Offset: k - keys[i-1] - 1})
+		}
+		spans[len(spans)-1].Length++
+		buckets = append(buckets, counts[k])
+	}
+	return spans, buckets
+}
@@ -0,0 +1,48 @@
+package logproto
+
+import (
+	"testing"
+
+	"github.com/prometheus/prometheus/model/histogram"
+	"github.com/stretchr/testify/require"
+)
+
+func testFloatHistogram() *histogram.FloatHistogram {
+	return &histogram.FloatHistogram{
+		CounterResetHint: histogram.GaugeType,
+		Schema:           3,
+		Count:            9,
+		Sum:              12.5,
+		ZeroThreshold:    1e-128,
+		ZeroCount:        1,
+		PositiveSpans:    []histogram.Span{{Offset: -5, Length: 2}, {Offset: 4, Length: 1}},
+		PositiveBuckets:  []float64{1, 2, 3},
+		NegativeSpans:    []histogram.Span{{Offset: 8, Length: 1}},
+		NegativeBuckets:  []float64{2},
+	}
+}
+
+func TestSampleHistogram_Marshal(t *testing.T) {
+	h := NewSampleHistogram(1000, testFloatHistogram())
+
+	b, err := h.Marshal()
+	require.NoError(t, err)
+	require.Equal(t, h.Size(), len(b))
+
+	var out SampleHistogram
+	require.NoError(t, out.Unmarshal(b))
+	require.True(t, h.Equal(out))
+	require.Equal(t, int64(1000), out.Timestamp)
+	require.True(t, testFloatHistogram().Equals(out.FloatHistogram()))
+}
+
+func TestFloatHistogramModelRoundtrip(t *testing.T) {
+	h := testFloatHistogram()
+
+	m := FromFloatHistogramToModel(h)
+	require.Equal(t, 9.0, float64(m.Count))
+	require.Equal(t, 12.5, float64(m.Sum))
+	require.Len(t, m.Buckets, 5)
+
+	require.True(t, h.Equals(FromModelToFloatHistogram(m)))
+}
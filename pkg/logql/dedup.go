@@ -0,0 +1,178 @@
+package logql
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/grafana/loki/v3/pkg/iter"
+	"github.com/grafana/loki/v3/pkg/logproto"
+	"github.com/grafana/loki/v3/pkg/logql/syntax"
+)
+
+// dedupPageParams overrides the time range and the limit of the query params
+// to fetch one page of the log lines of a query with a dedup stage.
+type dedupPageParams struct {
+	Params
+	start, end time.Time
+	limit      uint32
+}
+
+func (p dedupPageParams) Start() time.Time { return p.start }
+func (p dedupPageParams) End() time.Time   { return p.end }
+func (p dedupPageParams) Limit() uint32    { return p.limit }
+
+// NewDedupIterator returns an iterator over the deduplicated log lines of a
+// query with a dedup stage. Since duplicates don't count towards the limit of
+// the query, the log lines are fetched in pages of DedupLimitFactor times the
+// limit, each starting at the timestamp of the last line of the previous one,
+// until the range of the query is exhausted. Pages are only fetched when the
+// lines of the previous one are consumed.
+func NewDedupIterator(ctx context.Context, ev EntryEvaluatorFactory, expr syntax.LogSelectorExpr, params Params, dedup *syntax.DedupExpr) iter.EntryIterator {
+	limit := uint64(params.Limit()) * DedupLimitFactor
+	if limit > math.MaxUint32 {
+		limit = math.MaxUint32
+	}
+	it := &dedupPageIterator{
+		ctx:       ctx,
+		ev:        ev,
+		expr:      expr,
+		params:    params,
+		pageLimit: uint32(limit),
+		start:     params.Start(),
+		end:       params.End(),
+	}
+	return iter.NewDedupIterator(it, dedup.By, dedup.Window)
+}
+
+// dedupPageIterator iterates over the log lines of a query page by page.
+type dedupPageIterator struct {
+	ctx       context.Context
+	ev        EntryEvaluatorFactory
+	expr      syntax.LogSelectorExpr
+	params    Params
+	pageLimit uint32
+
+	// start and end are the time range of the next page.
+	start, end time.Time
+	exhausted  bool
+
+	page iter.EntryIterator
+	// fetched and returned are the number of lines of the current page that
+	// were fetched and returned.
+	fetched, returned uint32
+
+	// lastTs is the timestamp of the last returned line, and last counts the
+	// returned lines with that timestamp. Since pages overlap at that
+	// timestamp, the lines are skipped once when they are fetched again.
+	lastTs time.Time
+	last   map[string]int
+	skip   map[string]int
+
+	err error
+}
+
+func (i *dedupPageIterator) Next() bool {
+	for {
+		if i.page == nil {
+			if i.exhausted || !i.nextPage() {
+				return false
+			}
+		}
+		if !i.page.Next() {
+			if err := i.page.Err(); err != nil {
+				i.err = err
+				return false
+			}
+			if err := i.closePage(); err != nil {
+				i.err = err
+				return false
+			}
+			continue
+		}
+		i.fetched++
+
+		entry := i.page.At()
+		key := i.page.Labels() + "\x00" + entry.Line
+		if entry.Timestamp.Equal(i.lastTs) && i.skip[key] > 0 {
+			i.skip[key]--
+			continue
+		}
+		if !entry.Timestamp.Equal(i.lastTs) {
+			i.lastTs = entry.Timestamp
+			i.last = make(map[string]int)
+			i.skip = nil
+		}
+		i.last[key]++
+		i.returned++
+		return true
+	}
+}
+
+// nextPage fetches the next page. It returns false if that fails.
+func (i *dedupPageIterator) nextPage() bool {
+	page, err := i.ev.NewIterator(i.ctx, i.expr, dedupPageParams{
+		Params: i.params,
+		start:  i.start,
+		end:    i.end,
+		limit:  i.pageLimit,
+	})
+	if err != nil {
+		i.err = err
+		return false
+	}
+	i.page = page
+	i.fetched, i.returned = 0, 0
+	i.skip = make(map[string]int, len(i.last))
+	for key, count := range i.last {
+		i.skip[key] = count
+	}
+	return true
+}
+
+// closePage closes the current page and sets the time range of the next one.
+func (i *dedupPageIterator) closePage() error {
+	err := i.page.Close()
+	i.page = nil
+
+	// A page with less lines than its limit contains the remaining lines of
+	// the range.
+	if i.fetched < i.pageLimit || i.lastTs.IsZero() {
+		i.exhausted = true
+		return err
+	}
+	// If a page only contains lines that were already returned, all of them
+	// have the same timestamp, and the next page has to start after it.
+	next := i.lastTs
+	if i.returned == 0 {
+		if i.params.Direction() == logproto.FORWARD {
+			next = next.Add(time.Nanosecond)
+		} else {
+			next = next.Add(-time.Nanosecond)
+		}
+	}
+	// The end of the range is exclusive.
+	if i.params.Direction() == logproto.FORWARD {
+		i.start = next
+	} else {
+		i.end = next.Add(time.Nanosecond)
+	}
+	if !i.start.Before(i.end) {
+		i.exhausted = true
+	}
+	return err
+}
+
+func (i *dedupPageIterator) At() logproto.Entry { return i.page.At() }
+func (i *dedupPageIterator) Labels() string     { return i.page.Labels() }
+func (i *dedupPageIterator) StreamHash() uint64 { return i.page.StreamHash() }
+func (i *dedupPageIterator) Err() error         { return i.err }
+
+func (i *dedupPageIterator) Close() error {
+	if i.page == nil {
+		return nil
+	}
+	err := i.page.Close()
+	i.page = nil
+	return err
+}
@@ -272,6 +272,32 @@ func (c *ConcatLogSelectorExpr) Pretty(level int) string {
 	return s
 }
 
+// DedupLogSelectorExpr deduplicates the merged log lines of downstream
+// queries, since duplicates can be in different shards.
+type DedupLogSelectorExpr struct {
+	syntax.LogSelectorExpr
+	dedup *syntax.DedupExpr
+}
+
+func (e *DedupLogSelectorExpr) String() string {
+	return fmt.Sprintf("dedup<%s %s>", e.LogSelectorExpr.String(), e.dedup.String())
+}
+
+// DedupLogSelectorExpr has no representation in LogQL. Its prettified version
+// is e.g. `dedup<concat(downstream<{foo="bar"}, shard=1_of_3>) | dedup 5s>`
+func (e *DedupLogSelectorExpr) Pretty(level int) string {
+	s := syntax.Indent(level)
+	if !syntax.NeedSplit(e) {
+		return s + e.String()
+	}
+
+	s += "dedup<\n"
+	s += e.LogSelectorExpr.Pretty(level + 1)
+	s += "\n" + syntax.Indent(level+1) + e.dedup.String() + "\n"
+	s += syntax.Indent(level) + ">"
+	return s
+}
+
 // QuantileSketchEvalExpr evaluates a quantile sketch to the actual quantile.
 type QuantileSketchEvalExpr struct {
 	syntax.SampleExpr
@@ -777,6 +803,11 @@ func (ev *DownstreamEvaluator) NewIterator(
 
 		return iter.NewSortEntryIterator(xs, params.Direction()), nil
 
+	case *DedupLogSelectorExpr:
+		// The shards are queried page by page until there are enough log
+		// lines, since duplicates don't count towards the limit.
+		return NewDedupIterator(ctx, ev, e.LogSelectorExpr, params, e.dedup), nil
+
 	default:
 		return nil, EvaluatorUnsupportedType(expr, ev)
 	}
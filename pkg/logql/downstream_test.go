@@ -58,6 +58,8 @@ func TestMappingEquivalence(t *testing.T) {
 		{`max(count(rate({a=~".+"}[1s])))`, false, nil},
 		{`max(sum by (cluster) (rate({a=~".+"}[1s]))) / count(rate({a=~".+"}[1s]))`, false, nil},
 		{`sum(rate({a=~".+"} |= "foo" != "foo"[1s]) or vector(1))`, false, nil},
+		{`sum by (a) (count_over_time({a=~".+"} | sample 0.5 [1s]))`, false, nil},
+		{`sum(rate({a=~".+"} | sample 0.5 by (a) --extrapolate [1s]))`, false, nil},
 		{`avg_over_time({a=~".+"} | logfmt | unwrap value [1s])`, false, nil},
 		{`avg_over_time({a=~".+"} | logfmt | unwrap value [1s]) by (a)`, true, nil},
 		{`avg_over_time({a=~".+"} | logfmt | unwrap value [1s]) without (stream)`, true, nil},
@@ -74,7 +76,13 @@ func TestMappingEquivalence(t *testing.T) {
 			false,
 			nil,
 		},
+		{`max_over_time(sum(rate({a=~".+"}[1s]))[5s:1s])`, false, nil},
+		{`avg_over_time(sum by (a) (count_over_time({a=~".+"}[1s]))[4s:2s] offset 1s)`, false, nil},
+		{`max(quantile_over_time(0.5, sum by (a) (rate({a=~".+"}[1s]))[5s:]))`, false, nil},
 		{`first_over_time({a=~".+"} | logfmt | unwrap value [1s])`, false, []string{ShardFirstOverTime}},
+		{`abs(sum by (a) (rate({a=~".+"}[1s])))`, false, nil},
+		{`clamp_min(sum(rate({a=~".+"}[1s])), 1)`, false, nil},
+		{`count_values("value", sum by (a) (count_over_time({a=~".+"}[2s])))`, false, nil},
 		{`first_over_time({a=~".+"} | logfmt | unwrap value [1s]) by (a)`, false, []string{ShardFirstOverTime}},
 		{`first_over_time({a=~".+"} | logfmt | unwrap value [1s] offset 2s) by (a)`, false, []string{ShardFirstOverTime}},
 		{`first_over_time({a=~".+"} | logfmt | unwrap value [1s] offset -2s) by (a)`, false, []string{ShardFirstOverTime}},
@@ -689,6 +697,9 @@ func TestRangeMappingEquivalence(t *testing.T) {
 		// label_replace
 		{`label_replace(sum by (a) (count_over_time({a=~".+"}[3s])), "", "", "", "")`, time.Second},
 		{`label_replace(sum by (a) (count_over_time({a=~".+"}[3s])), "foo", "$1", "a", "(.*)")`, time.Second},
+
+		// functions
+		{`round(sum by (a) (count_over_time({a=~".+"}[3s])), 2)`, time.Second},
 	} {
 		q := NewMockQuerier(
 			shards,
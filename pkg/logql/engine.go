@@ -22,6 +22,7 @@ import (
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
 	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/histogram"
 	"github.com/prometheus/prometheus/model/labels"
 	"github.com/prometheus/prometheus/promql"
 	promql_parser "github.com/prometheus/prometheus/promql/parser"
@@ -30,6 +31,7 @@ import (
 
 	"github.com/grafana/loki/v3/pkg/iter"
 	"github.com/grafana/loki/v3/pkg/logproto"
+	logqllog "github.com/grafana/loki/v3/pkg/logql/log"
 	"github.com/grafana/loki/v3/pkg/logql/syntax"
 	"github.com/grafana/loki/v3/pkg/logqlmodel"
 	"github.com/grafana/loki/v3/pkg/logqlmodel/stats"
@@ -160,11 +162,15 @@ type EngineOpts struct {
 	// MaxCountMinSketchHeapSize is the maximum number of labels the heap for a topk query using a count min sketch
 	// can track. This impacts the memory usage and accuracy of a sharded probabilistic topk query.
 	MaxCountMinSketchHeapSize int `yaml:"max_count_min_sketch_heap_size"`
+
+	// GeoIP configures the MaxMind databases used by the geoip stage.
+	GeoIP logqllog.GeoIPConfig `yaml:"geoip"`
 }
 
 func (opts *EngineOpts) RegisterFlagsWithPrefix(prefix string, f *flag.FlagSet) {
 	f.DurationVar(&opts.MaxLookBackPeriod, prefix+"max-lookback-period", 30*time.Second, "The maximum amount of time to look back for log lines. Used only for instant log queries.")
 	f.IntVar(&opts.MaxCountMinSketchHeapSize, prefix+"max-count-min-sketch-heap-size", 10_000, "The maximum number of labels the heap of a topk query using a count min sketch can track.")
+	opts.GeoIP.RegisterFlagsWithPrefix(prefix+"geoip.", f)
 
 	// Log executing query by default
 	opts.LogExecutingQuery = true
@@ -340,9 +346,17 @@ func (q *query) Eval(ctx context.Context) (promql_parser.Value, error) {
 		return value, err
 
 	case syntax.LogSelectorExpr:
-		itr, err := q.evaluator.NewIterator(ctx, e, q.params)
-		if err != nil {
-			return nil, err
+		// Duplicates can come from different ingesters or chunks, so they are
+		// collapsed after merging the log lines.
+		var itr iter.EntryIterator
+		if dedup := syntax.ExtractDedup(e); dedup != nil {
+			itr = NewDedupIterator(ctx, q.evaluator, e, q.params, dedup)
+		} else {
+			var err error
+			itr, err = q.evaluator.NewIterator(ctx, e, q.params)
+			if err != nil {
+				return nil, err
+			}
 		}
 
 		encodingFlags := httpreq.ExtractEncodingFlagsFromCtx(ctx)
@@ -462,10 +476,17 @@ func vectorsToSeriesWithLimit(vec promql.Vector, sm map[uint64]promql.Series, ma
 			}
 			sm[hash] = series
 		}
-		series.Floats = append(series.Floats, promql.FPoint{
-			T: p.T,
-			F: p.F,
-		})
+		if p.H != nil {
+			series.Histograms = append(series.Histograms, promql.HPoint{
+				T: p.T,
+				H: p.H,
+			})
+		} else {
+			series.Floats = append(series.Floats, promql.FPoint{
+				T: p.T,
+				F: p.F,
+			})
+		}
 		sm[hash] = series
 	}
 	return limitExceeded
@@ -804,6 +825,7 @@ type groupedAggregation struct {
 	groupCount  int
 	heap        vectorByValueHeap
 	reverseHeap vectorByReverseValueHeap
+	histogram   *histogram.FloatHistogram
 }
 
 func (q *query) evalVariants(
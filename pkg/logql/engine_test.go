@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"math"
+	"sort"
 	"strings"
 	"testing"
 	"time"
@@ -2294,6 +2295,99 @@ func TestEngine_RangeQuery(t *testing.T) {
 				},
 			},
 		},
+		{
+			`clamp_max(sum by (app) (rate({app="foo"}[1m])), 0.5)`,
+			time.Unix(60, 0), time.Unix(180, 0), 30 * time.Second, 0, logproto.FORWARD, 100,
+			[][]logproto.Series{
+				{newSeries(testSize, identity, `{app="foo"}`)},
+			},
+			[]SelectSampleParams{
+				{&logproto.SampleQueryRequest{Start: time.Unix(0, 0), End: time.Unix(180, 0), Selector: `sum by (app) (rate({app="foo"}[1m]))`}},
+			},
+			promql.Matrix{
+				promql.Series{
+					Metric: labels.FromStrings("app", "foo"),
+					Floats: []promql.FPoint{{T: 60 * 1000, F: 0.5}, {T: 90 * 1000, F: 0.5}, {T: 120 * 1000, F: 0.5}, {T: 150 * 1000, F: 0.5}, {T: 180 * 1000, F: 0.5}},
+				},
+			},
+		},
+		{
+			`time()`,
+			time.Unix(60, 0), time.Unix(180, 0), 30 * time.Second, 0, logproto.FORWARD, 100,
+			[][]logproto.Series{},
+			[]SelectSampleParams{},
+			promql.Matrix{
+				promql.Series{
+					Metric: labels.EmptyLabels(),
+					Floats: []promql.FPoint{{T: 60 * 1000, F: 60}, {T: 90 * 1000, F: 90}, {T: 120 * 1000, F: 120}, {T: 150 * 1000, F: 150}, {T: 180 * 1000, F: 180}},
+				},
+			},
+		},
+		{
+			`count_values("value", sum by (app) (count_over_time({app=~"foo|bar"}[1m])))`,
+			time.Unix(60, 0), time.Unix(120, 0), 30 * time.Second, 0, logproto.FORWARD, 100,
+			[][]logproto.Series{
+				{
+					newSeries(testSize, factor(10, identity), `{app="foo"}`),
+					newSeries(testSize, factor(10, identity), `{app="bar"}`),
+				},
+			},
+			[]SelectSampleParams{
+				{&logproto.SampleQueryRequest{Start: time.Unix(0, 0), End: time.Unix(120, 0), Selector: `sum by (app) (count_over_time({app=~"foo|bar"}[1m]))`}},
+			},
+			promql.Matrix{
+				promql.Series{
+					Metric: labels.FromStrings("value", "6"),
+					Floats: []promql.FPoint{{T: 60 * 1000, F: 2}, {T: 90 * 1000, F: 2}, {T: 120 * 1000, F: 2}},
+				},
+			},
+		},
+		{
+			`deriv({app="foo"} | unwrap foo [30s])`,
+			time.Unix(60, 0), time.Unix(120, 0), 30 * time.Second, 0, logproto.FORWARD, 100,
+			[][]logproto.Series{
+				{newSeries(testSize, incValue(10), `{app="foo"}`)},
+			},
+			[]SelectSampleParams{
+				{&logproto.SampleQueryRequest{Start: time.Unix(30, 0), End: time.Unix(120, 0), Selector: `deriv({app="foo"} | unwrap foo[30s])`}},
+			},
+			promql.Matrix{
+				promql.Series{
+					Metric: labels.FromStrings("app", "foo"),
+					Floats: []promql.FPoint{{T: 60 * 1000, F: 1}, {T: 90 * 1000, F: 1}, {T: 120 * 1000, F: 1}},
+				},
+			},
+		},
+		{
+			`predict_linear({app="foo"} | unwrap foo [30s], 60)`,
+			time.Unix(60, 0), time.Unix(120, 0), 30 * time.Second, 0, logproto.FORWARD, 100,
+			[][]logproto.Series{
+				{newSeries(testSize, incValue(10), `{app="foo"}`)},
+			},
+			[]SelectSampleParams{
+				{&logproto.SampleQueryRequest{Start: time.Unix(30, 0), End: time.Unix(120, 0), Selector: `predict_linear({app="foo"} | unwrap foo[30s], 60)`}},
+			},
+			promql.Matrix{
+				promql.Series{
+					Metric: labels.FromStrings("app", "foo"),
+					Floats: []promql.FPoint{{T: 60 * 1000, F: 130}, {T: 90 * 1000, F: 160}, {T: 120 * 1000, F: 190}},
+				},
+			},
+		},
+		{
+			`absent(sum(rate({app="foo"}[1m])))`,
+			time.Unix(60, 0), time.Unix(120, 0), 30 * time.Second, 0, logproto.FORWARD, 100,
+			[][]logproto.Series{{}},
+			[]SelectSampleParams{
+				{&logproto.SampleQueryRequest{Start: time.Unix(0, 0), End: time.Unix(120, 0), Selector: `sum(rate({app="foo"}[1m]))`}},
+			},
+			promql.Matrix{
+				promql.Series{
+					Metric: labels.FromStrings("app", "foo"),
+					Floats: []promql.FPoint{{T: 60 * 1000, F: 1}, {T: 90 * 1000, F: 1}, {T: 120 * 1000, F: 1}},
+				},
+			},
+		},
 	} {
 		t.Run(fmt.Sprintf("%s %s", test.qs, test.direction), func(t *testing.T) {
 			t.Parallel()
@@ -3046,6 +3140,183 @@ func (e errorIteratorQuerier) SelectSamples(_ context.Context, _ SelectSamplePar
 	return iter.NewSortSampleIterator(e.samples()), nil
 }
 
+func TestEngine_Dedup(t *testing.T) {
+	streams := []logproto.Stream{
+		{Labels: `{app="a", request_id="1"}`, Entries: []logproto.Entry{{Timestamp: time.Unix(1, 0), Line: "x"}}},
+		{Labels: `{app="b", request_id="1"}`, Entries: []logproto.Entry{{Timestamp: time.Unix(2, 0), Line: "y"}}},
+		{Labels: `{app="c", request_id="2"}`, Entries: []logproto.Entry{{Timestamp: time.Unix(2, 0), Line: "z"}}},
+	}
+	// The querier returns every stream for every shard, so every shard returns
+	// duplicates of the same entries.
+	querier := errorIteratorQuerier{
+		entries: func() []iter.EntryIterator {
+			its := make([]iter.EntryIterator, 0, len(streams))
+			for _, s := range streams {
+				its = append(its, iter.NewStreamIterator(s))
+			}
+			return its
+		},
+	}
+	regular := NewEngine(EngineOpts{}, querier, NoLimits, log.NewNopLogger())
+	sharded := NewDownstreamEngine(EngineOpts{}, MockDownstreamer{regular}, NoLimits, log.NewNopLogger())
+
+	params, err := NewLiteralParams(`{app=~".+"} | dedup 1s by (request_id)`, time.Unix(0, 0), time.Unix(10, 0), 0, 0, logproto.FORWARD, 100, nil, nil)
+	require.NoError(t, err)
+	ctx := user.InjectOrgID(context.Background(), "fake")
+
+	expected := func(shards int) []string {
+		return []string{
+			fmt.Sprintf(`{__dup_count__="%d", app="c", request_id="2"} 2 z`, shards),
+			fmt.Sprintf(`{__dup_count__="%d", app="a", request_id="1"} 1 x`, 2*shards),
+		}
+	}
+	entries := func(streams logqlmodel.Streams) []string {
+		var res []string
+		for _, s := range streams {
+			for _, e := range s.Entries {
+				res = append(res, fmt.Sprintf("%s %d %s", s.Labels, e.Timestamp.Unix(), e.Line))
+			}
+		}
+		sort.Strings(res)
+		return res
+	}
+
+	res, err := regular.Query(params).Exec(ctx)
+	require.NoError(t, err)
+	require.Equal(t, expected(1), entries(res.Data.(logqlmodel.Streams)))
+
+	// The sharded query deduplicates the entries after merging the shards.
+	mapper := NewShardMapper(NewPowerOfTwoStrategy(ConstantShards(2)), nilShardMetrics, nil)
+	_, _, mapped, err := mapper.Parse(params.GetExpression())
+	require.NoError(t, err)
+	res, err = sharded.Query(ctx, ParamsWithExpressionOverride{Params: params, ExpressionOverride: mapped}).Exec(ctx)
+	require.NoError(t, err)
+	require.Equal(t, expected(2), entries(res.Data.(logqlmodel.Streams)))
+}
+
+// limitedQuerier returns at most the limit of log lines of the query, like
+// ingesters and the store do.
+type limitedQuerier struct {
+	Querier
+}
+
+func (q limitedQuerier) SelectLogs(ctx context.Context, p SelectLogParams) (iter.EntryIterator, error) {
+	it, err := q.Querier.SelectLogs(ctx, p)
+	if err != nil {
+		return nil, err
+	}
+	return &limitedEntryIterator{EntryIterator: it, limit: p.Limit}, nil
+}
+
+type limitedEntryIterator struct {
+	iter.EntryIterator
+	limit, n uint32
+}
+
+func (it *limitedEntryIterator) Next() bool {
+	if it.n >= it.limit {
+		return false
+	}
+	it.n++
+	return it.EntryIterator.Next()
+}
+
+func TestEngine_Dedup_Limit(t *testing.T) {
+	// Every line is logged by 3 apps at the same time.
+	var streams []logproto.Stream
+	for _, app := range []string{"a", "b", "c"} {
+		s := logproto.Stream{Labels: fmt.Sprintf(`{app=%q}`, app)}
+		for i := int64(1); i <= 5; i++ {
+			s.Entries = append(s.Entries, logproto.Entry{Timestamp: time.Unix(i, 0), Line: fmt.Sprintf("line %d", i)})
+		}
+		streams = append(streams, s)
+	}
+	regular := NewEngine(EngineOpts{}, limitedQuerier{NewMockQuerier(2, streams)}, NoLimits, log.NewNopLogger())
+	sharded := NewDownstreamEngine(EngineOpts{}, MockDownstreamer{regular}, NoLimits, log.NewNopLogger())
+
+	params, err := NewLiteralParams(`{app=~".+"} | dedup`, time.Unix(0, 0), time.Unix(10, 0), 0, 0, logproto.FORWARD, 3, nil, nil)
+	require.NoError(t, err)
+	ctx := user.InjectOrgID(context.Background(), "fake")
+
+	lines := func(streams logqlmodel.Streams) []string {
+		var res []string
+		for _, s := range streams {
+			for _, e := range s.Entries {
+				res = append(res, e.Line)
+			}
+		}
+		sort.Strings(res)
+		return res
+	}
+
+	// Duplicates don't count towards the limit, so more log lines than the
+	// limit are fetched.
+	res, err := regular.Query(params).Exec(ctx)
+	require.NoError(t, err)
+	require.Equal(t, []string{"line 1", "line 2", "line 3"}, lines(res.Data.(logqlmodel.Streams)))
+
+	mapper := NewShardMapper(NewPowerOfTwoStrategy(ConstantShards(2)), nilShardMetrics, nil)
+	_, _, mapped, err := mapper.Parse(params.GetExpression())
+	require.NoError(t, err)
+	res, err = sharded.Query(ctx, ParamsWithExpressionOverride{Params: params, ExpressionOverride: mapped}).Exec(ctx)
+	require.NoError(t, err)
+	require.Equal(t, []string{"line 1", "line 2", "line 3"}, lines(res.Data.(logqlmodel.Streams)))
+}
+
+func TestEngine_Dedup_Pages(t *testing.T) {
+	// Every line is logged by 10 apps at the same time, so the first page of
+	// log lines only contains duplicates of two lines.
+	var streams []logproto.Stream
+	for app := 0; app < 10; app++ {
+		s := logproto.Stream{Labels: fmt.Sprintf(`{app="%d"}`, app)}
+		for i := int64(1); i <= 5; i++ {
+			s.Entries = append(s.Entries, logproto.Entry{Timestamp: time.Unix(i, 0), Line: fmt.Sprintf("line %d", i)})
+		}
+		streams = append(streams, s)
+	}
+	regular := NewEngine(EngineOpts{}, limitedQuerier{NewMockQuerier(2, streams)}, NoLimits, log.NewNopLogger())
+	sharded := NewDownstreamEngine(EngineOpts{}, MockDownstreamer{regular}, NoLimits, log.NewNopLogger())
+	ctx := user.InjectOrgID(context.Background(), "fake")
+
+	lines := func(streams logqlmodel.Streams) []string {
+		var res []string
+		for _, s := range streams {
+			for _, e := range s.Entries {
+				res = append(res, e.Line)
+			}
+		}
+		sort.Strings(res)
+		return res
+	}
+
+	for _, tc := range []struct {
+		direction logproto.Direction
+		limit     uint32
+		expected  []string
+	}{
+		{logproto.FORWARD, 3, []string{"line 1", "line 2", "line 3"}},
+		{logproto.BACKWARD, 3, []string{"line 3", "line 4", "line 5"}},
+		// The range is exhausted before the limit is reached.
+		{logproto.FORWARD, 10, []string{"line 1", "line 2", "line 3", "line 4", "line 5"}},
+	} {
+		t.Run(fmt.Sprintf("%s limit %d", tc.direction, tc.limit), func(t *testing.T) {
+			params, err := NewLiteralParams(`{app=~".+"} | dedup`, time.Unix(0, 0), time.Unix(10, 0), 0, 0, tc.direction, tc.limit, nil, nil)
+			require.NoError(t, err)
+
+			res, err := regular.Query(params).Exec(ctx)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, lines(res.Data.(logqlmodel.Streams)))
+
+			mapper := NewShardMapper(NewPowerOfTwoStrategy(ConstantShards(2)), nilShardMetrics, nil)
+			_, _, mapped, err := mapper.Parse(params.GetExpression())
+			require.NoError(t, err)
+			res, err = sharded.Query(ctx, ParamsWithExpressionOverride{Params: params, ExpressionOverride: mapped}).Exec(ctx)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, lines(res.Data.(logqlmodel.Streams)))
+		})
+	}
+}
+
 func TestMultiVariantQueries_Limits(t *testing.T) {
 	variantQuery := `variants(bytes_over_time({app="foo"}[1m]), count_over_time({app="foo"}[1m])) of ({app="foo"}[1m])`
 	testTime := time.Unix(60, 0)
@@ -217,6 +217,22 @@ func (p ParamsWithChunkOverrides) GetStoreChunks() *logproto.ChunkRefGroup {
 	return p.StoreChunksOverride
 }
 
+// ParamsWithLimitOverride overrides the limit of log lines of a query.
+type ParamsWithLimitOverride struct {
+	Params
+	LimitOverride uint32
+}
+
+// Limit returns the overriding limit.
+func (p ParamsWithLimitOverride) Limit() uint32 {
+	return p.LimitOverride
+}
+
+// DedupLimitFactor is the factor by which the limit of the pages of log lines
+// fetched for a query with a dedup stage is larger than the limit of the
+// query, since duplicates don't count towards it.
+const DedupLimitFactor = 4
+
 func ParamOverridesFromShard(base Params, shard *ShardWithChunkRefs) (result Params) {
 	if shard == nil {
 		return base
@@ -381,10 +397,14 @@ func (ev *DefaultEvaluator) NewStepEvaluator(
 			return nil, err
 		}
 		return newRangeAggEvaluator(iter.NewPeekingSampleIterator(it), e, q, e.Left.Offset)
+	case *syntax.SubqueryAggregationExpr:
+		return newSubqueryAggEvaluator(ctx, nextEvFactory, e, q)
 	case *syntax.BinOpExpr:
 		return newBinOpStepEvaluator(ctx, nextEvFactory, e, q)
 	case *syntax.LabelReplaceExpr:
 		return newLabelReplaceEvaluator(ctx, nextEvFactory, e, q)
+	case *syntax.FunctionExpr:
+		return newFunctionEvaluator(ctx, nextEvFactory, e, q)
 	case *syntax.VectorExpr:
 		val, err := e.Value()
 		if err != nil {
@@ -416,9 +436,17 @@ func newVectorAggEvaluator(
 		return newCountMinSketchVectorAggEvaluator(nextEvaluator, expr, maxCountMinSketchHeapSize)
 	}
 
+	groups := expr.Grouping.Groups
+	if expr.Operation == syntax.OpTypeCountValues && !expr.Grouping.Without {
+		// count_values keeps the label of the counted values in addition to the grouping labels.
+		groups = append([]string{expr.Label}, groups...)
+		sort.Strings(groups)
+	}
+
 	return &VectorAggEvaluator{
 		nextEvaluator: nextEvaluator,
 		expr:          expr,
+		groups:        groups,
 		buf:           make([]byte, 0, 1024),
 		lb:            labels.NewBuilder(labels.EmptyLabels()),
 	}, nil
@@ -427,8 +455,10 @@ func newVectorAggEvaluator(
 type VectorAggEvaluator struct {
 	nextEvaluator StepEvaluator
 	expr          *syntax.VectorAggregationExpr
+	groups        []string
 	buf           []byte
 	lb            *labels.Builder
+	err           error
 }
 
 func (e *VectorAggEvaluator) Next() (bool, int64, StepResult) {
@@ -446,12 +476,17 @@ func (e *VectorAggEvaluator) Next() (bool, int64, StepResult) {
 	}
 	for _, s := range vec {
 		metric := s.Metric
+		if e.expr.Operation == syntax.OpTypeCountValues {
+			e.lb.Reset(metric)
+			e.lb.Set(e.expr.Label, strconv.FormatFloat(s.F, 'f', -1, 64))
+			metric = e.lb.Labels()
+		}
 
 		var groupingKey uint64
 		if e.expr.Grouping.Without {
-			groupingKey, e.buf = metric.HashWithoutLabels(e.buf, e.expr.Grouping.Groups...)
+			groupingKey, e.buf = metric.HashWithoutLabels(e.buf, e.groups...)
 		} else {
-			groupingKey, e.buf = metric.HashForLabels(e.buf, e.expr.Grouping.Groups...)
+			groupingKey, e.buf = metric.HashForLabels(e.buf, e.groups...)
 		}
 		group, ok := result[groupingKey]
 		// Add a new group if it doesn't exist.
@@ -460,13 +495,13 @@ func (e *VectorAggEvaluator) Next() (bool, int64, StepResult) {
 
 			if e.expr.Grouping.Without {
 				e.lb.Reset(metric)
-				e.lb.Del(e.expr.Grouping.Groups...)
+				e.lb.Del(e.groups...)
 				e.lb.Del(labels.MetricName)
 				m = e.lb.Labels()
 			} else {
-				b := labels.NewScratchBuilder(len(e.expr.Grouping.Groups))
+				b := labels.NewScratchBuilder(len(e.groups))
 				metric.Range(func(l labels.Label) {
-					for _, n := range e.expr.Grouping.Groups {
+					for _, n := range e.groups {
 						if l.Name == n {
 							b.Add(l.Name, l.Value)
 							break
@@ -481,6 +516,9 @@ func (e *VectorAggEvaluator) Next() (bool, int64, StepResult) {
 				mean:       s.F,
 				groupCount: 1,
 			}
+			if s.H != nil && e.expr.Operation == syntax.OpTypeSum {
+				result[groupingKey].histogram = s.H.Copy()
+			}
 
 			inputVecLen := len(vec)
 			resultSize := e.expr.Params
@@ -519,6 +557,15 @@ func (e *VectorAggEvaluator) Next() (bool, int64, StepResult) {
 		}
 		switch e.expr.Operation {
 		case syntax.OpTypeSum:
+			if s.H != nil {
+				if group.histogram == nil {
+					group.histogram = s.H.Copy()
+				} else if _, err := group.histogram.Add(s.H); err != nil {
+					e.err = err
+					return false, 0, SampleVector{}
+				}
+				continue
+			}
 			group.value += s.F
 
 		case syntax.OpTypeAvg:
@@ -535,7 +582,7 @@ func (e *VectorAggEvaluator) Next() (bool, int64, StepResult) {
 				group.value = s.F
 			}
 
-		case syntax.OpTypeCount:
+		case syntax.OpTypeCount, syntax.OpTypeCountValues:
 			group.groupCount++
 
 		case syntax.OpTypeStddev, syntax.OpTypeStdvar:
@@ -585,7 +632,7 @@ func (e *VectorAggEvaluator) Next() (bool, int64, StepResult) {
 		case syntax.OpTypeAvg:
 			aggr.value = aggr.mean
 
-		case syntax.OpTypeCount:
+		case syntax.OpTypeCount, syntax.OpTypeCountValues:
 			aggr.value = float64(aggr.groupCount)
 
 		case syntax.OpTypeStddev:
@@ -617,6 +664,15 @@ func (e *VectorAggEvaluator) Next() (bool, int64, StepResult) {
 				})
 			}
 			continue // Bypass default append.
+		case syntax.OpTypeSum:
+			if aggr.histogram != nil {
+				vec = append(vec, promql.Sample{
+					Metric: aggr.labels,
+					T:      ts,
+					H:      aggr.histogram.Compact(0),
+				})
+				continue // Bypass default append.
+			}
 		default:
 		}
 		vec = append(vec, promql.Sample{
@@ -633,6 +689,9 @@ func (e *VectorAggEvaluator) Close() error {
 }
 
 func (e *VectorAggEvaluator) Error() error {
+	if e.err != nil {
+		return e.err
+	}
 	return e.nextEvaluator.Error()
 }
 
@@ -825,6 +884,25 @@ func newBinOpStepEvaluator(
 		)
 	}
 
+	// time() is a scalar, so like a literal it is matched with all labels in the other leg.
+	if lTime, rTime := isTimeExpr(expr.SampleExpr), isTimeExpr(expr.RHS); lTime != rTime {
+		other := expr.RHS
+		if rTime {
+			other = expr.SampleExpr
+		}
+		nextEv, err := evFactory.NewStepEvaluator(ctx, evFactory, other, q)
+		if err != nil {
+			return nil, err
+		}
+		return &LiteralStepEvaluator{
+			nextEv:     nextEv,
+			stepTime:   true,
+			inverted:   rTime,
+			op:         expr.Op,
+			returnBool: expr.Opts.ReturnBool,
+		}, nil
+	}
+
 	var lse, rse StepEvaluator
 
 	ctx, cancel := context.WithCancelCause(ctx)
@@ -1154,6 +1232,14 @@ type LiteralStepEvaluator struct {
 	inverted   bool
 	op         string
 	returnBool bool
+	// stepTime is set for time(), whose value is the time of each step in seconds.
+	stepTime bool
+}
+
+// isTimeExpr returns whether the expression is time().
+func isTimeExpr(expr syntax.SampleExpr) bool {
+	fn, ok := expr.(*syntax.FunctionExpr)
+	return ok && fn.Function == syntax.OpFuncTime
 }
 
 func (e *LiteralStepEvaluator) Next() (bool, int64, StepResult) {
@@ -1161,6 +1247,10 @@ func (e *LiteralStepEvaluator) Next() (bool, int64, StepResult) {
 	if !ok {
 		return ok, ts, r
 	}
+	val := e.val
+	if e.stepTime {
+		val = float64(ts) / 1e3
+	}
 	vec := r.SampleVector()
 	results := make(promql.Vector, 0, len(vec))
 	for _, sample := range vec {
@@ -1168,7 +1258,7 @@ func (e *LiteralStepEvaluator) Next() (bool, int64, StepResult) {
 		literalPoint := promql.Sample{
 			Metric: sample.Metric,
 			T:      ts,
-			F:      e.val,
+			F:      val,
 		}
 
 		left, right := &literalPoint, &sample
@@ -1315,6 +1405,157 @@ func (e *LabelReplaceEvaluator) Error() error {
 	return e.nextEvaluator.Error()
 }
 
+// newFunctionEvaluator returns an evaluator applying a math or time function to
+// each sample of the inner step evaluator. Functions without argument are
+// applied to the time of each step instead.
+func newFunctionEvaluator(
+	ctx context.Context,
+	evFactory SampleEvaluatorFactory,
+	expr *syntax.FunctionExpr,
+	q Params,
+) (*FunctionEvaluator, error) {
+	var nextEvaluator StepEvaluator
+	if expr.Left == nil {
+		// Functions without argument are applied to the time of the evaluation.
+		nextEvaluator = newVectorIterator(0, q.Step().Milliseconds(), q.Start().UnixMilli(), q.End().UnixMilli())
+	} else {
+		var err error
+		nextEvaluator, err = evFactory.NewStepEvaluator(ctx, evFactory, expr.Left, q)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var lbs labels.Labels
+	if expr.Function == syntax.OpFuncAbsent {
+		var err error
+		lbs, err = absentLabels(expr.Left)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &FunctionEvaluator{
+		nextEvaluator: nextEvaluator,
+		expr:          expr,
+		absentLabels:  lbs,
+		buf:           make([]byte, 0, 1024),
+	}, nil
+}
+
+// FunctionEvaluator applies a function to the result of its inner expression at every step.
+type FunctionEvaluator struct {
+	nextEvaluator StepEvaluator
+	expr          *syntax.FunctionExpr
+	absentLabels  labels.Labels
+	buf           []byte
+}
+
+func (e *FunctionEvaluator) Next() (bool, int64, StepResult) {
+	next, ts, r := e.nextEvaluator.Next()
+	if !next {
+		return false, 0, SampleVector{}
+	}
+	vec := r.SampleVector()
+
+	switch e.expr.Function {
+	case syntax.OpFuncAbsent:
+		if len(vec) > 0 {
+			return next, ts, SampleVector{}
+		}
+		return next, ts, SampleVector{promql.Sample{T: ts, F: 1, Metric: e.absentLabels}}
+	case syntax.OpFuncHistogramQuantile:
+		return next, ts, SampleVector(e.histogramQuantile(ts, vec))
+	}
+
+	for i := range vec {
+		if e.expr.Left == nil || e.expr.Function == syntax.OpFuncTimestamp {
+			// The samples of a step have the timestamp of the step.
+			vec[i].F = float64(ts) / 1e3
+		}
+		vec[i].F = e.apply(vec[i].F)
+	}
+	return next, ts, SampleVector(vec)
+}
+
+// apply returns the result of the function for the value of a single sample.
+func (e *FunctionEvaluator) apply(v float64) float64 {
+	switch e.expr.Function {
+	case syntax.OpFuncAbs:
+		return math.Abs(v)
+	case syntax.OpFuncCeil:
+		return math.Ceil(v)
+	case syntax.OpFuncFloor:
+		return math.Floor(v)
+	case syntax.OpFuncRound:
+		toNearest := 1.0
+		if e.expr.Params != nil {
+			toNearest = *e.expr.Params
+		}
+		// Like in Prometheus, dividing by the inverse is more precise than multiplying.
+		toNearestInverse := 1.0 / toNearest
+		return math.Floor(v*toNearestInverse+0.5) / toNearestInverse
+	case syntax.OpFuncLn:
+		return math.Log(v)
+	case syntax.OpFuncExp:
+		return math.Exp(v)
+	case syntax.OpFuncClampMin:
+		return math.Max(*e.expr.Params, v)
+	case syntax.OpFuncClampMax:
+		return math.Min(*e.expr.Params, v)
+	case syntax.OpFuncDayOfWeek:
+		return float64(time.Unix(int64(v), 0).UTC().Weekday())
+	case syntax.OpFuncHour:
+		return float64(time.Unix(int64(v), 0).UTC().Hour())
+	default:
+		// time() and timestamp() return the time set by Next.
+		return v
+	}
+}
+
+// histogramQuantile calculates the quantile of the histograms made of the series with the same labels but `le`,
+// whose value is the upper bound of their bucket.
+func (e *FunctionEvaluator) histogramQuantile(ts int64, vec promql.Vector) promql.Vector {
+	type histogram struct {
+		metric  labels.Labels
+		buckets promql.Buckets
+	}
+	histograms := map[uint64]*histogram{}
+	var order []uint64
+	for _, s := range vec {
+		upperBound, err := strconv.ParseFloat(s.Metric.Get(labels.BucketLabel), 64)
+		if err != nil {
+			// Series without a valid bucket are ignored, like in Prometheus.
+			continue
+		}
+		var hash uint64
+		hash, e.buf = s.Metric.HashWithoutLabels(e.buf, labels.BucketLabel)
+		h, ok := histograms[hash]
+		if !ok {
+			h = &histogram{metric: labels.NewBuilder(s.Metric).Del(labels.BucketLabel).Labels()}
+			histograms[hash] = h
+			order = append(order, hash)
+		}
+		h.buckets = append(h.buckets, promql.Bucket{UpperBound: upperBound, Count: s.F})
+	}
+
+	result := make(promql.Vector, 0, len(histograms))
+	for _, hash := range order {
+		h := histograms[hash]
+		v, _, _ := promql.BucketQuantile(*e.expr.Params, h.buckets)
+		result = append(result, promql.Sample{T: ts, F: v, Metric: h.metric})
+	}
+	return result
+}
+
+func (e *FunctionEvaluator) Close() error {
+	return e.nextEvaluator.Close()
+}
+
+func (e *FunctionEvaluator) Error() error {
+	return e.nextEvaluator.Error()
+}
+
 // This is to replace missing timeseries during absent_over_time aggregation.
 func absentLabels(expr syntax.SampleExpr) (labels.Labels, error) {
 	m := labels.Labels{}
@@ -1441,6 +1682,7 @@ func (ev *DefaultEvaluator) newVariantsEvaluator(
 					variantEvaluator = &VectorAggEvaluator{
 						nextEvaluator: rangeEvaluator,
 						expr:          e,
+						groups:        e.Grouping.Groups,
 						buf:           make([]byte, 0, 1024),
 						lb:            labels.NewBuilder(labels.EmptyLabels()),
 					}
@@ -14,6 +14,11 @@ func (e *LabelReplaceEvaluator) Explain(parent Node) {
 	e.nextEvaluator.Explain(b)
 }
 
+func (e *FunctionEvaluator) Explain(parent Node) {
+	b := parent.Childf("%s Function", e.expr.Function)
+	e.nextEvaluator.Explain(b)
+}
+
 func (e *VectorAggEvaluator) Explain(parent Node) {
 	b := parent.Childf("[%s, %s] VectorAgg", e.expr.Operation, e.expr.Grouping)
 	e.nextEvaluator.Explain(b)
@@ -48,6 +53,13 @@ func (e *AbsentRangeVectorEvaluator) Explain(parent Node) {
 	parent.Child("Absent RangeVectorAgg")
 }
 
+func (e *SubqueryEvaluator) Explain(parent Node) {
+	b := parent.Child("SubqueryAgg")
+	if e.inner != nil {
+		e.inner.Explain(b)
+	}
+}
+
 func (e *BinOpStepEvaluator) Explain(parent Node) {
 	b := parent.Childf("%s BinOp", e.expr.Op)
 	e.lse.Explain(b)
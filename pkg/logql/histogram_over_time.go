@@ -0,0 +1,64 @@
+package logql
+
+import (
+	"math"
+	"slices"
+
+	"github.com/prometheus/prometheus/model/histogram"
+	"github.com/prometheus/prometheus/promql"
+
+	"github.com/grafana/loki/v3/pkg/logproto"
+)
+
+// histogramOverTimeSchema is the schema of the native histograms returned by
+// histogram_over_time. Schema 3 splits each power of two into 8 exponential
+// buckets, which bounds the relative error of estimated quantiles to ~4.4%.
+const histogramOverTimeSchema = 3
+
+// histogramOverTimeBounds are the lower bounds of the buckets of one power of
+// two, in the range [0.5, 1) of the fraction returned by math.Frexp.
+var histogramOverTimeBounds = func() []float64 {
+	n := 1 << histogramOverTimeSchema
+	bounds := make([]float64, n)
+	for i := range bounds {
+		bounds[i] = math.Exp2(float64(i)/float64(n) - 1)
+	}
+	return bounds
+}()
+
+// histogramOverTime returns the distribution of the sample values as a native
+// histogram. Values that are NaN or infinite are not counted.
+func histogramOverTime(samples []promql.FPoint) *histogram.FloatHistogram {
+	h := &histogram.FloatHistogram{
+		CounterResetHint: histogram.GaugeType,
+		Schema:           histogramOverTimeSchema,
+	}
+	positive := map[int32]float64{}
+	negative := map[int32]float64{}
+	for _, s := range samples {
+		if math.IsNaN(s.F) || math.IsInf(s.F, 0) {
+			continue
+		}
+		h.Count++
+		h.Sum += s.F
+		switch {
+		case s.F > 0:
+			positive[histogramOverTimeBucket(s.F)]++
+		case s.F < 0:
+			negative[histogramOverTimeBucket(-s.F)]++
+		default:
+			h.ZeroCount++
+		}
+	}
+	h.PositiveSpans, h.PositiveBuckets = logproto.HistogramSpans(positive)
+	h.NegativeSpans, h.NegativeBuckets = logproto.HistogramSpans(negative)
+	return h
+}
+
+// histogramOverTimeBucket returns the index of the bucket of a positive value.
+// Bucket i holds the values in (2^((i-1)/8), 2^(i/8)].
+func histogramOverTimeBucket(v float64) int32 {
+	frac, exp := math.Frexp(v)
+	idx, _ := slices.BinarySearch(histogramOverTimeBounds, frac)
+	return int32(idx + (exp-1)*len(histogramOverTimeBounds))
+}
@@ -0,0 +1,107 @@
+package logql
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/grafana/dskit/user"
+	"github.com/prometheus/prometheus/model/histogram"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/promql"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/v3/pkg/logproto"
+)
+
+func TestHistogramOverTime(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		values   []float64
+		expected *histogram.FloatHistogram
+	}{
+		{
+			name:   "empty",
+			values: nil,
+			expected: &histogram.FloatHistogram{
+				CounterResetHint: histogram.GaugeType,
+				Schema:           histogramOverTimeSchema,
+			},
+		},
+		{
+			name:   "bucket bounds",
+			values: []float64{1, 1, 2, 0.6, 1.05},
+			expected: &histogram.FloatHistogram{
+				CounterResetHint: histogram.GaugeType,
+				Schema:           histogramOverTimeSchema,
+				Count:            5,
+				Sum:              5.65,
+				PositiveSpans:    []histogram.Span{{Offset: -5, Length: 1}, {Offset: 4, Length: 2}, {Offset: 6, Length: 1}},
+				PositiveBuckets:  []float64{1, 2, 1, 1},
+			},
+		},
+		{
+			name:   "negative, zero and invalid values",
+			values: []float64{-1, 0, 0, math.NaN(), math.Inf(1), 4},
+			expected: &histogram.FloatHistogram{
+				CounterResetHint: histogram.GaugeType,
+				Schema:           histogramOverTimeSchema,
+				Count:            4,
+				Sum:              3,
+				ZeroCount:        2,
+				PositiveSpans:    []histogram.Span{{Offset: 16, Length: 1}},
+				PositiveBuckets:  []float64{1},
+				NegativeSpans:    []histogram.Span{{Offset: 0, Length: 1}},
+				NegativeBuckets:  []float64{1},
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			samples := make([]promql.FPoint, 0, len(tc.values))
+			for i, v := range tc.values {
+				samples = append(samples, promql.FPoint{T: int64(i), F: v})
+			}
+			h := histogramOverTime(samples)
+			require.InDelta(t, tc.expected.Sum, h.Sum, 1e-9)
+			h.Sum = tc.expected.Sum
+			require.Equal(t, tc.expected, h)
+		})
+	}
+}
+
+func TestEngine_HistogramOverTime(t *testing.T) {
+	qs := `sum(histogram_over_time({app=~"foo|bar"} | unwrap foo [30s]))`
+	data := [][]logproto.Series{
+		{
+			newSeries(testSize, offset(46, constantValue(1)), `{app="foo"}`),
+			newSeries(testSize, offset(46, constantValue(2)), `{app="bar"}`),
+		},
+	}
+	params := []SelectSampleParams{
+		{&logproto.SampleQueryRequest{Start: time.Unix(30, 0), End: time.Unix(60, 0), Selector: qs}},
+	}
+
+	eng := NewEngine(EngineOpts{}, newQuerierRecorder(t, data, params), NoLimits, log.NewNopLogger())
+	p, err := NewLiteralParams(qs, time.Unix(60, 0), time.Unix(60, 0), 0, 0, logproto.FORWARD, 0, nil, nil)
+	require.NoError(t, err)
+	res, err := eng.Query(p).Exec(user.InjectOrgID(context.Background(), "fake"))
+	require.NoError(t, err)
+
+	vec, ok := res.Data.(promql.Vector)
+	require.True(t, ok)
+	require.Len(t, vec, 1)
+	require.Equal(t, labels.EmptyLabels(), vec[0].Metric)
+	require.NotNil(t, vec[0].H)
+
+	expected := &histogram.FloatHistogram{
+		CounterResetHint: histogram.GaugeType,
+		Schema:           histogramOverTimeSchema,
+		Count:            30,
+		Sum:              45,
+		PositiveSpans:    []histogram.Span{{Offset: 0, Length: 1}, {Offset: 7, Length: 1}},
+		PositiveBuckets:  []float64{15, 15},
+	}
+	require.True(t, expected.Equals(vec[0].H), "unexpected histogram %s", vec[0].H)
+}
@@ -4,7 +4,10 @@ var (
 	// Possible errors thrown by a log pipeline.
 	errJSON             = "JSONParserErr"
 	errLogfmt           = "LogfmtParserErr"
+	errCSV              = "CSVParserErr"
+	errXML              = "XMLParserErr"
 	errSampleExtraction = "SampleExtractionErr"
 	errLabelFilter      = "LabelFilterErr"
 	errTemplateFormat   = "TemplateFormatErr"
+	errGeoIP            = "GeoIPErr"
 )
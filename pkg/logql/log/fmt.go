@@ -3,6 +3,7 @@ package log
 import (
 	"bytes"
 	"fmt"
+	"net/netip"
 	"net/url"
 	"strconv"
 	"strings"
@@ -70,6 +71,7 @@ var (
 		"unixToTime":       unixToTime,
 		"alignLeft":        alignLeft,
 		"alignRight":       alignRight,
+		"cidr_match":       cidrMatch,
 	}
 
 	// sprig template functions
@@ -170,6 +172,21 @@ func unixEpochNanos(date time.Time) string {
 	return strconv.FormatInt(date.UnixNano(), 10)
 }
 
+// cidrMatch returns true if the ip address is matched by the pattern, which
+// can be a single ip address, an ip range or a CIDR like in the ip filter.
+// A value that isn't an ip address is never matched.
+func cidrMatch(pattern, ip string) (bool, error) {
+	matcher, err := getMatcher(pattern)
+	if err != nil {
+		return false, err
+	}
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false, nil
+	}
+	return containsIP(matcher, addr), nil
+}
+
 func toDateInZone(fmt, zone, str string) time.Time {
 	loc, err := time.LoadLocation(zone)
 	if err != nil {
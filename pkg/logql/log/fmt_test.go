@@ -479,6 +479,54 @@ func Test_lineFormatter_Format(t *testing.T) {
 			labels.FromStrings("foo", "hello"),
 			[]byte("1"),
 		},
+		{
+			"cidr_match",
+			newMustLineFormatter(`{{ if cidr_match "10.0.0.0/8" .src }}internal{{ else }}external{{ end }}`),
+			labels.FromStrings("src", "10.1.2.3"),
+			0,
+			[]byte("internal"),
+			labels.FromStrings("src", "10.1.2.3"),
+			nil,
+		},
+		{
+			"cidr_match no match",
+			newMustLineFormatter(`{{ if .src | cidr_match "10.0.0.0/8" }}internal{{ else }}external{{ end }}`),
+			labels.FromStrings("src", "192.168.1.1"),
+			0,
+			[]byte("external"),
+			labels.FromStrings("src", "192.168.1.1"),
+			nil,
+		},
+		{
+			"cidr_match ipv6 range",
+			newMustLineFormatter(`{{ cidr_match "2001:db8::1-2001:db8::ff" .src }}`),
+			labels.FromStrings("src", "2001:db8::a"),
+			0,
+			[]byte("true"),
+			labels.FromStrings("src", "2001:db8::a"),
+			nil,
+		},
+		{
+			"cidr_match not an ip",
+			newMustLineFormatter(`{{ cidr_match "10.0.0.0/8" .src }}`),
+			labels.FromStrings("src", "foo"),
+			0,
+			[]byte("false"),
+			labels.FromStrings("src", "foo"),
+			nil,
+		},
+		{
+			"cidr_match invalid pattern",
+			newMustLineFormatter(`{{ cidr_match "10.0.0.0/99" .src }}`),
+			labels.FromStrings("src", "10.1.2.3"),
+			0,
+			nil,
+			labels.FromStrings("__error__", "TemplateFormatErr",
+				"src", "10.1.2.3",
+				"__error_details__", `template: line:1:3: executing "line" at <cidr_match "10.0.0.0/99" .src>: error calling cidr_match: ip: invalid pattern: "10.0.0.0/99"`,
+			),
+			nil,
+		},
 		{
 			"simple key template",
 			newMustLineFormatter("{{.foo}}"),
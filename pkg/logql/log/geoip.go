@@ -0,0 +1,177 @@
+package log
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"net"
+	"strconv"
+	"sync/atomic"
+
+	"github.com/oschwald/geoip2-golang"
+)
+
+// The labels added by the geoip stage. They are named like the labels of the
+// geoip stage of Promtail, so queries and dashboards can be shared.
+const (
+	GeoIPCityNameLabel                     = "geoip_city_name"
+	GeoIPCountryNameLabel                  = "geoip_country_name"
+	GeoIPCountryCodeLabel                  = "geoip_country_code"
+	GeoIPContinentNameLabel                = "geoip_continent_name"
+	GeoIPContinentCodeLabel                = "geoip_continent_code"
+	GeoIPLocationLatitudeLabel             = "geoip_location_latitude"
+	GeoIPLocationLongitudeLabel            = "geoip_location_longitude"
+	GeoIPPostalCodeLabel                   = "geoip_postal_code"
+	GeoIPTimezoneLabel                     = "geoip_timezone"
+	GeoIPSubdivisionNameLabel              = "geoip_subdivision_name"
+	GeoIPSubdivisionCodeLabel              = "geoip_subdivision_code"
+	GeoIPAutonomousSystemNumberLabel       = "geoip_autonomous_system_number"
+	GeoIPAutonomousSystemOrganizationLabel = "geoip_autonomous_system_organization"
+)
+
+var ErrGeoIPNotConfigured = errors.New("geoip: no MaxMind database configured")
+
+// geoIPDatabases holds the databases loaded by LoadGeoIPDatabases.
+var geoIPDatabases atomic.Pointer[geoIPReaders]
+
+type geoIPReaders struct {
+	city *geoip2.Reader
+	asn  *geoip2.Reader
+}
+
+// GeoIPConfig configures the MaxMind databases used by the geoip stage.
+type GeoIPConfig struct {
+	CityDatabase string `yaml:"city_database"`
+	ASNDatabase  string `yaml:"asn_database"`
+}
+
+func (cfg *GeoIPConfig) RegisterFlagsWithPrefix(prefix string, f *flag.FlagSet) {
+	f.StringVar(&cfg.CityDatabase, prefix+"city-database", "", "Path to a MaxMind GeoIP2 or GeoLite2 City database. The geoip stage of LogQL queries uses it to add the location labels of an IP address.")
+	f.StringVar(&cfg.ASNDatabase, prefix+"asn-database", "", "Path to a MaxMind GeoLite2 ASN database. The geoip stage of LogQL queries uses it to add the autonomous system labels of an IP address.")
+}
+
+// LoadGeoIPDatabases opens the databases of the given config and makes them
+// available to the geoip stages created afterwards.
+// Previously loaded databases are not closed, since the stages of running
+// queries might still use them.
+func LoadGeoIPDatabases(cfg GeoIPConfig) error {
+	var (
+		readers geoIPReaders
+		err     error
+	)
+	if cfg.CityDatabase != "" {
+		readers.city, err = geoip2.Open(cfg.CityDatabase)
+		if err != nil {
+			return fmt.Errorf("opening geoip city database: %w", err)
+		}
+	}
+	if cfg.ASNDatabase != "" {
+		readers.asn, err = geoip2.Open(cfg.ASNDatabase)
+		if err != nil {
+			if readers.city != nil {
+				_ = readers.city.Close()
+			}
+			return fmt.Errorf("opening geoip asn database: %w", err)
+		}
+	}
+	geoIPDatabases.Store(&readers)
+	return nil
+}
+
+// GeoIPStage looks up the IP address of a label in the loaded MaxMind
+// databases, and adds the location and autonomous system of the address as
+// labels.
+type GeoIPStage struct {
+	source string
+	city   *geoip2.Reader
+	asn    *geoip2.Reader
+}
+
+// NewGeoIPStage creates a geoip stage for the IP address of the given label.
+// It returns ErrGeoIPNotConfigured if no database is loaded.
+func NewGeoIPStage(source string) (*GeoIPStage, error) {
+	readers := geoIPDatabases.Load()
+	if readers == nil || (readers.city == nil && readers.asn == nil) {
+		return nil, ErrGeoIPNotConfigured
+	}
+	return &GeoIPStage{
+		source: source,
+		city:   readers.city,
+		asn:    readers.asn,
+	}, nil
+}
+
+func (g *GeoIPStage) Process(_ int64, line []byte, lbs *LabelsBuilder) ([]byte, bool) {
+	value, ok := lbs.Get(g.source)
+	if !ok || value == "" {
+		return line, true
+	}
+
+	ip := net.ParseIP(value)
+	if ip == nil {
+		lbs.SetErr(errGeoIP)
+		lbs.SetErrorDetails(fmt.Sprintf("label %s is not a valid ip address: %q", g.source, value))
+		return line, true
+	}
+
+	if g.city != nil {
+		record, err := g.city.City(ip)
+		if err != nil {
+			lbs.SetErr(errGeoIP)
+			lbs.SetErrorDetails(err.Error())
+			return line, true
+		}
+		addGeoIPCityLabels(lbs, record)
+	}
+	if g.asn != nil {
+		record, err := g.asn.ASN(ip)
+		if err != nil {
+			lbs.SetErr(errGeoIP)
+			lbs.SetErrorDetails(err.Error())
+			return line, true
+		}
+		addGeoIPASNLabels(lbs, record)
+	}
+	return line, true
+}
+
+func (g *GeoIPStage) RequiredLabelNames() []string {
+	return []string{g.source}
+}
+
+// addGeoIPCityLabels adds the non-empty fields of a city record as labels.
+func addGeoIPCityLabels(lbs *LabelsBuilder, record *geoip2.City) {
+	setNonEmpty := func(name, value string) {
+		if value != "" {
+			lbs.Set(ParsedLabel, name, value)
+		}
+	}
+
+	setNonEmpty(GeoIPCityNameLabel, record.City.Names["en"])
+	setNonEmpty(GeoIPCountryNameLabel, record.Country.Names["en"])
+	setNonEmpty(GeoIPCountryCodeLabel, record.Country.IsoCode)
+	setNonEmpty(GeoIPContinentNameLabel, record.Continent.Names["en"])
+	setNonEmpty(GeoIPContinentCodeLabel, record.Continent.Code)
+	setNonEmpty(GeoIPPostalCodeLabel, record.Postal.Code)
+	setNonEmpty(GeoIPTimezoneLabel, record.Location.TimeZone)
+	if record.Location.Latitude != 0 || record.Location.Longitude != 0 {
+		lbs.Set(ParsedLabel, GeoIPLocationLatitudeLabel, strconv.FormatFloat(record.Location.Latitude, 'f', -1, 64))
+		lbs.Set(ParsedLabel, GeoIPLocationLongitudeLabel, strconv.FormatFloat(record.Location.Longitude, 'f', -1, 64))
+	}
+	if len(record.Subdivisions) > 0 {
+		// The last subdivision is the most specific one.
+		subdivision := record.Subdivisions[len(record.Subdivisions)-1]
+		setNonEmpty(GeoIPSubdivisionNameLabel, subdivision.Names["en"])
+		setNonEmpty(GeoIPSubdivisionCodeLabel, subdivision.IsoCode)
+	}
+}
+
+// addGeoIPASNLabels adds the non-empty fields of an ASN record as labels.
+func addGeoIPASNLabels(lbs *LabelsBuilder, record *geoip2.ASN) {
+	if record.AutonomousSystemNumber != 0 {
+		lbs.Set(ParsedLabel, GeoIPAutonomousSystemNumberLabel, strconv.FormatUint(uint64(record.AutonomousSystemNumber), 10))
+	}
+	if record.AutonomousSystemOrganization != "" {
+		lbs.Set(ParsedLabel, GeoIPAutonomousSystemOrganizationLabel, record.AutonomousSystemOrganization)
+	}
+}
@@ -0,0 +1,101 @@
+package log
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"github.com/oschwald/geoip2-golang"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewGeoIPStage(t *testing.T) {
+	geoIPDatabases.Store(nil)
+	t.Cleanup(func() { geoIPDatabases.Store(nil) })
+
+	_, err := NewGeoIPStage("src_ip")
+	require.ErrorIs(t, err, ErrGeoIPNotConfigured)
+
+	require.NoError(t, LoadGeoIPDatabases(GeoIPConfig{}))
+	_, err = NewGeoIPStage("src_ip")
+	require.ErrorIs(t, err, ErrGeoIPNotConfigured)
+
+	err = LoadGeoIPDatabases(GeoIPConfig{CityDatabase: filepath.Join(t.TempDir(), "missing.mmdb")})
+	require.ErrorContains(t, err, "opening geoip city database")
+}
+
+func TestGeoIPStage_Process(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		lbs  labels.Labels
+		want labels.Labels
+	}{
+		{
+			"missing label",
+			labels.FromStrings("app", "foo"),
+			labels.FromStrings("app", "foo"),
+		},
+		{
+			"empty label",
+			labels.FromStrings("app", "foo", "src_ip", ""),
+			labels.FromStrings("app", "foo", "src_ip", ""),
+		},
+		{
+			"invalid ip",
+			labels.FromStrings("app", "foo", "src_ip", "not-an-ip"),
+			labels.FromStrings("app", "foo", "src_ip", "not-an-ip",
+				"__error__", "GeoIPErr",
+				"__error_details__", `label src_ip is not a valid ip address: "not-an-ip"`,
+			),
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			stage := &GeoIPStage{source: "src_ip"}
+			b := NewBaseLabelsBuilder().ForLabels(tt.lbs, labels.StableHash(tt.lbs))
+			b.Reset()
+
+			line, ok := stage.Process(0, []byte("line"), b)
+			require.True(t, ok)
+			require.Equal(t, []byte("line"), line)
+			require.Equal(t, tt.want, b.LabelsResult().Labels())
+		})
+	}
+}
+
+func TestAddGeoIPLabels(t *testing.T) {
+	var city geoip2.City
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"City": {"Names": {"en": "Stockholm"}},
+		"Country": {"Names": {"en": "Sweden"}, "IsoCode": "SE"},
+		"Continent": {"Names": {"en": "Europe"}, "Code": "EU"},
+		"Postal": {"Code": "100 05"},
+		"Location": {"TimeZone": "Europe/Stockholm", "Latitude": 59.3247, "Longitude": 18.056},
+		"Subdivisions": [{"Names": {"en": "Stockholm County"}, "IsoCode": "AB"}]
+	}`), &city))
+	asn := geoip2.ASN{AutonomousSystemNumber: 1257, AutonomousSystemOrganization: "Tele2"}
+
+	lbs := labels.FromStrings("src_ip", "89.160.20.112")
+	b := NewBaseLabelsBuilder().ForLabels(lbs, labels.StableHash(lbs))
+	b.Reset()
+
+	addGeoIPCityLabels(b, &city)
+	addGeoIPASNLabels(b, &asn)
+
+	require.Equal(t, labels.FromStrings(
+		"src_ip", "89.160.20.112",
+		"geoip_city_name", "Stockholm",
+		"geoip_country_name", "Sweden",
+		"geoip_country_code", "SE",
+		"geoip_continent_name", "Europe",
+		"geoip_continent_code", "EU",
+		"geoip_postal_code", "100 05",
+		"geoip_timezone", "Europe/Stockholm",
+		"geoip_location_latitude", "59.3247",
+		"geoip_location_longitude", "18.056",
+		"geoip_subdivision_name", "Stockholm County",
+		"geoip_subdivision_code", "AB",
+		"geoip_autonomous_system_number", "1257",
+		"geoip_autonomous_system_organization", "Tele2",
+	), b.LabelsResult().Labels())
+}
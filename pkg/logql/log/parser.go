@@ -2,8 +2,11 @@ package log
 
 import (
 	"bytes"
+	"encoding/xml"
 	"errors"
 	"fmt"
+	"io"
+	"strconv"
 	"strings"
 	"unicode/utf8"
 	"unsafe"
@@ -13,6 +16,7 @@ import (
 	"github.com/grafana/loki/v3/pkg/logql/log/jsonexpr"
 	"github.com/grafana/loki/v3/pkg/logql/log/logfmt"
 	"github.com/grafana/loki/v3/pkg/logql/log/pattern"
+	"github.com/grafana/loki/v3/pkg/logql/log/xmlexpr"
 	"github.com/grafana/loki/v3/pkg/logqlmodel"
 
 	"github.com/grafana/regexp"
@@ -34,6 +38,9 @@ var (
 	_ Stage = &JSONParser{}
 	_ Stage = &RegexpParser{}
 	_ Stage = &LogfmtParser{}
+	_ Stage = &CSVParser{}
+	_ Stage = &XMLParser{}
+	_ Stage = &XMLExpressionParser{}
 
 	trueBytes = []byte("true")
 
@@ -831,3 +838,575 @@ func (u *UnpackParser) unpack(entry []byte, lbs *LabelsBuilder) ([]byte, error)
 	}
 	return entry, nil
 }
+
+const (
+	// CSVDefaultDelimiter and CSVDefaultQuote are the delimiter and the quote
+	// character of RFC 4180.
+	CSVDefaultDelimiter = ","
+	CSVDefaultQuote     = `"`
+)
+
+var (
+	errCSVUnterminatedQuote = errors.New("unterminated quoted field")
+	errCSVExtraneousQuote   = errors.New("extraneous character after quoted field")
+)
+
+type CSVParser struct {
+	columns   []string
+	delimiter rune
+	quote     rune
+
+	keys      []csvKey
+	quotedBuf []byte
+}
+
+type csvKey struct {
+	s   string
+	ok  bool
+	set bool
+}
+
+// NewCSVParser creates a parser that extracts the fields of a CSV log line.
+// The columns are the comma separated names of the fields, by position, since
+// log lines don't come with a header. An empty name or `_` skips a field. The
+// fields are named `_1`, `_2`... when no column is given.
+// The delimiter and the quote must be a single character, an empty quote
+// disables quoting.
+func NewCSVParser(columns, delimiter, quote string) (*CSVParser, error) {
+	p := &CSVParser{}
+	if columns != "" {
+		p.columns = strings.Split(columns, ",")
+		for i, c := range p.columns {
+			c = strings.TrimSpace(c)
+			if c != "" && c != "_" && !model.LabelName(c).IsValid() {
+				return nil, fmt.Errorf("invalid column name '%s'", c)
+			}
+			p.columns[i] = c
+		}
+	}
+
+	if utf8.RuneCountInString(delimiter) != 1 {
+		return nil, fmt.Errorf("the delimiter must be a single character, got '%s'", delimiter)
+	}
+	p.delimiter, _ = utf8.DecodeRuneInString(delimiter)
+	if p.delimiter == '\n' || p.delimiter == '\r' || p.delimiter == utf8.RuneError {
+		return nil, fmt.Errorf("invalid delimiter '%s'", delimiter)
+	}
+
+	if quote != "" {
+		if utf8.RuneCountInString(quote) != 1 {
+			return nil, fmt.Errorf("the quote must be a single character, got '%s'", quote)
+		}
+		p.quote, _ = utf8.DecodeRuneInString(quote)
+		if p.quote == p.delimiter {
+			return nil, errors.New("the quote and the delimiter must be different")
+		}
+	}
+	return p, nil
+}
+
+func (c *CSVParser) Process(_ int64, line []byte, lbs *LabelsBuilder) ([]byte, bool) {
+	parserHints := lbs.ParserLabelHints()
+	if parserHints.NoLabels() {
+		return line, true
+	}
+
+	rest := line
+	for i := 0; rest != nil; i++ {
+		if c.columns != nil && i >= len(c.columns) {
+			// the remaining fields are not named.
+			return line, true
+		}
+
+		var (
+			field []byte
+			err   error
+		)
+		field, rest, err = c.readField(rest)
+		if err != nil {
+			addErrLabel(errCSV, err, lbs)
+			if !parserHints.ShouldContinueParsingLine(logqlmodel.ErrorLabel, lbs) {
+				return line, false
+			}
+			return line, true
+		}
+
+		key, ok := c.key(i, lbs)
+		if !ok || parserHints.Extracted(key) {
+			continue
+		}
+
+		if bytes.ContainsRune(field, utf8.RuneError) {
+			field = bytes.Map(removeInvalidUtf, field)
+		}
+
+		lbs.Set(ParsedLabel, key, string(field))
+		if !parserHints.ShouldContinueParsingLine(key, lbs) {
+			return line, false
+		}
+
+		if parserHints.AllRequiredExtracted() {
+			return line, true
+		}
+	}
+
+	// Like the json parser with parameters, named columns missing from the
+	// line are extracted as empty labels.
+	for i := range c.columns {
+		key, ok := c.key(i, lbs)
+		if !ok || parserHints.Extracted(key) {
+			continue
+		}
+		if _, ok := lbs.Get(key); !ok {
+			lbs.Set(ParsedLabel, key, "")
+		}
+	}
+	return line, true
+}
+
+// key returns the label name of the field at the given position, and whether
+// the field must be extracted.
+func (c *CSVParser) key(i int, lbs *LabelsBuilder) (string, bool) {
+	for len(c.keys) <= i {
+		c.keys = append(c.keys, csvKey{})
+	}
+	if k := c.keys[i]; k.set {
+		return k.s, k.ok
+	}
+
+	name := "_" + strconv.Itoa(i+1)
+	if c.columns != nil {
+		name = c.columns[i]
+	}
+	k := csvKey{s: name, ok: name != "" && name != "_", set: true}
+	if k.ok {
+		if lbs.BaseHas(k.s) {
+			k.s += duplicateSuffix
+		}
+		k.ok = lbs.ParserLabelHints().ShouldExtract(k.s)
+	}
+	c.keys[i] = k
+	return k.s, k.ok
+}
+
+// readField reads the first field of the line. The rest of the line after the
+// delimiter is nil when there's no other field.
+func (c *CSVParser) readField(line []byte) ([]byte, []byte, error) {
+	if c.quote == 0 || len(line) == 0 {
+		return c.splitUnquoted(line)
+	}
+	r, size := utf8.DecodeRune(line)
+	if r != c.quote {
+		return c.splitUnquoted(line)
+	}
+
+	// quoted field, a doubled quote is an escaped quote.
+	c.quotedBuf = c.quotedBuf[:0]
+	line = line[size:]
+	for {
+		i := bytes.IndexRune(line, c.quote)
+		if i < 0 {
+			return nil, nil, errCSVUnterminatedQuote
+		}
+		c.quotedBuf = append(c.quotedBuf, line[:i]...)
+		line = line[i+size:]
+
+		next, nextSize := utf8.DecodeRune(line)
+		switch {
+		case len(line) == 0:
+			return c.quotedBuf, nil, nil
+		case next == c.quote:
+			c.quotedBuf = utf8.AppendRune(c.quotedBuf, c.quote)
+			line = line[nextSize:]
+		case next == c.delimiter:
+			return c.quotedBuf, line[nextSize:], nil
+		default:
+			return nil, nil, errCSVExtraneousQuote
+		}
+	}
+}
+
+func (c *CSVParser) splitUnquoted(line []byte) ([]byte, []byte, error) {
+	i := bytes.IndexRune(line, c.delimiter)
+	if i < 0 {
+		return line, nil, nil
+	}
+	return line[:i], line[i+utf8.RuneLen(c.delimiter):], nil
+}
+
+func (c *CSVParser) RequiredLabelNames() []string { return []string{} }
+
+var errUnexpectedXMLElement = errors.New("expecting an xml element, but found none")
+
+// xmlElement is an open element of the XML document being parsed.
+type xmlElement struct {
+	name string
+	// attrs are only kept by the XMLExpressionParser.
+	attrs []xml.Attr
+	// position is the position of the element amongst its siblings with the same
+	// name, and index amongst all its siblings. Both are 1-based.
+	position, index int
+	// children counts the children of the element by name.
+	children    map[string]int
+	numChildren int
+	prefixLen   int
+	text        []byte
+}
+
+type XMLParser struct {
+	keys     internedStringSet
+	elements []xmlElement
+	prefix   []byte
+}
+
+// NewXMLParser creates a parser that extracts the text and the attributes of
+// all the elements of an xml log line.
+// The label names are built from the path of the elements, from the children of
+// the root element, with the `_` separator. Attributes are suffixed to the name
+// of their element.
+func NewXMLParser() *XMLParser {
+	return &XMLParser{
+		keys:   internedStringSet{},
+		prefix: make([]byte, 0, 64),
+	}
+}
+
+func (x *XMLParser) Process(_ int64, line []byte, lbs *LabelsBuilder) ([]byte, bool) {
+	parserHints := lbs.ParserLabelHints()
+	if parserHints.NoLabels() {
+		return line, true
+	}
+
+	x.elements = x.elements[:0]
+	x.prefix = x.prefix[:0]
+	foundRoot := false
+
+	dec := xml.NewDecoder(bytes.NewReader(line))
+	for {
+		tok, err := dec.Token()
+		if errors.Is(err, io.EOF) {
+			if !foundRoot {
+				addErrLabel(errXML, errUnexpectedXMLElement, lbs)
+			}
+			return line, true
+		}
+		if err != nil {
+			addErrLabel(errXML, err, lbs)
+			return line, true
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			foundRoot = true
+			x.elements = append(x.elements, xmlElement{name: t.Name.Local, prefixLen: len(x.prefix)})
+			if len(x.elements) > 1 {
+				// the root element isn't part of the label names, like the root object of a json document.
+				if len(x.prefix) > 0 {
+					x.prefix = append(x.prefix, jsonSpacer)
+				}
+				x.prefix = appendSanitized(x.prefix, []byte(t.Name.Local))
+				if !parserHints.ShouldExtractPrefix(string(x.prefix)) {
+					if err := dec.Skip(); err != nil {
+						addErrLabel(errXML, err, lbs)
+						return line, true
+					}
+					x.closeElement()
+					continue
+				}
+			}
+
+			for _, attr := range t.Attr {
+				if attr.Name.Space == "xmlns" || attr.Name.Local == "xmlns" {
+					continue
+				}
+				prefixLen := len(x.prefix)
+				if len(x.prefix) > 0 {
+					x.prefix = append(x.prefix, jsonSpacer)
+				}
+				x.prefix = appendSanitized(x.prefix, []byte(attr.Name.Local))
+				ok, done := x.setLabel(x.prefix, attr.Value, lbs)
+				x.prefix = x.prefix[:prefixLen]
+				if !ok {
+					return line, false
+				}
+				if done {
+					return line, true
+				}
+			}
+
+		case xml.CharData:
+			if n := len(x.elements); n > 0 {
+				x.elements[n-1].text = append(x.elements[n-1].text, t...)
+			}
+
+		case xml.EndElement:
+			elt := x.elements[len(x.elements)-1]
+			if len(x.elements) > 1 {
+				if text := bytes.TrimSpace(elt.text); len(text) > 0 {
+					ok, done := x.setLabel(x.prefix, string(text), lbs)
+					if !ok {
+						return line, false
+					}
+					if done {
+						return line, true
+					}
+				}
+			}
+			x.closeElement()
+			if len(x.elements) == 0 {
+				// anything after the root element is ignored.
+				return line, true
+			}
+		}
+	}
+}
+
+func (x *XMLParser) closeElement() {
+	n := len(x.elements) - 1
+	x.prefix = x.prefix[:x.elements[n].prefixLen]
+	x.elements = x.elements[:n]
+}
+
+// setLabel sets the label with the given key if it's required. It returns false
+// if the line must be filtered out, and true as second value if all the
+// required labels are extracted.
+func (x *XMLParser) setLabel(key []byte, value string, lbs *LabelsBuilder) (bool, bool) {
+	parserHints := lbs.ParserLabelHints()
+	if len(key) == 0 {
+		return true, false
+	}
+	sanitizedKey, ok := x.keys.Get(key, func() (string, bool) {
+		field := string(key)
+		if lbs.BaseHas(field) {
+			field = field + duplicateSuffix
+		}
+		if !parserHints.ShouldExtract(field) {
+			return "", false
+		}
+		return field, true
+	})
+	// only the first value of a repeated element is kept.
+	if !ok || parserHints.Extracted(sanitizedKey) {
+		return true, false
+	}
+
+	lbs.Set(ParsedLabel, sanitizedKey, value)
+	if !parserHints.ShouldContinueParsingLine(sanitizedKey, lbs) {
+		return false, false
+	}
+	return true, parserHints.AllRequiredExtracted()
+}
+
+func (x *XMLParser) RequiredLabelNames() []string { return []string{} }
+
+type XMLExpressionParser struct {
+	ids   []string
+	paths []xmlexpr.Path
+	keys  internedStringSet
+
+	elements []xmlElement
+	found    []bool
+	// capturing is the index of the path whose text is being read, -1 if none.
+	capturing []int
+}
+
+// NewXMLExpressionParser creates a parser that extracts the values selected by
+// the XPath expressions of an xml log line.
+func NewXMLExpressionParser(expressions []LabelExtractionExpr) (*XMLExpressionParser, error) {
+	var ids []string
+	var paths []xmlexpr.Path
+	for _, exp := range expressions {
+		path, err := xmlexpr.Parse(exp.Expression)
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse expression [%s]: %w", exp.Expression, err)
+		}
+
+		if !model.LabelName(exp.Identifier).IsValid() {
+			return nil, fmt.Errorf("invalid extracted label name '%s'", exp.Identifier)
+		}
+
+		ids = append(ids, exp.Identifier)
+		paths = append(paths, path)
+	}
+
+	return &XMLExpressionParser{
+		ids:   ids,
+		paths: paths,
+		keys:  internedStringSet{},
+		found: make([]bool, len(ids)),
+	}, nil
+}
+
+func (x *XMLExpressionParser) Process(_ int64, line []byte, lbs *LabelsBuilder) ([]byte, bool) {
+	if len(line) == 0 || lbs.ParserLabelHints().NoLabels() {
+		return line, true
+	}
+
+	x.elements = x.elements[:0]
+	x.capturing = x.capturing[:0]
+	for i := range x.found {
+		x.found[i] = false
+	}
+
+	ok := x.extract(line, lbs)
+
+	// Ensure there's a label for every value
+	for i, id := range x.ids {
+		if !x.found[i] {
+			if _, ok := lbs.Get(id); !ok {
+				lbs.Set(ParsedLabel, id, "")
+			}
+		}
+	}
+	return line, ok
+}
+
+func (x *XMLExpressionParser) extract(line []byte, lbs *LabelsBuilder) bool {
+	var (
+		dec       = xml.NewDecoder(bytes.NewReader(line))
+		foundRoot bool
+		remaining = len(x.ids)
+	)
+	for remaining > 0 {
+		tok, err := dec.Token()
+		if errors.Is(err, io.EOF) {
+			if !foundRoot {
+				addErrLabel(errXML, errUnexpectedXMLElement, lbs)
+			}
+			return true
+		}
+		if err != nil {
+			addErrLabel(errXML, err, lbs)
+			return true
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if foundRoot && len(x.elements) == 0 {
+				// anything after the root element is ignored.
+				return true
+			}
+			foundRoot = true
+			x.openElement(t)
+
+			for i, path := range x.paths {
+				if x.found[i] || !x.matches(path) {
+					continue
+				}
+				if path.Attribute == "" {
+					x.capturing = append(x.capturing, i)
+					continue
+				}
+				for _, attr := range t.Attr {
+					if attr.Name.Local == path.Attribute {
+						if !x.setLabel(i, attr.Value, lbs) {
+							return false
+						}
+						remaining--
+						break
+					}
+				}
+			}
+
+		case xml.CharData:
+			if n := len(x.elements); n > 0 && len(x.capturing) > 0 {
+				x.elements[n-1].text = append(x.elements[n-1].text, t...)
+			}
+
+		case xml.EndElement:
+			depth := len(x.elements)
+			elt := x.elements[depth-1]
+			captured := x.capturing[:0]
+			for _, i := range x.capturing {
+				if x.found[i] || x.depth(x.paths[i]) != depth {
+					captured = append(captured, i)
+					continue
+				}
+				if !x.setLabel(i, string(bytes.TrimSpace(elt.text)), lbs) {
+					return false
+				}
+				remaining--
+			}
+			x.capturing = captured
+			x.elements = x.elements[:depth-1]
+		}
+	}
+	return true
+}
+
+func (x *XMLExpressionParser) openElement(t xml.StartElement) {
+	elt := xmlElement{name: t.Name.Local, attrs: t.Attr}
+	if n := len(x.elements); n > 0 {
+		parent := &x.elements[n-1]
+		if parent.children == nil {
+			parent.children = map[string]int{}
+		}
+		parent.children[elt.name]++
+		parent.numChildren++
+		elt.position, elt.index = parent.children[elt.name], parent.numChildren
+	} else {
+		elt.position, elt.index = 1, 1
+	}
+	x.elements = append(x.elements, elt)
+}
+
+// depth returns the depth of the elements selected by the path.
+func (x *XMLExpressionParser) depth(path xmlexpr.Path) int {
+	if path.Absolute {
+		return len(path.Steps)
+	}
+	return len(path.Steps) + 1
+}
+
+// matches tells if the path selects the last opened element.
+func (x *XMLExpressionParser) matches(path xmlexpr.Path) bool {
+	if x.depth(path) != len(x.elements) {
+		return false
+	}
+	elements := x.elements[len(x.elements)-len(path.Steps):]
+	for i, step := range path.Steps {
+		elt := elements[i]
+		if step.Name != xmlexpr.Wildcard && step.Name != elt.name {
+			return false
+		}
+		if step.Position != 0 {
+			position := elt.position
+			if step.Name == xmlexpr.Wildcard {
+				position = elt.index
+			}
+			if position != step.Position {
+				return false
+			}
+		}
+		if step.Attribute != "" && !hasXMLAttr(elt.attrs, step.Attribute, step.Value) {
+			return false
+		}
+	}
+	return true
+}
+
+func hasXMLAttr(attrs []xml.Attr, name, value string) bool {
+	for _, attr := range attrs {
+		if attr.Name.Local == name && attr.Value == value {
+			return true
+		}
+	}
+	return false
+}
+
+// setLabel sets the label of the i-th expression. It returns false if the line
+// must be filtered out.
+func (x *XMLExpressionParser) setLabel(i int, value string, lbs *LabelsBuilder) bool {
+	x.found[i] = true
+	identifier := x.ids[i]
+	key, _ := x.keys.Get(unsafeGetBytes(identifier), func() (string, bool) {
+		if lbs.BaseHas(identifier) {
+			identifier = identifier + duplicateSuffix
+		}
+		return identifier, true
+	})
+	lbs.Set(ParsedLabel, key, value)
+	return lbs.ParserLabelHints().ShouldContinueParsingLine(key, lbs)
+}
+
+func (x *XMLExpressionParser) RequiredLabelNames() []string { return []string{} }
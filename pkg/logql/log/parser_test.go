@@ -1766,6 +1766,245 @@ func Test_PatternParser(t *testing.T) {
 	}
 }
 
+func Test_CSVParser(t *testing.T) {
+	tests := []struct {
+		name      string
+		columns   string
+		delimiter string
+		quote     string
+		line      []byte
+		lbs       labels.Labels
+		want      labels.Labels
+		hints     ParserHint
+	}{
+		{
+			"positional",
+			"", ",", `"`,
+			[]byte(`foo,bar,,baz`),
+			labels.EmptyLabels(),
+			labels.FromStrings("_1", "foo", "_2", "bar", "_3", "", "_4", "baz"),
+			NoParserHints(),
+		},
+		{
+			"columns",
+			"ts,level,,msg", ",", `"`,
+			[]byte(`2024-01-01,info,skipped,"hello, ""world""",ignored`),
+			labels.FromStrings("app", "foo"),
+			labels.FromStrings("app", "foo", "level", "info", "msg", `hello, "world"`, "ts", "2024-01-01"),
+			NoParserHints(),
+		},
+		{
+			"skip with underscore",
+			"_,level", ",", `"`,
+			[]byte(`2024-01-01,warn`),
+			labels.EmptyLabels(),
+			labels.FromStrings("level", "warn"),
+			NoParserHints(),
+		},
+		{
+			"missing columns",
+			"ts,level,msg", ",", `"`,
+			[]byte(`2024-01-01,info`),
+			labels.EmptyLabels(),
+			labels.FromStrings("level", "info", "msg", "", "ts", "2024-01-01"),
+			NoParserHints(),
+		},
+		{
+			"duplicate",
+			"app,level", ",", `"`,
+			[]byte(`bar,info`),
+			labels.FromStrings("app", "foo"),
+			labels.FromStrings("app", "foo", "app_extracted", "bar", "level", "info"),
+			NoParserHints(),
+		},
+		{
+			"delimiter and quote",
+			"host,msg,code", ";", "'",
+			[]byte(`web-1;'it''s; fine';200`),
+			labels.EmptyLabels(),
+			labels.FromStrings("code", "200", "host", "web-1", "msg", "it's; fine"),
+			NoParserHints(),
+		},
+		{
+			"no quote",
+			"a,b", "\t", "",
+			[]byte("\"x\t\"y"),
+			labels.EmptyLabels(),
+			labels.FromStrings("a", `"x`, "b", `"y`),
+			NoParserHints(),
+		},
+		{
+			"unterminated quote",
+			"a,b", ",", `"`,
+			[]byte(`x,"y`),
+			labels.EmptyLabels(),
+			labels.FromStrings("__error__", "CSVParserErr", "__error_details__", "unterminated quoted field", "a", "x"),
+			NoParserHints(),
+		},
+		{
+			"extraneous quote",
+			"a,b", ",", `"`,
+			[]byte(`"x"y,z`),
+			labels.EmptyLabels(),
+			labels.FromStrings("__error__", "CSVParserErr", "__error_details__", "extraneous character after quoted field"),
+			NoParserHints(),
+		},
+		{
+			"hints",
+			"ts,level,msg", ",", `"`,
+			[]byte(`2024-01-01,info,hello`),
+			labels.EmptyLabels(),
+			labels.FromStrings("level", "info"),
+			NewParserHint([]string{"level"}, []string{"level"}, false, true, "", nil),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := NewCSVParser(tt.columns, tt.delimiter, tt.quote)
+			require.NoError(t, err)
+			b := NewBaseLabelsBuilderWithGrouping(nil, tt.hints, false, false).ForLabels(tt.lbs, labels.StableHash(tt.lbs))
+			b.Reset()
+			_, _ = p.Process(0, tt.line, b)
+			require.Equal(t, tt.want, b.LabelsResult().Labels())
+		})
+	}
+}
+
+func TestNewCSVParserFailures(t *testing.T) {
+	for _, tc := range []struct {
+		columns, delimiter, quote string
+		err                       string
+	}{
+		{"a,b", ";;", `"`, "the delimiter must be a single character, got ';;'"},
+		{"a,b", "", `"`, "the delimiter must be a single character, got ''"},
+		{"a,b", ",", ",", "the quote and the delimiter must be different"},
+		{"a,b", ",", `""`, `the quote must be a single character, got '""'`},
+	} {
+		_, err := NewCSVParser(tc.columns, tc.delimiter, tc.quote)
+		require.EqualError(t, err, tc.err)
+	}
+}
+
+func Test_XMLParser(t *testing.T) {
+	tests := []struct {
+		name  string
+		line  []byte
+		lbs   labels.Labels
+		want  labels.Labels
+		hints ParserHint
+	}{
+		{
+			"nested",
+			[]byte(`<Event xmlns="http://schemas.microsoft.com/win/2004/08/events/event"><System><Provider Name="Security"/><EventID>4624</EventID><Computer> dc-1 </Computer></System></Event>`),
+			labels.EmptyLabels(),
+			labels.FromStrings("System_Computer", "dc-1", "System_EventID", "4624", "System_Provider_Name", "Security"),
+			NoParserHints(),
+		},
+		{
+			"root attributes and repeated elements",
+			[]byte(`<log level="info"><msg>first</msg><msg>second</msg><app>bar</app></log>`),
+			labels.FromStrings("app", "foo"),
+			labels.FromStrings("app", "foo", "app_extracted", "bar", "level", "info", "msg", "first"),
+			NoParserHints(),
+		},
+		{
+			"not xml",
+			[]byte(`level=info msg=hello`),
+			labels.EmptyLabels(),
+			labels.FromStrings("__error__", "XMLParserErr", "__error_details__", "expecting an xml element, but found none"),
+			NoParserHints(),
+		},
+		{
+			"invalid",
+			[]byte(`<log><msg>hello</log>`),
+			labels.EmptyLabels(),
+			labels.FromStrings("__error__", "XMLParserErr", "__error_details__", "XML syntax error on line 1: element <msg> closed by </log>"),
+			NoParserHints(),
+		},
+		{
+			"hints",
+			[]byte(`<log><msg>hello</msg><req><status>200</status><path>/</path></req></log>`),
+			labels.EmptyLabels(),
+			labels.FromStrings("req_status", "200"),
+			NewParserHint([]string{"req_status"}, []string{"req_status"}, false, true, "", nil),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewXMLParser()
+			b := NewBaseLabelsBuilderWithGrouping(nil, tt.hints, false, false).ForLabels(tt.lbs, labels.StableHash(tt.lbs))
+			b.Reset()
+			_, _ = p.Process(0, tt.line, b)
+			require.Equal(t, tt.want, b.LabelsResult().Labels())
+		})
+	}
+}
+
+func TestXMLExpressionParser(t *testing.T) {
+	testLine := []byte(`<Event><System><Provider Name="Microsoft-Windows-Security-Auditing"/><EventID>4624</EventID></System>` +
+		`<EventData><Data Name="SubjectUserName">-</Data><Data Name="TargetUserName">alice</Data><Data Name="LogonType">3</Data></EventData></Event>`)
+
+	tests := []struct {
+		name        string
+		line        []byte
+		expressions []LabelExtractionExpr
+		lbs         labels.Labels
+		want        labels.Labels
+	}{
+		{
+			"relative and absolute paths",
+			testLine,
+			[]LabelExtractionExpr{
+				NewLabelExtractionExpr("event_id", "System/EventID"),
+				NewLabelExtractionExpr("provider", "/Event/System/Provider/@Name"),
+			},
+			labels.EmptyLabels(),
+			labels.FromStrings("event_id", "4624", "provider", "Microsoft-Windows-Security-Auditing"),
+		},
+		{
+			"predicates",
+			testLine,
+			[]LabelExtractionExpr{
+				NewLabelExtractionExpr("user", "EventData/Data[@Name='TargetUserName']"),
+				NewLabelExtractionExpr("third", "EventData/Data[3]"),
+				NewLabelExtractionExpr("second", "*[2]/*[2]/text()"),
+			},
+			labels.EmptyLabels(),
+			labels.FromStrings("second", "alice", "third", "3", "user", "alice"),
+		},
+		{
+			"missing and duplicate",
+			testLine,
+			[]LabelExtractionExpr{
+				NewLabelExtractionExpr("app", "System/Provider/@Name"),
+				NewLabelExtractionExpr("missing", "System/Level"),
+				NewLabelExtractionExpr("wrong_root", "/Log/System/EventID"),
+			},
+			labels.FromStrings("app", "foo"),
+			labels.FromStrings("app", "foo", "app_extracted", "Microsoft-Windows-Security-Auditing", "missing", "", "wrong_root", ""),
+		},
+		{
+			"invalid",
+			[]byte(`<Event><System>`),
+			[]LabelExtractionExpr{
+				NewLabelExtractionExpr("event_id", "System/EventID"),
+			},
+			labels.EmptyLabels(),
+			labels.FromStrings("__error__", "XMLParserErr", "__error_details__", "XML syntax error on line 1: unexpected EOF", "event_id", ""),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := NewXMLExpressionParser(tt.expressions)
+			require.NoError(t, err)
+			b := NewBaseLabelsBuilderWithGrouping(nil, NoParserHints(), false, false).ForLabels(tt.lbs, labels.StableHash(tt.lbs))
+			b.Reset()
+			_, _ = p.Process(0, tt.line, b)
+			require.Equal(t, tt.want, b.LabelsResult().Labels())
+		})
+	}
+}
+
 func BenchmarkJsonExpressionParser(b *testing.B) {
 	simpleJsn := []byte(`{
       "data": "Click Here",
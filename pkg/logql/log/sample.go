@@ -0,0 +1,104 @@
+package log
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+
+	"github.com/cespare/xxhash/v2"
+)
+
+// sampleKeySeparator separates the values of the labels of a sample key. It is
+// not valid UTF-8, so it can't be part of a value.
+const sampleKeySeparator = "\xff"
+
+// SampleStage keeps a deterministic subset of the log lines.
+//
+// A log line is kept if the hash of its key is below the threshold of the
+// ratio. The key consists of the values of the given labels, so either all or
+// none of the log lines of e.g. a trace are kept. Without labels, the key is
+// the timestamp and content of the log line.
+// Since the decision only depends on the log line, every shard of a query and
+// every engine keeps the same log lines.
+type SampleStage struct {
+	threshold uint64
+	by        []string
+
+	digest *xxhash.Digest
+}
+
+// NewSampleStage creates a sample stage that keeps the given ratio of the log
+// lines, sampled by the values of the given labels.
+func NewSampleStage(ratio float64, by []string) (*SampleStage, error) {
+	if ratio <= 0 || ratio > 1 {
+		return nil, fmt.Errorf("invalid sample ratio %v, must be greater than 0 and less than or equal to 1", ratio)
+	}
+	return &SampleStage{
+		threshold: SampleThreshold(ratio),
+		by:        by,
+		digest:    xxhash.New(),
+	}, nil
+}
+
+func (s *SampleStage) Process(ts int64, line []byte, lbs *LabelsBuilder) ([]byte, bool) {
+	s.digest.Reset()
+	if len(s.by) == 0 {
+		writeSampleLineKey(s.digest, ts, line)
+	} else {
+		for i, name := range s.by {
+			if i > 0 {
+				_, _ = s.digest.WriteString(sampleKeySeparator)
+			}
+			// Missing labels are treated as empty values.
+			value, _ := lbs.Get(name)
+			_, _ = s.digest.WriteString(value)
+		}
+	}
+	return line, SampleKeep(s.digest.Sum64(), s.threshold)
+}
+
+func (s *SampleStage) RequiredLabelNames() []string {
+	return s.by
+}
+
+// SampleThreshold returns the threshold below which the hashes of the kept log
+// lines are for the given ratio.
+func SampleThreshold(ratio float64) uint64 {
+	if ratio >= 1 {
+		return math.MaxUint64
+	}
+	return uint64(ratio * math.MaxUint64)
+}
+
+// SampleKeep returns whether a log line with the given hash is kept.
+func SampleKeep(hash, threshold uint64) bool {
+	return threshold == math.MaxUint64 || hash < threshold
+}
+
+// SampleLineHash returns the hash of a log line that is sampled without
+// labels.
+func SampleLineHash(ts int64, line []byte) uint64 {
+	digest := xxhash.New()
+	writeSampleLineKey(digest, ts, line)
+	return digest.Sum64()
+}
+
+// SampleLabelsHash returns the hash of a log line that is sampled by the given
+// values of its labels.
+func SampleLabelsHash(values []string) uint64 {
+	digest := xxhash.New()
+	for i, value := range values {
+		if i > 0 {
+			_, _ = digest.WriteString(sampleKeySeparator)
+		}
+		_, _ = digest.WriteString(value)
+	}
+	return digest.Sum64()
+}
+
+func writeSampleLineKey(digest *xxhash.Digest, ts int64, line []byte) {
+	var buf [8]byte
+	binary.LittleEndian.PutUint64(buf[:], uint64(ts))
+	_, _ = digest.Write(buf[:])
+	_, _ = digest.Write(line)
+}
@@ -0,0 +1,77 @@
+package log
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewSampleStage(t *testing.T) {
+	for _, ratio := range []float64{-1, 0, 1.5} {
+		_, err := NewSampleStage(ratio, nil)
+		require.Error(t, err, "ratio %v", ratio)
+	}
+	for _, ratio := range []float64{0.001, 0.5, 1} {
+		_, err := NewSampleStage(ratio, nil)
+		require.NoError(t, err, "ratio %v", ratio)
+	}
+}
+
+func TestSampleStage_Lines(t *testing.T) {
+	stage, err := NewSampleStage(0.1, nil)
+	require.NoError(t, err)
+	other, err := NewSampleStage(0.1, nil)
+	require.NoError(t, err)
+
+	lbs := labels.FromStrings("app", "foo")
+	b := NewBaseLabelsBuilder().ForLabels(lbs, labels.StableHash(lbs))
+
+	const lines = 10000
+	kept := 0
+	for i := range lines {
+		line := []byte(fmt.Sprintf("line %d", i))
+		_, ok := stage.Process(int64(i), line, b)
+		// The decision only depends on the log line.
+		_, otherOk := other.Process(int64(i), line, b)
+		require.Equal(t, ok, otherOk)
+		if ok {
+			kept++
+		}
+	}
+	require.InDelta(t, lines/10, kept, lines/100)
+
+	all, err := NewSampleStage(1, nil)
+	require.NoError(t, err)
+	for i := range 100 {
+		_, ok := all.Process(int64(i), []byte("line"), b)
+		require.True(t, ok)
+	}
+}
+
+func TestSampleStage_Labels(t *testing.T) {
+	stage, err := NewSampleStage(0.5, []string{"trace_id"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"trace_id"}, stage.RequiredLabelNames())
+
+	keep := map[string]bool{}
+	for i := range 1000 {
+		traceID := fmt.Sprintf("trace-%d", i%20)
+		lbs := labels.FromStrings("app", "foo", "trace_id", traceID)
+		b := NewBaseLabelsBuilder().ForLabels(lbs, labels.StableHash(lbs))
+
+		_, ok := stage.Process(int64(i), []byte(fmt.Sprintf("line %d", i)), b)
+		// Either all or none of the log lines of a trace are kept.
+		if prev, seen := keep[traceID]; seen {
+			require.Equal(t, prev, ok, "trace %s", traceID)
+		}
+		keep[traceID] = ok
+	}
+
+	// Log lines without the label are sampled like an empty value.
+	lbs := labels.FromStrings("app", "foo")
+	b := NewBaseLabelsBuilder().ForLabels(lbs, labels.StableHash(lbs))
+	_, ok := stage.Process(0, []byte("line"), b)
+	require.Equal(t, SampleKeep(SampleLabelsHash([]string{""}), SampleThreshold(0.5)), ok)
+}
@@ -0,0 +1,208 @@
+// Package xmlexpr parses the subset of XPath used by the LogQL xml parser to
+// extract values from XML log lines.
+package xmlexpr
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Wildcard is the name of a step matching any element.
+const Wildcard = "*"
+
+// Step is a location step of a Path. It selects a child element by name and
+// optional predicates.
+type Step struct {
+	// Name is the local name of the element, or Wildcard.
+	Name string
+	// Attribute and Value are set by an `[@attribute='value']` predicate.
+	Attribute, Value string
+	// Position is set by a `[n]` predicate. It is 1-based, 0 means unset.
+	Position int
+}
+
+// Path selects the text or an attribute of an element of an XML document.
+type Path struct {
+	// Absolute paths start with a `/`, and their first step selects the root
+	// element. The first step of relative paths selects a child of the root
+	// element, whatever its name.
+	Absolute bool
+	Steps    []Step
+	// Attribute is the name of the attribute selected by a final `@attribute`
+	// step. The text of the element is selected when it's empty.
+	Attribute string
+}
+
+// Parse parses an XPath expression like `/Event/System/Provider/@Name` or
+// `EventData/Data[@Name='TargetUserName']`.
+func Parse(expr string) (Path, error) {
+	var p Path
+
+	s := strings.TrimSpace(expr)
+	if strings.HasPrefix(s, "//") {
+		return p, errors.New("descendant steps are not supported")
+	}
+	if strings.HasPrefix(s, "/") {
+		p.Absolute = true
+		s = s[1:]
+	}
+
+	parts, err := splitSteps(s)
+	if err != nil {
+		return p, err
+	}
+	for i, part := range parts {
+		last := i == len(parts)-1
+		switch {
+		case part == "":
+			return p, errors.New("empty step")
+		case part == "text()":
+			if !last {
+				return p, errors.New("text() must be the last step")
+			}
+		case strings.HasPrefix(part, "@"):
+			if !last {
+				return p, errors.New("an attribute must be the last step")
+			}
+			if !isName(part[1:]) {
+				return p, fmt.Errorf("invalid attribute name %q", part[1:])
+			}
+			p.Attribute = part[1:]
+		default:
+			step, err := parseStep(part)
+			if err != nil {
+				return p, err
+			}
+			p.Steps = append(p.Steps, step)
+		}
+	}
+	if len(p.Steps) == 0 {
+		return p, errors.New("the path must select an element")
+	}
+	return p, nil
+}
+
+// splitSteps splits a path on the slashes that are not within a predicate.
+func splitSteps(s string) ([]string, error) {
+	var (
+		parts   []string
+		start   int
+		inPred  bool
+		inQuote rune
+	)
+	for i, r := range s {
+		switch {
+		case inQuote != 0:
+			if r == inQuote {
+				inQuote = 0
+			}
+		case r == '\'' || r == '"':
+			if !inPred {
+				return nil, fmt.Errorf("unexpected quote at position %d", i)
+			}
+			inQuote = r
+		case r == '[':
+			if inPred {
+				return nil, fmt.Errorf("unexpected '[' at position %d", i)
+			}
+			inPred = true
+		case r == ']':
+			if !inPred {
+				return nil, fmt.Errorf("unexpected ']' at position %d", i)
+			}
+			inPred = false
+		case r == '/' && !inPred:
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+	if inQuote != 0 {
+		return nil, errors.New("unterminated string")
+	}
+	if inPred {
+		return nil, errors.New("missing closing ']'")
+	}
+	return append(parts, s[start:]), nil
+}
+
+func parseStep(s string) (Step, error) {
+	var step Step
+
+	name, preds, _ := strings.Cut(s, "[")
+	if name != Wildcard && !isName(name) {
+		return step, fmt.Errorf("invalid element name %q", name)
+	}
+	step.Name = name
+
+	for preds != "" {
+		end := closingBracket(preds)
+		if end < 0 {
+			return step, errors.New("missing closing ']'")
+		}
+		pred, rest := preds[:end], preds[end+1:]
+		if err := parsePredicate(pred, &step); err != nil {
+			return step, err
+		}
+		if rest != "" && !strings.HasPrefix(rest, "[") {
+			return step, fmt.Errorf("unexpected %q after predicate", rest)
+		}
+		preds = strings.TrimPrefix(rest, "[")
+	}
+	return step, nil
+}
+
+// closingBracket returns the index of the first ']' of s that is not quoted.
+func closingBracket(s string) int {
+	var inQuote rune
+	for i, r := range s {
+		switch {
+		case inQuote != 0:
+			if r == inQuote {
+				inQuote = 0
+			}
+		case r == '\'' || r == '"':
+			inQuote = r
+		case r == ']':
+			return i
+		}
+	}
+	return -1
+}
+
+func parsePredicate(s string, step *Step) error {
+	s = strings.TrimSpace(s)
+	if !strings.HasPrefix(s, "@") {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 1 {
+			return fmt.Errorf("invalid predicate %q: expecting a position or an attribute comparison", s)
+		}
+		if step.Position != 0 {
+			return errors.New("only one position predicate is supported per step")
+		}
+		step.Position = n
+		return nil
+	}
+
+	if step.Attribute != "" {
+		return errors.New("only one attribute predicate is supported per step")
+	}
+	name, value, ok := strings.Cut(s[1:], "=")
+	name, value = strings.TrimSpace(name), strings.TrimSpace(value)
+	if !ok || !isName(name) || len(value) < 2 || (value[0] != '\'' && value[0] != '"') || value[len(value)-1] != value[0] {
+		return fmt.Errorf("invalid predicate %q: expecting @attribute='value'", s)
+	}
+	step.Attribute, step.Value = name, value[1:len(value)-1]
+	return nil
+}
+
+// isName reports whether s is a valid local name. It doesn't implement all
+// the rules of the XML specification, only the ones relevant to the syntax of
+// paths.
+func isName(s string) bool {
+	if s == "" {
+		return false
+	}
+	return !strings.ContainsAny(s, "/[]@'\"=*:() \t\n")
+}
@@ -0,0 +1,94 @@
+package xmlexpr
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name string
+		expr string
+		want Path
+		err  string
+	}{
+		{
+			"relative element",
+			"System/EventID",
+			Path{Steps: []Step{{Name: "System"}, {Name: "EventID"}}},
+			"",
+		},
+		{
+			"absolute attribute",
+			"/Event/System/Provider/@Name",
+			Path{Absolute: true, Steps: []Step{{Name: "Event"}, {Name: "System"}, {Name: "Provider"}}, Attribute: "Name"},
+			"",
+		},
+		{
+			"text",
+			"/Event/System/Computer/text()",
+			Path{Absolute: true, Steps: []Step{{Name: "Event"}, {Name: "System"}, {Name: "Computer"}}},
+			"",
+		},
+		{
+			"predicates",
+			`EventData/Data[@Name='Target/User[Name]'][2]`,
+			Path{Steps: []Step{{Name: "EventData"}, {Name: "Data", Attribute: "Name", Value: "Target/User[Name]", Position: 2}}},
+			"",
+		},
+		{
+			"wildcard",
+			`*[1]/@id`,
+			Path{Steps: []Step{{Name: Wildcard, Position: 1}}, Attribute: "id"},
+			"",
+		},
+		{
+			"descendant",
+			"//EventID",
+			Path{},
+			"descendant steps are not supported",
+		},
+		{
+			"empty step",
+			"System//EventID",
+			Path{},
+			"empty step",
+		},
+		{
+			"attribute not last",
+			"System/@id/EventID",
+			Path{},
+			"an attribute must be the last step",
+		},
+		{
+			"only an attribute",
+			"@id",
+			Path{},
+			"the path must select an element",
+		},
+		{
+			"invalid predicate",
+			"Data[Name='foo']",
+			Path{},
+			`invalid predicate "Name='foo'": expecting a position or an attribute comparison`,
+		},
+		{
+			"unterminated predicate",
+			"Data[@Name='foo'",
+			Path{},
+			"missing closing ']'",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.expr)
+			if tt.err != "" {
+				require.EqualError(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -41,6 +41,16 @@ func (m *MatrixStepEvaluator) Next() (bool, int64, StepResult) {
 	vec := make(promql.Vector, 0, len(m.m))
 
 	for i, series := range m.m {
+		if len(series.Histograms) > 0 && series.Histograms[0].T == ts {
+			vec = append(vec, promql.Sample{
+				Metric: series.Metric,
+				T:      series.Histograms[0].T,
+				H:      series.Histograms[0].H,
+			})
+			m.m[i].Histograms = m.m[i].Histograms[1:]
+			continue
+		}
+
 		ln := len(series.Floats)
 
 		if ln == 0 || series.Floats[0].T != ts {
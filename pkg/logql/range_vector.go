@@ -7,6 +7,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/prometheus/prometheus/model/histogram"
 	"github.com/prometheus/prometheus/model/labels"
 	"github.com/prometheus/prometheus/promql"
 	promql_parser "github.com/prometheus/prometheus/promql/parser"
@@ -55,6 +56,38 @@ func newRangeVectorIterator(
 	if selRange >= step && start != end {
 		overlap = true
 	}
+	if expr.Operation == syntax.OpRangeTypePredictLinear {
+		// predict_linear extrapolates from the time of the evaluation, which is only known by the iterator.
+		it := &batchRangeVectorIterator{
+			iter:     it,
+			step:     step,
+			end:      end,
+			selRange: selRange,
+			metrics:  map[string]labels.Labels{},
+			window:   map[string]*promql.Series{},
+			current:  start - step, // first loop iteration will set it to start
+			offset:   offset,
+		}
+		duration := *expr.Params
+		it.agg = func(samples []promql.FPoint) float64 {
+			return predictLinear(samples, it.current+it.offset, duration)
+		}
+		return it, nil
+	}
+	if expr.Operation == syntax.OpRangeTypeHistogram {
+		// histogram_over_time returns native histograms instead of floats.
+		return &batchRangeVectorIterator{
+			iter:     it,
+			step:     step,
+			end:      end,
+			selRange: selRange,
+			metrics:  map[string]labels.Labels{},
+			window:   map[string]*promql.Series{},
+			histAgg:  histogramOverTime,
+			current:  start - step, // first loop iteration will set it to start
+			offset:   offset,
+		}, nil
+	}
 	if !overlap {
 		_, err := streamingAggregator(expr)
 		if err != nil {
@@ -97,6 +130,7 @@ type batchRangeVectorIterator struct {
 	metrics                              map[string]labels.Labels
 	at                                   []promql.Sample
 	agg                                  BatchRangeVectorAggregator
+	histAgg                              func([]promql.FPoint) *histogram.FloatHistogram
 }
 
 func (r *batchRangeVectorIterator) Next() bool {
@@ -194,11 +228,16 @@ func (r *batchRangeVectorIterator) At() (int64, StepResult) {
 	// convert ts from nano to milli seconds as the iterator work with nanoseconds
 	ts := r.current/1e+6 + r.offset/1e+6
 	for _, series := range r.window {
-		r.at = append(r.at, promql.Sample{
-			F:      r.agg(series.Floats),
+		sample := promql.Sample{
 			T:      ts,
 			Metric: series.Metric,
-		})
+		}
+		if r.histAgg != nil {
+			sample.H = r.histAgg(series.Floats)
+		} else {
+			sample.F = r.agg(series.Floats)
+		}
+		r.at = append(r.at, sample)
 	}
 	return ts, SampleVector(r.at)
 }
@@ -250,6 +289,8 @@ func aggregator(r *syntax.RangeAggregationExpr) (BatchRangeVectorAggregator, err
 		return last, nil
 	case syntax.OpRangeTypeAbsent:
 		return one, nil
+	case syntax.OpRangeTypeDeriv:
+		return deriv, nil
 	default:
 		return nil, fmt.Errorf(syntax.UnsupportedErr, r.Operation)
 	}
@@ -504,6 +545,55 @@ func one(_ []promql.FPoint) float64 {
 	return 1.0
 }
 
+// deriv calculates the per-second derivative of the samples using a simple linear regression.
+func deriv(samples []promql.FPoint) float64 {
+	if len(samples) < 2 {
+		return math.NaN()
+	}
+	slope, _ := linearRegression(samples, samples[0].T)
+	return slope
+}
+
+// predictLinear predicts the value of the samples in duration seconds from the given timestamp in nanoseconds,
+// using a simple linear regression.
+func predictLinear(samples []promql.FPoint, ts int64, duration float64) float64 {
+	if len(samples) < 2 {
+		return math.NaN()
+	}
+	slope, intercept := linearRegression(samples, ts)
+	return slope*duration + intercept
+}
+
+// linearRegression returns the slope per second and the intercept at the given timestamp in nanoseconds
+// of the least squares fit of the samples.
+func linearRegression(samples []promql.FPoint, interceptTime int64) (slope, intercept float64) {
+	var n, sumX, sumY, sumXY, sumX2 float64
+	constY := true
+	for _, sample := range samples {
+		if sample.F != samples[0].F {
+			constY = false
+		}
+		x := float64(sample.T-interceptTime) / 1e9
+		n++
+		sumX += x
+		sumY += sample.F
+		sumXY += x * sample.F
+		sumX2 += x * x
+	}
+	if constY {
+		if math.IsInf(samples[0].F, 0) {
+			return math.NaN(), math.NaN()
+		}
+		return 0, samples[0].F
+	}
+	covXY := sumXY - sumX*sumY/n
+	varX := sumX2 - sumX*sumX/n
+
+	slope = covXY / varX
+	intercept = sumY/n - slope*sumX/n
+	return slope, intercept
+}
+
 // streaming range agg
 type streamRangeVectorIterator struct {
 	iter                                 iter.PeekingSampleIterator
@@ -627,6 +717,8 @@ func streamingAggregator(r *syntax.RangeAggregationExpr) (RangeStreamingAgg, err
 		return &LastOverTime{}, nil
 	case syntax.OpRangeTypeAbsent:
 		return &OneOverTime{}, nil
+	case syntax.OpRangeTypeDeriv:
+		return &DerivOverTime{}, nil
 	default:
 		return nil, fmt.Errorf(syntax.UnsupportedErr, r.Operation)
 	}
@@ -851,3 +943,15 @@ func (a *OneOverTime) agg(_ promql.FPoint) {
 func (a *OneOverTime) at() float64 {
 	return 1.0
 }
+
+type DerivOverTime struct {
+	samples []promql.FPoint
+}
+
+func (a *DerivOverTime) agg(sample promql.FPoint) {
+	a.samples = append(a.samples, sample)
+}
+
+func (a *DerivOverTime) at() float64 {
+	return deriv(a.samples)
+}
@@ -175,6 +175,22 @@ func (m RangeMapper) Map(expr syntax.SampleExpr, vectorAggrPushdown *syntax.Vect
 		}
 		e.Left = lhsMapped
 		return e, nil
+	case *syntax.FunctionExpr:
+		if e.Left == nil {
+			return e, nil
+		}
+		// The outer vector aggregation can't be pushed down, since the function
+		// must be applied to the samples before they are aggregated.
+		lhsMapped, err := m.Map(e.Left, nil, recorder)
+		if err != nil {
+			return nil, err
+		}
+		e.Left = lhsMapped
+		return e, nil
+	case *syntax.SubqueryAggregationExpr:
+		// The inner expression is evaluated at absolute steps of the subquery, so
+		// the aggregation over time can't be split into sub-ranges.
+		return e, nil
 	case *syntax.LiteralExpr:
 		return e, nil
 	case *syntax.VectorExpr:
@@ -579,6 +595,8 @@ func isSplittableByRange(expr syntax.SampleExpr) bool {
 		return isSplittableByRange(e.SampleExpr) || literalLHS && isSplittableByRange(e.RHS) || literalRHS
 	case *syntax.LabelReplaceExpr:
 		return isSplittableByRange(e.Left)
+	case *syntax.FunctionExpr:
+		return e.Left != nil && isSplittableByRange(e.Left)
 	case *syntax.VectorExpr:
 		return false
 	default:
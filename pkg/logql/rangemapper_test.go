@@ -66,6 +66,14 @@ func Test_SplitRangeInterval(t *testing.T) {
 			)`,
 			2,
 		},
+		{
+			`count_over_time({app="foo"} | sample 0.1 by (trace_id) --extrapolate [4s])`,
+			`sum without () (
+				downstream<count_over_time({app="foo"} | sample 0.1 by (trace_id) --extrapolate [2s] offset 2s), shard=<nil>>
+				++ downstream<count_over_time({app="foo"} | sample 0.1 by (trace_id) --extrapolate [2s]), shard=<nil>>
+			)`,
+			2,
+		},
 		{
 			`rate({app="foo"}[4s] offset 1m)`,
 			`(sum without () (
@@ -99,8 +99,12 @@ func (m ShardMapper) Map(expr syntax.Expr, r *downstreamRecorder, topLevel bool)
 		return m.mapVectorAggregationExpr(e, r, topLevel)
 	case *syntax.LabelReplaceExpr:
 		return m.mapLabelReplaceExpr(e, r, topLevel)
+	case *syntax.FunctionExpr:
+		return m.mapFunctionExpr(e, r, topLevel)
 	case *syntax.RangeAggregationExpr:
 		return m.mapRangeAggregationExpr(e, r, topLevel)
+	case *syntax.SubqueryAggregationExpr:
+		return m.mapSubqueryAggregationExpr(e, r)
 	case *syntax.BinOpExpr:
 		return m.mapBinOpExpr(e, r, topLevel)
 	default:
@@ -161,6 +165,16 @@ func (m ShardMapper) mapBinOpExpr(e *syntax.BinOpExpr, r *downstreamRecorder, to
 }
 
 func (m ShardMapper) mapLogSelectorExpr(expr syntax.LogSelectorExpr, r *downstreamRecorder) (syntax.LogSelectorExpr, uint64, error) {
+	// Duplicates can be in different shards, so the downstream queries don't
+	// deduplicate their log lines, but the merged log lines are.
+	if dedup, rest := splitDedupStage(expr); dedup != nil {
+		mapped, bytesPerShard, err := m.mapLogSelectorExpr(rest, r)
+		if err != nil {
+			return nil, 0, err
+		}
+		return &DedupLogSelectorExpr{LogSelectorExpr: mapped, dedup: dedup}, bytesPerShard, nil
+	}
+
 	var head *ConcatLogSelectorExpr
 	shards, maxBytesPerShard, err := m.shards.Shards(expr)
 	if err != nil {
@@ -189,6 +203,26 @@ func (m ShardMapper) mapLogSelectorExpr(expr syntax.LogSelectorExpr, r *downstre
 	return head, maxBytesPerShard, nil
 }
 
+// splitDedupStage returns the dedup stage of the expression and the expression
+// without it. The parser ensures that dedup is the last stage of the pipeline.
+func splitDedupStage(expr syntax.LogSelectorExpr) (*syntax.DedupExpr, syntax.LogSelectorExpr) {
+	p, ok := expr.(*syntax.PipelineExpr)
+	if !ok || len(p.MultiStages) == 0 {
+		return nil, expr
+	}
+	dedup, ok := p.MultiStages[len(p.MultiStages)-1].(*syntax.DedupExpr)
+	if !ok {
+		return nil, expr
+	}
+	if len(p.MultiStages) == 1 {
+		return dedup, p.Left
+	}
+	return dedup, &syntax.PipelineExpr{
+		Left:        p.Left,
+		MultiStages: p.MultiStages[:len(p.MultiStages)-1],
+	}
+}
+
 func (m ShardMapper) mapSampleExpr(expr syntax.SampleExpr, r *downstreamRecorder) (syntax.SampleExpr, uint64, error) {
 	var head *ConcatSampleExpr
 	shards, maxBytesPerShard, err := m.shards.Shards(expr)
@@ -339,6 +373,7 @@ func (m ShardMapper) mapVectorAggregationExpr(expr *syntax.VectorAggregationExpr
 		Grouping:  expr.Grouping,
 		Params:    expr.Params,
 		Operation: expr.Operation,
+		Label:     expr.Label,
 	}, bytesPerShard, nil
 }
 
@@ -415,6 +450,44 @@ func (m ShardMapper) mapLabelReplaceExpr(expr *syntax.LabelReplaceExpr, r *downs
 	return &cpy, bytesPerShard, nil
 }
 
+// mapFunctionExpr shards the argument of a function. The function itself is applied
+// on the query frontend to the merged results of the shards.
+func (m ShardMapper) mapFunctionExpr(expr *syntax.FunctionExpr, r *downstreamRecorder, topLevel bool) (syntax.SampleExpr, uint64, error) {
+	if expr.Left == nil {
+		return expr, 0, nil
+	}
+	subMapped, bytesPerShard, err := m.Map(expr.Left, r, topLevel)
+	if err != nil {
+		return nil, 0, err
+	}
+	cpy := *expr
+	cpy.Left = subMapped.(syntax.SampleExpr)
+	return &cpy, bytesPerShard, nil
+}
+
+// mapSubqueryAggregationExpr shards the inner expression of a subquery. The aggregation over
+// time of its results is evaluated on the query frontend, since it needs the complete
+// result of the inner expression at every step.
+func (m ShardMapper) mapSubqueryAggregationExpr(expr *syntax.SubqueryAggregationExpr, r *downstreamRecorder) (syntax.SampleExpr, uint64, error) {
+	subMapped, bytesPerShard, err := m.Map(expr.Left.Left, r, false)
+	if err != nil {
+		return nil, 0, err
+	}
+	inner, ok := subMapped.(syntax.SampleExpr)
+	if !ok {
+		return nil, 0, badASTMapping(subMapped)
+	}
+	// If the inner expression can't be sharded, the whole subquery is left to the querier.
+	if isNoOp(expr.Left.Left, inner) {
+		return expr, bytesPerShard, nil
+	}
+	cpy := *expr
+	subquery := *expr.Left
+	subquery.Left = inner
+	cpy.Left = &subquery
+	return &cpy, bytesPerShard, nil
+}
+
 // These functions require a different merge strategy than the default
 // concatenation.
 // This is because the same label sets may exist on multiple shards when label-reducing parsing is applied or when
@@ -427,6 +500,7 @@ var rangeMergeMap = map[string]string{
 	syntax.OpRangeTypeBytes:     syntax.OpTypeSum,
 	syntax.OpRangeTypeBytesRate: syntax.OpTypeSum,
 	syntax.OpRangeTypeSum:       syntax.OpTypeSum,
+	syntax.OpRangeTypeHistogram: syntax.OpTypeSum,
 
 	// min & max require taking the min|max of the shards
 	syntax.OpRangeTypeMin: syntax.OpTypeMin,
@@ -440,7 +514,7 @@ func (m ShardMapper) mapRangeAggregationExpr(expr *syntax.RangeAggregationExpr,
 
 	switch expr.Operation {
 
-	case syntax.OpRangeTypeCount, syntax.OpRangeTypeRate, syntax.OpRangeTypeBytes, syntax.OpRangeTypeBytesRate, syntax.OpRangeTypeSum, syntax.OpRangeTypeMax, syntax.OpRangeTypeMin:
+	case syntax.OpRangeTypeCount, syntax.OpRangeTypeRate, syntax.OpRangeTypeBytes, syntax.OpRangeTypeBytesRate, syntax.OpRangeTypeSum, syntax.OpRangeTypeHistogram, syntax.OpRangeTypeMax, syntax.OpRangeTypeMin:
 		// if the expr can reduce labels, it can cause the same labelset to
 		// exist on separate shards and we'll need to merge the results
 		// accordingly. If it does not reduce labels and has no special grouping
@@ -533,6 +607,10 @@ func (m ShardMapper) mapRangeAggregationExpr(expr *syntax.RangeAggregationExpr,
 			Op:         syntax.OpTypeDiv,
 		}, bytesPerShard, nil
 
+	case syntax.OpRangeTypeDeriv, syntax.OpRangeTypePredictLinear:
+		// The series are not split across shards, since labels are not reduced.
+		return m.mapSampleExpr(expr, r)
+
 	case syntax.OpRangeTypeQuantile:
 		if !m.quantileOverTimeSharding {
 			return noOp(expr, m.shards.Resolver())
@@ -663,9 +741,12 @@ func isNoOp(left syntax.Expr, right syntax.Expr) bool {
 }
 
 func isLiteralOrVector(e syntax.Expr) bool {
-	switch e.(type) {
+	switch e := e.(type) {
 	case *syntax.VectorExpr, *syntax.LiteralExpr:
 		return true
+	case *syntax.FunctionExpr:
+		// Functions without argument, like time(), only depend on the time of the evaluation.
+		return e.Left == nil
 	default:
 		return false
 	}
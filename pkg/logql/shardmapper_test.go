@@ -144,6 +144,23 @@ func TestMappingStrings(t *testing.T) {
 			out: `downstream<{foo="bar"} |="foo" |~"bar" | json | (latency>=10s or (foo<5,bar="t")) | line_format "b{{.blip}}", shard=0_of_2>
 					++downstream<{foo="bar"} |="foo" |~"bar" | json | (latency>=10s or (foo<5, bar="t")) | line_format "b{{.blip}}", shard=1_of_2>`,
 		},
+		{
+			in: `{foo="bar"} | dedup`,
+			out: `dedup<downstream<{foo="bar"}, shard=0_of_2>
+					++ downstream<{foo="bar"}, shard=1_of_2> | dedup>`,
+		},
+		{
+			in: `{foo="bar"} |= "foo" | json | dedup 5s by (request_id)`,
+			out: `dedup<downstream<{foo="bar"} |="foo" | json, shard=0_of_2>
+					++ downstream<{foo="bar"} |="foo" | json, shard=1_of_2> | dedup 5s by (request_id)>`,
+		},
+		{
+			in: `sum(count_over_time({foo="bar"} | sample 0.01 by (trace_id) --extrapolate [1m]))`,
+			out: `sum(
+				downstream<sum(count_over_time({foo="bar"} | sample 0.01 by (trace_id) --extrapolate [1m])), shard=0_of_2>
+				++ downstream<sum(count_over_time({foo="bar"} | sample 0.01 by (trace_id) --extrapolate [1m])), shard=1_of_2>
+			)`,
+		},
 		{
 			in: `sum(rate({foo="bar"}[1m]))`,
 			out: `sum(
@@ -151,6 +168,17 @@ func TestMappingStrings(t *testing.T) {
 				++ downstream<sum(rate({foo="bar"}[1m])), shard=1_of_2>
 			)`,
 		},
+		{
+			in: `max_over_time(sum(rate({foo="bar"}[1m]))[1h:5m])`,
+			out: `max_over_time(sum(
+				downstream<sum(rate({foo="bar"}[1m])), shard=0_of_2>
+				++ downstream<sum(rate({foo="bar"}[1m])), shard=1_of_2>
+			)[1h:5m])`,
+		},
+		{
+			in:  `max_over_time(quantile_over_time(0.99, {foo="bar"} | unwrap x [1m])[1h:5m])`,
+			out: `max_over_time(quantile_over_time(0.99,{foo="bar"}|unwrapx[1m])[1h:5m])`,
+		},
 		{
 			in: `max(count(rate({foo="bar"}[5m]))) / 2`,
 			out: `(max(
@@ -320,6 +348,29 @@ func TestMappingStrings(t *testing.T) {
 					)
 				)`,
 		},
+		{
+			in: `clamp_max(sum by (cluster) (rate({foo="bar"}[5m])), 10)`,
+			out: `clamp_max(
+				sum by (cluster) (
+					downstream<sum by (cluster) (rate({foo="bar"}[5m])), shard=0_of_2>
+					++ downstream<sum by (cluster) (rate({foo="bar"}[5m])), shard=1_of_2>
+				),
+				10
+			)`,
+		},
+		{
+			in:  `time() - sum(rate({foo="bar"}[5m]))`,
+			out: `(time() - sum(downstream<sum(rate({foo="bar"}[5m])), shard=0_of_2> ++ downstream<sum(rate({foo="bar"}[5m])), shard=1_of_2>))`,
+		},
+		{
+			in: `deriv({foo="bar"} | unwrap latency [5m])`,
+			out: `downstream<deriv({foo="bar"} | unwrap latency [5m]), shard=0_of_2>
+				++ downstream<deriv({foo="bar"} | unwrap latency [5m]), shard=1_of_2>`,
+		},
+		{
+			in:  `deriv({foo="bar"} | logfmt | unwrap latency [5m]) by (cluster)`,
+			out: `deriv({foo="bar"} | logfmt | unwrap latency [5m]) by (cluster)`,
+		},
 		{
 			in: `sum(count_over_time({foo="bar"} | logfmt | label_format bar=baz | bar="buz" [5m])) by (bar)`,
 			out: `sum by (bar) (
@@ -364,6 +415,14 @@ func TestMappingStrings(t *testing.T) {
 				downstream<max_over_time({foo="ugh"}|unwrapbaz[1m])by(),shard=1_of_2>
 			)`,
 		},
+		{
+			in: `histogram_over_time({foo="ugh"} | unwrap baz [1m]) by ()`,
+			out: `sum(
+				downstream<histogram_over_time({foo="ugh"}|unwrapbaz[1m])by(),shard=0_of_2>
+				++
+				downstream<histogram_over_time({foo="ugh"}|unwrapbaz[1m])by(),shard=1_of_2>
+			)`,
+		},
 		{
 			in: `avg(avg_over_time({job=~"myapps.*"} |= "stats" | json busy="utilization" | unwrap busy [5m]))`,
 			out: `(
@@ -0,0 +1,165 @@
+package logql
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/promql"
+
+	"github.com/grafana/loki/v3/pkg/iter"
+	"github.com/grafana/loki/v3/pkg/logproto"
+	"github.com/grafana/loki/v3/pkg/logql/syntax"
+)
+
+// defaultSubqueryStep is the resolution of a subquery without step in an instant query.
+const defaultSubqueryStep = time.Minute
+
+// subqueryParams overrides the time range, step and expression of the query params
+// to evaluate the inner expression of a subquery.
+type subqueryParams struct {
+	Params
+	expr       syntax.SampleExpr
+	start, end time.Time
+	step       time.Duration
+}
+
+func (p subqueryParams) QueryString() string        { return p.expr.String() }
+func (p subqueryParams) Start() time.Time           { return p.start }
+func (p subqueryParams) End() time.Time             { return p.end }
+func (p subqueryParams) Step() time.Duration        { return p.step }
+func (p subqueryParams) GetExpression() syntax.Expr { return p.expr }
+
+// subqueryStep returns the resolution at which the inner expression of the subquery is evaluated.
+func subqueryStep(expr *syntax.SubqueryExpr, q Params) time.Duration {
+	if expr.Step != 0 {
+		return expr.Step
+	}
+	if q.Step() != 0 {
+		return q.Step()
+	}
+	return defaultSubqueryStep
+}
+
+// subqueryRange returns the time range of the evaluations of the inner expression of the subquery.
+// Like in Prometheus the evaluations are aligned to multiples of the step, rather than to the start
+// of the query, so that they are the same regardless of how the query is split.
+func subqueryRange(expr *syntax.SubqueryExpr, q Params) (start, end time.Time) {
+	step := subqueryStep(expr, q).Nanoseconds()
+
+	s := q.Start().Add(-expr.Offset).Add(-expr.Interval).UnixNano()
+	aligned := s - s%step
+	if aligned < s {
+		aligned += step
+	}
+
+	e := q.End().Add(-expr.Offset).UnixNano()
+	return time.Unix(0, aligned), time.Unix(0, e-e%step)
+}
+
+func newSubqueryAggEvaluator(
+	ctx context.Context,
+	evFactory SampleEvaluatorFactory,
+	expr *syntax.SubqueryAggregationExpr,
+	q Params,
+) (StepEvaluator, error) {
+	start, end := subqueryRange(expr.Left, q)
+
+	var inner StepEvaluator
+	var it iter.SampleIterator = iter.NoopSampleIterator
+	if !start.After(end) {
+		var err error
+		inner, err = evFactory.NewStepEvaluator(ctx, evFactory, expr.Left.Left, subqueryParams{
+			Params: q,
+			expr:   expr.Left.Left,
+			start:  start,
+			end:    end,
+			step:   subqueryStep(expr.Left, q),
+		})
+		if err != nil {
+			return nil, err
+		}
+		it = &stepEvaluatorSampleIterator{ev: inner}
+	}
+
+	// The samples of the subquery are aggregated like unwrapped values of a log range.
+	rangeExpr := &syntax.RangeAggregationExpr{
+		Left: &syntax.LogRangeExpr{
+			Interval: expr.Left.Interval,
+			Offset:   expr.Left.Offset,
+			Unwrap:   &syntax.UnwrapExpr{},
+		},
+		Operation: expr.Operation,
+		Params:    expr.Params,
+	}
+	rangeIter, err := newRangeVectorIterator(
+		iter.NewPeekingSampleIterator(it), rangeExpr,
+		expr.Left.Interval.Nanoseconds(),
+		q.Step().Nanoseconds(),
+		q.Start().UnixNano(), q.End().UnixNano(), expr.Left.Offset.Nanoseconds(),
+	)
+	if err != nil {
+		if inner != nil {
+			_ = inner.Close()
+		}
+		return nil, err
+	}
+
+	var ev StepEvaluator = &RangeVectorEvaluator{iter: rangeIter}
+	if expr.Operation == syntax.OpRangeTypeAbsent {
+		// The inner expression may have changed the labels of the selected streams,
+		// so absent series can't be labelled from the selector.
+		ev = &AbsentRangeVectorEvaluator{iter: rangeIter, lbs: labels.EmptyLabels()}
+	}
+	return &SubqueryEvaluator{StepEvaluator: ev, inner: inner}, nil
+}
+
+// SubqueryEvaluator aggregates over time the results of the evaluation of a subquery.
+type SubqueryEvaluator struct {
+	StepEvaluator
+	// inner evaluates the inner expression of the subquery. It is nil if the
+	// subquery range doesn't contain any evaluation.
+	inner StepEvaluator
+}
+
+// stepEvaluatorSampleIterator iterates over the samples of all the steps of a step evaluator, in time order.
+// The timestamps of the samples are the ones of their step.
+type stepEvaluatorSampleIterator struct {
+	ev StepEvaluator
+
+	ts  int64
+	vec promql.Vector
+	i   int
+	cur promql.Sample
+}
+
+func (it *stepEvaluatorSampleIterator) Next() bool {
+	for it.i >= len(it.vec) {
+		ok, ts, r := it.ev.Next()
+		if !ok {
+			return false
+		}
+		it.ts = ts
+		it.vec = r.SampleVector()
+		it.i = 0
+	}
+	it.cur = it.vec[it.i]
+	it.i++
+	return true
+}
+
+func (it *stepEvaluatorSampleIterator) At() logproto.Sample {
+	return logproto.Sample{
+		Timestamp: it.ts * int64(time.Millisecond),
+		Value:     it.cur.F,
+		Hash:      it.cur.Metric.Hash(),
+	}
+}
+
+func (it *stepEvaluatorSampleIterator) Labels() string { return it.cur.Metric.String() }
+
+func (it *stepEvaluatorSampleIterator) StreamHash() uint64 { return it.cur.Metric.Hash() }
+
+func (it *stepEvaluatorSampleIterator) Err() error { return it.ev.Error() }
+
+func (it *stepEvaluatorSampleIterator) Close() error { return it.ev.Close() }
@@ -0,0 +1,109 @@
+package logql
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/grafana/dskit/user"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/v3/pkg/logproto"
+	"github.com/grafana/loki/v3/pkg/logql/syntax"
+)
+
+func TestSubqueryEquivalence(t *testing.T) {
+	var (
+		rounds  = 20
+		streams = randomStreams(10, rounds+1, 1, []string{"a", "b"}, true)
+		start   = time.Unix(0, 0)
+		end     = time.Unix(int64(rounds), 0)
+		step    = time.Second
+	)
+
+	for _, tc := range []struct {
+		subquery, expected string
+	}{
+		// The windows of the inner range aggregations cover the whole range of the subquery.
+		{`sum_over_time(count_over_time({a=~".+"}[1s])[5s:1s])`, `count_over_time({a=~".+"}[5s])`},
+		{`sum_over_time(count_over_time({a=~".+"}[1s])[6s:])`, `count_over_time({a=~".+"}[6s])`},
+		{`sum_over_time(sum by (a) (count_over_time({a=~".+"}[2s]))[6s:2s])`, `sum by (a) (count_over_time({a=~".+"}[6s]))`},
+		{`sum_over_time(count_over_time({a=~".+"}[1s])[5s:1s] offset 3s)`, `count_over_time({a=~".+"}[5s] offset 3s)`},
+		{`rate(count_over_time({a=~".+"}[1s])[5s:1s])`, `rate({a=~".+"}[5s])`},
+		{`max_over_time(sum(rate({a=~".+"}[1s]))[1s:1s])`, `sum(rate({a=~".+"}[1s]))`},
+		{`count_over_time(sum(count_over_time({a=~".+"}[1s]))[5s:1s])`, `sum(count_over_time({a=~".+"}[1s])) * 0 + 5`},
+		{`absent_over_time(sum(count_over_time({a="none"}[1s]))[5s:1s])`, `vector(1)`},
+	} {
+		eng := NewEngine(EngineOpts{}, NewMockQuerier(1, streams), NoLimits, log.NewNopLogger())
+		ctx := user.InjectOrgID(context.Background(), "fake")
+
+		t.Run(tc.subquery, func(t *testing.T) {
+			exec := func(qs string) any {
+				params, err := NewLiteralParams(qs, start.Add(10*time.Second), end, step, 0, logproto.FORWARD, 100, nil, nil)
+				require.NoError(t, err)
+				res, err := eng.Query(params).Exec(ctx)
+				require.NoError(t, err)
+				return res.Data
+			}
+			require.Equal(t, exec(tc.expected), exec(tc.subquery))
+		})
+	}
+}
+
+func TestSubqueryRange(t *testing.T) {
+	for _, tc := range []struct {
+		name       string
+		query      string
+		start, end time.Time
+		step       time.Duration
+
+		expectedStart, expectedEnd time.Time
+	}{
+		{
+			name:          "aligned to the step of the subquery",
+			query:         `max_over_time(sum(rate({app="foo"}[1m]))[1h:5m])`,
+			start:         time.Unix(7230, 0),
+			end:           time.Unix(10830, 0),
+			step:          time.Minute,
+			expectedStart: time.Unix(3900, 0),
+			expectedEnd:   time.Unix(10800, 0),
+		},
+		{
+			name:          "with offset",
+			query:         `max_over_time(sum(rate({app="foo"}[1m]))[1h:5m] offset 10m)`,
+			start:         time.Unix(7230, 0),
+			end:           time.Unix(10830, 0),
+			step:          time.Minute,
+			expectedStart: time.Unix(3300, 0),
+			expectedEnd:   time.Unix(10200, 0),
+		},
+		{
+			name:          "step of the query",
+			query:         `max_over_time(sum(rate({app="foo"}[1m]))[1h:])`,
+			start:         time.Unix(7230, 0),
+			end:           time.Unix(10830, 0),
+			step:          30 * time.Second,
+			expectedStart: time.Unix(3630, 0),
+			expectedEnd:   time.Unix(10830, 0),
+		},
+		{
+			name:          "instant query",
+			query:         `max_over_time(sum(rate({app="foo"}[1m]))[1h:])`,
+			start:         time.Unix(7230, 0),
+			end:           time.Unix(7230, 0),
+			expectedStart: time.Unix(3660, 0),
+			expectedEnd:   time.Unix(7200, 0),
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			params, err := NewLiteralParams(tc.query, tc.start, tc.end, tc.step, 0, logproto.FORWARD, 0, nil, nil)
+			require.NoError(t, err)
+			expr := params.GetExpression().(*syntax.SubqueryAggregationExpr)
+
+			start, end := subqueryRange(expr.Left, params)
+			require.Equal(t, tc.expectedStart.UTC(), start.UTC())
+			require.Equal(t, tc.expectedEnd.UTC(), end.UTC())
+		})
+	}
+}
@@ -63,10 +63,21 @@ func (LineFmtExpr) isExpr()                {}
 func (LabelFmtExpr) isExpr()               {}
 func (JSONExpressionParserExpr) isExpr()   {}
 func (LogfmtExpressionParserExpr) isExpr() {}
+func (CSVParserExpr) isExpr()              {}
+func (XMLExpressionParserExpr) isExpr()    {}
+func (StatsExpr) isExpr()                  {}
+func (JoinExpr) isExpr()                   {}
+func (GeoIPExpr) isExpr()                  {}
+func (SamplingExpr) isExpr()               {}
+func (DedupExpr) isExpr()                  {}
+func (MacroExpr) isExpr()                  {}
 func (LogRangeExpr) isExpr()               {}
 func (OffsetExpr) isExpr()                 {}
 func (UnwrapExpr) isExpr()                 {}
 func (MultiVariantExpr) isExpr()           {}
+func (SubqueryExpr) isExpr()               {}
+func (SubqueryAggregationExpr) isExpr()    {}
+func (FunctionExpr) isExpr()               {}
 
 // LogSelectorExpr is a expression filtering and returning logs.
 type LogSelectorExpr interface {
@@ -96,12 +107,14 @@ type SampleExpr interface {
 	isSampleExpr()
 }
 
-func (RangeAggregationExpr) isSampleExpr()  {}
-func (VectorAggregationExpr) isSampleExpr() {}
-func (LiteralExpr) isSampleExpr()           {}
-func (VectorExpr) isSampleExpr()            {}
-func (LabelReplaceExpr) isSampleExpr()      {}
-func (MultiVariantExpr) isSampleExpr()      {}
+func (RangeAggregationExpr) isSampleExpr()    {}
+func (VectorAggregationExpr) isSampleExpr()   {}
+func (LiteralExpr) isSampleExpr()             {}
+func (VectorExpr) isSampleExpr()              {}
+func (LabelReplaceExpr) isSampleExpr()        {}
+func (MultiVariantExpr) isSampleExpr()        {}
+func (SubqueryAggregationExpr) isSampleExpr() {}
+func (FunctionExpr) isSampleExpr()            {}
 
 // StageExpr is an expression defining a single step into a log pipeline
 type StageExpr interface {
@@ -123,6 +136,14 @@ func (LineFmtExpr) isStageExpr()                {}
 func (LabelFmtExpr) isStageExpr()               {}
 func (JSONExpressionParserExpr) isStageExpr()   {}
 func (LogfmtExpressionParserExpr) isStageExpr() {}
+func (CSVParserExpr) isStageExpr()              {}
+func (XMLExpressionParserExpr) isStageExpr()    {}
+func (StatsExpr) isStageExpr()                  {}
+func (JoinExpr) isStageExpr()                   {}
+func (GeoIPExpr) isStageExpr()                  {}
+func (SamplingExpr) isStageExpr()               {}
+func (DedupExpr) isStageExpr()                  {}
+func (MacroExpr) isStageExpr()                  {}
 
 func Clone[T Expr](e T) (T, error) {
 	var empty T
@@ -154,11 +175,81 @@ func ExtractLineFilters(e Expr) []LineFilterExpr {
 				filters = append(filters, *e)
 			}
 		},
+		// The line filters of the joined query don't apply to this query.
+		VisitJoinFn: func(_ RootVisitor, _ *JoinExpr) {},
 	}
 	e.Accept(visitor)
 	return filters
 }
 
+// ExtractStats returns the stats stage of the expression, or nil if the
+// expression doesn't aggregate its log lines into a table.
+func ExtractStats(e Expr) *StatsExpr {
+	if e == nil {
+		return nil
+	}
+	var stats *StatsExpr
+	visitor := &DepthFirstTraversal{
+		VisitStatsFn: func(_ RootVisitor, e *StatsExpr) {
+			stats = e
+		},
+		VisitJoinFn: func(_ RootVisitor, _ *JoinExpr) {},
+	}
+	e.Accept(visitor)
+	return stats
+}
+
+// ExtractJoin returns the join stage of the expression, or nil if the
+// expression doesn't join with another query.
+func ExtractJoin(e Expr) *JoinExpr {
+	if e == nil {
+		return nil
+	}
+	var join *JoinExpr
+	visitor := &DepthFirstTraversal{
+		VisitJoinFn: func(_ RootVisitor, e *JoinExpr) {
+			join = e
+		},
+	}
+	e.Accept(visitor)
+	return join
+}
+
+// ExtractDedup returns the dedup stage of the expression, or nil if the
+// expression doesn't deduplicate its log lines.
+func ExtractDedup(e Expr) *DedupExpr {
+	if e == nil {
+		return nil
+	}
+	var dedup *DedupExpr
+	visitor := &DepthFirstTraversal{
+		VisitDedupFn: func(_ RootVisitor, e *DedupExpr) {
+			dedup = e
+		},
+		VisitJoinFn: func(_ RootVisitor, _ *JoinExpr) {},
+	}
+	e.Accept(visitor)
+	return dedup
+}
+
+// ExtractMacro returns the first macro stage of the expression that is not
+// expanded, or nil if the expression doesn't reference any macro.
+func ExtractMacro(e Expr) *MacroExpr {
+	if e == nil {
+		return nil
+	}
+	var macro *MacroExpr
+	visitor := &DepthFirstTraversal{
+		VisitMacroFn: func(_ RootVisitor, e *MacroExpr) {
+			if macro == nil {
+				macro = e
+			}
+		},
+	}
+	e.Accept(visitor)
+	return macro
+}
+
 func ExtractLabelFiltersBeforeParser(e Expr) []*LabelFilterExpr {
 	if e == nil {
 		return nil
@@ -187,9 +278,13 @@ func ExtractLabelFiltersBeforeParser(e Expr) []*LabelFilterExpr {
 		VisitLabelParserFn:            func(_ RootVisitor, _ *LineParserExpr) { foundParseStage = true },
 		VisitJSONExpressionParserFn:   func(_ RootVisitor, _ *JSONExpressionParserExpr) { foundParseStage = true },
 		VisitLogfmtExpressionParserFn: func(_ RootVisitor, _ *LogfmtExpressionParserExpr) { foundParseStage = true },
+		VisitCSVParserFn:              func(_ RootVisitor, _ *CSVParserExpr) { foundParseStage = true },
+		VisitXMLExpressionParserFn:    func(_ RootVisitor, _ *XMLExpressionParserExpr) { foundParseStage = true },
 		VisitLabelFmtFn:               func(_ RootVisitor, _ *LabelFmtExpr) { foundParseStage = true },
 		VisitKeepLabelFn:              func(_ RootVisitor, _ *KeepLabelsExpr) { foundParseStage = true },
 		VisitDropLabelsFn:             func(_ RootVisitor, _ *DropLabelsExpr) { foundParseStage = true },
+		// The label filters of the joined query don't apply to this query.
+		VisitJoinFn: func(_ RootVisitor, _ *JoinExpr) {},
 	}
 	e.Accept(visitor)
 	return filters
@@ -762,6 +857,8 @@ func (e *LineParserExpr) Stage() (log.Stage, error) {
 		return log.NewUnpackParser(), nil
 	case OpParserTypePattern:
 		return log.NewPatternParser(e.Param)
+	case OpParserTypeXML:
+		return log.NewXMLParser(), nil
 	default:
 		return nil, fmt.Errorf("unknown parser operator: %s", e.Op)
 	}
@@ -842,6 +939,158 @@ func (e *DecolorizeExpr) Walk(f WalkFn) { f(e) }
 
 func (e *DecolorizeExpr) Accept(v RootVisitor) { v.VisitDecolorize(e) }
 
+// GeoIPExpr adds the location and autonomous system of the ip address of a
+// label as labels, e.g. `| geoip src_ip`.
+type GeoIPExpr struct {
+	Source string
+}
+
+func newGeoIPExpr(source string) *GeoIPExpr {
+	return &GeoIPExpr{Source: source}
+}
+
+func (e *GeoIPExpr) Shardable(_ bool) bool { return true }
+
+func (e *GeoIPExpr) Stage() (log.Stage, error) {
+	return log.NewGeoIPStage(e.Source)
+}
+
+func (e *GeoIPExpr) String() string {
+	return fmt.Sprintf("%s %s %s", OpPipe, OpGeoIP, e.Source)
+}
+
+func (e *GeoIPExpr) Walk(f WalkFn) { f(e) }
+
+func (e *GeoIPExpr) Accept(v RootVisitor) { v.VisitGeoIP(e) }
+
+// SamplingExpr keeps a deterministic subset of the log lines, e.g.
+// `| sample 0.01` or `| sample 0.01 by (trace_id)`.
+// With --extrapolate, metric queries that count log lines or bytes scale
+// their results by the inverse of the ratio.
+type SamplingExpr struct {
+	Ratio       float64
+	By          []string
+	Extrapolate bool
+}
+
+func newSamplingExpr(ratio string, by []string, flags []string) *SamplingExpr {
+	e := &SamplingExpr{Ratio: mustNewFloat(ratio), By: by}
+	if e.Ratio <= 0 || e.Ratio > 1 {
+		panic(logqlmodel.NewParseError(fmt.Sprintf("invalid sample ratio %s, must be greater than 0 and less than or equal to 1", ratio), 0, 0))
+	}
+	for _, f := range flags {
+		if f != OpExtrapolate {
+			panic(logqlmodel.NewParseError(fmt.Sprintf("invalid flag %s for sample, only %s is supported", f, OpExtrapolate), 0, 0))
+		}
+		e.Extrapolate = true
+	}
+	return e
+}
+
+// Sampling is deterministic, so every shard keeps the same lines.
+func (e *SamplingExpr) Shardable(_ bool) bool { return true }
+
+func (e *SamplingExpr) Stage() (log.Stage, error) {
+	return log.NewSampleStage(e.Ratio, e.By)
+}
+
+func (e *SamplingExpr) String() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%s %s %s", OpPipe, OpSample, strconv.FormatFloat(e.Ratio, 'f', -1, 64)))
+	if len(e.By) > 0 {
+		sb.WriteString(fmt.Sprintf(" by (%s)", strings.Join(e.By, ",")))
+	}
+	if e.Extrapolate {
+		sb.WriteString(" ")
+		sb.WriteString(OpExtrapolate)
+	}
+	return sb.String()
+}
+
+func (e *SamplingExpr) Walk(f WalkFn) { f(e) }
+
+func (e *SamplingExpr) Accept(v RootVisitor) { v.VisitSampling(e) }
+
+// sampleExtrapolation returns the factor metric queries over the stages scale
+// their results by, which is the inverse of the ratios of the sample stages
+// with --extrapolate.
+func sampleExtrapolation(stages MultiStageExpr) float64 {
+	factor := 1.0
+	for _, stage := range stages {
+		if sample, ok := stage.(*SamplingExpr); ok && sample.Extrapolate {
+			factor /= sample.Ratio
+		}
+	}
+	return factor
+}
+
+// DedupExpr collapses duplicated log lines into the first one, e.g.
+// `| dedup` or `| dedup 5s by (request_id)`.
+// Log lines are duplicates if they have the same values for the labels in By,
+// or the same content without labels, and their timestamps are at most Window
+// apart. Duplicates can be spread over ingesters and shards, so the log lines
+// are deduplicated by the engine after merging them, rather than by a stage of
+// the pipeline.
+type DedupExpr struct {
+	By     []string
+	Window time.Duration
+}
+
+func newDedupExpr(window time.Duration, by []string) *DedupExpr {
+	if window < 0 {
+		panic(logqlmodel.NewParseError(fmt.Sprintf("invalid dedup window %s, must be greater than or equal to 0", model.Duration(window)), 0, 0))
+	}
+	return &DedupExpr{By: by, Window: window}
+}
+
+// The shard mapper deduplicates the log lines after merging the shards.
+func (e *DedupExpr) Shardable(_ bool) bool { return true }
+
+func (e *DedupExpr) Stage() (log.Stage, error) {
+	return log.NoopStage, nil
+}
+
+func (e *DedupExpr) String() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%s %s", OpPipe, OpDedup))
+	if e.Window > 0 {
+		sb.WriteString(fmt.Sprintf(" %s", model.Duration(e.Window)))
+	}
+	if len(e.By) > 0 {
+		sb.WriteString(fmt.Sprintf(" by (%s)", strings.Join(e.By, ",")))
+	}
+	return sb.String()
+}
+
+func (e *DedupExpr) Walk(f WalkFn) { f(e) }
+
+func (e *DedupExpr) Accept(v RootVisitor) { v.VisitDedup(e) }
+
+// MacroExpr references a named pipeline of a tenant, e.g. `| @access_log`.
+// It is replaced by the stages of the pipeline when the query is parsed with
+// ParseExprWithMacros, and can't be executed otherwise.
+type MacroExpr struct {
+	Name string
+}
+
+func newMacroExpr(name string) *MacroExpr {
+	return &MacroExpr{Name: name}
+}
+
+func (e *MacroExpr) Shardable(_ bool) bool { return false }
+
+func (e *MacroExpr) Stage() (log.Stage, error) {
+	return nil, fmt.Errorf("macro %s%s is not expanded", OpMacro, e.Name)
+}
+
+func (e *MacroExpr) String() string {
+	return fmt.Sprintf("%s %s%s", OpPipe, OpMacro, e.Name)
+}
+
+func (e *MacroExpr) Walk(f WalkFn) { f(e) }
+
+func (e *MacroExpr) Accept(v RootVisitor) { v.VisitMacro(e) }
+
 type DropLabelsExpr struct {
 	dropLabels []log.NamedLabelMatcher
 }
@@ -944,6 +1193,100 @@ func (e *KeepLabelsExpr) Walk(f WalkFn) { f(e) }
 
 func (e *KeepLabelsExpr) Accept(v RootVisitor) { v.VisitKeepLabel(e) }
 
+// StatsAggregation is a single aggregation of a stats stage, e.g. `avg(latency)`.
+// Label is empty for `count()`, which counts log lines.
+type StatsAggregation struct {
+	Operation string
+	Label     string
+}
+
+func (a StatsAggregation) String() string {
+	return fmt.Sprintf("%s(%s)", a.Operation, a.Label)
+}
+
+// StatsExpr aggregates the log lines of a query into a table, with one row
+// per group and one column per aggregation, e.g.
+// `| stats count(), avg(latency) by (status)`.
+// It is only supported by the new query engine.
+type StatsExpr struct {
+	Aggregations []StatsAggregation
+	Grouping     *Grouping
+}
+
+func newStatsExpr(aggregations []StatsAggregation, groups []string) *StatsExpr {
+	e := &StatsExpr{Aggregations: aggregations}
+	if len(groups) > 0 {
+		e.Grouping = &Grouping{Groups: groups}
+	}
+	return e
+}
+
+func (e *StatsExpr) Shardable(_ bool) bool { return false }
+
+func (e *StatsExpr) Stage() (log.Stage, error) {
+	return nil, errors.New("stats stage is only supported by the new query engine")
+}
+
+func (e *StatsExpr) String() string {
+	var sb strings.Builder
+
+	sb.WriteString(fmt.Sprintf("%s %s ", OpPipe, OpStats))
+
+	for i, agg := range e.Aggregations {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		sb.WriteString(agg.String())
+	}
+	if e.Grouping != nil {
+		sb.WriteString(e.Grouping.String())
+	}
+	return sb.String()
+}
+
+func (e *StatsExpr) Walk(f WalkFn) { f(e) }
+
+func (e *StatsExpr) Accept(v RootVisitor) { v.VisitStats(e) }
+
+// JoinExpr correlates the log lines of a query with the log lines of a second
+// query that have the same values for the labels in On and whose timestamps
+// are at most Window apart, e.g.
+// `| join on (request_id) [30s] ({app="b"} | json)`.
+// Each matched pair yields one log line, so counting the lines of a query
+// counts the matched pairs.
+// It is only supported by the new query engine.
+type JoinExpr struct {
+	On     []string
+	Window time.Duration
+	Right  LogSelectorExpr
+}
+
+func newJoinExpr(on []string, window time.Duration, right LogSelectorExpr) *JoinExpr {
+	return &JoinExpr{On: on, Window: window, Right: right}
+}
+
+func (e *JoinExpr) Shardable(_ bool) bool { return false }
+
+func (e *JoinExpr) Stage() (log.Stage, error) {
+	return nil, errors.New("join stage is only supported by the new query engine")
+}
+
+func (e *JoinExpr) String() string {
+	return fmt.Sprintf("%s %s %s (%s) [%s] (%s)",
+		OpPipe, OpJoin, OpOn, strings.Join(e.On, ", "), model.Duration(e.Window), e.Right.String())
+}
+
+func (e *JoinExpr) Walk(f WalkFn) {
+	if !f(e) {
+		return
+	}
+	if e.Right != nil {
+		e.Right.Walk(f)
+	}
+}
+
+func (e *JoinExpr) Accept(v RootVisitor) { v.VisitJoin(e) }
+
 func (e *LineFmtExpr) Shardable(_ bool) bool { return true }
 
 func (e *LineFmtExpr) Walk(f WalkFn) { f(e) }
@@ -1099,6 +1442,114 @@ func (l *LogfmtExpressionParserExpr) String() string {
 	return sb.String()
 }
 
+type CSVParserExpr struct {
+	Columns   string
+	Delimiter string
+	Quote     string
+}
+
+// newCSVParserExpr creates a csv parser from its columns and its options, which
+// are pairs of option name and value.
+func newCSVParserExpr(columns string, options []string) *CSVParserExpr {
+	e := CSVParserExpr{
+		Columns:   columns,
+		Delimiter: log.CSVDefaultDelimiter,
+		Quote:     log.CSVDefaultQuote,
+	}
+	for i := 0; i+1 < len(options); i += 2 {
+		switch options[i] {
+		case OpCSVDelimiter:
+			e.Delimiter = options[i+1]
+		case OpCSVQuote:
+			e.Quote = options[i+1]
+		default:
+			panic(logqlmodel.NewParseError(fmt.Sprintf("invalid csv parser option: %s", options[i]), 0, 0))
+		}
+	}
+
+	if _, err := e.Stage(); err != nil {
+		panic(logqlmodel.NewParseError(fmt.Sprintf("invalid csv parser: %s", err.Error()), 0, 0))
+	}
+	return &e
+}
+
+func (e *CSVParserExpr) Shardable(_ bool) bool { return true }
+
+func (e *CSVParserExpr) Walk(f WalkFn) { f(e) }
+
+func (e *CSVParserExpr) Accept(v RootVisitor) { v.VisitCSVParser(e) }
+
+func (e *CSVParserExpr) Stage() (log.Stage, error) {
+	return log.NewCSVParser(e.Columns, e.Delimiter, e.Quote)
+}
+
+func (e *CSVParserExpr) String() string {
+	var sb strings.Builder
+	sb.WriteString(OpPipe)
+	sb.WriteString(" ")
+	sb.WriteString(OpParserTypeCSV)
+
+	if e.Columns != "" {
+		sb.WriteString(" ")
+		sb.WriteString(strconv.Quote(e.Columns))
+	}
+
+	// Only the options that differ from the defaults are printed.
+	sep := " "
+	if e.Delimiter != log.CSVDefaultDelimiter {
+		sb.WriteString(sep)
+		sb.WriteString(OpCSVDelimiter)
+		sb.WriteString("=")
+		sb.WriteString(strconv.Quote(e.Delimiter))
+		sep = ","
+	}
+	if e.Quote != log.CSVDefaultQuote {
+		sb.WriteString(sep)
+		sb.WriteString(OpCSVQuote)
+		sb.WriteString("=")
+		sb.WriteString(strconv.Quote(e.Quote))
+	}
+	return sb.String()
+}
+
+type XMLExpressionParserExpr struct {
+	Expressions []log.LabelExtractionExpr
+}
+
+func newXMLExpressionParser(expressions []log.LabelExtractionExpr) *XMLExpressionParserExpr {
+	if _, err := log.NewXMLExpressionParser(expressions); err != nil {
+		panic(logqlmodel.NewParseError(fmt.Sprintf("invalid xml parser: %s", err.Error()), 0, 0))
+	}
+	return &XMLExpressionParserExpr{
+		Expressions: expressions,
+	}
+}
+
+func (x *XMLExpressionParserExpr) Shardable(_ bool) bool { return true }
+
+func (x *XMLExpressionParserExpr) Walk(f WalkFn) { f(x) }
+
+func (x *XMLExpressionParserExpr) Accept(v RootVisitor) { v.VisitXMLExpressionParser(x) }
+
+func (x *XMLExpressionParserExpr) Stage() (log.Stage, error) {
+	return log.NewXMLExpressionParser(x.Expressions)
+}
+
+func (x *XMLExpressionParserExpr) String() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%s %s ", OpPipe, OpParserTypeXML))
+	for i, exp := range x.Expressions {
+		sb.WriteString(exp.Identifier)
+		sb.WriteString("=")
+		sb.WriteString(strconv.Quote(exp.Expression))
+
+		if i+1 != len(x.Expressions) {
+			sb.WriteString(",")
+		}
+	}
+	return sb.String()
+}
+
 func mustNewMatcher(t labels.MatchType, n, v string) *labels.Matcher {
 	m, err := labels.NewMatcher(t, n, v)
 	if err != nil {
@@ -1241,34 +1692,38 @@ func newOffsetExpr(offset time.Duration) *OffsetExpr {
 
 const (
 	// vector ops
-	OpTypeSum      = "sum"
-	OpTypeAvg      = "avg"
-	OpTypeMax      = "max"
-	OpTypeMin      = "min"
-	OpTypeCount    = "count"
-	OpTypeStddev   = "stddev"
-	OpTypeStdvar   = "stdvar"
-	OpTypeBottomK  = "bottomk"
-	OpTypeTopK     = "topk"
-	OpTypeSort     = "sort"
-	OpTypeSortDesc = "sort_desc"
+	OpTypeSum         = "sum"
+	OpTypeAvg         = "avg"
+	OpTypeMax         = "max"
+	OpTypeMin         = "min"
+	OpTypeCount       = "count"
+	OpTypeStddev      = "stddev"
+	OpTypeStdvar      = "stdvar"
+	OpTypeBottomK     = "bottomk"
+	OpTypeTopK        = "topk"
+	OpTypeSort        = "sort"
+	OpTypeSortDesc    = "sort_desc"
+	OpTypeCountValues = "count_values"
 
 	// range vector ops
-	OpRangeTypeCount       = "count_over_time"
-	OpRangeTypeRate        = "rate"
-	OpRangeTypeRateCounter = "rate_counter"
-	OpRangeTypeBytes       = "bytes_over_time"
-	OpRangeTypeBytesRate   = "bytes_rate"
-	OpRangeTypeAvg         = "avg_over_time"
-	OpRangeTypeSum         = "sum_over_time"
-	OpRangeTypeMin         = "min_over_time"
-	OpRangeTypeMax         = "max_over_time"
-	OpRangeTypeStdvar      = "stdvar_over_time"
-	OpRangeTypeStddev      = "stddev_over_time"
-	OpRangeTypeQuantile    = "quantile_over_time"
-	OpRangeTypeFirst       = "first_over_time"
-	OpRangeTypeLast        = "last_over_time"
-	OpRangeTypeAbsent      = "absent_over_time"
+	OpRangeTypeCount         = "count_over_time"
+	OpRangeTypeRate          = "rate"
+	OpRangeTypeRateCounter   = "rate_counter"
+	OpRangeTypeBytes         = "bytes_over_time"
+	OpRangeTypeBytesRate     = "bytes_rate"
+	OpRangeTypeAvg           = "avg_over_time"
+	OpRangeTypeSum           = "sum_over_time"
+	OpRangeTypeMin           = "min_over_time"
+	OpRangeTypeMax           = "max_over_time"
+	OpRangeTypeStdvar        = "stdvar_over_time"
+	OpRangeTypeStddev        = "stddev_over_time"
+	OpRangeTypeQuantile      = "quantile_over_time"
+	OpRangeTypeHistogram     = "histogram_over_time"
+	OpRangeTypeFirst         = "first_over_time"
+	OpRangeTypeLast          = "last_over_time"
+	OpRangeTypeAbsent        = "absent_over_time"
+	OpRangeTypeDeriv         = "deriv"
+	OpRangeTypePredictLinear = "predict_linear"
 
 	// vector
 	OpTypeVector = "vector"
@@ -1300,6 +1755,12 @@ const (
 	OpParserTypeRegexp  = "regexp"
 	OpParserTypeUnpack  = "unpack"
 	OpParserTypePattern = "pattern"
+	OpParserTypeCSV     = "csv"
+	OpParserTypeXML     = "xml"
+
+	// csv parser options
+	OpCSVDelimiter = "delimiter"
+	OpCSVQuote     = "quote"
 
 	OpFmtLine    = "line_format"
 	OpFmtLabel   = "label_format"
@@ -1322,6 +1783,22 @@ const (
 
 	OpLabelReplace = "label_replace"
 
+	// functions
+	OpFuncAbs               = "abs"
+	OpFuncCeil              = "ceil"
+	OpFuncFloor             = "floor"
+	OpFuncRound             = "round"
+	OpFuncLn                = "ln"
+	OpFuncExp               = "exp"
+	OpFuncClampMin          = "clamp_min"
+	OpFuncClampMax          = "clamp_max"
+	OpFuncTime              = "time"
+	OpFuncTimestamp         = "timestamp"
+	OpFuncDayOfWeek         = "day_of_week"
+	OpFuncHour              = "hour"
+	OpFuncAbsent            = "absent"
+	OpFuncHistogramQuantile = "histogram_quantile"
+
 	// function filters
 	OpFilterIP = "ip"
 
@@ -1331,10 +1808,31 @@ const (
 	// keep labels
 	OpKeep = "keep"
 
+	// stats
+	OpStats = "stats"
+
+	// join
+	OpJoin = "join"
+
+	// geoip
+	OpGeoIP = "geoip"
+
+	// sample
+	OpSample = "sample"
+
+	// dedup
+	OpDedup = "dedup"
+
+	// macros
+	OpMacro = "@"
+
 	// parser flags
 	OpStrict    = "--strict"
 	OpKeepEmpty = "--keep-empty"
 
+	// sample flags
+	OpExtrapolate = "--extrapolate"
+
 	// internal expressions not represented in LogQL. These are used to
 	// evaluate expressions differently resulting in intermediate formats
 	// that are not consumable by LogQL clients but are used for sharding.
@@ -1385,7 +1883,7 @@ type RangeAggregationExpr struct {
 func newRangeAggregationExpr(left *LogRangeExpr, operation string, gr *Grouping, stringParams *string) SampleExpr {
 	var params *float64
 	if stringParams != nil {
-		if operation != OpRangeTypeQuantile && operation != OpRangeTypeQuantileSketch {
+		if operation != OpRangeTypeQuantile && operation != OpRangeTypeQuantileSketch && operation != OpRangeTypePredictLinear {
 			return &RangeAggregationExpr{err: logqlmodel.NewParseError(fmt.Sprintf("parameter %s not supported for operation %s", *stringParams, operation), 0, 0)}
 		}
 		var err error
@@ -1396,7 +1894,7 @@ func newRangeAggregationExpr(left *LogRangeExpr, operation string, gr *Grouping,
 		}
 
 	} else {
-		if operation == OpRangeTypeQuantile {
+		if operation == OpRangeTypeQuantile || operation == OpRangeTypePredictLinear {
 			return &RangeAggregationExpr{err: logqlmodel.NewParseError(fmt.Sprintf("parameter required for operation %s", operation), 0, 0)}
 		}
 	}
@@ -1441,7 +1939,8 @@ func (e RangeAggregationExpr) validate() error {
 		switch e.Operation {
 		case OpRangeTypeAvg, OpRangeTypeStddev, OpRangeTypeStdvar, OpRangeTypeQuantile,
 			OpRangeTypeQuantileSketch, OpRangeTypeMax, OpRangeTypeMin, OpRangeTypeFirst,
-			OpRangeTypeLast, OpRangeTypeFirstWithTimestamp, OpRangeTypeLastWithTimestamp:
+			OpRangeTypeLast, OpRangeTypeFirstWithTimestamp, OpRangeTypeLastWithTimestamp,
+			OpRangeTypeDeriv, OpRangeTypePredictLinear, OpRangeTypeHistogram:
 		default:
 			return fmt.Errorf("grouping not allowed for %s aggregation", e.Operation)
 		}
@@ -1451,7 +1950,8 @@ func (e RangeAggregationExpr) validate() error {
 		case OpRangeTypeAvg, OpRangeTypeSum, OpRangeTypeMax, OpRangeTypeMin, OpRangeTypeStddev,
 			OpRangeTypeStdvar, OpRangeTypeQuantile, OpRangeTypeRate, OpRangeTypeRateCounter,
 			OpRangeTypeAbsent, OpRangeTypeFirst, OpRangeTypeLast, OpRangeTypeQuantileSketch,
-			OpRangeTypeFirstWithTimestamp, OpRangeTypeLastWithTimestamp:
+			OpRangeTypeFirstWithTimestamp, OpRangeTypeLastWithTimestamp, OpRangeTypeDeriv,
+			OpRangeTypePredictLinear, OpRangeTypeHistogram:
 			return nil
 		default:
 			return fmt.Errorf("invalid aggregation %s with unwrap", e.Operation)
@@ -1474,11 +1974,16 @@ func (e *RangeAggregationExpr) String() string {
 	var sb strings.Builder
 	sb.WriteString(e.Operation)
 	sb.WriteString("(")
-	if e.Params != nil {
+	if e.Params != nil && e.Operation != OpRangeTypePredictLinear {
 		sb.WriteString(strconv.FormatFloat(*e.Params, 'f', -1, 64))
 		sb.WriteString(",")
 	}
 	sb.WriteString(e.Left.String())
+	// The parameter of predict_linear follows the range, like in PromQL.
+	if e.Params != nil && e.Operation == OpRangeTypePredictLinear {
+		sb.WriteString(",")
+		sb.WriteString(strconv.FormatFloat(*e.Params, 'f', -1, 64))
+	}
 	sb.WriteString(")")
 	if e.Grouping != nil {
 		sb.WriteString(e.Grouping.String())
@@ -1495,6 +2000,13 @@ func (e *RangeAggregationExpr) Shardable(topLevel bool) bool {
 	if e.Operation == OpRangeTypeQuantile && !topLevel {
 		return false
 	}
+	// The linear regressions of deriv and predict_linear need all the samples of
+	// a series, which are only in a single shard if labels aren't reduced.
+	if e.Operation == OpRangeTypeDeriv || e.Operation == OpRangeTypePredictLinear {
+		if ReducesLabels(e) || (e.Grouping != nil && !e.Grouping.Noop()) {
+			return false
+		}
+	}
 	return shardableOps[e.Operation] && e.Left.Shardable(topLevel)
 }
 
@@ -1509,6 +2021,180 @@ func (e *RangeAggregationExpr) Walk(f WalkFn) {
 
 func (e *RangeAggregationExpr) Accept(v RootVisitor) { v.VisitRangeAggregation(e) }
 
+// SubqueryExpr evaluates a metric expression over a range at a fixed resolution, e.g. the
+// `sum(rate({app="foo"}[1m]))[1h:5m]` of `max_over_time(sum(rate({app="foo"}[1m]))[1h:5m])`.
+// A zero step means that the step of the query is used.
+type SubqueryExpr struct {
+	Left     SampleExpr
+	Interval time.Duration
+	Step     time.Duration
+	Offset   time.Duration
+}
+
+// impls Stringer
+func (e SubqueryExpr) String() string {
+	var sb strings.Builder
+	sb.WriteString(e.Left.String())
+	sb.WriteString(e.rangeString())
+	if e.Offset != 0 {
+		offsetExpr := OffsetExpr{Offset: e.Offset}
+		sb.WriteString(offsetExpr.String())
+	}
+	return sb.String()
+}
+
+func (e SubqueryExpr) rangeString() string {
+	if e.Step == 0 {
+		return fmt.Sprintf("[%v:]", model.Duration(e.Interval))
+	}
+	return fmt.Sprintf("[%v:%v]", model.Duration(e.Interval), model.Duration(e.Step))
+}
+
+// Shardable returns false: the inner expression has to be evaluated as a whole at every step of the subquery.
+func (e *SubqueryExpr) Shardable(_ bool) bool { return false }
+
+func (e *SubqueryExpr) Walk(f WalkFn) {
+	if !f(e) {
+		return
+	}
+	if e.Left != nil {
+		e.Left.Walk(f)
+	}
+}
+
+func (e *SubqueryExpr) Accept(v RootVisitor) { v.VisitSubquery(e) }
+
+func newSubqueryExpr(left SampleExpr, r subqueryRange, o *OffsetExpr) *SubqueryExpr {
+	var offset time.Duration
+	if o != nil {
+		offset = o.Offset
+	}
+	return &SubqueryExpr{
+		Left:     left,
+		Interval: r.interval,
+		Step:     r.step,
+		Offset:   offset,
+	}
+}
+
+// SubqueryAggregationExpr applies a range vector aggregation to the result of a subquery,
+// e.g. `max_over_time(sum(rate({app="foo"}[1m]))[1h:5m])`.
+type SubqueryAggregationExpr struct {
+	Left      *SubqueryExpr
+	Operation string
+
+	Params *float64
+	err    error
+}
+
+func newSubqueryAggregationExpr(left *SubqueryExpr, operation string, stringParams *string) SampleExpr {
+	var params *float64
+	if stringParams != nil {
+		if operation != OpRangeTypeQuantile && operation != OpRangeTypePredictLinear {
+			return &SubqueryAggregationExpr{err: logqlmodel.NewParseError(fmt.Sprintf("parameter %s not supported for operation %s", *stringParams, operation), 0, 0)}
+		}
+		var err error
+		params = new(float64)
+		*params, err = strconv.ParseFloat(*stringParams, 64)
+		if err != nil {
+			return &SubqueryAggregationExpr{err: logqlmodel.NewParseError(fmt.Sprintf("invalid parameter for operation %s: %s", operation, err), 0, 0)}
+		}
+	} else if operation == OpRangeTypeQuantile || operation == OpRangeTypePredictLinear {
+		return &SubqueryAggregationExpr{err: logqlmodel.NewParseError(fmt.Sprintf("parameter required for operation %s", operation), 0, 0)}
+	}
+	e := &SubqueryAggregationExpr{
+		Left:      left,
+		Operation: operation,
+		Params:    params,
+	}
+	if err := e.validate(); err != nil {
+		return &SubqueryAggregationExpr{err: logqlmodel.NewParseError(err.Error(), 0, 0)}
+	}
+	return e
+}
+
+func (e SubqueryAggregationExpr) validate() error {
+	if e.Left.Interval <= 0 {
+		return fmt.Errorf("subquery range must be positive")
+	}
+	if e.Left.Step < 0 {
+		return fmt.Errorf("subquery step must not be negative")
+	}
+	switch e.Operation {
+	case OpRangeTypeAvg, OpRangeTypeSum, OpRangeTypeMax, OpRangeTypeMin, OpRangeTypeStddev,
+		OpRangeTypeStdvar, OpRangeTypeQuantile, OpRangeTypeRate, OpRangeTypeRateCounter,
+		OpRangeTypeAbsent, OpRangeTypeFirst, OpRangeTypeLast, OpRangeTypeCount, OpRangeTypeDeriv,
+		OpRangeTypePredictLinear:
+		return nil
+	default:
+		return fmt.Errorf("invalid aggregation %s of a subquery", e.Operation)
+	}
+}
+
+func (e *SubqueryAggregationExpr) Selector() (LogSelectorExpr, error) {
+	if e.err != nil {
+		return nil, e.err
+	}
+	return e.Left.Left.Selector()
+}
+
+func (e *SubqueryAggregationExpr) Extractors() ([]SampleExtractor, error) {
+	if e.err != nil {
+		return []SampleExtractor{}, e.err
+	}
+	return e.Left.Left.Extractors()
+}
+
+// MatcherGroups returns the matcher groups of the inner expression, with their ranges
+// extended by the range and offset of the subquery.
+func (e *SubqueryAggregationExpr) MatcherGroups() ([]MatcherRange, error) {
+	if e.err != nil {
+		return nil, e.err
+	}
+	groups, err := e.Left.Left.MatcherGroups()
+	if err != nil {
+		return nil, err
+	}
+	for i := range groups {
+		groups[i].Interval += e.Left.Interval
+		groups[i].Offset += e.Left.Offset
+	}
+	return groups, nil
+}
+
+// impls Stringer
+func (e *SubqueryAggregationExpr) String() string {
+	var sb strings.Builder
+	sb.WriteString(e.Operation)
+	sb.WriteString("(")
+	if e.Params != nil && e.Operation != OpRangeTypePredictLinear {
+		sb.WriteString(strconv.FormatFloat(*e.Params, 'f', -1, 64))
+		sb.WriteString(",")
+	}
+	sb.WriteString(e.Left.String())
+	if e.Params != nil && e.Operation == OpRangeTypePredictLinear {
+		sb.WriteString(",")
+		sb.WriteString(strconv.FormatFloat(*e.Params, 'f', -1, 64))
+	}
+	sb.WriteString(")")
+	return sb.String()
+}
+
+// Shardable returns false: the aggregation needs the complete result of the subquery
+// at every step, only the inner expression may be sharded.
+func (e *SubqueryAggregationExpr) Shardable(_ bool) bool { return false }
+
+func (e *SubqueryAggregationExpr) Walk(f WalkFn) {
+	if !f(e) {
+		return
+	}
+	if e.Left != nil {
+		e.Left.Walk(f)
+	}
+}
+
+func (e *SubqueryAggregationExpr) Accept(v RootVisitor) { v.VisitSubqueryAggregation(e) }
+
 // Grouping struct represents the grouping by/without label(s) for vector aggregators and range vector aggregators.
 // The representation is as follows:
 //   - No Grouping (labels dismissed): <operation> (<expr>) => Grouping{Without: false, Groups: nil}
@@ -1557,7 +2243,9 @@ type VectorAggregationExpr struct {
 	Grouping  *Grouping `json:"grouping,omitempty"`
 	Params    int       `json:"params"`
 	Operation string    `json:"operation"`
-	err       error
+	// Label is the name of the label holding the counted values of count_values.
+	Label string `json:"label,omitempty"`
+	err   error
 }
 
 func mustNewVectorAggregationExpr(left SampleExpr, operation string, gr *Grouping, params *string) SampleExpr {
@@ -1595,6 +2283,21 @@ func mustNewVectorAggregationExpr(left SampleExpr, operation string, gr *Groupin
 	}
 }
 
+func mustNewCountValuesExpr(left SampleExpr, label string, gr *Grouping) SampleExpr {
+	if !model.LabelName(label).IsValid() {
+		return &VectorAggregationExpr{err: logqlmodel.NewParseError(fmt.Sprintf("invalid label name %q for operation %s", label, OpTypeCountValues), 0, 0)}
+	}
+	if gr == nil {
+		gr = &Grouping{}
+	}
+	return &VectorAggregationExpr{
+		Left:      left,
+		Operation: OpTypeCountValues,
+		Grouping:  gr,
+		Label:     label,
+	}
+}
+
 func (e *VectorAggregationExpr) MatcherGroups() ([]MatcherRange, error) {
 	if e.err != nil {
 		return nil, e.err
@@ -1647,6 +2350,8 @@ func (e *VectorAggregationExpr) String() string {
 	// bottomK and topk can have first parameter as 0
 	case OpTypeBottomK, OpTypeTopK, OpTypeApproxTopK:
 		params = []string{fmt.Sprintf("%d", e.Params), e.Left.String()}
+	case OpTypeCountValues:
+		params = []string{strconv.Quote(e.Label), e.Left.String()}
 	default:
 		if e.Params != 0 {
 			params = []string{fmt.Sprintf("%d", e.Params), e.Left.String()}
@@ -2292,6 +2997,133 @@ func (e *LabelReplaceExpr) String() string {
 	return sb.String()
 }
 
+// FunctionExpr is a PromQL-like function applied to the samples of a metric
+// expression, like abs(), clamp_min() or histogram_quantile().
+type FunctionExpr struct {
+	// Left is nil for the functions called without argument, like time().
+	Left     SampleExpr
+	Function string
+	Params   *float64
+	err      error
+}
+
+func newFunctionExpr(function string, left SampleExpr, param *LiteralExpr) *FunctionExpr {
+	var params *float64
+	if param != nil {
+		v, err := param.Value()
+		if err != nil {
+			return &FunctionExpr{err: err}
+		}
+		params = &v
+	}
+	e := &FunctionExpr{
+		Left:     left,
+		Function: function,
+		Params:   params,
+	}
+	if err := e.validate(); err != nil {
+		return &FunctionExpr{err: logqlmodel.NewParseError(err.Error(), 0, 0)}
+	}
+	return e
+}
+
+func (e FunctionExpr) validate() error {
+	switch e.Function {
+	case OpFuncTime:
+		if e.Left != nil {
+			return fmt.Errorf("function %s doesn't take any argument", e.Function)
+		}
+	case OpFuncDayOfWeek, OpFuncHour:
+		// The argument is optional, the time of the evaluation is used without it.
+	default:
+		if e.Left == nil {
+			return fmt.Errorf("argument required for function %s", e.Function)
+		}
+	}
+
+	switch e.Function {
+	case OpFuncClampMin, OpFuncClampMax, OpFuncHistogramQuantile:
+		if e.Params == nil {
+			return fmt.Errorf("parameter required for function %s", e.Function)
+		}
+	case OpFuncRound:
+		// The parameter is optional and defaults to 1.
+	default:
+		if e.Params != nil {
+			return fmt.Errorf("parameter %s not supported for function %s", strconv.FormatFloat(*e.Params, 'f', -1, 64), e.Function)
+		}
+	}
+	return nil
+}
+
+func (e *FunctionExpr) Selector() (LogSelectorExpr, error) {
+	if e.err != nil {
+		return nil, e.err
+	}
+	if e.Left == nil {
+		// Like vector(), functions without argument don't select any log.
+		return (&VectorExpr{}).Selector()
+	}
+	return e.Left.Selector()
+}
+
+func (e *FunctionExpr) MatcherGroups() ([]MatcherRange, error) {
+	if e.err != nil {
+		return nil, e.err
+	}
+	if e.Left == nil {
+		return nil, nil
+	}
+	return e.Left.MatcherGroups()
+}
+
+func (e *FunctionExpr) Extractors() ([]SampleExtractor, error) {
+	if e.err != nil {
+		return []SampleExtractor{}, e.err
+	}
+	if e.Left == nil {
+		return []SampleExtractor{}, nil
+	}
+	return e.Left.Extractors()
+}
+
+func (e *FunctionExpr) Shardable(_ bool) bool {
+	return false
+}
+
+func (e *FunctionExpr) Walk(f WalkFn) {
+	if !f(e) {
+		return
+	}
+	if e.Left != nil {
+		e.Left.Walk(f)
+	}
+}
+
+func (e *FunctionExpr) Accept(v RootVisitor) { v.VisitFunction(e) }
+
+func (e *FunctionExpr) String() string {
+	var params []string
+	if e.Left != nil {
+		params = append(params, e.Left.String())
+	}
+	if e.Params != nil {
+		p := strconv.FormatFloat(*e.Params, 'f', -1, 64)
+		// The quantile of histogram_quantile is its first argument, like in PromQL.
+		if e.Function == OpFuncHistogramQuantile {
+			params = append([]string{p}, params...)
+		} else {
+			params = append(params, p)
+		}
+	}
+	var sb strings.Builder
+	sb.WriteString(e.Function)
+	sb.WriteString("(")
+	sb.WriteString(strings.Join(params, ","))
+	sb.WriteString(")")
+	return sb.String()
+}
+
 // shardableOps lists the operations which may be sharded, but are not
 // guaranteed to be. See the `Shardable()` implementations
 // on the respective expr types for more details.
@@ -2323,17 +3155,20 @@ var shardableOps = map[string]bool{
 	OpTypeApproxTopK: true,
 
 	// range vector ops
-	OpRangeTypeAvg:       true,
-	OpRangeTypeCount:     true,
-	OpRangeTypeFirst:     true,
-	OpRangeTypeLast:      true,
-	OpRangeTypeRate:      true,
-	OpRangeTypeBytes:     true,
-	OpRangeTypeBytesRate: true,
-	OpRangeTypeSum:       true,
-	OpRangeTypeMax:       true,
-	OpRangeTypeMin:       true,
-	OpRangeTypeQuantile:  true,
+	OpRangeTypeAvg:           true,
+	OpRangeTypeCount:         true,
+	OpRangeTypeFirst:         true,
+	OpRangeTypeLast:          true,
+	OpRangeTypeRate:          true,
+	OpRangeTypeBytes:         true,
+	OpRangeTypeBytesRate:     true,
+	OpRangeTypeSum:           true,
+	OpRangeTypeMax:           true,
+	OpRangeTypeMin:           true,
+	OpRangeTypeQuantile:      true,
+	OpRangeTypeHistogram:     true,
+	OpRangeTypeDeriv:         true,
+	OpRangeTypePredictLinear: true,
 
 	// binops - arith
 	OpTypeAdd: true,
@@ -55,6 +55,7 @@ func (v *cloneVisitor) VisitVectorAggregation(e *VectorAggregationExpr) {
 		Left:      MustClone[SampleExpr](e.Left),
 		Params:    e.Params,
 		Operation: e.Operation,
+		Label:     e.Label,
 	}
 
 	if e.Grouping != nil {
@@ -82,11 +83,49 @@ func (v *cloneVisitor) VisitRangeAggregation(e *RangeAggregationExpr) {
 	v.cloned = copied
 }
 
+func (v *cloneVisitor) VisitSubqueryAggregation(e *SubqueryAggregationExpr) {
+	copied := &SubqueryAggregationExpr{
+		Left:      MustClone[*SubqueryExpr](e.Left),
+		Operation: e.Operation,
+	}
+
+	if e.Params != nil {
+		tmp := *e.Params
+		copied.Params = &tmp
+	}
+
+	v.cloned = copied
+}
+
+func (v *cloneVisitor) VisitSubquery(e *SubqueryExpr) {
+	v.cloned = &SubqueryExpr{
+		Left:     MustClone[SampleExpr](e.Left),
+		Interval: e.Interval,
+		Step:     e.Step,
+		Offset:   e.Offset,
+	}
+}
+
 func (v *cloneVisitor) VisitLabelReplace(e *LabelReplaceExpr) {
 	left := MustClone[SampleExpr](e.Left)
 	v.cloned = mustNewLabelReplaceExpr(left, e.Dst, e.Replacement, e.Src, e.Regex)
 }
 
+func (v *cloneVisitor) VisitFunction(e *FunctionExpr) {
+	copied := &FunctionExpr{
+		Function: e.Function,
+	}
+	if e.Left != nil {
+		copied.Left = MustClone[SampleExpr](e.Left)
+	}
+	if e.Params != nil {
+		tmp := *e.Params
+		copied.Params = &tmp
+	}
+
+	v.cloned = copied
+}
+
 func (v *cloneVisitor) VisitLiteral(e *LiteralExpr) {
 	v.cloned = &LiteralExpr{Val: e.Val}
 }
@@ -144,6 +183,32 @@ func (v *cloneVisitor) VisitDecolorize(*DecolorizeExpr) {
 	v.cloned = &DecolorizeExpr{}
 }
 
+func (v *cloneVisitor) VisitGeoIP(e *GeoIPExpr) {
+	v.cloned = &GeoIPExpr{Source: e.Source}
+}
+
+func (v *cloneVisitor) VisitSampling(e *SamplingExpr) {
+	copied := &SamplingExpr{Ratio: e.Ratio, Extrapolate: e.Extrapolate}
+	if e.By != nil {
+		copied.By = make([]string, len(e.By))
+		copy(copied.By, e.By)
+	}
+	v.cloned = copied
+}
+
+func (v *cloneVisitor) VisitDedup(e *DedupExpr) {
+	copied := &DedupExpr{Window: e.Window}
+	if e.By != nil {
+		copied.By = make([]string, len(e.By))
+		copy(copied.By, e.By)
+	}
+	v.cloned = copied
+}
+
+func (v *cloneVisitor) VisitMacro(e *MacroExpr) {
+	v.cloned = &MacroExpr{Name: e.Name}
+}
+
 func (v *cloneVisitor) VisitDropLabels(e *DropLabelsExpr) {
 	copied := &DropLabelsExpr{
 		dropLabels: make([]log.NamedLabelMatcher, len(e.dropLabels)),
@@ -301,6 +366,46 @@ func (v *cloneVisitor) VisitLogfmtParser(e *LogfmtParserExpr) {
 	}
 }
 
+func (v *cloneVisitor) VisitCSVParser(e *CSVParserExpr) {
+	v.cloned = &CSVParserExpr{
+		Columns:   e.Columns,
+		Delimiter: e.Delimiter,
+		Quote:     e.Quote,
+	}
+}
+
+func (v *cloneVisitor) VisitXMLExpressionParser(e *XMLExpressionParserExpr) {
+	copied := &XMLExpressionParserExpr{
+		Expressions: make([]log.LabelExtractionExpr, len(e.Expressions)),
+	}
+	copy(copied.Expressions, e.Expressions)
+
+	v.cloned = copied
+}
+
+func (v *cloneVisitor) VisitStats(e *StatsExpr) {
+	copied := &StatsExpr{
+		Aggregations: make([]StatsAggregation, len(e.Aggregations)),
+	}
+	copy(copied.Aggregations, e.Aggregations)
+	if e.Grouping != nil {
+		copied.Grouping = cloneGrouping(e.Grouping)
+	}
+
+	v.cloned = copied
+}
+
+func (v *cloneVisitor) VisitJoin(e *JoinExpr) {
+	copied := &JoinExpr{
+		On:     make([]string, len(e.On)),
+		Window: e.Window,
+		Right:  MustClone[LogSelectorExpr](e.Right),
+	}
+	copy(copied.On, e.On)
+
+	v.cloned = copied
+}
+
 func (v *cloneVisitor) VisitVariants(e *MultiVariantExpr) {
 	copied := &MultiVariantExpr{
 		logRange: MustClone[*LogRangeExpr](e.logRange),
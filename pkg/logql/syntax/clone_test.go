@@ -38,6 +38,18 @@ func TestClone(t *testing.T) {
 		"keep label": {
 			query: `{app="foo"} |= "bar" | json | keep latency, status_code="200"`,
 		},
+		"geoip": {
+			query: `{app="foo"} | json | geoip src_ip`,
+		},
+		"sample": {
+			query: `{app="foo"} | json | sample 0.1 by (trace_id) --extrapolate`,
+		},
+		"dedup": {
+			query: `{app="foo"} | json | dedup 5s by (request_id)`,
+		},
+		"join": {
+			query: `{app="foo"} | json | join on (request_id) [30s] ({app="bar"} | logfmt)`,
+		},
 		"regexp": {
 			query: `{env="prod", app=~"loki.*"} |~ ".*foo.*"`,
 		},
@@ -49,7 +49,10 @@ func (r RangeAggregationExpr) extractor(override *Grouping) (log.SampleExtractor
 
 	sort.Strings(groups)
 
-	var stages []log.Stage
+	var (
+		stages        []log.Stage
+		extrapolation = 1.0
+	)
 	if p, ok := r.Left.Left.(*PipelineExpr); ok {
 		// if the expression is a pipeline then take all stages into account first.
 		st, err := p.MultiStages.stages()
@@ -57,6 +60,7 @@ func (r RangeAggregationExpr) extractor(override *Grouping) (log.SampleExtractor
 			return nil, err
 		}
 		stages = st
+		extrapolation = sampleExtrapolation(p.MultiStages)
 	}
 	// unwrap...means we want to extract metrics from labels.
 	if r.Left.Unwrap != nil {
@@ -78,15 +82,26 @@ func (r RangeAggregationExpr) extractor(override *Grouping) (log.SampleExtractor
 	}
 	// otherwise we extract metrics from the log line.
 	switch r.Operation {
-	case OpRangeTypeRate, OpRangeTypeCount, OpRangeTypeAbsent:
+	case OpRangeTypeAbsent:
 		return log.NewLineSampleExtractor(log.CountExtractor, stages, groups, without, noLabels)
+	case OpRangeTypeRate, OpRangeTypeCount:
+		return log.NewLineSampleExtractor(extrapolate(log.CountExtractor, extrapolation), stages, groups, without, noLabels)
 	case OpRangeTypeBytes, OpRangeTypeBytesRate:
-		return log.NewLineSampleExtractor(log.BytesExtractor, stages, groups, without, noLabels)
+		return log.NewLineSampleExtractor(extrapolate(log.BytesExtractor, extrapolation), stages, groups, without, noLabels)
 	default:
 		return nil, fmt.Errorf(UnsupportedErr, r.Operation)
 	}
 }
 
+// extrapolate scales the values of a line extractor by the given factor, so
+// metric queries over sampled log lines estimate the values of all log lines.
+func extrapolate(extractor log.LineExtractor, factor float64) log.LineExtractor {
+	if factor == 1 {
+		return extractor
+	}
+	return func(line []byte) float64 { return extractor(line) * factor }
+}
+
 func (m *MultiVariantExpr) Extractors() ([]log.SampleExtractor, error) {
 	if m.err != nil {
 		return nil, m.err
@@ -110,6 +110,45 @@ func Test_Extractor(t *testing.T) {
 	}
 }
 
+func Test_Extractor_SampleExtrapolation(t *testing.T) {
+	t.Parallel()
+	lbs := labels.FromStrings("app", "foo")
+	line := []byte("line")
+
+	for _, tc := range []struct {
+		query string
+		value float64
+	}{
+		{`count_over_time({app="foo"} | sample 1 [5m])`, 1},
+		{`count_over_time({app="foo"} | sample 1 --extrapolate [5m])`, 1},
+		{`count_over_time({app="foo"} | sample 0.5 --extrapolate [5m])`, 2},
+		{`rate({app="foo"} | sample 0.5 --extrapolate | sample 0.25 --extrapolate [5m])`, 8},
+		{`bytes_over_time({app="foo"} | sample 0.5 --extrapolate [5m])`, 8},
+		// Only log lines and bytes are extrapolated.
+		{`count_over_time({app="foo"} | sample 0.5 [5m])`, 1},
+		{`sum_over_time({app="foo"} | sample 0.5 --extrapolate | label_format v="3" | unwrap v [5m])`, 3},
+	} {
+		t.Run(tc.query, func(t *testing.T) {
+			expr, err := ParseSampleExpr(tc.query)
+			require.NoError(t, err)
+			extractors, err := expr.Extractors()
+			require.NoError(t, err)
+			require.Len(t, extractors, 1)
+
+			// Find a timestamp for which the log line is kept by all stages.
+			for ts := int64(0); ; ts++ {
+				samples, ok := extractors[0].ForStream(lbs).Process(ts, line, labels.EmptyLabels())
+				if !ok {
+					continue
+				}
+				require.Len(t, samples, 1)
+				require.Equal(t, tc.value, samples[0].Value)
+				return
+			}
+		})
+	}
+}
+
 func Test_MultiVariantExpr_Extractors(t *testing.T) {
 	t.Parallel()
 
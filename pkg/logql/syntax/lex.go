@@ -86,44 +86,65 @@ var tokens = map[string]int{
 	VariantsOf: OF,
 }
 
+// pipelineTokens are tokens that are only keywords when they start a pipeline
+// stage, so they can still be used as label names.
+var pipelineTokens = map[string]int{
+	// parsers
+	OpParserTypeCSV: CSV,
+	OpParserTypeXML: XML,
+
+	OpStats:  STATS,
+	OpJoin:   JOIN,
+	OpGeoIP:  GEOIP,
+	OpSample: SAMPLE,
+	OpDedup:  DEDUP,
+}
+
 var parserFlags = map[string]struct{}{
 	OpStrict:    {},
 	OpKeepEmpty: {},
+
+	// sample flags
+	OpExtrapolate: {},
 }
 
 // functionTokens are tokens that needs to be suffixes with parenthesis
 var functionTokens = map[string]int{
 	// range vec ops
-	OpRangeTypeRate:        RATE,
-	OpRangeTypeRateCounter: RATE_COUNTER,
-	OpRangeTypeCount:       COUNT_OVER_TIME,
-	OpRangeTypeBytesRate:   BYTES_RATE,
-	OpRangeTypeBytes:       BYTES_OVER_TIME,
-	OpRangeTypeAvg:         AVG_OVER_TIME,
-	OpRangeTypeSum:         SUM_OVER_TIME,
-	OpRangeTypeMin:         MIN_OVER_TIME,
-	OpRangeTypeMax:         MAX_OVER_TIME,
-	OpRangeTypeStdvar:      STDVAR_OVER_TIME,
-	OpRangeTypeStddev:      STDDEV_OVER_TIME,
-	OpRangeTypeQuantile:    QUANTILE_OVER_TIME,
-	OpRangeTypeFirst:       FIRST_OVER_TIME,
-	OpRangeTypeLast:        LAST_OVER_TIME,
-	OpRangeTypeAbsent:      ABSENT_OVER_TIME,
-	OpTypeVector:           VECTOR,
+	OpRangeTypeRate:          RATE,
+	OpRangeTypeRateCounter:   RATE_COUNTER,
+	OpRangeTypeCount:         COUNT_OVER_TIME,
+	OpRangeTypeBytesRate:     BYTES_RATE,
+	OpRangeTypeBytes:         BYTES_OVER_TIME,
+	OpRangeTypeAvg:           AVG_OVER_TIME,
+	OpRangeTypeSum:           SUM_OVER_TIME,
+	OpRangeTypeMin:           MIN_OVER_TIME,
+	OpRangeTypeMax:           MAX_OVER_TIME,
+	OpRangeTypeStdvar:        STDVAR_OVER_TIME,
+	OpRangeTypeStddev:        STDDEV_OVER_TIME,
+	OpRangeTypeQuantile:      QUANTILE_OVER_TIME,
+	OpRangeTypeHistogram:     HISTOGRAM_OVER_TIME,
+	OpRangeTypeFirst:         FIRST_OVER_TIME,
+	OpRangeTypeLast:          LAST_OVER_TIME,
+	OpRangeTypeAbsent:        ABSENT_OVER_TIME,
+	OpRangeTypeDeriv:         DERIV,
+	OpRangeTypePredictLinear: PREDICT_LINEAR,
+	OpTypeVector:             VECTOR,
 
 	// vec ops
-	OpTypeSum:      SUM,
-	OpTypeAvg:      AVG,
-	OpTypeMax:      MAX,
-	OpTypeMin:      MIN,
-	OpTypeCount:    COUNT,
-	OpTypeStddev:   STDDEV,
-	OpTypeStdvar:   STDVAR,
-	OpTypeBottomK:  BOTTOMK,
-	OpTypeTopK:     TOPK,
-	OpTypeSort:     SORT,
-	OpTypeSortDesc: SORT_DESC,
-	OpLabelReplace: LABEL_REPLACE,
+	OpTypeSum:         SUM,
+	OpTypeAvg:         AVG,
+	OpTypeMax:         MAX,
+	OpTypeMin:         MIN,
+	OpTypeCount:       COUNT,
+	OpTypeStddev:      STDDEV,
+	OpTypeStdvar:      STDVAR,
+	OpTypeBottomK:     BOTTOMK,
+	OpTypeTopK:        TOPK,
+	OpTypeSort:        SORT,
+	OpTypeSortDesc:    SORT_DESC,
+	OpTypeCountValues: COUNT_VALUES,
+	OpLabelReplace:    LABEL_REPLACE,
 
 	OpTypeApproxTopK: APPROX_TOPK,
 
@@ -134,15 +155,39 @@ var functionTokens = map[string]int{
 
 	// filterOp
 	OpFilterIP: IP,
+
+	// functions
+	OpFuncAbs:               ABS,
+	OpFuncCeil:              CEIL,
+	OpFuncFloor:             FLOOR,
+	OpFuncRound:             ROUND,
+	OpFuncLn:                LN,
+	OpFuncExp:               EXP,
+	OpFuncClampMin:          CLAMP_MIN,
+	OpFuncClampMax:          CLAMP_MAX,
+	OpFuncTime:              TIME,
+	OpFuncTimestamp:         TIMESTAMP,
+	OpFuncDayOfWeek:         DAY_OF_WEEK,
+	OpFuncHour:              HOUR,
+	OpFuncAbsent:            ABSENT,
+	OpFuncHistogramQuantile: HISTOGRAM_QUANTILE,
 }
 
 type lexer struct {
 	Scanner
 	errs    []logqlmodel.ParseError
 	builder strings.Builder
+	// last is the last token returned.
+	last int
 }
 
 func (l *lexer) Lex(lval *syntaxSymType) int {
+	tok := l.lex(lval)
+	l.last = tok
+	return tok
+}
+
+func (l *lexer) lex(lval *syntaxSymType) int {
 	r := l.Scan()
 
 	switch r {
@@ -152,7 +197,7 @@ func (l *lexer) Lex(lval *syntaxSymType) int {
 		for next := l.Peek(); next != '\n' && next != scanner.EOF; next = l.Next() {
 		}
 
-		return l.Lex(lval)
+		return l.lex(lval)
 
 	case scanner.EOF:
 		return 0
@@ -188,6 +233,15 @@ func (l *lexer) Lex(lval *syntaxSymType) int {
 			return DURATION
 		}
 
+	case '@': // macros are referenced by their name, e.g. `| @access_log`
+		if next := l.Peek(); next != '_' && !unicode.IsLetter(next) {
+			l.Error("expected the name of a macro after @")
+			return 0
+		}
+		l.Scan()
+		lval.str = l.TokenText()
+		return MACRO
+
 	case scanner.String, scanner.RawString:
 		var err error
 		tokenText := l.TokenText()
@@ -208,6 +262,15 @@ func (l *lexer) Lex(lval *syntaxSymType) int {
 		l.builder.Reset()
 		for r := l.Next(); r != scanner.EOF; r = l.Next() {
 			if r == ']' {
+				if rng, step, ok := strings.Cut(l.builder.String(), ":"); ok {
+					sr, err := parseSubqueryRange(rng, step)
+					if err != nil {
+						l.Error(err.Error())
+						return 0
+					}
+					lval.subqueryRange = sr
+					return SUBQUERY_RANGE
+				}
 				i, err := model.ParseDuration(l.builder.String())
 				if err != nil {
 					l.Error(err.Error())
@@ -244,6 +307,10 @@ func (l *lexer) Lex(lval *syntaxSymType) int {
 		return tok
 	}
 
+	if tok, ok := pipelineTokens[tokenTextLower]; ok && l.last == PIPE && !isLabelFilter(l.Scanner) {
+		return tok
+	}
+
 	if tok, ok := tokens[tokenNext]; ok {
 		l.Next()
 		return tok
@@ -290,6 +357,28 @@ func tryScanFlag(l *Scanner) (string, bool) {
 	return flag, true
 }
 
+// subqueryRange is the `[range:step]` of a subquery. The step is optional.
+type subqueryRange struct {
+	interval, step time.Duration
+}
+
+func parseSubqueryRange(rng, step string) (subqueryRange, error) {
+	var sr subqueryRange
+	i, err := model.ParseDuration(strings.TrimSpace(rng))
+	if err != nil {
+		return sr, err
+	}
+	sr.interval = time.Duration(i)
+	if step = strings.TrimSpace(step); step != "" {
+		s, err := model.ParseDuration(step)
+		if err != nil {
+			return sr, err
+		}
+		sr.step = time.Duration(s)
+	}
+	return sr, nil
+}
+
 func tryScanDuration(number string, l *Scanner) (time.Duration, bool) {
 	var sb strings.Builder
 	sb.WriteString(number)
@@ -411,6 +500,17 @@ func isFunction(sc Scanner) bool {
 	return false
 }
 
+// isLabelFilter returns true if the scanned identifier is followed by a
+// comparison operator, e.g. `| csv="1"`.
+func isLabelFilter(sc Scanner) bool {
+	sc = trimSpace(sc)
+	switch sc.Peek() {
+	case '=', '!', '<', '>':
+		return true
+	}
+	return false
+}
+
 func trimSpace(l Scanner) Scanner {
 	for n := l.Peek(); n != scanner.EOF; n = l.Peek() {
 		if unicode.IsSpace(n) {
@@ -0,0 +1,103 @@
+package syntax
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/grafana/loki/v3/pkg/logqlmodel"
+)
+
+// macroSelector is the stream selector used to parse the pipeline of a macro,
+// since the grammar only accepts pipelines after a stream selector.
+const macroSelector = `{__macro__="_"}`
+
+// Macros maps the names of macros to the pipelines they expand to, e.g.
+// `access_log` to `| json | line_format "{{.method}} {{.path}}"`.
+type Macros map[string]string
+
+// ParseMacro parses the pipeline of a macro. The pipeline consists of line
+// filters and pipeline stages, and can't reference other macros.
+func ParseMacro(pipeline string) (MultiStageExpr, error) {
+	if strings.TrimSpace(pipeline) == "" {
+		return nil, logqlmodel.NewParseError("the pipeline of a macro can't be empty", 0, 0)
+	}
+
+	expr, err := ParseExprWithoutValidation(macroSelector + " " + pipeline)
+	if err != nil {
+		return nil, fmt.Errorf("invalid macro pipeline: %w", err)
+	}
+	p, ok := expr.(*PipelineExpr)
+	if !ok {
+		return nil, logqlmodel.NewParseError("the pipeline of a macro must only contain line filters and pipeline stages", 0, 0)
+	}
+	if macro := ExtractMacro(p); macro != nil {
+		return nil, logqlmodel.NewParseError(fmt.Sprintf("macros can't reference other macros, found %s%s", OpMacro, macro.Name), 0, 0)
+	}
+	return p.MultiStages, nil
+}
+
+// ParseExprWithMacros parses a string and expands the macros it references
+// with the given pipelines before validating the expression.
+func ParseExprWithMacros(input string, macros Macros) (Expr, error) {
+	expr, err := ParseExprWithoutValidation(input)
+	if err != nil {
+		return nil, err
+	}
+	if err := ExpandMacros(expr, macros); err != nil {
+		return nil, err
+	}
+	if err := validateExpr(expr); err != nil {
+		return nil, err
+	}
+	return expr, nil
+}
+
+// ExpandMacros replaces the macro stages of the pipelines of the expression
+// in place with the stages of the macros. Macros that are not defined are
+// left untouched, so the validation of the expression reports them.
+func ExpandMacros(expr Expr, macros Macros) error {
+	if len(macros) == 0 {
+		return nil
+	}
+
+	var (
+		err    error
+		parsed = make(map[string]MultiStageExpr, len(macros))
+	)
+	visitor := &DepthFirstTraversal{}
+	visitor.VisitPipelineFn = func(_ RootVisitor, e *PipelineExpr) {
+		stages := make(MultiStageExpr, 0, len(e.MultiStages))
+		for _, stage := range e.MultiStages {
+			macro, ok := stage.(*MacroExpr)
+			if !ok {
+				// The query of a join is not traversed otherwise.
+				if join, ok := stage.(*JoinExpr); ok {
+					join.Right.Accept(visitor)
+				}
+				stages = append(stages, stage)
+				continue
+			}
+
+			pipeline, ok := macros[macro.Name]
+			if !ok {
+				stages = append(stages, stage)
+				continue
+			}
+			if _, ok := parsed[macro.Name]; !ok {
+				macroStages, macroErr := ParseMacro(pipeline)
+				if macroErr != nil && err == nil {
+					err = fmt.Errorf("macro %s%s: %w", OpMacro, macro.Name, macroErr)
+				}
+				parsed[macro.Name] = macroStages
+			}
+			// Each reference gets its own copy, since stages are not safe to
+			// share between pipelines.
+			for _, s := range parsed[macro.Name] {
+				stages = append(stages, MustClone[StageExpr](s))
+			}
+		}
+		e.MultiStages = stages
+	}
+	expr.Accept(visitor)
+	return err
+}
@@ -0,0 +1,80 @@
+package syntax
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseExprWithMacros(t *testing.T) {
+	macros := Macros{
+		"access_log": `| json | line_format "{{.method}} {{.path}}"`,
+		"errors":     `|= "error" | logfmt | level="error"`,
+		"invalid":    `| json |`,
+		"nested":     `| @access_log`,
+	}
+
+	for _, tc := range []struct {
+		in  string
+		exp string
+		err string
+	}{
+		{
+			in:  `{app="api"} | @access_log`,
+			exp: `{app="api"} | json | line_format "{{.method}} {{.path}}"`,
+		},
+		{
+			in:  `{app="api"} |= "GET" | @access_log | @errors | drop level`,
+			exp: `{app="api"} |= "GET" | json | line_format "{{.method}} {{.path}}" |= "error" | logfmt | level="error" | drop level`,
+		},
+		{
+			in:  `sum by (path) (count_over_time({app="api"} | @access_log [5m]))`,
+			exp: `sum by (path)(count_over_time({app="api"} | json | line_format "{{.method}} {{.path}}"[5m]))`,
+		},
+		{
+			in:  `{app="api"} | json | join on (request_id) [30s] ({app="db"} | @errors)`,
+			exp: `{app="api"} | json | join on (request_id) [30s] ({app="db"} |= "error" | logfmt | level="error")`,
+		},
+		{
+			in:  `{app="api"} | json`,
+			exp: `{app="api"} | json`,
+		},
+		{
+			in:  `{app="api"} | @unknown`,
+			err: "parse error : macro @unknown is not defined",
+		},
+		{
+			in:  `{app="api"} | @invalid`,
+			err: "macro @invalid: invalid macro pipeline: parse error",
+		},
+		{
+			in:  `{app="api"} | @nested`,
+			err: "macro @nested: parse error : macros can't reference other macros, found @access_log",
+		},
+	} {
+		t.Run(tc.in, func(t *testing.T) {
+			expr, err := ParseExprWithMacros(tc.in, macros)
+			if tc.err != "" {
+				require.ErrorContains(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.exp, expr.String())
+		})
+	}
+}
+
+func TestParseMacro(t *testing.T) {
+	stages, err := ParseMacro(`| json | line_format "{{.msg}}"`)
+	require.NoError(t, err)
+	require.Equal(t, MultiStageExpr{
+		newLabelParserExpr(OpParserTypeJSON, ""),
+		newLineFmtExpr("{{.msg}}"),
+	}, stages)
+
+	_, err = ParseMacro(" ")
+	require.ErrorContains(t, err, "the pipeline of a macro can't be empty")
+
+	_, err = ParseMacro(`json`)
+	require.ErrorContains(t, err, "invalid macro pipeline")
+}
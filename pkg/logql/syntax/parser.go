@@ -17,6 +17,9 @@ const (
 	EmptyMatchers = "{}"
 
 	errAtleastOneEqualityMatcherRequired = "queries require at least one regexp or equality matcher that does not have an empty-compatible value. For instance, app=~\".*\" does not meet this requirement, but app=~\".+\" will"
+	errStatsInMetricQuery                = "stats stage is only allowed in log queries"
+	errDedupInMetricQuery                = "dedup stage is only allowed in log queries"
+	errHistogramNotSummed                = "histogram_over_time can only be aggregated with sum"
 )
 
 var parserPool = sync.Pool{
@@ -46,6 +49,9 @@ func init() {
 	for str, tok := range tokens {
 		syntaxToknames[tok-syntaxPrivate+1] = str
 	}
+	for str, tok := range pipelineTokens {
+		syntaxToknames[tok-syntaxPrivate+1] = str
+	}
 }
 
 type parser struct {
@@ -57,6 +63,7 @@ type parser struct {
 
 func (p *parser) Parse() (Expr, error) {
 	p.errs = p.errs[:0]
+	p.last = 0
 	p.Scanner.Error = func(_ *Scanner, msg string) {
 		p.Error(msg)
 	}
@@ -115,8 +122,14 @@ func MustParseExpr(input string) Expr {
 func validateExpr(expr Expr) error {
 	switch e := expr.(type) {
 	case SampleExpr:
+		if err := validateHistogramExpr(e); err != nil {
+			return err
+		}
 		return validateSampleExpr(e)
 	case LogSelectorExpr:
+		if err := validateStatsStage(e); err != nil {
+			return err
+		}
 		return validateLogSelectorExpression(e)
 	case VariantsExpr:
 		return validateVariantsExpr(e)
@@ -126,6 +139,12 @@ func validateExpr(expr Expr) error {
 }
 
 func validateVariantsExpr(e VariantsExpr) error {
+	if ExtractStats(e.LogRange().Left) != nil {
+		return logqlmodel.NewParseError(errStatsInMetricQuery, 0, 0)
+	}
+	if ExtractDedup(e.LogRange().Left) != nil {
+		return logqlmodel.NewParseError(errDedupInMetricQuery, 0, 0)
+	}
 	err := validateLogSelectorExpression(e.LogRange().Left)
 	if err != nil {
 		return err
@@ -227,11 +246,30 @@ func validateSampleExpr(expr SampleExpr) error {
 			return e.err
 		}
 		return validateSampleExpr(e.Left)
+	case *SubqueryAggregationExpr:
+		if e.err != nil {
+			return e.err
+		}
+		return validateSampleExpr(e.Left.Left)
+	case *FunctionExpr:
+		if e.err != nil {
+			return e.err
+		}
+		if e.Left == nil {
+			return nil
+		}
+		return validateSampleExpr(e.Left)
 	default:
 		selector, err := e.Selector()
 		if err != nil {
 			return err
 		}
+		if ExtractStats(selector) != nil {
+			return logqlmodel.NewParseError(errStatsInMetricQuery, 0, 0)
+		}
+		if ExtractDedup(selector) != nil {
+			return logqlmodel.NewParseError(errDedupInMetricQuery, 0, 0)
+		}
 		return validateLogSelectorExpression(selector)
 	}
 }
@@ -241,10 +279,96 @@ func validateLogSelectorExpression(expr LogSelectorExpr) error {
 	case *VectorExpr:
 		return nil
 	default:
+		if macro := ExtractMacro(e); macro != nil {
+			return logqlmodel.NewParseError(fmt.Sprintf("macro %s%s is not defined", OpMacro, macro.Name), 0, 0)
+		}
+		if stats := ExtractStats(e); stats != nil && !isLastStage(e, stats) {
+			return logqlmodel.NewParseError("stats must be the last stage of a pipeline", 0, 0)
+		}
+		// Dedup is applied to the merged log lines of the query, after the
+		// pipeline.
+		if dedup := ExtractDedup(e); dedup != nil && !isLastStage(e, dedup) {
+			return logqlmodel.NewParseError("dedup must be the last stage of a pipeline", 0, 0)
+		}
+		if join := ExtractJoin(e); join != nil {
+			if err := validateJoinStage(e, join); err != nil {
+				return err
+			}
+		}
 		return validateMatchers(e.Matchers())
 	}
 }
 
+// validateJoinStage checks a join stage and the query it joins with. Join
+// merges the log lines of two queries, so it must be the last stage of the
+// pipeline and can't be nested.
+func validateJoinStage(expr LogSelectorExpr, join *JoinExpr) error {
+	if !isLastStage(expr, join) {
+		return logqlmodel.NewParseError("join must be the last stage of a pipeline", 0, 0)
+	}
+	if join.Window <= 0 {
+		return logqlmodel.NewParseError("join window must be greater than 0", 0, 0)
+	}
+	if macro := ExtractMacro(join.Right); macro != nil {
+		return logqlmodel.NewParseError(fmt.Sprintf("macro %s%s is not defined", OpMacro, macro.Name), 0, 0)
+	}
+	if ExtractJoin(join.Right) != nil || ExtractStats(join.Right) != nil {
+		return logqlmodel.NewParseError("the query of a join stage can't contain join or stats stages", 0, 0)
+	}
+	return validateMatchers(join.Right.Matchers())
+}
+
+// validateStatsStage checks the aggregations of a stats stage. Stats turns log
+// lines into a table, so it is only allowed at the end of a log query, which
+// validateLogSelectorExpression ensures.
+func validateStatsStage(expr LogSelectorExpr) error {
+	stats := ExtractStats(expr)
+	if stats == nil {
+		return nil
+	}
+	seen := make(map[string]struct{}, len(stats.Aggregations))
+	for _, agg := range stats.Aggregations {
+		name := agg.String()
+		if _, ok := seen[name]; ok {
+			return logqlmodel.NewParseError(fmt.Sprintf("duplicate stats aggregation %s", name), 0, 0)
+		}
+		seen[name] = struct{}{}
+	}
+	return nil
+}
+
+// validateHistogramExpr ensures that the native histograms returned by
+// histogram_over_time are either the result of the query or only summed,
+// since the other operations work on float samples.
+func validateHistogramExpr(expr SampleExpr) error {
+	for {
+		agg, ok := expr.(*VectorAggregationExpr)
+		if !ok || agg.Operation != OpTypeSum {
+			break
+		}
+		expr = agg.Left
+	}
+	if r, ok := expr.(*RangeAggregationExpr); ok && r.Operation == OpRangeTypeHistogram {
+		return nil
+	}
+	var err error
+	expr.Walk(func(e Expr) bool {
+		if r, ok := e.(*RangeAggregationExpr); ok && r.Operation == OpRangeTypeHistogram {
+			err = logqlmodel.NewParseError(errHistogramNotSummed, 0, 0)
+		}
+		return err == nil
+	})
+	return err
+}
+
+func isLastStage(expr LogSelectorExpr, stage StageExpr) bool {
+	p, ok := expr.(*PipelineExpr)
+	if !ok || len(p.MultiStages) == 0 {
+		return false
+	}
+	return p.MultiStages[len(p.MultiStages)-1] == stage
+}
+
 // validateSortGrouping prevent by|without groupings on sort operations.
 // This will keep compatibility with promql and allowing sort by (foo) doesn't make much sense anyway when sort orders by value instead of labels.
 func validateSortGrouping(grouping *Grouping) error {
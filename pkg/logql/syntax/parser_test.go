@@ -50,6 +50,80 @@ var ParseTestCases = []struct {
 			},
 		),
 	},
+	{
+		in: `{ foo = "bar" } | json | geoip src_ip | geoip_country_code != "SE"`,
+		exp: newPipelineExpr(
+			newMatcherExpr([]*labels.Matcher{mustNewMatcher(labels.MatchEqual, "foo", "bar")}),
+			MultiStageExpr{
+				newLabelParserExpr(OpParserTypeJSON, ""),
+				newGeoIPExpr("src_ip"),
+				&LabelFilterExpr{
+					LabelFilterer: log.NewStringLabelFilter(mustNewMatcher(labels.MatchNotEqual, "geoip_country_code", "SE")),
+				},
+			},
+		),
+	},
+	{
+		in: `{ foo = "bar" } | sample 0.01`,
+		exp: newPipelineExpr(
+			newMatcherExpr([]*labels.Matcher{mustNewMatcher(labels.MatchEqual, "foo", "bar")}),
+			MultiStageExpr{
+				&SamplingExpr{Ratio: 0.01},
+			},
+		),
+	},
+	{
+		in: `count_over_time({ foo = "bar" } | json | sample 0.1 by trace_id --extrapolate [5m])`,
+		exp: newRangeAggregationExpr(
+			newLogRange(
+				newPipelineExpr(
+					newMatcherExpr([]*labels.Matcher{mustNewMatcher(labels.MatchEqual, "foo", "bar")}),
+					MultiStageExpr{
+						newLabelParserExpr(OpParserTypeJSON, ""),
+						&SamplingExpr{Ratio: 0.1, By: []string{"trace_id"}, Extrapolate: true},
+					},
+				),
+				5*time.Minute, nil, nil),
+			OpRangeTypeCount, nil, nil,
+		),
+	},
+	{
+		in: `{ foo = "bar" } | sample 1 by (cluster, trace_id) | level="error"`,
+		exp: newPipelineExpr(
+			newMatcherExpr([]*labels.Matcher{mustNewMatcher(labels.MatchEqual, "foo", "bar")}),
+			MultiStageExpr{
+				&SamplingExpr{Ratio: 1, By: []string{"cluster", "trace_id"}},
+				&LabelFilterExpr{
+					LabelFilterer: log.NewStringLabelFilter(mustNewMatcher(labels.MatchEqual, "level", "error")),
+				},
+			},
+		),
+	},
+	{
+		in:  `{ foo = "bar" } | sample 2`,
+		exp: nil,
+		err: logqlmodel.NewParseError("invalid sample ratio 2, must be greater than 0 and less than or equal to 1", 0, 0),
+	},
+	{
+		in:  `{ foo = "bar" } | sample 0.1 --strict`,
+		exp: nil,
+		err: logqlmodel.NewParseError("invalid flag --strict for sample, only --extrapolate is supported", 0, 0),
+	},
+	{
+		in:  `{ foo = "bar" } | @access_log | status >= 500`,
+		exp: nil,
+		err: logqlmodel.NewParseError("macro @access_log is not defined", 0, 0),
+	},
+	{
+		in:  `{ foo = "bar" } | @ access_log`,
+		exp: nil,
+		err: logqlmodel.NewParseError("expected the name of a macro after @", 1, 19),
+	},
+	{
+		in:  `{ foo = "bar" } | geoip "src_ip"`,
+		exp: nil,
+		err: logqlmodel.NewParseError("syntax error: unexpected STRING, expecting IDENTIFIER", 1, 25),
+	},
 	{
 		// test [12h] before filter expr
 		in: `count_over_time({foo="bar"}[12h] |= "error")`,
@@ -390,6 +464,84 @@ var ParseTestCases = []struct {
 		in:  `label_replace(rate({ foo = "bar" }[5m]),"foo","$1","bar","^^^^x43\\q")`,
 		err: logqlmodel.NewParseError("invalid regex in label_replace: error parsing regexp: invalid escape sequence: `\\q`", 0, 0),
 	},
+	{
+		in: `max_over_time(sum(rate({ foo = "bar" }[5m]))[1h:1m])`,
+		exp: &SubqueryAggregationExpr{
+			Left: &SubqueryExpr{
+				Left: mustNewVectorAggregationExpr(
+					&RangeAggregationExpr{
+						Left: &LogRangeExpr{
+							Left:     &MatchersExpr{Mts: []*labels.Matcher{mustNewMatcher(labels.MatchEqual, "foo", "bar")}},
+							Interval: 5 * time.Minute,
+						},
+						Operation: "rate",
+					},
+					"sum", nil, nil),
+				Interval: time.Hour,
+				Step:     time.Minute,
+			},
+			Operation: "max_over_time",
+		},
+	},
+	{
+		in: `quantile_over_time(0.99, rate({ foo = "bar" }[5m])[1d:] offset 1h)`,
+		exp: newSubqueryAggregationExpr(
+			&SubqueryExpr{
+				Left: &RangeAggregationExpr{
+					Left: &LogRangeExpr{
+						Left:     &MatchersExpr{Mts: []*labels.Matcher{mustNewMatcher(labels.MatchEqual, "foo", "bar")}},
+						Interval: 5 * time.Minute,
+					},
+					Operation: "rate",
+				},
+				Interval: 24 * time.Hour,
+				Offset:   time.Hour,
+			},
+			OpRangeTypeQuantile, NewStringLabelFilter("0.99"),
+		),
+	},
+	{
+		in:  `bytes_over_time(rate({ foo = "bar" }[5m])[1h:1m])`,
+		err: logqlmodel.NewParseError("invalid aggregation bytes_over_time of a subquery", 0, 0),
+	},
+	{
+		in:  `quantile_over_time(rate({ foo = "bar" }[5m])[1h:1m])`,
+		err: logqlmodel.NewParseError("parameter required for operation quantile_over_time", 0, 0),
+	},
+	{
+		in:  `max_over_time(rate({ foo = "bar" }[5m])[0s:1m])`,
+		err: logqlmodel.NewParseError("subquery range must be positive", 0, 0),
+	},
+	{
+		in: `sum by (app) (histogram_over_time({app="foo"} | unwrap latency [5m]))`,
+		exp: mustNewVectorAggregationExpr(
+			newRangeAggregationExpr(
+				newLogRange(
+					newMatcherExpr([]*labels.Matcher{mustNewMatcher(labels.MatchEqual, "app", "foo")}),
+					5*time.Minute,
+					newUnwrapExpr("latency", ""),
+					nil),
+				OpRangeTypeHistogram, nil, nil,
+			),
+			OpTypeSum, &Grouping{Groups: []string{"app"}}, nil,
+		),
+	},
+	{
+		in:  `max(histogram_over_time({app="foo"} | unwrap latency [5m]))`,
+		err: logqlmodel.NewParseError(errHistogramNotSummed, 0, 0),
+	},
+	{
+		in:  `histogram_over_time({app="foo"} | unwrap latency [5m]) > 1`,
+		err: logqlmodel.NewParseError(errHistogramNotSummed, 0, 0),
+	},
+	{
+		in:  `histogram_over_time({app="foo"}[5m])`,
+		err: logqlmodel.NewParseError("invalid aggregation histogram_over_time without unwrap", 0, 0),
+	},
+	{
+		in:  `max_over_time({ foo = "bar" }[1h:1m])`,
+		err: logqlmodel.NewParseError("syntax error: unexpected SUBQUERY_RANGE", 0, 30),
+	},
 	{
 		in:  `rate({ foo = "bar" }[5)`,
 		err: logqlmodel.NewParseError("missing closing ']' in duration", 0, 21),
@@ -2927,7 +3079,7 @@ var ParseTestCases = []struct {
 	},
 	{
 		in:  `quantile_over_time(foo,{namespace="tns"} |= "level=error" | json |foo>=5,bar<25ms| unwrap latency [5m])`,
-		err: logqlmodel.NewParseError("syntax error: unexpected IDENTIFIER, expecting NUMBER or { or (", 1, 20),
+		err: logqlmodel.NewParseError("syntax error: unexpected IDENTIFIER", 1, 20),
 	},
 	{
 		in:  `vector(abc)`,
@@ -2941,6 +3093,80 @@ var ParseTestCases = []struct {
 		in:  `label_replace(vector(0), "foo", "bar", "", "")`,
 		exp: mustNewLabelReplaceExpr(&VectorExpr{Val: 0, err: nil}, "foo", "bar", "", ""),
 	},
+	{
+		in:  `time()`,
+		exp: newFunctionExpr(OpFuncTime, nil, nil),
+	},
+	{
+		in:  `hour()`,
+		exp: newFunctionExpr(OpFuncHour, nil, nil),
+	},
+	{
+		in: `abs(rate({ foo = "bar" }[5m]))`,
+		exp: newFunctionExpr(OpFuncAbs,
+			newRangeAggregationExpr(
+				newLogRange(newMatcherExpr([]*labels.Matcher{mustNewMatcher(labels.MatchEqual, "foo", "bar")}), 5*time.Minute, nil, nil),
+				OpRangeTypeRate, nil, nil),
+			nil),
+	},
+	{
+		in: `clamp_max(rate({ foo = "bar" }[5m]), 10)`,
+		exp: newFunctionExpr(OpFuncClampMax,
+			newRangeAggregationExpr(
+				newLogRange(newMatcherExpr([]*labels.Matcher{mustNewMatcher(labels.MatchEqual, "foo", "bar")}), 5*time.Minute, nil, nil),
+				OpRangeTypeRate, nil, nil),
+			mustNewLiteralExpr("10", false)),
+	},
+	{
+		in: `histogram_quantile(0.99, sum by (le) (rate({ foo = "bar" } | unwrap le [5m])))`,
+		exp: newFunctionExpr(OpFuncHistogramQuantile,
+			mustNewVectorAggregationExpr(
+				newRangeAggregationExpr(
+					newLogRange(newMatcherExpr([]*labels.Matcher{mustNewMatcher(labels.MatchEqual, "foo", "bar")}), 5*time.Minute, newUnwrapExpr("le", ""), nil),
+					OpRangeTypeRate, nil, nil),
+				OpTypeSum, &Grouping{Groups: []string{"le"}}, nil),
+			mustNewLiteralExpr("0.99", false)),
+	},
+	{
+		in:  `timestamp(vector(1))`,
+		exp: newFunctionExpr(OpFuncTimestamp, &VectorExpr{Val: 1}, nil),
+	},
+	{
+		in:  `clamp_min(rate({ foo = "bar" }[5m]))`,
+		err: logqlmodel.NewParseError("parameter required for function clamp_min", 0, 0),
+	},
+	{
+		in:  `abs(rate({ foo = "bar" }[5m]), 2)`,
+		err: logqlmodel.NewParseError("parameter 2 not supported for function abs", 0, 0),
+	},
+	{
+		in:  `ln()`,
+		err: logqlmodel.NewParseError("argument required for function ln", 0, 0),
+	},
+	{
+		in: `count_values("value", rate({ foo = "bar" }[5m]))`,
+		exp: mustNewCountValuesExpr(
+			newRangeAggregationExpr(
+				newLogRange(newMatcherExpr([]*labels.Matcher{mustNewMatcher(labels.MatchEqual, "foo", "bar")}), 5*time.Minute, nil, nil),
+				OpRangeTypeRate, nil, nil),
+			"value", nil),
+	},
+	{
+		in:  `count_values("", rate({ foo = "bar" }[5m]))`,
+		err: logqlmodel.NewParseError(`invalid label name "" for operation count_values`, 0, 0),
+	},
+	{
+		in: `deriv({ foo = "bar" } | unwrap bytes [5m])`,
+		exp: newRangeAggregationExpr(
+			newLogRange(newMatcherExpr([]*labels.Matcher{mustNewMatcher(labels.MatchEqual, "foo", "bar")}), 5*time.Minute, newUnwrapExpr("bytes", ""), nil),
+			OpRangeTypeDeriv, nil, nil),
+	},
+	{
+		in: `predict_linear({ foo = "bar" } | unwrap bytes [5m], 3600)`,
+		exp: newRangeAggregationExpr(
+			newLogRange(newMatcherExpr([]*labels.Matcher{mustNewMatcher(labels.MatchEqual, "foo", "bar")}), 5*time.Minute, newUnwrapExpr("bytes", ""), nil),
+			OpRangeTypePredictLinear, nil, NewStringLabelFilter("3600")),
+	},
 	{
 		in: `sum(vector(0))`,
 		exp: &VectorAggregationExpr{
@@ -3175,6 +3401,181 @@ var ParseTestCases = []struct {
 			},
 		},
 	},
+	{
+		in: `{app="foo"} | csv`,
+		exp: &PipelineExpr{
+			Left: newMatcherExpr([]*labels.Matcher{{Type: labels.MatchEqual, Name: "app", Value: "foo"}}),
+			MultiStages: MultiStageExpr{
+				&CSVParserExpr{Delimiter: ",", Quote: `"`},
+			},
+		},
+	},
+	{
+		in: `{app="foo"} | csv "ts,level,,msg" delimiter=";", quote="'"`,
+		exp: &PipelineExpr{
+			Left: newMatcherExpr([]*labels.Matcher{{Type: labels.MatchEqual, Name: "app", Value: "foo"}}),
+			MultiStages: MultiStageExpr{
+				&CSVParserExpr{Columns: "ts,level,,msg", Delimiter: ";", Quote: "'"},
+			},
+		},
+	},
+	{
+		in: `{app="foo"} | csv quote=""`,
+		exp: &PipelineExpr{
+			Left: newMatcherExpr([]*labels.Matcher{{Type: labels.MatchEqual, Name: "app", Value: "foo"}}),
+			MultiStages: MultiStageExpr{
+				&CSVParserExpr{Delimiter: ",", Quote: ""},
+			},
+		},
+	},
+	{
+		in:  `{app="foo"} | csv "ts,level" separator=";"`,
+		err: logqlmodel.NewParseError("invalid csv parser option: separator", 0, 0),
+	},
+	{
+		in:  `{app="foo"} | csv "ts,level" delimiter=", "`,
+		err: logqlmodel.NewParseError("invalid csv parser: the delimiter must be a single character, got ', '", 0, 0),
+	},
+	{
+		in: `{app="foo"} | logfmt | stats count(), avg(latency) by status`,
+		exp: &PipelineExpr{
+			Left: newMatcherExpr([]*labels.Matcher{{Type: labels.MatchEqual, Name: "app", Value: "foo"}}),
+			MultiStages: MultiStageExpr{
+				newLogfmtParserExpr(nil),
+				&StatsExpr{
+					Aggregations: []StatsAggregation{
+						{Operation: OpTypeCount},
+						{Operation: OpTypeAvg, Label: "latency"},
+					},
+					Grouping: &Grouping{Groups: []string{"status"}},
+				},
+			},
+		},
+	},
+	{
+		in: `{app="foo"} | stats sum(bytes), min(bytes), max(bytes) by (namespace, pod)`,
+		exp: &PipelineExpr{
+			Left: newMatcherExpr([]*labels.Matcher{{Type: labels.MatchEqual, Name: "app", Value: "foo"}}),
+			MultiStages: MultiStageExpr{
+				&StatsExpr{
+					Aggregations: []StatsAggregation{
+						{Operation: OpTypeSum, Label: "bytes"},
+						{Operation: OpTypeMin, Label: "bytes"},
+						{Operation: OpTypeMax, Label: "bytes"},
+					},
+					Grouping: &Grouping{Groups: []string{"namespace", "pod"}},
+				},
+			},
+		},
+	},
+	{
+		in:  `{app="foo"} | stats count() | logfmt`,
+		err: logqlmodel.NewParseError("stats must be the last stage of a pipeline", 0, 0),
+	},
+	{
+		in:  `{app="foo"} | stats count(), count()`,
+		err: logqlmodel.NewParseError("duplicate stats aggregation count()", 0, 0),
+	},
+	{
+		in:  `count_over_time({app="foo"} | stats count() [5m])`,
+		err: logqlmodel.NewParseError("stats stage is only allowed in log queries", 0, 0),
+	},
+	{
+		in: `{app="foo"} | json | join on (request_id, user) [30s] ({app="bar"} |= "error" | logfmt)`,
+		exp: &PipelineExpr{
+			Left: newMatcherExpr([]*labels.Matcher{{Type: labels.MatchEqual, Name: "app", Value: "foo"}}),
+			MultiStages: MultiStageExpr{
+				newLabelParserExpr(OpParserTypeJSON, ""),
+				&JoinExpr{
+					On:     []string{"request_id", "user"},
+					Window: 30 * time.Second,
+					Right: newPipelineExpr(
+						newMatcherExpr([]*labels.Matcher{{Type: labels.MatchEqual, Name: "app", Value: "bar"}}),
+						MultiStageExpr{
+							newLineFilterExpr(log.LineMatchEqual, "", "error"),
+							newLogfmtParserExpr(nil),
+						},
+					),
+				},
+			},
+		},
+	},
+	{
+		in:  `{app="foo"} | join on (request_id) [30s] ({app="bar"}) | json`,
+		err: logqlmodel.NewParseError("join must be the last stage of a pipeline", 0, 0),
+	},
+	{
+		in:  `{app="foo"} | join on (request_id) [30s] ({app="bar"} | join on (request_id) [30s] ({app="baz"}))`,
+		err: logqlmodel.NewParseError("the query of a join stage can't contain join or stats stages", 0, 0),
+	},
+	{
+		in:  `{app="foo"} | join on (request_id) [0s] ({app="bar"})`,
+		err: logqlmodel.NewParseError("join window must be greater than 0", 0, 0),
+	},
+	{
+		in: `{app="foo"} | dedup`,
+		exp: &PipelineExpr{
+			Left:        newMatcherExpr([]*labels.Matcher{{Type: labels.MatchEqual, Name: "app", Value: "foo"}}),
+			MultiStages: MultiStageExpr{&DedupExpr{}},
+		},
+	},
+	{
+		in: `{app="foo"} | json | dedup 5s by (request_id, user)`,
+		exp: &PipelineExpr{
+			Left: newMatcherExpr([]*labels.Matcher{{Type: labels.MatchEqual, Name: "app", Value: "foo"}}),
+			MultiStages: MultiStageExpr{
+				newLabelParserExpr(OpParserTypeJSON, ""),
+				&DedupExpr{By: []string{"request_id", "user"}, Window: 5 * time.Second},
+			},
+		},
+	},
+	{
+		in: `{app="foo"} | logfmt | dedup by request_id`,
+		exp: &PipelineExpr{
+			Left: newMatcherExpr([]*labels.Matcher{{Type: labels.MatchEqual, Name: "app", Value: "foo"}}),
+			MultiStages: MultiStageExpr{
+				newLogfmtParserExpr(nil),
+				&DedupExpr{By: []string{"request_id"}},
+			},
+		},
+	},
+	{
+		in:  `{app="foo"} | dedup | json`,
+		err: logqlmodel.NewParseError("dedup must be the last stage of a pipeline", 0, 0),
+	},
+	{
+		in:  `{app="foo"} | dedup -5s`,
+		err: logqlmodel.NewParseError("invalid dedup window -5s, must be greater than or equal to 0", 0, 0),
+	},
+	{
+		in:  `count_over_time({app="foo"} | dedup [5m])`,
+		err: logqlmodel.NewParseError("dedup stage is only allowed in log queries", 0, 0),
+	},
+	{
+		in: `{app="foo"} | xml`,
+		exp: &PipelineExpr{
+			Left: newMatcherExpr([]*labels.Matcher{{Type: labels.MatchEqual, Name: "app", Value: "foo"}}),
+			MultiStages: MultiStageExpr{
+				newLabelParserExpr(OpParserTypeXML, ""),
+			},
+		},
+	},
+	{
+		in: `{app="foo"} | xml event_id="System/EventID", user="EventData/Data[@Name='TargetUserName']"`,
+		exp: &PipelineExpr{
+			Left: newMatcherExpr([]*labels.Matcher{{Type: labels.MatchEqual, Name: "app", Value: "foo"}}),
+			MultiStages: MultiStageExpr{
+				newXMLExpressionParser([]log.LabelExtractionExpr{
+					log.NewLabelExtractionExpr("event_id", `System/EventID`),
+					log.NewLabelExtractionExpr("user", `EventData/Data[@Name='TargetUserName']`),
+				}),
+			},
+		},
+	},
+	{
+		in:  `{app="foo"} | xml event_id="//EventID"`,
+		err: logqlmodel.NewParseError("invalid xml parser: cannot parse expression [//EventID]: descendant steps are not supported", 0, 0),
+	},
 	{
 		in: `{app="foo"} |= "foo" or "bar" |= "buzz" or "fizz"`,
 		exp: &PipelineExpr{
@@ -3386,6 +3787,69 @@ func TestParse(t *testing.T) {
 	}
 }
 
+func TestParse_PipelineKeywordsAsLabelNames(t *testing.T) {
+	for _, tc := range []struct {
+		in  string
+		exp Expr
+	}{
+		{
+			in:  `{stats="1"}`,
+			exp: newMatcherExpr([]*labels.Matcher{mustNewMatcher(labels.MatchEqual, "stats", "1")}),
+		},
+		{
+			in: `{app="foo"} | csv="1"`,
+			exp: newPipelineExpr(
+				newMatcherExpr([]*labels.Matcher{mustNewMatcher(labels.MatchEqual, "app", "foo")}),
+				MultiStageExpr{
+					newLabelFilterExpr(log.NewStringLabelFilter(mustNewMatcher(labels.MatchEqual, "csv", "1"))),
+				},
+			),
+		},
+		{
+			in: `sum by (sample) (count_over_time({join="a", xml!="b"}[5m]))`,
+			exp: mustNewVectorAggregationExpr(
+				newRangeAggregationExpr(
+					&LogRangeExpr{
+						Left: newMatcherExpr([]*labels.Matcher{
+							mustNewMatcher(labels.MatchEqual, "join", "a"),
+							mustNewMatcher(labels.MatchNotEqual, "xml", "b"),
+						}),
+						Interval: 5 * time.Minute,
+					},
+					OpRangeTypeCount, nil, nil,
+				),
+				OpTypeSum,
+				&Grouping{Groups: []string{"sample"}},
+				nil,
+			),
+		},
+	} {
+		t.Run(tc.in, func(t *testing.T) {
+			ast, err := ParseExpr(tc.in)
+			require.NoError(t, err)
+			AssertExpressions(t, tc.exp, ast)
+		})
+	}
+
+	// The keywords can be used anywhere a label name is expected.
+	for _, in := range []string{
+		`{app="foo"} | dedup!="1" | geoip=~"a.*" | sample > 1`,
+		`{app="foo"} | json | keep stats, join | drop dedup`,
+		`{app="foo"} | logfmt | label_format csv=xml | line_format "{{.csv}}"`,
+		`{app="foo"} | csv | stats count() by (join)`,
+		`{app="foo"} | dedup 5s by (stats, sample)`,
+		`max_over_time({app="foo"} | logfmt | unwrap sample [5m]) by (geoip)`,
+	} {
+		t.Run(in, func(t *testing.T) {
+			ast, err := ParseExpr(in)
+			require.NoError(t, err)
+			// The canonical form of the query still parses.
+			_, err = ParseExpr(ast.String())
+			require.NoError(t, err)
+		})
+	}
+}
+
 func TestParseMatchers(t *testing.T) {
 	tests := []struct {
 		input   string
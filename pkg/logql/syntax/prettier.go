@@ -109,6 +109,7 @@ func (e *LogfmtParserExpr) Pretty(level int) string {
 // `| regexp`
 // `| pattern`
 // `| unpack`
+// `| xml`
 func (e *LineParserExpr) Pretty(level int) string {
 	return commonPrefixIndent(level, e)
 }
@@ -121,6 +122,16 @@ func (e *KeepLabelsExpr) Pretty(level int) string {
 	return commonPrefixIndent(level, e)
 }
 
+// e.g: | stats count(), avg(latency) by (status)
+func (e *StatsExpr) Pretty(level int) string {
+	return commonPrefixIndent(level, e)
+}
+
+// e.g: | join on (request_id) [30s] ({app="b"} | json)
+func (e *JoinExpr) Pretty(level int) string {
+	return commonPrefixIndent(level, e)
+}
+
 // e.g: | level!="error"
 func (e *LabelFilterExpr) Pretty(level int) string {
 	return commonPrefixIndent(level, e)
@@ -136,6 +147,26 @@ func (e *DecolorizeExpr) Pretty(_ int) string {
 	return e.String()
 }
 
+// e.g: | geoip src_ip
+func (e *GeoIPExpr) Pretty(_ int) string {
+	return e.String()
+}
+
+// e.g: | sample 0.01 by (trace_id)
+func (e *SamplingExpr) Pretty(_ int) string {
+	return e.String()
+}
+
+// e.g: | dedup 5s by (request_id)
+func (e *DedupExpr) Pretty(_ int) string {
+	return e.String()
+}
+
+// e.g: | @access_log
+func (e *MacroExpr) Pretty(_ int) string {
+	return e.String()
+}
+
 // e.g: | label_format dst="{{ .src }}"
 func (e *LabelFmtExpr) Pretty(level int) string {
 	return commonPrefixIndent(level, e)
@@ -151,6 +182,16 @@ func (e *LogfmtExpressionParserExpr) Pretty(level int) string {
 	return commonPrefixIndent(level, e)
 }
 
+// e.g: | csv "column,another" delimiter=";"
+func (e *CSVParserExpr) Pretty(level int) string {
+	return commonPrefixIndent(level, e)
+}
+
+// e.g: | xml label="xpath", another="xpath"
+func (e *XMLExpressionParserExpr) Pretty(level int) string {
+	return commonPrefixIndent(level, e)
+}
+
 // e.g: sum_over_time({foo="bar"} | logfmt | unwrap bytes_processed [5m])
 func (e *UnwrapExpr) Pretty(level int) string {
 	s := Indent(level)
@@ -210,13 +251,17 @@ func (e *RangeAggregationExpr) Pretty(level int) string {
 	s += "(\n"
 
 	// print args to the function.
-	if e.Params != nil {
+	if e.Params != nil && e.Operation != OpRangeTypePredictLinear {
 		s = fmt.Sprintf("%s%s%s,", s, Indent(level+1), fmt.Sprint(*e.Params))
 		s += "\n"
 	}
 
 	s += e.Left.Pretty(level + 1)
 
+	if e.Params != nil && e.Operation == OpRangeTypePredictLinear {
+		s = fmt.Sprintf("%s,\n%s%s", s, Indent(level+1), fmt.Sprint(*e.Params))
+	}
+
 	s += "\n" + Indent(level) + ")"
 
 	if e.Grouping != nil {
@@ -226,6 +271,46 @@ func (e *RangeAggregationExpr) Pretty(level int) string {
 	return s
 }
 
+// e.g: max_over_time(sum(rate({foo="bar"}[5m]))[1h:5m])
+func (e *SubqueryAggregationExpr) Pretty(level int) string {
+	s := Indent(level)
+	if !NeedSplit(e) {
+		return s + e.String()
+	}
+
+	s += e.Operation
+
+	s += "(\n"
+
+	if e.Params != nil && e.Operation != OpRangeTypePredictLinear {
+		s = fmt.Sprintf("%s%s%s,", s, Indent(level+1), fmt.Sprint(*e.Params))
+		s += "\n"
+	}
+
+	s += e.Left.Pretty(level + 1)
+
+	if e.Params != nil && e.Operation == OpRangeTypePredictLinear {
+		s = fmt.Sprintf("%s,\n%s%s", s, Indent(level+1), fmt.Sprint(*e.Params))
+	}
+
+	s += "\n" + Indent(level) + ")"
+
+	return s
+}
+
+// e.g: sum(rate({foo="bar"}[5m]))[1h:5m]
+// NOTE: like for log ranges, the `[range:step]` stays on the last line of the inner expression.
+func (e *SubqueryExpr) Pretty(level int) string {
+	s := e.Left.Pretty(level) + " " + e.rangeString()
+
+	if e.Offset != 0 {
+		oe := OffsetExpr{Offset: e.Offset}
+		s += oe.Pretty(level)
+	}
+
+	return s
+}
+
 // e.g:
 // sum(count_over_time({foo="bar"}[5m])) by (container)
 // topk(10, count_over_time({foo="bar"}[5m])) by (container)
@@ -250,6 +335,8 @@ func (e *VectorAggregationExpr) Pretty(level int) string {
 	// e.Params default value (0) can mean a legit param for topk and bottomk
 	case OpTypeBottomK, OpTypeTopK:
 		params = []string{fmt.Sprintf("%s%d", Indent(level+1), e.Params), left}
+	case OpTypeCountValues:
+		params = []string{Indent(level+1) + strconv.Quote(e.Label), left}
 
 	default:
 		if e.Params != 0 {
@@ -338,6 +425,45 @@ func (e *LabelReplaceExpr) Pretty(level int) string {
 	return s
 }
 
+// e.g: clamp_min(sum(rate({foo="bar"}[5m])), 0)
+func (e *FunctionExpr) Pretty(level int) string {
+	s := Indent(level)
+
+	if !NeedSplit(e) {
+		return s + e.String()
+	}
+
+	s += e.Function
+
+	s += "(\n"
+
+	var params []string
+	if e.Left != nil {
+		params = append(params, e.Left.Pretty(level+1))
+	}
+	if e.Params != nil {
+		p := Indent(level+1) + fmt.Sprint(*e.Params)
+		if e.Function == OpFuncHistogramQuantile {
+			params = append([]string{p}, params...)
+		} else {
+			params = append(params, p)
+		}
+	}
+
+	for i, v := range params {
+		s += v
+		// LogQL doesn't allow `,` at the end of last argument.
+		if i < len(params)-1 {
+			s += ","
+		}
+		s += "\n"
+	}
+
+	s += Indent(level) + ")"
+
+	return s
+}
+
 // e.g: vector(5)
 func (e *VectorExpr) Pretty(level int) string {
 	return commonPrefixIndent(level, e)
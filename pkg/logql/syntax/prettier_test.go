@@ -200,6 +200,96 @@ func TestFormat_LabelReplace(t *testing.T) {
 	}
 }
 
+func TestFormat_Subquery(t *testing.T) {
+	MaxCharsPerLine = 20
+
+	cases := []struct {
+		name string
+		in   string
+		exp  string
+	}{
+		{
+			name: "subquery",
+			in:   `max_over_time(sum by (service) (rate({job="api-server"}|= "err" [5m]))[1h:5m] offset 1h)`,
+			exp: `max_over_time(
+  sum by (service)(
+    rate(
+      {job="api-server"}
+        |= "err" [5m]
+    )
+  ) [1h:5m] offset 1h
+)`,
+		},
+		{
+			name: "subquery_with_parameter",
+			in:   `quantile_over_time(0.99, rate({job="api-server"}[5m])[1d:])`,
+			exp: `quantile_over_time(
+  0.99,
+  rate(
+    {job="api-server"} [5m]
+  ) [1d:]
+)`,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			expr, err := ParseExpr(c.in)
+			require.NoError(t, err)
+			got := Prettify(expr)
+			assert.Equal(t, c.exp, got)
+		})
+	}
+}
+
+func TestFormat_Function(t *testing.T) {
+	MaxCharsPerLine = 20
+
+	cases := []struct {
+		name string
+		in   string
+		exp  string
+	}{
+		{
+			name: "function",
+			in:   `clamp_max(rate({job="api-server"}[5m]), 10)`,
+			exp: `clamp_max(
+  rate(
+    {job="api-server"} [5m]
+  ),
+  10
+)`,
+		},
+		{
+			name: "histogram_quantile",
+			in:   `histogram_quantile(0.9, sum by (le) (rate({job="api-server"} | unwrap le [5m])))`,
+			exp: `histogram_quantile(
+  0.9,
+  sum by (le)(
+    rate(
+      {job="api-server"}
+        | unwrap le [5m]
+    )
+  )
+)`,
+		},
+		{
+			name: "no argument",
+			in:   `time()`,
+			exp:  `time()`,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			expr, err := ParseExpr(c.in)
+			require.NoError(t, err)
+			got := Prettify(expr)
+			assert.Equal(t, c.exp, got)
+		})
+	}
+}
+
 func TestFormat_BinOp(t *testing.T) {
 	MaxCharsPerLine = 20
 
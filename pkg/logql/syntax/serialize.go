@@ -38,6 +38,7 @@ const (
 	Card                = "cardinality"
 	Dst                 = "dst"
 	Duration            = "duration"
+	Function            = "function"
 	Groups              = "groups"
 	GroupingField       = "grouping"
 	Include             = "include"
@@ -68,7 +69,10 @@ const (
 	ReturnBool          = "return_bool"
 	RHS                 = "rhs"
 	Src                 = "src"
+	StepNanos           = "step_nanos"
 	StringField         = "string"
+	Subquery            = "subquery"
+	SubqueryAgg         = "subquery_agg"
 	NoopField           = "noop"
 	Type                = "type"
 	Unwrap              = "unwrap"
@@ -92,12 +96,16 @@ func DecodeJSON(raw string) (Expr, error) {
 		return decodeVectorAgg(iter)
 	case RangeAgg:
 		return decodeRangeAgg(iter)
+	case SubqueryAgg:
+		return decodeSubqueryAgg(iter)
 	case Literal:
 		return decodeLiteral(iter)
 	case Vector:
 		return decodeVector(iter)
 	case LabelReplace:
 		return decodeLabelReplace(iter)
+	case Function:
+		return decodeFunction(iter)
 	case LogSelector:
 		return decodeLogSelector(iter)
 	case Variants:
@@ -169,6 +177,12 @@ func (v *JSONSerializer) VisitVectorAggregation(e *VectorAggregationExpr) {
 		encodeGrouping(v.Stream, e.Grouping)
 	}
 
+	if e.Label != "" {
+		v.WriteMore()
+		v.WriteObjectField(Label)
+		v.WriteString(e.Label)
+	}
+
 	v.WriteMore()
 	v.WriteObjectField(Inner)
 	e.Left.Accept(v)
@@ -232,6 +246,50 @@ func (v *JSONSerializer) VisitLogRange(e *LogRangeExpr) {
 	v.Flush()
 }
 
+func (v *JSONSerializer) VisitSubqueryAggregation(e *SubqueryAggregationExpr) {
+	v.WriteObjectStart()
+
+	v.WriteObjectField(SubqueryAgg)
+	v.WriteObjectStart()
+
+	v.WriteObjectField(Op)
+	v.WriteString(e.Operation)
+
+	if e.Params != nil {
+		v.WriteMore()
+		v.WriteObjectField(Params)
+		v.WriteFloat64(*e.Params)
+	}
+
+	v.WriteMore()
+	v.WriteObjectField(Subquery)
+	v.VisitSubquery(e.Left)
+
+	v.WriteObjectEnd()
+	v.WriteObjectEnd()
+	v.Flush()
+}
+
+func (v *JSONSerializer) VisitSubquery(e *SubqueryExpr) {
+	v.WriteObjectStart()
+
+	v.WriteObjectField(IntervalNanos)
+	v.WriteInt64(int64(e.Interval))
+	v.WriteMore()
+	v.WriteObjectField(StepNanos)
+	v.WriteInt64(int64(e.Step))
+	v.WriteMore()
+	v.WriteObjectField(OffsetNanos)
+	v.WriteInt64(int64(e.Offset))
+
+	v.WriteMore()
+	v.WriteObjectField(Inner)
+	e.Left.Accept(v)
+
+	v.WriteObjectEnd()
+	v.Flush()
+}
+
 func (v *JSONSerializer) VisitLabelReplace(e *LabelReplaceExpr) {
 	v.WriteObjectStart()
 
@@ -262,6 +320,32 @@ func (v *JSONSerializer) VisitLabelReplace(e *LabelReplaceExpr) {
 	v.Flush()
 }
 
+func (v *JSONSerializer) VisitFunction(e *FunctionExpr) {
+	v.WriteObjectStart()
+
+	v.WriteObjectField(Function)
+	v.WriteObjectStart()
+
+	v.WriteObjectField(Name)
+	v.WriteString(e.Function)
+
+	if e.Params != nil {
+		v.WriteMore()
+		v.WriteObjectField(Params)
+		v.WriteFloat64(*e.Params)
+	}
+
+	if e.Left != nil {
+		v.WriteMore()
+		v.WriteObjectField(Inner)
+		e.Left.Accept(v)
+	}
+
+	v.WriteObjectEnd()
+	v.WriteObjectEnd()
+	v.Flush()
+}
+
 func (v *JSONSerializer) VisitLiteral(e *LiteralExpr) {
 	v.WriteObjectStart()
 
@@ -348,6 +432,14 @@ func (*JSONSerializer) VisitLineFilter(*LineFilterExpr)
 func (*JSONSerializer) VisitLineFmt(*LineFmtExpr)                               {}
 func (*JSONSerializer) VisitLogfmtExpressionParser(*LogfmtExpressionParserExpr) {}
 func (*JSONSerializer) VisitLogfmtParser(*LogfmtParserExpr)                     {}
+func (*JSONSerializer) VisitCSVParser(*CSVParserExpr)                           {}
+func (*JSONSerializer) VisitXMLExpressionParser(*XMLExpressionParserExpr)       {}
+func (*JSONSerializer) VisitStats(*StatsExpr)                                   {}
+func (*JSONSerializer) VisitJoin(*JoinExpr)                                     {}
+func (*JSONSerializer) VisitGeoIP(*GeoIPExpr)                                   {}
+func (*JSONSerializer) VisitSampling(*SamplingExpr)                             {}
+func (*JSONSerializer) VisitDedup(*DedupExpr)                                   {}
+func (*JSONSerializer) VisitMacro(*MacroExpr)                                   {}
 
 func encodeGrouping(s *jsoniter.Stream, g *Grouping) {
 	s.WriteObjectStart()
@@ -742,12 +834,16 @@ func decodeSample(iter *jsoniter.Iterator) (SampleExpr, error) {
 			expr, err = decodeVectorAgg(iter)
 		case RangeAgg:
 			expr, err = decodeRangeAgg(iter)
+		case SubqueryAgg:
+			expr, err = decodeSubqueryAgg(iter)
 		case Literal:
 			expr, err = decodeLiteral(iter)
 		case Vector:
 			expr, err = decodeVector(iter)
 		case LabelReplace:
 			expr, err = decodeLabelReplace(iter)
+		case Function:
+			expr, err = decodeFunction(iter)
 		default:
 			return nil, fmt.Errorf("unknown sample expression type: %s", key)
 		}
@@ -860,6 +956,8 @@ func decodeVectorAgg(iter *jsoniter.Iterator) (*VectorAggregationExpr, error) {
 			expr.Params = iter.ReadInt()
 		case GroupingField:
 			expr.Grouping, err = decodeGrouping(iter)
+		case Label:
+			expr.Label = iter.ReadString()
 		case Inner:
 			expr.Left, err = decodeSample(iter)
 		}
@@ -909,6 +1007,45 @@ func decodeLogRange(iter *jsoniter.Iterator) (*LogRangeExpr, error) {
 	return expr, err
 }
 
+func decodeSubqueryAgg(iter *jsoniter.Iterator) (*SubqueryAggregationExpr, error) {
+	expr := &SubqueryAggregationExpr{}
+	var err error
+
+	for f := iter.ReadObject(); f != ""; f = iter.ReadObject() {
+		switch f {
+		case Op:
+			expr.Operation = iter.ReadString()
+		case Params:
+			tmp := iter.ReadFloat64()
+			expr.Params = &tmp
+		case Subquery:
+			expr.Left, err = decodeSubquery(iter)
+		}
+	}
+
+	return expr, err
+}
+
+func decodeSubquery(iter *jsoniter.Iterator) (*SubqueryExpr, error) {
+	expr := &SubqueryExpr{}
+	var err error
+
+	for f := iter.ReadObject(); f != ""; f = iter.ReadObject() {
+		switch f {
+		case Inner:
+			expr.Left, err = decodeSample(iter)
+		case IntervalNanos:
+			expr.Interval = time.Duration(iter.ReadInt64())
+		case StepNanos:
+			expr.Step = time.Duration(iter.ReadInt64())
+		case OffsetNanos:
+			expr.Offset = time.Duration(iter.ReadInt64())
+		}
+	}
+
+	return expr, err
+}
+
 func decodeLabelReplace(iter *jsoniter.Iterator) (*LabelReplaceExpr, error) {
 	var err error
 	var left SampleExpr
@@ -935,6 +1072,25 @@ func decodeLabelReplace(iter *jsoniter.Iterator) (*LabelReplaceExpr, error) {
 	return mustNewLabelReplaceExpr(left, dst, replacement, src, regex), nil
 }
 
+func decodeFunction(iter *jsoniter.Iterator) (*FunctionExpr, error) {
+	expr := &FunctionExpr{}
+	var err error
+
+	for f := iter.ReadObject(); f != ""; f = iter.ReadObject() {
+		switch f {
+		case Name:
+			expr.Function = iter.ReadString()
+		case Params:
+			tmp := iter.ReadFloat64()
+			expr.Params = &tmp
+		case Inner:
+			expr.Left, err = decodeSample(iter)
+		}
+	}
+
+	return expr, err
+}
+
 func decodeLiteral(iter *jsoniter.Iterator) (*LiteralExpr, error) {
 	expr := &LiteralExpr{}
 
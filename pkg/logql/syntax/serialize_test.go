@@ -59,6 +59,12 @@ func TestJSONSerializationRoundTrip(t *testing.T) {
 		"empty label filter string": {
 			query: `rate({app="foo"} |= "bar" | json | unwrap latency | path!="" [5m])`,
 		},
+		"subquery": {
+			query: `quantile_over_time(0.9, sum by (app) (rate({foo="bar"} | json [5m]))[1h:1m] offset 1h)`,
+		},
+		"nested subqueries": {
+			query: `max_over_time(avg_over_time(rate({foo="bar"}[5m])[1h:])[1d:1h])`,
+		},
 		"multiple variants": {
 			query: `variants(bytes_over_time({foo="bar"}[5m]), count_over_time({foo="bar"}[5m])) of ({foo="bar"}[5m])`,
 		},
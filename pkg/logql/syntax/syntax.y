@@ -12,6 +12,7 @@ import (
   val interface{}
   bytes uint64
   dur time.Duration
+  subqueryRange subqueryRange
   op string
   binOp string
   str string
@@ -43,24 +44,27 @@ import (
   labelExtractionExpressionList []log.LabelExtractionExpr
   unwrapExpr *UnwrapExpr
   offsetExpr *OffsetExpr
+  subqueryExpr *SubqueryExpr
+  statsAggregation StatsAggregation
+  statsAggregations []StatsAggregation
 }
 
 %start root
 
 %type <expr> expr
 %type <logExpr> logExpr
-%type <metricExpr> metricExpr rangeAggregationExpr vectorAggregationExpr binOpExpr labelReplaceExpr vectorExpr
+%type <metricExpr> metricExpr rangeAggregationExpr vectorAggregationExpr binOpExpr labelReplaceExpr vectorExpr subqueryAggregationExpr functionExpr
 %type <variantsExpr> variantsExpr
-%type <stage> pipelineStage logfmtParser labelParser jsonExpressionParser logfmtExpressionParser lineFormatExpr decolorizeExpr labelFormatExpr dropLabelsExpr keepLabelsExpr
+%type <stage> pipelineStage logfmtParser labelParser jsonExpressionParser logfmtExpressionParser csvParser xmlExpressionParser lineFormatExpr decolorizeExpr labelFormatExpr dropLabelsExpr keepLabelsExpr statsExpr joinExpr geoIPExpr samplingExpr dedupExpr macroExpr
 %type <stages> pipelineExpr
 %type <lineFilterExpr> lineFilter lineFilters orFilter
-%type <op> rangeOp convOp vectorOp filterOp
+%type <op> rangeOp convOp vectorOp filterOp functionOp statsOp
 %type <filterer> bytesFilter numberFilter durationFilter labelFilter unitFilter ipLabelFilter
 %type <filter> filter
 %type <matcher> matcher
 %type <matchers> matchers selector
 %type <str> vector
-%type <strs> labels parserFlags
+%type <strs> labels parserFlags csvOptions
 %type <binOpts> binOpModifier boolModifier onOrIgnoringModifier
 %type <namedMatcher> namedMatcher
 %type <namedMatchers> namedMatchers
@@ -73,18 +77,23 @@ import (
 %type <labelExtractionExpressionList> labelExtractionExpressionList
 %type <unwrapExpr> unwrapExpr
 %type <offsetExpr> offsetExpr
+%type <subqueryExpr> subqueryExpr
 %type <metricExprs> metricExprs
+%type <statsAggregation> statsAggregation
+%type <statsAggregations> statsAggregations
 
 %token <bytes> BYTES
-%token <str> IDENTIFIER STRING NUMBER FUNCTION_FLAG
+%token <str> IDENTIFIER STRING NUMBER FUNCTION_FLAG MACRO
 %token <dur> DURATION RANGE
+%token <subqueryRange> SUBQUERY_RANGE
 %token <val> MATCHERS LABELS EQ RE NRE NPA OPEN_BRACE CLOSE_BRACE OPEN_BRACKET CLOSE_BRACKET COMMA DOT PIPE_MATCH PIPE_EXACT PIPE_PATTERN
              OPEN_PARENTHESIS CLOSE_PARENTHESIS BY WITHOUT COUNT_OVER_TIME RATE RATE_COUNTER SUM SORT SORT_DESC AVG
              MAX MIN COUNT STDDEV STDVAR BOTTOMK TOPK APPROX_TOPK
              BYTES_OVER_TIME BYTES_RATE BOOL JSON REGEXP LOGFMT PIPE LINE_FMT LABEL_FMT UNWRAP AVG_OVER_TIME SUM_OVER_TIME MIN_OVER_TIME
              MAX_OVER_TIME STDVAR_OVER_TIME STDDEV_OVER_TIME QUANTILE_OVER_TIME BYTES_CONV DURATION_CONV DURATION_SECONDS_CONV
              FIRST_OVER_TIME LAST_OVER_TIME ABSENT_OVER_TIME VECTOR LABEL_REPLACE UNPACK OFFSET PATTERN IP ON IGNORING GROUP_LEFT GROUP_RIGHT
-             DECOLORIZE DROP KEEP VARIANTS OF
+             DECOLORIZE DROP KEEP VARIANTS OF DERIV PREDICT_LINEAR COUNT_VALUES ABS CEIL FLOOR ROUND LN EXP CLAMP_MIN
+             CLAMP_MAX TIME TIMESTAMP DAY_OF_WEEK HOUR ABSENT HISTOGRAM_QUANTILE HISTOGRAM_OVER_TIME CSV XML STATS JOIN GEOIP SAMPLE DEDUP
 
 // Operators are listed with increasing precedence.
 %left <binOp> OR
@@ -118,6 +127,8 @@ metricExpr:
     | literalExpr                                   { $$ = $1 }
     | labelReplaceExpr                              { $$ = $1 }
     | vectorExpr                                    { $$ = $1 }
+    | subqueryAggregationExpr                       { $$ = $1 }
+    | functionExpr                                  { $$ = $1 }
     | OPEN_PARENTHESIS metricExpr CLOSE_PARENTHESIS { $$ = $2 }
     ;
 
@@ -171,6 +182,20 @@ rangeAggregationExpr:
     | rangeOp OPEN_PARENTHESIS NUMBER COMMA logRangeExpr CLOSE_PARENTHESIS           { $$ = newRangeAggregationExpr($5, $1, nil, &$3) }
     | rangeOp OPEN_PARENTHESIS logRangeExpr CLOSE_PARENTHESIS grouping               { $$ = newRangeAggregationExpr($3, $1, $5, nil) }
     | rangeOp OPEN_PARENTHESIS NUMBER COMMA logRangeExpr CLOSE_PARENTHESIS grouping  { $$ = newRangeAggregationExpr($5, $1, $7, &$3) }
+    // The parameter of predict_linear follows the range, like in PromQL.
+    | PREDICT_LINEAR OPEN_PARENTHESIS logRangeExpr COMMA NUMBER CLOSE_PARENTHESIS           { $$ = newRangeAggregationExpr($3, OpRangeTypePredictLinear, nil, &$5) }
+    | PREDICT_LINEAR OPEN_PARENTHESIS logRangeExpr COMMA NUMBER CLOSE_PARENTHESIS grouping  { $$ = newRangeAggregationExpr($3, OpRangeTypePredictLinear, $7, &$5) }
+    ;
+
+subqueryExpr:
+      metricExpr SUBQUERY_RANGE             { $$ = newSubqueryExpr($1, $2, nil) }
+    | metricExpr SUBQUERY_RANGE offsetExpr  { $$ = newSubqueryExpr($1, $2, $3) }
+    ;
+
+subqueryAggregationExpr:
+      rangeOp OPEN_PARENTHESIS subqueryExpr CLOSE_PARENTHESIS               { $$ = newSubqueryAggregationExpr($3, $1, nil) }
+    | rangeOp OPEN_PARENTHESIS NUMBER COMMA subqueryExpr CLOSE_PARENTHESIS  { $$ = newSubqueryAggregationExpr($5, $1, &$3) }
+    | PREDICT_LINEAR OPEN_PARENTHESIS subqueryExpr COMMA NUMBER CLOSE_PARENTHESIS  { $$ = newSubqueryAggregationExpr($3, OpRangeTypePredictLinear, &$5) }
     ;
 
 vectorAggregationExpr:
@@ -182,6 +207,10 @@ vectorAggregationExpr:
     | vectorOp OPEN_PARENTHESIS NUMBER COMMA metricExpr CLOSE_PARENTHESIS                 { $$ = mustNewVectorAggregationExpr($5, $1, nil, &$3) }
     | vectorOp OPEN_PARENTHESIS NUMBER COMMA metricExpr CLOSE_PARENTHESIS grouping        { $$ = mustNewVectorAggregationExpr($5, $1, $7, &$3) }
     | vectorOp grouping OPEN_PARENTHESIS NUMBER COMMA metricExpr CLOSE_PARENTHESIS        { $$ = mustNewVectorAggregationExpr($6, $1, $2, &$4) }
+    // count_values takes the name of the label holding the counted values.
+    | COUNT_VALUES OPEN_PARENTHESIS STRING COMMA metricExpr CLOSE_PARENTHESIS               { $$ = mustNewCountValuesExpr($5, $3, nil) }
+    | COUNT_VALUES OPEN_PARENTHESIS STRING COMMA metricExpr CLOSE_PARENTHESIS grouping      { $$ = mustNewCountValuesExpr($5, $3, $7) }
+    | COUNT_VALUES grouping OPEN_PARENTHESIS STRING COMMA metricExpr CLOSE_PARENTHESIS      { $$ = mustNewCountValuesExpr($6, $4, $2) }
     ;
 
 labelReplaceExpr:
@@ -189,6 +218,14 @@ labelReplaceExpr:
       { $$ = mustNewLabelReplaceExpr($3, $5, $7, $9, $11)}
     ;
 
+functionExpr:
+      functionOp OPEN_PARENTHESIS CLOSE_PARENTHESIS                                   { $$ = newFunctionExpr($1, nil, nil) }
+    | functionOp OPEN_PARENTHESIS metricExpr CLOSE_PARENTHESIS                        { $$ = newFunctionExpr($1, $3, nil) }
+    | functionOp OPEN_PARENTHESIS metricExpr COMMA literalExpr CLOSE_PARENTHESIS      { $$ = newFunctionExpr($1, $3, $5) }
+    // The quantile of histogram_quantile is its first argument, like in PromQL.
+    | HISTOGRAM_QUANTILE OPEN_PARENTHESIS literalExpr COMMA metricExpr CLOSE_PARENTHESIS  { $$ = newFunctionExpr(OpFuncHistogramQuantile, $5, $3) }
+    ;
+
 selector:
       OPEN_BRACE matchers CLOSE_BRACE  { $$ = $2 }
     | OPEN_BRACE matchers error        { $$ = $2 }
@@ -218,12 +255,20 @@ pipelineStage:
   | PIPE labelParser             { $$ = $2 }
   | PIPE jsonExpressionParser    { $$ = $2 }
   | PIPE logfmtExpressionParser  { $$ = $2 }
+  | PIPE csvParser               { $$ = $2 }
+  | PIPE xmlExpressionParser     { $$ = $2 }
   | PIPE labelFilter             { $$ = &LabelFilterExpr{LabelFilterer: $2 }}
   | PIPE lineFormatExpr          { $$ = $2 }
   | PIPE decolorizeExpr          { $$ = $2 }
   | PIPE labelFormatExpr         { $$ = $2 }
   | PIPE dropLabelsExpr          { $$ = $2 }
   | PIPE keepLabelsExpr          { $$ = $2 }
+  | PIPE statsExpr               { $$ = $2 }
+  | PIPE joinExpr                { $$ = $2 }
+  | PIPE geoIPExpr               { $$ = $2 }
+  | PIPE samplingExpr            { $$ = $2 }
+  | PIPE dedupExpr               { $$ = $2 }
+  | PIPE macroExpr               { $$ = $2 }
   ;
 
 filter:
@@ -271,6 +316,7 @@ labelParser:
   | REGEXP STRING       { $$ = newLabelParserExpr(OpParserTypeRegexp, $2) }
   | UNPACK              { $$ = newLabelParserExpr(OpParserTypeUnpack, "") }
   | PATTERN STRING      { $$ = newLabelParserExpr(OpParserTypePattern, $2) }
+  | XML                 { $$ = newLabelParserExpr(OpParserTypeXML, "") }
   ;
 
 jsonExpressionParser:
@@ -281,10 +327,49 @@ logfmtExpressionParser:
   | LOGFMT labelExtractionExpressionList              { $$ = newLogfmtExpressionParser($2, nil)}
   ;
 
+xmlExpressionParser:
+    XML labelExtractionExpressionList { $$ = newXMLExpressionParser($2) }
+  ;
+
+csvParser:
+    CSV                   { $$ = newCSVParserExpr("", nil) }
+  | CSV STRING            { $$ = newCSVParserExpr($2, nil) }
+  | CSV csvOptions        { $$ = newCSVParserExpr("", $2) }
+  | CSV STRING csvOptions { $$ = newCSVParserExpr($2, $3) }
+  ;
+
+// csvOptions are pairs of option name and value.
+csvOptions:
+    IDENTIFIER EQ STRING                  { $$ = []string{ $1, $3 } }
+  | csvOptions COMMA IDENTIFIER EQ STRING { $$ = append($1, $3, $5) }
+  ;
+
 lineFormatExpr: LINE_FMT STRING { $$ = newLineFmtExpr($2) };
 
 decolorizeExpr: DECOLORIZE { $$ = newDecolorizeExpr() };
 
+geoIPExpr: GEOIP IDENTIFIER { $$ = newGeoIPExpr($2) };
+
+samplingExpr:
+      SAMPLE NUMBER                                                              { $$ = newSamplingExpr($2, nil, nil) }
+    | SAMPLE NUMBER parserFlags                                                  { $$ = newSamplingExpr($2, nil, $3) }
+    | SAMPLE NUMBER BY labels                                                    { $$ = newSamplingExpr($2, $4, nil) }
+    | SAMPLE NUMBER BY labels parserFlags                                        { $$ = newSamplingExpr($2, $4, $5) }
+    | SAMPLE NUMBER BY OPEN_PARENTHESIS labels CLOSE_PARENTHESIS                 { $$ = newSamplingExpr($2, $5, nil) }
+    | SAMPLE NUMBER BY OPEN_PARENTHESIS labels CLOSE_PARENTHESIS parserFlags     { $$ = newSamplingExpr($2, $5, $7) }
+    ;
+
+dedupExpr:
+      DEDUP                                                                      { $$ = newDedupExpr(0, nil) }
+    | DEDUP DURATION                                                             { $$ = newDedupExpr($2, nil) }
+    | DEDUP BY labels                                                            { $$ = newDedupExpr(0, $3) }
+    | DEDUP BY OPEN_PARENTHESIS labels CLOSE_PARENTHESIS                         { $$ = newDedupExpr(0, $4) }
+    | DEDUP DURATION BY labels                                                   { $$ = newDedupExpr($2, $4) }
+    | DEDUP DURATION BY OPEN_PARENTHESIS labels CLOSE_PARENTHESIS                { $$ = newDedupExpr($2, $5) }
+    ;
+
+macroExpr: MACRO { $$ = newMacroExpr($1) };
+
 labelFormat:
      IDENTIFIER EQ IDENTIFIER { $$ = log.NewRenameLabelFmt($1, $3)}
   |  IDENTIFIER EQ STRING     { $$ = log.NewTemplateLabelFmt($1, $3)}
@@ -372,6 +457,33 @@ dropLabelsExpr: DROP namedMatchers { $$ = newDropLabelsExpr($2) }
 
 keepLabelsExpr: KEEP namedMatchers { $$ = newKeepLabelsExpr($2) }
 
+statsExpr:
+      STATS statsAggregations                                                 { $$ = newStatsExpr($2, nil) }
+    | STATS statsAggregations BY labels                                       { $$ = newStatsExpr($2, $4) }
+    | STATS statsAggregations BY OPEN_PARENTHESIS labels CLOSE_PARENTHESIS    { $$ = newStatsExpr($2, $5) }
+    ;
+
+joinExpr:
+      JOIN ON OPEN_PARENTHESIS labels CLOSE_PARENTHESIS RANGE OPEN_PARENTHESIS logExpr CLOSE_PARENTHESIS    { $$ = newJoinExpr($4, $6, $8) }
+    ;
+
+statsAggregations:
+      statsAggregation                               { $$ = []StatsAggregation{ $1 } }
+    | statsAggregations COMMA statsAggregation       { $$ = append($1, $3) }
+    ;
+
+statsAggregation:
+      COUNT OPEN_PARENTHESIS CLOSE_PARENTHESIS                 { $$ = StatsAggregation{ Operation: OpTypeCount } }
+    | statsOp OPEN_PARENTHESIS IDENTIFIER CLOSE_PARENTHESIS    { $$ = StatsAggregation{ Operation: $1, Label: $3 } }
+    ;
+
+statsOp:
+      SUM     { $$ = OpTypeSum }
+    | AVG     { $$ = OpTypeAvg }
+    | MIN     { $$ = OpTypeMin }
+    | MAX     { $$ = OpTypeMax }
+    ;
+
 // Operator precedence only works if each of these is listed separately.
 binOpExpr:
          expr OR binOpModifier expr          { $$ = mustNewBinOpExpr("or", $3, $1, $4) }
@@ -502,9 +614,27 @@ rangeOp:
     | STDVAR_OVER_TIME   { $$ = OpRangeTypeStdvar }
     | STDDEV_OVER_TIME   { $$ = OpRangeTypeStddev }
     | QUANTILE_OVER_TIME { $$ = OpRangeTypeQuantile }
+    | HISTOGRAM_OVER_TIME { $$ = OpRangeTypeHistogram }
     | FIRST_OVER_TIME    { $$ = OpRangeTypeFirst }
     | LAST_OVER_TIME     { $$ = OpRangeTypeLast }
     | ABSENT_OVER_TIME   { $$ = OpRangeTypeAbsent }
+    | DERIV              { $$ = OpRangeTypeDeriv }
+    ;
+
+functionOp:
+      ABS          { $$ = OpFuncAbs }
+    | CEIL         { $$ = OpFuncCeil }
+    | FLOOR        { $$ = OpFuncFloor }
+    | ROUND        { $$ = OpFuncRound }
+    | LN           { $$ = OpFuncLn }
+    | EXP          { $$ = OpFuncExp }
+    | CLAMP_MIN    { $$ = OpFuncClampMin }
+    | CLAMP_MAX    { $$ = OpFuncClampMax }
+    | TIME         { $$ = OpFuncTime }
+    | TIMESTAMP    { $$ = OpFuncTimestamp }
+    | DAY_OF_WEEK  { $$ = OpFuncDayOfWeek }
+    | HOUR         { $$ = OpFuncHour }
+    | ABSENT       { $$ = OpFuncAbsent }
     ;
 
 offsetExpr:
@@ -10,14 +10,15 @@ import (
 )
 
 type syntaxSymType struct {
-	yys   int
-	val   interface{}
-	bytes uint64
-	dur   time.Duration
-	op    string
-	binOp string
-	str   string
-	strs  []string
+	yys           int
+	val           interface{}
+	bytes         uint64
+	dur           time.Duration
+	subqueryRange subqueryRange
+	op            string
+	binOp         string
+	str           string
+	strs          []string
 
 	expr         Expr
 	logExpr      LogSelectorExpr
@@ -45,6 +46,9 @@ type syntaxSymType struct {
 	labelExtractionExpressionList []log.LabelExtractionExpr
 	unwrapExpr                    *UnwrapExpr
 	offsetExpr                    *OffsetExpr
+	subqueryExpr                  *SubqueryExpr
+	statsAggregation              StatsAggregation
+	statsAggregations             []StatsAggregation
 }
 
 const BYTES = 57346
@@ -52,95 +56,122 @@ const IDENTIFIER = 57347
 const STRING = 57348
 const NUMBER = 57349
 const FUNCTION_FLAG = 57350
-const DURATION = 57351
-const RANGE = 57352
-const MATCHERS = 57353
-const LABELS = 57354
-const EQ = 57355
-const RE = 57356
-const NRE = 57357
-const NPA = 57358
-const OPEN_BRACE = 57359
-const CLOSE_BRACE = 57360
-const OPEN_BRACKET = 57361
-const CLOSE_BRACKET = 57362
-const COMMA = 57363
-const DOT = 57364
-const PIPE_MATCH = 57365
-const PIPE_EXACT = 57366
-const PIPE_PATTERN = 57367
-const OPEN_PARENTHESIS = 57368
-const CLOSE_PARENTHESIS = 57369
-const BY = 57370
-const WITHOUT = 57371
-const COUNT_OVER_TIME = 57372
-const RATE = 57373
-const RATE_COUNTER = 57374
-const SUM = 57375
-const SORT = 57376
-const SORT_DESC = 57377
-const AVG = 57378
-const MAX = 57379
-const MIN = 57380
-const COUNT = 57381
-const STDDEV = 57382
-const STDVAR = 57383
-const BOTTOMK = 57384
-const TOPK = 57385
-const APPROX_TOPK = 57386
-const BYTES_OVER_TIME = 57387
-const BYTES_RATE = 57388
-const BOOL = 57389
-const JSON = 57390
-const REGEXP = 57391
-const LOGFMT = 57392
-const PIPE = 57393
-const LINE_FMT = 57394
-const LABEL_FMT = 57395
-const UNWRAP = 57396
-const AVG_OVER_TIME = 57397
-const SUM_OVER_TIME = 57398
-const MIN_OVER_TIME = 57399
-const MAX_OVER_TIME = 57400
-const STDVAR_OVER_TIME = 57401
-const STDDEV_OVER_TIME = 57402
-const QUANTILE_OVER_TIME = 57403
-const BYTES_CONV = 57404
-const DURATION_CONV = 57405
-const DURATION_SECONDS_CONV = 57406
-const FIRST_OVER_TIME = 57407
-const LAST_OVER_TIME = 57408
-const ABSENT_OVER_TIME = 57409
-const VECTOR = 57410
-const LABEL_REPLACE = 57411
-const UNPACK = 57412
-const OFFSET = 57413
-const PATTERN = 57414
-const IP = 57415
-const ON = 57416
-const IGNORING = 57417
-const GROUP_LEFT = 57418
-const GROUP_RIGHT = 57419
-const DECOLORIZE = 57420
-const DROP = 57421
-const KEEP = 57422
-const VARIANTS = 57423
-const OF = 57424
-const OR = 57425
-const AND = 57426
-const UNLESS = 57427
-const CMP_EQ = 57428
-const NEQ = 57429
-const LT = 57430
-const LTE = 57431
-const GT = 57432
-const GTE = 57433
-const ADD = 57434
-const SUB = 57435
-const MUL = 57436
-const DIV = 57437
-const MOD = 57438
-const POW = 57439
+const MACRO = 57351
+const DURATION = 57352
+const RANGE = 57353
+const SUBQUERY_RANGE = 57354
+const MATCHERS = 57355
+const LABELS = 57356
+const EQ = 57357
+const RE = 57358
+const NRE = 57359
+const NPA = 57360
+const OPEN_BRACE = 57361
+const CLOSE_BRACE = 57362
+const OPEN_BRACKET = 57363
+const CLOSE_BRACKET = 57364
+const COMMA = 57365
+const DOT = 57366
+const PIPE_MATCH = 57367
+const PIPE_EXACT = 57368
+const PIPE_PATTERN = 57369
+const OPEN_PARENTHESIS = 57370
+const CLOSE_PARENTHESIS = 57371
+const BY = 57372
+const WITHOUT = 57373
+const COUNT_OVER_TIME = 57374
+const RATE = 57375
+const RATE_COUNTER = 57376
+const SUM = 57377
+const SORT = 57378
+const SORT_DESC = 57379
+const AVG = 57380
+const MAX = 57381
+const MIN = 57382
+const COUNT = 57383
+const STDDEV = 57384
+const STDVAR = 57385
+const BOTTOMK = 57386
+const TOPK = 57387
+const APPROX_TOPK = 57388
+const BYTES_OVER_TIME = 57389
+const BYTES_RATE = 57390
+const BOOL = 57391
+const JSON = 57392
+const REGEXP = 57393
+const LOGFMT = 57394
+const PIPE = 57395
+const LINE_FMT = 57396
+const LABEL_FMT = 57397
+const UNWRAP = 57398
+const AVG_OVER_TIME = 57399
+const SUM_OVER_TIME = 57400
+const MIN_OVER_TIME = 57401
+const MAX_OVER_TIME = 57402
+const STDVAR_OVER_TIME = 57403
+const STDDEV_OVER_TIME = 57404
+const QUANTILE_OVER_TIME = 57405
+const BYTES_CONV = 57406
+const DURATION_CONV = 57407
+const DURATION_SECONDS_CONV = 57408
+const FIRST_OVER_TIME = 57409
+const LAST_OVER_TIME = 57410
+const ABSENT_OVER_TIME = 57411
+const VECTOR = 57412
+const LABEL_REPLACE = 57413
+const UNPACK = 57414
+const OFFSET = 57415
+const PATTERN = 57416
+const IP = 57417
+const ON = 57418
+const IGNORING = 57419
+const GROUP_LEFT = 57420
+const GROUP_RIGHT = 57421
+const DECOLORIZE = 57422
+const DROP = 57423
+const KEEP = 57424
+const VARIANTS = 57425
+const OF = 57426
+const DERIV = 57427
+const PREDICT_LINEAR = 57428
+const COUNT_VALUES = 57429
+const ABS = 57430
+const CEIL = 57431
+const FLOOR = 57432
+const ROUND = 57433
+const LN = 57434
+const EXP = 57435
+const CLAMP_MIN = 57436
+const CLAMP_MAX = 57437
+const TIME = 57438
+const TIMESTAMP = 57439
+const DAY_OF_WEEK = 57440
+const HOUR = 57441
+const ABSENT = 57442
+const HISTOGRAM_QUANTILE = 57443
+const HISTOGRAM_OVER_TIME = 57444
+const CSV = 57445
+const XML = 57446
+const STATS = 57447
+const JOIN = 57448
+const GEOIP = 57449
+const SAMPLE = 57450
+const DEDUP = 57451
+const OR = 57452
+const AND = 57453
+const UNLESS = 57454
+const CMP_EQ = 57455
+const NEQ = 57456
+const LT = 57457
+const LTE = 57458
+const GT = 57459
+const GTE = 57460
+const ADD = 57461
+const SUB = 57462
+const MUL = 57463
+const DIV = 57464
+const MOD = 57465
+const POW = 57466
 
 var syntaxToknames = [...]string{
 	"$end",
@@ -151,8 +182,10 @@ var syntaxToknames = [...]string{
 	"STRING",
 	"NUMBER",
 	"FUNCTION_FLAG",
+	"MACRO",
 	"DURATION",
 	"RANGE",
+	"SUBQUERY_RANGE",
 	"MATCHERS",
 	"LABELS",
 	"EQ",
@@ -225,6 +258,31 @@ var syntaxToknames = [...]string{
 	"KEEP",
 	"VARIANTS",
 	"OF",
+	"DERIV",
+	"PREDICT_LINEAR",
+	"COUNT_VALUES",
+	"ABS",
+	"CEIL",
+	"FLOOR",
+	"ROUND",
+	"LN",
+	"EXP",
+	"CLAMP_MIN",
+	"CLAMP_MAX",
+	"TIME",
+	"TIMESTAMP",
+	"DAY_OF_WEEK",
+	"HOUR",
+	"ABSENT",
+	"HISTOGRAM_QUANTILE",
+	"HISTOGRAM_OVER_TIME",
+	"CSV",
+	"XML",
+	"STATS",
+	"JOIN",
+	"GEOIP",
+	"SAMPLE",
+	"DEDUP",
 	"OR",
 	"AND",
 	"UNLESS",
@@ -241,294 +299,408 @@ var syntaxToknames = [...]string{
 	"MOD",
 	"POW",
 }
+
 var syntaxStatenames = [...]string{}
 
 const syntaxEofCode = 1
 const syntaxErrCode = 2
 const syntaxInitialStackSize = 16
 
-var syntaxExca = [...]int{
+var syntaxExca = [...]int16{
 	-1, 1,
 	1, -1,
 	-2, 0,
-	-1, 150,
-	21, 227,
-	27, 227,
+	-1, 192,
+	23, 300,
+	29, 300,
 	-2, 3,
-	-1, 290,
-	21, 228,
-	27, 228,
+	-1, 380,
+	23, 301,
+	29, 301,
 	-2, 3,
 }
 
 const syntaxPrivate = 57344
 
-const syntaxLast = 642
-
-var syntaxAct = [...]int{
-
-	293, 232, 88, 4, 217, 67, 130, 6, 188, 206,
-	158, 79, 203, 195, 66, 241, 205, 193, 80, 2,
-	59, 286, 84, 51, 52, 53, 60, 61, 64, 65,
-	62, 63, 54, 55, 56, 57, 58, 59, 143, 218,
-	11, 52, 53, 60, 61, 64, 65, 62, 63, 54,
-	55, 56, 57, 58, 59, 60, 61, 64, 65, 62,
-	63, 54, 55, 56, 57, 58, 59, 56, 57, 58,
-	59, 289, 113, 144, 210, 156, 157, 296, 119, 54,
-	55, 56, 57, 58, 59, 150, 301, 154, 156, 157,
-	269, 162, 225, 18, 160, 268, 265, 167, 224, 18,
-	284, 264, 219, 18, 281, 283, 146, 18, 278, 280,
-	98, 18, 70, 277, 169, 172, 173, 298, 174, 175,
-	176, 177, 178, 179, 180, 181, 182, 183, 184, 185,
-	186, 187, 275, 170, 171, 18, 272, 274, 371, 18,
-	146, 271, 371, 200, 197, 208, 208, 216, 211, 214,
-	215, 212, 213, 343, 297, 140, 209, 392, 296, 267,
-	223, 155, 228, 343, 387, 263, 297, 310, 239, 235,
-	140, 190, 236, 360, 145, 233, 134, 228, 19, 20,
-	374, 114, 244, 350, 19, 20, 190, 380, 19, 20,
-	379, 134, 19, 20, 298, 298, 19, 20, 252, 253,
-	254, 344, 335, 299, 298, 75, 77, 298, 75, 77,
-	256, 89, 90, 72, 73, 74, 72, 73, 74, 378,
-	19, 20, 228, 243, 19, 20, 290, 87, 368, 89,
-	90, 376, 291, 294, 189, 300, 228, 303, 160, 113,
-	306, 292, 307, 119, 234, 320, 295, 305, 191, 189,
-	304, 266, 270, 273, 276, 279, 282, 285, 346, 347,
-	348, 229, 310, 363, 314, 316, 319, 321, 359, 208,
-	299, 322, 328, 324, 353, 75, 77, 76, 140, 333,
-	76, 243, 308, 72, 73, 74, 310, 351, 140, 247,
-	310, 331, 358, 243, 190, 336, 357, 338, 340, 134,
-	342, 113, 243, 318, 140, 243, 352, 341, 337, 134,
-	113, 234, 310, 354, 237, 317, 310, 222, 312, 243,
-	190, 148, 311, 221, 315, 134, 259, 245, 147, 334,
-	159, 126, 127, 125, 330, 135, 137, 301, 365, 366,
-	15, 242, 160, 113, 367, 364, 140, 76, 15, 161,
-	369, 370, 329, 128, 287, 129, 375, 161, 251, 250,
-	249, 136, 138, 139, 231, 18, 248, 134, 220, 75,
-	77, 382, 166, 383, 384, 15, 165, 72, 73, 74,
-	261, 302, 191, 189, 7, 164, 388, 94, 23, 24,
-	25, 38, 47, 48, 39, 41, 42, 40, 43, 44,
-	45, 46, 49, 26, 27, 234, 93, 86, 81, 390,
-	386, 356, 152, 28, 29, 30, 31, 32, 33, 34,
-	257, 309, 262, 35, 36, 37, 50, 21, 151, 231,
-	240, 153, 260, 246, 75, 77, 238, 75, 77, 14,
-	15, 76, 72, 73, 74, 72, 73, 74, 230, 7,
-	19, 20, 258, 23, 24, 25, 38, 47, 48, 39,
-	41, 42, 40, 43, 44, 45, 46, 49, 26, 27,
-	234, 385, 85, 234, 373, 372, 349, 339, 28, 29,
-	30, 31, 32, 33, 34, 83, 326, 327, 35, 36,
-	37, 50, 21, 296, 196, 163, 196, 255, 391, 194,
-	3, 168, 75, 77, 14, 15, 76, 92, 78, 76,
-	72, 73, 74, 91, 7, 19, 20, 389, 23, 24,
-	25, 38, 47, 48, 39, 41, 42, 40, 43, 44,
-	45, 46, 49, 26, 27, 377, 362, 361, 234, 332,
-	323, 149, 313, 28, 29, 30, 31, 32, 33, 34,
-	75, 77, 140, 35, 36, 37, 50, 21, 72, 73,
-	74, 325, 288, 227, 204, 202, 226, 225, 224, 14,
-	201, 199, 198, 134, 76, 95, 381, 355, 207, 196,
-	19, 20, 85, 204, 97, 96, 69, 192, 22, 82,
-	71, 131, 132, 141, 133, 126, 127, 125, 142, 135,
-	137, 17, 345, 16, 68, 124, 123, 122, 121, 120,
-	118, 117, 116, 115, 5, 13, 12, 128, 10, 129,
-	9, 8, 76, 1, 0, 136, 138, 139, 99, 100,
-	101, 102, 103, 104, 105, 106, 107, 108, 109, 110,
-	111, 112,
+const syntaxLast = 1186
+
+var syntaxAct = [...]int16{
+	302, 3, 88, 243, 313, 305, 87, 386, 110, 99,
+	6, 285, 239, 166, 266, 261, 246, 253, 260, 258,
+	202, 244, 80, 22, 4, 101, 2, 196, 198, 199,
+	114, 105, 100, 72, 73, 74, 81, 82, 85, 86,
+	83, 84, 75, 76, 77, 78, 79, 80, 75, 76,
+	77, 78, 79, 80, 376, 185, 379, 11, 73, 74,
+	81, 82, 85, 86, 83, 84, 75, 76, 77, 78,
+	79, 80, 81, 82, 85, 86, 83, 84, 75, 76,
+	77, 78, 79, 80, 77, 78, 79, 80, 374, 91,
+	139, 22, 287, 182, 373, 273, 359, 286, 293, 22,
+	96, 98, 358, 147, 223, 224, 221, 222, 93, 94,
+	95, 241, 186, 200, 206, 389, 170, 371, 203, 203,
+	22, 501, 355, 370, 292, 22, 197, 192, 354, 207,
+	392, 391, 205, 205, 208, 23, 24, 350, 449, 501,
+	537, 389, 215, 368, 218, 390, 22, 220, 124, 367,
+	276, 225, 226, 227, 228, 229, 230, 231, 232, 233,
+	234, 235, 236, 237, 238, 365, 188, 357, 22, 362,
+	277, 364, 22, 182, 189, 361, 534, 181, 219, 140,
+	391, 188, 248, 255, 456, 187, 251, 391, 263, 263,
+	278, 198, 199, 353, 264, 344, 170, 97, 242, 240,
+	111, 112, 343, 23, 24, 245, 99, 182, 525, 291,
+	300, 23, 24, 528, 296, 304, 182, 316, 160, 161,
+	159, 515, 171, 173, 392, 241, 496, 349, 514, 100,
+	170, 339, 23, 24, 241, 311, 507, 23, 24, 170,
+	162, 516, 163, 458, 459, 460, 404, 505, 172, 174,
+	175, 533, 513, 329, 330, 331, 296, 269, 23, 24,
+	270, 272, 271, 267, 113, 333, 111, 112, 449, 489,
+	336, 165, 164, 176, 177, 178, 179, 180, 390, 348,
+	23, 24, 351, 446, 23, 24, 498, 481, 284, 279,
+	282, 283, 280, 281, 474, 473, 464, 109, 296, 111,
+	112, 385, 387, 139, 382, 395, 381, 470, 388, 203,
+	391, 393, 242, 240, 397, 380, 147, 467, 400, 383,
+	391, 404, 240, 205, 466, 396, 465, 512, 404, 447,
+	412, 414, 417, 419, 510, 401, 356, 360, 363, 366,
+	369, 372, 375, 407, 404, 444, 432, 404, 429, 411,
+	486, 420, 434, 482, 435, 437, 263, 439, 428, 431,
+	424, 404, 404, 402, 384, 17, 404, 478, 477, 384,
+	96, 98, 476, 315, 532, 96, 98, 321, 93, 94,
+	95, 410, 461, 93, 94, 95, 309, 450, 442, 452,
+	182, 139, 448, 451, 462, 404, 139, 418, 454, 315,
+	315, 475, 315, 301, 455, 315, 303, 296, 241, 96,
+	98, 303, 315, 170, 404, 299, 404, 93, 94, 95,
+	406, 394, 405, 416, 415, 323, 413, 182, 468, 317,
+	315, 322, 290, 471, 297, 480, 314, 17, 289, 483,
+	315, 484, 315, 485, 190, 303, 492, 445, 441, 440,
+	170, 490, 495, 438, 139, 493, 491, 494, 377, 347,
+	96, 98, 346, 436, 500, 430, 345, 97, 93, 94,
+	95, 499, 97, 328, 503, 301, 504, 327, 315, 506,
+	326, 96, 98, 325, 288, 96, 98, 214, 212, 93,
+	94, 95, 479, 93, 94, 95, 303, 211, 517, 210,
+	522, 352, 120, 518, 119, 520, 97, 118, 117, 108,
+	523, 22, 107, 102, 524, 404, 526, 303, 385, 395,
+	139, 90, 472, 17, 337, 527, 334, 531, 462, 529,
+	139, 408, 7, 217, 535, 194, 29, 30, 31, 46,
+	55, 56, 47, 49, 50, 48, 51, 52, 53, 54,
+	57, 32, 33, 193, 245, 296, 195, 97, 403, 342,
+	340, 34, 35, 36, 37, 38, 39, 40, 324, 404,
+	320, 42, 43, 44, 58, 25, 307, 318, 97, 310,
+	308, 298, 97, 341, 338, 335, 306, 16, 521, 45,
+	19, 21, 59, 60, 61, 62, 63, 64, 65, 66,
+	67, 68, 69, 70, 71, 28, 41, 22, 511, 502,
+	106, 332, 497, 463, 453, 247, 245, 247, 332, 17,
+	245, 426, 427, 23, 24, 104, 399, 398, 7, 275,
+	254, 252, 29, 30, 31, 46, 55, 56, 47, 49,
+	50, 48, 51, 52, 53, 54, 57, 32, 33, 216,
+	116, 115, 536, 530, 509, 508, 488, 34, 35, 36,
+	37, 38, 39, 40, 487, 443, 423, 42, 43, 44,
+	58, 25, 425, 421, 409, 259, 265, 378, 319, 295,
+	294, 293, 292, 16, 256, 45, 19, 21, 59, 60,
+	61, 62, 63, 64, 65, 66, 67, 68, 69, 70,
+	71, 28, 41, 22, 250, 249, 213, 519, 315, 469,
+	433, 262, 422, 247, 254, 17, 106, 274, 259, 23,
+	24, 191, 257, 123, 204, 122, 26, 103, 29, 30,
+	31, 46, 55, 56, 47, 49, 50, 48, 51, 52,
+	53, 54, 57, 32, 33, 92, 167, 168, 183, 169,
+	184, 268, 27, 34, 35, 36, 37, 38, 39, 40,
+	20, 457, 18, 42, 43, 44, 58, 25, 89, 158,
+	157, 156, 155, 154, 153, 152, 151, 150, 149, 16,
+	148, 45, 19, 21, 59, 60, 61, 62, 63, 64,
+	65, 66, 67, 68, 69, 70, 71, 28, 41, 312,
+	146, 145, 144, 143, 142, 141, 5, 15, 14, 13,
+	12, 17, 10, 9, 8, 23, 24, 1, 0, 0,
+	7, 0, 0, 0, 29, 30, 31, 46, 55, 56,
+	47, 49, 50, 48, 51, 52, 53, 54, 57, 32,
+	33, 0, 0, 0, 0, 0, 0, 0, 0, 34,
+	35, 36, 37, 38, 39, 40, 0, 0, 0, 42,
+	43, 44, 58, 25, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 16, 0, 45, 19, 21,
+	59, 60, 61, 62, 63, 64, 65, 66, 67, 68,
+	69, 70, 71, 28, 41, 209, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 17, 0, 0,
+	0, 23, 24, 0, 0, 0, 7, 0, 0, 0,
+	29, 30, 31, 46, 55, 56, 47, 49, 50, 48,
+	51, 52, 53, 54, 57, 32, 33, 0, 0, 0,
+	0, 0, 0, 0, 0, 34, 35, 36, 37, 38,
+	39, 40, 0, 0, 0, 42, 43, 44, 58, 25,
+	0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+	0, 16, 0, 45, 19, 21, 59, 60, 61, 62,
+	63, 64, 65, 66, 67, 68, 69, 70, 71, 28,
+	41, 201, 0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 17, 0, 0, 0, 23, 24, 0,
+	0, 0, 204, 0, 0, 0, 29, 30, 31, 46,
+	55, 56, 47, 49, 50, 48, 51, 52, 53, 54,
+	57, 32, 33, 0, 0, 0, 0, 0, 0, 0,
+	0, 34, 35, 36, 37, 38, 39, 40, 0, 0,
+	0, 42, 43, 44, 58, 25, 0, 0, 0, 0,
+	0, 0, 0, 0, 182, 0, 0, 16, 181, 45,
+	19, 21, 59, 60, 61, 62, 63, 64, 65, 66,
+	67, 68, 69, 70, 71, 28, 41, 170, 96, 98,
+	0, 0, 0, 0, 0, 121, 93, 94, 95, 0,
+	0, 0, 0, 23, 24, 0, 0, 0, 0, 160,
+	161, 159, 0, 171, 173, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 303, 0, 0, 0, 0, 0,
+	0, 162, 0, 163, 0, 0, 0, 0, 0, 172,
+	174, 175, 0, 0, 389, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 165, 164, 176, 177, 178, 179, 180, 125,
+	126, 127, 128, 129, 130, 131, 132, 133, 134, 135,
+	136, 137, 138, 0, 0, 97,
 }
-var syntaxPact = [...]int{
-
-	358, -1000, -60, -1000, -1000, -1000, 535, 358, -1000, -1000,
-	-1000, -1000, -1000, -1000, 382, 467, 381, 201, -1000, 506,
-	500, 380, 361, -1000, -1000, -1000, -1000, -1000, -1000, -1000,
-	-1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000,
-	-1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000,
-	-1000, 63, 63, 63, 63, 63, 63, 63, 63, 63,
-	63, 63, 63, 63, 63, 63, 535, -1000, 190, 547,
-	-45, 67, -1000, -1000, -1000, -1000, -1000, -1000, 301, 294,
-	-60, 358, 410, -1000, -1000, 74, 323, 488, 359, 350,
-	346, -1000, -1000, 358, 494, 358, 59, 39, -1000, 358,
-	358, 358, 358, 358, 358, 358, 358, 358, 358, 358,
-	358, 358, 358, -1000, -45, -1000, -1000, -1000, -1000, 165,
-	-1000, -1000, -1000, -1000, -1000, 491, 574, 566, -1000, 565,
-	-1000, -1000, -1000, -1000, 341, 564, -1000, 578, 573, 573,
-	61, -1000, -1000, 33, -1000, 342, -1000, -1000, -1000, 296,
-	-1000, -1000, -1000, 577, 562, 561, 560, 557, 234, 427,
-	419, 331, 287, 415, 423, 314, 300, 412, 262, -43,
-	340, 334, 333, 332, -31, -31, -27, -27, -77, -77,
-	-77, -77, -13, -13, -13, -13, -13, -13, 165, 341,
-	341, 341, 489, 399, -1000, -1000, 439, 399, -1000, -1000,
-	299, -1000, 411, -1000, 367, 401, -1000, 74, -1000, 401,
-	92, 86, 132, 128, 104, 100, 96, -1000, -62, 328,
-	556, -11, 358, -1000, -1000, -1000, -1000, -1000, -1000, 183,
-	331, 422, 144, 193, 283, 354, 220, 183, 358, 255,
-	400, 295, -1000, -1000, 291, -1000, 536, -1000, 297, 288,
-	276, 218, 273, 165, 150, -1000, 399, 574, 534, -1000,
-	559, 481, 573, 326, -1000, -1000, -1000, 308, -1000, -1000,
-	-1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000,
-	-1000, -1000, -1000, -1000, -1000, -1000, 33, 533, 252, 303,
-	-1000, -1000, 175, 487, 66, 487, 468, 6, 341, 6,
-	143, 196, 466, 156, 260, -1000, -1000, 247, -1000, 358,
-	572, -1000, -1000, 390, 269, -1000, 265, -1000, -1000, 241,
-	-1000, 146, -1000, -1000, -1000, -1000, -1000, -1000, -1000, 531,
-	530, -1000, 236, -1000, 331, 183, 66, 487, 66, -1000,
-	-1000, 165, -1000, 6, -1000, 202, -1000, -1000, -1000, 87,
-	465, 464, 153, 183, 204, -1000, 529, -1000, -1000, -1000,
-	-1000, 192, 163, -1000, 160, -1000, 66, -1000, 571, 91,
-	66, 32, 6, 6, 461, -1000, -1000, 389, -1000, -1000,
-	-1000, 137, 66, -1000, -1000, 6, 511, -1000, -1000, 388,
-	492, 130, -1000,
+
+var syntaxPact = [...]int16{
+	600, -32768, -77, -32768, -32768, -32768, 468, 600, -32768, -32768,
+	-32768, -32768, -32768, -32768, -32768, -32768, 485, 605, 484, 481,
+	269, 236, -32768, 644, 643, 480, 479, 476, 474, -32768,
+	-32768, -32768, -32768, -32768, -32768, -32768, -32768, -32768, -32768, -32768,
+	-32768, -32768, -32768, -32768, -32768, -32768, -32768, -32768, -32768, -32768,
+	-32768, -32768, -32768, -32768, -32768, -32768, -32768, -32768, -32768, -32768,
+	-32768, -32768, -32768, -32768, -32768, -32768, -32768, -32768, -32768, -32768,
+	-32768, -32768, 99, 99, 99, 99, 99, 99, 99, 99,
+	99, 99, 99, 99, 99, 99, 99, 468, -32768, 83,
+	1059, -55, 106, -32768, -32768, -32768, -32768, -32768, -32768, 145,
+	415, -77, 600, 533, -32768, -32768, 12, 984, 696, 888,
+	471, 469, 460, 700, 459, -32768, -32768, 600, 642, 504,
+	16, 600, 30, 26, -32768, 600, 600, 600, 600, 600,
+	600, 600, 600, 600, 600, 600, 600, 600, 600, -32768,
+	-55, -32768, -32768, -32768, -32768, -32768, -32768, 88, -32768, -32768,
+	-32768, -32768, -32768, -32768, -32768, -32768, -32768, -32768, -32768, 612,
+	708, 699, -32768, 698, 708, 625, -32768, -32768, -32768, -32768,
+	422, 678, -32768, 713, 706, 706, 222, 19, 712, 622,
+	140, -32768, 175, -32768, -32768, 91, -32768, 456, -32768, -32768,
+	-32768, 409, -32768, -32768, -32768, 711, 676, 675, 674, 673,
+	405, 558, 386, 464, 696, 574, 553, 557, 357, 556,
+	792, 407, 400, 554, 672, 547, 348, -32768, 402, 545,
+	-53, 455, 452, 449, 445, -41, -41, -37, -37, -102,
+	-102, -102, -102, -71, -71, -71, -71, -71, -71, 88,
+	422, 422, 422, 610, 503, -32768, -32768, 570, 503, -32768,
+	-32768, 503, 709, 501, 569, 202, -32768, 537, -32768, 568,
+	536, -32768, 12, -32768, 536, 172, -32768, 438, 434, -32768,
+	-32768, -32768, -32768, 431, -32768, 197, 107, 473, 118, 92,
+	165, 161, 139, 113, 84, -32768, -56, 430, 671, -28,
+	600, -32768, -32768, -32768, -32768, -32768, -32768, 170, 696, -32768,
+	358, 1071, 134, 168, 392, 296, 42, 620, 619, 170,
+	600, 334, 535, 393, -32768, -32768, 391, -32768, 600, 508,
+	668, -32768, -32768, 16, 600, 397, 395, 394, 368, 385,
+	88, 211, -32768, 503, 708, 667, 501, 707, 660, -32768,
+	670, 616, 706, 437, 222, 317, 705, 703, 603, 435,
+	425, 492, 703, 421, -32768, -32768, -32768, 420, -32768, -32768,
+	-32768, -32768, -32768, -32768, -32768, -32768, -32768, -32768, -32768, -32768,
+	-32768, -32768, -32768, -32768, -32768, -32768, 91, 659, 316, 419,
+	-32768, -32768, 254, 300, 42, 127, 443, 78, 443, 604,
+	42, 422, 179, 353, 602, 267, -32768, -32768, 297, 295,
+	-32768, 288, -32768, 600, 704, -32768, -32768, 278, 600, 499,
+	266, 265, 372, -32768, 343, -32768, -32768, 339, -32768, 338,
+	-32768, -32768, 477, -32768, -32768, -32768, -32768, -32768, -32768, 492,
+	703, -32768, -32768, 258, 324, 546, 703, 492, 703, 321,
+	658, 650, -32768, 240, -32768, 418, 170, -32768, -32768, 42,
+	78, 443, 78, -32768, -32768, 88, -32768, 198, -32768, -32768,
+	-32768, 601, 257, 68, 598, 170, -32768, 170, 218, -32768,
+	170, 207, 649, -32768, -32768, -32768, -32768, -32768, -32768, 648,
+	305, -32768, 597, 603, 298, 223, -32768, 199, 192, -32768,
+	212, 464, 418, -32768, -32768, 78, 702, 42, 577, 86,
+	78, 74, 42, -32768, -32768, -32768, -32768, -32768, 491, -32768,
+	-32768, 180, 608, -32768, -32768, -32768, -32768, 358, 392, 184,
+	-32768, 42, 78, -32768, 647, 346, 603, 353, -32768, -32768,
+	228, 147, 346, 646, -32768, 145, 111, -32768,
 }
-var syntaxPgo = [...]int{
-
-	0, 623, 18, 500, 3, 621, 620, 618, 616, 615,
-	614, 5, 613, 612, 611, 610, 609, 608, 607, 606,
-	605, 14, 112, 604, 4, 603, 602, 601, 102, 598,
-	594, 593, 8, 592, 591, 590, 6, 589, 7, 588,
-	15, 587, 575, 585, 584, 9, 16, 12, 565, 2,
-	10, 40, 13, 17, 1, 0, 541,
+
+var syntaxPgo = [...]int16{
+	0, 817, 25, 1, 24, 814, 813, 812, 810, 809,
+	808, 807, 806, 2, 805, 804, 803, 802, 801, 800,
+	780, 778, 777, 776, 775, 774, 773, 772, 771, 770,
+	769, 6, 89, 768, 11, 762, 761, 760, 92, 752,
+	751, 750, 749, 748, 12, 747, 746, 745, 13, 727,
+	10, 726, 4, 3, 17, 1095, 725, 723, 15, 18,
+	19, 722, 8, 5, 57, 16, 21, 0, 7, 20,
+	721, 14, 676,
 }
-var syntaxR1 = [...]int{
 
+var syntaxR1 = [...]int8{
 	0, 1, 2, 2, 2, 3, 3, 3, 4, 4,
-	4, 4, 4, 4, 4, 10, 50, 50, 50, 50,
-	50, 50, 50, 50, 50, 50, 50, 50, 50, 50,
-	50, 50, 50, 50, 50, 50, 50, 50, 50, 50,
-	50, 50, 54, 54, 54, 26, 26, 26, 5, 5,
-	5, 5, 6, 6, 6, 6, 6, 6, 8, 38,
-	38, 38, 37, 37, 36, 36, 36, 36, 21, 21,
-	11, 11, 11, 11, 11, 11, 11, 11, 11, 11,
-	11, 35, 35, 35, 35, 35, 35, 28, 24, 24,
-	24, 22, 22, 22, 23, 23, 41, 41, 12, 12,
-	13, 13, 13, 13, 14, 15, 15, 16, 17, 47,
-	47, 48, 48, 48, 18, 32, 32, 32, 32, 32,
-	32, 32, 32, 32, 52, 52, 53, 53, 34, 34,
-	33, 33, 31, 31, 31, 31, 31, 31, 31, 29,
-	29, 29, 29, 29, 29, 29, 30, 30, 30, 30,
-	30, 30, 30, 45, 45, 46, 46, 19, 20, 7,
+	4, 4, 4, 4, 4, 4, 4, 12, 63, 63,
+	63, 63, 63, 63, 63, 63, 63, 63, 63, 63,
+	63, 63, 63, 63, 63, 63, 63, 63, 63, 63,
+	63, 63, 63, 63, 67, 67, 67, 36, 36, 36,
+	5, 5, 5, 5, 5, 5, 69, 69, 10, 10,
+	10, 6, 6, 6, 6, 6, 6, 6, 6, 6,
+	8, 11, 11, 11, 11, 50, 50, 50, 49, 49,
+	48, 48, 48, 48, 31, 31, 13, 13, 13, 13,
+	13, 13, 13, 13, 13, 13, 13, 13, 13, 13,
+	13, 13, 13, 13, 13, 47, 47, 47, 47, 47,
+	47, 38, 34, 34, 34, 32, 32, 32, 33, 33,
+	53, 53, 14, 14, 15, 15, 15, 15, 15, 16,
+	17, 17, 19, 18, 18, 18, 18, 54, 54, 20,
+	21, 27, 28, 28, 28, 28, 28, 28, 29, 29,
+	29, 29, 29, 29, 30, 60, 60, 61, 61, 61,
+	22, 44, 44, 44, 44, 44, 44, 44, 44, 44,
+	65, 65, 66, 66, 46, 46, 45, 45, 43, 43,
+	43, 43, 43, 43, 43, 41, 41, 41, 41, 41,
+	41, 41, 42, 42, 42, 42, 42, 42, 42, 58,
+	58, 59, 59, 23, 24, 25, 25, 25, 26, 72,
+	72, 71, 71, 40, 40, 40, 40, 7, 7, 7,
 	7, 7, 7, 7, 7, 7, 7, 7, 7, 7,
-	7, 7, 7, 7, 43, 43, 44, 44, 44, 44,
-	42, 42, 42, 42, 42, 42, 42, 42, 51, 51,
-	51, 9, 39, 27, 27, 27, 27, 27, 27, 27,
-	27, 27, 27, 27, 27, 25, 25, 25, 25, 25,
-	25, 25, 25, 25, 25, 25, 25, 25, 25, 25,
-	55, 40, 40, 49, 49, 49, 49, 56, 56,
+	7, 7, 56, 56, 57, 57, 57, 57, 55, 55,
+	55, 55, 55, 55, 55, 55, 64, 64, 64, 9,
+	51, 37, 37, 37, 37, 37, 37, 37, 37, 37,
+	37, 37, 37, 35, 35, 35, 35, 35, 35, 35,
+	35, 35, 35, 35, 35, 35, 35, 35, 35, 35,
+	39, 39, 39, 39, 39, 39, 39, 39, 39, 39,
+	39, 39, 39, 68, 52, 52, 62, 62, 62, 62,
+	70, 70,
 }
-var syntaxR2 = [...]int{
 
+var syntaxR2 = [...]int8{
 	0, 1, 1, 1, 1, 1, 2, 3, 1, 1,
-	1, 1, 1, 1, 3, 8, 2, 3, 4, 5,
-	3, 4, 5, 6, 3, 4, 5, 6, 3, 4,
-	5, 6, 4, 5, 6, 7, 3, 4, 4, 5,
-	3, 2, 3, 6, 3, 1, 1, 1, 4, 6,
-	5, 7, 4, 5, 5, 6, 7, 7, 12, 3,
-	3, 2, 1, 3, 3, 3, 3, 3, 1, 2,
-	1, 2, 2, 2, 2, 2, 2, 2, 2, 2,
-	2, 1, 1, 1, 1, 1, 1, 1, 1, 3,
-	4, 2, 5, 3, 1, 2, 1, 2, 1, 2,
-	1, 2, 1, 2, 2, 3, 2, 2, 1, 3,
-	3, 1, 3, 3, 2, 1, 1, 1, 1, 3,
-	2, 3, 3, 3, 3, 1, 1, 3, 6, 6,
-	1, 1, 3, 3, 3, 3, 3, 3, 3, 3,
+	1, 1, 1, 1, 1, 1, 3, 8, 2, 3,
+	4, 5, 3, 4, 5, 6, 3, 4, 5, 6,
+	3, 4, 5, 6, 4, 5, 6, 7, 3, 4,
+	4, 5, 3, 2, 3, 6, 3, 1, 1, 1,
+	4, 6, 5, 7, 6, 7, 2, 3, 4, 6,
+	6, 4, 5, 5, 6, 7, 7, 6, 7, 7,
+	12, 3, 4, 6, 6, 3, 3, 2, 1, 3,
+	3, 3, 3, 3, 1, 2, 1, 2, 2, 2,
+	2, 2, 2, 2, 2, 2, 2, 2, 2, 2,
+	2, 2, 2, 2, 2, 1, 1, 1, 1, 1,
+	1, 1, 1, 3, 4, 2, 5, 3, 1, 2,
+	1, 2, 1, 2, 1, 2, 1, 2, 1, 2,
+	3, 2, 2, 1, 2, 2, 3, 3, 5, 2,
+	1, 2, 2, 3, 4, 5, 6, 7, 1, 2,
+	3, 5, 4, 6, 1, 3, 3, 1, 3, 3,
+	2, 1, 1, 1, 1, 3, 2, 3, 3, 3,
+	3, 1, 1, 3, 6, 6, 1, 1, 3, 3,
 	3, 3, 3, 3, 3, 3, 3, 3, 3, 3,
-	3, 3, 3, 1, 1, 1, 3, 2, 2, 4,
+	3, 3, 3, 3, 3, 3, 3, 3, 3, 1,
+	1, 1, 3, 2, 2, 2, 4, 6, 9, 1,
+	3, 3, 4, 1, 1, 1, 1, 4, 4, 4,
 	4, 4, 4, 4, 4, 4, 4, 4, 4, 4,
-	4, 4, 4, 4, 0, 1, 5, 4, 5, 4,
-	1, 1, 2, 4, 5, 2, 4, 5, 1, 2,
-	2, 4, 1, 1, 1, 1, 1, 1, 1, 1,
+	4, 4, 0, 1, 5, 4, 5, 4, 1, 1,
+	2, 4, 5, 2, 4, 5, 1, 2, 2, 4,
+	1, 1, 1, 1, 1, 1, 1, 1, 1, 1,
 	1, 1, 1, 1, 1, 1, 1, 1, 1, 1,
 	1, 1, 1, 1, 1, 1, 1, 1, 1, 1,
-	2, 1, 3, 4, 4, 3, 3, 1, 3,
+	1, 1, 1, 1, 1, 1, 1, 1, 1, 1,
+	1, 1, 1, 2, 1, 3, 4, 4, 3, 3,
+	1, 3,
 }
-var syntaxChk = [...]int{
-
-	-1000, -1, -2, -3, -4, -10, -38, 26, -5, -6,
-	-7, -51, -8, -9, 81, 17, -25, -27, 7, 92,
-	93, 69, -39, 30, 31, 32, 45, 46, 55, 56,
-	57, 58, 59, 60, 61, 65, 66, 67, 33, 36,
-	39, 37, 38, 40, 41, 42, 43, 34, 35, 44,
-	68, 83, 84, 85, 92, 93, 94, 95, 96, 97,
-	86, 87, 90, 91, 88, 89, -21, -11, -23, 51,
-	-22, -35, 23, 24, 25, 15, 87, 16, -3, -4,
-	-2, 26, -37, 18, -36, 5, 26, 26, -49, 28,
-	29, 7, 7, 26, 26, -42, -43, -44, 47, -42,
-	-42, -42, -42, -42, -42, -42, -42, -42, -42, -42,
-	-42, -42, -42, -11, -22, -12, -13, -14, -15, -32,
-	-16, -17, -18, -19, -20, 50, 48, 49, 70, 72,
-	-36, -34, -33, -30, 26, 52, 78, 53, 79, 80,
-	5, -31, -29, 83, 6, -28, 73, 27, 27, -56,
-	-4, 18, 2, 21, 13, 87, 14, 15, -50, 7,
-	-38, 26, -4, 7, 26, 26, 26, -4, 7, -2,
-	74, 75, 76, 77, -2, -2, -2, -2, -2, -2,
-	-2, -2, -2, -2, -2, -2, -2, -2, -32, 84,
-	21, 83, -41, -53, 8, -52, 5, -53, 6, 6,
-	-32, 6, -48, -47, 5, -46, -45, 5, -36, -46,
-	13, 87, 90, 91, 88, 89, 86, -24, 6, -28,
-	26, 27, 21, -36, 6, 6, 6, 6, 2, 27,
-	21, 10, -54, -21, 51, -38, -50, 27, 21, -4,
-	7, -40, 27, 5, -40, 27, 21, 27, 26, 26,
-	26, 26, -32, -32, -32, 8, -53, 21, 13, 27,
-	21, 13, 21, 73, 9, 4, -51, 73, 9, 4,
-	-51, 9, 4, -51, 9, 4, -51, 9, 4, -51,
-	9, 4, -51, 9, 4, -51, 83, 26, 6, 82,
-	-4, -49, -50, -55, -54, -21, 71, 10, 51, 10,
-	-54, 54, 27, -54, -21, 27, -49, -4, 27, 21,
-	21, 27, 27, 6, -40, 27, -40, 27, 27, -40,
-	27, -40, -52, 6, -47, 2, 5, 6, -45, 26,
-	26, -24, 6, 27, 26, 27, -54, -21, -54, 9,
-	-55, -32, -55, 10, 5, -26, 62, 63, 64, 10,
-	27, 27, -54, 27, -4, 5, 21, 27, 27, 27,
-	27, 6, 6, 27, -50, -49, -54, -55, 26, -55,
-	-54, 51, 10, 10, 27, -49, 27, 6, 27, 27,
-	27, 5, -54, -55, -55, 10, 21, 27, -55, 6,
-	21, 6, 27,
+
+var syntaxChk = [...]int16{
+	-32768, -1, -2, -3, -4, -12, -50, 28, -5, -6,
+	-7, -64, -8, -9, -10, -11, 83, 19, -35, 86,
+	-37, 87, 7, 119, 120, 71, -51, -39, 101, 32,
+	33, 34, 47, 48, 57, 58, 59, 60, 61, 62,
+	63, 102, 67, 68, 69, 85, 35, 38, 41, 39,
+	40, 42, 43, 44, 45, 36, 37, 46, 70, 88,
+	89, 90, 91, 92, 93, 94, 95, 96, 97, 98,
+	99, 100, 110, 111, 112, 119, 120, 121, 122, 123,
+	124, 113, 114, 117, 118, 115, 116, -31, -13, -33,
+	53, -32, -47, 25, 26, 27, 17, 114, 18, -3,
+	-4, -2, 28, -49, 20, -48, 5, 28, 28, 28,
+	-62, 30, 31, 28, -62, 7, 7, 28, 28, 28,
+	28, -55, -56, -57, 49, -55, -55, -55, -55, -55,
+	-55, -55, -55, -55, -55, -55, -55, -55, -55, -13,
+	-32, -14, -15, -16, -17, -18, -19, -44, -20, -21,
+	-22, -23, -24, -25, -26, -27, -28, -29, -30, 52,
+	50, 51, 72, 74, 104, 103, -48, -46, -45, -42,
+	28, 54, 80, 55, 81, 82, 105, 106, 107, 108,
+	109, 9, 5, -43, -41, 110, 6, -38, 75, 29,
+	29, -70, -4, 20, 2, 23, 15, 114, 16, 17,
+	-63, 7, -69, -50, 28, -4, -63, -69, -4, 7,
+	28, 28, 28, 6, 28, -4, 7, 29, -4, -64,
+	-2, 76, 77, 78, 79, -2, -2, -2, -2, -2,
+	-2, -2, -2, -2, -2, -2, -2, -2, -2, -44,
+	111, 23, 110, -53, -66, 8, -65, 5, -66, 6,
+	6, -66, 6, -54, 5, -44, 6, -61, -60, 5,
+	-59, -58, 5, -48, -59, -72, -71, 41, -40, 35,
+	38, 40, 39, 76, 5, 7, 10, 30, 15, 114,
+	117, 118, 115, 116, 113, -34, 6, -38, 28, 29,
+	23, -48, 6, 6, 6, 6, 2, 29, 23, 29,
+	-31, 11, -67, 53, -50, -63, 12, 23, 23, 29,
+	23, -4, 7, -52, 29, 5, -52, 29, 23, 6,
+	23, 29, 29, 23, 23, 28, 28, 28, 28, -44,
+	-44, -44, 8, -66, 23, 15, -54, 23, 15, 29,
+	23, 15, 23, 30, 23, 28, 28, 28, -53, 30,
+	30, -52, 28, 75, 10, 4, -64, 75, 10, 4,
+	-64, 10, 4, -64, 10, 4, -64, 10, 4, -64,
+	10, 4, -64, 10, 4, -64, 110, 28, 6, 84,
+	-4, -62, -63, -69, 11, -67, -68, -67, -31, 73,
+	11, 53, 56, -31, 29, -67, 29, -68, 7, 7,
+	-62, -4, 29, 23, 23, 29, 29, -4, 23, 6,
+	-64, -4, -52, 29, -52, 29, 29, -52, 29, -52,
+	-65, 6, 5, 6, -60, 2, 5, 6, -58, -52,
+	28, -71, 29, 5, -52, -52, 28, -52, 28, -52,
+	28, 28, -34, 6, 29, 28, 29, 29, -68, 11,
+	-67, -31, -67, 10, -68, -44, 5, -36, 64, 65,
+	66, 29, -67, 11, 29, 29, 29, 29, -4, 5,
+	29, -4, 23, 29, 29, 29, 29, 29, 29, 15,
+	-52, 29, 29, -53, -52, -52, 29, 6, 6, 29,
+	-63, -50, 28, -62, -68, -67, 28, 11, 29, -68,
+	-67, 53, 11, -62, -62, 29, -62, 29, 6, 6,
+	29, 11, 29, 29, 29, 29, 29, -31, -50, 5,
+	-68, 11, -67, -68, 23, 28, -53, -31, 29, -68,
+	6, -3, 28, 23, 29, -3, 6, 29,
 }
-var syntaxDef = [...]int{
 
+var syntaxDef = [...]int16{
 	0, -2, 1, 2, 3, 4, 5, 0, 8, 9,
-	10, 11, 12, 13, 0, 0, 0, 0, 188, 0,
-	0, 0, 0, 205, 206, 207, 208, 209, 210, 211,
-	212, 213, 214, 215, 216, 217, 218, 219, 193, 194,
-	195, 196, 197, 198, 199, 200, 201, 202, 203, 204,
-	192, 174, 174, 174, 174, 174, 174, 174, 174, 174,
-	174, 174, 174, 174, 174, 174, 6, 68, 70, 0,
-	94, 0, 81, 82, 83, 84, 85, 86, 2, 3,
-	0, 0, 0, 61, 62, 0, 0, 0, 0, 0,
-	0, 189, 190, 0, 0, 0, 180, 181, 175, 0,
-	0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
-	0, 0, 0, 69, 95, 71, 72, 73, 74, 75,
-	76, 77, 78, 79, 80, 98, 100, 0, 102, 0,
-	115, 116, 117, 118, 0, 0, 108, 0, 0, 0,
-	0, 130, 131, 0, 91, 0, 87, 7, 14, 0,
-	-2, 59, 60, 0, 0, 0, 0, 0, 0, 0,
-	0, 0, 3, 188, 0, 0, 0, 3, 0, 159,
-	0, 0, 182, 185, 160, 161, 162, 163, 164, 165,
-	166, 167, 168, 169, 170, 171, 172, 173, 120, 0,
-	0, 0, 99, 106, 96, 126, 125, 104, 101, 103,
-	0, 107, 114, 111, 0, 157, 155, 153, 154, 158,
-	0, 0, 0, 0, 0, 0, 0, 93, 88, 0,
-	0, 0, 0, 63, 64, 65, 66, 67, 41, 48,
-	0, 16, 0, 0, 0, 0, 0, 52, 0, 3,
-	188, 0, 225, 221, 0, 226, 0, 191, 0, 0,
-	0, 0, 121, 122, 123, 97, 105, 0, 0, 119,
-	0, 0, 0, 0, 137, 144, 151, 0, 136, 143,
-	150, 132, 139, 146, 133, 140, 147, 134, 141, 148,
-	135, 142, 149, 138, 145, 152, 0, 0, 0, 0,
-	-2, 50, 0, 17, 20, 36, 0, 24, 0, 28,
-	0, 0, 0, 0, 0, 40, 54, 3, 53, 0,
-	0, 223, 224, 0, 0, 177, 0, 179, 183, 0,
-	186, 0, 127, 124, 112, 113, 109, 110, 156, 0,
-	0, 89, 0, 92, 0, 49, 21, 37, 38, 220,
-	25, 44, 29, 32, 42, 0, 45, 46, 47, 18,
-	0, 0, 0, 55, 3, 222, 0, 176, 178, 184,
-	187, 0, 0, 90, 0, 51, 39, 33, 0, 19,
-	22, 0, 26, 30, 0, 56, 57, 0, 128, 129,
-	15, 0, 23, 27, 31, 34, 0, 43, 35, 0,
-	0, 0, 58,
+	10, 11, 12, 13, 14, 15, 0, 0, 0, 0,
+	0, 0, 246, 0, 0, 0, 0, 0, 0, 263,
+	264, 265, 266, 267, 268, 269, 270, 271, 272, 273,
+	274, 275, 276, 277, 278, 279, 251, 252, 253, 254,
+	255, 256, 257, 258, 259, 260, 261, 262, 250, 280,
+	281, 282, 283, 284, 285, 286, 287, 288, 289, 290,
+	291, 292, 232, 232, 232, 232, 232, 232, 232, 232,
+	232, 232, 232, 232, 232, 232, 232, 6, 84, 86,
+	0, 118, 0, 105, 106, 107, 108, 109, 110, 2,
+	3, 0, 0, 0, 77, 78, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 247, 248, 0, 0, 0,
+	0, 0, 238, 239, 233, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0, 0, 85,
+	119, 87, 88, 89, 90, 91, 92, 93, 94, 95,
+	96, 97, 98, 99, 100, 101, 102, 103, 104, 122,
+	124, 0, 126, 0, 128, 133, 161, 162, 163, 164,
+	0, 0, 140, 0, 0, 0, 0, 0, 0, 0,
+	148, 154, 0, 176, 177, 0, 115, 0, 111, 7,
+	16, 0, -2, 75, 76, 0, 0, 0, 0, 0,
+	0, 246, 0, 5, 0, 3, 0, 0, 3, 246,
+	0, 0, 0, 0, 0, 3, 0, 71, 3, 0,
+	217, 0, 0, 240, 243, 218, 219, 220, 221, 222,
+	223, 224, 225, 226, 227, 228, 229, 230, 231, 166,
+	0, 0, 0, 123, 131, 120, 172, 171, 129, 125,
+	127, 132, 134, 135, 0, 0, 139, 160, 157, 0,
+	203, 201, 199, 200, 204, 205, 209, 0, 0, 213,
+	214, 215, 216, 0, 141, 142, 149, 0, 0, 0,
+	0, 0, 0, 0, 0, 117, 112, 0, 0, 0,
+	0, 79, 80, 81, 82, 83, 43, 50, 0, 58,
+	6, 18, 0, 0, 5, 0, 56, 0, 0, 61,
+	0, 3, 246, 0, 298, 294, 0, 299, 0, 0,
+	0, 249, 72, 0, 0, 0, 0, 0, 0, 167,
+	168, 169, 121, 130, 0, 0, 136, 0, 0, 165,
+	0, 0, 0, 0, 0, 0, 0, 0, 143, 0,
+	0, 150, 0, 0, 183, 190, 197, 0, 182, 189,
+	196, 178, 185, 192, 179, 186, 193, 180, 187, 194,
+	181, 188, 195, 184, 191, 198, 0, 0, 0, 0,
+	-2, 52, 0, 0, 30, 0, 19, 22, 38, 0,
+	26, 0, 0, 6, 0, 0, 42, 57, 0, 0,
+	63, 3, 62, 0, 0, 296, 297, 3, 0, 0,
+	0, 3, 0, 235, 0, 237, 241, 0, 244, 0,
+	173, 170, 0, 137, 158, 159, 155, 156, 202, 206,
+	0, 210, 211, 0, 0, 144, 0, 152, 0, 0,
+	0, 0, 113, 0, 116, 0, 51, 59, 31, 34,
+	23, 39, 40, 293, 27, 46, 44, 0, 47, 48,
+	49, 0, 0, 20, 0, 54, 60, 64, 3, 295,
+	67, 3, 0, 73, 74, 234, 236, 242, 245, 0,
+	0, 212, 0, 145, 0, 0, 151, 0, 0, 114,
+	0, 0, 0, 53, 35, 41, 0, 32, 0, 21,
+	24, 0, 28, 55, 65, 66, 68, 69, 0, 138,
+	207, 0, 146, 153, 174, 175, 17, 0, 0, 0,
+	33, 36, 25, 29, 0, 0, 147, 0, 45, 37,
+	0, 0, 0, 0, 208, 0, 0, 70,
 }
-var syntaxTok1 = [...]int{
 
+var syntaxTok1 = [...]int8{
 	1,
 }
-var syntaxTok2 = [...]int{
 
+var syntaxTok2 = [...]int8{
 	2, 3, 4, 5, 6, 7, 8, 9, 10, 11,
 	12, 13, 14, 15, 16, 17, 18, 19, 20, 21,
 	22, 23, 24, 25, 26, 27, 28, 29, 30, 31,
@@ -538,9 +710,13 @@ var syntaxTok2 = [...]int{
 	62, 63, 64, 65, 66, 67, 68, 69, 70, 71,
 	72, 73, 74, 75, 76, 77, 78, 79, 80, 81,
 	82, 83, 84, 85, 86, 87, 88, 89, 90, 91,
-	92, 93, 94, 95, 96, 97,
+	92, 93, 94, 95, 96, 97, 98, 99, 100, 101,
+	102, 103, 104, 105, 106, 107, 108, 109, 110, 111,
+	112, 113, 114, 115, 116, 117, 118, 119, 120, 121,
+	122, 123, 124,
 }
-var syntaxTok3 = [...]int{
+
+var syntaxTok3 = [...]int8{
 	0,
 }
 
@@ -581,7 +757,7 @@ func syntaxNewParser() syntaxParser {
 	return &syntaxParserImpl{}
 }
 
-const syntaxFlag = -1000
+const syntaxFlag = -32768
 
 func syntaxTokname(c int) string {
 	if c >= 1 && c-1 < len(syntaxToknames) {
@@ -620,9 +796,9 @@ func syntaxErrorMessage(state, lookAhead int) string {
 	expected := make([]int, 0, 4)
 
 	// Look for shiftable tokens.
-	base := syntaxPact[state]
+	base := int(syntaxPact[state])
 	for tok := TOKSTART; tok-1 < len(syntaxToknames); tok++ {
-		if n := base + tok; n >= 0 && n < syntaxLast && syntaxChk[syntaxAct[n]] == tok {
+		if n := base + tok; n >= 0 && n < syntaxLast && int(syntaxChk[int(syntaxAct[n])]) == tok {
 			if len(expected) == cap(expected) {
 				return res
 			}
@@ -632,13 +808,13 @@ func syntaxErrorMessage(state, lookAhead int) string {
 
 	if syntaxDef[state] == -2 {
 		i := 0
-		for syntaxExca[i] != -1 || syntaxExca[i+1] != state {
+		for syntaxExca[i] != -1 || int(syntaxExca[i+1]) != state {
 			i += 2
 		}
 
 		// Look for tokens that we accept or reduce.
 		for i += 2; syntaxExca[i] >= 0; i += 2 {
-			tok := syntaxExca[i]
+			tok := int(syntaxExca[i])
 			if tok < TOKSTART || syntaxExca[i+1] == 0 {
 				continue
 			}
@@ -669,30 +845,30 @@ func syntaxlex1(lex syntaxLexer, lval *syntaxSymType) (char, token int) {
 	token = 0
 	char = lex.Lex(lval)
 	if char <= 0 {
-		token = syntaxTok1[0]
+		token = int(syntaxTok1[0])
 		goto out
 	}
 	if char < len(syntaxTok1) {
-		token = syntaxTok1[char]
+		token = int(syntaxTok1[char])
 		goto out
 	}
 	if char >= syntaxPrivate {
 		if char < syntaxPrivate+len(syntaxTok2) {
-			token = syntaxTok2[char-syntaxPrivate]
+			token = int(syntaxTok2[char-syntaxPrivate])
 			goto out
 		}
 	}
 	for i := 0; i < len(syntaxTok3); i += 2 {
-		token = syntaxTok3[i+0]
+		token = int(syntaxTok3[i+0])
 		if token == char {
-			token = syntaxTok3[i+1]
+			token = int(syntaxTok3[i+1])
 			goto out
 		}
 	}
 
 out:
 	if token == 0 {
-		token = syntaxTok2[1] /* unknown char */
+		token = int(syntaxTok2[1]) /* unknown char */
 	}
 	if syntaxDebug >= 3 {
 		__yyfmt__.Printf("lex %s(%d)\n", syntaxTokname(token), uint(char))
@@ -747,7 +923,7 @@ syntaxstack:
 	syntaxS[syntaxp].yys = syntaxstate
 
 syntaxnewstate:
-	syntaxn = syntaxPact[syntaxstate]
+	syntaxn = int(syntaxPact[syntaxstate])
 	if syntaxn <= syntaxFlag {
 		goto syntaxdefault /* simple state */
 	}
@@ -758,8 +934,8 @@ syntaxnewstate:
 	if syntaxn < 0 || syntaxn >= syntaxLast {
 		goto syntaxdefault
 	}
-	syntaxn = syntaxAct[syntaxn]
-	if syntaxChk[syntaxn] == syntaxtoken { /* valid shift */
+	syntaxn = int(syntaxAct[syntaxn])
+	if int(syntaxChk[syntaxn]) == syntaxtoken { /* valid shift */
 		syntaxrcvr.char = -1
 		syntaxtoken = -1
 		syntaxVAL = syntaxrcvr.lval
@@ -772,7 +948,7 @@ syntaxnewstate:
 
 syntaxdefault:
 	/* default state action */
-	syntaxn = syntaxDef[syntaxstate]
+	syntaxn = int(syntaxDef[syntaxstate])
 	if syntaxn == -2 {
 		if syntaxrcvr.char < 0 {
 			syntaxrcvr.char, syntaxtoken = syntaxlex1(syntaxlex, &syntaxrcvr.lval)
@@ -781,18 +957,18 @@ syntaxdefault:
 		/* look through exception table */
 		xi := 0
 		for {
-			if syntaxExca[xi+0] == -1 && syntaxExca[xi+1] == syntaxstate {
+			if syntaxExca[xi+0] == -1 && int(syntaxExca[xi+1]) == syntaxstate {
 				break
 			}
 			xi += 2
 		}
 		for xi += 2; ; xi += 2 {
-			syntaxn = syntaxExca[xi+0]
+			syntaxn = int(syntaxExca[xi+0])
 			if syntaxn < 0 || syntaxn == syntaxtoken {
 				break
 			}
 		}
-		syntaxn = syntaxExca[xi+1]
+		syntaxn = int(syntaxExca[xi+1])
 		if syntaxn < 0 {
 			goto ret0
 		}
@@ -814,10 +990,10 @@ syntaxdefault:
 
 			/* find a state where "error" is a legal shift action */
 			for syntaxp >= 0 {
-				syntaxn = syntaxPact[syntaxS[syntaxp].yys] + syntaxErrCode
+				syntaxn = int(syntaxPact[syntaxS[syntaxp].yys]) + syntaxErrCode
 				if syntaxn >= 0 && syntaxn < syntaxLast {
-					syntaxstate = syntaxAct[syntaxn] /* simulate a shift of "error" */
-					if syntaxChk[syntaxstate] == syntaxErrCode {
+					syntaxstate = int(syntaxAct[syntaxn]) /* simulate a shift of "error" */
+					if int(syntaxChk[syntaxstate]) == syntaxErrCode {
 						goto syntaxstack
 					}
 				}
@@ -853,7 +1029,7 @@ syntaxdefault:
 	syntaxpt := syntaxp
 	_ = syntaxpt // guard against "declared and not used"
 
-	syntaxp -= syntaxR2[syntaxn]
+	syntaxp -= int(syntaxR2[syntaxn])
 	// syntaxp is now the index of $0. Perform the default action. Iff the
 	// reduced production is ε, $1 is possibly out of range.
 	if syntaxp+1 >= len(syntaxS) {
@@ -864,16 +1040,16 @@ syntaxdefault:
 	syntaxVAL = syntaxS[syntaxp+1]
 
 	/* consult goto table to find next state */
-	syntaxn = syntaxR1[syntaxn]
-	syntaxg := syntaxPgo[syntaxn]
+	syntaxn = int(syntaxR1[syntaxn])
+	syntaxg := int(syntaxPgo[syntaxn])
 	syntaxj := syntaxg + syntaxS[syntaxp].yys + 1
 
 	if syntaxj >= syntaxLast {
-		syntaxstate = syntaxAct[syntaxg]
+		syntaxstate = int(syntaxAct[syntaxg])
 	} else {
-		syntaxstate = syntaxAct[syntaxj]
-		if syntaxChk[syntaxstate] != -syntaxn {
-			syntaxstate = syntaxAct[syntaxg]
+		syntaxstate = int(syntaxAct[syntaxj])
+		if int(syntaxChk[syntaxstate]) != -syntaxn {
+			syntaxstate = int(syntaxAct[syntaxg])
 		}
 	}
 	// dummy call; replaced with literal code
@@ -945,1077 +1121,1442 @@ syntaxdefault:
 			syntaxVAL.metricExpr = syntaxDollar[1].metricExpr
 		}
 	case 14:
+		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
+		{
+			syntaxVAL.metricExpr = syntaxDollar[1].metricExpr
+		}
+	case 15:
+		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
+		{
+			syntaxVAL.metricExpr = syntaxDollar[1].metricExpr
+		}
+	case 16:
 		syntaxDollar = syntaxS[syntaxpt-3 : syntaxpt+1]
 		{
 			syntaxVAL.metricExpr = syntaxDollar[2].metricExpr
 		}
-	case 15:
+	case 17:
 		syntaxDollar = syntaxS[syntaxpt-8 : syntaxpt+1]
 		{
 			syntaxVAL.variantsExpr = newVariantsExpr(syntaxDollar[3].metricExprs, syntaxDollar[7].logRangeExpr)
 		}
-	case 16:
+	case 18:
 		syntaxDollar = syntaxS[syntaxpt-2 : syntaxpt+1]
 		{
 			syntaxVAL.logRangeExpr = newLogRange(newMatcherExpr(syntaxDollar[1].matchers), syntaxDollar[2].dur, nil, nil)
 		}
-	case 17:
+	case 19:
 		syntaxDollar = syntaxS[syntaxpt-3 : syntaxpt+1]
 		{
 			syntaxVAL.logRangeExpr = newLogRange(newMatcherExpr(syntaxDollar[1].matchers), syntaxDollar[2].dur, nil, syntaxDollar[3].offsetExpr)
 		}
-	case 18:
+	case 20:
 		syntaxDollar = syntaxS[syntaxpt-4 : syntaxpt+1]
 		{
 			syntaxVAL.logRangeExpr = newLogRange(newMatcherExpr(syntaxDollar[2].matchers), syntaxDollar[4].dur, nil, nil)
 		}
-	case 19:
+	case 21:
 		syntaxDollar = syntaxS[syntaxpt-5 : syntaxpt+1]
 		{
 			syntaxVAL.logRangeExpr = newLogRange(newMatcherExpr(syntaxDollar[2].matchers), syntaxDollar[4].dur, nil, syntaxDollar[5].offsetExpr)
 		}
-	case 20:
+	case 22:
 		syntaxDollar = syntaxS[syntaxpt-3 : syntaxpt+1]
 		{
 			syntaxVAL.logRangeExpr = newLogRange(newMatcherExpr(syntaxDollar[1].matchers), syntaxDollar[2].dur, syntaxDollar[3].unwrapExpr, nil)
 		}
-	case 21:
+	case 23:
 		syntaxDollar = syntaxS[syntaxpt-4 : syntaxpt+1]
 		{
 			syntaxVAL.logRangeExpr = newLogRange(newMatcherExpr(syntaxDollar[1].matchers), syntaxDollar[2].dur, syntaxDollar[4].unwrapExpr, syntaxDollar[3].offsetExpr)
 		}
-	case 22:
+	case 24:
 		syntaxDollar = syntaxS[syntaxpt-5 : syntaxpt+1]
 		{
 			syntaxVAL.logRangeExpr = newLogRange(newMatcherExpr(syntaxDollar[2].matchers), syntaxDollar[4].dur, syntaxDollar[5].unwrapExpr, nil)
 		}
-	case 23:
+	case 25:
 		syntaxDollar = syntaxS[syntaxpt-6 : syntaxpt+1]
 		{
 			syntaxVAL.logRangeExpr = newLogRange(newMatcherExpr(syntaxDollar[2].matchers), syntaxDollar[4].dur, syntaxDollar[6].unwrapExpr, syntaxDollar[5].offsetExpr)
 		}
-	case 24:
+	case 26:
 		syntaxDollar = syntaxS[syntaxpt-3 : syntaxpt+1]
 		{
 			syntaxVAL.logRangeExpr = newLogRange(newMatcherExpr(syntaxDollar[1].matchers), syntaxDollar[3].dur, syntaxDollar[2].unwrapExpr, nil)
 		}
-	case 25:
+	case 27:
 		syntaxDollar = syntaxS[syntaxpt-4 : syntaxpt+1]
 		{
 			syntaxVAL.logRangeExpr = newLogRange(newMatcherExpr(syntaxDollar[1].matchers), syntaxDollar[3].dur, syntaxDollar[2].unwrapExpr, syntaxDollar[4].offsetExpr)
 		}
-	case 26:
+	case 28:
 		syntaxDollar = syntaxS[syntaxpt-5 : syntaxpt+1]
 		{
 			syntaxVAL.logRangeExpr = newLogRange(newMatcherExpr(syntaxDollar[2].matchers), syntaxDollar[5].dur, syntaxDollar[3].unwrapExpr, nil)
 		}
-	case 27:
+	case 29:
 		syntaxDollar = syntaxS[syntaxpt-6 : syntaxpt+1]
 		{
 			syntaxVAL.logRangeExpr = newLogRange(newMatcherExpr(syntaxDollar[2].matchers), syntaxDollar[5].dur, syntaxDollar[3].unwrapExpr, syntaxDollar[6].offsetExpr)
 		}
-	case 28:
+	case 30:
 		syntaxDollar = syntaxS[syntaxpt-3 : syntaxpt+1]
 		{
 			syntaxVAL.logRangeExpr = newLogRange(newPipelineExpr(newMatcherExpr(syntaxDollar[1].matchers), syntaxDollar[2].stages), syntaxDollar[3].dur, nil, nil)
 		}
-	case 29:
+	case 31:
 		syntaxDollar = syntaxS[syntaxpt-4 : syntaxpt+1]
 		{
 			syntaxVAL.logRangeExpr = newLogRange(newPipelineExpr(newMatcherExpr(syntaxDollar[1].matchers), syntaxDollar[2].stages), syntaxDollar[3].dur, nil, syntaxDollar[4].offsetExpr)
 		}
-	case 30:
+	case 32:
 		syntaxDollar = syntaxS[syntaxpt-5 : syntaxpt+1]
 		{
 			syntaxVAL.logRangeExpr = newLogRange(newPipelineExpr(newMatcherExpr(syntaxDollar[2].matchers), syntaxDollar[3].stages), syntaxDollar[5].dur, nil, nil)
 		}
-	case 31:
+	case 33:
 		syntaxDollar = syntaxS[syntaxpt-6 : syntaxpt+1]
 		{
 			syntaxVAL.logRangeExpr = newLogRange(newPipelineExpr(newMatcherExpr(syntaxDollar[2].matchers), syntaxDollar[3].stages), syntaxDollar[5].dur, nil, syntaxDollar[6].offsetExpr)
 		}
-	case 32:
+	case 34:
 		syntaxDollar = syntaxS[syntaxpt-4 : syntaxpt+1]
 		{
 			syntaxVAL.logRangeExpr = newLogRange(newPipelineExpr(newMatcherExpr(syntaxDollar[1].matchers), syntaxDollar[2].stages), syntaxDollar[4].dur, syntaxDollar[3].unwrapExpr, nil)
 		}
-	case 33:
+	case 35:
 		syntaxDollar = syntaxS[syntaxpt-5 : syntaxpt+1]
 		{
 			syntaxVAL.logRangeExpr = newLogRange(newPipelineExpr(newMatcherExpr(syntaxDollar[1].matchers), syntaxDollar[2].stages), syntaxDollar[4].dur, syntaxDollar[3].unwrapExpr, syntaxDollar[5].offsetExpr)
 		}
-	case 34:
+	case 36:
 		syntaxDollar = syntaxS[syntaxpt-6 : syntaxpt+1]
 		{
 			syntaxVAL.logRangeExpr = newLogRange(newPipelineExpr(newMatcherExpr(syntaxDollar[2].matchers), syntaxDollar[3].stages), syntaxDollar[6].dur, syntaxDollar[4].unwrapExpr, nil)
 		}
-	case 35:
+	case 37:
 		syntaxDollar = syntaxS[syntaxpt-7 : syntaxpt+1]
 		{
 			syntaxVAL.logRangeExpr = newLogRange(newPipelineExpr(newMatcherExpr(syntaxDollar[2].matchers), syntaxDollar[3].stages), syntaxDollar[6].dur, syntaxDollar[4].unwrapExpr, syntaxDollar[7].offsetExpr)
 		}
-	case 36:
+	case 38:
 		syntaxDollar = syntaxS[syntaxpt-3 : syntaxpt+1]
 		{
 			syntaxVAL.logRangeExpr = newLogRange(newPipelineExpr(newMatcherExpr(syntaxDollar[1].matchers), syntaxDollar[3].stages), syntaxDollar[2].dur, nil, nil)
 		}
-	case 37:
+	case 39:
 		syntaxDollar = syntaxS[syntaxpt-4 : syntaxpt+1]
 		{
 			syntaxVAL.logRangeExpr = newLogRange(newPipelineExpr(newMatcherExpr(syntaxDollar[1].matchers), syntaxDollar[4].stages), syntaxDollar[2].dur, nil, syntaxDollar[3].offsetExpr)
 		}
-	case 38:
+	case 40:
 		syntaxDollar = syntaxS[syntaxpt-4 : syntaxpt+1]
 		{
 			syntaxVAL.logRangeExpr = newLogRange(newPipelineExpr(newMatcherExpr(syntaxDollar[1].matchers), syntaxDollar[3].stages), syntaxDollar[2].dur, syntaxDollar[4].unwrapExpr, nil)
 		}
-	case 39:
+	case 41:
 		syntaxDollar = syntaxS[syntaxpt-5 : syntaxpt+1]
 		{
 			syntaxVAL.logRangeExpr = newLogRange(newPipelineExpr(newMatcherExpr(syntaxDollar[1].matchers), syntaxDollar[4].stages), syntaxDollar[2].dur, syntaxDollar[5].unwrapExpr, syntaxDollar[3].offsetExpr)
 		}
-	case 40:
+	case 42:
 		syntaxDollar = syntaxS[syntaxpt-3 : syntaxpt+1]
 		{
 			syntaxVAL.logRangeExpr = syntaxDollar[2].logRangeExpr
 		}
-	case 42:
+	case 44:
 		syntaxDollar = syntaxS[syntaxpt-3 : syntaxpt+1]
 		{
 			syntaxVAL.unwrapExpr = newUnwrapExpr(syntaxDollar[3].str, "")
 		}
-	case 43:
+	case 45:
 		syntaxDollar = syntaxS[syntaxpt-6 : syntaxpt+1]
 		{
 			syntaxVAL.unwrapExpr = newUnwrapExpr(syntaxDollar[5].str, syntaxDollar[3].op)
 		}
-	case 44:
+	case 46:
 		syntaxDollar = syntaxS[syntaxpt-3 : syntaxpt+1]
 		{
 			syntaxVAL.unwrapExpr = syntaxDollar[1].unwrapExpr.addPostFilter(syntaxDollar[3].filterer)
 		}
-	case 45:
+	case 47:
 		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
 		{
 			syntaxVAL.op = OpConvBytes
 		}
-	case 46:
+	case 48:
 		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
 		{
 			syntaxVAL.op = OpConvDuration
 		}
-	case 47:
+	case 49:
 		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
 		{
 			syntaxVAL.op = OpConvDurationSeconds
 		}
-	case 48:
+	case 50:
 		syntaxDollar = syntaxS[syntaxpt-4 : syntaxpt+1]
 		{
 			syntaxVAL.metricExpr = newRangeAggregationExpr(syntaxDollar[3].logRangeExpr, syntaxDollar[1].op, nil, nil)
 		}
-	case 49:
+	case 51:
 		syntaxDollar = syntaxS[syntaxpt-6 : syntaxpt+1]
 		{
 			syntaxVAL.metricExpr = newRangeAggregationExpr(syntaxDollar[5].logRangeExpr, syntaxDollar[1].op, nil, &syntaxDollar[3].str)
 		}
-	case 50:
+	case 52:
 		syntaxDollar = syntaxS[syntaxpt-5 : syntaxpt+1]
 		{
 			syntaxVAL.metricExpr = newRangeAggregationExpr(syntaxDollar[3].logRangeExpr, syntaxDollar[1].op, syntaxDollar[5].grouping, nil)
 		}
-	case 51:
+	case 53:
 		syntaxDollar = syntaxS[syntaxpt-7 : syntaxpt+1]
 		{
 			syntaxVAL.metricExpr = newRangeAggregationExpr(syntaxDollar[5].logRangeExpr, syntaxDollar[1].op, syntaxDollar[7].grouping, &syntaxDollar[3].str)
 		}
-	case 52:
-		syntaxDollar = syntaxS[syntaxpt-4 : syntaxpt+1]
-		{
-			syntaxVAL.metricExpr = mustNewVectorAggregationExpr(syntaxDollar[3].metricExpr, syntaxDollar[1].op, nil, nil)
-		}
-	case 53:
-		syntaxDollar = syntaxS[syntaxpt-5 : syntaxpt+1]
-		{
-			syntaxVAL.metricExpr = mustNewVectorAggregationExpr(syntaxDollar[4].metricExpr, syntaxDollar[1].op, syntaxDollar[2].grouping, nil)
-		}
 	case 54:
-		syntaxDollar = syntaxS[syntaxpt-5 : syntaxpt+1]
+		syntaxDollar = syntaxS[syntaxpt-6 : syntaxpt+1]
 		{
-			syntaxVAL.metricExpr = mustNewVectorAggregationExpr(syntaxDollar[3].metricExpr, syntaxDollar[1].op, syntaxDollar[5].grouping, nil)
+			syntaxVAL.metricExpr = newRangeAggregationExpr(syntaxDollar[3].logRangeExpr, OpRangeTypePredictLinear, nil, &syntaxDollar[5].str)
 		}
 	case 55:
-		syntaxDollar = syntaxS[syntaxpt-6 : syntaxpt+1]
+		syntaxDollar = syntaxS[syntaxpt-7 : syntaxpt+1]
 		{
-			syntaxVAL.metricExpr = mustNewVectorAggregationExpr(syntaxDollar[5].metricExpr, syntaxDollar[1].op, nil, &syntaxDollar[3].str)
+			syntaxVAL.metricExpr = newRangeAggregationExpr(syntaxDollar[3].logRangeExpr, OpRangeTypePredictLinear, syntaxDollar[7].grouping, &syntaxDollar[5].str)
 		}
 	case 56:
-		syntaxDollar = syntaxS[syntaxpt-7 : syntaxpt+1]
+		syntaxDollar = syntaxS[syntaxpt-2 : syntaxpt+1]
 		{
-			syntaxVAL.metricExpr = mustNewVectorAggregationExpr(syntaxDollar[5].metricExpr, syntaxDollar[1].op, syntaxDollar[7].grouping, &syntaxDollar[3].str)
+			syntaxVAL.subqueryExpr = newSubqueryExpr(syntaxDollar[1].metricExpr, syntaxDollar[2].subqueryRange, nil)
 		}
 	case 57:
-		syntaxDollar = syntaxS[syntaxpt-7 : syntaxpt+1]
+		syntaxDollar = syntaxS[syntaxpt-3 : syntaxpt+1]
 		{
-			syntaxVAL.metricExpr = mustNewVectorAggregationExpr(syntaxDollar[6].metricExpr, syntaxDollar[1].op, syntaxDollar[2].grouping, &syntaxDollar[4].str)
+			syntaxVAL.subqueryExpr = newSubqueryExpr(syntaxDollar[1].metricExpr, syntaxDollar[2].subqueryRange, syntaxDollar[3].offsetExpr)
 		}
 	case 58:
-		syntaxDollar = syntaxS[syntaxpt-12 : syntaxpt+1]
+		syntaxDollar = syntaxS[syntaxpt-4 : syntaxpt+1]
 		{
-			syntaxVAL.metricExpr = mustNewLabelReplaceExpr(syntaxDollar[3].metricExpr, syntaxDollar[5].str, syntaxDollar[7].str, syntaxDollar[9].str, syntaxDollar[11].str)
+			syntaxVAL.metricExpr = newSubqueryAggregationExpr(syntaxDollar[3].subqueryExpr, syntaxDollar[1].op, nil)
 		}
 	case 59:
-		syntaxDollar = syntaxS[syntaxpt-3 : syntaxpt+1]
+		syntaxDollar = syntaxS[syntaxpt-6 : syntaxpt+1]
 		{
-			syntaxVAL.matchers = syntaxDollar[2].matchers
+			syntaxVAL.metricExpr = newSubqueryAggregationExpr(syntaxDollar[5].subqueryExpr, syntaxDollar[1].op, &syntaxDollar[3].str)
 		}
 	case 60:
-		syntaxDollar = syntaxS[syntaxpt-3 : syntaxpt+1]
+		syntaxDollar = syntaxS[syntaxpt-6 : syntaxpt+1]
 		{
-			syntaxVAL.matchers = syntaxDollar[2].matchers
+			syntaxVAL.metricExpr = newSubqueryAggregationExpr(syntaxDollar[3].subqueryExpr, OpRangeTypePredictLinear, &syntaxDollar[5].str)
 		}
 	case 61:
-		syntaxDollar = syntaxS[syntaxpt-2 : syntaxpt+1]
+		syntaxDollar = syntaxS[syntaxpt-4 : syntaxpt+1]
 		{
+			syntaxVAL.metricExpr = mustNewVectorAggregationExpr(syntaxDollar[3].metricExpr, syntaxDollar[1].op, nil, nil)
 		}
 	case 62:
-		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
+		syntaxDollar = syntaxS[syntaxpt-5 : syntaxpt+1]
 		{
-			syntaxVAL.matchers = []*labels.Matcher{syntaxDollar[1].matcher}
+			syntaxVAL.metricExpr = mustNewVectorAggregationExpr(syntaxDollar[4].metricExpr, syntaxDollar[1].op, syntaxDollar[2].grouping, nil)
 		}
 	case 63:
-		syntaxDollar = syntaxS[syntaxpt-3 : syntaxpt+1]
+		syntaxDollar = syntaxS[syntaxpt-5 : syntaxpt+1]
 		{
-			syntaxVAL.matchers = append(syntaxDollar[1].matchers, syntaxDollar[3].matcher)
+			syntaxVAL.metricExpr = mustNewVectorAggregationExpr(syntaxDollar[3].metricExpr, syntaxDollar[1].op, syntaxDollar[5].grouping, nil)
 		}
 	case 64:
-		syntaxDollar = syntaxS[syntaxpt-3 : syntaxpt+1]
+		syntaxDollar = syntaxS[syntaxpt-6 : syntaxpt+1]
 		{
-			syntaxVAL.matcher = mustNewMatcher(labels.MatchEqual, syntaxDollar[1].str, syntaxDollar[3].str)
+			syntaxVAL.metricExpr = mustNewVectorAggregationExpr(syntaxDollar[5].metricExpr, syntaxDollar[1].op, nil, &syntaxDollar[3].str)
 		}
 	case 65:
-		syntaxDollar = syntaxS[syntaxpt-3 : syntaxpt+1]
+		syntaxDollar = syntaxS[syntaxpt-7 : syntaxpt+1]
 		{
-			syntaxVAL.matcher = mustNewMatcher(labels.MatchNotEqual, syntaxDollar[1].str, syntaxDollar[3].str)
+			syntaxVAL.metricExpr = mustNewVectorAggregationExpr(syntaxDollar[5].metricExpr, syntaxDollar[1].op, syntaxDollar[7].grouping, &syntaxDollar[3].str)
 		}
 	case 66:
-		syntaxDollar = syntaxS[syntaxpt-3 : syntaxpt+1]
+		syntaxDollar = syntaxS[syntaxpt-7 : syntaxpt+1]
 		{
-			syntaxVAL.matcher = mustNewMatcher(labels.MatchRegexp, syntaxDollar[1].str, syntaxDollar[3].str)
+			syntaxVAL.metricExpr = mustNewVectorAggregationExpr(syntaxDollar[6].metricExpr, syntaxDollar[1].op, syntaxDollar[2].grouping, &syntaxDollar[4].str)
 		}
 	case 67:
-		syntaxDollar = syntaxS[syntaxpt-3 : syntaxpt+1]
+		syntaxDollar = syntaxS[syntaxpt-6 : syntaxpt+1]
 		{
-			syntaxVAL.matcher = mustNewMatcher(labels.MatchNotRegexp, syntaxDollar[1].str, syntaxDollar[3].str)
+			syntaxVAL.metricExpr = mustNewCountValuesExpr(syntaxDollar[5].metricExpr, syntaxDollar[3].str, nil)
 		}
 	case 68:
-		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
+		syntaxDollar = syntaxS[syntaxpt-7 : syntaxpt+1]
 		{
-			syntaxVAL.stages = MultiStageExpr{syntaxDollar[1].stage}
+			syntaxVAL.metricExpr = mustNewCountValuesExpr(syntaxDollar[5].metricExpr, syntaxDollar[3].str, syntaxDollar[7].grouping)
 		}
 	case 69:
-		syntaxDollar = syntaxS[syntaxpt-2 : syntaxpt+1]
+		syntaxDollar = syntaxS[syntaxpt-7 : syntaxpt+1]
 		{
-			syntaxVAL.stages = append(syntaxDollar[1].stages, syntaxDollar[2].stage)
+			syntaxVAL.metricExpr = mustNewCountValuesExpr(syntaxDollar[6].metricExpr, syntaxDollar[4].str, syntaxDollar[2].grouping)
 		}
 	case 70:
-		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
+		syntaxDollar = syntaxS[syntaxpt-12 : syntaxpt+1]
 		{
-			syntaxVAL.stage = syntaxDollar[1].lineFilterExpr
+			syntaxVAL.metricExpr = mustNewLabelReplaceExpr(syntaxDollar[3].metricExpr, syntaxDollar[5].str, syntaxDollar[7].str, syntaxDollar[9].str, syntaxDollar[11].str)
 		}
 	case 71:
-		syntaxDollar = syntaxS[syntaxpt-2 : syntaxpt+1]
+		syntaxDollar = syntaxS[syntaxpt-3 : syntaxpt+1]
 		{
-			syntaxVAL.stage = syntaxDollar[2].stage
+			syntaxVAL.metricExpr = newFunctionExpr(syntaxDollar[1].op, nil, nil)
 		}
 	case 72:
-		syntaxDollar = syntaxS[syntaxpt-2 : syntaxpt+1]
+		syntaxDollar = syntaxS[syntaxpt-4 : syntaxpt+1]
 		{
-			syntaxVAL.stage = syntaxDollar[2].stage
+			syntaxVAL.metricExpr = newFunctionExpr(syntaxDollar[1].op, syntaxDollar[3].metricExpr, nil)
 		}
 	case 73:
-		syntaxDollar = syntaxS[syntaxpt-2 : syntaxpt+1]
+		syntaxDollar = syntaxS[syntaxpt-6 : syntaxpt+1]
 		{
-			syntaxVAL.stage = syntaxDollar[2].stage
+			syntaxVAL.metricExpr = newFunctionExpr(syntaxDollar[1].op, syntaxDollar[3].metricExpr, syntaxDollar[5].literalExpr)
 		}
 	case 74:
-		syntaxDollar = syntaxS[syntaxpt-2 : syntaxpt+1]
+		syntaxDollar = syntaxS[syntaxpt-6 : syntaxpt+1]
 		{
-			syntaxVAL.stage = syntaxDollar[2].stage
+			syntaxVAL.metricExpr = newFunctionExpr(OpFuncHistogramQuantile, syntaxDollar[5].metricExpr, syntaxDollar[3].literalExpr)
 		}
 	case 75:
-		syntaxDollar = syntaxS[syntaxpt-2 : syntaxpt+1]
+		syntaxDollar = syntaxS[syntaxpt-3 : syntaxpt+1]
 		{
-			syntaxVAL.stage = &LabelFilterExpr{LabelFilterer: syntaxDollar[2].filterer}
+			syntaxVAL.matchers = syntaxDollar[2].matchers
 		}
 	case 76:
-		syntaxDollar = syntaxS[syntaxpt-2 : syntaxpt+1]
+		syntaxDollar = syntaxS[syntaxpt-3 : syntaxpt+1]
 		{
-			syntaxVAL.stage = syntaxDollar[2].stage
+			syntaxVAL.matchers = syntaxDollar[2].matchers
 		}
 	case 77:
 		syntaxDollar = syntaxS[syntaxpt-2 : syntaxpt+1]
 		{
-			syntaxVAL.stage = syntaxDollar[2].stage
 		}
 	case 78:
-		syntaxDollar = syntaxS[syntaxpt-2 : syntaxpt+1]
+		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
 		{
-			syntaxVAL.stage = syntaxDollar[2].stage
+			syntaxVAL.matchers = []*labels.Matcher{syntaxDollar[1].matcher}
 		}
 	case 79:
-		syntaxDollar = syntaxS[syntaxpt-2 : syntaxpt+1]
+		syntaxDollar = syntaxS[syntaxpt-3 : syntaxpt+1]
 		{
-			syntaxVAL.stage = syntaxDollar[2].stage
+			syntaxVAL.matchers = append(syntaxDollar[1].matchers, syntaxDollar[3].matcher)
 		}
 	case 80:
-		syntaxDollar = syntaxS[syntaxpt-2 : syntaxpt+1]
+		syntaxDollar = syntaxS[syntaxpt-3 : syntaxpt+1]
 		{
-			syntaxVAL.stage = syntaxDollar[2].stage
+			syntaxVAL.matcher = mustNewMatcher(labels.MatchEqual, syntaxDollar[1].str, syntaxDollar[3].str)
 		}
 	case 81:
-		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
+		syntaxDollar = syntaxS[syntaxpt-3 : syntaxpt+1]
 		{
-			syntaxVAL.filter = log.LineMatchRegexp
+			syntaxVAL.matcher = mustNewMatcher(labels.MatchNotEqual, syntaxDollar[1].str, syntaxDollar[3].str)
 		}
 	case 82:
-		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
+		syntaxDollar = syntaxS[syntaxpt-3 : syntaxpt+1]
 		{
-			syntaxVAL.filter = log.LineMatchEqual
+			syntaxVAL.matcher = mustNewMatcher(labels.MatchRegexp, syntaxDollar[1].str, syntaxDollar[3].str)
 		}
 	case 83:
-		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
+		syntaxDollar = syntaxS[syntaxpt-3 : syntaxpt+1]
 		{
-			syntaxVAL.filter = log.LineMatchPattern
+			syntaxVAL.matcher = mustNewMatcher(labels.MatchNotRegexp, syntaxDollar[1].str, syntaxDollar[3].str)
 		}
 	case 84:
 		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
 		{
-			syntaxVAL.filter = log.LineMatchNotRegexp
+			syntaxVAL.stages = MultiStageExpr{syntaxDollar[1].stage}
 		}
 	case 85:
-		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
+		syntaxDollar = syntaxS[syntaxpt-2 : syntaxpt+1]
 		{
-			syntaxVAL.filter = log.LineMatchNotEqual
+			syntaxVAL.stages = append(syntaxDollar[1].stages, syntaxDollar[2].stage)
 		}
 	case 86:
 		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
 		{
-			syntaxVAL.filter = log.LineMatchNotPattern
+			syntaxVAL.stage = syntaxDollar[1].lineFilterExpr
 		}
 	case 87:
-		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
+		syntaxDollar = syntaxS[syntaxpt-2 : syntaxpt+1]
 		{
-			syntaxVAL.op = OpFilterIP
+			syntaxVAL.stage = syntaxDollar[2].stage
 		}
 	case 88:
-		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
+		syntaxDollar = syntaxS[syntaxpt-2 : syntaxpt+1]
 		{
-			syntaxVAL.lineFilterExpr = newLineFilterExpr(log.LineMatchEqual, "", syntaxDollar[1].str)
+			syntaxVAL.stage = syntaxDollar[2].stage
 		}
 	case 89:
-		syntaxDollar = syntaxS[syntaxpt-3 : syntaxpt+1]
+		syntaxDollar = syntaxS[syntaxpt-2 : syntaxpt+1]
 		{
-			syntaxVAL.lineFilterExpr = newOrLineFilterExpr(newLineFilterExpr(log.LineMatchEqual, "", syntaxDollar[1].str), syntaxDollar[3].lineFilterExpr)
+			syntaxVAL.stage = syntaxDollar[2].stage
 		}
 	case 90:
-		syntaxDollar = syntaxS[syntaxpt-4 : syntaxpt+1]
+		syntaxDollar = syntaxS[syntaxpt-2 : syntaxpt+1]
 		{
-			syntaxVAL.lineFilterExpr = newLineFilterExpr(log.LineMatchEqual, syntaxDollar[1].op, syntaxDollar[3].str)
+			syntaxVAL.stage = syntaxDollar[2].stage
 		}
 	case 91:
 		syntaxDollar = syntaxS[syntaxpt-2 : syntaxpt+1]
 		{
-			syntaxVAL.lineFilterExpr = newLineFilterExpr(syntaxDollar[1].filter, "", syntaxDollar[2].str)
+			syntaxVAL.stage = syntaxDollar[2].stage
 		}
 	case 92:
-		syntaxDollar = syntaxS[syntaxpt-5 : syntaxpt+1]
+		syntaxDollar = syntaxS[syntaxpt-2 : syntaxpt+1]
 		{
-			syntaxVAL.lineFilterExpr = newLineFilterExpr(syntaxDollar[1].filter, syntaxDollar[2].op, syntaxDollar[4].str)
+			syntaxVAL.stage = syntaxDollar[2].stage
 		}
 	case 93:
-		syntaxDollar = syntaxS[syntaxpt-3 : syntaxpt+1]
+		syntaxDollar = syntaxS[syntaxpt-2 : syntaxpt+1]
 		{
-			syntaxVAL.lineFilterExpr = newOrLineFilterExpr(syntaxDollar[1].lineFilterExpr, syntaxDollar[3].lineFilterExpr)
+			syntaxVAL.stage = &LabelFilterExpr{LabelFilterer: syntaxDollar[2].filterer}
 		}
 	case 94:
-		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
+		syntaxDollar = syntaxS[syntaxpt-2 : syntaxpt+1]
 		{
-			syntaxVAL.lineFilterExpr = syntaxDollar[1].lineFilterExpr
+			syntaxVAL.stage = syntaxDollar[2].stage
 		}
 	case 95:
 		syntaxDollar = syntaxS[syntaxpt-2 : syntaxpt+1]
 		{
-			syntaxVAL.lineFilterExpr = newNestedLineFilterExpr(syntaxDollar[1].lineFilterExpr, syntaxDollar[2].lineFilterExpr)
+			syntaxVAL.stage = syntaxDollar[2].stage
 		}
 	case 96:
-		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
+		syntaxDollar = syntaxS[syntaxpt-2 : syntaxpt+1]
 		{
-			syntaxVAL.strs = []string{syntaxDollar[1].str}
+			syntaxVAL.stage = syntaxDollar[2].stage
 		}
 	case 97:
 		syntaxDollar = syntaxS[syntaxpt-2 : syntaxpt+1]
 		{
-			syntaxVAL.strs = append(syntaxDollar[1].strs, syntaxDollar[2].str)
+			syntaxVAL.stage = syntaxDollar[2].stage
 		}
 	case 98:
-		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
+		syntaxDollar = syntaxS[syntaxpt-2 : syntaxpt+1]
 		{
-			syntaxVAL.stage = newLogfmtParserExpr(nil)
+			syntaxVAL.stage = syntaxDollar[2].stage
 		}
 	case 99:
 		syntaxDollar = syntaxS[syntaxpt-2 : syntaxpt+1]
 		{
-			syntaxVAL.stage = newLogfmtParserExpr(syntaxDollar[2].strs)
+			syntaxVAL.stage = syntaxDollar[2].stage
 		}
 	case 100:
-		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
+		syntaxDollar = syntaxS[syntaxpt-2 : syntaxpt+1]
 		{
-			syntaxVAL.stage = newLabelParserExpr(OpParserTypeJSON, "")
+			syntaxVAL.stage = syntaxDollar[2].stage
 		}
 	case 101:
 		syntaxDollar = syntaxS[syntaxpt-2 : syntaxpt+1]
 		{
-			syntaxVAL.stage = newLabelParserExpr(OpParserTypeRegexp, syntaxDollar[2].str)
+			syntaxVAL.stage = syntaxDollar[2].stage
 		}
 	case 102:
+		syntaxDollar = syntaxS[syntaxpt-2 : syntaxpt+1]
+		{
+			syntaxVAL.stage = syntaxDollar[2].stage
+		}
+	case 103:
+		syntaxDollar = syntaxS[syntaxpt-2 : syntaxpt+1]
+		{
+			syntaxVAL.stage = syntaxDollar[2].stage
+		}
+	case 104:
+		syntaxDollar = syntaxS[syntaxpt-2 : syntaxpt+1]
+		{
+			syntaxVAL.stage = syntaxDollar[2].stage
+		}
+	case 105:
+		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
+		{
+			syntaxVAL.filter = log.LineMatchRegexp
+		}
+	case 106:
+		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
+		{
+			syntaxVAL.filter = log.LineMatchEqual
+		}
+	case 107:
+		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
+		{
+			syntaxVAL.filter = log.LineMatchPattern
+		}
+	case 108:
+		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
+		{
+			syntaxVAL.filter = log.LineMatchNotRegexp
+		}
+	case 109:
+		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
+		{
+			syntaxVAL.filter = log.LineMatchNotEqual
+		}
+	case 110:
+		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
+		{
+			syntaxVAL.filter = log.LineMatchNotPattern
+		}
+	case 111:
+		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
+		{
+			syntaxVAL.op = OpFilterIP
+		}
+	case 112:
+		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
+		{
+			syntaxVAL.lineFilterExpr = newLineFilterExpr(log.LineMatchEqual, "", syntaxDollar[1].str)
+		}
+	case 113:
+		syntaxDollar = syntaxS[syntaxpt-3 : syntaxpt+1]
+		{
+			syntaxVAL.lineFilterExpr = newOrLineFilterExpr(newLineFilterExpr(log.LineMatchEqual, "", syntaxDollar[1].str), syntaxDollar[3].lineFilterExpr)
+		}
+	case 114:
+		syntaxDollar = syntaxS[syntaxpt-4 : syntaxpt+1]
+		{
+			syntaxVAL.lineFilterExpr = newLineFilterExpr(log.LineMatchEqual, syntaxDollar[1].op, syntaxDollar[3].str)
+		}
+	case 115:
+		syntaxDollar = syntaxS[syntaxpt-2 : syntaxpt+1]
+		{
+			syntaxVAL.lineFilterExpr = newLineFilterExpr(syntaxDollar[1].filter, "", syntaxDollar[2].str)
+		}
+	case 116:
+		syntaxDollar = syntaxS[syntaxpt-5 : syntaxpt+1]
+		{
+			syntaxVAL.lineFilterExpr = newLineFilterExpr(syntaxDollar[1].filter, syntaxDollar[2].op, syntaxDollar[4].str)
+		}
+	case 117:
+		syntaxDollar = syntaxS[syntaxpt-3 : syntaxpt+1]
+		{
+			syntaxVAL.lineFilterExpr = newOrLineFilterExpr(syntaxDollar[1].lineFilterExpr, syntaxDollar[3].lineFilterExpr)
+		}
+	case 118:
+		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
+		{
+			syntaxVAL.lineFilterExpr = syntaxDollar[1].lineFilterExpr
+		}
+	case 119:
+		syntaxDollar = syntaxS[syntaxpt-2 : syntaxpt+1]
+		{
+			syntaxVAL.lineFilterExpr = newNestedLineFilterExpr(syntaxDollar[1].lineFilterExpr, syntaxDollar[2].lineFilterExpr)
+		}
+	case 120:
+		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
+		{
+			syntaxVAL.strs = []string{syntaxDollar[1].str}
+		}
+	case 121:
+		syntaxDollar = syntaxS[syntaxpt-2 : syntaxpt+1]
+		{
+			syntaxVAL.strs = append(syntaxDollar[1].strs, syntaxDollar[2].str)
+		}
+	case 122:
+		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
+		{
+			syntaxVAL.stage = newLogfmtParserExpr(nil)
+		}
+	case 123:
+		syntaxDollar = syntaxS[syntaxpt-2 : syntaxpt+1]
+		{
+			syntaxVAL.stage = newLogfmtParserExpr(syntaxDollar[2].strs)
+		}
+	case 124:
+		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
+		{
+			syntaxVAL.stage = newLabelParserExpr(OpParserTypeJSON, "")
+		}
+	case 125:
+		syntaxDollar = syntaxS[syntaxpt-2 : syntaxpt+1]
+		{
+			syntaxVAL.stage = newLabelParserExpr(OpParserTypeRegexp, syntaxDollar[2].str)
+		}
+	case 126:
 		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
 		{
 			syntaxVAL.stage = newLabelParserExpr(OpParserTypeUnpack, "")
 		}
-	case 103:
+	case 127:
 		syntaxDollar = syntaxS[syntaxpt-2 : syntaxpt+1]
 		{
 			syntaxVAL.stage = newLabelParserExpr(OpParserTypePattern, syntaxDollar[2].str)
 		}
-	case 104:
+	case 128:
+		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
+		{
+			syntaxVAL.stage = newLabelParserExpr(OpParserTypeXML, "")
+		}
+	case 129:
 		syntaxDollar = syntaxS[syntaxpt-2 : syntaxpt+1]
 		{
 			syntaxVAL.stage = newJSONExpressionParser(syntaxDollar[2].labelExtractionExpressionList)
 		}
-	case 105:
+	case 130:
 		syntaxDollar = syntaxS[syntaxpt-3 : syntaxpt+1]
 		{
 			syntaxVAL.stage = newLogfmtExpressionParser(syntaxDollar[3].labelExtractionExpressionList, syntaxDollar[2].strs)
 		}
-	case 106:
+	case 131:
 		syntaxDollar = syntaxS[syntaxpt-2 : syntaxpt+1]
 		{
 			syntaxVAL.stage = newLogfmtExpressionParser(syntaxDollar[2].labelExtractionExpressionList, nil)
 		}
-	case 107:
+	case 132:
+		syntaxDollar = syntaxS[syntaxpt-2 : syntaxpt+1]
+		{
+			syntaxVAL.stage = newXMLExpressionParser(syntaxDollar[2].labelExtractionExpressionList)
+		}
+	case 133:
+		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
+		{
+			syntaxVAL.stage = newCSVParserExpr("", nil)
+		}
+	case 134:
+		syntaxDollar = syntaxS[syntaxpt-2 : syntaxpt+1]
+		{
+			syntaxVAL.stage = newCSVParserExpr(syntaxDollar[2].str, nil)
+		}
+	case 135:
+		syntaxDollar = syntaxS[syntaxpt-2 : syntaxpt+1]
+		{
+			syntaxVAL.stage = newCSVParserExpr("", syntaxDollar[2].strs)
+		}
+	case 136:
+		syntaxDollar = syntaxS[syntaxpt-3 : syntaxpt+1]
+		{
+			syntaxVAL.stage = newCSVParserExpr(syntaxDollar[2].str, syntaxDollar[3].strs)
+		}
+	case 137:
+		syntaxDollar = syntaxS[syntaxpt-3 : syntaxpt+1]
+		{
+			syntaxVAL.strs = []string{syntaxDollar[1].str, syntaxDollar[3].str}
+		}
+	case 138:
+		syntaxDollar = syntaxS[syntaxpt-5 : syntaxpt+1]
+		{
+			syntaxVAL.strs = append(syntaxDollar[1].strs, syntaxDollar[3].str, syntaxDollar[5].str)
+		}
+	case 139:
 		syntaxDollar = syntaxS[syntaxpt-2 : syntaxpt+1]
 		{
 			syntaxVAL.stage = newLineFmtExpr(syntaxDollar[2].str)
 		}
-	case 108:
+	case 140:
 		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
 		{
 			syntaxVAL.stage = newDecolorizeExpr()
 		}
-	case 109:
+	case 141:
+		syntaxDollar = syntaxS[syntaxpt-2 : syntaxpt+1]
+		{
+			syntaxVAL.stage = newGeoIPExpr(syntaxDollar[2].str)
+		}
+	case 142:
+		syntaxDollar = syntaxS[syntaxpt-2 : syntaxpt+1]
+		{
+			syntaxVAL.stage = newSamplingExpr(syntaxDollar[2].str, nil, nil)
+		}
+	case 143:
+		syntaxDollar = syntaxS[syntaxpt-3 : syntaxpt+1]
+		{
+			syntaxVAL.stage = newSamplingExpr(syntaxDollar[2].str, nil, syntaxDollar[3].strs)
+		}
+	case 144:
+		syntaxDollar = syntaxS[syntaxpt-4 : syntaxpt+1]
+		{
+			syntaxVAL.stage = newSamplingExpr(syntaxDollar[2].str, syntaxDollar[4].strs, nil)
+		}
+	case 145:
+		syntaxDollar = syntaxS[syntaxpt-5 : syntaxpt+1]
+		{
+			syntaxVAL.stage = newSamplingExpr(syntaxDollar[2].str, syntaxDollar[4].strs, syntaxDollar[5].strs)
+		}
+	case 146:
+		syntaxDollar = syntaxS[syntaxpt-6 : syntaxpt+1]
+		{
+			syntaxVAL.stage = newSamplingExpr(syntaxDollar[2].str, syntaxDollar[5].strs, nil)
+		}
+	case 147:
+		syntaxDollar = syntaxS[syntaxpt-7 : syntaxpt+1]
+		{
+			syntaxVAL.stage = newSamplingExpr(syntaxDollar[2].str, syntaxDollar[5].strs, syntaxDollar[7].strs)
+		}
+	case 148:
+		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
+		{
+			syntaxVAL.stage = newDedupExpr(0, nil)
+		}
+	case 149:
+		syntaxDollar = syntaxS[syntaxpt-2 : syntaxpt+1]
+		{
+			syntaxVAL.stage = newDedupExpr(syntaxDollar[2].dur, nil)
+		}
+	case 150:
+		syntaxDollar = syntaxS[syntaxpt-3 : syntaxpt+1]
+		{
+			syntaxVAL.stage = newDedupExpr(0, syntaxDollar[3].strs)
+		}
+	case 151:
+		syntaxDollar = syntaxS[syntaxpt-5 : syntaxpt+1]
+		{
+			syntaxVAL.stage = newDedupExpr(0, syntaxDollar[4].strs)
+		}
+	case 152:
+		syntaxDollar = syntaxS[syntaxpt-4 : syntaxpt+1]
+		{
+			syntaxVAL.stage = newDedupExpr(syntaxDollar[2].dur, syntaxDollar[4].strs)
+		}
+	case 153:
+		syntaxDollar = syntaxS[syntaxpt-6 : syntaxpt+1]
+		{
+			syntaxVAL.stage = newDedupExpr(syntaxDollar[2].dur, syntaxDollar[5].strs)
+		}
+	case 154:
+		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
+		{
+			syntaxVAL.stage = newMacroExpr(syntaxDollar[1].str)
+		}
+	case 155:
 		syntaxDollar = syntaxS[syntaxpt-3 : syntaxpt+1]
 		{
 			syntaxVAL.labelFormat = log.NewRenameLabelFmt(syntaxDollar[1].str, syntaxDollar[3].str)
 		}
-	case 110:
+	case 156:
 		syntaxDollar = syntaxS[syntaxpt-3 : syntaxpt+1]
 		{
 			syntaxVAL.labelFormat = log.NewTemplateLabelFmt(syntaxDollar[1].str, syntaxDollar[3].str)
 		}
-	case 111:
+	case 157:
 		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
 		{
 			syntaxVAL.labelsFormat = []log.LabelFmt{syntaxDollar[1].labelFormat}
 		}
-	case 112:
+	case 158:
 		syntaxDollar = syntaxS[syntaxpt-3 : syntaxpt+1]
 		{
 			syntaxVAL.labelsFormat = append(syntaxDollar[1].labelsFormat, syntaxDollar[3].labelFormat)
 		}
-	case 114:
+	case 160:
 		syntaxDollar = syntaxS[syntaxpt-2 : syntaxpt+1]
 		{
 			syntaxVAL.stage = newLabelFmtExpr(syntaxDollar[2].labelsFormat)
 		}
-	case 115:
+	case 161:
 		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
 		{
 			syntaxVAL.filterer = log.NewStringLabelFilter(syntaxDollar[1].matcher)
 		}
-	case 116:
+	case 162:
 		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
 		{
 			syntaxVAL.filterer = syntaxDollar[1].filterer
 		}
-	case 117:
+	case 163:
 		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
 		{
 			syntaxVAL.filterer = syntaxDollar[1].filterer
 		}
-	case 118:
+	case 164:
 		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
 		{
 			syntaxVAL.filterer = syntaxDollar[1].filterer
 		}
-	case 119:
+	case 165:
 		syntaxDollar = syntaxS[syntaxpt-3 : syntaxpt+1]
 		{
 			syntaxVAL.filterer = syntaxDollar[2].filterer
 		}
-	case 120:
+	case 166:
 		syntaxDollar = syntaxS[syntaxpt-2 : syntaxpt+1]
 		{
 			syntaxVAL.filterer = log.NewAndLabelFilter(syntaxDollar[1].filterer, syntaxDollar[2].filterer)
 		}
-	case 121:
+	case 167:
 		syntaxDollar = syntaxS[syntaxpt-3 : syntaxpt+1]
 		{
 			syntaxVAL.filterer = log.NewAndLabelFilter(syntaxDollar[1].filterer, syntaxDollar[3].filterer)
 		}
-	case 122:
+	case 168:
 		syntaxDollar = syntaxS[syntaxpt-3 : syntaxpt+1]
 		{
 			syntaxVAL.filterer = log.NewAndLabelFilter(syntaxDollar[1].filterer, syntaxDollar[3].filterer)
 		}
-	case 123:
+	case 169:
 		syntaxDollar = syntaxS[syntaxpt-3 : syntaxpt+1]
 		{
 			syntaxVAL.filterer = log.NewOrLabelFilter(syntaxDollar[1].filterer, syntaxDollar[3].filterer)
 		}
-	case 124:
+	case 170:
 		syntaxDollar = syntaxS[syntaxpt-3 : syntaxpt+1]
 		{
 			syntaxVAL.labelExtractionExpression = log.NewLabelExtractionExpr(syntaxDollar[1].str, syntaxDollar[3].str)
 		}
-	case 125:
+	case 171:
 		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
 		{
 			syntaxVAL.labelExtractionExpression = log.NewLabelExtractionExpr(syntaxDollar[1].str, syntaxDollar[1].str)
 		}
-	case 126:
+	case 172:
 		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
 		{
 			syntaxVAL.labelExtractionExpressionList = []log.LabelExtractionExpr{syntaxDollar[1].labelExtractionExpression}
 		}
-	case 127:
+	case 173:
 		syntaxDollar = syntaxS[syntaxpt-3 : syntaxpt+1]
 		{
 			syntaxVAL.labelExtractionExpressionList = append(syntaxDollar[1].labelExtractionExpressionList, syntaxDollar[3].labelExtractionExpression)
 		}
-	case 128:
+	case 174:
 		syntaxDollar = syntaxS[syntaxpt-6 : syntaxpt+1]
 		{
 			syntaxVAL.filterer = log.NewIPLabelFilter(syntaxDollar[5].str, syntaxDollar[1].str, log.LabelFilterEqual)
 		}
-	case 129:
+	case 175:
 		syntaxDollar = syntaxS[syntaxpt-6 : syntaxpt+1]
 		{
 			syntaxVAL.filterer = log.NewIPLabelFilter(syntaxDollar[5].str, syntaxDollar[1].str, log.LabelFilterNotEqual)
 		}
-	case 130:
+	case 176:
 		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
 		{
 			syntaxVAL.filterer = syntaxDollar[1].filterer
 		}
-	case 131:
+	case 177:
 		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
 		{
 			syntaxVAL.filterer = syntaxDollar[1].filterer
 		}
-	case 132:
+	case 178:
 		syntaxDollar = syntaxS[syntaxpt-3 : syntaxpt+1]
 		{
 			syntaxVAL.filterer = log.NewDurationLabelFilter(log.LabelFilterGreaterThan, syntaxDollar[1].str, syntaxDollar[3].dur)
 		}
-	case 133:
+	case 179:
 		syntaxDollar = syntaxS[syntaxpt-3 : syntaxpt+1]
 		{
 			syntaxVAL.filterer = log.NewDurationLabelFilter(log.LabelFilterGreaterThanOrEqual, syntaxDollar[1].str, syntaxDollar[3].dur)
 		}
-	case 134:
+	case 180:
 		syntaxDollar = syntaxS[syntaxpt-3 : syntaxpt+1]
 		{
 			syntaxVAL.filterer = log.NewDurationLabelFilter(log.LabelFilterLesserThan, syntaxDollar[1].str, syntaxDollar[3].dur)
 		}
-	case 135:
+	case 181:
 		syntaxDollar = syntaxS[syntaxpt-3 : syntaxpt+1]
 		{
 			syntaxVAL.filterer = log.NewDurationLabelFilter(log.LabelFilterLesserThanOrEqual, syntaxDollar[1].str, syntaxDollar[3].dur)
 		}
-	case 136:
+	case 182:
 		syntaxDollar = syntaxS[syntaxpt-3 : syntaxpt+1]
 		{
 			syntaxVAL.filterer = log.NewDurationLabelFilter(log.LabelFilterNotEqual, syntaxDollar[1].str, syntaxDollar[3].dur)
 		}
-	case 137:
+	case 183:
 		syntaxDollar = syntaxS[syntaxpt-3 : syntaxpt+1]
 		{
 			syntaxVAL.filterer = log.NewDurationLabelFilter(log.LabelFilterEqual, syntaxDollar[1].str, syntaxDollar[3].dur)
 		}
-	case 138:
+	case 184:
 		syntaxDollar = syntaxS[syntaxpt-3 : syntaxpt+1]
 		{
 			syntaxVAL.filterer = log.NewDurationLabelFilter(log.LabelFilterEqual, syntaxDollar[1].str, syntaxDollar[3].dur)
 		}
-	case 139:
+	case 185:
 		syntaxDollar = syntaxS[syntaxpt-3 : syntaxpt+1]
 		{
 			syntaxVAL.filterer = log.NewBytesLabelFilter(log.LabelFilterGreaterThan, syntaxDollar[1].str, syntaxDollar[3].bytes)
 		}
-	case 140:
+	case 186:
 		syntaxDollar = syntaxS[syntaxpt-3 : syntaxpt+1]
 		{
 			syntaxVAL.filterer = log.NewBytesLabelFilter(log.LabelFilterGreaterThanOrEqual, syntaxDollar[1].str, syntaxDollar[3].bytes)
 		}
-	case 141:
+	case 187:
 		syntaxDollar = syntaxS[syntaxpt-3 : syntaxpt+1]
 		{
 			syntaxVAL.filterer = log.NewBytesLabelFilter(log.LabelFilterLesserThan, syntaxDollar[1].str, syntaxDollar[3].bytes)
 		}
-	case 142:
+	case 188:
 		syntaxDollar = syntaxS[syntaxpt-3 : syntaxpt+1]
 		{
 			syntaxVAL.filterer = log.NewBytesLabelFilter(log.LabelFilterLesserThanOrEqual, syntaxDollar[1].str, syntaxDollar[3].bytes)
 		}
-	case 143:
+	case 189:
 		syntaxDollar = syntaxS[syntaxpt-3 : syntaxpt+1]
 		{
 			syntaxVAL.filterer = log.NewBytesLabelFilter(log.LabelFilterNotEqual, syntaxDollar[1].str, syntaxDollar[3].bytes)
 		}
-	case 144:
+	case 190:
 		syntaxDollar = syntaxS[syntaxpt-3 : syntaxpt+1]
 		{
 			syntaxVAL.filterer = log.NewBytesLabelFilter(log.LabelFilterEqual, syntaxDollar[1].str, syntaxDollar[3].bytes)
 		}
-	case 145:
+	case 191:
 		syntaxDollar = syntaxS[syntaxpt-3 : syntaxpt+1]
 		{
 			syntaxVAL.filterer = log.NewBytesLabelFilter(log.LabelFilterEqual, syntaxDollar[1].str, syntaxDollar[3].bytes)
 		}
-	case 146:
+	case 192:
 		syntaxDollar = syntaxS[syntaxpt-3 : syntaxpt+1]
 		{
 			syntaxVAL.filterer = log.NewNumericLabelFilter(log.LabelFilterGreaterThan, syntaxDollar[1].str, syntaxDollar[3].literalExpr.Val)
 		}
-	case 147:
+	case 193:
 		syntaxDollar = syntaxS[syntaxpt-3 : syntaxpt+1]
 		{
 			syntaxVAL.filterer = log.NewNumericLabelFilter(log.LabelFilterGreaterThanOrEqual, syntaxDollar[1].str, syntaxDollar[3].literalExpr.Val)
 		}
-	case 148:
+	case 194:
 		syntaxDollar = syntaxS[syntaxpt-3 : syntaxpt+1]
 		{
 			syntaxVAL.filterer = log.NewNumericLabelFilter(log.LabelFilterLesserThan, syntaxDollar[1].str, syntaxDollar[3].literalExpr.Val)
 		}
-	case 149:
+	case 195:
 		syntaxDollar = syntaxS[syntaxpt-3 : syntaxpt+1]
 		{
 			syntaxVAL.filterer = log.NewNumericLabelFilter(log.LabelFilterLesserThanOrEqual, syntaxDollar[1].str, syntaxDollar[3].literalExpr.Val)
 		}
-	case 150:
+	case 196:
 		syntaxDollar = syntaxS[syntaxpt-3 : syntaxpt+1]
 		{
 			syntaxVAL.filterer = log.NewNumericLabelFilter(log.LabelFilterNotEqual, syntaxDollar[1].str, syntaxDollar[3].literalExpr.Val)
 		}
-	case 151:
+	case 197:
 		syntaxDollar = syntaxS[syntaxpt-3 : syntaxpt+1]
 		{
 			syntaxVAL.filterer = log.NewNumericLabelFilter(log.LabelFilterEqual, syntaxDollar[1].str, syntaxDollar[3].literalExpr.Val)
 		}
-	case 152:
+	case 198:
 		syntaxDollar = syntaxS[syntaxpt-3 : syntaxpt+1]
 		{
 			syntaxVAL.filterer = log.NewNumericLabelFilter(log.LabelFilterEqual, syntaxDollar[1].str, syntaxDollar[3].literalExpr.Val)
 		}
-	case 153:
+	case 199:
 		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
 		{
 			syntaxVAL.namedMatcher = log.NewNamedLabelMatcher(nil, syntaxDollar[1].str)
 		}
-	case 154:
+	case 200:
 		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
 		{
 			syntaxVAL.namedMatcher = log.NewNamedLabelMatcher(syntaxDollar[1].matcher, "")
 		}
-	case 155:
+	case 201:
 		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
 		{
 			syntaxVAL.namedMatchers = []log.NamedLabelMatcher{syntaxDollar[1].namedMatcher}
 		}
-	case 156:
+	case 202:
 		syntaxDollar = syntaxS[syntaxpt-3 : syntaxpt+1]
 		{
 			syntaxVAL.namedMatchers = append(syntaxDollar[1].namedMatchers, syntaxDollar[3].namedMatcher)
 		}
-	case 157:
+	case 203:
 		syntaxDollar = syntaxS[syntaxpt-2 : syntaxpt+1]
 		{
 			syntaxVAL.stage = newDropLabelsExpr(syntaxDollar[2].namedMatchers)
 		}
-	case 158:
+	case 204:
 		syntaxDollar = syntaxS[syntaxpt-2 : syntaxpt+1]
 		{
 			syntaxVAL.stage = newKeepLabelsExpr(syntaxDollar[2].namedMatchers)
 		}
-	case 159:
+	case 205:
+		syntaxDollar = syntaxS[syntaxpt-2 : syntaxpt+1]
+		{
+			syntaxVAL.stage = newStatsExpr(syntaxDollar[2].statsAggregations, nil)
+		}
+	case 206:
+		syntaxDollar = syntaxS[syntaxpt-4 : syntaxpt+1]
+		{
+			syntaxVAL.stage = newStatsExpr(syntaxDollar[2].statsAggregations, syntaxDollar[4].strs)
+		}
+	case 207:
+		syntaxDollar = syntaxS[syntaxpt-6 : syntaxpt+1]
+		{
+			syntaxVAL.stage = newStatsExpr(syntaxDollar[2].statsAggregations, syntaxDollar[5].strs)
+		}
+	case 208:
+		syntaxDollar = syntaxS[syntaxpt-9 : syntaxpt+1]
+		{
+			syntaxVAL.stage = newJoinExpr(syntaxDollar[4].strs, syntaxDollar[6].dur, syntaxDollar[8].logExpr)
+		}
+	case 209:
+		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
+		{
+			syntaxVAL.statsAggregations = []StatsAggregation{syntaxDollar[1].statsAggregation}
+		}
+	case 210:
+		syntaxDollar = syntaxS[syntaxpt-3 : syntaxpt+1]
+		{
+			syntaxVAL.statsAggregations = append(syntaxDollar[1].statsAggregations, syntaxDollar[3].statsAggregation)
+		}
+	case 211:
+		syntaxDollar = syntaxS[syntaxpt-3 : syntaxpt+1]
+		{
+			syntaxVAL.statsAggregation = StatsAggregation{Operation: OpTypeCount}
+		}
+	case 212:
+		syntaxDollar = syntaxS[syntaxpt-4 : syntaxpt+1]
+		{
+			syntaxVAL.statsAggregation = StatsAggregation{Operation: syntaxDollar[1].op, Label: syntaxDollar[3].str}
+		}
+	case 213:
+		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
+		{
+			syntaxVAL.op = OpTypeSum
+		}
+	case 214:
+		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
+		{
+			syntaxVAL.op = OpTypeAvg
+		}
+	case 215:
+		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
+		{
+			syntaxVAL.op = OpTypeMin
+		}
+	case 216:
+		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
+		{
+			syntaxVAL.op = OpTypeMax
+		}
+	case 217:
 		syntaxDollar = syntaxS[syntaxpt-4 : syntaxpt+1]
 		{
 			syntaxVAL.metricExpr = mustNewBinOpExpr("or", syntaxDollar[3].binOpts, syntaxDollar[1].expr, syntaxDollar[4].expr)
 		}
-	case 160:
+	case 218:
 		syntaxDollar = syntaxS[syntaxpt-4 : syntaxpt+1]
 		{
 			syntaxVAL.metricExpr = mustNewBinOpExpr("and", syntaxDollar[3].binOpts, syntaxDollar[1].expr, syntaxDollar[4].expr)
 		}
-	case 161:
+	case 219:
 		syntaxDollar = syntaxS[syntaxpt-4 : syntaxpt+1]
 		{
 			syntaxVAL.metricExpr = mustNewBinOpExpr("unless", syntaxDollar[3].binOpts, syntaxDollar[1].expr, syntaxDollar[4].expr)
 		}
-	case 162:
+	case 220:
 		syntaxDollar = syntaxS[syntaxpt-4 : syntaxpt+1]
 		{
 			syntaxVAL.metricExpr = mustNewBinOpExpr("+", syntaxDollar[3].binOpts, syntaxDollar[1].expr, syntaxDollar[4].expr)
 		}
-	case 163:
+	case 221:
 		syntaxDollar = syntaxS[syntaxpt-4 : syntaxpt+1]
 		{
 			syntaxVAL.metricExpr = mustNewBinOpExpr("-", syntaxDollar[3].binOpts, syntaxDollar[1].expr, syntaxDollar[4].expr)
 		}
-	case 164:
+	case 222:
 		syntaxDollar = syntaxS[syntaxpt-4 : syntaxpt+1]
 		{
 			syntaxVAL.metricExpr = mustNewBinOpExpr("*", syntaxDollar[3].binOpts, syntaxDollar[1].expr, syntaxDollar[4].expr)
 		}
-	case 165:
+	case 223:
 		syntaxDollar = syntaxS[syntaxpt-4 : syntaxpt+1]
 		{
 			syntaxVAL.metricExpr = mustNewBinOpExpr("/", syntaxDollar[3].binOpts, syntaxDollar[1].expr, syntaxDollar[4].expr)
 		}
-	case 166:
+	case 224:
 		syntaxDollar = syntaxS[syntaxpt-4 : syntaxpt+1]
 		{
 			syntaxVAL.metricExpr = mustNewBinOpExpr("%", syntaxDollar[3].binOpts, syntaxDollar[1].expr, syntaxDollar[4].expr)
 		}
-	case 167:
+	case 225:
 		syntaxDollar = syntaxS[syntaxpt-4 : syntaxpt+1]
 		{
 			syntaxVAL.metricExpr = mustNewBinOpExpr("^", syntaxDollar[3].binOpts, syntaxDollar[1].expr, syntaxDollar[4].expr)
 		}
-	case 168:
+	case 226:
 		syntaxDollar = syntaxS[syntaxpt-4 : syntaxpt+1]
 		{
 			syntaxVAL.metricExpr = mustNewBinOpExpr("==", syntaxDollar[3].binOpts, syntaxDollar[1].expr, syntaxDollar[4].expr)
 		}
-	case 169:
+	case 227:
 		syntaxDollar = syntaxS[syntaxpt-4 : syntaxpt+1]
 		{
 			syntaxVAL.metricExpr = mustNewBinOpExpr("!=", syntaxDollar[3].binOpts, syntaxDollar[1].expr, syntaxDollar[4].expr)
 		}
-	case 170:
+	case 228:
 		syntaxDollar = syntaxS[syntaxpt-4 : syntaxpt+1]
 		{
 			syntaxVAL.metricExpr = mustNewBinOpExpr(">", syntaxDollar[3].binOpts, syntaxDollar[1].expr, syntaxDollar[4].expr)
 		}
-	case 171:
+	case 229:
 		syntaxDollar = syntaxS[syntaxpt-4 : syntaxpt+1]
 		{
 			syntaxVAL.metricExpr = mustNewBinOpExpr(">=", syntaxDollar[3].binOpts, syntaxDollar[1].expr, syntaxDollar[4].expr)
 		}
-	case 172:
+	case 230:
 		syntaxDollar = syntaxS[syntaxpt-4 : syntaxpt+1]
 		{
 			syntaxVAL.metricExpr = mustNewBinOpExpr("<", syntaxDollar[3].binOpts, syntaxDollar[1].expr, syntaxDollar[4].expr)
 		}
-	case 173:
+	case 231:
 		syntaxDollar = syntaxS[syntaxpt-4 : syntaxpt+1]
 		{
 			syntaxVAL.metricExpr = mustNewBinOpExpr("<=", syntaxDollar[3].binOpts, syntaxDollar[1].expr, syntaxDollar[4].expr)
 		}
-	case 174:
+	case 232:
 		syntaxDollar = syntaxS[syntaxpt-0 : syntaxpt+1]
 		{
 			syntaxVAL.binOpts = &BinOpOptions{VectorMatching: &VectorMatching{Card: CardOneToOne}}
 		}
-	case 175:
+	case 233:
 		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
 		{
 			syntaxVAL.binOpts = &BinOpOptions{VectorMatching: &VectorMatching{Card: CardOneToOne}, ReturnBool: true}
 		}
-	case 176:
+	case 234:
 		syntaxDollar = syntaxS[syntaxpt-5 : syntaxpt+1]
 		{
 			syntaxVAL.binOpts = syntaxDollar[1].binOpts
 			syntaxVAL.binOpts.VectorMatching.On = true
 			syntaxVAL.binOpts.VectorMatching.MatchingLabels = syntaxDollar[4].strs
 		}
-	case 177:
+	case 235:
 		syntaxDollar = syntaxS[syntaxpt-4 : syntaxpt+1]
 		{
 			syntaxVAL.binOpts = syntaxDollar[1].binOpts
 			syntaxVAL.binOpts.VectorMatching.On = true
 		}
-	case 178:
+	case 236:
 		syntaxDollar = syntaxS[syntaxpt-5 : syntaxpt+1]
 		{
 			syntaxVAL.binOpts = syntaxDollar[1].binOpts
 			syntaxVAL.binOpts.VectorMatching.MatchingLabels = syntaxDollar[4].strs
 		}
-	case 179:
+	case 237:
 		syntaxDollar = syntaxS[syntaxpt-4 : syntaxpt+1]
 		{
 			syntaxVAL.binOpts = syntaxDollar[1].binOpts
 		}
-	case 180:
+	case 238:
 		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
 		{
 			syntaxVAL.binOpts = syntaxDollar[1].binOpts
 		}
-	case 181:
+	case 239:
 		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
 		{
 			syntaxVAL.binOpts = syntaxDollar[1].binOpts
 		}
-	case 182:
+	case 240:
 		syntaxDollar = syntaxS[syntaxpt-2 : syntaxpt+1]
 		{
 			syntaxVAL.binOpts = syntaxDollar[1].binOpts
 			syntaxVAL.binOpts.VectorMatching.Card = CardManyToOne
 		}
-	case 183:
+	case 241:
 		syntaxDollar = syntaxS[syntaxpt-4 : syntaxpt+1]
 		{
 			syntaxVAL.binOpts = syntaxDollar[1].binOpts
 			syntaxVAL.binOpts.VectorMatching.Card = CardManyToOne
 		}
-	case 184:
+	case 242:
 		syntaxDollar = syntaxS[syntaxpt-5 : syntaxpt+1]
 		{
 			syntaxVAL.binOpts = syntaxDollar[1].binOpts
 			syntaxVAL.binOpts.VectorMatching.Card = CardManyToOne
 			syntaxVAL.binOpts.VectorMatching.Include = syntaxDollar[4].strs
 		}
-	case 185:
+	case 243:
 		syntaxDollar = syntaxS[syntaxpt-2 : syntaxpt+1]
 		{
 			syntaxVAL.binOpts = syntaxDollar[1].binOpts
 			syntaxVAL.binOpts.VectorMatching.Card = CardOneToMany
 		}
-	case 186:
+	case 244:
 		syntaxDollar = syntaxS[syntaxpt-4 : syntaxpt+1]
 		{
 			syntaxVAL.binOpts = syntaxDollar[1].binOpts
 			syntaxVAL.binOpts.VectorMatching.Card = CardOneToMany
 		}
-	case 187:
+	case 245:
 		syntaxDollar = syntaxS[syntaxpt-5 : syntaxpt+1]
 		{
 			syntaxVAL.binOpts = syntaxDollar[1].binOpts
 			syntaxVAL.binOpts.VectorMatching.Card = CardOneToMany
 			syntaxVAL.binOpts.VectorMatching.Include = syntaxDollar[4].strs
 		}
-	case 188:
+	case 246:
 		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
 		{
 			syntaxVAL.literalExpr = mustNewLiteralExpr(syntaxDollar[1].str, false)
 		}
-	case 189:
+	case 247:
 		syntaxDollar = syntaxS[syntaxpt-2 : syntaxpt+1]
 		{
 			syntaxVAL.literalExpr = mustNewLiteralExpr(syntaxDollar[2].str, false)
 		}
-	case 190:
+	case 248:
 		syntaxDollar = syntaxS[syntaxpt-2 : syntaxpt+1]
 		{
 			syntaxVAL.literalExpr = mustNewLiteralExpr(syntaxDollar[2].str, true)
 		}
-	case 191:
+	case 249:
 		syntaxDollar = syntaxS[syntaxpt-4 : syntaxpt+1]
 		{
 			syntaxVAL.metricExpr = NewVectorExpr(syntaxDollar[3].str)
 		}
-	case 192:
+	case 250:
 		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
 		{
 			syntaxVAL.str = OpTypeVector
 		}
-	case 193:
+	case 251:
 		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
 		{
 			syntaxVAL.op = OpTypeSum
 		}
-	case 194:
+	case 252:
 		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
 		{
 			syntaxVAL.op = OpTypeAvg
 		}
-	case 195:
+	case 253:
 		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
 		{
 			syntaxVAL.op = OpTypeCount
 		}
-	case 196:
+	case 254:
 		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
 		{
 			syntaxVAL.op = OpTypeMax
 		}
-	case 197:
+	case 255:
 		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
 		{
 			syntaxVAL.op = OpTypeMin
 		}
-	case 198:
+	case 256:
 		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
 		{
 			syntaxVAL.op = OpTypeStddev
 		}
-	case 199:
+	case 257:
 		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
 		{
 			syntaxVAL.op = OpTypeStdvar
 		}
-	case 200:
+	case 258:
 		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
 		{
 			syntaxVAL.op = OpTypeBottomK
 		}
-	case 201:
+	case 259:
 		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
 		{
 			syntaxVAL.op = OpTypeTopK
 		}
-	case 202:
+	case 260:
 		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
 		{
 			syntaxVAL.op = OpTypeSort
 		}
-	case 203:
+	case 261:
 		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
 		{
 			syntaxVAL.op = OpTypeSortDesc
 		}
-	case 204:
+	case 262:
 		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
 		{
 			syntaxVAL.op = OpTypeApproxTopK
 		}
-	case 205:
+	case 263:
 		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
 		{
 			syntaxVAL.op = OpRangeTypeCount
 		}
-	case 206:
+	case 264:
 		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
 		{
 			syntaxVAL.op = OpRangeTypeRate
 		}
-	case 207:
+	case 265:
 		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
 		{
 			syntaxVAL.op = OpRangeTypeRateCounter
 		}
-	case 208:
+	case 266:
 		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
 		{
 			syntaxVAL.op = OpRangeTypeBytes
 		}
-	case 209:
+	case 267:
 		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
 		{
 			syntaxVAL.op = OpRangeTypeBytesRate
 		}
-	case 210:
+	case 268:
 		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
 		{
 			syntaxVAL.op = OpRangeTypeAvg
 		}
-	case 211:
+	case 269:
 		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
 		{
 			syntaxVAL.op = OpRangeTypeSum
 		}
-	case 212:
+	case 270:
 		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
 		{
 			syntaxVAL.op = OpRangeTypeMin
 		}
-	case 213:
+	case 271:
 		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
 		{
 			syntaxVAL.op = OpRangeTypeMax
 		}
-	case 214:
+	case 272:
 		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
 		{
 			syntaxVAL.op = OpRangeTypeStdvar
 		}
-	case 215:
+	case 273:
 		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
 		{
 			syntaxVAL.op = OpRangeTypeStddev
 		}
-	case 216:
+	case 274:
 		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
 		{
 			syntaxVAL.op = OpRangeTypeQuantile
 		}
-	case 217:
+	case 275:
+		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
+		{
+			syntaxVAL.op = OpRangeTypeHistogram
+		}
+	case 276:
 		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
 		{
 			syntaxVAL.op = OpRangeTypeFirst
 		}
-	case 218:
+	case 277:
 		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
 		{
 			syntaxVAL.op = OpRangeTypeLast
 		}
-	case 219:
+	case 278:
 		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
 		{
 			syntaxVAL.op = OpRangeTypeAbsent
 		}
-	case 220:
+	case 279:
+		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
+		{
+			syntaxVAL.op = OpRangeTypeDeriv
+		}
+	case 280:
+		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
+		{
+			syntaxVAL.op = OpFuncAbs
+		}
+	case 281:
+		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
+		{
+			syntaxVAL.op = OpFuncCeil
+		}
+	case 282:
+		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
+		{
+			syntaxVAL.op = OpFuncFloor
+		}
+	case 283:
+		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
+		{
+			syntaxVAL.op = OpFuncRound
+		}
+	case 284:
+		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
+		{
+			syntaxVAL.op = OpFuncLn
+		}
+	case 285:
+		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
+		{
+			syntaxVAL.op = OpFuncExp
+		}
+	case 286:
+		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
+		{
+			syntaxVAL.op = OpFuncClampMin
+		}
+	case 287:
+		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
+		{
+			syntaxVAL.op = OpFuncClampMax
+		}
+	case 288:
+		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
+		{
+			syntaxVAL.op = OpFuncTime
+		}
+	case 289:
+		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
+		{
+			syntaxVAL.op = OpFuncTimestamp
+		}
+	case 290:
+		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
+		{
+			syntaxVAL.op = OpFuncDayOfWeek
+		}
+	case 291:
+		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
+		{
+			syntaxVAL.op = OpFuncHour
+		}
+	case 292:
+		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
+		{
+			syntaxVAL.op = OpFuncAbsent
+		}
+	case 293:
 		syntaxDollar = syntaxS[syntaxpt-2 : syntaxpt+1]
 		{
 			syntaxVAL.offsetExpr = newOffsetExpr(syntaxDollar[2].dur)
 		}
-	case 221:
+	case 294:
 		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
 		{
 			syntaxVAL.strs = []string{syntaxDollar[1].str}
 		}
-	case 222:
+	case 295:
 		syntaxDollar = syntaxS[syntaxpt-3 : syntaxpt+1]
 		{
 			syntaxVAL.strs = append(syntaxDollar[1].strs, syntaxDollar[3].str)
 		}
-	case 223:
+	case 296:
 		syntaxDollar = syntaxS[syntaxpt-4 : syntaxpt+1]
 		{
 			syntaxVAL.grouping = &Grouping{Without: false, Groups: syntaxDollar[3].strs}
 		}
-	case 224:
+	case 297:
 		syntaxDollar = syntaxS[syntaxpt-4 : syntaxpt+1]
 		{
 			syntaxVAL.grouping = &Grouping{Without: true, Groups: syntaxDollar[3].strs}
 		}
-	case 225:
+	case 298:
 		syntaxDollar = syntaxS[syntaxpt-3 : syntaxpt+1]
 		{
 			syntaxVAL.grouping = &Grouping{Without: false, Groups: nil}
 		}
-	case 226:
+	case 299:
 		syntaxDollar = syntaxS[syntaxpt-3 : syntaxpt+1]
 		{
 			syntaxVAL.grouping = &Grouping{Without: true, Groups: nil}
 		}
-	case 227:
+	case 300:
 		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
 		{
 			syntaxVAL.metricExprs = []SampleExpr{syntaxDollar[1].metricExpr}
 		}
-	case 228:
+	case 301:
 		syntaxDollar = syntaxS[syntaxpt-3 : syntaxpt+1]
 		{
 			syntaxVAL.metricExprs = append(syntaxDollar[1].metricExprs, syntaxDollar[3].metricExpr)
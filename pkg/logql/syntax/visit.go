@@ -11,13 +11,16 @@ type RootVisitor interface {
 	VariantsExprVisitor
 
 	VisitLogRange(*LogRangeExpr)
+	VisitSubquery(*SubqueryExpr)
 }
 
 type SampleExprVisitor interface {
 	VisitBinOp(*BinOpExpr)
 	VisitVectorAggregation(*VectorAggregationExpr)
 	VisitRangeAggregation(*RangeAggregationExpr)
+	VisitSubqueryAggregation(*SubqueryAggregationExpr)
 	VisitLabelReplace(*LabelReplaceExpr)
+	VisitFunction(*FunctionExpr)
 	VisitLiteral(*LiteralExpr)
 	VisitVector(*VectorExpr)
 }
@@ -41,6 +44,14 @@ type StageExprVisitor interface {
 	VisitLineFmt(*LineFmtExpr)
 	VisitLogfmtExpressionParser(*LogfmtExpressionParserExpr)
 	VisitLogfmtParser(*LogfmtParserExpr)
+	VisitCSVParser(*CSVParserExpr)
+	VisitXMLExpressionParser(*XMLExpressionParserExpr)
+	VisitStats(*StatsExpr)
+	VisitJoin(*JoinExpr)
+	VisitGeoIP(*GeoIPExpr)
+	VisitSampling(*SamplingExpr)
+	VisitDedup(*DedupExpr)
+	VisitMacro(*MacroExpr)
 }
 
 type VariantsExprVisitor interface {
@@ -51,9 +62,14 @@ var _ RootVisitor = &DepthFirstTraversal{}
 
 type DepthFirstTraversal struct {
 	VisitBinOpFn                  func(v RootVisitor, e *BinOpExpr)
+	VisitCSVParserFn              func(v RootVisitor, e *CSVParserExpr)
 	VisitDecolorizeFn             func(v RootVisitor, e *DecolorizeExpr)
+	VisitDedupFn                  func(v RootVisitor, e *DedupExpr)
 	VisitDropLabelsFn             func(v RootVisitor, e *DropLabelsExpr)
+	VisitFunctionFn               func(v RootVisitor, e *FunctionExpr)
+	VisitGeoIPFn                  func(v RootVisitor, e *GeoIPExpr)
 	VisitJSONExpressionParserFn   func(v RootVisitor, e *JSONExpressionParserExpr)
+	VisitJoinFn                   func(v RootVisitor, e *JoinExpr)
 	VisitKeepLabelFn              func(v RootVisitor, e *KeepLabelsExpr)
 	VisitLabelFilterFn            func(v RootVisitor, e *LabelFilterExpr)
 	VisitLabelFmtFn               func(v RootVisitor, e *LabelFmtExpr)
@@ -63,14 +79,20 @@ type DepthFirstTraversal struct {
 	VisitLineFmtFn                func(v RootVisitor, e *LineFmtExpr)
 	VisitLiteralFn                func(v RootVisitor, e *LiteralExpr)
 	VisitLogRangeFn               func(v RootVisitor, e *LogRangeExpr)
+	VisitMacroFn                  func(v RootVisitor, e *MacroExpr)
 	VisitLogfmtExpressionParserFn func(v RootVisitor, e *LogfmtExpressionParserExpr)
 	VisitLogfmtParserFn           func(v RootVisitor, e *LogfmtParserExpr)
 	VisitMatchersFn               func(v RootVisitor, e *MatchersExpr)
 	VisitPipelineFn               func(v RootVisitor, e *PipelineExpr)
 	VisitRangeAggregationFn       func(v RootVisitor, e *RangeAggregationExpr)
+	VisitSamplingFn               func(v RootVisitor, e *SamplingExpr)
+	VisitStatsFn                  func(v RootVisitor, e *StatsExpr)
+	VisitSubqueryFn               func(v RootVisitor, e *SubqueryExpr)
+	VisitSubqueryAggregationFn    func(v RootVisitor, e *SubqueryAggregationExpr)
 	VisitVectorFn                 func(v RootVisitor, e *VectorExpr)
 	VisitVectorAggregationFn      func(v RootVisitor, e *VectorAggregationExpr)
 	VisitVariantsFn               func(v RootVisitor, e *MultiVariantExpr)
+	VisitXMLExpressionParserFn    func(v RootVisitor, e *XMLExpressionParserExpr)
 }
 
 // VisitBinOp implements RootVisitor.
@@ -86,6 +108,16 @@ func (v *DepthFirstTraversal) VisitBinOp(e *BinOpExpr) {
 	}
 }
 
+// VisitCSVParser implements RootVisitor.
+func (v *DepthFirstTraversal) VisitCSVParser(e *CSVParserExpr) {
+	if e == nil {
+		return
+	}
+	if v.VisitCSVParserFn != nil {
+		v.VisitCSVParserFn(v, e)
+	}
+}
+
 // VisitDecolorize implements RootVisitor.
 func (v *DepthFirstTraversal) VisitDecolorize(e *DecolorizeExpr) {
 	if e == nil {
@@ -96,6 +128,46 @@ func (v *DepthFirstTraversal) VisitDecolorize(e *DecolorizeExpr) {
 	}
 }
 
+// VisitGeoIP implements RootVisitor.
+func (v *DepthFirstTraversal) VisitGeoIP(e *GeoIPExpr) {
+	if e == nil {
+		return
+	}
+	if v.VisitGeoIPFn != nil {
+		v.VisitGeoIPFn(v, e)
+	}
+}
+
+// VisitSampling implements RootVisitor.
+func (v *DepthFirstTraversal) VisitSampling(e *SamplingExpr) {
+	if e == nil {
+		return
+	}
+	if v.VisitSamplingFn != nil {
+		v.VisitSamplingFn(v, e)
+	}
+}
+
+// VisitDedup implements RootVisitor.
+func (v *DepthFirstTraversal) VisitDedup(e *DedupExpr) {
+	if e == nil {
+		return
+	}
+	if v.VisitDedupFn != nil {
+		v.VisitDedupFn(v, e)
+	}
+}
+
+// VisitMacro implements RootVisitor.
+func (v *DepthFirstTraversal) VisitMacro(e *MacroExpr) {
+	if e == nil {
+		return
+	}
+	if v.VisitMacroFn != nil {
+		v.VisitMacroFn(v, e)
+	}
+}
+
 // VisitDropLabels implements RootVisitor.
 func (v *DepthFirstTraversal) VisitDropLabels(e *DropLabelsExpr) {
 	if e == nil {
@@ -106,6 +178,18 @@ func (v *DepthFirstTraversal) VisitDropLabels(e *DropLabelsExpr) {
 	}
 }
 
+// VisitFunction implements RootVisitor.
+func (v *DepthFirstTraversal) VisitFunction(e *FunctionExpr) {
+	if e == nil {
+		return
+	}
+	if v.VisitFunctionFn != nil {
+		v.VisitFunctionFn(v, e)
+	} else if e.Left != nil {
+		e.Left.Accept(v)
+	}
+}
+
 // VisitJSONExpressionParser implements RootVisitor.
 func (v *DepthFirstTraversal) VisitJSONExpressionParser(e *JSONExpressionParserExpr) {
 	if e == nil {
@@ -272,6 +356,52 @@ func (v *DepthFirstTraversal) VisitRangeAggregation(e *RangeAggregationExpr) {
 	}
 }
 
+// VisitStats implements RootVisitor.
+func (v *DepthFirstTraversal) VisitStats(e *StatsExpr) {
+	if e == nil {
+		return
+	}
+	if v.VisitStatsFn != nil {
+		v.VisitStatsFn(v, e)
+	}
+}
+
+// VisitJoin implements RootVisitor.
+func (v *DepthFirstTraversal) VisitJoin(e *JoinExpr) {
+	if e == nil {
+		return
+	}
+	if v.VisitJoinFn != nil {
+		v.VisitJoinFn(v, e)
+	} else if e.Right != nil {
+		e.Right.Accept(v)
+	}
+}
+
+// VisitSubquery implements RootVisitor.
+func (v *DepthFirstTraversal) VisitSubquery(e *SubqueryExpr) {
+	if e == nil {
+		return
+	}
+	if v.VisitSubqueryFn != nil {
+		v.VisitSubqueryFn(v, e)
+	} else {
+		e.Left.Accept(v)
+	}
+}
+
+// VisitSubqueryAggregation implements RootVisitor.
+func (v *DepthFirstTraversal) VisitSubqueryAggregation(e *SubqueryAggregationExpr) {
+	if e == nil {
+		return
+	}
+	if v.VisitSubqueryAggregationFn != nil {
+		v.VisitSubqueryAggregationFn(v, e)
+	} else {
+		e.Left.Accept(v)
+	}
+}
+
 // VisitVector implements RootVisitor.
 func (v *DepthFirstTraversal) VisitVector(e *VectorExpr) {
 	if e == nil {
@@ -309,3 +439,13 @@ func (v *DepthFirstTraversal) VisitVariants(e *MultiVariantExpr) {
 		}
 	}
 }
+
+// VisitXMLExpressionParser implements RootVisitor.
+func (v *DepthFirstTraversal) VisitXMLExpressionParser(e *XMLExpressionParserExpr) {
+	if e == nil {
+		return
+	}
+	if v.VisitXMLExpressionParserFn != nil {
+		v.VisitXMLExpressionParserFn(v, e)
+	}
+}
@@ -47,6 +47,37 @@ func TestDepthFirstTraversalVisitor(t *testing.T) {
 	require.Equal(t, expected, visited)
 }
 
+func TestDepthFirstTraversalVisitor_Join(t *testing.T) {
+	visited := [][2]string{}
+
+	visitor := &DepthFirstTraversal{
+		VisitLineFilterFn: func(_ RootVisitor, e *LineFilterExpr) {
+			visited = append(visited, [2]string{fmt.Sprintf("%T", e), e.String()})
+		},
+		VisitMatchersFn: func(_ RootVisitor, e *MatchersExpr) {
+			visited = append(visited, [2]string{fmt.Sprintf("%T", e), e.String()})
+		},
+	}
+
+	expected := [][2]string{
+		{"*syntax.MatchersExpr", `{app="foo"}`},
+		{"*syntax.LineFilterExpr", `|= "foo"`},
+		{"*syntax.MatchersExpr", `{app="bar"}`},
+		{"*syntax.LineFilterExpr", `|= "bar"`},
+	}
+
+	query := `{app="foo"} |= "foo" | join on (request_id) [30s] ({app="bar"} |= "bar")`
+	expr, err := ParseExpr(query)
+	require.NoError(t, err)
+	expr.Accept(visitor)
+	require.Equal(t, expected, visited)
+
+	// Extracting the line filters of a query ignores the joined query.
+	filters := ExtractLineFilters(expr)
+	require.Len(t, filters, 1)
+	require.Equal(t, `|= "foo"`, filters[0].String())
+}
+
 func TestDepthFirstTraversalVisitor_Variants(t *testing.T) {
 	visited := [][2]string{}
 
@@ -39,6 +39,11 @@ func Test_Walkable(t *testing.T) {
 			expr: `variants(count_over_time({job="foo"}[5m]), bytes_over_time({job="foo"}[5m])) of ({job="foo"}[5m])`,
 			want: 9,
 		},
+		{
+			desc: "join query",
+			expr: `{app="foo"} | json | join on (request_id) [30s] ({app="bar"} |= "error" | logfmt)`,
+			want: 8,
+		},
 	}
 	for _, test := range tests {
 		t.Run(test.desc, func(t *testing.T) {
@@ -84,6 +89,18 @@ func Test_AppendMatchers(t *testing.T) {
 				},
 			},
 		},
+		{
+			desc: "join query",
+			expr: `{app="foo"} | json | join on (request_id) [30s] ({app="bar"} |= "error")`,
+			want: `{app="foo", namespace="a"} | json | join on (request_id) [30s] ({app="bar", namespace="a"} |= "error")`,
+			matchers: []*labels.Matcher{
+				{
+					Name:  "namespace",
+					Type:  labels.MatchEqual,
+					Value: "a",
+				},
+			},
+		},
 	}
 	for _, test := range tests {
 		t.Run(test.desc, func(t *testing.T) {
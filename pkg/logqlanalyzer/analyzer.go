@@ -16,10 +16,20 @@ type logQLAnalyzer struct {
 }
 
 func (a logQLAnalyzer) analyze(query string, logs []string) (*Result, error) {
-	expr, err := syntax.ParseLogSelector(query, true)
+	return a.analyzeWithMacros(query, logs, nil, false)
+}
+
+// analyzeWithMacros analyzes a query that can reference the given macros. If
+// expand is true, the result contains the query with the macros expanded.
+func (a logQLAnalyzer) analyzeWithMacros(query string, logs []string, macros syntax.Macros, expand bool) (*Result, error) {
+	parsed, err := syntax.ParseExprWithMacros(query, macros)
 	if err != nil {
 		return nil, errors.Wrap(err, "invalid query")
 	}
+	expr, ok := parsed.(syntax.LogSelectorExpr)
+	if !ok {
+		return nil, errors.New("invalid query: only log selector is supported")
+	}
 	streamSelector, stages, err := a.extractExpressionParts(expr)
 	if err != nil {
 		return nil, errors.Wrap(err, "can not extract parts of expression")
@@ -34,6 +44,9 @@ func (a logQLAnalyzer) analyze(query string, logs []string) (*Result, error) {
 	}
 	analyzer := NewPipelineAnalyzer(pipeline, streamLabels)
 	response := &Result{StreamSelector: streamSelector, Stages: stages, Results: make([]LineResult, 0, len(logs))}
+	if expand {
+		response.ExpandedQuery = expr.String()
+	}
 	for _, line := range logs {
 		analysisRecords := analyzer.AnalyzeLine(line)
 		response.Results = append(response.Results, mapAllToLineResult(line, analysisRecords))
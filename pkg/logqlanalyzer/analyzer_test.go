@@ -129,3 +129,22 @@ func Test_logQLAnalyzer_analyze_expected_line_after_line_format_to_be_correct(t
 		FilteredOut:  false,
 	}, result.Results[1].StageRecords[1], "line is expected to be reformatted on this stage")
 }
+
+func Test_logQLAnalyzer_analyze_macros(t *testing.T) {
+	macros := map[string]string{"levels": `| logfmt | lvl="error"`}
+
+	result, err := logQLAnalyzer{}.analyzeWithMacros("{job=\"analyze\"} | @levels", []string{line1, line2}, macros, true)
+	require.NoError(t, err)
+	require.Equal(t, []string{"| logfmt", "| lvl=\"error\""}, result.Stages)
+	require.Equal(t, "{job=\"analyze\"} | logfmt | lvl=\"error\"", result.ExpandedQuery)
+	require.False(t, result.Results[0].StageRecords[1].FilteredOut)
+	records := result.Results[1].StageRecords
+	require.True(t, records[len(records)-1].FilteredOut)
+
+	result, err = logQLAnalyzer{}.analyzeWithMacros("{job=\"analyze\"} | @levels", []string{line1}, macros, false)
+	require.NoError(t, err)
+	require.Empty(t, result.ExpandedQuery)
+
+	_, err = logQLAnalyzer{}.analyze("{job=\"analyze\"} | @levels", []string{line1})
+	require.ErrorContains(t, err, "macro @levels is not defined")
+}
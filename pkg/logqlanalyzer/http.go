@@ -42,7 +42,7 @@ func (s *LogQLAnalyzeHandler) ServeHTTP(w http.ResponseWriter, req *http.Request
 		writeError(req.Context(), w, err, http.StatusBadRequest, "unable unmarshal request body")
 		return
 	}
-	result, err := s.analyzer.analyze(requestBody.Query, requestBody.Logs)
+	result, err := s.analyzer.analyzeWithMacros(requestBody.Query, requestBody.Logs, requestBody.Macros, requestBody.Expand)
 	if err != nil {
 		writeError(req.Context(), w, err, http.StatusBadRequest, "unable to analyze query")
 		return
@@ -67,12 +67,17 @@ func writeError(ctx context.Context, w http.ResponseWriter, err error, statusCod
 type Request struct {
 	Query string   `json:"query"`
 	Logs  []string `json:"logs"`
+	// Macros are the pipelines of the macros the query can reference.
+	Macros map[string]string `json:"macros,omitempty"`
+	// Expand adds the query with the macros expanded to the result.
+	Expand bool `json:"expand,omitempty"`
 }
 
 type Result struct {
 	StreamSelector string       `json:"stream_selector"`
 	Stages         []string     `json:"stages"`
 	Results        []LineResult `json:"results"`
+	ExpandedQuery  string       `json:"expanded_query,omitempty"`
 }
 
 type LineResult struct {
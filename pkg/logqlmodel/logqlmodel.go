@@ -12,6 +12,9 @@ import (
 // ValueTypeStreams promql.ValueType for log streams
 const ValueTypeStreams = "streams"
 
+// ValueTypeTable promql.ValueType for tabular results of the stats stage
+const ValueTypeTable = "table"
+
 // PackedEntryKey is a special JSON key used by the pack promtail stage and unpack parser
 const PackedEntryKey = "_entry"
 
@@ -47,3 +50,19 @@ func (streams Streams) Lines() int64 {
 	}
 	return res
 }
+
+// Table is promql.Value holding the result of a stats stage: one row per
+// group, one column per group label and aggregation. Values are stored as
+// strings, and missing values are empty strings.
+type Table struct {
+	Columns []string
+	Rows    [][]string
+}
+
+// Type implements `promql.Value` and `parser.Value`
+func (Table) Type() parser.ValueType { return ValueTypeTable }
+
+// String implements `promql.Value` and `parser.Value`
+func (Table) String() string {
+	return ""
+}
@@ -3,12 +3,19 @@ package loki
 import (
 	"encoding/json"
 	"net/http"
+	"strconv"
+
+	"github.com/grafana/dskit/tenant"
 
 	"github.com/grafana/loki/v3/pkg/logql/syntax"
+	"github.com/grafana/loki/v3/pkg/querier/macros"
 	"github.com/grafana/loki/v3/pkg/util/server"
 )
 
-func formatQueryHandler() http.HandlerFunc {
+// formatQueryHandler formats the query parameter. If a store is given, the
+// macros of the query are validated against the macros of the tenant, and
+// expanded if the expand parameter is true.
+func formatQueryHandler(store *macros.Store) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		var (
 			statusCode = http.StatusOK
@@ -17,7 +24,7 @@ func formatQueryHandler() http.HandlerFunc {
 			errStr     string
 		)
 
-		expr, err := syntax.ParseExpr(r.FormValue("query"))
+		expr, err := parseFormatQuery(r, store)
 		if err != nil {
 			statusCode = http.StatusBadRequest
 			status = "invalid-query"
@@ -44,6 +51,31 @@ func formatQueryHandler() http.HandlerFunc {
 	}
 }
 
+func parseFormatQuery(r *http.Request, store *macros.Store) (syntax.Expr, error) {
+	query := r.FormValue("query")
+	if store == nil {
+		return syntax.ParseExpr(query)
+	}
+
+	tenantID, err := tenant.TenantID(r.Context())
+	if err != nil {
+		return nil, err
+	}
+	tenantMacros, err := store.Macros(r.Context(), tenantID)
+	if err != nil {
+		return nil, err
+	}
+	expr, err := syntax.ParseExprWithMacros(query, tenantMacros)
+	if err != nil {
+		return nil, err
+	}
+	if expand, _ := strconv.ParseBool(r.FormValue("expand")); expand {
+		return expr, nil
+	}
+	// Format the query as written, the expanded query is valid.
+	return syntax.ParseExprWithoutValidation(query)
+}
+
 type FormatQueryResponse struct {
 	Status string `json:"status"`
 	Data   string `json:"data,omitempty"`
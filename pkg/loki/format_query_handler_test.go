@@ -1,14 +1,19 @@
 package loki
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
+	"github.com/grafana/dskit/user"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/v3/pkg/querier/macros"
+	"github.com/grafana/loki/v3/pkg/storage/chunk/client/testutils"
 )
 
 func Test_formatQueryHandlerResponse(t *testing.T) {
@@ -42,7 +47,63 @@ func Test_formatQueryHandlerResponse(t *testing.T) {
 
 			w := httptest.NewRecorder()
 
-			formatQueryHandler()(w, req)
+			formatQueryHandler(nil)(w, req)
+
+			var got FormatQueryResponse
+
+			err = json.NewDecoder(w.Body).Decode(&got)
+			require.NoError(t, err)
+
+			assert.Equal(t, tc.expected, got)
+		})
+	}
+}
+
+func Test_formatQueryHandlerMacros(t *testing.T) {
+	store := macros.NewStore(testutils.NewInMemoryObjectClient(), 0)
+	_, err := store.Put(context.Background(), "fake", "access_log", `| json | status >= 500`, 0)
+	require.NoError(t, err)
+
+	cases := []struct {
+		name     string
+		query    string
+		expected FormatQueryResponse
+	}{
+		{
+			name:  "macro",
+			query: `{foo="bar"} | @access_log`,
+			expected: FormatQueryResponse{
+				Status: "success",
+				Data:   `{foo="bar"} | @access_log`,
+			},
+		},
+		{
+			name:  "expand",
+			query: `{foo="bar"} | @access_log&expand=true`,
+			expected: FormatQueryResponse{
+				Status: "success",
+				Data:   `{foo="bar"} | json | status>=500`,
+			},
+		},
+		{
+			name:  "unknown macro",
+			query: `{foo="bar"} | @unknown`,
+			expected: FormatQueryResponse{
+				Status: "invalid-query",
+				Err:    "parse error : macro @unknown is not defined",
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			req, err := http.NewRequest("GET", fmt.Sprintf("http://localhost:808?query=%s", tc.query), nil)
+			require.NoError(t, err)
+			req = req.WithContext(user.InjectOrgID(req.Context(), "fake"))
+
+			w := httptest.NewRecorder()
+
+			formatQueryHandler(store)(w, req)
 
 			var got FormatQueryResponse
 
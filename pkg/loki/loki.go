@@ -53,6 +53,7 @@ import (
 	"github.com/grafana/loki/v3/pkg/lokifrontend/frontend/transport"
 	"github.com/grafana/loki/v3/pkg/pattern"
 	"github.com/grafana/loki/v3/pkg/querier"
+	"github.com/grafana/loki/v3/pkg/querier/macros"
 	"github.com/grafana/loki/v3/pkg/querier/queryrange"
 	"github.com/grafana/loki/v3/pkg/querier/queryrange/queryrangebase"
 	"github.com/grafana/loki/v3/pkg/querier/worker"
@@ -421,6 +422,7 @@ type Loki struct {
 	Querier                             querier.Querier
 	cacheGenerationLoader               queryrangebase.CacheGenNumberLoader
 	querierAPI                          *querier.QuerierAPI
+	queryMacros                         *macros.Store
 	ingesterQuerier                     *querier.IngesterQuerier
 	Store                               storage.Store
 	BloomStore                          bloomshipper.Store
@@ -593,7 +595,12 @@ func (t *Loki) Run(opts RunOpts) error {
 	t.Server.HTTP.Path("/loki/api/v1/status/buildinfo").Methods("GET").HandlerFunc(versionHandler())
 
 	t.Server.HTTP.Path("/debug/fgprof").Methods("GET", "POST").Handler(fgprof.Handler())
-	t.Server.HTTP.Path("/loki/api/v1/format_query").Methods("GET", "POST").HandlerFunc(formatQueryHandler())
+	if t.queryMacros != nil {
+		// The macros of the query are looked up for the tenant of the request.
+		t.Server.HTTP.Path("/loki/api/v1/format_query").Methods("GET", "POST").Handler(t.HTTPAuthMiddleware.Wrap(formatQueryHandler(t.queryMacros)))
+	} else {
+		t.Server.HTTP.Path("/loki/api/v1/format_query").Methods("GET", "POST").HandlerFunc(formatQueryHandler(nil))
+	}
 
 	// Let's listen for events from this manager, and log them.
 	logHook := func(msg, key string) func() {
@@ -784,6 +791,8 @@ func (t *Loki) setupModuleManager() error {
 	mm.RegisterModule(QuerySchedulerRing, t.initQuerySchedulerRing, modules.UserInvisibleModule)
 	mm.RegisterModule(Analytics, t.initAnalytics, modules.UserInvisibleModule)
 	mm.RegisterModule(CacheGenerationLoader, t.initCacheGenerationLoader, modules.UserInvisibleModule)
+	mm.RegisterModule(GeoIP, t.initGeoIP, modules.UserInvisibleModule)
+	mm.RegisterModule(QueryMacros, t.initQueryMacros, modules.UserInvisibleModule)
 	mm.RegisterModule(PatternRingClient, t.initPatternRingClient, modules.UserInvisibleModule)
 	mm.RegisterModule(PatternIngesterTee, t.initPatternIngesterTee, modules.UserInvisibleModule)
 	mm.RegisterModule(PatternIngester, t.initPatternIngester)
@@ -817,16 +826,16 @@ func (t *Loki) setupModuleManager() error {
 		IngestLimitsFrontend:         {IngestLimitsRing, Overrides, Server, MemberlistKV},
 		IngestLimitsFrontendRing:     {RuntimeConfig, Server, MemberlistKV},
 		Store:                        {Overrides, IndexGatewayRing},
-		Ingester:                     {Store, Server, MemberlistKV, TenantConfigs, Analytics, PartitionRing, UIRing},
-		Querier:                      {Store, Ring, Server, IngesterQuerier, PatternRingClient, Overrides, Analytics, CacheGenerationLoader, QuerySchedulerRing, UIRing},
+		Ingester:                     {Store, Server, MemberlistKV, TenantConfigs, Analytics, PartitionRing, UIRing, GeoIP},
+		Querier:                      {Store, Ring, Server, IngesterQuerier, PatternRingClient, Overrides, Analytics, CacheGenerationLoader, QuerySchedulerRing, UIRing, GeoIP, QueryMacros},
 		QueryFrontendTripperware:     {Server, Overrides, TenantConfigs},
-		QueryFrontend:                {QueryFrontendTripperware, Analytics, CacheGenerationLoader, QuerySchedulerRing, UIRing},
+		QueryFrontend:                {QueryFrontendTripperware, Analytics, CacheGenerationLoader, QuerySchedulerRing, UIRing, QueryMacros},
 		QueryScheduler:               {Server, Overrides, MemberlistKV, Analytics, QuerySchedulerRing, UIRing},
 		QueryEngine:                  {QueryEngineScheduler},
 		QueryEngineScheduler:         {Server, Overrides, TenantConfigs, Analytics},
 		QueryEngineWorker:            {QueryEngineScheduler},
-		Ruler:                        {Ring, Server, RulerStorage, RuleEvaluator, Overrides, TenantConfigs, Analytics, UIRing},
-		RuleEvaluator:                {Ring, Server, Store, IngesterQuerier, Overrides, TenantConfigs, Analytics},
+		Ruler:                        {Ring, Server, RulerStorage, RuleEvaluator, Overrides, TenantConfigs, Analytics, UIRing, QueryMacros},
+		RuleEvaluator:                {Ring, Server, Store, IngesterQuerier, Overrides, TenantConfigs, Analytics, GeoIP},
 		TableManager:                 {Server, Analytics, UIRing},
 		Compactor:                    {Server, Overrides, MemberlistKV, Analytics, UIRing},
 		IndexGateway:                 {Server, Store, BloomStore, IndexGatewayRing, IndexGatewayInterceptors, Analytics, UIRing},
@@ -848,6 +857,8 @@ func (t *Loki) setupModuleManager() error {
 		DataObjConsumer:              {MemberlistKV, ScratchStore, PartitionRing, Server, UI},
 		DataObjIndexBuilder:          {ScratchStore, Server, UIRing},
 		ScratchStore:                 {},
+		GeoIP:                        {},
+		QueryMacros:                  {Server},
 
 		Read:    {QueryFrontend, Querier},
 		Write:   {Ingester, Distributor, PatternIngester},
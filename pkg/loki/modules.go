@@ -59,6 +59,7 @@ import (
 	limitsproto "github.com/grafana/loki/v3/pkg/limits/proto"
 	"github.com/grafana/loki/v3/pkg/logproto"
 	"github.com/grafana/loki/v3/pkg/logql"
+	logqllog "github.com/grafana/loki/v3/pkg/logql/log"
 	"github.com/grafana/loki/v3/pkg/logqlmodel/stats"
 	"github.com/grafana/loki/v3/pkg/lokifrontend/frontend"
 	"github.com/grafana/loki/v3/pkg/lokifrontend/frontend/transport"
@@ -66,6 +67,7 @@ import (
 	"github.com/grafana/loki/v3/pkg/lokifrontend/frontend/v2/frontendv2pb"
 	"github.com/grafana/loki/v3/pkg/pattern"
 	"github.com/grafana/loki/v3/pkg/querier"
+	"github.com/grafana/loki/v3/pkg/querier/macros"
 	"github.com/grafana/loki/v3/pkg/querier/queryrange"
 	"github.com/grafana/loki/v3/pkg/querier/queryrange/queryrangebase"
 	"github.com/grafana/loki/v3/pkg/querier/tail"
@@ -151,6 +153,8 @@ const (
 	MemberlistKV                 = "memberlist-kv"
 	Analytics                    = "analytics"
 	CacheGenerationLoader        = "cache-generation-loader"
+	GeoIP                        = "geoip"
+	QueryMacros                  = "query-macros"
 	PartitionRing                = "partition-ring"
 	DataObjExplorer              = "dataobj-explorer"
 	DataObjConsumer              = "dataobj-consumer"
@@ -646,6 +650,10 @@ func (t *Loki) initQuerier() (services.Service, error) {
 		)
 	}
 
+	if t.queryMacros != nil {
+		toMerge = append(toMerge, macros.NewExpandMiddleware(t.queryMacros))
+	}
+
 	httpMiddleware := middleware.Merge(toMerge...)
 
 	handler := querier.NewQuerierHandler(t.querierAPI)
@@ -1183,6 +1191,36 @@ func (t *Loki) initQueryFrontendMiddleware() (_ services.Service, err error) {
 	return services.NewIdleService(nil, nil), nil
 }
 
+func (t *Loki) initGeoIP() (services.Service, error) {
+	if err := logqllog.LoadGeoIPDatabases(t.Cfg.Querier.Engine.GeoIP); err != nil {
+		return nil, err
+	}
+	return nil, nil
+}
+
+func (t *Loki) initQueryMacros() (services.Service, error) {
+	if !t.Cfg.Querier.Macros.Enabled {
+		return nil, nil
+	}
+
+	var (
+		objectClient client.ObjectClient
+		err          error
+	)
+	if t.Cfg.StorageConfig.UseThanosObjstore {
+		objectClient, err = base_ruler.NewRuleObjectClient(context.Background(), t.Cfg.RulerStorage, "query-macros", util_log.Logger)
+	} else {
+		objectClient, err = base_ruler.NewLegacyRuleObjectClient(t.Cfg.Ruler.StoreConfig, t.Cfg.StorageConfig.Hedging, t.ClientMetrics)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("creating the storage of query macros: %w", err)
+	}
+
+	t.queryMacros = macros.NewStore(objectClient, t.Cfg.Querier.Macros.CacheTTL)
+	macros.NewAPI(t.queryMacros).RegisterRoutes(t.Server.HTTP, t.HTTPAuthMiddleware.Wrap)
+	return nil, nil
+}
+
 func (t *Loki) initCacheGenerationLoader() (_ services.Service, err error) {
 	var client generationnumber.CacheGenClient
 	if t.supportIndexDeleteRequest() {
@@ -1294,6 +1332,10 @@ func (t *Loki) initQueryFrontend() (_ services.Service, err error) {
 		toMerge = append(toMerge, querylimits.NewQueryLimitsMiddleware(logger))
 	}
 
+	if t.queryMacros != nil {
+		toMerge = append(toMerge, macros.NewExpandMiddleware(t.queryMacros))
+	}
+
 	frontendHandler = middleware.Merge(toMerge...).Wrap(frontendHandler)
 
 	var defaultHandler http.Handler
@@ -1525,9 +1567,16 @@ func (t *Loki) initRuler() (_ services.Service, err error) {
 
 	t.Cfg.Ruler.Ring.ListenPort = t.Cfg.Server.GRPCListenPort
 
+	// The macros of the rules are expanded when they are loaded.
+	var ruleMacros ruler.MacroStore
+	if t.queryMacros != nil {
+		ruleMacros = t.queryMacros
+	}
+
 	t.ruler, err = ruler.NewRuler(
 		t.Cfg.Ruler,
 		t.ruleEvaluator,
+		ruleMacros,
 		prometheus.DefaultRegisterer,
 		util_log.Logger,
 		t.RulerStorage,
@@ -15,7 +15,11 @@ import (
 	"github.com/grafana/loki/v3/pkg/dataobj/metastore"
 	"github.com/grafana/loki/v3/pkg/loghttp"
 	"github.com/grafana/loki/v3/pkg/logproto"
+	"github.com/grafana/loki/v3/pkg/logql"
+	"github.com/grafana/loki/v3/pkg/logql/syntax"
 	"github.com/grafana/loki/v3/pkg/logqlmodel"
+	"github.com/grafana/loki/v3/pkg/querier/plan"
+	"github.com/grafana/loki/v3/pkg/querier/queryrange"
 	"github.com/grafana/loki/v3/pkg/util/constants"
 	"github.com/grafana/loki/v3/pkg/validation"
 
@@ -54,6 +58,72 @@ func TestInstantQueryHandler(t *testing.T) {
 	})
 }
 
+// tableEngine is a query engine returning the same table for every query.
+type tableEngine struct {
+	table logqlmodel.Table
+}
+
+func (e tableEngine) Query(_ logql.Params) logql.Query { return e }
+
+func (e tableEngine) Exec(_ context.Context) (logqlmodel.Result, error) {
+	return logqlmodel.Result{Data: e.table}, nil
+}
+
+func TestRangeQueryHandler_StatsTable(t *testing.T) {
+	table := logqlmodel.Table{
+		Columns: []string{"status", "count()"},
+		Rows:    [][]string{{"200", "2"}, {"500", "1"}},
+	}
+	api := setupAPI(t, newQuerierMock(), false)
+	api.engineV1 = tableEngine{table: table}
+
+	t.Run("json", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/loki/api/v1/query_range?"+url.Values{
+			"query": []string{`{app="foo"} | logfmt | stats count() by (status)`},
+			"start": []string{"0"},
+			"end":   []string{"10"},
+		}.Encode(), nil)
+		req.Header.Set("X-Scope-OrgID", "test-org")
+		res := makeRequest(t, buildHandler(api), req)
+		require.Equalf(t, http.StatusOK, res.Code, "response was not HTTP OK: %s", res.Body.String())
+
+		var resp loghttp.QueryResponse
+		require.NoError(t, resp.UnmarshalJSON(res.Body.Bytes()))
+		require.Equal(t, loghttp.ResultType(loghttp.ResultTypeTable), resp.Data.ResultType)
+		require.Equal(t, loghttp.Table{Columns: table.Columns, Rows: table.Rows}, resp.Data.Result)
+	})
+
+	t.Run("protobuf", func(t *testing.T) {
+		req := &queryrange.LokiRequest{
+			Query:   `{app="foo"} | logfmt | stats count() by (status)`,
+			Limit:   100,
+			StartTs: time.Unix(0, 0),
+			EndTs:   time.Unix(10, 0),
+			Path:    "/loki/api/v1/query_range",
+			Plan: &plan.QueryPlan{
+				AST: syntax.MustParseExpr(`{app="foo"} | logfmt | stats count() by (status)`),
+			},
+		}
+		ctx := user.InjectOrgID(context.Background(), "test-org")
+		res, err := NewQuerierHandler(api).Do(ctx, req)
+		require.NoError(t, err)
+
+		// The response is sent to the frontend as protobuf.
+		wrapped, err := queryrange.QueryResponseWrap(res)
+		require.NoError(t, err)
+		buf, err := wrapped.Marshal()
+		require.NoError(t, err)
+		var unmarshalled queryrange.QueryResponse
+		require.NoError(t, unmarshalled.Unmarshal(buf))
+		res, err = queryrange.QueryResponseUnwrap(&unmarshalled)
+		require.NoError(t, err)
+
+		result, err := queryrange.ResponseToResult(res)
+		require.NoError(t, err)
+		require.Equal(t, table, result.Data)
+	})
+}
+
 type slowConnectionSimulator struct {
 	sleepFor   time.Duration
 	deadline   time.Duration
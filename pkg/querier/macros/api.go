@@ -0,0 +1,171 @@
+package macros
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/grafana/dskit/httpgrpc"
+	"github.com/grafana/dskit/tenant"
+
+	serverutil "github.com/grafana/loki/v3/pkg/util/server"
+)
+
+// API serves the HTTP endpoints to manage the macros of a tenant.
+type API struct {
+	store *Store
+}
+
+// NewAPI returns a new API for the given store.
+func NewAPI(store *Store) *API {
+	return &API{store: store}
+}
+
+// RegisterRoutes registers the routes of the API. The handlers expect the
+// tenant in the request context, so wrap them with the auth middleware.
+func (a *API) RegisterRoutes(router *mux.Router, wrap func(http.Handler) http.Handler) {
+	router.Path("/loki/api/v1/macros").Methods("GET").Handler(wrap(http.HandlerFunc(a.ListMacros)))
+	router.Path("/loki/api/v1/macros/{name}").Methods("GET").Handler(wrap(http.HandlerFunc(a.GetMacro)))
+	router.Path("/loki/api/v1/macros/{name}").Methods("POST", "PUT").Handler(wrap(http.HandlerFunc(a.PutMacro)))
+	router.Path("/loki/api/v1/macros/{name}").Methods("DELETE").Handler(wrap(http.HandlerFunc(a.DeleteMacro)))
+	router.Path("/loki/api/v1/macros/{name}/versions").Methods("GET").Handler(wrap(http.HandlerFunc(a.ListVersions)))
+}
+
+// PutMacroRequest is the body of a request to create or update a macro.
+type PutMacroRequest struct {
+	Pipeline string `json:"pipeline"`
+	// Version is the latest version the update is based on. If set, the
+	// update fails if the macro has been updated in the meantime.
+	Version int `json:"version,omitempty"`
+}
+
+type response struct {
+	Status string      `json:"status"`
+	Data   interface{} `json:"data,omitempty"`
+}
+
+// ListMacros returns the latest version of all macros of the tenant.
+func (a *API) ListMacros(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := tenant.TenantID(r.Context())
+	if err != nil {
+		serverutil.WriteError(err, w)
+		return
+	}
+
+	macros, err := a.store.List(r.Context(), tenantID)
+	if err != nil {
+		writeError(err, w)
+		return
+	}
+	writeResponse(w, http.StatusOK, macros)
+}
+
+// GetMacro returns the latest version of a macro, or the version given by the
+// version parameter.
+func (a *API) GetMacro(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := tenant.TenantID(r.Context())
+	if err != nil {
+		serverutil.WriteError(err, w)
+		return
+	}
+
+	var (
+		name  = mux.Vars(r)["name"]
+		macro *Macro
+	)
+	if v := r.FormValue("version"); v != "" {
+		version, err := strconv.Atoi(v)
+		if err != nil || version <= 0 {
+			serverutil.WriteError(httpgrpc.Errorf(http.StatusBadRequest, "invalid version %q, must be a positive integer", v), w)
+			return
+		}
+		macro, err = a.store.GetVersion(r.Context(), tenantID, name, version)
+		if err != nil {
+			writeError(err, w)
+			return
+		}
+	} else {
+		macro, err = a.store.Get(r.Context(), tenantID, name)
+		if err != nil {
+			writeError(err, w)
+			return
+		}
+	}
+	writeResponse(w, http.StatusOK, macro)
+}
+
+// ListVersions returns all versions of a macro.
+func (a *API) ListVersions(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := tenant.TenantID(r.Context())
+	if err != nil {
+		serverutil.WriteError(err, w)
+		return
+	}
+
+	versions, err := a.store.Versions(r.Context(), tenantID, mux.Vars(r)["name"])
+	if err != nil {
+		writeError(err, w)
+		return
+	}
+	writeResponse(w, http.StatusOK, versions)
+}
+
+// PutMacro validates a macro and stores it as a new version.
+func (a *API) PutMacro(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := tenant.TenantID(r.Context())
+	if err != nil {
+		serverutil.WriteError(err, w)
+		return
+	}
+
+	var req PutMacroRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		serverutil.WriteError(httpgrpc.Errorf(http.StatusBadRequest, "invalid request body: %s", err), w)
+		return
+	}
+
+	macro, err := a.store.Put(r.Context(), tenantID, mux.Vars(r)["name"], req.Pipeline, req.Version)
+	if err != nil {
+		writeError(err, w)
+		return
+	}
+	writeResponse(w, http.StatusOK, macro)
+}
+
+// DeleteMacro deletes all versions of a macro.
+func (a *API) DeleteMacro(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := tenant.TenantID(r.Context())
+	if err != nil {
+		serverutil.WriteError(err, w)
+		return
+	}
+
+	if err := a.store.Delete(r.Context(), tenantID, mux.Vars(r)["name"]); err != nil {
+		writeError(err, w)
+		return
+	}
+	writeResponse(w, http.StatusOK, nil)
+}
+
+func writeResponse(w http.ResponseWriter, statusCode int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(statusCode)
+	if err := json.NewEncoder(w).Encode(response{Status: "success", Data: data}); err != nil {
+		serverutil.WriteError(err, w)
+	}
+}
+
+// writeError writes the errors of the store with the matching status code.
+func writeError(err error, w http.ResponseWriter) {
+	switch {
+	case errors.Is(err, ErrMacroNotFound):
+		err = httpgrpc.Errorf(http.StatusNotFound, "%s", err)
+	case errors.Is(err, ErrVersionConflict):
+		err = httpgrpc.Errorf(http.StatusConflict, "%s", err)
+	case errors.Is(err, ErrInvalidMacro):
+		err = httpgrpc.Errorf(http.StatusBadRequest, "%s", err)
+	}
+	serverutil.WriteError(err, w)
+}
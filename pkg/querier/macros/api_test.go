@@ -0,0 +1,125 @@
+package macros
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/grafana/dskit/user"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPI(t *testing.T) {
+	store, _ := newTestStore(t, 0)
+	router := mux.NewRouter()
+	NewAPI(store).RegisterRoutes(router, func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, r.WithContext(user.InjectOrgID(r.Context(), "fake")))
+		})
+	})
+
+	do := func(method, path, body string) (int, string) {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Code, w.Body.String()
+	}
+
+	code, body := do("POST", "/loki/api/v1/macros/access_log", `{"pipeline": "| json"}`)
+	require.Equal(t, http.StatusOK, code, body)
+	code, body = do("POST", "/loki/api/v1/macros/access_log", `{"pipeline": "| json | status >= 500", "version": 1}`)
+	require.Equal(t, http.StatusOK, code, body)
+
+	code, body = do("POST", "/loki/api/v1/macros/access_log", `{"pipeline": "| logfmt", "version": 1}`)
+	require.Equal(t, http.StatusConflict, code, body)
+	code, body = do("POST", "/loki/api/v1/macros/access_log", `{"pipeline": "| json |"}`)
+	require.Equal(t, http.StatusBadRequest, code, body)
+	require.Contains(t, body, "invalid macro")
+	code, body = do("POST", "/loki/api/v1/macros/access_log", `{`)
+	require.Equal(t, http.StatusBadRequest, code, body)
+
+	var resp struct {
+		Status string `json:"status"`
+		Data   *Macro `json:"data"`
+	}
+	code, body = do("GET", "/loki/api/v1/macros/access_log", "")
+	require.Equal(t, http.StatusOK, code, body)
+	require.NoError(t, json.Unmarshal([]byte(body), &resp))
+	require.Equal(t, "success", resp.Status)
+	require.Equal(t, `| json | status >= 500`, resp.Data.Pipeline)
+	require.Equal(t, 2, resp.Data.Version)
+
+	code, body = do("GET", "/loki/api/v1/macros/access_log?version=1", "")
+	require.Equal(t, http.StatusOK, code, body)
+	require.NoError(t, json.Unmarshal([]byte(body), &resp))
+	require.Equal(t, `| json`, resp.Data.Pipeline)
+
+	code, body = do("GET", "/loki/api/v1/macros/access_log?version=3", "")
+	require.Equal(t, http.StatusNotFound, code, body)
+	code, body = do("GET", "/loki/api/v1/macros/access_log?version=latest", "")
+	require.Equal(t, http.StatusBadRequest, code, body)
+
+	var list struct {
+		Data []*Macro `json:"data"`
+	}
+	code, body = do("GET", "/loki/api/v1/macros/access_log/versions", "")
+	require.Equal(t, http.StatusOK, code, body)
+	require.NoError(t, json.Unmarshal([]byte(body), &list))
+	require.Len(t, list.Data, 2)
+
+	code, body = do("GET", "/loki/api/v1/macros", "")
+	require.Equal(t, http.StatusOK, code, body)
+	require.NoError(t, json.Unmarshal([]byte(body), &list))
+	require.Len(t, list.Data, 1)
+	require.Equal(t, "access_log", list.Data[0].Name)
+
+	code, body = do("DELETE", "/loki/api/v1/macros/access_log", "")
+	require.Equal(t, http.StatusOK, code, body)
+	code, body = do("GET", "/loki/api/v1/macros/access_log", "")
+	require.Equal(t, http.StatusNotFound, code, body)
+	code, body = do("DELETE", "/loki/api/v1/macros/access_log", "")
+	require.Equal(t, http.StatusNotFound, code, body)
+}
+
+func TestExpandMiddleware(t *testing.T) {
+	store, _ := newTestStore(t, 0)
+	_, err := store.Put(t.Context(), "fake", "access_log", `| json | status >= 500`, 0)
+	require.NoError(t, err)
+	_, err = store.Put(t.Context(), "other", "access_log", `| logfmt`, 0)
+	require.NoError(t, err)
+
+	var got string
+	handler := NewExpandMiddleware(store).Wrap(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		got = r.Form.Get("query")
+	}))
+
+	for _, tc := range []struct {
+		orgID, query, exp string
+		code              int
+	}{
+		{"fake", `{app="api"} | @access_log`, `{app="api"} | json | status>=500`, http.StatusOK},
+		{"fake", `rate({app="api"} | @access_log [5m])`, `rate({app="api"} | json | status>=500[5m])`, http.StatusOK},
+		{"other", `{app="api"} | @access_log`, `{app="api"} | logfmt`, http.StatusOK},
+		// Queries without macros are passed on as is.
+		{"fake", `{app="api"}    |= "user@example.com"`, `{app="api"}    |= "user@example.com"`, http.StatusOK},
+		// Undefined macros are reported by the handlers.
+		{"fake", `{app="api"} | @unknown`, `{app="api"} | @unknown`, http.StatusOK},
+		{"fake|other", `{app="api"} | @access_log`, `{app="api"} | @access_log`, http.StatusOK},
+	} {
+		t.Run(tc.query, func(t *testing.T) {
+			got = ""
+			req := httptest.NewRequest("GET", "/loki/api/v1/query_range?query="+url.QueryEscape(tc.query), nil)
+			req = req.WithContext(user.InjectOrgID(req.Context(), tc.orgID))
+			require.NoError(t, req.ParseForm())
+
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+			require.Equal(t, tc.code, w.Code, w.Body.String())
+			require.Equal(t, tc.exp, got)
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package macros
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/grafana/dskit/httpgrpc"
+	"github.com/grafana/dskit/middleware"
+	"github.com/grafana/dskit/tenant"
+
+	"github.com/grafana/loki/v3/pkg/logql/syntax"
+	serverutil "github.com/grafana/loki/v3/pkg/util/server"
+)
+
+// NewExpandMiddleware creates a middleware which expands the macros of the
+// query parameter with the macros of the tenant, so the handlers don't need
+// to know about macros. Queries without macros are passed on unchanged.
+//
+// It expects the auth middleware to run before, and the form of the request
+// to be parsed already.
+func NewExpandMiddleware(store *Store) middleware.Interface {
+	return middleware.Func(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			query := r.Form.Get("query")
+			if !strings.Contains(query, syntax.OpMacro) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			// Queries that don't parse are rejected by the handlers.
+			expr, err := syntax.ParseExprWithoutValidation(query)
+			if err != nil || syntax.ExtractMacro(expr) == nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			// Macros are scoped to a single tenant.
+			tenants, err := tenant.TenantIDs(r.Context())
+			if err != nil || len(tenants) != 1 {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			macros, err := store.Macros(r.Context(), tenants[0])
+			if err != nil {
+				serverutil.WriteError(fmt.Errorf("loading macros: %w", err), w)
+				return
+			}
+			if err := syntax.ExpandMacros(expr, macros); err != nil {
+				serverutil.WriteError(httpgrpc.Errorf(http.StatusBadRequest, "%s", err), w)
+				return
+			}
+
+			r.Form.Set("query", expr.String())
+			next.ServeHTTP(w, r)
+		})
+	})
+}
@@ -0,0 +1,308 @@
+package macros
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/grafana/loki/v3/pkg/logql/syntax"
+	"github.com/grafana/loki/v3/pkg/storage/chunk/client"
+)
+
+// Object Macro Storage Schema
+// ===========================
+// Object Name: "macros/<user_id>/<macro name>/<zero padded version>"
+// Storage Format: JSON encoded Macro
+//
+// Each update of a macro is stored as a new version, so previous versions can
+// be inspected. Macro names are restricted to label name characters, so they
+// are valid object names in all backends.
+
+const (
+	delim        = "/"
+	macrosPrefix = "macros" + delim
+)
+
+var (
+	ErrMacroNotFound   = errors.New("macro not found")
+	ErrVersionConflict = errors.New("macro version conflict")
+	ErrInvalidMacro    = errors.New("invalid macro")
+
+	nameRegexp = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+)
+
+// Config configures the macros of LogQL queries.
+type Config struct {
+	Enabled  bool          `yaml:"enabled"`
+	CacheTTL time.Duration `yaml:"cache_ttl"`
+}
+
+func (cfg *Config) RegisterFlagsWithPrefix(prefix string, f *flag.FlagSet) {
+	f.BoolVar(&cfg.Enabled, prefix+"enabled", false, "Enable tenant-defined macros in LogQL queries, e.g. `{app=\"api\"} | @access_log`. Macros are stored in the ruler storage, which has to be an object storage.")
+	f.DurationVar(&cfg.CacheTTL, prefix+"cache-ttl", time.Minute, "Duration for which the macros of a tenant are cached before they are loaded from the storage again.")
+}
+
+// Macro is a named pipeline of a tenant.
+type Macro struct {
+	Name      string    `json:"name"`
+	Pipeline  string    `json:"pipeline"`
+	Version   int       `json:"version"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Store stores the versions of the macros of the tenants in an object storage.
+type Store struct {
+	client   client.ObjectClient
+	cacheTTL time.Duration
+	now      func() time.Time
+
+	mtx   sync.Mutex
+	cache map[string]cachedMacros
+}
+
+type cachedMacros struct {
+	macros  syntax.Macros
+	expires time.Time
+}
+
+// NewStore returns a new Store.
+func NewStore(client client.ObjectClient, cacheTTL time.Duration) *Store {
+	return &Store{
+		client:   client,
+		cacheTTL: cacheTTL,
+		now:      time.Now,
+		cache:    map[string]cachedMacros{},
+	}
+}
+
+// ValidateMacro returns an error wrapping ErrInvalidMacro if the name or the
+// pipeline of a macro is invalid.
+func ValidateMacro(name, pipeline string) error {
+	if !nameRegexp.MatchString(name) {
+		return fmt.Errorf("%w: name %q must match %s", ErrInvalidMacro, name, nameRegexp)
+	}
+	if _, err := syntax.ParseMacro(pipeline); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidMacro, err)
+	}
+	return nil
+}
+
+// Get returns the latest version of a macro.
+func (s *Store) Get(ctx context.Context, tenant, name string) (*Macro, error) {
+	versions, err := s.versions(ctx, tenant, name)
+	if err != nil {
+		return nil, err
+	}
+	if len(versions) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrMacroNotFound, name)
+	}
+	return s.GetVersion(ctx, tenant, name, versions[len(versions)-1])
+}
+
+// GetVersion returns the given version of a macro.
+func (s *Store) GetVersion(ctx context.Context, tenant, name string, version int) (*Macro, error) {
+	key := versionKey(tenant, name, version)
+	reader, _, err := s.client.GetObject(ctx, key)
+	if err != nil {
+		if s.client.IsObjectNotFoundErr(err) {
+			return nil, fmt.Errorf("%w: %s version %d", ErrMacroNotFound, name, version)
+		}
+		return nil, fmt.Errorf("failed to get macro %s: %w", key, err)
+	}
+	defer func() { _ = reader.Close() }()
+
+	buf, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read macro %s: %w", key, err)
+	}
+	var macro Macro
+	if err := json.Unmarshal(buf, &macro); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal macro %s: %w", key, err)
+	}
+	return &macro, nil
+}
+
+// Versions returns all versions of a macro, from the oldest to the latest.
+func (s *Store) Versions(ctx context.Context, tenant, name string) ([]*Macro, error) {
+	versions, err := s.versions(ctx, tenant, name)
+	if err != nil {
+		return nil, err
+	}
+	if len(versions) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrMacroNotFound, name)
+	}
+
+	macros := make([]*Macro, 0, len(versions))
+	for _, version := range versions {
+		macro, err := s.GetVersion(ctx, tenant, name, version)
+		if err != nil {
+			return nil, err
+		}
+		macros = append(macros, macro)
+	}
+	return macros, nil
+}
+
+// List returns the latest version of all macros of a tenant, sorted by name.
+func (s *Store) List(ctx context.Context, tenant string) ([]*Macro, error) {
+	_, prefixes, err := s.client.List(ctx, tenantPrefix(tenant), delim)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list macros of tenant %s: %w", tenant, err)
+	}
+
+	macros := make([]*Macro, 0, len(prefixes))
+	for _, prefix := range prefixes {
+		name := strings.TrimSuffix(strings.TrimPrefix(string(prefix), tenantPrefix(tenant)), delim)
+		macro, err := s.Get(ctx, tenant, name)
+		if errors.Is(err, ErrMacroNotFound) {
+			// The macro got deleted in the meantime.
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		macros = append(macros, macro)
+	}
+	sort.Slice(macros, func(i, j int) bool { return macros[i].Name < macros[j].Name })
+	return macros, nil
+}
+
+// Put validates a macro and stores its pipeline as a new version. If
+// expectedVersion is not zero, it returns ErrVersionConflict unless it is the
+// latest version of the macro. The check is best effort, concurrent updates of
+// the same macro can still overwrite each other.
+func (s *Store) Put(ctx context.Context, tenant, name, pipeline string, expectedVersion int) (*Macro, error) {
+	if err := ValidateMacro(name, pipeline); err != nil {
+		return nil, err
+	}
+
+	versions, err := s.versions(ctx, tenant, name)
+	if err != nil {
+		return nil, err
+	}
+	latest := 0
+	if len(versions) > 0 {
+		latest = versions[len(versions)-1]
+	}
+	if expectedVersion != 0 && expectedVersion != latest {
+		return nil, fmt.Errorf("%w: expected version %d of macro %s, but the latest version is %d", ErrVersionConflict, expectedVersion, name, latest)
+	}
+
+	macro := &Macro{
+		Name:      name,
+		Pipeline:  pipeline,
+		Version:   latest + 1,
+		UpdatedAt: s.now().UTC(),
+	}
+	buf, err := json.Marshal(macro)
+	if err != nil {
+		return nil, err
+	}
+	key := versionKey(tenant, name, macro.Version)
+	if err := s.client.PutObject(ctx, key, bytes.NewReader(buf)); err != nil {
+		return nil, fmt.Errorf("failed to put macro %s: %w", key, err)
+	}
+
+	s.invalidate(tenant)
+	return macro, nil
+}
+
+// Delete deletes all versions of a macro.
+func (s *Store) Delete(ctx context.Context, tenant, name string) error {
+	versions, err := s.versions(ctx, tenant, name)
+	if err != nil {
+		return err
+	}
+	if len(versions) == 0 {
+		return fmt.Errorf("%w: %s", ErrMacroNotFound, name)
+	}
+
+	for _, version := range versions {
+		key := versionKey(tenant, name, version)
+		if err := s.client.DeleteObject(ctx, key); err != nil && !s.client.IsObjectNotFoundErr(err) {
+			return fmt.Errorf("failed to delete macro %s: %w", key, err)
+		}
+	}
+
+	s.invalidate(tenant)
+	return nil
+}
+
+// Macros returns the pipelines of the latest version of all macros of a
+// tenant. The result is cached for the configured TTL.
+func (s *Store) Macros(ctx context.Context, tenant string) (syntax.Macros, error) {
+	s.mtx.Lock()
+	cached, ok := s.cache[tenant]
+	s.mtx.Unlock()
+	if ok && s.now().Before(cached.expires) {
+		return cached.macros, nil
+	}
+
+	list, err := s.List(ctx, tenant)
+	if err != nil {
+		return nil, err
+	}
+	macros := make(syntax.Macros, len(list))
+	for _, macro := range list {
+		macros[macro.Name] = macro.Pipeline
+	}
+
+	s.mtx.Lock()
+	s.cache[tenant] = cachedMacros{macros: macros, expires: s.now().Add(s.cacheTTL)}
+	s.mtx.Unlock()
+	return macros, nil
+}
+
+// invalidate drops the cached macros of a tenant, so this instance serves an
+// update immediately. Other instances serve it after the cache TTL.
+func (s *Store) invalidate(tenant string) {
+	s.mtx.Lock()
+	delete(s.cache, tenant)
+	s.mtx.Unlock()
+}
+
+// versions returns the sorted versions of a macro.
+func (s *Store) versions(ctx context.Context, tenant, name string) ([]int, error) {
+	if !nameRegexp.MatchString(name) {
+		return nil, fmt.Errorf("%w: name %q must match %s", ErrInvalidMacro, name, nameRegexp)
+	}
+
+	prefix := macroPrefix(tenant, name)
+	objects, _, err := s.client.List(ctx, prefix, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list versions of macro %s: %w", prefix, err)
+	}
+
+	versions := make([]int, 0, len(objects))
+	for _, object := range objects {
+		version, err := strconv.Atoi(strings.TrimPrefix(object.Key, prefix))
+		if err != nil {
+			// Skip objects that are not written by the store.
+			continue
+		}
+		versions = append(versions, version)
+	}
+	sort.Ints(versions)
+	return versions, nil
+}
+
+func tenantPrefix(tenant string) string {
+	return macrosPrefix + tenant + delim
+}
+
+func macroPrefix(tenant, name string) string {
+	return tenantPrefix(tenant) + name + delim
+}
+
+func versionKey(tenant, name string, version int) string {
+	return fmt.Sprintf("%s%010d", macroPrefix(tenant, name), version)
+}
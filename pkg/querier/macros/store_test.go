@@ -0,0 +1,131 @@
+package macros
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/v3/pkg/logql/syntax"
+	"github.com/grafana/loki/v3/pkg/storage/chunk/client/testutils"
+)
+
+func newTestStore(t *testing.T, cacheTTL time.Duration) (*Store, *time.Time) {
+	t.Helper()
+
+	now := time.Unix(1000, 0)
+	store := NewStore(testutils.NewInMemoryObjectClient(), cacheTTL)
+	store.now = func() time.Time { return now }
+	return store, &now
+}
+
+func TestStore(t *testing.T) {
+	ctx := context.Background()
+	store, _ := newTestStore(t, 0)
+
+	_, err := store.Get(ctx, "fake", "access_log")
+	require.ErrorIs(t, err, ErrMacroNotFound)
+
+	v1, err := store.Put(ctx, "fake", "access_log", `| json`, 0)
+	require.NoError(t, err)
+	require.Equal(t, &Macro{Name: "access_log", Pipeline: `| json`, Version: 1, UpdatedAt: time.Unix(1000, 0).UTC()}, v1)
+
+	v2, err := store.Put(ctx, "fake", "access_log", `| json | status >= 500`, 1)
+	require.NoError(t, err)
+	require.Equal(t, 2, v2.Version)
+
+	_, err = store.Put(ctx, "fake", "access_log", `| logfmt`, 1)
+	require.ErrorIs(t, err, ErrVersionConflict)
+
+	_, err = store.Put(ctx, "fake", "errors", `|= "error"`, 0)
+	require.NoError(t, err)
+	_, err = store.Put(ctx, "other", "access_log", `| logfmt`, 0)
+	require.NoError(t, err)
+
+	latest, err := store.Get(ctx, "fake", "access_log")
+	require.NoError(t, err)
+	require.Equal(t, v2, latest)
+
+	first, err := store.GetVersion(ctx, "fake", "access_log", 1)
+	require.NoError(t, err)
+	require.Equal(t, v1, first)
+
+	versions, err := store.Versions(ctx, "fake", "access_log")
+	require.NoError(t, err)
+	require.Equal(t, []*Macro{v1, v2}, versions)
+
+	list, err := store.List(ctx, "fake")
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+	require.Equal(t, "access_log", list[0].Name)
+	require.Equal(t, "errors", list[1].Name)
+
+	macros, err := store.Macros(ctx, "fake")
+	require.NoError(t, err)
+	require.Equal(t, syntax.Macros{"access_log": `| json | status >= 500`, "errors": `|= "error"`}, macros)
+
+	require.NoError(t, store.Delete(ctx, "fake", "access_log"))
+	_, err = store.Get(ctx, "fake", "access_log")
+	require.ErrorIs(t, err, ErrMacroNotFound)
+	require.ErrorIs(t, store.Delete(ctx, "fake", "access_log"), ErrMacroNotFound)
+
+	// The macros of other tenants are untouched.
+	macros, err = store.Macros(ctx, "other")
+	require.NoError(t, err)
+	require.Equal(t, syntax.Macros{"access_log": `| logfmt`}, macros)
+}
+
+func TestStore_Validation(t *testing.T) {
+	ctx := context.Background()
+	store, _ := newTestStore(t, 0)
+
+	for _, tc := range []struct {
+		name, pipeline string
+	}{
+		{"1st", `| json`},
+		{"access-log", `| json`},
+		{"access_log", ``},
+		{"access_log", `| json |`},
+		{"access_log", `| @other`},
+		{"access_log", `| json | @other`},
+	} {
+		_, err := store.Put(ctx, "fake", tc.name, tc.pipeline, 0)
+		require.ErrorIs(t, err, ErrInvalidMacro, "name %q, pipeline %q", tc.name, tc.pipeline)
+	}
+
+	_, err := store.Get(ctx, "fake", "../other")
+	require.ErrorIs(t, err, ErrInvalidMacro)
+}
+
+func TestStore_MacrosCache(t *testing.T) {
+	ctx := context.Background()
+	store, now := newTestStore(t, time.Minute)
+	other := NewStore(store.client, time.Minute)
+	other.now = store.now
+
+	_, err := store.Put(ctx, "fake", "access_log", `| json`, 0)
+	require.NoError(t, err)
+
+	macros, err := other.Macros(ctx, "fake")
+	require.NoError(t, err)
+	require.Equal(t, syntax.Macros{"access_log": `| json`}, macros)
+
+	// Updates through the store are served immediately by the same store,
+	// and after the TTL by other stores.
+	_, err = store.Put(ctx, "fake", "access_log", `| logfmt`, 0)
+	require.NoError(t, err)
+
+	macros, err = store.Macros(ctx, "fake")
+	require.NoError(t, err)
+	require.Equal(t, syntax.Macros{"access_log": `| logfmt`}, macros)
+
+	macros, err = other.Macros(ctx, "fake")
+	require.NoError(t, err)
+	require.Equal(t, syntax.Macros{"access_log": `| json`}, macros)
+
+	*now = now.Add(time.Minute)
+	macros, err = other.Macros(ctx, "fake")
+	require.NoError(t, err)
+	require.Equal(t, syntax.Macros{"access_log": `| logfmt`}, macros)
+}
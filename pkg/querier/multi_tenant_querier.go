@@ -58,8 +58,8 @@ func (q *MultiTenantQuerier) SelectLogs(ctx context.Context, params logql.Select
 	if err != nil {
 		return nil, err
 	}
-	matchedTenants, filteredMatchers := filterValuesByMatchers(defaultTenantLabel, tenantIDs, selector.Matchers()...)
-	params.Selector = replaceMatchers(selector, filteredMatchers).String()
+	matchedTenants, updatedSelector := removeTenantMatchers(selector, tenantIDs)
+	params.Selector = updatedSelector.String()
 
 	parsed, err := syntax.ParseLogSelector(params.Selector, true)
 	if err != nil {
@@ -359,26 +359,31 @@ func removeTenantSelector(params logql.SelectSampleParams, tenantIDs []string) (
 	if err != nil {
 		return nil, nil, err
 	}
-	selector, err := expr.Selector()
-	if err != nil {
-		return nil, nil, err
-	}
-	matchedTenants, filteredMatchers := filterValuesByMatchers(defaultTenantLabel, tenantIDs, selector.Matchers()...)
-	updatedExpr := replaceMatchers(expr, filteredMatchers)
+	matchedTenants, updatedExpr := removeTenantMatchers(expr, tenantIDs)
 	return matchedTenants, updatedExpr, nil
 }
 
-// replaceMatchers traverses the passed expression and replaces all matchers.
-func replaceMatchers(expr syntax.Expr, matchers []*labels.Matcher) syntax.Expr {
+// removeTenantMatchers traverses the passed expression and removes the tenant
+// ID matchers of all its selectors, including the selector of a joined query.
+// Since every selector of the expression is evaluated within the same tenant,
+// it returns the tenants that are matched by all of them.
+func removeTenantMatchers(expr syntax.Expr, tenantIDs []string) (map[string]struct{}, syntax.Expr) {
 	expr, _ = syntax.Clone(expr)
+	matchedTenants := sliceToSet(tenantIDs)
 	expr.Walk(func(e syntax.Expr) bool {
 		switch concrete := e.(type) {
 		case *syntax.MatchersExpr:
-			concrete.Mts = matchers
+			matched, filteredMatchers := filterValuesByMatchers(defaultTenantLabel, tenantIDs, concrete.Mts...)
+			for id := range matchedTenants {
+				if _, ok := matched[id]; !ok {
+					delete(matchedTenants, id)
+				}
+			}
+			concrete.Mts = filteredMatchers
 		}
 		return true
 	})
-	return expr
+	return matchedTenants, expr
 }
 
 // See https://github.com/grafana/mimir/blob/114ab88b50638a2047e2ca2a60640f6ca6fe8c17/pkg/querier/tenantfederation/tenant_federation.go#L29-L69
@@ -205,6 +205,34 @@ func TestMultiTenantQuerier_TenantFilter(t *testing.T) {
 	}
 }
 
+func TestMultiTenantQuerier_TenantFilterJoin(t *testing.T) {
+	for _, tc := range []struct {
+		desc     string
+		selector string
+		expected string
+		tenants  map[string]struct{}
+	}{
+		{
+			desc:     "both sides",
+			selector: `{app="foo", __tenant_id__="1"} | join on (request_id) [30s] ({app="bar", __tenant_id__=~"1|2"})`,
+			expected: `{app="foo"} | join on (request_id) [30s] ({app="bar"})`,
+			tenants:  map[string]struct{}{"1": {}},
+		},
+		{
+			desc:     "right side only",
+			selector: `{app="foo"} | join on (request_id) [30s] ({app="bar", __tenant_id__="2"})`,
+			expected: `{app="foo"} | join on (request_id) [30s] ({app="bar"})`,
+			tenants:  map[string]struct{}{"2": {}},
+		},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			tenants, updatedSelector := removeTenantMatchers(syntax.MustParseExpr(tc.selector), []string{"1", "2", "3"})
+			require.Equal(t, tc.tenants, tenants)
+			require.Equal(t, removeWhiteSpace(tc.expected), removeWhiteSpace(updatedSelector.String()))
+		})
+	}
+}
+
 var samples = []logproto.Sample{
 	{Timestamp: time.Unix(2, 0).UnixNano(), Hash: 1, Value: 1.},
 	{Timestamp: time.Unix(5, 0).UnixNano(), Hash: 2, Value: 1.},
@@ -1218,6 +1218,15 @@ func decodeResponseJSONFrom(buf []byte, req queryrangebase.Request, headers http
 				},
 				Statistics: resp.Data.Statistics,
 			}, nil
+		case loghttp.ResultTypeTable:
+			table := resp.Data.Result.(loghttp.Table)
+			return &TableResponse{
+				Columns:    table.Columns,
+				Rows:       toProtoTableRows(table.Rows),
+				Headers:    httpResponseHeadersToPromResponseHeaders(headers),
+				Warnings:   resp.Warnings,
+				Statistics: resp.Data.Statistics,
+			}, nil
 		default:
 			return nil, httpgrpc.Errorf(http.StatusInternalServerError, "unsupported response type, got (%s)", string(resp.Data.ResultType))
 		}
@@ -1377,6 +1386,10 @@ func encodeResponseJSONTo(version loghttp.Version, res queryrangebase.Response,
 		if err := marshal.WriteDetectedLabelsResponseJSON(response.Response, w); err != nil {
 			return err
 		}
+	case *TableResponse:
+		if err := marshal.WriteQueryResponseJSON(response.table(), response.Warnings, response.Statistics, w, encodeFlags); err != nil {
+			return err
+		}
 	default:
 		return httpgrpc.Errorf(http.StatusInternalServerError, "%s", fmt.Sprintf("invalid response format, got (%T)", res))
 	}
@@ -1608,6 +1621,13 @@ func (Codec) MergeResponse(responses ...queryrangebase.Response) (queryrangebase
 			Response: &logproto.QueryPatternsResponse{Series: mergedPatterns.Series},
 			Headers:  resp0.Headers,
 		}, nil
+	case *TableResponse:
+		// The aggregations of a table, e.g. avg, can't be merged, so stats
+		// queries are neither split nor sharded.
+		if len(responses) > 1 {
+			return nil, errors.New("table responses can't be merged")
+		}
+		return res, nil
 	default:
 		return nil, fmt.Errorf("unknown response type (%T) in merging responses", responses[0])
 	}
@@ -1719,9 +1739,14 @@ func toProtoMatrix(m loghttp.Matrix) []queryrangebase.SampleStream {
 				TimestampMs: int64(s.Timestamp),
 			})
 		}
+		var histograms []logproto.SampleHistogram
+		for _, h := range stream.Histograms {
+			histograms = append(histograms, logproto.NewSampleHistogram(int64(h.Timestamp), logproto.FromModelToFloatHistogram(h.Histogram)))
+		}
 		res = append(res, queryrangebase.SampleStream{
-			Labels:  logproto.FromMetricsToLabelAdapters(stream.Metric),
-			Samples: samples,
+			Labels:     logproto.FromMetricsToLabelAdapters(stream.Metric),
+			Samples:    samples,
+			Histograms: histograms,
 		})
 	}
 	return res
@@ -1734,6 +1759,15 @@ func toProtoVector(v loghttp.Vector) []queryrangebase.SampleStream {
 		return res
 	}
 	for _, s := range v {
+		if s.Histogram != nil {
+			res = append(res, queryrangebase.SampleStream{
+				Histograms: []logproto.SampleHistogram{
+					logproto.NewSampleHistogram(int64(s.Timestamp), logproto.FromModelToFloatHistogram(s.Histogram)),
+				},
+				Labels: logproto.FromMetricsToLabelAdapters(s.Metric),
+			})
+			continue
+		}
 		res = append(res, queryrangebase.SampleStream{
 			Samples: []logproto.LegacySample{{
 				Value:       float64(s.Value),
@@ -381,6 +381,15 @@ func Test_codec_DecodeResponse(t *testing.T) {
 				Statistics: statsResult,
 			}, "",
 		},
+		{
+			"table",
+			&http.Response{StatusCode: 200, Body: io.NopCloser(strings.NewReader(`{"status":"success","data":{"resultType":"table","result":{"columns":["status","count()"],"rows":[["200","2"],["500","1"]]}}}`))},
+			&LokiRequest{Direction: logproto.FORWARD, Limit: 100, Path: "/loki/api/v1/query_range"},
+			&TableResponse{
+				Columns: []string{"status", "count()"},
+				Rows:    []TableRow{{Values: []string{"200", "2"}}, {Values: []string{"500", "1"}}},
+			}, "",
+		},
 		{
 			"matrix-empty-streams",
 			&http.Response{StatusCode: 200, Body: io.NopCloser(strings.NewReader(matrixStringEmptyResult))},
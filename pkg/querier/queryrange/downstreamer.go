@@ -244,6 +244,12 @@ func sampleStreamToMatrix(streams []queryrangebase.SampleStream) parser.Value {
 				F: sample.Value,
 			})
 		}
+		for _, h := range stream.Histograms {
+			x.Histograms = append(x.Histograms, promql.HPoint{
+				T: h.Timestamp,
+				H: h.FloatHistogram(),
+			})
+		}
 
 		xs = append(xs, x)
 	}
@@ -260,8 +266,13 @@ func sampleStreamToVector(streams []queryrangebase.SampleStream) parser.Value {
 		}
 		x.Metric = lblsBuilder.Labels()
 
-		x.T = stream.Samples[0].TimestampMs
-		x.F = stream.Samples[0].Value
+		if len(stream.Histograms) > 0 {
+			x.T = stream.Histograms[0].Timestamp
+			x.H = stream.Histograms[0].FloatHistogram()
+		} else {
+			x.T = stream.Samples[0].TimestampMs
+			x.F = stream.Samples[0].Value
+		}
 
 		xs = append(xs, x)
 	}
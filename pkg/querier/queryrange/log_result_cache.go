@@ -105,6 +105,11 @@ func (l *logResultCache) Do(ctx context.Context, req queryrangebase.Request) (qu
 		return nil, httpgrpc.Errorf(http.StatusInternalServerError, "invalid request type %T", req)
 	}
 
+	// only streams are cached, not the tables of stats stages.
+	if hasStatsStage(lokiReq) {
+		return l.next.Do(ctx, req)
+	}
+
 	interval := validation.SmallestPositiveNonZeroDurationPerTenant(tenantIDs, l.limits.QuerySplitDuration)
 	// skip caching by if interval is unset
 	// skip caching when limit is 0 as it would get registerted as empty result in the cache even if that time range contains log lines.
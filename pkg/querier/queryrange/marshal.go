@@ -145,6 +145,13 @@ func ResultToResponse(result logqlmodel.Result, params logql.Params) (queryrange
 			Warnings:   result.Warnings,
 			Statistics: result.Statistics,
 		}, err
+	case logqlmodel.Table:
+		return &TableResponse{
+			Columns:    data.Columns,
+			Rows:       toProtoTableRows(data.Rows),
+			Warnings:   result.Warnings,
+			Statistics: result.Statistics,
+		}, nil
 	}
 
 	return nil, fmt.Errorf("unsupported data type: %T", result.Data)
@@ -222,6 +229,13 @@ func ResponseToResult(resp queryrangebase.Response) (logqlmodel.Result, error) {
 			Warnings:   r.Warnings,
 			Statistics: r.Statistics,
 		}, nil
+	case *TableResponse:
+		return logqlmodel.Result{
+			Data:       r.table(),
+			Headers:    resp.GetHeaders(),
+			Warnings:   r.Warnings,
+			Statistics: r.Statistics,
+		}, nil
 	default:
 		return logqlmodel.Result{}, fmt.Errorf("cannot decode (%T)", resp)
 	}
@@ -259,6 +273,8 @@ func QueryResponseUnwrap(res *QueryResponse) (queryrangebase.Response, error) {
 		return concrete.DetectedFields, nil
 	case *QueryResponse_CountMinSketches:
 		return concrete.CountMinSketches, nil
+	case *QueryResponse_Table:
+		return concrete.Table, nil
 	default:
 		return nil, fmt.Errorf("unsupported QueryResponse response type, got (%T)", res.Response)
 	}
@@ -302,6 +318,8 @@ func QueryResponseWrap(res queryrangebase.Response) (*QueryResponse, error) {
 		p.Response = &QueryResponse_DetectedFields{response}
 	case *CountMinSketchResponse:
 		p.Response = &QueryResponse_CountMinSketches{response}
+	case *TableResponse:
+		p.Response = &QueryResponse_Table{response}
 	default:
 		return nil, fmt.Errorf("invalid response format, got (%T)", res)
 	}
@@ -475,3 +493,20 @@ func (Codec) QueryRequestWrap(ctx context.Context, r queryrangebase.Request) (*Q
 
 	return result, nil
 }
+
+func toProtoTableRows(rows [][]string) []TableRow {
+	res := make([]TableRow, 0, len(rows))
+	for _, row := range rows {
+		res = append(res, TableRow{Values: row})
+	}
+	return res
+}
+
+// table returns the table held by the response.
+func (m *TableResponse) table() logqlmodel.Table {
+	rows := make([][]string, 0, len(m.Rows))
+	for _, row := range m.Rows {
+		rows = append(rows, row.Values)
+	}
+	return logqlmodel.Table{Columns: m.Columns, Rows: rows}
+}
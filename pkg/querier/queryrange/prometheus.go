@@ -13,6 +13,7 @@ import (
 	"go.opentelemetry.io/otel/trace"
 
 	"github.com/grafana/loki/v3/pkg/loghttp"
+	"github.com/grafana/loki/v3/pkg/logproto"
 	"github.com/grafana/loki/v3/pkg/logqlmodel/stats"
 	"github.com/grafana/loki/v3/pkg/querier/queryrange/queryrangebase"
 	"github.com/grafana/loki/v3/pkg/storage/chunk/cache/resultscache"
@@ -94,6 +95,14 @@ func (p *LokiPromResponse) marshalVector() ([]byte, error) {
 		for _, v := range v.Labels {
 			lbs[model.LabelName(v.Name)] = model.LabelValue(v.Value)
 		}
+		if len(v.Histograms) > 0 {
+			vec[i] = model.Sample{
+				Metric:    model.Metric(lbs),
+				Timestamp: model.Time(v.Histograms[0].Timestamp),
+				Histogram: logproto.FromFloatHistogramToModel(v.Histograms[0].FloatHistogram()),
+			}
+			continue
+		}
 		vec[i] = model.Sample{
 			Metric:    model.Metric(lbs),
 			Timestamp: model.Time(v.Samples[0].TimestampMs),
@@ -961,6 +961,118 @@ func (m *CountMinSketchResponse) GetStatistics() stats.Result {
 	return stats.Result{}
 }
 
+// TableResponse holds the tabular result of a stats stage.
+type TableResponse struct {
+	Columns    []string                                                                                                `protobuf:"bytes,1,rep,name=columns,proto3" json:"columns,omitempty"`
+	Rows       []TableRow                                                                                              `protobuf:"bytes,2,rep,name=rows,proto3" json:"rows"`
+	Headers    []github_com_grafana_loki_v3_pkg_querier_queryrange_queryrangebase_definitions.PrometheusResponseHeader `protobuf:"bytes,3,rep,name=Headers,proto3,customtype=github.com/grafana/loki/v3/pkg/querier/queryrange/queryrangebase/definitions.PrometheusResponseHeader" json:"-"`
+	Warnings   []string                                                                                                `protobuf:"bytes,4,rep,name=warnings,proto3" json:"warnings,omitempty"`
+	Statistics stats.Result                                                                                            `protobuf:"bytes,5,opt,name=statistics,proto3" json:"statistics"`
+}
+
+func (m *TableResponse) Reset()      { *m = TableResponse{} }
+func (*TableResponse) ProtoMessage() {}
+func (*TableResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_51b9d53b40d11902, []int{14}
+}
+func (m *TableResponse) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *TableResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_TableResponse.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *TableResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_TableResponse.Merge(m, src)
+}
+func (m *TableResponse) XXX_Size() int {
+	return m.Size()
+}
+func (m *TableResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_TableResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_TableResponse proto.InternalMessageInfo
+
+func (m *TableResponse) GetColumns() []string {
+	if m != nil {
+		return m.Columns
+	}
+	return nil
+}
+
+func (m *TableResponse) GetRows() []TableRow {
+	if m != nil {
+		return m.Rows
+	}
+	return nil
+}
+
+func (m *TableResponse) GetWarnings() []string {
+	if m != nil {
+		return m.Warnings
+	}
+	return nil
+}
+
+func (m *TableResponse) GetStatistics() stats.Result {
+	if m != nil {
+		return m.Statistics
+	}
+	return stats.Result{}
+}
+
+type TableRow struct {
+	Values []string `protobuf:"bytes,1,rep,name=values,proto3" json:"values,omitempty"`
+}
+
+func (m *TableRow) Reset()      { *m = TableRow{} }
+func (*TableRow) ProtoMessage() {}
+func (*TableRow) Descriptor() ([]byte, []int) {
+	return fileDescriptor_51b9d53b40d11902, []int{15}
+}
+func (m *TableRow) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *TableRow) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_TableRow.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *TableRow) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_TableRow.Merge(m, src)
+}
+func (m *TableRow) XXX_Size() int {
+	return m.Size()
+}
+func (m *TableRow) XXX_DiscardUnknown() {
+	xxx_messageInfo_TableRow.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_TableRow proto.InternalMessageInfo
+
+func (m *TableRow) GetValues() []string {
+	if m != nil {
+		return m.Values
+	}
+	return nil
+}
+
 type ShardsResponse struct {
 	Response *github_com_grafana_loki_v3_pkg_logproto.ShardsResponse                                                 `protobuf:"bytes,1,opt,name=response,proto3,customtype=github.com/grafana/loki/v3/pkg/logproto.ShardsResponse" json:"response,omitempty"`
 	Headers  []github_com_grafana_loki_v3_pkg_querier_queryrange_queryrangebase_definitions.PrometheusResponseHeader `protobuf:"bytes,2,rep,name=Headers,proto3,customtype=github.com/grafana/loki/v3/pkg/querier/queryrange/queryrangebase/definitions.PrometheusResponseHeader" json:"-"`
@@ -969,7 +1081,7 @@ type ShardsResponse struct {
 func (m *ShardsResponse) Reset()      { *m = ShardsResponse{} }
 func (*ShardsResponse) ProtoMessage() {}
 func (*ShardsResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_51b9d53b40d11902, []int{14}
+	return fileDescriptor_51b9d53b40d11902, []int{16}
 }
 func (m *ShardsResponse) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -1006,7 +1118,7 @@ type DetectedFieldsResponse struct {
 func (m *DetectedFieldsResponse) Reset()      { *m = DetectedFieldsResponse{} }
 func (*DetectedFieldsResponse) ProtoMessage() {}
 func (*DetectedFieldsResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_51b9d53b40d11902, []int{15}
+	return fileDescriptor_51b9d53b40d11902, []int{17}
 }
 func (m *DetectedFieldsResponse) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -1043,7 +1155,7 @@ type QueryPatternsResponse struct {
 func (m *QueryPatternsResponse) Reset()      { *m = QueryPatternsResponse{} }
 func (*QueryPatternsResponse) ProtoMessage() {}
 func (*QueryPatternsResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_51b9d53b40d11902, []int{16}
+	return fileDescriptor_51b9d53b40d11902, []int{18}
 }
 func (m *QueryPatternsResponse) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -1080,7 +1192,7 @@ type DetectedLabelsResponse struct {
 func (m *DetectedLabelsResponse) Reset()      { *m = DetectedLabelsResponse{} }
 func (*DetectedLabelsResponse) ProtoMessage() {}
 func (*DetectedLabelsResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_51b9d53b40d11902, []int{17}
+	return fileDescriptor_51b9d53b40d11902, []int{19}
 }
 func (m *DetectedLabelsResponse) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -1125,13 +1237,14 @@ type QueryResponse struct {
 	//	*QueryResponse_PatternsResponse
 	//	*QueryResponse_DetectedLabels
 	//	*QueryResponse_CountMinSketches
+	//	*QueryResponse_Table
 	Response isQueryResponse_Response `protobuf_oneof:"response"`
 }
 
 func (m *QueryResponse) Reset()      { *m = QueryResponse{} }
 func (*QueryResponse) ProtoMessage() {}
 func (*QueryResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_51b9d53b40d11902, []int{18}
+	return fileDescriptor_51b9d53b40d11902, []int{20}
 }
 func (m *QueryResponse) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -1206,6 +1319,9 @@ type QueryResponse_DetectedLabels struct {
 type QueryResponse_CountMinSketches struct {
 	CountMinSketches *CountMinSketchResponse `protobuf:"bytes,14,opt,name=countMinSketches,proto3,oneof"`
 }
+type QueryResponse_Table struct {
+	Table *TableResponse `protobuf:"bytes,15,opt,name=table,proto3,oneof"`
+}
 
 func (*QueryResponse_Series) isQueryResponse_Response()           {}
 func (*QueryResponse_Labels) isQueryResponse_Response()           {}
@@ -1220,6 +1336,7 @@ func (*QueryResponse_DetectedFields) isQueryResponse_Response()   {}
 func (*QueryResponse_PatternsResponse) isQueryResponse_Response() {}
 func (*QueryResponse_DetectedLabels) isQueryResponse_Response()   {}
 func (*QueryResponse_CountMinSketches) isQueryResponse_Response() {}
+func (*QueryResponse_Table) isQueryResponse_Response()            {}
 
 func (m *QueryResponse) GetResponse() isQueryResponse_Response {
 	if m != nil {
@@ -1326,6 +1443,13 @@ func (m *QueryResponse) GetCountMinSketches() *CountMinSketchResponse {
 	return nil
 }
 
+func (m *QueryResponse) GetTable() *TableResponse {
+	if x, ok := m.GetResponse().(*QueryResponse_Table); ok {
+		return x.Table
+	}
+	return nil
+}
+
 // XXX_OneofWrappers is for the internal use of the proto package.
 func (*QueryResponse) XXX_OneofWrappers() []interface{} {
 	return []interface{}{
@@ -1342,6 +1466,7 @@ func (*QueryResponse) XXX_OneofWrappers() []interface{} {
 		(*QueryResponse_PatternsResponse)(nil),
 		(*QueryResponse_DetectedLabels)(nil),
 		(*QueryResponse_CountMinSketches)(nil),
+		(*QueryResponse_Table)(nil),
 	}
 }
 
@@ -1364,7 +1489,7 @@ type QueryRequest struct {
 func (m *QueryRequest) Reset()      { *m = QueryRequest{} }
 func (*QueryRequest) ProtoMessage() {}
 func (*QueryRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_51b9d53b40d11902, []int{19}
+	return fileDescriptor_51b9d53b40d11902, []int{21}
 }
 func (m *QueryRequest) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -1557,6 +1682,8 @@ func init() {
 	proto.RegisterType((*TopKSketchesResponse)(nil), "queryrange.TopKSketchesResponse")
 	proto.RegisterType((*QuantileSketchResponse)(nil), "queryrange.QuantileSketchResponse")
 	proto.RegisterType((*CountMinSketchResponse)(nil), "queryrange.CountMinSketchResponse")
+	proto.RegisterType((*TableResponse)(nil), "queryrange.TableResponse")
+	proto.RegisterType((*TableRow)(nil), "queryrange.TableRow")
 	proto.RegisterType((*ShardsResponse)(nil), "queryrange.ShardsResponse")
 	proto.RegisterType((*DetectedFieldsResponse)(nil), "queryrange.DetectedFieldsResponse")
 	proto.RegisterType((*QueryPatternsResponse)(nil), "queryrange.QueryPatternsResponse")
@@ -1571,132 +1698,137 @@ func init() {
 }
 
 var fileDescriptor_51b9d53b40d11902 = []byte{
-	// 1991 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xec, 0x59, 0xcf, 0x6f, 0x1b, 0xc7,
-	0x15, 0xe6, 0xf2, 0xa7, 0x38, 0x92, 0x68, 0x75, 0xac, 0x2a, 0x5b, 0xc5, 0xe1, 0xb2, 0x04, 0x9a,
-	0xa8, 0x45, 0x4b, 0xc6, 0x54, 0xe2, 0x26, 0xaa, 0x6b, 0xc4, 0x6b, 0xd9, 0x95, 0x5d, 0xbb, 0x71,
-	0x56, 0x42, 0x0e, 0xbd, 0x14, 0x23, 0x72, 0x44, 0x6e, 0x45, 0xee, 0xae, 0x77, 0x86, 0xb2, 0x05,
-	0x14, 0x45, 0xfe, 0x81, 0xa0, 0xb9, 0xf7, 0x5e, 0xf4, 0x56, 0x14, 0x28, 0x7a, 0xe8, 0xa9, 0xc7,
-	0xe4, 0x50, 0xc0, 0xc7, 0x80, 0x40, 0xd9, 0x9a, 0xbe, 0x14, 0x3a, 0x05, 0xe8, 0x3f, 0x50, 0xcc,
-	0x8f, 0x5d, 0xce, 0x70, 0x57, 0x35, 0xe9, 0x16, 0x05, 0x54, 0xf8, 0x42, 0xee, 0xcc, 0xbc, 0x6f,
-	0x76, 0xe6, 0x7b, 0xdf, 0x9b, 0x37, 0x33, 0x0b, 0xde, 0x0a, 0x8e, 0xbb, 0xcd, 0x47, 0x43, 0x1c,
-	0xba, 0x38, 0xe4, 0xff, 0xa7, 0x21, 0xf2, 0xba, 0x58, 0x79, 0x6c, 0x04, 0xa1, 0x4f, 0x7d, 0x08,
-	0xa6, 0x35, 0x9b, 0xad, 0xae, 0x4b, 0x7b, 0xc3, 0xc3, 0x46, 0xdb, 0x1f, 0x34, 0xbb, 0x7e, 0xd7,
-	0x6f, 0x76, 0x7d, 0xbf, 0xdb, 0xc7, 0x28, 0x70, 0x89, 0x7c, 0x6c, 0x86, 0x41, 0xbb, 0x49, 0x28,
-	0xa2, 0x43, 0x22, 0xf0, 0x9b, 0xeb, 0xcc, 0x90, 0x3f, 0x72, 0x88, 0xac, 0xb5, 0xa4, 0x39, 0x2f,
-	0x1d, 0x0e, 0x8f, 0x9a, 0xd4, 0x1d, 0x60, 0x42, 0xd1, 0x20, 0x88, 0x0c, 0xd8, 0xf8, 0xfa, 0x7e,
-	0x57, 0x20, 0x5d, 0xaf, 0x83, 0x9f, 0x74, 0x11, 0xc5, 0x8f, 0xd1, 0xa9, 0x34, 0x78, 0x5d, 0x33,
-	0x88, 0x1e, 0x64, 0xe3, 0xa6, 0xd6, 0x18, 0x20, 0x4a, 0x71, 0xe8, 0xc9, 0xb6, 0x6f, 0x68, 0x6d,
-	0xe4, 0x18, 0xd3, 0x76, 0x4f, 0x36, 0xd5, 0x64, 0xd3, 0xa3, 0xfe, 0xc0, 0xef, 0xe0, 0x3e, 0x9f,
-	0x08, 0x11, 0xbf, 0xd2, 0xe2, 0x32, 0xb3, 0x08, 0x86, 0xa4, 0xc7, 0x7f, 0x64, 0xe5, 0xad, 0x17,
-	0x72, 0x79, 0x88, 0x08, 0x6e, 0x76, 0xf0, 0x91, 0xeb, 0xb9, 0xd4, 0xf5, 0x3d, 0xa2, 0x3e, 0xcb,
-	0x4e, 0xae, 0xcd, 0xd7, 0xc9, 0xac, 0x7f, 0x36, 0xdf, 0x66, 0x38, 0x42, 0xfd, 0x10, 0x75, 0x71,
-	0xb3, 0xdd, 0x1b, 0x7a, 0xc7, 0xcd, 0x36, 0x6a, 0xf7, 0x70, 0x33, 0xc4, 0x64, 0xd8, 0xa7, 0x44,
-	0x14, 0xe8, 0x69, 0x80, 0xe5, 0x9b, 0xea, 0x5f, 0xe4, 0xc1, 0xf2, 0x7d, 0xff, 0xd8, 0x75, 0xf0,
-	0xa3, 0x21, 0x26, 0x14, 0xae, 0x83, 0x02, 0xef, 0xd5, 0x34, 0x6a, 0xc6, 0x56, 0xd9, 0x11, 0x05,
-	0x56, 0xdb, 0x77, 0x07, 0x2e, 0x35, 0xb3, 0x35, 0x63, 0x6b, 0xd5, 0x11, 0x05, 0x08, 0x41, 0x9e,
-	0x50, 0x1c, 0x98, 0xb9, 0x9a, 0xb1, 0x95, 0x73, 0xf8, 0x33, 0xdc, 0x04, 0x4b, 0xae, 0x47, 0x71,
-	0x78, 0x82, 0xfa, 0x66, 0x99, 0xd7, 0xc7, 0x65, 0x78, 0x03, 0x94, 0x08, 0x45, 0x21, 0x3d, 0x20,
-	0x66, 0xbe, 0x66, 0x6c, 0x2d, 0xb7, 0x36, 0x1b, 0xc2, 0xf3, 0x8d, 0xc8, 0xf3, 0x8d, 0x83, 0xc8,
-	0xf3, 0xf6, 0xd2, 0xe7, 0x63, 0x2b, 0xf3, 0xd9, 0xdf, 0x2c, 0xc3, 0x89, 0x40, 0x70, 0x07, 0x14,
-	0xb0, 0xd7, 0x39, 0x20, 0x66, 0x61, 0x01, 0xb4, 0x80, 0xc0, 0xab, 0xa0, 0xdc, 0x71, 0x43, 0xdc,
-	0x66, 0x2c, 0x9b, 0xc5, 0x9a, 0xb1, 0x55, 0x69, 0x5d, 0x6e, 0xc4, 0x42, 0xd9, 0x8d, 0x9a, 0x9c,
-	0xa9, 0x15, 0x9b, 0x5e, 0x80, 0x68, 0xcf, 0x2c, 0x71, 0x26, 0xf8, 0x33, 0xac, 0x83, 0x22, 0xe9,
-	0xa1, 0xb0, 0x43, 0xcc, 0xa5, 0x5a, 0x6e, 0xab, 0x6c, 0x83, 0xb3, 0xb1, 0x25, 0x6b, 0x1c, 0xf9,
-	0x0f, 0x7f, 0x06, 0xf2, 0x41, 0x1f, 0x79, 0x26, 0xe0, 0xa3, 0x5c, 0x6b, 0x28, 0x5e, 0x7a, 0xd8,
-	0x47, 0x9e, 0xfd, 0xfe, 0x68, 0x6c, 0xbd, 0xab, 0x06, 0x4f, 0x88, 0x8e, 0x90, 0x87, 0x9a, 0x7d,
-	0xff, 0xd8, 0x6d, 0x9e, 0x6c, 0x37, 0x55, 0xdf, 0xb3, 0x8e, 0x1a, 0x1f, 0xb1, 0x0e, 0x18, 0xd4,
-	0xe1, 0x1d, 0xc3, 0x7b, 0x60, 0x99, 0xf9, 0x18, 0xdf, 0x62, 0x0e, 0x26, 0xe6, 0x32, 0x7f, 0xcf,
-	0x6b, 0xd3, 0xd9, 0xf0, 0x7a, 0x07, 0x1f, 0xfd, 0x28, 0xf4, 0x87, 0x81, 0x7d, 0xe9, 0x6c, 0x6c,
-	0xa9, 0xf6, 0x8e, 0x5a, 0x80, 0xf7, 0x40, 0x85, 0x89, 0xc2, 0xf5, 0xba, 0x1f, 0x06, 0x5c, 0x81,
-	0xe6, 0x0a, 0xef, 0xee, 0x4a, 0x43, 0x95, 0x4c, 0xe3, 0x96, 0x66, 0x63, 0xe7, 0x19, 0xbd, 0xce,
-	0x0c, 0xb2, 0x3e, 0xc9, 0x01, 0xc8, 0xb4, 0x74, 0xd7, 0x23, 0x14, 0x79, 0xf4, 0x65, 0x24, 0x75,
-	0x1d, 0x14, 0x59, 0xf0, 0x1f, 0x10, 0x2e, 0xaa, 0x79, 0x7d, 0x2c, 0x31, 0xba, 0x93, 0xf3, 0x0b,
-	0x39, 0xb9, 0x90, 0xea, 0xe4, 0xe2, 0x0b, 0x9d, 0x5c, 0xfa, 0x1f, 0x39, 0x79, 0xe9, 0xbf, 0xeb,
-	0xe4, 0xf2, 0x4b, 0x3b, 0xd9, 0x04, 0x79, 0x36, 0x4a, 0xb8, 0x06, 0x72, 0x21, 0x7a, 0xcc, 0x7d,
-	0xba, 0xe2, 0xb0, 0xc7, 0xfa, 0x24, 0x0f, 0x56, 0xc4, 0x52, 0x42, 0x02, 0xdf, 0x23, 0x98, 0xf1,
-	0xb8, 0xcf, 0x57, 0x7f, 0xe1, 0x79, 0xc9, 0x23, 0xaf, 0x71, 0x64, 0x0b, 0xfc, 0x00, 0xe4, 0x77,
-	0x11, 0x45, 0x5c, 0x05, 0xcb, 0xad, 0x75, 0x95, 0x47, 0xd6, 0x17, 0x6b, 0xb3, 0x37, 0xd8, 0x40,
-	0xce, 0xc6, 0x56, 0xa5, 0x83, 0x28, 0xfa, 0xae, 0x3f, 0x70, 0x29, 0x1e, 0x04, 0xf4, 0xd4, 0xe1,
-	0x48, 0xf8, 0x2e, 0x28, 0xdf, 0x0e, 0x43, 0x3f, 0x3c, 0x38, 0x0d, 0x30, 0x57, 0x4d, 0xd9, 0x7e,
-	0xed, 0x6c, 0x6c, 0x5d, 0xc6, 0x51, 0xa5, 0x82, 0x98, 0x5a, 0xc2, 0x6f, 0x83, 0x02, 0x2f, 0x70,
-	0x9d, 0x94, 0xed, 0xcb, 0x67, 0x63, 0xeb, 0x12, 0x87, 0x28, 0xe6, 0xc2, 0x42, 0x97, 0x55, 0x61,
-	0x2e, 0x59, 0xc5, 0xea, 0x2e, 0xaa, 0xea, 0x36, 0x41, 0xe9, 0x04, 0x87, 0x84, 0x75, 0x53, 0xe2,
-	0xf5, 0x51, 0x11, 0xde, 0x04, 0x80, 0x11, 0xe3, 0x12, 0xea, 0xb6, 0x23, 0x67, 0xaf, 0x36, 0x44,
-	0xb2, 0x71, 0xb8, 0x8f, 0x6c, 0x28, 0x59, 0x50, 0x0c, 0x1d, 0xe5, 0x19, 0xfe, 0xce, 0x00, 0xa5,
-	0x3d, 0x8c, 0x3a, 0x38, 0x64, 0xee, 0xcd, 0x6d, 0x2d, 0xb7, 0xbe, 0xd5, 0x50, 0x33, 0xcb, 0xc3,
-	0xd0, 0x1f, 0x60, 0xda, 0xc3, 0x43, 0x12, 0x39, 0x48, 0x58, 0xdb, 0xde, 0x68, 0x6c, 0xe1, 0x39,
-	0xa5, 0x3a, 0x57, 0x42, 0x3b, 0xf7, 0x55, 0x67, 0x63, 0xcb, 0xf8, 0x9e, 0x13, 0x8d, 0x12, 0xb6,
-	0xc0, 0xd2, 0x63, 0x14, 0x7a, 0xae, 0xd7, 0x25, 0x26, 0xe0, 0x91, 0xb6, 0x71, 0x36, 0xb6, 0x60,
-	0x54, 0xa7, 0x38, 0x22, 0xb6, 0xab, 0xff, 0xd5, 0x00, 0x5f, 0x63, 0xc2, 0xd8, 0x67, 0xe3, 0x21,
-	0xca, 0x12, 0x33, 0x40, 0xb4, 0xdd, 0x33, 0x0d, 0xd6, 0x8d, 0x23, 0x0a, 0x6a, 0xbe, 0xc9, 0xfe,
-	0x47, 0xf9, 0x26, 0xb7, 0x78, 0xbe, 0x89, 0xd6, 0x95, 0x7c, 0xea, 0xba, 0x52, 0x38, 0x6f, 0x5d,
-	0xa9, 0xff, 0x4a, 0xae, 0xa1, 0xd1, 0xfc, 0x16, 0x08, 0xa5, 0x3b, 0x71, 0x28, 0xe5, 0xf8, 0x68,
-	0x63, 0x85, 0x8a, 0xbe, 0xee, 0x76, 0xb0, 0x47, 0xdd, 0x23, 0x17, 0x87, 0x2f, 0x08, 0x28, 0x45,
-	0xa5, 0x39, 0x5d, 0xa5, 0xaa, 0xc4, 0xf2, 0x17, 0x42, 0x62, 0x7a, 0x5c, 0x15, 0x5e, 0x22, 0xae,
-	0xea, 0xff, 0xcc, 0x82, 0x0d, 0xe6, 0x91, 0xfb, 0xe8, 0x10, 0xf7, 0x7f, 0x82, 0x06, 0x0b, 0x7a,
-	0xe5, 0x4d, 0xc5, 0x2b, 0x65, 0x1b, 0xbe, 0x62, 0x7d, 0x3e, 0xd6, 0x7f, 0x63, 0x80, 0xa5, 0x28,
-	0x01, 0xc0, 0x06, 0x00, 0x02, 0xc6, 0xd7, 0x78, 0xc1, 0x75, 0x85, 0x81, 0xc3, 0xb8, 0xd6, 0x51,
-	0x2c, 0xe0, 0xcf, 0x41, 0x51, 0x94, 0x64, 0x2c, 0x28, 0x69, 0x73, 0x9f, 0x86, 0x18, 0x0d, 0x6e,
-	0x76, 0x50, 0x40, 0x71, 0x68, 0xbf, 0xcf, 0x46, 0x31, 0x1a, 0x5b, 0x6f, 0x9d, 0xc7, 0x52, 0xb4,
-	0xc3, 0x97, 0x38, 0xe6, 0x5f, 0xf1, 0x4e, 0x47, 0xbe, 0xa1, 0xfe, 0xa9, 0x01, 0xd6, 0xd8, 0x40,
-	0x19, 0x35, 0xb1, 0x30, 0x76, 0xc1, 0x52, 0x28, 0x9f, 0xf9, 0x70, 0x97, 0x5b, 0xf5, 0x86, 0x4e,
-	0x6b, 0x0a, 0x95, 0x3c, 0xe1, 0x1a, 0x4e, 0x8c, 0x84, 0xdb, 0x1a, 0x8d, 0xd9, 0x34, 0x1a, 0x45,
-	0x8e, 0x56, 0x89, 0xfb, 0x73, 0x16, 0xc0, 0xbb, 0xec, 0x84, 0xc4, 0xf4, 0x37, 0x95, 0xea, 0x93,
-	0xc4, 0x88, 0xae, 0x4c, 0x49, 0x49, 0xda, 0xdb, 0x37, 0x46, 0x63, 0x6b, 0xe7, 0x05, 0xda, 0xf9,
-	0x37, 0x78, 0x65, 0x16, 0xaa, 0x7c, 0xb3, 0x17, 0x41, 0xbe, 0xf5, 0x3f, 0x64, 0x41, 0xe5, 0x63,
-	0xbf, 0x3f, 0x1c, 0xe0, 0x98, 0xbe, 0x20, 0x41, 0x9f, 0x39, 0xa5, 0x4f, 0xb7, 0xb5, 0x77, 0x46,
-	0x63, 0xeb, 0xda, 0xbc, 0xd4, 0xe9, 0xd8, 0x0b, 0x4d, 0xdb, 0xaf, 0x73, 0x60, 0xfd, 0xc0, 0x0f,
-	0x7e, 0xbc, 0xcf, 0x4f, 0xd1, 0xca, 0x32, 0xd9, 0x4b, 0x90, 0xb7, 0x3e, 0x25, 0x8f, 0x21, 0x1e,
-	0x20, 0x1a, 0xba, 0x4f, 0xec, 0x6b, 0xa3, 0xb1, 0xd5, 0x9a, 0x97, 0xb8, 0x29, 0xee, 0x22, 0x93,
-	0xa6, 0xed, 0x81, 0x72, 0xf3, 0xed, 0x81, 0x66, 0xd6, 0x85, 0xfc, 0x7c, 0xeb, 0xc2, 0xef, 0x73,
-	0x60, 0xe3, 0xa3, 0x21, 0xf2, 0xa8, 0xdb, 0xc7, 0xc2, 0x43, 0xb1, 0x7f, 0x7e, 0x91, 0xf0, 0x4f,
-	0x75, 0xea, 0x1f, 0x1d, 0x23, 0x3d, 0xf5, 0xc1, 0x68, 0x6c, 0x5d, 0x9f, 0xd7, 0x53, 0x69, 0x3d,
-	0xbc, 0xf2, 0xd9, 0xbc, 0x3e, 0xbb, 0xe5, 0x0f, 0x3d, 0xfa, 0xc0, 0xf5, 0x16, 0xf1, 0x99, 0x8e,
-	0xf9, 0x18, 0xb7, 0xa9, 0x1f, 0x2e, 0xe6, 0xb3, 0xb4, 0x1e, 0x5e, 0xf9, 0x6c, 0x1e, 0x9f, 0xfd,
-	0x29, 0x0b, 0x2a, 0xfb, 0x62, 0x4f, 0x1f, 0xb1, 0x75, 0x92, 0xe2, 0x2b, 0xf5, 0x12, 0x33, 0x38,
-	0x6c, 0xe8, 0x88, 0xc5, 0x52, 0x88, 0x8e, 0xbd, 0xd0, 0x29, 0xe4, 0x2f, 0x59, 0xb0, 0xb1, 0x8b,
-	0x29, 0x6e, 0x53, 0xdc, 0xb9, 0xe3, 0xe2, 0xbe, 0x42, 0xe2, 0x27, 0x46, 0x82, 0xc5, 0x9a, 0x72,
-	0x08, 0x4f, 0x05, 0xd9, 0xf6, 0x68, 0x6c, 0xdd, 0x98, 0x97, 0xc7, 0xf4, 0x3e, 0x2e, 0x34, 0x9f,
-	0x5f, 0x64, 0xc1, 0xd7, 0xc5, 0xc5, 0x92, 0xb8, 0xf5, 0x9e, 0xd2, 0xf9, 0xcb, 0x04, 0x9b, 0x96,
-	0xba, 0xe6, 0xa7, 0x40, 0xec, 0x9b, 0xa3, 0xb1, 0xf5, 0xc3, 0xf9, 0x17, 0xfd, 0x94, 0x2e, 0xfe,
-	0x6f, 0xb4, 0xc9, 0xcf, 0x82, 0x8b, 0x6a, 0x53, 0x07, 0xbd, 0x9c, 0x36, 0xf5, 0x3e, 0x2e, 0x34,
-	0x9f, 0x7f, 0x2c, 0x81, 0x55, 0xae, 0x92, 0x98, 0xc6, 0xef, 0x00, 0x79, 0x78, 0x96, 0x1c, 0xc2,
-	0xe8, 0xc2, 0x25, 0x0c, 0xda, 0x8d, 0x7d, 0x79, 0xac, 0x16, 0x16, 0xf0, 0x3d, 0x50, 0x24, 0xfc,
-	0x5a, 0x43, 0x9e, 0x8b, 0xaa, 0xb3, 0x37, 0x87, 0xfa, 0x05, 0xca, 0x5e, 0xc6, 0x91, 0xf6, 0xf0,
-	0x3a, 0x28, 0xf6, 0x39, 0x8b, 0xf2, 0x5a, 0xa7, 0x3e, 0x8b, 0x4c, 0x1e, 0xf4, 0x19, 0x5a, 0x60,
-	0xe0, 0x35, 0x50, 0xe0, 0x09, 0x40, 0xa6, 0x03, 0xed, 0xb5, 0xc9, 0x63, 0xd0, 0x5e, 0xc6, 0x11,
-	0xe6, 0xb0, 0x05, 0xf2, 0x41, 0xe8, 0x0f, 0xe4, 0x61, 0xf8, 0xca, 0xec, 0x3b, 0xd5, 0xd3, 0xe3,
-	0x5e, 0xc6, 0xe1, 0xb6, 0xf0, 0x1d, 0x50, 0x22, 0xfc, 0xd8, 0x49, 0xf8, 0x35, 0x22, 0x3b, 0x73,
-	0xcc, 0xc0, 0x14, 0x48, 0x64, 0x0a, 0xdf, 0x01, 0xc5, 0x13, 0x7e, 0xa8, 0x90, 0x77, 0xd3, 0x9b,
-	0x2a, 0x48, 0x3f, 0x6e, 0xb0, 0x79, 0x09, 0x5b, 0x78, 0x07, 0xac, 0x50, 0x3f, 0x38, 0x8e, 0xf6,
-	0xee, 0xf2, 0x0a, 0xb2, 0xa6, 0x62, 0xd3, 0xf6, 0xf6, 0x7b, 0x19, 0x47, 0xc3, 0xc1, 0x87, 0x60,
-	0xed, 0x91, 0xb6, 0xdf, 0xc3, 0xd1, 0x65, 0xb3, 0xc6, 0x73, 0xfa, 0x4e, 0x74, 0x2f, 0xe3, 0x24,
-	0xd0, 0x70, 0x17, 0x54, 0x88, 0x96, 0xe1, 0xe4, 0x87, 0x15, 0x6d, 0x5e, 0x7a, 0x0e, 0xdc, 0xcb,
-	0x38, 0x33, 0x18, 0x78, 0x1f, 0x54, 0x3a, 0xda, 0xfa, 0x2e, 0x3f, 0x9b, 0x68, 0xa3, 0x4a, 0xcf,
-	0x00, 0xac, 0x37, 0x1d, 0x0b, 0x3f, 0x04, 0x6b, 0xc1, 0xcc, 0xda, 0x26, 0xbf, 0x9b, 0x7c, 0x53,
-	0x9f, 0x65, 0xca, 0x22, 0xc8, 0x26, 0x39, 0x0b, 0x56, 0x87, 0x27, 0x42, 0xdc, 0x5c, 0x3d, 0x7f,
-	0x78, 0xfa, 0x22, 0xa0, 0x0e, 0x4f, 0xb4, 0x30, 0x27, 0xb4, 0xb5, 0x0d, 0x1c, 0x26, 0x66, 0x25,
-	0xd9, 0x5f, 0xfa, 0xd6, 0x92, 0x8d, 0x6f, 0x16, 0x6d, 0x83, 0xe9, 0x02, 0x57, 0xff, 0xb4, 0x08,
-	0x56, 0x64, 0xe0, 0x8a, 0xdb, 0xd7, 0xef, 0xc7, 0xb1, 0x28, 0xe2, 0xf6, 0x8d, 0xf3, 0x62, 0x91,
-	0x9b, 0x2b, 0xa1, 0xf8, 0x76, 0x1c, 0x8a, 0x22, 0x88, 0x37, 0xa6, 0x8b, 0x26, 0x9f, 0x89, 0x82,
-	0x90, 0xe1, 0xb7, 0x1d, 0x85, 0x9f, 0x88, 0xdd, 0xd7, 0xd3, 0xef, 0x30, 0x22, 0x94, 0x8c, 0xbd,
-	0x1d, 0x50, 0x72, 0xc5, 0x27, 0xa9, 0xb4, 0xa8, 0x4d, 0x7e, 0xb1, 0x62, 0xd1, 0x24, 0x01, 0x70,
-	0x7b, 0x1a, 0x83, 0x05, 0xf9, 0x09, 0x26, 0x11, 0x83, 0x31, 0x28, 0x0a, 0xc1, 0xab, 0x71, 0x08,
-	0x16, 0x67, 0x3f, 0xdb, 0x44, 0x01, 0x18, 0x4f, 0x4c, 0xc6, 0xdf, 0x6d, 0xb0, 0x1a, 0x29, 0x96,
-	0x37, 0xc9, 0x00, 0x7c, 0xe3, 0xbc, 0x8d, 0x62, 0x84, 0xd7, 0x51, 0xf0, 0x6e, 0x42, 0xe6, 0xe5,
-	0xd9, 0xe4, 0x3e, 0x2b, 0xf2, 0xa8, 0xa7, 0x59, 0x8d, 0xdf, 0x03, 0x97, 0xa6, 0x32, 0x15, 0x63,
-	0x02, 0xc9, 0xc3, 0xa1, 0x26, 0xf0, 0xa8, 0xab, 0x59, 0xa0, 0x3a, 0x2c, 0x29, 0xef, 0xe5, 0xf3,
-	0x86, 0x15, 0x89, 0x3b, 0x31, 0x2c, 0xa9, 0xed, 0x3d, 0xb0, 0x34, 0xc0, 0x14, 0x75, 0x10, 0x45,
-	0x66, 0x89, 0x27, 0xba, 0x37, 0x13, 0x21, 0x27, 0xd1, 0x8d, 0x07, 0xd2, 0xf0, 0xb6, 0x47, 0xc3,
-	0x53, 0xb9, 0x57, 0x8f, 0xd1, 0x9b, 0x3f, 0x00, 0xab, 0x9a, 0x01, 0x5c, 0x03, 0xb9, 0x63, 0x1c,
-	0x7d, 0xa6, 0x64, 0x8f, 0x70, 0x1d, 0x14, 0x4e, 0x50, 0x7f, 0x88, 0xb9, 0x3e, 0xcb, 0x8e, 0x28,
-	0xec, 0x64, 0xdf, 0x33, 0xec, 0x32, 0x28, 0x85, 0xe2, 0x2d, 0x76, 0xf7, 0xe9, 0xb3, 0x6a, 0xe6,
-	0xcb, 0x67, 0xd5, 0xcc, 0x57, 0xcf, 0xaa, 0xc6, 0x27, 0x93, 0xaa, 0xf1, 0xdb, 0x49, 0xd5, 0xf8,
-	0x7c, 0x52, 0x35, 0x9e, 0x4e, 0xaa, 0xc6, 0xdf, 0x27, 0x55, 0xe3, 0x1f, 0x93, 0x6a, 0xe6, 0xab,
-	0x49, 0xd5, 0xf8, 0xec, 0x79, 0x35, 0xf3, 0xf4, 0x79, 0x35, 0xf3, 0xe5, 0xf3, 0x6a, 0xe6, 0xa7,
-	0x57, 0x17, 0xce, 0xb9, 0x87, 0x45, 0xce, 0xd4, 0xf6, 0xbf, 0x02, 0x00, 0x00, 0xff, 0xff, 0xaa,
-	0x37, 0x05, 0xb8, 0xaf, 0x21, 0x00, 0x00,
+	// 2067 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xec, 0x59, 0xcd, 0x6f, 0x1b, 0xc7,
+	0x15, 0xe7, 0xf2, 0x53, 0x1c, 0x7d, 0x58, 0x1d, 0xab, 0xca, 0x46, 0x71, 0xb8, 0x2a, 0x81, 0x26,
+	0x6a, 0xd1, 0x2e, 0x63, 0x29, 0x71, 0x13, 0x35, 0x35, 0xe2, 0xb5, 0xec, 0xca, 0xae, 0xdd, 0x38,
+	0x2b, 0x21, 0x87, 0x5e, 0x8a, 0x11, 0x39, 0x22, 0xb7, 0x22, 0x77, 0xd7, 0xbb, 0x43, 0xc9, 0x02,
+	0x8a, 0x22, 0xff, 0x40, 0xd0, 0xdc, 0x7b, 0x2f, 0x7a, 0x2b, 0x0a, 0xf4, 0xd4, 0x53, 0x8f, 0xc9,
+	0xa1, 0x80, 0x8f, 0x01, 0x81, 0xb2, 0x35, 0x7d, 0x69, 0x75, 0x0a, 0xd0, 0xfe, 0x01, 0xc5, 0x7c,
+	0x2d, 0x67, 0xb8, 0xab, 0x9a, 0x54, 0x8b, 0x02, 0x2a, 0x7c, 0x21, 0xe7, 0xe3, 0xfd, 0x66, 0xdf,
+	0xfc, 0xde, 0x7b, 0xf3, 0xe6, 0x03, 0xbc, 0x19, 0x1e, 0xb5, 0x1b, 0x8f, 0xfb, 0x38, 0xf2, 0x70,
+	0xc4, 0xfe, 0x4f, 0x23, 0xe4, 0xb7, 0xb1, 0x52, 0xb4, 0xc3, 0x28, 0x20, 0x01, 0x04, 0xe3, 0x96,
+	0xb5, 0xcd, 0xb6, 0x47, 0x3a, 0xfd, 0x03, 0xbb, 0x19, 0xf4, 0x1a, 0xed, 0xa0, 0x1d, 0x34, 0xda,
+	0x41, 0xd0, 0xee, 0x62, 0x14, 0x7a, 0xb1, 0x28, 0x36, 0xa2, 0xb0, 0xd9, 0x88, 0x09, 0x22, 0xfd,
+	0x98, 0xe3, 0xd7, 0x56, 0xa8, 0x20, 0x2b, 0x32, 0x88, 0x68, 0xb5, 0x84, 0x38, 0xab, 0x1d, 0xf4,
+	0x0f, 0x1b, 0xc4, 0xeb, 0xe1, 0x98, 0xa0, 0x5e, 0x28, 0x05, 0xa8, 0x7e, 0xdd, 0xa0, 0xcd, 0x91,
+	0x9e, 0xdf, 0xc2, 0x4f, 0xda, 0x88, 0xe0, 0x13, 0x74, 0x2a, 0x04, 0x5e, 0xd3, 0x04, 0x64, 0x41,
+	0x74, 0xae, 0x69, 0x9d, 0x21, 0x22, 0x04, 0x47, 0xbe, 0xe8, 0x7b, 0x55, 0xeb, 0x8b, 0x8f, 0x30,
+	0x69, 0x76, 0x44, 0xd7, 0xba, 0xe8, 0x7a, 0xdc, 0xed, 0x05, 0x2d, 0xdc, 0x65, 0x13, 0x89, 0xf9,
+	0xaf, 0x90, 0xb8, 0x4a, 0x25, 0xc2, 0x7e, 0xdc, 0x61, 0x3f, 0xa2, 0xf1, 0xf6, 0x0b, 0xb9, 0x3c,
+	0x40, 0x31, 0x6e, 0xb4, 0xf0, 0xa1, 0xe7, 0x7b, 0xc4, 0x0b, 0xfc, 0x58, 0x2d, 0x8b, 0x41, 0x6e,
+	0x4c, 0x37, 0xc8, 0xa4, 0x7d, 0xd6, 0xde, 0xa2, 0xb8, 0x98, 0x04, 0x11, 0x6a, 0xe3, 0x46, 0xb3,
+	0xd3, 0xf7, 0x8f, 0x1a, 0x4d, 0xd4, 0xec, 0xe0, 0x46, 0x84, 0xe3, 0x7e, 0x97, 0xc4, 0xbc, 0x42,
+	0x4e, 0x43, 0x2c, 0xbe, 0x54, 0xff, 0xa2, 0x08, 0xe6, 0x1f, 0x04, 0x47, 0x9e, 0x8b, 0x1f, 0xf7,
+	0x71, 0x4c, 0xe0, 0x0a, 0x28, 0xb1, 0x51, 0x4d, 0x63, 0xdd, 0xd8, 0xa8, 0xba, 0xbc, 0x42, 0x5b,
+	0xbb, 0x5e, 0xcf, 0x23, 0x66, 0x7e, 0xdd, 0xd8, 0x58, 0x74, 0x79, 0x05, 0x42, 0x50, 0x8c, 0x09,
+	0x0e, 0xcd, 0xc2, 0xba, 0xb1, 0x51, 0x70, 0x59, 0x19, 0xae, 0x81, 0x39, 0xcf, 0x27, 0x38, 0x3a,
+	0x46, 0x5d, 0xb3, 0xca, 0xda, 0x93, 0x3a, 0xbc, 0x09, 0x2a, 0x31, 0x41, 0x11, 0xd9, 0x8f, 0xcd,
+	0xe2, 0xba, 0xb1, 0x31, 0xbf, 0xb9, 0x66, 0x73, 0xcb, 0xdb, 0xd2, 0xf2, 0xf6, 0xbe, 0xb4, 0xbc,
+	0x33, 0xf7, 0xf9, 0xd0, 0xca, 0x7d, 0xf6, 0x17, 0xcb, 0x70, 0x25, 0x08, 0x6e, 0x83, 0x12, 0xf6,
+	0x5b, 0xfb, 0xb1, 0x59, 0x9a, 0x01, 0xcd, 0x21, 0xf0, 0x3a, 0xa8, 0xb6, 0xbc, 0x08, 0x37, 0x29,
+	0xcb, 0x66, 0x79, 0xdd, 0xd8, 0x58, 0xda, 0xbc, 0x6a, 0x27, 0x8e, 0xb2, 0x23, 0xbb, 0xdc, 0xb1,
+	0x14, 0x9d, 0x5e, 0x88, 0x48, 0xc7, 0xac, 0x30, 0x26, 0x58, 0x19, 0xd6, 0x41, 0x39, 0xee, 0xa0,
+	0xa8, 0x15, 0x9b, 0x73, 0xeb, 0x85, 0x8d, 0xaa, 0x03, 0xce, 0x86, 0x96, 0x68, 0x71, 0xc5, 0x3f,
+	0xfc, 0x29, 0x28, 0x86, 0x5d, 0xe4, 0x9b, 0x80, 0x69, 0xb9, 0x6c, 0x2b, 0x56, 0x7a, 0xd4, 0x45,
+	0xbe, 0xf3, 0xde, 0x60, 0x68, 0xbd, 0xa3, 0x06, 0x4f, 0x84, 0x0e, 0x91, 0x8f, 0x1a, 0xdd, 0xe0,
+	0xc8, 0x6b, 0x1c, 0x6f, 0x35, 0x54, 0xdb, 0xd3, 0x81, 0xec, 0x8f, 0xe8, 0x00, 0x14, 0xea, 0xb2,
+	0x81, 0xe1, 0x7d, 0x30, 0x4f, 0x6d, 0x8c, 0x6f, 0x53, 0x03, 0xc7, 0xe6, 0x3c, 0xfb, 0xce, 0x2b,
+	0xe3, 0xd9, 0xb0, 0x76, 0x17, 0x1f, 0xfe, 0x30, 0x0a, 0xfa, 0xa1, 0x73, 0xe5, 0x6c, 0x68, 0xa9,
+	0xf2, 0xae, 0x5a, 0x81, 0xf7, 0xc1, 0x12, 0x75, 0x0a, 0xcf, 0x6f, 0x7f, 0x18, 0x32, 0x0f, 0x34,
+	0x17, 0xd8, 0x70, 0xd7, 0x6c, 0xd5, 0x65, 0xec, 0xdb, 0x9a, 0x8c, 0x53, 0xa4, 0xf4, 0xba, 0x13,
+	0xc8, 0xfa, 0xa8, 0x00, 0x20, 0xf5, 0xa5, 0x7b, 0x7e, 0x4c, 0x90, 0x4f, 0x2e, 0xe2, 0x52, 0xef,
+	0x83, 0x32, 0x0d, 0xfe, 0xfd, 0xd8, 0x2c, 0xcc, 0x60, 0x63, 0x81, 0xd1, 0x8d, 0x5c, 0x9c, 0xc9,
+	0xc8, 0xa5, 0x4c, 0x23, 0x97, 0x5f, 0x68, 0xe4, 0xca, 0xff, 0xc8, 0xc8, 0x73, 0xff, 0x5d, 0x23,
+	0x57, 0x2f, 0x6c, 0x64, 0x13, 0x14, 0xa9, 0x96, 0x70, 0x19, 0x14, 0x22, 0x74, 0xc2, 0x6c, 0xba,
+	0xe0, 0xd2, 0x62, 0x7d, 0x54, 0x04, 0x0b, 0x7c, 0x29, 0x89, 0xc3, 0xc0, 0x8f, 0x31, 0xe5, 0x71,
+	0x8f, 0xad, 0xfe, 0xdc, 0xf2, 0x82, 0x47, 0xd6, 0xe2, 0x8a, 0x1e, 0xf8, 0x01, 0x28, 0xee, 0x20,
+	0x82, 0x98, 0x17, 0xcc, 0x6f, 0xae, 0xa8, 0x3c, 0xd2, 0xb1, 0x68, 0x9f, 0xb3, 0x4a, 0x15, 0x39,
+	0x1b, 0x5a, 0x4b, 0x2d, 0x44, 0xd0, 0x77, 0x82, 0x9e, 0x47, 0x70, 0x2f, 0x24, 0xa7, 0x2e, 0x43,
+	0xc2, 0x77, 0x40, 0xf5, 0x4e, 0x14, 0x05, 0xd1, 0xfe, 0x69, 0x88, 0x99, 0xd7, 0x54, 0x9d, 0x57,
+	0xce, 0x86, 0xd6, 0x55, 0x2c, 0x1b, 0x15, 0xc4, 0x58, 0x12, 0x7e, 0x0b, 0x94, 0x58, 0x85, 0xf9,
+	0x49, 0xd5, 0xb9, 0x7a, 0x36, 0xb4, 0xae, 0x30, 0x88, 0x22, 0xce, 0x25, 0x74, 0xb7, 0x2a, 0x4d,
+	0xe5, 0x56, 0x89, 0x77, 0x97, 0x55, 0xef, 0x36, 0x41, 0xe5, 0x18, 0x47, 0xb1, 0x17, 0x70, 0xbf,
+	0x59, 0x74, 0x65, 0x15, 0xde, 0x02, 0x80, 0x12, 0xe3, 0xc5, 0xc4, 0x6b, 0x4a, 0x63, 0x2f, 0xda,
+	0x3c, 0xd9, 0xb8, 0xcc, 0x46, 0x0e, 0x14, 0x2c, 0x28, 0x82, 0xae, 0x52, 0x86, 0xbf, 0x35, 0x40,
+	0x65, 0x17, 0xa3, 0x16, 0x8e, 0xa8, 0x79, 0x0b, 0x1b, 0xf3, 0x9b, 0xdf, 0xb4, 0xd5, 0xcc, 0xf2,
+	0x28, 0x0a, 0x7a, 0x98, 0x74, 0x70, 0x3f, 0x96, 0x06, 0xe2, 0xd2, 0x8e, 0x3f, 0x18, 0x5a, 0x78,
+	0x4a, 0x57, 0x9d, 0x2a, 0xa1, 0x9d, 0xfb, 0xa9, 0xb3, 0xa1, 0x65, 0x7c, 0xd7, 0x95, 0x5a, 0xc2,
+	0x4d, 0x30, 0x77, 0x82, 0x22, 0xdf, 0xf3, 0xdb, 0xb1, 0x09, 0x58, 0xa4, 0xad, 0x9e, 0x0d, 0x2d,
+	0x28, 0xdb, 0x14, 0x43, 0x24, 0x72, 0xf5, 0x3f, 0x1b, 0xe0, 0x6b, 0xd4, 0x31, 0xf6, 0xa8, 0x3e,
+	0xb1, 0xb2, 0xc4, 0xf4, 0x10, 0x69, 0x76, 0x4c, 0x83, 0x0e, 0xe3, 0xf2, 0x8a, 0x9a, 0x6f, 0xf2,
+	0xff, 0x51, 0xbe, 0x29, 0xcc, 0x9e, 0x6f, 0xe4, 0xba, 0x52, 0xcc, 0x5c, 0x57, 0x4a, 0xe7, 0xad,
+	0x2b, 0xf5, 0x5f, 0x8a, 0x35, 0x54, 0xce, 0x6f, 0x86, 0x50, 0xba, 0x9b, 0x84, 0x52, 0x81, 0x69,
+	0x9b, 0x78, 0x28, 0x1f, 0xeb, 0x5e, 0x0b, 0xfb, 0xc4, 0x3b, 0xf4, 0x70, 0xf4, 0x82, 0x80, 0x52,
+	0xbc, 0xb4, 0xa0, 0x7b, 0xa9, 0xea, 0x62, 0xc5, 0x4b, 0xe1, 0x62, 0x7a, 0x5c, 0x95, 0x2e, 0x10,
+	0x57, 0xf5, 0x7f, 0xe4, 0xc1, 0x2a, 0xb5, 0xc8, 0x03, 0x74, 0x80, 0xbb, 0x3f, 0x46, 0xbd, 0x19,
+	0xad, 0xf2, 0x86, 0x62, 0x95, 0xaa, 0x03, 0x5f, 0xb2, 0x3e, 0x1d, 0xeb, 0xbf, 0x36, 0xc0, 0x9c,
+	0x4c, 0x00, 0xd0, 0x06, 0x80, 0xc3, 0xd8, 0x1a, 0xcf, 0xb9, 0x5e, 0xa2, 0xe0, 0x28, 0x69, 0x75,
+	0x15, 0x09, 0xf8, 0x33, 0x50, 0xe6, 0x35, 0x11, 0x0b, 0x4a, 0xda, 0xdc, 0x23, 0x11, 0x46, 0xbd,
+	0x5b, 0x2d, 0x14, 0x12, 0x1c, 0x39, 0xef, 0x51, 0x2d, 0x06, 0x43, 0xeb, 0xcd, 0xf3, 0x58, 0x92,
+	0x3b, 0x7c, 0x81, 0xa3, 0xf6, 0xe5, 0xdf, 0x74, 0xc5, 0x17, 0xea, 0x9f, 0x1a, 0x60, 0x99, 0x2a,
+	0x4a, 0xa9, 0x49, 0x1c, 0x63, 0x07, 0xcc, 0x45, 0xa2, 0xcc, 0xd4, 0x9d, 0xdf, 0xac, 0xdb, 0x3a,
+	0xad, 0x19, 0x54, 0xb2, 0x84, 0x6b, 0xb8, 0x09, 0x12, 0x6e, 0x69, 0x34, 0xe6, 0xb3, 0x68, 0xe4,
+	0x39, 0x5a, 0x25, 0xee, 0x8f, 0x79, 0x00, 0xef, 0xd1, 0x13, 0x12, 0xf5, 0xbf, 0xb1, 0xab, 0x3e,
+	0x49, 0x69, 0x74, 0x6d, 0x4c, 0x4a, 0x5a, 0xde, 0xb9, 0x39, 0x18, 0x5a, 0xdb, 0x2f, 0xf0, 0x9d,
+	0x7f, 0x83, 0x57, 0x66, 0xa1, 0xba, 0x6f, 0xfe, 0x32, 0xb8, 0x6f, 0xfd, 0xf7, 0x79, 0xb0, 0xf4,
+	0x71, 0xd0, 0xed, 0xf7, 0x70, 0x42, 0x5f, 0x98, 0xa2, 0xcf, 0x1c, 0xd3, 0xa7, 0xcb, 0x3a, 0xdb,
+	0x83, 0xa1, 0x75, 0x63, 0x5a, 0xea, 0x74, 0xec, 0xa5, 0xa6, 0xed, 0x57, 0x05, 0xb0, 0xb2, 0x1f,
+	0x84, 0x3f, 0xda, 0x63, 0xa7, 0x68, 0x65, 0x99, 0xec, 0xa4, 0xc8, 0x5b, 0x19, 0x93, 0x47, 0x11,
+	0x0f, 0x11, 0x89, 0xbc, 0x27, 0xce, 0x8d, 0xc1, 0xd0, 0xda, 0x9c, 0x96, 0xb8, 0x31, 0xee, 0x32,
+	0x93, 0xa6, 0xed, 0x81, 0x0a, 0xd3, 0xed, 0x81, 0x26, 0xd6, 0x85, 0xe2, 0x74, 0xeb, 0xc2, 0xef,
+	0x0a, 0x60, 0xf5, 0xa3, 0x3e, 0xf2, 0x89, 0xd7, 0xc5, 0xdc, 0x42, 0x89, 0x7d, 0x7e, 0x9e, 0xb2,
+	0x4f, 0x6d, 0x6c, 0x1f, 0x1d, 0x23, 0x2c, 0xf5, 0xc1, 0x60, 0x68, 0xbd, 0x3f, 0xad, 0xa5, 0xb2,
+	0x46, 0x78, 0x69, 0xb3, 0x69, 0x6d, 0x76, 0x3b, 0xe8, 0xfb, 0xe4, 0xa1, 0xe7, 0xcf, 0x62, 0x33,
+	0x1d, 0xf3, 0x31, 0x6e, 0x92, 0x20, 0x9a, 0xcd, 0x66, 0x59, 0x23, 0xbc, 0xb4, 0xd9, 0x34, 0x36,
+	0xfb, 0x67, 0x1e, 0x2c, 0xee, 0xa3, 0x83, 0xee, 0x38, 0x77, 0x98, 0xa0, 0xd2, 0xa4, 0xab, 0xbc,
+	0x1f, 0x8b, 0xe3, 0x89, 0xac, 0x42, 0x1b, 0x14, 0xa3, 0xe0, 0x44, 0x52, 0xa8, 0x1d, 0x7e, 0xf9,
+	0x10, 0xc1, 0x89, 0xf8, 0x02, 0x93, 0xd3, 0x68, 0x2f, 0x5c, 0x3a, 0xda, 0x8b, 0x17, 0xa2, 0xbd,
+	0x34, 0x1d, 0xed, 0x75, 0x30, 0x27, 0x29, 0x83, 0xab, 0xa0, 0x7c, 0x8c, 0xba, 0x7d, 0x2c, 0xf9,
+	0x16, 0xb5, 0xfa, 0x1f, 0xf2, 0x60, 0x69, 0x8f, 0x1f, 0xb7, 0xa4, 0x6d, 0x8e, 0x33, 0xc2, 0x48,
+	0xbd, 0x5f, 0x0e, 0x0f, 0x6c, 0x1d, 0x31, 0x5b, 0x76, 0xd7, 0xb1, 0x97, 0x3a, 0xbb, 0xff, 0x29,
+	0x0f, 0x56, 0x77, 0x30, 0xc1, 0x4d, 0x82, 0x5b, 0x77, 0x3d, 0xdc, 0x55, 0x48, 0xfc, 0xc4, 0x48,
+	0xb1, 0xb8, 0xae, 0xdc, 0x8f, 0x64, 0x82, 0x1c, 0x67, 0x30, 0xb4, 0x6e, 0x4e, 0xcb, 0x63, 0xf6,
+	0x18, 0x97, 0x9a, 0xcf, 0x2f, 0xf2, 0xe0, 0xeb, 0xfc, 0xce, 0x8f, 0x3f, 0x48, 0x8c, 0xe9, 0xfc,
+	0x45, 0x8a, 0x4d, 0x4b, 0x4d, 0xc7, 0x19, 0x10, 0xe7, 0xd6, 0x60, 0x68, 0xfd, 0x60, 0xfa, 0x7c,
+	0x9c, 0x31, 0xc4, 0xff, 0x8d, 0x6f, 0xb2, 0x63, 0xfa, 0xac, 0xbe, 0xa9, 0x83, 0x2e, 0xe6, 0x9b,
+	0xfa, 0x18, 0x97, 0x9a, 0xcf, 0xbf, 0x57, 0xc0, 0x22, 0xf3, 0x92, 0x84, 0xc6, 0x6f, 0x03, 0x71,
+	0xaf, 0x21, 0x38, 0x84, 0xf2, 0x2e, 0x2c, 0x0a, 0x9b, 0xf6, 0x9e, 0xb8, 0xf1, 0xe0, 0x12, 0xf0,
+	0x5d, 0x50, 0x8e, 0xa9, 0x52, 0xf2, 0xc8, 0x5a, 0x9b, 0xbc, 0xd4, 0xd5, 0xef, 0xb6, 0x76, 0x73,
+	0xae, 0x90, 0xa7, 0xb7, 0xff, 0x5d, 0xc6, 0xa2, 0x59, 0x48, 0x1d, 0x9a, 0xed, 0xec, 0x3b, 0x18,
+	0x8a, 0xe6, 0x18, 0x78, 0x03, 0x94, 0x58, 0x92, 0x30, 0x8b, 0xe9, 0xcf, 0xa6, 0x4f, 0xa8, 0xbb,
+	0x39, 0x97, 0x8b, 0xc3, 0x4d, 0x50, 0x0c, 0xa3, 0xa0, 0x27, 0x32, 0xcd, 0xb5, 0xc9, 0x6f, 0xaa,
+	0x07, 0xfb, 0xdd, 0x9c, 0xcb, 0x64, 0xe1, 0xdb, 0xf4, 0x6a, 0x31, 0xc2, 0xa8, 0x17, 0x9b, 0x65,
+	0x71, 0x1c, 0x9c, 0x80, 0x29, 0x10, 0x29, 0x0a, 0xdf, 0x06, 0xe5, 0x63, 0x76, 0xde, 0x13, 0xcf,
+	0x06, 0x6b, 0x2a, 0x48, 0x3f, 0x09, 0xd2, 0x79, 0x71, 0x59, 0x78, 0x17, 0x2c, 0x90, 0x20, 0x3c,
+	0x92, 0xc7, 0x2a, 0x71, 0x3b, 0xbc, 0xae, 0xed, 0x16, 0x32, 0x8e, 0x5d, 0xbb, 0x39, 0x57, 0xc3,
+	0xc1, 0x47, 0x60, 0xf9, 0xb1, 0xb6, 0x15, 0xc7, 0xf2, 0x1d, 0x40, 0xe3, 0x39, 0xfb, 0x90, 0xb0,
+	0x9b, 0x73, 0x53, 0x68, 0xb8, 0x03, 0x96, 0x62, 0x2d, 0xc3, 0x99, 0x20, 0x3d, 0x2f, 0x3d, 0x07,
+	0xee, 0xe6, 0xdc, 0x09, 0x0c, 0x7c, 0x00, 0x96, 0x5a, 0xda, 0xfa, 0x6e, 0xce, 0xa7, 0xb5, 0xca,
+	0xce, 0x00, 0x74, 0x34, 0x1d, 0x0b, 0x3f, 0x04, 0xcb, 0xe1, 0xc4, 0xda, 0x26, 0x9e, 0xb4, 0xbe,
+	0xa1, 0xcf, 0x32, 0x63, 0x11, 0xa4, 0x93, 0x9c, 0x04, 0xab, 0xea, 0xf1, 0x10, 0x37, 0x17, 0xcf,
+	0x57, 0x4f, 0x5f, 0x04, 0x54, 0xf5, 0x78, 0x0f, 0x35, 0x42, 0x53, 0xdb, 0x5b, 0xe3, 0xd8, 0x5c,
+	0x4a, 0x8f, 0x97, 0xbd, 0xeb, 0xa7, 0xfa, 0x4d, 0xa2, 0xe1, 0x75, 0x50, 0x22, 0x74, 0xe7, 0x63,
+	0x5e, 0x61, 0xc3, 0xbc, 0x9a, 0xde, 0x45, 0x2a, 0x1e, 0xcf, 0x24, 0x1d, 0x30, 0x5e, 0x13, 0xeb,
+	0x9f, 0x96, 0xc1, 0x82, 0x88, 0x75, 0x7e, 0x97, 0xfe, 0xbd, 0x24, 0x7c, 0x79, 0xa8, 0xbf, 0x7e,
+	0x5e, 0xf8, 0x32, 0x71, 0x25, 0x7a, 0xdf, 0x4a, 0xa2, 0x97, 0xc7, 0xfd, 0xea, 0x78, 0x9d, 0x65,
+	0x93, 0x57, 0x10, 0x22, 0x62, 0xb7, 0x64, 0xc4, 0xf2, 0x70, 0x7f, 0x2d, 0xfb, 0x46, 0x4a, 0xa2,
+	0x44, 0xb8, 0x6e, 0x83, 0x8a, 0xc7, 0x1f, 0x18, 0xb3, 0x02, 0x3d, 0xfd, 0xfe, 0x48, 0x03, 0x50,
+	0x00, 0xe0, 0xd6, 0x38, 0x6c, 0x4b, 0xe2, 0x41, 0x2d, 0x15, 0xb6, 0x09, 0x48, 0x46, 0xed, 0xf5,
+	0x24, 0x6a, 0xcb, 0x93, 0x8f, 0x70, 0x32, 0x66, 0x93, 0x89, 0x89, 0x90, 0xbd, 0x03, 0x16, 0xa5,
+	0x93, 0xb3, 0x2e, 0x11, 0xb3, 0xaf, 0x9f, 0xb7, 0xb7, 0x94, 0x78, 0x1d, 0x05, 0xef, 0xa5, 0x22,
+	0xa3, 0x3a, 0xb9, 0x1f, 0x98, 0x8c, 0x0b, 0x39, 0xd2, 0x64, 0x58, 0xdc, 0x07, 0x57, 0xc6, 0x9e,
+	0xcd, 0x75, 0x02, 0xe9, 0xa3, 0xbe, 0x16, 0x13, 0x72, 0xa8, 0x49, 0xa0, 0xaa, 0x96, 0x88, 0x88,
+	0xf9, 0xf3, 0xd4, 0x92, 0xf1, 0x90, 0x52, 0x4b, 0x84, 0xc3, 0x2e, 0x98, 0xeb, 0x61, 0x82, 0xe8,
+	0x8d, 0xb8, 0x59, 0x61, 0xb9, 0xf1, 0x8d, 0x54, 0x94, 0x0a, 0xb4, 0xfd, 0x50, 0x08, 0xde, 0xf1,
+	0x49, 0x74, 0x2a, 0x8e, 0x00, 0x09, 0x7a, 0xed, 0xfb, 0x60, 0x51, 0x13, 0xa0, 0x0f, 0x94, 0x47,
+	0x58, 0x3e, 0x3a, 0xd3, 0x22, 0x7d, 0x25, 0x62, 0x27, 0x01, 0xe6, 0x9f, 0x55, 0x97, 0x57, 0xb6,
+	0xf3, 0xef, 0x1a, 0x4e, 0x15, 0x54, 0x22, 0xfe, 0x15, 0xa7, 0xfd, 0xf4, 0x59, 0x2d, 0xf7, 0xe5,
+	0xb3, 0x5a, 0xee, 0xab, 0x67, 0x35, 0xe3, 0x93, 0x51, 0xcd, 0xf8, 0xcd, 0xa8, 0x66, 0x7c, 0x3e,
+	0xaa, 0x19, 0x4f, 0x47, 0x35, 0xe3, 0xaf, 0xa3, 0x9a, 0xf1, 0xb7, 0x51, 0x2d, 0xf7, 0xd5, 0xa8,
+	0x66, 0x7c, 0xf6, 0xbc, 0x96, 0x7b, 0xfa, 0xbc, 0x96, 0xfb, 0xf2, 0x79, 0x2d, 0xf7, 0x93, 0xeb,
+	0x33, 0xa7, 0xe9, 0x83, 0x32, 0x63, 0x6a, 0xeb, 0x5f, 0x03, 0x00, 0xca, 0x27, 0x62, 0xfe, 0x7d,
+	0x23, 0x00, 0x00,
 }
 
 func (this *LokiRequest) Equal(that interface{}) bool {
@@ -2317,6 +2449,91 @@ func (this *CountMinSketchResponse) Equal(that interface{}) bool {
 	}
 	return true
 }
+func (this *TableResponse) Equal(that interface{}) bool {
+	if that == nil {
+		return this == nil
+	}
+
+	that1, ok := that.(*TableResponse)
+	if !ok {
+		that2, ok := that.(TableResponse)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
+	}
+	if that1 == nil {
+		return this == nil
+	} else if this == nil {
+		return false
+	}
+	if len(this.Columns) != len(that1.Columns) {
+		return false
+	}
+	for i := range this.Columns {
+		if this.Columns[i] != that1.Columns[i] {
+			return false
+		}
+	}
+	if len(this.Rows) != len(that1.Rows) {
+		return false
+	}
+	for i := range this.Rows {
+		if !this.Rows[i].Equal(&that1.Rows[i]) {
+			return false
+		}
+	}
+	if len(this.Headers) != len(that1.Headers) {
+		return false
+	}
+	for i := range this.Headers {
+		if !this.Headers[i].Equal(that1.Headers[i]) {
+			return false
+		}
+	}
+	if len(this.Warnings) != len(that1.Warnings) {
+		return false
+	}
+	for i := range this.Warnings {
+		if this.Warnings[i] != that1.Warnings[i] {
+			return false
+		}
+	}
+	if !this.Statistics.Equal(&that1.Statistics) {
+		return false
+	}
+	return true
+}
+func (this *TableRow) Equal(that interface{}) bool {
+	if that == nil {
+		return this == nil
+	}
+
+	that1, ok := that.(*TableRow)
+	if !ok {
+		that2, ok := that.(TableRow)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
+	}
+	if that1 == nil {
+		return this == nil
+	} else if this == nil {
+		return false
+	}
+	if len(this.Values) != len(that1.Values) {
+		return false
+	}
+	for i := range this.Values {
+		if this.Values[i] != that1.Values[i] {
+			return false
+		}
+	}
+	return true
+}
 func (this *ShardsResponse) Equal(that interface{}) bool {
 	if that == nil {
 		return this == nil
@@ -2806,6 +3023,30 @@ func (this *QueryResponse_CountMinSketches) Equal(that interface{}) bool {
 	}
 	return true
 }
+func (this *QueryResponse_Table) Equal(that interface{}) bool {
+	if that == nil {
+		return this == nil
+	}
+
+	that1, ok := that.(*QueryResponse_Table)
+	if !ok {
+		that2, ok := that.(QueryResponse_Table)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
+	}
+	if that1 == nil {
+		return this == nil
+	} else if this == nil {
+		return false
+	}
+	if !this.Table.Equal(that1.Table) {
+		return false
+	}
+	return true
+}
 func (this *QueryRequest) Equal(that interface{}) bool {
 	if that == nil {
 		return this == nil
@@ -3289,6 +3530,36 @@ func (this *CountMinSketchResponse) GoString() string {
 	s = append(s, "}")
 	return strings.Join(s, "")
 }
+func (this *TableResponse) GoString() string {
+	if this == nil {
+		return "nil"
+	}
+	s := make([]string, 0, 9)
+	s = append(s, "&queryrange.TableResponse{")
+	s = append(s, "Columns: "+fmt.Sprintf("%#v", this.Columns)+",\n")
+	if this.Rows != nil {
+		vs := make([]*TableRow, len(this.Rows))
+		for i := range vs {
+			vs[i] = &this.Rows[i]
+		}
+		s = append(s, "Rows: "+fmt.Sprintf("%#v", vs)+",\n")
+	}
+	s = append(s, "Headers: "+fmt.Sprintf("%#v", this.Headers)+",\n")
+	s = append(s, "Warnings: "+fmt.Sprintf("%#v", this.Warnings)+",\n")
+	s = append(s, "Statistics: "+strings.Replace(this.Statistics.GoString(), `&`, ``, 1)+",\n")
+	s = append(s, "}")
+	return strings.Join(s, "")
+}
+func (this *TableRow) GoString() string {
+	if this == nil {
+		return "nil"
+	}
+	s := make([]string, 0, 5)
+	s = append(s, "&queryrange.TableRow{")
+	s = append(s, "Values: "+fmt.Sprintf("%#v", this.Values)+",\n")
+	s = append(s, "}")
+	return strings.Join(s, "")
+}
 func (this *ShardsResponse) GoString() string {
 	if this == nil {
 		return "nil"
@@ -3337,7 +3608,7 @@ func (this *QueryResponse) GoString() string {
 	if this == nil {
 		return "nil"
 	}
-	s := make([]string, 0, 18)
+	s := make([]string, 0, 19)
 	s = append(s, "&queryrange.QueryResponse{")
 	if this.Status != nil {
 		s = append(s, "Status: "+fmt.Sprintf("%#v", this.Status)+",\n")
@@ -3452,6 +3723,14 @@ func (this *QueryResponse_CountMinSketches) GoString() string {
 		`CountMinSketches:` + fmt.Sprintf("%#v", this.CountMinSketches) + `}`}, ", ")
 	return s
 }
+func (this *QueryResponse_Table) GoString() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&queryrange.QueryResponse_Table{` +
+		`Table:` + fmt.Sprintf("%#v", this.Table) + `}`}, ", ")
+	return s
+}
 func (this *QueryRequest) GoString() string {
 	if this == nil {
 		return "nil"
@@ -4507,7 +4786,7 @@ func (m *CountMinSketchResponse) MarshalToSizedBuffer(dAtA []byte) (int, error)
 	return len(dAtA) - i, nil
 }
 
-func (m *ShardsResponse) Marshal() (dAtA []byte, err error) {
+func (m *TableResponse) Marshal() (dAtA []byte, err error) {
 	size := m.Size()
 	dAtA = make([]byte, size)
 	n, err := m.MarshalToSizedBuffer(dAtA[:size])
@@ -4517,16 +4796,35 @@ func (m *ShardsResponse) Marshal() (dAtA []byte, err error) {
 	return dAtA[:n], nil
 }
 
-func (m *ShardsResponse) MarshalTo(dAtA []byte) (int, error) {
+func (m *TableResponse) MarshalTo(dAtA []byte) (int, error) {
 	size := m.Size()
 	return m.MarshalToSizedBuffer(dAtA[:size])
 }
 
-func (m *ShardsResponse) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+func (m *TableResponse) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	i := len(dAtA)
 	_ = i
 	var l int
 	_ = l
+	{
+		size, err := m.Statistics.MarshalToSizedBuffer(dAtA[:i])
+		if err != nil {
+			return 0, err
+		}
+		i -= size
+		i = encodeVarintQueryrange(dAtA, i, uint64(size))
+	}
+	i--
+	dAtA[i] = 0x2a
+	if len(m.Warnings) > 0 {
+		for iNdEx := len(m.Warnings) - 1; iNdEx >= 0; iNdEx-- {
+			i -= len(m.Warnings[iNdEx])
+			copy(dAtA[i:], m.Warnings[iNdEx])
+			i = encodeVarintQueryrange(dAtA, i, uint64(len(m.Warnings[iNdEx])))
+			i--
+			dAtA[i] = 0x22
+		}
+	}
 	if len(m.Headers) > 0 {
 		for iNdEx := len(m.Headers) - 1; iNdEx >= 0; iNdEx-- {
 			{
@@ -4538,25 +4836,36 @@ func (m *ShardsResponse) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 				i = encodeVarintQueryrange(dAtA, i, uint64(size))
 			}
 			i--
-			dAtA[i] = 0x12
+			dAtA[i] = 0x1a
 		}
 	}
-	if m.Response != nil {
-		{
-			size := m.Response.Size()
-			i -= size
-			if _, err := m.Response.MarshalTo(dAtA[i:]); err != nil {
-				return 0, err
+	if len(m.Rows) > 0 {
+		for iNdEx := len(m.Rows) - 1; iNdEx >= 0; iNdEx-- {
+			{
+				size, err := m.Rows[iNdEx].MarshalToSizedBuffer(dAtA[:i])
+				if err != nil {
+					return 0, err
+				}
+				i -= size
+				i = encodeVarintQueryrange(dAtA, i, uint64(size))
 			}
-			i = encodeVarintQueryrange(dAtA, i, uint64(size))
+			i--
+			dAtA[i] = 0x12
+		}
+	}
+	if len(m.Columns) > 0 {
+		for iNdEx := len(m.Columns) - 1; iNdEx >= 0; iNdEx-- {
+			i -= len(m.Columns[iNdEx])
+			copy(dAtA[i:], m.Columns[iNdEx])
+			i = encodeVarintQueryrange(dAtA, i, uint64(len(m.Columns[iNdEx])))
+			i--
+			dAtA[i] = 0xa
 		}
-		i--
-		dAtA[i] = 0xa
 	}
 	return len(dAtA) - i, nil
 }
 
-func (m *DetectedFieldsResponse) Marshal() (dAtA []byte, err error) {
+func (m *TableRow) Marshal() (dAtA []byte, err error) {
 	size := m.Size()
 	dAtA = make([]byte, size)
 	n, err := m.MarshalToSizedBuffer(dAtA[:size])
@@ -4566,22 +4875,103 @@ func (m *DetectedFieldsResponse) Marshal() (dAtA []byte, err error) {
 	return dAtA[:n], nil
 }
 
-func (m *DetectedFieldsResponse) MarshalTo(dAtA []byte) (int, error) {
+func (m *TableRow) MarshalTo(dAtA []byte) (int, error) {
 	size := m.Size()
 	return m.MarshalToSizedBuffer(dAtA[:size])
 }
 
-func (m *DetectedFieldsResponse) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+func (m *TableRow) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	i := len(dAtA)
 	_ = i
 	var l int
 	_ = l
-	if len(m.Headers) > 0 {
-		for iNdEx := len(m.Headers) - 1; iNdEx >= 0; iNdEx-- {
-			{
-				size := m.Headers[iNdEx].Size()
-				i -= size
-				if _, err := m.Headers[iNdEx].MarshalTo(dAtA[i:]); err != nil {
+	if len(m.Values) > 0 {
+		for iNdEx := len(m.Values) - 1; iNdEx >= 0; iNdEx-- {
+			i -= len(m.Values[iNdEx])
+			copy(dAtA[i:], m.Values[iNdEx])
+			i = encodeVarintQueryrange(dAtA, i, uint64(len(m.Values[iNdEx])))
+			i--
+			dAtA[i] = 0xa
+		}
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *ShardsResponse) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *ShardsResponse) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *ShardsResponse) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if len(m.Headers) > 0 {
+		for iNdEx := len(m.Headers) - 1; iNdEx >= 0; iNdEx-- {
+			{
+				size := m.Headers[iNdEx].Size()
+				i -= size
+				if _, err := m.Headers[iNdEx].MarshalTo(dAtA[i:]); err != nil {
+					return 0, err
+				}
+				i = encodeVarintQueryrange(dAtA, i, uint64(size))
+			}
+			i--
+			dAtA[i] = 0x12
+		}
+	}
+	if m.Response != nil {
+		{
+			size := m.Response.Size()
+			i -= size
+			if _, err := m.Response.MarshalTo(dAtA[i:]); err != nil {
+				return 0, err
+			}
+			i = encodeVarintQueryrange(dAtA, i, uint64(size))
+		}
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *DetectedFieldsResponse) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *DetectedFieldsResponse) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *DetectedFieldsResponse) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if len(m.Headers) > 0 {
+		for iNdEx := len(m.Headers) - 1; iNdEx >= 0; iNdEx-- {
+			{
+				size := m.Headers[iNdEx].Size()
+				i -= size
+				if _, err := m.Headers[iNdEx].MarshalTo(dAtA[i:]); err != nil {
 					return 0, err
 				}
 				i = encodeVarintQueryrange(dAtA, i, uint64(size))
@@ -5007,6 +5397,26 @@ func (m *QueryResponse_CountMinSketches) MarshalToSizedBuffer(dAtA []byte) (int,
 	}
 	return len(dAtA) - i, nil
 }
+func (m *QueryResponse_Table) MarshalTo(dAtA []byte) (int, error) {
+	return m.MarshalToSizedBuffer(dAtA[:m.Size()])
+}
+
+func (m *QueryResponse_Table) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	if m.Table != nil {
+		{
+			size, err := m.Table.MarshalToSizedBuffer(dAtA[:i])
+			if err != nil {
+				return 0, err
+			}
+			i -= size
+			i = encodeVarintQueryrange(dAtA, i, uint64(size))
+		}
+		i--
+		dAtA[i] = 0x7a
+	}
+	return len(dAtA) - i, nil
+}
 func (m *QueryRequest) Marshal() (dAtA []byte, err error) {
 	size := m.Size()
 	dAtA = make([]byte, size)
@@ -5660,6 +6070,56 @@ func (m *CountMinSketchResponse) Size() (n int) {
 	return n
 }
 
+func (m *TableResponse) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if len(m.Columns) > 0 {
+		for _, s := range m.Columns {
+			l = len(s)
+			n += 1 + l + sovQueryrange(uint64(l))
+		}
+	}
+	if len(m.Rows) > 0 {
+		for _, e := range m.Rows {
+			l = e.Size()
+			n += 1 + l + sovQueryrange(uint64(l))
+		}
+	}
+	if len(m.Headers) > 0 {
+		for _, e := range m.Headers {
+			l = e.Size()
+			n += 1 + l + sovQueryrange(uint64(l))
+		}
+	}
+	if len(m.Warnings) > 0 {
+		for _, s := range m.Warnings {
+			l = len(s)
+			n += 1 + l + sovQueryrange(uint64(l))
+		}
+	}
+	l = m.Statistics.Size()
+	n += 1 + l + sovQueryrange(uint64(l))
+	return n
+}
+
+func (m *TableRow) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if len(m.Values) > 0 {
+		for _, s := range m.Values {
+			l = len(s)
+			n += 1 + l + sovQueryrange(uint64(l))
+		}
+	}
+	return n
+}
+
 func (m *ShardsResponse) Size() (n int) {
 	if m == nil {
 		return 0
@@ -5908,6 +6368,18 @@ func (m *QueryResponse_CountMinSketches) Size() (n int) {
 	}
 	return n
 }
+func (m *QueryResponse_Table) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.Table != nil {
+		l = m.Table.Size()
+		n += 1 + l + sovQueryrange(uint64(l))
+	}
+	return n
+}
 func (m *QueryRequest) Size() (n int) {
 	if m == nil {
 		return 0
@@ -6253,6 +6725,35 @@ func (this *CountMinSketchResponse) String() string {
 	}, "")
 	return s
 }
+func (this *TableResponse) String() string {
+	if this == nil {
+		return "nil"
+	}
+	repeatedStringForRows := "[]TableRow{"
+	for _, f := range this.Rows {
+		repeatedStringForRows += strings.Replace(strings.Replace(f.String(), "TableRow", "TableRow", 1), `&`, ``, 1) + ","
+	}
+	repeatedStringForRows += "}"
+	s := strings.Join([]string{`&TableResponse{`,
+		`Columns:` + fmt.Sprintf("%v", this.Columns) + `,`,
+		`Rows:` + repeatedStringForRows + `,`,
+		`Headers:` + fmt.Sprintf("%v", this.Headers) + `,`,
+		`Warnings:` + fmt.Sprintf("%v", this.Warnings) + `,`,
+		`Statistics:` + strings.Replace(strings.Replace(fmt.Sprintf("%v", this.Statistics), "Result", "stats.Result", 1), `&`, ``, 1) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *TableRow) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&TableRow{`,
+		`Values:` + fmt.Sprintf("%v", this.Values) + `,`,
+		`}`,
+	}, "")
+	return s
+}
 func (this *ShardsResponse) String() string {
 	if this == nil {
 		return "nil"
@@ -6438,6 +6939,16 @@ func (this *QueryResponse_CountMinSketches) String() string {
 	}, "")
 	return s
 }
+func (this *QueryResponse_Table) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&QueryResponse_Table{`,
+		`Table:` + strings.Replace(fmt.Sprintf("%v", this.Table), "TableResponse", "TableResponse", 1) + `,`,
+		`}`,
+	}, "")
+	return s
+}
 func (this *QueryRequest) String() string {
 	if this == nil {
 		return "nil"
@@ -9387,6 +9898,309 @@ func (m *CountMinSketchResponse) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
+func (m *TableResponse) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowQueryrange
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: TableResponse: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: TableResponse: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Columns", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowQueryrange
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthQueryrange
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthQueryrange
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Columns = append(m.Columns, string(dAtA[iNdEx:postIndex]))
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Rows", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowQueryrange
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthQueryrange
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthQueryrange
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Rows = append(m.Rows, TableRow{})
+			if err := m.Rows[len(m.Rows)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Headers", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowQueryrange
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthQueryrange
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthQueryrange
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Headers = append(m.Headers, github_com_grafana_loki_v3_pkg_querier_queryrange_queryrangebase_definitions.PrometheusResponseHeader{})
+			if err := m.Headers[len(m.Headers)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Warnings", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowQueryrange
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthQueryrange
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthQueryrange
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Warnings = append(m.Warnings, string(dAtA[iNdEx:postIndex]))
+			iNdEx = postIndex
+		case 5:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Statistics", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowQueryrange
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthQueryrange
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthQueryrange
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if err := m.Statistics.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipQueryrange(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthQueryrange
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthQueryrange
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *TableRow) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowQueryrange
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: TableRow: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: TableRow: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Values", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowQueryrange
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthQueryrange
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthQueryrange
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Values = append(m.Values, string(dAtA[iNdEx:postIndex]))
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipQueryrange(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthQueryrange
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthQueryrange
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
 func (m *ShardsResponse) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
@@ -10399,6 +11213,41 @@ func (m *QueryResponse) Unmarshal(dAtA []byte) error {
 			}
 			m.Response = &QueryResponse_CountMinSketches{v}
 			iNdEx = postIndex
+		case 15:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Table", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowQueryrange
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthQueryrange
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthQueryrange
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			v := &TableResponse{}
+			if err := v.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			m.Response = &QueryResponse_Table{v}
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipQueryrange(dAtA[iNdEx:])
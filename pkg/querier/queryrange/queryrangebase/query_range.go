@@ -106,6 +106,9 @@ func (resp *PrometheusResponse) minTime() int64 {
 		return -1
 	}
 	if len(result[0].Samples) == 0 {
+		if len(result[0].Histograms) > 0 {
+			return result[0].Histograms[0].Timestamp
+		}
 		return -1
 	}
 	return result[0].Samples[0].TimestampMs
@@ -284,26 +287,38 @@ func (prometheusCodec) EncodeResponse(ctx context.Context, _ *http.Request, res
 // UnmarshalJSON implements json.Unmarshaler.
 func (s *SampleStream) UnmarshalJSON(data []byte) error {
 	var stream struct {
-		Metric model.Metric            `json:"metric"`
-		Values []logproto.LegacySample `json:"values"`
+		Metric     model.Metric                `json:"metric"`
+		Values     []logproto.LegacySample     `json:"values"`
+		Histograms []model.SampleHistogramPair `json:"histograms"`
 	}
 	if err := json.Unmarshal(data, &stream); err != nil {
 		return err
 	}
 	s.Labels = logproto.FromMetricsToLabelAdapters(stream.Metric)
 	s.Samples = stream.Values
+	s.Histograms = nil
+	for _, h := range stream.Histograms {
+		s.Histograms = append(s.Histograms, logproto.NewSampleHistogram(int64(h.Timestamp), logproto.FromModelToFloatHistogram(h.Histogram)))
+	}
 	return nil
 }
 
 // MarshalJSON implements json.Marshaler.
 func (s *SampleStream) MarshalJSON() ([]byte, error) {
 	stream := struct {
-		Metric model.Metric            `json:"metric"`
-		Values []logproto.LegacySample `json:"values"`
+		Metric     model.Metric                `json:"metric"`
+		Values     []logproto.LegacySample     `json:"values"`
+		Histograms []model.SampleHistogramPair `json:"histograms,omitempty"`
 	}{
 		Metric: logproto.FromLabelAdaptersToMetric(s.Labels),
 		Values: s.Samples,
 	}
+	for _, h := range s.Histograms {
+		stream.Histograms = append(stream.Histograms, model.SampleHistogramPair{
+			Timestamp: model.Time(h.Timestamp),
+			Histogram: logproto.FromFloatHistogramToModel(h.FloatHistogram()),
+		})
+	}
 	return json.Marshal(stream)
 }
 
@@ -332,6 +347,10 @@ func matrixMerge(resps []*PrometheusResponse) []SampleStream {
 				} // else there is no overlap, yay!
 			}
 			existing.Samples = append(existing.Samples, stream.Samples...)
+			if len(existing.Histograms) > 0 && len(stream.Histograms) > 0 {
+				stream.Histograms = sliceHistograms(stream.Histograms, existing.Histograms[len(existing.Histograms)-1].Timestamp)
+			}
+			existing.Histograms = append(existing.Histograms, stream.Histograms...)
 			output[metric] = existing
 		}
 	}
@@ -350,6 +369,14 @@ func matrixMerge(resps []*PrometheusResponse) []SampleStream {
 	return result
 }
 
+// sliceHistograms is the equivalent of sliceSamples for histograms.
+func sliceHistograms(histograms []logproto.SampleHistogram, minTs int64) []logproto.SampleHistogram {
+	searchResult := sort.Search(len(histograms), func(i int) bool {
+		return histograms[i].Timestamp > minTs
+	})
+	return histograms[searchResult:]
+}
+
 // sliceSamples assumes given samples are sorted by timestamp in ascending order and
 // return a sub slice whose first element's is the smallest timestamp that is strictly
 // bigger than the given minTs. Empty slice is returned if minTs is bigger than all the
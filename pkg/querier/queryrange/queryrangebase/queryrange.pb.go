@@ -13,6 +13,7 @@ import (
 	logproto "github.com/grafana/loki/v3/pkg/logproto"
 	definitions "github.com/grafana/loki/v3/pkg/querier/queryrange/queryrangebase/definitions"
 	resultscache "github.com/grafana/loki/v3/pkg/storage/chunk/cache/resultscache"
+	_ "github.com/prometheus/prometheus/prompb"
 	_ "google.golang.org/protobuf/types/known/durationpb"
 	io "io"
 	math "math"
@@ -268,8 +269,9 @@ func (m *PrometheusData) GetResult() []SampleStream {
 }
 
 type SampleStream struct {
-	Labels  []github_com_grafana_loki_v3_pkg_logproto.LabelAdapter `protobuf:"bytes,1,rep,name=labels,proto3,customtype=github.com/grafana/loki/v3/pkg/logproto.LabelAdapter" json:"metric"`
-	Samples []logproto.LegacySample                                `protobuf:"bytes,2,rep,name=samples,proto3" json:"values"`
+	Labels     []github_com_grafana_loki_v3_pkg_logproto.LabelAdapter    `protobuf:"bytes,1,rep,name=labels,proto3,customtype=github.com/grafana/loki/v3/pkg/logproto.LabelAdapter" json:"metric"`
+	Samples    []logproto.LegacySample                                   `protobuf:"bytes,2,rep,name=samples,proto3" json:"values"`
+	Histograms []github_com_grafana_loki_v3_pkg_logproto.SampleHistogram `protobuf:"bytes,3,rep,name=histograms,proto3,customtype=github.com/grafana/loki/v3/pkg/logproto.SampleHistogram" json:"histograms"`
 }
 
 func (m *SampleStream) Reset()      { *m = SampleStream{} }
@@ -323,55 +325,59 @@ func init() {
 }
 
 var fileDescriptor_4cc6a0c1d6b614c4 = []byte{
-	// 768 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x94, 0x54, 0x4f, 0x6f, 0xd3, 0x4e,
-	0x10, 0x8d, 0x9b, 0xc4, 0x69, 0xb6, 0x3f, 0xe5, 0x27, 0xb6, 0x55, 0x31, 0xa5, 0xb2, 0xa3, 0x08,
-	0xa4, 0x20, 0x81, 0x8d, 0x5a, 0xe8, 0x01, 0x09, 0xa9, 0xb8, 0x7f, 0x84, 0xaa, 0x4a, 0x54, 0x6e,
-	0x25, 0x24, 0x6e, 0x9b, 0x64, 0xeb, 0x58, 0xb5, 0xbd, 0xee, 0xee, 0xba, 0x28, 0x37, 0x4e, 0x9c,
-	0x7b, 0x83, 0x8f, 0xc0, 0x47, 0xa9, 0xc4, 0xa5, 0xc7, 0x8a, 0x83, 0xa1, 0xee, 0x05, 0xe5, 0xd4,
-	0x8f, 0x80, 0x76, 0xed, 0xb4, 0x4e, 0x0a, 0x2a, 0x9c, 0x3c, 0xb3, 0xf3, 0xde, 0xdb, 0x99, 0x37,
-	0xd6, 0x82, 0x95, 0xe8, 0xc0, 0xb5, 0x0e, 0x63, 0x4c, 0x3d, 0x4c, 0xe5, 0x77, 0x40, 0x51, 0xe8,
-	0xe2, 0x42, 0xd8, 0x41, 0xac, 0x98, 0x9a, 0x11, 0x25, 0x9c, 0xc0, 0xc6, 0x38, 0x60, 0x61, 0xce,
-	0x25, 0x2e, 0x91, 0x25, 0x4b, 0x44, 0x19, 0x6a, 0x41, 0x77, 0x09, 0x71, 0x7d, 0x6c, 0xc9, 0xac,
-	0x13, 0xef, 0x5b, 0xbd, 0x98, 0x22, 0xee, 0x91, 0x30, 0xaf, 0x1b, 0x93, 0x75, 0xee, 0x05, 0x98,
-	0x71, 0x14, 0x44, 0x39, 0xe0, 0xbe, 0x68, 0xcf, 0x27, 0x6e, 0xa6, 0x3c, 0x0a, 0xf2, 0xe2, 0xda,
-	0xdf, 0xf5, 0xde, 0xc3, 0xfb, 0x5e, 0xe8, 0x89, 0x5b, 0x59, 0x31, 0xce, 0x45, 0x9e, 0x0a, 0x11,
-	0xc6, 0x09, 0x45, 0x2e, 0xb6, 0xba, 0xfd, 0x38, 0x3c, 0xb0, 0xba, 0xa8, 0xdb, 0xc7, 0x16, 0xc5,
-	0x2c, 0xf6, 0x39, 0xcb, 0x12, 0x3e, 0x88, 0x70, 0xce, 0x68, 0x7d, 0x2a, 0x83, 0x3b, 0x3b, 0x94,
-	0x04, 0x98, 0xf7, 0x71, 0xcc, 0x1c, 0x7c, 0x18, 0x63, 0xc6, 0x21, 0x04, 0x95, 0x08, 0xf1, 0xbe,
-	0xa6, 0x34, 0x95, 0x76, 0xdd, 0x91, 0x31, 0x7c, 0x01, 0xaa, 0x8c, 0x23, 0xca, 0xb5, 0xa9, 0xa6,
-	0xd2, 0x9e, 0x59, 0x5a, 0x30, 0xb3, 0x71, 0xcd, 0xd1, 0xb8, 0xe6, 0xde, 0x68, 0x5c, 0x7b, 0xfa,
-	0x24, 0x31, 0x4a, 0xc7, 0xdf, 0x0d, 0xc5, 0xc9, 0x28, 0x70, 0x05, 0x94, 0x71, 0xd8, 0xd3, 0xca,
-	0xff, 0xc0, 0x14, 0x04, 0xd1, 0x07, 0xe3, 0x38, 0xd2, 0x2a, 0x4d, 0xa5, 0x5d, 0x76, 0x64, 0x0c,
-	0x5f, 0x82, 0x9a, 0x30, 0x96, 0xc4, 0x5c, 0xab, 0x4a, 0xbd, 0x7b, 0x37, 0xf4, 0xd6, 0xf3, 0xc5,
-	0x64, 0x72, 0x9f, 0x85, 0xdc, 0x88, 0x03, 0xe7, 0x40, 0x55, 0x5a, 0xaa, 0xa9, 0x72, 0xb6, 0x2c,
-	0x81, 0x5b, 0xa0, 0x21, 0xbc, 0xf1, 0x42, 0xf7, 0x4d, 0x24, 0x0d, 0xd5, 0x6a, 0x52, 0x7b, 0xd1,
-	0x2c, 0x3a, 0x67, 0xae, 0x8d, 0x61, 0xec, 0x8a, 0x90, 0x77, 0x26, 0x98, 0x70, 0x03, 0xd4, 0x5e,
-	0x63, 0xd4, 0xc3, 0x94, 0x69, 0xd3, 0xcd, 0x72, 0x7b, 0x66, 0xe9, 0x81, 0x59, 0xdc, 0xd4, 0x0d,
-	0xb7, 0x33, 0xb0, 0x5d, 0x1d, 0x26, 0x86, 0xf2, 0xc4, 0x19, 0x71, 0x5b, 0xe9, 0x14, 0x80, 0x45,
-	0x2c, 0x8b, 0x48, 0xc8, 0x30, 0x6c, 0x01, 0x75, 0x97, 0x23, 0x1e, 0xb3, 0x6c, 0x39, 0x36, 0x18,
-	0x26, 0x86, 0xca, 0xe4, 0x89, 0x93, 0x57, 0xe0, 0x16, 0xa8, 0xac, 0x23, 0x8e, 0xf2, 0x4d, 0xe9,
-	0xe6, 0xf8, 0x3f, 0x54, 0xe8, 0x40, 0xa0, 0xec, 0x79, 0x31, 0xc5, 0x30, 0x31, 0x1a, 0x3d, 0xc4,
-	0xd1, 0x63, 0x12, 0x78, 0x1c, 0x07, 0x11, 0x1f, 0x38, 0x52, 0x03, 0x3e, 0x07, 0xf5, 0x0d, 0x4a,
-	0x09, 0xdd, 0x1b, 0x44, 0x58, 0x2e, 0xb0, 0x6e, 0xdf, 0x1d, 0x26, 0xc6, 0x2c, 0x1e, 0x1d, 0x16,
-	0x18, 0xd7, 0x48, 0xf8, 0x08, 0x54, 0x65, 0x22, 0x57, 0x57, 0xb7, 0x67, 0x87, 0x89, 0xf1, 0xbf,
-	0xa4, 0x14, 0xe0, 0x19, 0x02, 0x6e, 0x5e, 0xfb, 0x55, 0x95, 0x7e, 0x3d, 0xfc, 0xa3, 0x5f, 0x99,
-	0x07, 0xbf, 0x37, 0x0c, 0x2e, 0x81, 0xe9, 0xb7, 0x88, 0x86, 0x5e, 0xe8, 0x32, 0x4d, 0x6d, 0x96,
-	0xdb, 0x75, 0x7b, 0x7e, 0x98, 0x18, 0xf0, 0x7d, 0x7e, 0x56, 0xb8, 0xf8, 0x0a, 0xd7, 0xfa, 0xa8,
-	0x80, 0xc6, 0xb8, 0x1d, 0xd0, 0x04, 0xc0, 0x91, 0x3b, 0x97, 0x13, 0x67, 0x26, 0x37, 0x86, 0x89,
-	0x01, 0xe8, 0xd5, 0xa9, 0x53, 0x40, 0xc0, 0x75, 0xa0, 0x66, 0x99, 0x36, 0x25, 0xbb, 0x5f, 0x9c,
-	0xb4, 0x7b, 0x17, 0x05, 0x91, 0x8f, 0x77, 0x39, 0xc5, 0x28, 0xb0, 0x1b, 0xb9, 0xd9, 0x6a, 0xa6,
-	0xe6, 0xe4, 0xdc, 0xd6, 0x57, 0x05, 0xfc, 0x57, 0x04, 0xc2, 0x01, 0x50, 0x7d, 0xd4, 0xc1, 0xbe,
-	0xd8, 0x73, 0x59, 0xfe, 0xe5, 0x57, 0x0f, 0xc6, 0x36, 0x76, 0x51, 0x77, 0xb0, 0x2d, 0xaa, 0x3b,
-	0xc8, 0xa3, 0xf6, 0xa6, 0xd0, 0xfc, 0x96, 0x18, 0xcf, 0x5c, 0x8f, 0xf7, 0xe3, 0x8e, 0xd9, 0x25,
-	0x81, 0xe5, 0x52, 0xb4, 0x8f, 0x42, 0x64, 0xf9, 0xe4, 0xc0, 0xb3, 0x8e, 0x96, 0xad, 0xe2, 0xd3,
-	0x63, 0x4a, 0xea, 0xab, 0x1e, 0x8a, 0x38, 0xa6, 0xa2, 0x97, 0x00, 0x73, 0xea, 0x75, 0x9d, 0xfc,
-	0x42, 0xb8, 0x0a, 0x6a, 0x4c, 0xb6, 0xc2, 0xf2, 0x91, 0xe6, 0x27, 0xef, 0xce, 0x3a, 0xbd, 0x1e,
-	0xe6, 0x08, 0xf9, 0x31, 0x66, 0xce, 0x88, 0x66, 0x1f, 0x9d, 0x9e, 0xeb, 0xa5, 0xb3, 0x73, 0xbd,
-	0x74, 0x79, 0xae, 0x2b, 0x1f, 0x52, 0x5d, 0xf9, 0x92, 0xea, 0xca, 0x49, 0xaa, 0x2b, 0xa7, 0xa9,
-	0xae, 0xfc, 0x48, 0x75, 0xe5, 0x67, 0xaa, 0x97, 0x2e, 0x53, 0x5d, 0x39, 0xbe, 0xd0, 0x4b, 0xa7,
-	0x17, 0x7a, 0xe9, 0xec, 0x42, 0x2f, 0xbd, 0x5b, 0xbd, 0xa5, 0xff, 0x5b, 0x5f, 0xc7, 0x8e, 0x2a,
-	0x9b, 0x5c, 0xfe, 0x15, 0x00, 0x00, 0xff, 0xff, 0x8f, 0x2e, 0x5a, 0xee, 0x09, 0x06, 0x00, 0x00,
+	// 823 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x94, 0x54, 0x41, 0x8f, 0xdb, 0x44,
+	0x14, 0x8e, 0xe3, 0xc4, 0xd9, 0x4c, 0x51, 0x10, 0xd3, 0xb2, 0x98, 0xa5, 0xb2, 0xa3, 0x08, 0xa4,
+	0x20, 0x81, 0x8d, 0x76, 0xa1, 0x48, 0x48, 0x48, 0xc5, 0xdd, 0x56, 0x55, 0x55, 0x44, 0xe5, 0xad,
+	0x84, 0xc4, 0x6d, 0x92, 0xcc, 0x3a, 0xd6, 0xda, 0x1e, 0x77, 0x66, 0xbc, 0x28, 0x37, 0x0e, 0x88,
+	0x73, 0x6f, 0xf0, 0x13, 0xf8, 0x29, 0x3d, 0xae, 0x38, 0x55, 0x1c, 0x0c, 0xeb, 0xbd, 0xa0, 0x9c,
+	0xf6, 0x27, 0xa0, 0x99, 0xb1, 0x93, 0x49, 0x16, 0xb4, 0xdb, 0x93, 0xdf, 0xcc, 0xfb, 0xde, 0xf7,
+	0xde, 0xf7, 0x3d, 0x6b, 0xc0, 0xbd, 0xfc, 0x24, 0xf2, 0x5f, 0x14, 0x98, 0xc6, 0x98, 0xca, 0xef,
+	0x82, 0xa2, 0x2c, 0xc2, 0x5a, 0x38, 0x41, 0x4c, 0x3f, 0x7a, 0x39, 0x25, 0x9c, 0xc0, 0xc1, 0x26,
+	0x60, 0xef, 0x20, 0x8a, 0xf9, 0xbc, 0x98, 0x78, 0x53, 0x92, 0xfa, 0x39, 0x25, 0x29, 0xe6, 0x73,
+	0x5c, 0xb0, 0xed, 0x30, 0x9f, 0xf8, 0x7c, 0x91, 0x63, 0xa6, 0x48, 0xf6, 0xee, 0x44, 0x24, 0x22,
+	0x32, 0xf4, 0x45, 0x54, 0xdf, 0x3a, 0x11, 0x21, 0x51, 0x82, 0x7d, 0x79, 0x9a, 0x14, 0xc7, 0xfe,
+	0xac, 0xa0, 0x88, 0xc7, 0x24, 0xab, 0xf3, 0xee, 0x76, 0x9e, 0xc7, 0x29, 0x66, 0x1c, 0xa5, 0x79,
+	0x0d, 0xf8, 0x40, 0x68, 0x4a, 0x48, 0xa4, 0x98, 0x9b, 0xa0, 0x4e, 0x3e, 0xb8, 0x99, 0xe0, 0x19,
+	0x3e, 0x8e, 0xb3, 0x58, 0x74, 0x65, 0x7a, 0x5c, 0x93, 0x7c, 0x26, 0x48, 0x18, 0x27, 0x14, 0x45,
+	0xd8, 0x9f, 0xce, 0x8b, 0xec, 0xc4, 0x9f, 0xa2, 0xe9, 0x1c, 0xfb, 0x14, 0xb3, 0x22, 0xe1, 0x4c,
+	0x1d, 0x34, 0xa9, 0xa3, 0x5f, 0x4d, 0xf0, 0xce, 0xb3, 0x95, 0x19, 0x21, 0x7e, 0x51, 0x60, 0xc6,
+	0x21, 0x04, 0x9d, 0x1c, 0xf1, 0xb9, 0x6d, 0x0c, 0x8d, 0x71, 0x3f, 0x94, 0x31, 0xfc, 0x0a, 0x74,
+	0x19, 0x47, 0x94, 0xdb, 0xed, 0xa1, 0x31, 0xbe, 0xb5, 0xbf, 0xe7, 0x29, 0xb9, 0x5e, 0x23, 0xd7,
+	0x7b, 0xde, 0xc8, 0x0d, 0x76, 0x5e, 0x95, 0x6e, 0xeb, 0xe5, 0x5f, 0xae, 0x11, 0xaa, 0x12, 0x78,
+	0x0f, 0x98, 0x38, 0x9b, 0xd9, 0xe6, 0x1b, 0x54, 0x8a, 0x02, 0x31, 0x07, 0xe3, 0x38, 0xb7, 0x3b,
+	0x43, 0x63, 0x6c, 0x86, 0x32, 0x86, 0x5f, 0x83, 0x9e, 0x30, 0x96, 0x14, 0xdc, 0xee, 0x4a, 0xbe,
+	0xf7, 0xaf, 0xf0, 0x1d, 0xd6, 0x8b, 0x51, 0x74, 0xbf, 0x09, 0xba, 0xa6, 0x06, 0xde, 0x01, 0x5d,
+	0x69, 0xa9, 0x6d, 0x49, 0x6d, 0xea, 0x00, 0x9f, 0x80, 0x81, 0xf0, 0x26, 0xce, 0xa2, 0xef, 0x72,
+	0x69, 0xa8, 0xdd, 0x93, 0xdc, 0x77, 0x3d, 0xdd, 0x39, 0xef, 0xc1, 0x06, 0x26, 0xe8, 0x08, 0xfa,
+	0x70, 0xab, 0x12, 0x3e, 0x04, 0xbd, 0xc7, 0x18, 0xcd, 0x30, 0x65, 0xf6, 0xce, 0xd0, 0x1c, 0xdf,
+	0xda, 0xff, 0xd0, 0xd3, 0x37, 0x75, 0xc5, 0x6d, 0x05, 0x0e, 0xba, 0xcb, 0xd2, 0x35, 0x3e, 0x0d,
+	0x9b, 0xda, 0x51, 0xd5, 0x06, 0x50, 0xc7, 0xb2, 0x9c, 0x64, 0x0c, 0xc3, 0x11, 0xb0, 0x8e, 0x38,
+	0xe2, 0x05, 0x53, 0xcb, 0x09, 0xc0, 0xb2, 0x74, 0x2d, 0x26, 0x6f, 0xc2, 0x3a, 0x03, 0x9f, 0x80,
+	0xce, 0x21, 0xe2, 0xa8, 0xde, 0x94, 0xe3, 0x6d, 0xfe, 0x43, 0xda, 0x04, 0x02, 0x15, 0xec, 0x0a,
+	0x15, 0xcb, 0xd2, 0x1d, 0xcc, 0x10, 0x47, 0x9f, 0x90, 0x34, 0xe6, 0x38, 0xcd, 0xf9, 0x22, 0x94,
+	0x1c, 0xf0, 0x0b, 0xd0, 0x7f, 0x48, 0x29, 0xa1, 0xcf, 0x17, 0x39, 0x96, 0x0b, 0xec, 0x07, 0xef,
+	0x2d, 0x4b, 0xf7, 0x36, 0x6e, 0x2e, 0xb5, 0x8a, 0x35, 0x12, 0x7e, 0x0c, 0xba, 0xf2, 0x20, 0x57,
+	0xd7, 0x0f, 0x6e, 0x2f, 0x4b, 0xf7, 0x6d, 0x59, 0xa2, 0xc1, 0x15, 0x02, 0x3e, 0x5a, 0xfb, 0xd5,
+	0x95, 0x7e, 0x7d, 0xf4, 0xbf, 0x7e, 0x29, 0x0f, 0xfe, 0xdb, 0x30, 0xb8, 0x0f, 0x76, 0xbe, 0x47,
+	0x34, 0x8b, 0xb3, 0x88, 0xd9, 0xd6, 0xd0, 0x1c, 0xf7, 0x83, 0xdd, 0x65, 0xe9, 0xc2, 0x1f, 0xeb,
+	0x3b, 0xad, 0xf1, 0x0a, 0x37, 0xfa, 0xc5, 0x00, 0x83, 0x4d, 0x3b, 0xa0, 0x07, 0x40, 0x28, 0x77,
+	0x2e, 0x15, 0x2b, 0x93, 0x07, 0xcb, 0xd2, 0x05, 0x74, 0x75, 0x1b, 0x6a, 0x08, 0x78, 0x08, 0x2c,
+	0x75, 0xb2, 0xdb, 0x72, 0xfa, 0xbb, 0xdb, 0x76, 0x1f, 0xa1, 0x34, 0x4f, 0xf0, 0x11, 0xa7, 0x18,
+	0xa5, 0xc1, 0xa0, 0x36, 0xdb, 0x52, 0x6c, 0x61, 0x5d, 0x3b, 0xfa, 0xa3, 0x0d, 0xde, 0xd2, 0x81,
+	0x70, 0x01, 0xac, 0x04, 0x4d, 0x70, 0x22, 0xf6, 0x6c, 0xca, 0xbf, 0x7c, 0xf5, 0x60, 0x3c, 0xc5,
+	0x11, 0x9a, 0x2e, 0x9e, 0x8a, 0xec, 0x33, 0x14, 0xd3, 0xe0, 0x91, 0xe0, 0xfc, 0xb3, 0x74, 0x3f,
+	0xd7, 0xde, 0xba, 0x88, 0xa2, 0x63, 0x94, 0x21, 0x3f, 0x21, 0x27, 0xb1, 0x7f, 0x7a, 0xe0, 0xeb,
+	0x4f, 0x8f, 0x27, 0x4b, 0xbf, 0x99, 0xa1, 0x9c, 0x63, 0x2a, 0x66, 0x49, 0x31, 0xa7, 0xf1, 0x34,
+	0xac, 0x1b, 0xc2, 0xfb, 0xa0, 0xc7, 0xe4, 0x28, 0xac, 0x96, 0xb4, 0xbb, 0xdd, 0x5b, 0x4d, 0xba,
+	0x16, 0x73, 0x8a, 0x92, 0x02, 0xb3, 0xb0, 0x29, 0x83, 0x3f, 0x1b, 0x00, 0xcc, 0x63, 0xc6, 0x49,
+	0x44, 0x51, 0xca, 0x6c, 0x53, 0xb2, 0xbc, 0xeb, 0xad, 0x5f, 0x5d, 0xef, 0x71, 0x93, 0x0d, 0xbe,
+	0xad, 0xa7, 0xff, 0xf2, 0xa6, 0xd3, 0xab, 0xe6, 0x2b, 0x02, 0xb1, 0x9a, 0x75, 0xaf, 0x50, 0x8b,
+	0x83, 0xd3, 0xb3, 0x73, 0xa7, 0xf5, 0xfa, 0xdc, 0x69, 0x5d, 0x9e, 0x3b, 0xc6, 0x4f, 0x95, 0x63,
+	0xfc, 0x5e, 0x39, 0xc6, 0xab, 0xca, 0x31, 0xce, 0x2a, 0xc7, 0xf8, 0xbb, 0x72, 0x8c, 0x7f, 0x2a,
+	0xa7, 0x75, 0x59, 0x39, 0xc6, 0xcb, 0x0b, 0xa7, 0x75, 0x76, 0xe1, 0xb4, 0x5e, 0x5f, 0x38, 0xad,
+	0x1f, 0xee, 0x5f, 0x33, 0xc8, 0xb5, 0x8f, 0xf4, 0xc4, 0x92, 0x83, 0x1e, 0xfc, 0x3b, 0x00, 0x21,
+	0xa7, 0x53, 0xac, 0xc5, 0x06, 0x00, 0x00,
 }
 
 func (this *PrometheusRequest) Equal(that interface{}) bool {
@@ -540,6 +546,14 @@ func (this *SampleStream) Equal(that interface{}) bool {
 			return false
 		}
 	}
+	if len(this.Histograms) != len(that1.Histograms) {
+		return false
+	}
+	for i := range this.Histograms {
+		if !this.Histograms[i].Equal(that1.Histograms[i]) {
+			return false
+		}
+	}
 	return true
 }
 func (this *PrometheusRequest) GoString() string {
@@ -599,7 +613,7 @@ func (this *SampleStream) GoString() string {
 	if this == nil {
 		return "nil"
 	}
-	s := make([]string, 0, 6)
+	s := make([]string, 0, 7)
 	s = append(s, "&queryrangebase.SampleStream{")
 	s = append(s, "Labels: "+fmt.Sprintf("%#v", this.Labels)+",\n")
 	if this.Samples != nil {
@@ -609,6 +623,7 @@ func (this *SampleStream) GoString() string {
 		}
 		s = append(s, "Samples: "+fmt.Sprintf("%#v", vs)+",\n")
 	}
+	s = append(s, "Histograms: "+fmt.Sprintf("%#v", this.Histograms)+",\n")
 	s = append(s, "}")
 	return strings.Join(s, "")
 }
@@ -851,6 +866,20 @@ func (m *SampleStream) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	_ = i
 	var l int
 	_ = l
+	if len(m.Histograms) > 0 {
+		for iNdEx := len(m.Histograms) - 1; iNdEx >= 0; iNdEx-- {
+			{
+				size := m.Histograms[iNdEx].Size()
+				i -= size
+				if _, err := m.Histograms[iNdEx].MarshalTo(dAtA[i:]); err != nil {
+					return 0, err
+				}
+				i = encodeVarintQueryrange(dAtA, i, uint64(size))
+			}
+			i--
+			dAtA[i] = 0x1a
+		}
+	}
 	if len(m.Samples) > 0 {
 		for iNdEx := len(m.Samples) - 1; iNdEx >= 0; iNdEx-- {
 			{
@@ -999,6 +1028,12 @@ func (m *SampleStream) Size() (n int) {
 			n += 1 + l + sovQueryrange(uint64(l))
 		}
 	}
+	if len(m.Histograms) > 0 {
+		for _, e := range m.Histograms {
+			l = e.Size()
+			n += 1 + l + sovQueryrange(uint64(l))
+		}
+	}
 	return n
 }
 
@@ -1078,6 +1113,7 @@ func (this *SampleStream) String() string {
 	s := strings.Join([]string{`&SampleStream{`,
 		`Labels:` + fmt.Sprintf("%v", this.Labels) + `,`,
 		`Samples:` + repeatedStringForSamples + `,`,
+		`Histograms:` + fmt.Sprintf("%v", this.Histograms) + `,`,
 		`}`,
 	}, "")
 	return s
@@ -1856,6 +1892,40 @@ func (m *SampleStream) Unmarshal(dAtA []byte) error {
 				return err
 			}
 			iNdEx = postIndex
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Histograms", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowQueryrange
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthQueryrange
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthQueryrange
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Histograms = append(m.Histograms, github_com_grafana_loki_v3_pkg_logproto.SampleHistogram{})
+			if err := m.Histograms[len(m.Histograms)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipQueryrange(dAtA[iNdEx:])
@@ -334,7 +334,12 @@ func extractSampleStream(start, end int64, stream SampleStream) (SampleStream, b
 			result.Samples = append(result.Samples, sample)
 		}
 	}
-	if len(result.Samples) == 0 {
+	for _, h := range stream.Histograms {
+		if start <= h.Timestamp && h.Timestamp <= end {
+			result.Histograms = append(result.Histograms, h)
+		}
+	}
+	if len(result.Samples) == 0 && len(result.Histograms) == 0 {
 		return SampleStream{}, false
 	}
 	return result, true
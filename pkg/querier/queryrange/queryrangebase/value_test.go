@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/pkg/errors"
+	"github.com/prometheus/prometheus/model/histogram"
 	"github.com/prometheus/prometheus/model/labels"
 	"github.com/prometheus/prometheus/promql"
 	"github.com/stretchr/testify/require"
@@ -154,6 +155,29 @@ func TestFromValue(t *testing.T) {
 				},
 			},
 		},
+		// Vector of native histograms
+		{
+			input: &promql.Result{
+				Value: promql.Vector{
+					promql.Sample{
+						T:      1,
+						H:      testHistogram,
+						Metric: labels.FromStrings("a", "a1"),
+					},
+				},
+			},
+			err: false,
+			expected: []SampleStream{
+				{
+					Labels: []logproto.LabelAdapter{
+						{Name: "a", Value: "a1"},
+					},
+					Histograms: []logproto.SampleHistogram{
+						logproto.NewSampleHistogram(1, testHistogram),
+					},
+				},
+			},
+		},
 	}
 
 	for i, c := range testExpr {
@@ -168,3 +192,34 @@ func TestFromValue(t *testing.T) {
 		})
 	}
 }
+
+var testHistogram = &histogram.FloatHistogram{
+	CounterResetHint: histogram.GaugeType,
+	Schema:           3,
+	Count:            3,
+	Sum:              4,
+	PositiveSpans:    []histogram.Span{{Offset: 0, Length: 1}, {Offset: 7, Length: 1}},
+	PositiveBuckets:  []float64{2, 1},
+}
+
+func TestSampleStream_Histograms(t *testing.T) {
+	stream := SampleStream{
+		Labels: []logproto.LabelAdapter{{Name: "a", Value: "a1"}},
+		Histograms: []logproto.SampleHistogram{
+			logproto.NewSampleHistogram(1000, testHistogram),
+		},
+	}
+
+	b, err := stream.Marshal()
+	require.NoError(t, err)
+	var fromProto SampleStream
+	require.NoError(t, fromProto.Unmarshal(b))
+	require.True(t, stream.Equal(fromProto))
+
+	b, err = json.Marshal(&stream)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"metric":{"a":"a1"},"values":null,"histograms":[[1,{"count":"3","sum":"4","buckets":[[0,"0.9170040432046711","1","2"],[0,"1.8340080864093422","2","1"]]}]]}`, string(b))
+	var fromJSON SampleStream
+	require.NoError(t, json.Unmarshal(b, &fromJSON))
+	require.True(t, stream.Equal(fromJSON))
+}
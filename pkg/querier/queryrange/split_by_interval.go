@@ -194,6 +194,17 @@ func (h *splitByInterval) Do(ctx context.Context, r queryrangebase.Request) (que
 		return h.next.Do(ctx, r)
 	}
 
+	// the table of a stats stage can't be merged across splits.
+	if hasStatsStage(r) {
+		return h.next.Do(ctx, r)
+	}
+
+	// duplicates can be in different splits, so the log lines are deduplicated
+	// over the whole time range.
+	if hasDedupStage(r) {
+		return h.next.Do(ctx, r)
+	}
+
 	intervals := h.splitter.split(time.Now().UTC(), tenantIDs, r, interval)
 
 	h.metrics.splits.Observe(float64(len(intervals)))
@@ -259,6 +270,7 @@ func maxRangeVectorAndOffsetDurationFromQueryString(q string) (time.Duration, ti
 }
 
 // maxRangeVectorAndOffsetDuration returns the maximum range vector and offset duration within a LogQL query.
+// The range and offset of a subquery are added to the ones of its inner expression.
 func maxRangeVectorAndOffsetDuration(expr syntax.Expr) (time.Duration, time.Duration) {
 	if _, ok := expr.(syntax.SampleExpr); !ok {
 		return 0, 0
@@ -266,15 +278,44 @@ func maxRangeVectorAndOffsetDuration(expr syntax.Expr) (time.Duration, time.Dura
 
 	var maxRVDuration, maxOffset time.Duration
 	expr.Walk(func(e syntax.Expr) bool {
-		if r, ok := e.(*syntax.LogRangeExpr); ok {
-			if r.Interval > maxRVDuration {
-				maxRVDuration = r.Interval
-			}
-			if r.Offset > maxOffset {
-				maxOffset = r.Offset
-			}
+		switch r := e.(type) {
+		case *syntax.LogRangeExpr:
+			maxRVDuration = max(maxRVDuration, r.Interval)
+			maxOffset = max(maxOffset, r.Offset)
+		case *syntax.SubqueryExpr:
+			innerRVDuration, innerOffset := maxRangeVectorAndOffsetDuration(r.Left)
+			maxRVDuration = max(maxRVDuration, innerRVDuration+r.Interval)
+			maxOffset = max(maxOffset, innerOffset+r.Offset)
+			return false
 		}
 		return true
 	})
 	return maxRVDuration, maxOffset
 }
+
+// hasStatsStage returns true if the request is a log query with a stats stage,
+// whose result is a table.
+func hasStatsStage(r queryrangebase.Request) bool {
+	req, ok := r.(*LokiRequest)
+	if !ok || req.Plan == nil || req.Plan.AST == nil {
+		return false
+	}
+
+	var found bool
+	req.Plan.AST.Walk(func(e syntax.Expr) bool {
+		if _, ok := e.(*syntax.StatsExpr); ok {
+			found = true
+		}
+		return !found
+	})
+	return found
+}
+
+// hasDedupStage returns true if the request is a log query with a dedup stage.
+func hasDedupStage(r queryrangebase.Request) bool {
+	req, ok := r.(*LokiRequest)
+	if !ok || req.Plan == nil || req.Plan.AST == nil {
+		return false
+	}
+	return syntax.ExtractDedup(req.Plan.AST) != nil
+}
@@ -1545,6 +1545,90 @@ func Test_splitByInterval_Do(t *testing.T) {
 	}
 }
 
+func Test_splitByInterval_Do_StatsStage(t *testing.T) {
+	ctx := user.InjectOrgID(context.Background(), "1")
+	var requests []queryrangebase.Request
+	next := queryrangebase.HandlerFunc(func(_ context.Context, r queryrangebase.Request) (queryrangebase.Response, error) {
+		requests = append(requests, r)
+		return &TableResponse{
+			Columns: []string{"count()"},
+			Rows:    []TableRow{{Values: []string{"1"}}},
+		}, nil
+	})
+
+	split := SplitByIntervalMiddleware(
+		testSchemas,
+		WithSplitByLimits(fakeLimits{maxQueryParallelism: 1}, time.Hour),
+		DefaultCodec,
+		newDefaultSplitter(fakeLimits{}, nil),
+		nilMetrics,
+	).Wrap(next)
+
+	query := `{app="foo"} | logfmt | stats count()`
+	req := &LokiRequest{
+		StartTs:   time.Unix(0, 0),
+		EndTs:     time.Unix(0, (4 * time.Hour).Nanoseconds()),
+		Query:     query,
+		Limit:     1000,
+		Step:      1,
+		Direction: logproto.FORWARD,
+		Path:      "/api/prom/query_range",
+		Plan: &plan.QueryPlan{
+			AST: syntax.MustParseExpr(query),
+		},
+	}
+	res, err := split.Do(ctx, req)
+	require.NoError(t, err)
+
+	// The table of a stats stage can't be merged, so the query isn't split.
+	require.Equal(t, []queryrangebase.Request{req}, requests)
+	require.Equal(t, &TableResponse{
+		Columns: []string{"count()"},
+		Rows:    []TableRow{{Values: []string{"1"}}},
+	}, res)
+}
+
+func Test_splitByInterval_Do_DedupStage(t *testing.T) {
+	ctx := user.InjectOrgID(context.Background(), "1")
+	var requests []queryrangebase.Request
+	next := queryrangebase.HandlerFunc(func(_ context.Context, r queryrangebase.Request) (queryrangebase.Response, error) {
+		requests = append(requests, r)
+		return &LokiResponse{
+			Status:    loghttp.QueryStatusSuccess,
+			Direction: logproto.BACKWARD,
+			Limit:     1000,
+			Version:   1,
+		}, nil
+	})
+
+	split := SplitByIntervalMiddleware(
+		testSchemas,
+		WithSplitByLimits(fakeLimits{maxQueryParallelism: 1}, time.Hour),
+		DefaultCodec,
+		newDefaultSplitter(fakeLimits{}, nil),
+		nilMetrics,
+	).Wrap(next)
+
+	query := `{app="foo"} | json | dedup 5m by (request_id)`
+	req := &LokiRequest{
+		StartTs:   time.Unix(0, 0),
+		EndTs:     time.Unix(0, (4 * time.Hour).Nanoseconds()),
+		Query:     query,
+		Limit:     1000,
+		Step:      1,
+		Direction: logproto.BACKWARD,
+		Path:      "/api/prom/query_range",
+		Plan: &plan.QueryPlan{
+			AST: syntax.MustParseExpr(query),
+		},
+	}
+	_, err := split.Do(ctx, req)
+	require.NoError(t, err)
+
+	// Duplicates can be in different splits, so the query isn't split.
+	require.Equal(t, []queryrangebase.Request{req}, requests)
+}
+
 func Test_series_splitByInterval_Do(t *testing.T) {
 	ctx := user.InjectOrgID(context.Background(), "1")
 	next := queryrangebase.HandlerFunc(func(_ context.Context, _ queryrangebase.Request) (queryrangebase.Response, error) {
@@ -1861,6 +1945,27 @@ func Test_ExitEarly(t *testing.T) {
 	require.Equal(t, expected, res)
 }
 
+func Test_maxRangeVectorAndOffsetDuration(t *testing.T) {
+	for _, tc := range []struct {
+		query          string
+		expectedRange  time.Duration
+		expectedOffset time.Duration
+	}{
+		{`{app="foo"}`, 0, 0},
+		{`rate({app="foo"}[5m])`, 5 * time.Minute, 0},
+		{`rate({app="foo"}[5m] offset 1h) / rate({app="foo"}[1h])`, time.Hour, time.Hour},
+		{`max_over_time(sum(rate({app="foo"}[5m]))[1h:1m])`, time.Hour + 5*time.Minute, 0},
+		{`max_over_time(sum(rate({app="foo"}[5m] offset 10m))[1h:1m] offset 1h)`, time.Hour + 5*time.Minute, time.Hour + 10*time.Minute},
+		{`max_over_time(max_over_time(rate({app="foo"}[5m])[1h:])[1d:1h]) / rate({app="foo"}[2d])`, 48 * time.Hour, 0},
+	} {
+		t.Run(tc.query, func(t *testing.T) {
+			maxRange, maxOffset := maxRangeVectorAndOffsetDuration(syntax.MustParseExpr(tc.query))
+			require.Equal(t, tc.expectedRange, maxRange)
+			require.Equal(t, tc.expectedOffset, maxOffset)
+		})
+	}
+}
+
 func Test_DoesntDeadlock(t *testing.T) {
 	n := 10
 
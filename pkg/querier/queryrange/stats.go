@@ -183,6 +183,10 @@ func StatsCollectorMiddleware() queryrangebase.Middleware {
 					responseStats = &stats.Result{}
 					totalEntries = 1
 					queryType = queryTypeDetectedLabels
+				case *TableResponse:
+					responseStats = &r.Statistics
+					totalEntries = len(r.Rows)
+					queryType = queryTypeLog
 				default:
 					level.Warn(logger).Log("msg", fmt.Sprintf("cannot compute stats, unexpected type: %T", resp))
 				}
@@ -78,6 +78,12 @@ func (q *Querier) Tail(ctx context.Context, req *logproto.TailRequest, categoriz
 		}
 	}
 
+	// Tailed log lines are streamed as they are pushed, so they can't be
+	// deduplicated.
+	if syntax.ExtractDedup(req.Plan.AST) != nil {
+		return nil, httpgrpc.Errorf(http.StatusBadRequest, "dedup is not supported when tailing")
+	}
+
 	deletes, err := deletion.DeletesForUserQuery(ctx, req.Start, time.Now(), q.deleteGetter)
 	if err != nil {
 		level.Error(spanlogger.FromContext(ctx, q.logger)).Log("msg", "failed loading deletes for user", "err", err)
@@ -86,6 +86,33 @@ func TestQuerier_Tail_QueryTimeoutConfigFlag(t *testing.T) {
 	logSelector.AssertExpectations(t)
 }
 
+func TestQuerier_Tail_Dedup(t *testing.T) {
+	request := logproto.TailRequest{
+		Query:    `{type="test"} | dedup`,
+		DelayFor: 0,
+		Limit:    10,
+		Start:    time.Now(),
+	}
+
+	ingester := newMockTailIngester()
+	ingester.On("TailersCount", mock.Anything).Return([]uint32{0}, nil)
+	logSelector := newMockTailLogSelector()
+
+	limits := &testutil.MockLimits{
+		MaxQueryTimeoutVal:            queryTimeout,
+		MaxStreamsMatchersPerQueryVal: 100,
+		MaxConcurrentTailRequestsVal:  10,
+	}
+	tailQuerier := NewQuerier(ingester, logSelector, newMockDeleteGettter("test", []deletionproto.DeleteRequest{}), limits, 7*24*time.Hour, NewMetrics(nil), log.NewNopLogger())
+
+	ctx := user.InjectOrgID(context.Background(), "test")
+	_, err := tailQuerier.Tail(ctx, &request, false)
+	require.Equal(t, httpgrpc.Errorf(http.StatusBadRequest, "dedup is not supported when tailing"), err)
+
+	ingester.AssertNotCalled(t, "Tail", mock.Anything, mock.Anything)
+	logSelector.AssertNotCalled(t, "SelectLogs", mock.Anything, mock.Anything)
+}
+
 func TestQuerier_concurrentTailLimits(t *testing.T) {
 	request := logproto.TailRequest{
 		Query:    "{type=\"test\"}",
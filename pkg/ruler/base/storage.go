@@ -87,35 +87,42 @@ func NewLegacyRuleStore(cfg RuleStoreConfig, hedgeCfg hedging.Config, clientMetr
 		loader = promRules.FileLoader{}
 	}
 
-	var err error
-	var client client.ObjectClient
+	if cfg.Type == "local" {
+		return local.NewLocalRulesClient(cfg.Local, loader)
+	}
+
+	client, err := NewLegacyRuleObjectClient(cfg, hedgeCfg, clientMetrics)
+	if err != nil {
+		return nil, err
+	}
 
+	return objectclient.NewRuleStore(client, loadRulesConcurrency, logger), nil
+}
+
+// NewLegacyRuleObjectClient returns an object client for the object storage
+// backend of the provided cfg. The local backend is not an object storage and
+// not supported.
+func NewLegacyRuleObjectClient(cfg RuleStoreConfig, hedgeCfg hedging.Config, clientMetrics storage.ClientMetrics) (client.ObjectClient, error) {
 	switch cfg.Type {
 	case "azure":
-		client, err = azure.NewBlobStorage(&cfg.Azure, clientMetrics.AzureMetrics, hedgeCfg)
+		return azure.NewBlobStorage(&cfg.Azure, clientMetrics.AzureMetrics, hedgeCfg)
 	case "gcs":
-		client, err = gcp.NewGCSObjectClient(context.Background(), cfg.GCS, hedgeCfg)
+		return gcp.NewGCSObjectClient(context.Background(), cfg.GCS, hedgeCfg)
 	case "s3":
-		client, err = aws.NewS3ObjectClient(cfg.S3, hedgeCfg)
+		return aws.NewS3ObjectClient(cfg.S3, hedgeCfg)
 	case "bos":
-		client, err = baidubce.NewBOSObjectStorage(&cfg.BOS)
+		return baidubce.NewBOSObjectStorage(&cfg.BOS)
 	case "swift":
-		client, err = openstack.NewSwiftObjectClient(cfg.Swift, hedgeCfg)
+		return openstack.NewSwiftObjectClient(cfg.Swift, hedgeCfg)
 	case "cos":
-		client, err = ibmcloud.NewCOSObjectClient(cfg.COS, hedgeCfg)
+		return ibmcloud.NewCOSObjectClient(cfg.COS, hedgeCfg)
 	case "alibabacloud":
-		client, err = alibaba.NewOssObjectClient(context.Background(), cfg.AlibabaCloud)
+		return alibaba.NewOssObjectClient(context.Background(), cfg.AlibabaCloud)
 	case "local":
-		return local.NewLocalRulesClient(cfg.Local, loader)
+		return nil, fmt.Errorf("rule storage mode %v is not an object storage", cfg.Type)
 	default:
 		return nil, fmt.Errorf("unrecognized rule storage mode %v, choose one of: configdb, gcs, s3, swift, azure, local", cfg.Type)
 	}
-
-	if err != nil {
-		return nil, err
-	}
-
-	return objectclient.NewRuleStore(client, loadRulesConcurrency, logger), nil
 }
 
 // NewRuleStore returns a rule store backend client based on the provided cfg.
@@ -134,3 +141,13 @@ func NewRuleStore(ctx context.Context, cfg rulestore.Config, cfgProvider bucket.
 
 	return bucketclient.NewBucketRuleStore(bucketClient, cfgProvider, logger), nil
 }
+
+// NewRuleObjectClient returns an object client for the backend of the
+// provided cfg. The local backend is not an object storage and not supported.
+func NewRuleObjectClient(ctx context.Context, cfg rulestore.Config, component string, logger log.Logger) (client.ObjectClient, error) {
+	if cfg.Backend == local.Name {
+		return nil, fmt.Errorf("rule storage backend %v is not an object storage", cfg.Backend)
+	}
+
+	return bucket.NewObjectClient(ctx, cfg.Backend, bucket.ConfigWithNamedStores{Config: cfg.Config}, component, hedging.Config{}, false, logger)
+}
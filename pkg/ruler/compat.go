@@ -25,6 +25,7 @@ import (
 	"github.com/prometheus/prometheus/template"
 
 	"github.com/grafana/loki/v3/pkg/logql/syntax"
+	"github.com/grafana/loki/v3/pkg/logqlmodel"
 	ruler "github.com/grafana/loki/v3/pkg/ruler/base"
 	"github.com/grafana/loki/v3/pkg/ruler/rulespb"
 	rulerutil "github.com/grafana/loki/v3/pkg/ruler/util"
@@ -60,19 +61,28 @@ type RulesLimits interface {
 // queryFunc returns a new query function using the rules.EngineQueryFunc function
 // and passing an altered timestamp.
 func queryFunc(evaluator Evaluator, checker readyChecker, userID string, logger log.Logger) rules.QueryFunc {
+	evalFn := EvaluatorQueryFunc(evaluator, logger)
 	return func(ctx context.Context, qs string, t time.Time) (promql.Vector, error) {
-		hash := util.HashedQuery(qs)
-		detail := rules.FromOriginContext(ctx)
-		detailLog := log.With(logger, "rule_name", detail.Name, "rule_type", detail.Kind, "query", qs, "query_hash", hash)
-
-		level.Info(detailLog).Log("msg", "evaluating rule")
-
 		// check if storage instance is ready; if not, fail the rule evaluation;
 		// we do this to prevent an attempt to append new samples before the WAL appender is ready
 		if !checker.isReady(userID) {
 			return nil, errNotReady
 		}
 
+		return evalFn(ctx, qs, t)
+	}
+}
+
+// EvaluatorQueryFunc returns a query function evaluating the rule queries
+// with the evaluator.
+func EvaluatorQueryFunc(evaluator Evaluator, logger log.Logger) rules.QueryFunc {
+	return func(ctx context.Context, qs string, t time.Time) (promql.Vector, error) {
+		hash := util.HashedQuery(qs)
+		detail := rules.FromOriginContext(ctx)
+		detailLog := log.With(logger, "rule_name", detail.Name, "rule_type", detail.Kind, "query", qs, "query_hash", hash)
+
+		level.Info(detailLog).Log("msg", "evaluating rule")
+
 		// Extract rule details
 		ruleName := detail.Name
 		ruleType := detail.Kind
@@ -136,7 +146,7 @@ const MetricsPrefix = "loki_ruler_wal_"
 
 var registry storageRegistry
 
-func MultiTenantRuleManager(cfg Config, evaluator Evaluator, overrides RulesLimits, logger log.Logger, reg prometheus.Registerer) ruler.ManagerFactory {
+func MultiTenantRuleManager(cfg Config, evaluator Evaluator, overrides RulesLimits, macros MacroStore, logger log.Logger, reg prometheus.Registerer) ruler.ManagerFactory {
 	reg = prometheus.WrapRegistererWithPrefix(MetricsPrefix, reg)
 
 	registry = newWALRegistry(log.With(logger, "storage", "registry"), reg, cfg, overrides)
@@ -156,7 +166,7 @@ func MultiTenantRuleManager(cfg Config, evaluator Evaluator, overrides RulesLimi
 
 		// GroupLoader builds a cache of the rules as they're loaded by the
 		// manager.This is used to back the memstore
-		groupLoader := NewCachingGroupLoader(GroupLoader{})
+		groupLoader := NewCachingGroupLoader(NewTenantGroupLoader(userID, macros))
 
 		mgr := rules.NewManager(&rules.ManagerOptions{
 			Appendable:               registry,
@@ -245,6 +255,37 @@ func ValidateGroups(grps ...rulefmt.RuleGroup) (errs []error) {
 	return errs
 }
 
+// MacroStore returns the LogQL macros of a tenant.
+type MacroStore interface {
+	Macros(ctx context.Context, tenant string) (syntax.Macros, error)
+}
+
+// parseRuleExpr parses the expression of a rule and expands the macros it
+// references with the macros of the tenant. Without a tenant, as when rules
+// are uploaded, an expression referencing macros is only checked for syntax
+// errors, since it is validated once the rule is loaded for its tenant.
+func parseRuleExpr(ctx context.Context, query, tenant string, store MacroStore) (syntax.Expr, error) {
+	expr, err := syntax.ParseExprWithoutValidation(query)
+	if err != nil {
+		return nil, err
+	}
+	macro := syntax.ExtractMacro(expr)
+	if macro == nil {
+		return syntax.ParseExpr(query)
+	}
+	if tenant == "" {
+		return expr, nil
+	}
+	if store == nil {
+		return nil, logqlmodel.NewParseError(fmt.Sprintf("macros are not enabled, found %s%s", syntax.OpMacro, macro.Name), 0, 0)
+	}
+	macros, err := store.Macros(ctx, tenant)
+	if err != nil {
+		return nil, fmt.Errorf("loading macros: %w", err)
+	}
+	return syntax.ParseExprWithMacros(query, macros)
+}
+
 func validateRule(r *rulefmt.Rule, groupName string) error {
 	if r.Record != "" && r.Alert != "" {
 		return errors.Errorf("only one of 'record' and 'alert' must be set")
@@ -256,7 +297,7 @@ func validateRule(r *rulefmt.Rule, groupName string) error {
 
 	if r.Expr == "" {
 		return errors.Errorf("field 'expr' must be set in rule")
-	} else if _, err := syntax.ParseExpr(r.Expr); err != nil {
+	} else if _, err := parseRuleExpr(context.Background(), r.Expr, "", nil); err != nil {
 		if r.Record != "" {
 			return errors.Wrapf(err, "could not parse expression for record '%s' in group '%s'", r.Record, groupName)
 		}
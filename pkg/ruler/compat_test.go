@@ -12,6 +12,7 @@ import (
 
 	"github.com/grafana/loki/v3/pkg/iter"
 	"github.com/grafana/loki/v3/pkg/logql"
+	"github.com/grafana/loki/v3/pkg/logql/syntax"
 	rulerbase "github.com/grafana/loki/v3/pkg/ruler/base"
 	"github.com/grafana/loki/v3/pkg/util/log"
 	"github.com/grafana/loki/v3/pkg/validation"
@@ -72,6 +73,41 @@ func TestInvalidRuleExprParsing(t *testing.T) {
 	assert.Containsf(t, recordErr.Error(), expectedRecordErrorMsg, "expected error containing '%s', got '%s'", expectedRecordErrorMsg, recordErr)
 }
 
+type mockMacroStore map[string]syntax.Macros
+
+func (s mockMacroStore) Macros(_ context.Context, tenant string) (syntax.Macros, error) {
+	return s[tenant], nil
+}
+
+func TestRuleExprWithMacro(t *testing.T) {
+	rule := &rulefmt.Rule{
+		Alert: "alert-1-name",
+		Expr:  `count_over_time({app="foo"} | @access_log [5m]) > 0`,
+	}
+
+	// The macros are unknown when rules are uploaded.
+	require.NoError(t, validateRule(rule, "test"))
+	_, err := GroupLoader{}.Parse(rule.Expr)
+	require.NoError(t, err)
+
+	store := mockMacroStore{
+		"tenant-a": {"access_log": `| json | status >= 500`},
+	}
+	expr, err := NewTenantGroupLoader("tenant-a", store).Parse(rule.Expr)
+	require.NoError(t, err)
+	require.Equal(t, `(count_over_time({app="foo"} | json | status>=500[5m]) > 0)`, expr.String())
+
+	_, err = NewTenantGroupLoader("tenant-b", store).Parse(rule.Expr)
+	require.ErrorContains(t, err, "macro @access_log is not defined")
+
+	_, err = NewTenantGroupLoader("tenant-a", nil).Parse(rule.Expr)
+	require.ErrorContains(t, err, "macros are not enabled, found @access_log")
+
+	// Syntax errors are reported when rules are uploaded.
+	rule.Expr = `count_over_time({app="foo"} | @access_log [5m]`
+	require.Error(t, validateRule(rule, "test"))
+}
+
 // TestInvalidRemoteWriteConfig tests that a validation error is raised when config is invalid
 func TestInvalidRemoteWriteConfig(t *testing.T) {
 	// if remote-write is not enabled, validation fails
@@ -34,6 +34,7 @@ import (
 	"google.golang.org/grpc/keepalive"
 
 	"github.com/grafana/loki/v3/pkg/loghttp"
+	"github.com/grafana/loki/v3/pkg/logproto"
 	"github.com/grafana/loki/v3/pkg/logqlmodel"
 	"github.com/grafana/loki/v3/pkg/util"
 	"github.com/grafana/loki/v3/pkg/util/build"
@@ -314,11 +315,16 @@ func (r *RemoteEvaluator) decodeResponse(ctx context.Context, resp *httpgrpc.HTT
 		vec := decoded.Data.Result.(loghttp.Vector)
 
 		for _, s := range vec {
-			res = append(res, promql.Sample{
+			sample := promql.Sample{
 				Metric: metricToLabels(s.Metric),
 				F:      float64(s.Value),
 				T:      int64(s.Timestamp),
-			})
+			}
+			if s.Histogram != nil {
+				sample.F = 0
+				sample.H = logproto.FromModelToFloatHistogram(s.Histogram)
+			}
+			res = append(res, sample)
 		}
 
 		instrument.ObserveWithExemplar(ctx, r.metrics.responseSizeSamples.WithLabelValues(orgID), float64(len(res)))
@@ -2,6 +2,7 @@ package ruler
 
 import (
 	"bytes"
+	"context"
 	"os"
 	"sync"
 
@@ -10,14 +11,24 @@ import (
 	"github.com/prometheus/prometheus/promql/parser"
 	"github.com/prometheus/prometheus/rules"
 	"gopkg.in/yaml.v3"
-
-	"github.com/grafana/loki/v3/pkg/logql/syntax"
 )
 
-type GroupLoader struct{}
+// GroupLoader loads and parses rule groups. The zero value doesn't expand
+// macros, see NewTenantGroupLoader.
+type GroupLoader struct {
+	tenant string
+	macros MacroStore
+}
+
+// NewTenantGroupLoader returns a GroupLoader that expands the macros of the
+// rules of a tenant with the macros of the store, which may be nil if macros
+// are disabled.
+func NewTenantGroupLoader(tenant string, macros MacroStore) GroupLoader {
+	return GroupLoader{tenant: tenant, macros: macros}
+}
 
-func (GroupLoader) Parse(query string) (parser.Expr, error) {
-	expr, err := syntax.ParseExpr(query)
+func (g GroupLoader) Parse(query string) (parser.Expr, error) {
+	expr, err := parseRuleExpr(context.Background(), query, g.tenant, g.macros)
 	if err != nil {
 		return nil, err
 	}
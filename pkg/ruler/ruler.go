@@ -13,7 +13,7 @@ import (
 
 var tracer = otel.Tracer("pkg/ruler")
 
-func NewRuler(cfg Config, evaluator Evaluator, reg prometheus.Registerer, logger log.Logger, ruleStore rulestore.RuleStore, limits RulesLimits, metricsNamespace string) (*ruler.Ruler, error) {
+func NewRuler(cfg Config, evaluator Evaluator, macros MacroStore, reg prometheus.Registerer, logger log.Logger, ruleStore rulestore.RuleStore, limits RulesLimits, metricsNamespace string) (*ruler.Ruler, error) {
 	// For backward compatibility, client and clients are defined in the remote_write config.
 	// When both are present, an error is thrown.
 	if len(cfg.RemoteWrite.Clients) > 0 && cfg.RemoteWrite.Client != nil {
@@ -30,7 +30,7 @@ func NewRuler(cfg Config, evaluator Evaluator, reg prometheus.Registerer, logger
 
 	mgr, err := ruler.NewDefaultMultiTenantManager(
 		cfg.Config,
-		MultiTenantRuleManager(cfg, evaluator, limits, logger, reg),
+		MultiTenantRuleManager(cfg, evaluator, limits, macros, logger, reg),
 		reg,
 		logger,
 		limits,
@@ -98,11 +98,12 @@ func NewStorage(logger log.Logger, metrics *Metrics, registerer prometheus.Regis
 			notify = storage.writeNotified.Notify
 		}
 		return &appender{
-			w:         storage,
-			notify:    notify,
-			series:    make([]record.RefSeries, 0, 100),
-			samples:   make([]record.RefSample, 0, 100),
-			exemplars: make([]record.RefExemplar, 0, 10),
+			w:               storage,
+			notify:          notify,
+			series:          make([]record.RefSeries, 0, 100),
+			samples:         make([]record.RefSample, 0, 100),
+			floatHistograms: make([]record.RefFloatHistogramSample, 0, 10),
+			exemplars:       make([]record.RefExemplar, 0, 10),
 		}
 	}
 
@@ -241,6 +242,16 @@ func (w *Storage) loadWAL(r *wlog.Reader) (err error) {
 					}
 				}
 				decoded <- samples
+			case record.FloatHistogramSamples, record.CustomBucketsFloatHistogramSamples:
+				histograms, err := dec.FloatHistogramSamples(rec, nil)
+				if err != nil {
+					errCh <- &wlog.CorruptionErr{
+						Err:     errors.Wrap(err, "decode float histograms"),
+						Segment: r.Segment(),
+						Offset:  r.Offset(),
+					}
+				}
+				decoded <- histograms
 			case record.Tombstones, record.Exemplars:
 				// We don't care about decoding tombstones or exemplars
 				continue
@@ -298,6 +309,20 @@ func (w *Storage) loadWAL(r *wlog.Reader) (err error) {
 
 			//nolint:staticcheck
 			samplesPool.Put(v)
+		case []record.RefFloatHistogramSample:
+			for _, h := range v {
+				series := w.series.getByID(h.Ref)
+				if series == nil {
+					level.Warn(w.logger).Log("msg", "found histogram referencing non-existing series, skipping")
+					continue
+				}
+
+				series.Lock()
+				if h.T > series.lastTs {
+					series.lastTs = h.T
+				}
+				series.Unlock()
+			}
 		default:
 			panic(fmt.Errorf("unexpected decoded type: %T", d))
 		}
@@ -552,26 +577,52 @@ func dirSize(path string) (int64, error) {
 type appender struct {
 	w *Storage
 	// Notify the underlying storage that some sample is written
-	notify    func()
-	series    []record.RefSeries
-	samples   []record.RefSample
-	exemplars []record.RefExemplar
+	notify          func()
+	series          []record.RefSeries
+	samples         []record.RefSample
+	floatHistograms []record.RefFloatHistogramSample
+	exemplars       []record.RefExemplar
 }
 
 var _ storage.Appender = (*appender)(nil)
 
 func (a *appender) Append(ref storage.SeriesRef, l labels.Labels, t int64, v float64) (storage.SeriesRef, error) {
+	series, err := a.getOrCreateByRef(ref, l)
+	if err != nil {
+		return 0, err
+	}
+
+	series.Lock()
+	defer series.Unlock()
+
+	// Update last recorded timestamp. Used by Storage.gc to determine if a
+	// series is stale.
+	series.updateTs(t)
+
+	a.samples = append(a.samples, record.RefSample{
+		Ref: series.ref,
+		T:   t,
+		V:   v,
+	})
+
+	a.w.metrics.TotalAppendedSamples.Inc()
+	return storage.SeriesRef(series.ref), nil
+}
+
+// getOrCreateByRef returns the series of a reference, or creates the series
+// of the labels if the reference is unknown.
+func (a *appender) getOrCreateByRef(ref storage.SeriesRef, l labels.Labels) (*memSeries, error) {
 	series := a.w.series.getByID(chunks.HeadSeriesRef(ref))
 	if series == nil {
 		// Ensure no empty or duplicate labels have gotten through. This mirrors the
 		// equivalent validation code in the TSDB's headAppender.
 		l = l.WithoutEmpty()
 		if l.IsEmpty() {
-			return 0, errors.Wrap(tsdb.ErrInvalidSample, "empty labelset")
+			return nil, errors.Wrap(tsdb.ErrInvalidSample, "empty labelset")
 		}
 
 		if lbl, dup := l.HasDuplicateLabelNames(); dup {
-			return 0, errors.Wrap(tsdb.ErrInvalidSample, fmt.Sprintf(`label name "%s" is not unique`, lbl))
+			return nil, errors.Wrap(tsdb.ErrInvalidSample, fmt.Sprintf(`label name "%s" is not unique`, lbl))
 		}
 
 		var created bool
@@ -586,22 +637,7 @@ func (a *appender) Append(ref storage.SeriesRef, l labels.Labels, t int64, v flo
 			a.w.metrics.TotalCreatedSeries.Inc()
 		}
 	}
-
-	series.Lock()
-	defer series.Unlock()
-
-	// Update last recorded timestamp. Used by Storage.gc to determine if a
-	// series is stale.
-	series.updateTs(t)
-
-	a.samples = append(a.samples, record.RefSample{
-		Ref: series.ref,
-		T:   t,
-		V:   v,
-	})
-
-	a.w.metrics.TotalAppendedSamples.Inc()
-	return storage.SeriesRef(series.ref), nil
+	return series, nil
 }
 
 func (a *appender) getOrCreate(l labels.Labels) (series *memSeries, created bool) {
@@ -661,9 +697,34 @@ func (a *appender) UpdateMetadata(_ storage.SeriesRef, _ labels.Labels, _ metada
 	return 0, nil
 }
 
-func (a *appender) AppendHistogram(_ storage.SeriesRef, _ labels.Labels, _ int64, _ *histogram.Histogram, _ *histogram.FloatHistogram) (storage.SeriesRef, error) {
-	// TODO: support native histograms
-	return 0, nil
+// AppendHistogram appends a native histogram. Integer histograms are stored as
+// float histograms, which are the histograms computed by recording rules.
+func (a *appender) AppendHistogram(ref storage.SeriesRef, l labels.Labels, t int64, h *histogram.Histogram, fh *histogram.FloatHistogram) (storage.SeriesRef, error) {
+	if h != nil {
+		fh = h.ToFloat(nil)
+	}
+	if fh == nil {
+		return 0, errors.Wrap(tsdb.ErrInvalidSample, "missing histogram")
+	}
+
+	series, err := a.getOrCreateByRef(ref, l)
+	if err != nil {
+		return 0, err
+	}
+
+	series.Lock()
+	defer series.Unlock()
+
+	series.updateTs(t)
+
+	a.floatHistograms = append(a.floatHistograms, record.RefFloatHistogramSample{
+		Ref: series.ref,
+		T:   t,
+		FH:  fh,
+	})
+
+	a.w.metrics.TotalAppendedSamples.Inc()
+	return storage.SeriesRef(series.ref), nil
 }
 
 func (a *appender) AppendHistogramCTZeroSample(_ storage.SeriesRef, _ labels.Labels, _ int64, _ int64, _ *histogram.Histogram, _ *histogram.FloatHistogram) (storage.SeriesRef, error) {
@@ -706,6 +767,25 @@ func (a *appender) Commit() error {
 		buf = buf[:0]
 	}
 
+	if len(a.floatHistograms) > 0 {
+		var customBucketsHistograms []record.RefFloatHistogramSample
+		buf, customBucketsHistograms = encoder.FloatHistogramSamples(a.floatHistograms, buf)
+		if len(buf) > 0 {
+			if err := a.w.wal.Log(buf); err != nil {
+				return err
+			}
+		}
+		buf = buf[:0]
+
+		if len(customBucketsHistograms) > 0 {
+			buf = encoder.CustomBucketsFloatHistogramSamples(customBucketsHistograms, buf)
+			if err := a.w.wal.Log(buf); err != nil {
+				return err
+			}
+			buf = buf[:0]
+		}
+	}
+
 	if len(a.exemplars) > 0 {
 		buf = encoder.Exemplars(a.exemplars, buf)
 		if err := a.w.wal.Log(buf); err != nil {
@@ -732,6 +812,14 @@ func (a *appender) Commit() error {
 			series.Unlock()
 		}
 	}
+	for _, h := range a.floatHistograms {
+		series := a.w.series.getByID(h.Ref)
+		if series != nil {
+			series.Lock()
+			series.pendingCommit = false
+			series.Unlock()
+		}
+	}
 
 	return a.Rollback()
 }
@@ -739,6 +827,7 @@ func (a *appender) Commit() error {
 func (a *appender) Rollback() error {
 	a.series = a.series[:0]
 	a.samples = a.samples[:0]
+	a.floatHistograms = a.floatHistograms[:0]
 	a.exemplars = a.exemplars[:0]
 	a.w.appenderPool.Put(a)
 	return nil
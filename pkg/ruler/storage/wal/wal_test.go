@@ -13,6 +13,7 @@ import (
 	"github.com/go-kit/log"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/prometheus/model/exemplar"
+	"github.com/prometheus/prometheus/model/histogram"
 	"github.com/prometheus/prometheus/model/labels"
 	"github.com/prometheus/prometheus/model/value"
 	"github.com/prometheus/prometheus/storage"
@@ -107,6 +108,42 @@ func TestStorage(t *testing.T) {
 	require.Equal(t, expectedExemplars, actualExemplars)
 }
 
+func TestStorage_Histograms(t *testing.T) {
+	walDir := t.TempDir()
+
+	s, err := newTestStorage(walDir)
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, s.Close())
+	}()
+
+	fh := &histogram.FloatHistogram{
+		CounterResetHint: histogram.GaugeType,
+		Schema:           3,
+		Count:            3,
+		Sum:              4,
+		PositiveSpans:    []histogram.Span{{Offset: 0, Length: 1}, {Offset: 7, Length: 1}},
+		PositiveBuckets:  []float64{2, 1},
+	}
+
+	app := s.Appender(context.Background())
+	_, err = app.AppendHistogram(0, labels.FromStrings("__name__", "foo"), 10, nil, fh)
+	require.NoError(t, err)
+	_, err = app.AppendHistogram(0, labels.FromStrings("__name__", "foo"), 20, nil, nil)
+	require.ErrorIs(t, err, tsdb.ErrInvalidSample)
+	require.NoError(t, app.Commit())
+
+	collector := walDataCollector{}
+	replayer := walReplayer{w: &collector}
+	require.NoError(t, replayer.Replay(s.wal.Dir()))
+
+	require.Len(t, collector.series, 1)
+	require.Len(t, collector.floatHistograms, 1)
+	require.Equal(t, collector.series[0].Ref, collector.floatHistograms[0].Ref)
+	require.Equal(t, int64(10), collector.floatHistograms[0].T)
+	require.True(t, fh.Equals(collector.floatHistograms[0].FH))
+}
+
 func TestStorage_ExistingWAL(t *testing.T) {
 	walDir := t.TempDir()
 
@@ -0,0 +1,349 @@
+package audit
+
+import (
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/labels"
+
+	indexshipper_storage "github.com/grafana/loki/v3/pkg/storage/stores/shipper/indexshipper/storage"
+	"github.com/grafana/loki/v3/pkg/storage/stores/shipper/indexshipper/tsdb"
+	"github.com/grafana/loki/v3/pkg/storage/stores/shipper/indexshipper/tsdb/index"
+)
+
+var (
+	// levelLabels are the label names usually holding the level of the logs,
+	// which Loki can detect as structured metadata instead.
+	levelLabels = map[string]struct{}{
+		"level":     {},
+		"lvl":       {},
+		"loglevel":  {},
+		"log_level": {},
+		"severity":  {},
+	}
+
+	// idLabelRegexp matches the names of labels usually holding unique
+	// identifiers.
+	idLabelRegexp = regexp.MustCompile(`(?i)(^|_)(trace|span|request|req|session|user|correlation|transaction)_?id$|(^|_)(uuid|ip|client_ip|url|path)$`)
+)
+
+// CardinalityConfig configures the cardinality audit.
+type CardinalityConfig struct {
+	// Tenant is the tenant of the streams of single tenant indexes.
+	Tenant string
+	// TopN is the number of labels listed per tenant.
+	TopN int
+	// MaxLabelValues is the number of values above which a label is reported
+	// as high cardinality.
+	MaxLabelValues int
+}
+
+// TenantCardinality holds the cardinality of the streams of a tenant.
+type TenantCardinality struct {
+	Tenant  string
+	Streams int
+	Chunks  int
+	Bytes   uint64
+	Entries uint64
+	Labels  []*LabelCardinality
+
+	labels map[string]*LabelCardinality
+}
+
+// LabelCardinality holds the cardinality of a label of a tenant.
+type LabelCardinality struct {
+	Name    string
+	Values  int
+	Streams int
+	Bytes   uint64
+
+	// StreamsWithout is the number of streams there would be without the
+	// label.
+	StreamsWithout int
+
+	values  map[string]struct{}
+	without map[uint64]struct{}
+}
+
+// Explosion returns how many times the label multiplies the number of
+// streams it's in.
+func (l *LabelCardinality) Explosion() float64 {
+	if l.StreamsWithout == 0 {
+		return 0
+	}
+	return float64(l.Streams) / float64(l.StreamsWithout)
+}
+
+// AnalyzeCardinality reads the series of the TSDB index file and returns the
+// cardinality of each tenant. Gzip compressed files are decompressed to a
+// temporary file first.
+func AnalyzeCardinality(ctx context.Context, path string, cfg CardinalityConfig) ([]*TenantCardinality, error) {
+	if indexshipper_storage.IsCompressedFile(path) {
+		decompressed, cleanup, err := decompressIndex(path)
+		if err != nil {
+			return nil, err
+		}
+		defer cleanup()
+		path = decompressed
+	}
+
+	idx, _, err := tsdb.NewTSDBIndexFromFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't open index %s: %w", path, err)
+	}
+	defer idx.Close()
+
+	defaultTenant := cfg.Tenant
+	if defaultTenant == "" {
+		// Compacted indexes are stored in <table>/<tenant>/<file>.
+		defaultTenant = filepath.Base(filepath.Dir(path))
+	}
+
+	tenants := map[string]*TenantCardinality{}
+	err = idx.ForSeries(ctx, "", nil, 0, math.MaxInt64, func(lbls labels.Labels, _ model.Fingerprint, chks []index.ChunkMeta) (stop bool) {
+		tenant := defaultTenant
+		if t := lbls.Get(tsdb.TenantLabel); t != "" {
+			tenant = t
+			lbls = labels.NewBuilder(lbls).Del(tsdb.TenantLabel).Labels()
+		}
+		tc, ok := tenants[tenant]
+		if !ok {
+			tc = &TenantCardinality{Tenant: tenant, labels: map[string]*LabelCardinality{}}
+			tenants[tenant] = tc
+		}
+		tc.add(lbls, chks)
+		return false
+	}, labels.MustNewMatcher(labels.MatchEqual, "", ""))
+	if err != nil {
+		return nil, fmt.Errorf("couldn't read series of index %s: %w", path, err)
+	}
+
+	res := make([]*TenantCardinality, 0, len(tenants))
+	for _, tc := range tenants {
+		tc.finish()
+		res = append(res, tc)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].Streams != res[j].Streams {
+			return res[i].Streams > res[j].Streams
+		}
+		return res[i].Tenant < res[j].Tenant
+	})
+	return res, nil
+}
+
+func decompressIndex(path string) (string, func(), error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", nil, err
+	}
+	defer f.Close()
+
+	r, err := gzip.NewReader(f)
+	if err != nil {
+		return "", nil, fmt.Errorf("couldn't decompress index %s: %w", path, err)
+	}
+	defer r.Close()
+
+	// Keep the directory of the file, which can be the tenant.
+	dir, err := os.MkdirTemp("", "lokitool-audit")
+	if err != nil {
+		return "", nil, err
+	}
+	cleanup := func() { os.RemoveAll(dir) }
+	tenantDir := filepath.Join(dir, filepath.Base(filepath.Dir(path)))
+	if err := os.MkdirAll(tenantDir, 0o700); err != nil {
+		cleanup()
+		return "", nil, err
+	}
+
+	decompressed := filepath.Join(tenantDir, strings.TrimSuffix(filepath.Base(path), filepath.Ext(path)))
+	out, err := os.Create(decompressed)
+	if err != nil {
+		cleanup()
+		return "", nil, err
+	}
+	defer out.Close()
+	if _, err := io.Copy(out, r); err != nil { //nolint:gosec
+		cleanup()
+		return "", nil, fmt.Errorf("couldn't decompress index %s: %w", path, err)
+	}
+	return decompressed, cleanup, nil
+}
+
+func (tc *TenantCardinality) add(lbls labels.Labels, chks []index.ChunkMeta) {
+	var bytes uint64
+	for _, chk := range chks {
+		bytes += uint64(chk.KB) << 10
+		tc.Entries += uint64(chk.Entries)
+	}
+	tc.Streams++
+	tc.Chunks += len(chks)
+	tc.Bytes += bytes
+
+	b := labels.NewBuilder(lbls)
+	lbls.Range(func(l labels.Label) {
+		lc, ok := tc.labels[l.Name]
+		if !ok {
+			lc = &LabelCardinality{Name: l.Name, values: map[string]struct{}{}, without: map[uint64]struct{}{}}
+			tc.labels[l.Name] = lc
+		}
+		lc.values[l.Value] = struct{}{}
+		lc.Streams++
+		lc.Bytes += bytes
+
+		lc.without[labels.StableHash(b.Del(l.Name).Labels())] = struct{}{}
+		b.Set(l.Name, l.Value)
+	})
+}
+
+func (tc *TenantCardinality) finish() {
+	for _, lc := range tc.labels {
+		lc.Values = len(lc.values)
+		lc.StreamsWithout = len(lc.without)
+		lc.values, lc.without = nil, nil
+		tc.Labels = append(tc.Labels, lc)
+	}
+	tc.labels = nil
+	sort.Slice(tc.Labels, func(i, j int) bool {
+		if tc.Labels[i].Values != tc.Labels[j].Values {
+			return tc.Labels[i].Values > tc.Labels[j].Values
+		}
+		return tc.Labels[i].Name < tc.Labels[j].Name
+	})
+}
+
+// ExplodingLabels returns the labels multiplying the number of streams, from
+// the worst one.
+func (tc *TenantCardinality) ExplodingLabels(cfg CardinalityConfig) []*LabelCardinality {
+	var res []*LabelCardinality
+	for _, lc := range tc.Labels {
+		if lc.Values > cfg.MaxLabelValues && lc.Explosion() >= 2 {
+			res = append(res, lc)
+		}
+	}
+	sort.SliceStable(res, func(i, j int) bool {
+		return res[i].Explosion() > res[j].Explosion()
+	})
+	return res
+}
+
+// StructuredMetadataCandidates returns the labels which are better stored as
+// structured metadata: the ones holding identifiers, or with about a value
+// per stream.
+func (tc *TenantCardinality) StructuredMetadataCandidates(cfg CardinalityConfig) []*LabelCardinality {
+	var res []*LabelCardinality
+	for _, lc := range tc.Labels {
+		if _, ok := levelLabels[lc.Name]; ok {
+			continue
+		}
+		highCardinality := lc.Values > cfg.MaxLabelValues && lc.Values*2 >= lc.Streams
+		if highCardinality || (idLabelRegexp.MatchString(lc.Name) && lc.Values > 1) {
+			res = append(res, lc)
+		}
+	}
+	return res
+}
+
+// LevelLabels returns the labels holding the level of the logs.
+func (tc *TenantCardinality) LevelLabels() []*LabelCardinality {
+	var res []*LabelCardinality
+	for _, lc := range tc.Labels {
+		if _, ok := levelLabels[lc.Name]; ok {
+			res = append(res, lc)
+		}
+	}
+	return res
+}
+
+// WriteCardinalityReport writes the ranked cardinality report of the tenants
+// along with the suggested changes.
+func WriteCardinalityReport(w io.Writer, tenants []*TenantCardinality, cfg CardinalityConfig) {
+	for i, tc := range tenants {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		fmt.Fprintf(w, "Tenant %s: %d streams, %d chunks, %d entries, %s\n", tc.Tenant, tc.Streams, tc.Chunks, tc.Entries, formatBytes(tc.Bytes))
+
+		fmt.Fprintf(w, "\n  Top labels by value count:\n")
+		fmt.Fprintf(w, "    %-30s %10s %10s %10s %12s\n", "LABEL", "VALUES", "STREAMS", "EXPLOSION", "BYTES")
+		for j, lc := range tc.Labels {
+			if j == cfg.TopN {
+				break
+			}
+			fmt.Fprintf(w, "    %-30s %10d %10d %9.1fx %12s\n", lc.Name, lc.Values, lc.Streams, lc.Explosion(), formatBytes(lc.Bytes))
+		}
+
+		exploding := tc.ExplodingLabels(cfg)
+		if len(exploding) > 0 {
+			fmt.Fprintf(w, "\n  Labels exploding streams:\n")
+			for _, lc := range exploding {
+				fmt.Fprintf(w, "    %s: %d values, %d streams would be %d without it\n", lc.Name, lc.Values, lc.Streams, lc.StreamsWithout)
+			}
+		}
+
+		candidates := tc.StructuredMetadataCandidates(cfg)
+		if len(candidates) > 0 {
+			fmt.Fprintf(w, "\n  Structured metadata candidates:\n")
+			for _, lc := range candidates {
+				fmt.Fprintf(w, "    %s: %d values in %d streams\n", lc.Name, lc.Values, lc.Streams)
+			}
+		}
+
+		writeSuggestions(w, tc, exploding, candidates)
+	}
+}
+
+func writeSuggestions(w io.Writer, tc *TenantCardinality, exploding, candidates []*LabelCardinality) {
+	levels := tc.LevelLabels()
+
+	drop := map[string]struct{}{}
+	for _, lcs := range [][]*LabelCardinality{levels, exploding, candidates} {
+		for _, lc := range lcs {
+			drop[lc.Name] = struct{}{}
+		}
+	}
+	if len(drop) == 0 {
+		return
+	}
+
+	fmt.Fprintf(w, "\n  Suggested changes:\n")
+	if len(levels) > 0 {
+		names := make([]string, 0, len(levels))
+		for _, lc := range levels {
+			names = append(names, lc.Name)
+		}
+		fmt.Fprintf(w, "    # Detect the level of the logs instead of using the %s label(s), in the overrides of the tenant:\n", strings.Join(names, ", "))
+		fmt.Fprintf(w, "    overrides:\n      %q:\n        discover_log_levels: true\n", tc.Tenant)
+	}
+
+	names := make([]string, 0, len(drop))
+	for name := range drop {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	fmt.Fprintf(w, "    # Drop the labels at the agent, and send the values needed as structured metadata:\n")
+	fmt.Fprintf(w, "    relabel_configs:\n      - action: labeldrop\n        regex: %s\n", strings.Join(names, "|"))
+}
+
+func formatBytes(b uint64) string {
+	const unit = 1024
+	if b < unit {
+		return fmt.Sprintf("%dB", b)
+	}
+	div, exp := uint64(unit), 0
+	for n := b / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f%ciB", float64(b)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,125 @@
+package audit
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/v3/pkg/storage/stores/shipper/indexshipper/tsdb"
+	"github.com/grafana/loki/v3/pkg/storage/stores/shipper/indexshipper/tsdb/index"
+)
+
+// buildIndex writes a TSDB index of the series to the directory, and returns
+// its path.
+func buildIndex(t *testing.T, dir string, series []labels.Labels) string {
+	t.Helper()
+	b := tsdb.NewBuilder(index.FormatV3)
+	for _, lbls := range series {
+		b.AddSeries(lbls, model.Fingerprint(labels.StableHash(lbls)), []index.ChunkMeta{{MinTime: 0, MaxTime: 1000, KB: 2, Entries: 10}})
+	}
+	id, err := b.Build(context.Background(), dir, func(from, through model.Time, checksum uint32) tsdb.Identifier {
+		return tsdb.NewPrefixedIdentifier(tsdb.SingleTenantTSDBIdentifier{
+			TS:       time.Now(),
+			From:     from,
+			Through:  through,
+			Checksum: checksum,
+		}, dir, dir)
+	})
+	require.NoError(t, err)
+	return id.Path()
+}
+
+func TestAnalyzeCardinality(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "tenant-a")
+	var series []labels.Labels
+	for i := 0; i < 150; i++ {
+		for _, level := range []string{"info", "error"} {
+			series = append(series, labels.FromStrings(
+				"app", "api",
+				"level", level,
+				"pod", fmt.Sprintf("api-%d", i),
+			))
+		}
+	}
+	series = append(series,
+		labels.FromStrings("app", "web", "trace_id", "abc"),
+		labels.FromStrings("app", "web", "trace_id", "def"),
+	)
+	path := buildIndex(t, dir, series)
+
+	cfg := CardinalityConfig{TopN: 10, MaxLabelValues: 100}
+	tenants, err := AnalyzeCardinality(context.Background(), path, cfg)
+	require.NoError(t, err)
+	require.Len(t, tenants, 1)
+
+	tc := tenants[0]
+	require.Equal(t, "tenant-a", tc.Tenant)
+	require.Equal(t, 302, tc.Streams)
+	require.Equal(t, 302, tc.Chunks)
+	require.Equal(t, uint64(3020), tc.Entries)
+	require.Equal(t, uint64(302*2048), tc.Bytes)
+
+	require.Equal(t, []string{"pod", "app", "level", "trace_id"}, labelNames(tc.Labels))
+	pod := tc.Labels[0]
+	require.Equal(t, 150, pod.Values)
+	require.Equal(t, 300, pod.Streams)
+	require.Equal(t, 2, pod.StreamsWithout)
+	require.Equal(t, float64(150), pod.Explosion())
+
+	require.Equal(t, []string{"pod"}, labelNames(tc.ExplodingLabels(cfg)))
+	require.Equal(t, []string{"pod", "trace_id"}, labelNames(tc.StructuredMetadataCandidates(cfg)))
+	require.Equal(t, []string{"level"}, labelNames(tc.LevelLabels()))
+
+	var buf bytes.Buffer
+	WriteCardinalityReport(&buf, tenants, cfg)
+	report := buf.String()
+	require.Contains(t, report, "Tenant tenant-a: 302 streams, 302 chunks, 3020 entries, 604.0KiB")
+	require.Contains(t, report, "pod: 150 values, 300 streams would be 2 without it")
+	require.Contains(t, report, "discover_log_levels: true")
+	require.Contains(t, report, "regex: level|pod|trace_id")
+}
+
+func TestAnalyzeCardinality_MultiTenant(t *testing.T) {
+	dir := t.TempDir()
+	path := buildIndex(t, dir, []labels.Labels{
+		labels.FromStrings(tsdb.TenantLabel, "a", "app", "api"),
+		labels.FromStrings(tsdb.TenantLabel, "b", "app", "api"),
+		labels.FromStrings(tsdb.TenantLabel, "b", "app", "web"),
+	})
+
+	// The index is read once decompressed.
+	b, err := os.ReadFile(path)
+	require.NoError(t, err)
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	_, err = w.Write(b)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	require.NoError(t, os.WriteFile(path+".gz", buf.Bytes(), 0o644))
+
+	tenants, err := AnalyzeCardinality(context.Background(), path+".gz", CardinalityConfig{TopN: 10, MaxLabelValues: 100})
+	require.NoError(t, err)
+	require.Len(t, tenants, 2)
+	require.Equal(t, "b", tenants[0].Tenant)
+	require.Equal(t, 2, tenants[0].Streams)
+	require.Equal(t, []string{"app"}, labelNames(tenants[0].Labels))
+	require.Equal(t, "a", tenants[1].Tenant)
+	require.Equal(t, 1, tenants[1].Streams)
+}
+
+func labelNames(lcs []*LabelCardinality) []string {
+	names := make([]string, 0, len(lcs))
+	for _, lc := range lcs {
+		names = append(names, lc.Name)
+	}
+	return names
+}
@@ -5,6 +5,7 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"path/filepath"
 
 	"github.com/alecthomas/kingpin/v2"
 	"github.com/go-kit/log"
@@ -21,6 +22,8 @@ type AuditCommand struct {
 	configFile string
 
 	extraArgs []string
+
+	cardinality audit.CardinalityConfig
 }
 
 func (a *AuditCommand) auditIndex(_ *kingpin.ParseContext) error {
@@ -49,6 +52,47 @@ func (a *AuditCommand) auditIndex(_ *kingpin.ParseContext) error {
 	return nil
 }
 
+func (a *AuditCommand) auditCardinality(_ *kingpin.ParseContext) error {
+	ctx := context.Background()
+	path := a.path
+
+	// Without a configuration the index file is a local one, else it's
+	// downloaded from the object storage.
+	if a.configFile != "" {
+		var auditCfg audit.Config
+		args := append([]string{"-config.file=" + a.configFile}, a.extraArgs...)
+		if err := util_cfg.DefaultUnmarshal(&auditCfg, args, flag.NewFlagSet("audit", flag.ContinueOnError)); err != nil {
+			return fmt.Errorf("failed parsing config: %w", err)
+		}
+		if err := auditCfg.SchemaConfig.Validate(); err != nil {
+			return fmt.Errorf("schema config is invalid: %w", err)
+		}
+		if err := auditCfg.StorageConfig.Validate(); err != nil {
+			return fmt.Errorf("storage config is invalid: %w", err)
+		}
+		if a.cardinality.Tenant == "" {
+			a.cardinality.Tenant = auditCfg.Tenant
+		}
+
+		objClient, err := audit.GetObjectClient(auditCfg)
+		if err != nil {
+			return err
+		}
+		localFile, err := audit.DownloadIndexFile(ctx, auditCfg, a.path, objClient, log.NewLogfmtLogger(os.Stderr))
+		if err != nil {
+			return err
+		}
+		path = filepath.Join(auditCfg.WorkingDir, localFile)
+	}
+
+	tenants, err := audit.AnalyzeCardinality(ctx, path, a.cardinality)
+	if err != nil {
+		return err
+	}
+	audit.WriteCardinalityReport(os.Stdout, tenants, a.cardinality)
+	return nil
+}
+
 func (a *AuditCommand) Register(app *kingpin.Application) {
 	auditCmd := app.Command("audit", "Audit Loki state.")
 
@@ -60,4 +104,15 @@ func (a *AuditCommand) Register(app *kingpin.Application) {
 	auditIndexCmd.Flag("config.file", "Auditing and storage configuration").Required().StringVar(&a.configFile)
 	auditIndexCmd.Flag("index.file", "Index to be audited").Required().StringVar(&a.path)
 	auditIndexCmd.Arg("args", "").StringsVar(&a.extraArgs)
+
+	auditCardinalityCmd := auditCmd.
+		Command("cardinality", "Report the stream cardinality of each tenant of the given TSDB index, and suggest labelling changes.").
+		Action(a.auditCardinality)
+
+	auditCardinalityCmd.Flag("index.file", "TSDB index to be audited, optionally gzip compressed. It's a local file unless --config.file is set.").Required().StringVar(&a.path)
+	auditCardinalityCmd.Flag("config.file", "Storage configuration used to download the index from the object storage.").StringVar(&a.configFile)
+	auditCardinalityCmd.Flag("tenant", "Tenant of the streams of a single tenant index. Defaults to the tenant of the configuration, else to the name of the directory of the index.").StringVar(&a.cardinality.Tenant)
+	auditCardinalityCmd.Flag("top", "Number of labels listed per tenant.").Default("10").IntVar(&a.cardinality.TopN)
+	auditCardinalityCmd.Flag("max-label-values", "Number of values above which a label is reported as high cardinality.").Default("100").IntVar(&a.cardinality.MaxLabelValues)
+	auditCardinalityCmd.Arg("args", "").StringsVar(&a.extraArgs)
 }
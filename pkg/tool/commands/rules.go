@@ -73,9 +73,16 @@ type RuleCommand struct {
 	// Lint Rules Config
 	LintDryRun bool
 
+	// Convert Rules Config
+	MetricMappingFile   string
+	AllowUntranslatable bool
+
 	// Rules check flags
 	Strict bool
 
+	// Rules test flags
+	TestFilesList []string
+
 	// List Rules Config
 	Format string
 
@@ -120,9 +127,15 @@ func (r *RuleCommand) Register(app *kingpin.Application) {
 	lintCmd := rulesCmd.
 		Command("lint", "formats a set of rule files. It reorders keys alphabetically, uses 4 spaces as indentantion, and formats PromQL expressions to a single line.").
 		Action(r.lint)
+	convertCmd := rulesCmd.
+		Command("convert", "converts Prometheus rules over metrics exported from logs into LogQL rules over the logs.").
+		Action(r.convert)
 	checkCmd := rulesCmd.
 		Command("check", "runs various best practice checks against rules.").
 		Action(r.checkRecordingRuleNames)
+	testCmd := rulesCmd.
+		Command("test", "runs unit tests of alerting and recording rules against log streams.").
+		Action(r.testRules)
 
 	// Require Loki cluster address and tentant ID on all these commands
 	for _, c := range []*kingpin.CmdClause{listCmd, printRulesCmd, getRuleGroupCmd, deleteRuleGroupCmd, loadRulesCmd, diffRulesCmd, syncRulesCmd} {
@@ -218,6 +231,16 @@ func (r *RuleCommand) Register(app *kingpin.Application) {
 	).StringVar(&r.RuleFilesPath)
 	lintCmd.Flag("dry-run", "Performs a trial run that doesn't make any changes and (mostly) produces the same outpupt as a real run.").Short('n').BoolVar(&r.LintDryRun)
 
+	// Convert Command
+	convertCmd.Arg("rule-files", "The rule files to convert.").ExistingFilesVar(&r.RuleFilesList)
+	convertCmd.Flag("rule-files", "The rule files to convert. Flag can be reused to load multiple files.").StringVar(&r.RuleFiles)
+	convertCmd.Flag(
+		"rule-dirs",
+		"Comma separated list of paths to directories containing rules yaml files. Each file in a directory with a .yml or .yaml suffix will be parsed.",
+	).StringVar(&r.RuleFilesPath)
+	convertCmd.Flag("metric-mapping", "File mapping the metrics used by the rules to the log lines they are computed from.").Required().ExistingFileVar(&r.MetricMappingFile)
+	convertCmd.Flag("allow-untranslatable", "Succeeds even if some rules can't be translated. These rules are left out of the converted files.").BoolVar(&r.AllowUntranslatable)
+
 	// Check Command
 	checkCmd.Arg("rule-files", "The rule files to check.").ExistingFilesVar(&r.RuleFilesList)
 	checkCmd.Flag("rule-files", "The rule files to check. Flag can be reused to load multiple files.").StringVar(&r.RuleFiles)
@@ -227,6 +250,9 @@ func (r *RuleCommand) Register(app *kingpin.Application) {
 	).StringVar(&r.RuleFilesPath)
 	checkCmd.Flag("strict", "fails rules checks that do not match best practices exactly").BoolVar(&r.Strict)
 
+	// Test Command
+	testCmd.Arg("test-files", "The unit test files to run.").Required().ExistingFilesVar(&r.TestFilesList)
+
 	// List Command
 	listCmd.Flag("format", "Backend type to interact with: <json|yaml|table>").Default("table").EnumVar(&r.Format, formats...)
 	listCmd.Flag("disable-color", "disable colored output").BoolVar(&r.DisableColor)
@@ -688,6 +714,58 @@ func (r *RuleCommand) lint(_ *kingpin.ParseContext) error {
 	return nil
 }
 
+func (r *RuleCommand) convert(_ *kingpin.ParseContext) error {
+	err := r.setupFiles()
+	if err != nil {
+		return errors.Wrap(err, "convert operation unsuccessful, unable to load rules files")
+	}
+
+	mappings, err := rules.LoadMetricMappings(r.MetricMappingFile)
+	if err != nil {
+		return errors.Wrap(err, "convert operation unsuccessful, unable to load metric mapping file")
+	}
+	converter, err := rules.NewConverter(mappings)
+	if err != nil {
+		return errors.Wrap(err, "convert operation unsuccessful, invalid metric mapping file")
+	}
+
+	namespaces, err := rules.ParsePrometheusFiles(r.RuleFilesList)
+	if err != nil {
+		return errors.Wrap(err, "convert operation unsuccessful, unable to parse rules files")
+	}
+
+	var count, untranslatable int
+	converted := make(map[string]rules.RuleNamespace, len(namespaces))
+	for name, ruleNamespace := range namespaces {
+		ns, errs := converter.Convert(ruleNamespace)
+		for _, err := range errs {
+			log.WithFields(log.Fields{
+				"namespace": name,
+				"file":      ruleNamespace.Filepath,
+			}).Warnf("untranslatable rule: %v", err)
+		}
+
+		for _, g := range ruleNamespace.Groups {
+			count += len(g.Rules)
+		}
+		untranslatable += len(errs)
+		converted[name] = ns
+	}
+
+	// the converted rules never overwrite the Prometheus rules they come from.
+	if err := save(converted, false); err != nil {
+		return err
+	}
+
+	if untranslatable > 0 && !r.AllowUntranslatable {
+		return fmt.Errorf("%d of %d rules can't be translated", untranslatable, count)
+	}
+
+	log.Infof("SUCCESS: %d rules found, %d converted expressions", count, count-untranslatable)
+
+	return nil
+}
+
 func (r *RuleCommand) checkRecordingRuleNames(_ *kingpin.ParseContext) error {
 	err := r.setupFiles()
 	if err != nil {
@@ -720,6 +798,26 @@ func (r *RuleCommand) checkRecordingRuleNames(_ *kingpin.ParseContext) error {
 	return nil
 }
 
+func (r *RuleCommand) testRules(_ *kingpin.ParseContext) error {
+	var failed int
+	for _, file := range r.TestFilesList {
+		errs := rules.RunUnitTests(file)
+		for _, err := range errs {
+			fmt.Printf("FAILED: %v\n", err)
+		}
+		if len(errs) > 0 {
+			failed++
+			continue
+		}
+		log.Infof("SUCCESS: %s", file)
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d rule test files failed", failed, len(r.TestFilesList))
+	}
+	return nil
+}
+
 // Taken from https://github.com/prometheus/prometheus/blob/8c8de46003d1800c9d40121b4a5e5de8582ef6e1/cmd/promtool/main.go#L403
 type compareRuleType struct {
 	metric string
@@ -0,0 +1,509 @@
+package rules
+
+import (
+	"bytes"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/promql/parser"
+	yaml "gopkg.in/yaml.v3"
+
+	logql "github.com/grafana/loki/v3/pkg/logql/syntax"
+	"github.com/grafana/loki/v3/pkg/tool/rules/rwrulefmt"
+)
+
+// MetricType is the type of a metric exported from logs.
+type MetricType string
+
+const (
+	// MetricTypeCounter is a counter incremented by every matching log line, or
+	// by the value of the unwrapped label if set.
+	MetricTypeCounter MetricType = "counter"
+	// MetricTypeGauge is a gauge set to the value of the unwrapped label.
+	MetricTypeGauge MetricType = "gauge"
+	// MetricTypeHistogram is a histogram observing the value of the unwrapped label.
+	MetricTypeHistogram MetricType = "histogram"
+)
+
+// MetricMapping maps a metric of an exporter that turns logs into metrics, such as
+// mtail or grok_exporter, to the log lines it is computed from.
+type MetricMapping struct {
+	// Name of the metric. The name of a histogram is the one without the
+	// _bucket, _sum and _count suffixes.
+	Name string     `yaml:"name"`
+	Type MetricType `yaml:"type,omitempty"`
+	// Selector is the stream selector of the log lines the metric is computed from.
+	Selector string `yaml:"selector"`
+	// Pipeline filters the log lines and extracts the labels of the metric.
+	Pipeline string `yaml:"pipeline,omitempty"`
+	// Unwrap is the extracted label holding the value of the metric.
+	Unwrap string `yaml:"unwrap,omitempty"`
+}
+
+// MetricMappings is the format of the metric mapping files.
+type MetricMappings struct {
+	Metrics []MetricMapping `yaml:"metrics"`
+}
+
+// LoadMetricMappings reads the metric mappings of a file.
+func LoadMetricMappings(file string) ([]MetricMapping, error) {
+	content, err := loadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	decoder.KnownFields(true)
+
+	var mappings MetricMappings
+	if err := decoder.Decode(&mappings); err != nil {
+		return nil, fmt.Errorf("unable to parse metric mapping file %s: %w", file, err)
+	}
+	return mappings.Metrics, nil
+}
+
+// ConversionError is the error of a rule whose expression can't be translated.
+type ConversionError struct {
+	Group    string
+	Rule     int
+	RuleName string
+	Err      error
+}
+
+func (e *ConversionError) Error() string {
+	return fmt.Sprintf("group %q, rule %d, %q: %v", e.Group, e.Rule, e.RuleName, e.Err)
+}
+
+func (e *ConversionError) Unwrap() error {
+	return e.Err
+}
+
+// Converter translates PromQL expressions over metrics exported from logs into LogQL
+// expressions over the log lines the metrics are computed from.
+type Converter struct {
+	metrics map[string]MetricMapping
+}
+
+// NewConverter validates the metric mappings and returns a converter using them.
+func NewConverter(mappings []MetricMapping) (*Converter, error) {
+	c := &Converter{metrics: make(map[string]MetricMapping, len(mappings))}
+	for i, m := range mappings {
+		if m.Name == "" {
+			return nil, fmt.Errorf("metric mapping %d has no name", i)
+		}
+		if _, ok := c.metrics[m.Name]; ok {
+			return nil, fmt.Errorf("duplicate metric mapping %s", m.Name)
+		}
+
+		switch m.Type {
+		case "":
+			m.Type = MetricTypeCounter
+		case MetricTypeCounter:
+		case MetricTypeGauge, MetricTypeHistogram:
+			if m.Unwrap == "" {
+				return nil, fmt.Errorf("metric mapping %s of type %s requires an unwrapped label", m.Name, m.Type)
+			}
+		default:
+			return nil, fmt.Errorf("metric mapping %s has unknown type %q", m.Name, m.Type)
+		}
+
+		if _, err := logql.ParseLogSelector(strings.TrimSpace(m.Selector+" "+m.Pipeline), true); err != nil {
+			return nil, fmt.Errorf("metric mapping %s has an invalid selector or pipeline: %w", m.Name, err)
+		}
+		c.metrics[m.Name] = m
+	}
+	return c, nil
+}
+
+// Convert translates the expressions of the rules of the namespace. Rules which can't be
+// translated are left out of the returned namespace, and their errors are returned.
+func (c *Converter) Convert(ns RuleNamespace) (RuleNamespace, []error) {
+	var errs []error
+	converted := RuleNamespace{
+		Namespace: ns.Namespace,
+		Filepath:  ns.Filepath,
+	}
+
+	for _, group := range ns.Groups {
+		g := rwrulefmt.RuleGroup{RuleGroup: group.RuleGroup, RWConfigs: group.RWConfigs}
+		g.Rules = nil
+
+		for i, rule := range group.Rules {
+			expr, err := c.ConvertExpr(rule.Expr)
+			if err != nil {
+				errs = append(errs, &ConversionError{
+					Group:    group.Name,
+					Rule:     i,
+					RuleName: getRuleName(rule),
+					Err:      err,
+				})
+				continue
+			}
+
+			rule.Expr = expr
+			g.Rules = append(g.Rules, rule)
+		}
+
+		if len(g.Rules) > 0 {
+			converted.Groups = append(converted.Groups, g)
+		}
+	}
+
+	return converted, errs
+}
+
+// ConvertExpr translates a PromQL expression into a LogQL expression, which is
+// validated by the LogQL parser.
+func (c *Converter) ConvertExpr(promql string) (string, error) {
+	expr, err := parser.ParseExpr(promql)
+	if err != nil {
+		return "", err
+	}
+
+	s, err := c.convert(expr)
+	if err != nil {
+		return "", err
+	}
+
+	logqlExpr, err := logql.ParseExpr(s)
+	if err != nil {
+		return "", fmt.Errorf("translated expression %s is invalid: %w", s, err)
+	}
+	return logqlExpr.String(), nil
+}
+
+func (c *Converter) convert(expr parser.Expr) (string, error) {
+	switch e := expr.(type) {
+	case *parser.NumberLiteral:
+		return strconv.FormatFloat(e.Val, 'f', -1, 64), nil
+
+	case *parser.StringLiteral:
+		return strconv.Quote(e.Val), nil
+
+	case *parser.ParenExpr:
+		s, err := c.convert(e.Expr)
+		if err != nil {
+			return "", err
+		}
+		return "(" + s + ")", nil
+
+	case *parser.UnaryExpr:
+		s, err := c.convert(e.Expr)
+		if err != nil {
+			return "", err
+		}
+		return e.Op.String() + s, nil
+
+	case *parser.BinaryExpr:
+		return c.convertBinary(e)
+
+	case *parser.AggregateExpr:
+		return c.convertAggregation(e)
+
+	case *parser.Call:
+		return c.convertCall(e)
+
+	case *parser.VectorSelector:
+		return "", fmt.Errorf("instant vector selector %s has no LogQL equivalent, it must be used within a range function", e)
+
+	case *parser.SubqueryExpr:
+		return "", fmt.Errorf("subquery %s has no LogQL equivalent", e)
+
+	default:
+		return "", fmt.Errorf("expression %s has no LogQL equivalent", expr)
+	}
+}
+
+func (c *Converter) convertBinary(e *parser.BinaryExpr) (string, error) {
+	lhs, err := c.convert(e.LHS)
+	if err != nil {
+		return "", err
+	}
+	rhs, err := c.convert(e.RHS)
+	if err != nil {
+		return "", err
+	}
+
+	var sb strings.Builder
+	sb.WriteString(lhs)
+	sb.WriteString(" ")
+	sb.WriteString(e.Op.String())
+	if e.ReturnBool {
+		sb.WriteString(" bool")
+	}
+
+	if m := e.VectorMatching; m != nil && (m.On || len(m.MatchingLabels) > 0) {
+		if m.On {
+			sb.WriteString(" on (")
+		} else {
+			sb.WriteString(" ignoring (")
+		}
+		sb.WriteString(strings.Join(m.MatchingLabels, ", "))
+		sb.WriteString(")")
+
+		switch m.Card {
+		case parser.CardManyToOne:
+			sb.WriteString(" group_left (" + strings.Join(m.Include, ", ") + ")")
+		case parser.CardOneToMany:
+			sb.WriteString(" group_right (" + strings.Join(m.Include, ", ") + ")")
+		}
+	}
+
+	sb.WriteString(" ")
+	sb.WriteString(rhs)
+	return sb.String(), nil
+}
+
+func (c *Converter) convertAggregation(e *parser.AggregateExpr) (string, error) {
+	switch e.Op {
+	case parser.SUM, parser.AVG, parser.MIN, parser.MAX, parser.COUNT,
+		parser.STDDEV, parser.STDVAR, parser.TOPK, parser.BOTTOMK:
+	default:
+		return "", fmt.Errorf("aggregation %s has no LogQL equivalent", e.Op)
+	}
+
+	inner, err := c.convert(e.Expr)
+	if err != nil {
+		return "", err
+	}
+	if e.Param != nil {
+		param, err := c.convert(e.Param)
+		if err != nil {
+			return "", err
+		}
+		inner = param + ", " + inner
+	}
+
+	return e.Op.String() + grouping(e.Grouping, e.Without) + " (" + inner + ")", nil
+}
+
+func (c *Converter) convertCall(e *parser.Call) (string, error) {
+	switch name := e.Func.Name; name {
+	case "rate", "increase":
+		sel, rng, err := matrixSelector(e.Args[0])
+		if err != nil {
+			return "", err
+		}
+		m, unwrap, err := c.counter(sel)
+		if err != nil {
+			return "", err
+		}
+
+		// Without an unwrapped value, every log line increments the counter by one.
+		fn := name
+		if name == "increase" {
+			fn = "count_over_time"
+			if unwrap {
+				fn = "sum_over_time"
+			}
+		}
+		return fn + "(" + logRange(m, sel, rng, unwrap) + ")", nil
+
+	case "avg_over_time", "min_over_time", "max_over_time", "sum_over_time",
+		"stddev_over_time", "stdvar_over_time", "last_over_time", "quantile_over_time":
+		arg := e.Args[len(e.Args)-1]
+		sel, rng, err := matrixSelector(arg)
+		if err != nil {
+			return "", err
+		}
+		m, err := c.mapping(sel.Name)
+		if err != nil {
+			return "", err
+		}
+		if m.Type != MetricTypeGauge {
+			return "", fmt.Errorf("%s of %s metric %s has no LogQL equivalent", name, m.Type, m.Name)
+		}
+
+		args := logRange(m, sel, rng, true)
+		if name == "quantile_over_time" {
+			q, err := c.convert(e.Args[0])
+			if err != nil {
+				return "", err
+			}
+			args = q + ", " + args
+		}
+		return name + "(" + args + ")", nil
+
+	case "absent_over_time":
+		sel, rng, err := matrixSelector(e.Args[0])
+		if err != nil {
+			return "", err
+		}
+		m, err := c.mapping(sel.Name)
+		if err != nil {
+			return "", err
+		}
+		return name + "(" + logRange(m, sel, rng, false) + ")", nil
+
+	case "histogram_quantile":
+		return c.convertHistogramQuantile(e)
+
+	case "vector", "label_replace":
+		args := make([]string, 0, len(e.Args))
+		for _, arg := range e.Args {
+			s, err := c.convert(arg)
+			if err != nil {
+				return "", err
+			}
+			args = append(args, s)
+		}
+		return name + "(" + strings.Join(args, ", ") + ")", nil
+
+	default:
+		return "", fmt.Errorf("function %s has no LogQL equivalent", name)
+	}
+}
+
+// convertHistogramQuantile translates the quantile of the rate of the buckets of a histogram
+// into the quantile of the observed values. Unlike histogram_quantile, quantile_over_time
+// computes the exact quantile rather than interpolating it from the buckets.
+func (c *Converter) convertHistogramQuantile(e *parser.Call) (string, error) {
+	q, err := c.convert(e.Args[0])
+	if err != nil {
+		return "", err
+	}
+
+	inner := unwrapParens(e.Args[1])
+	var groups []string
+	aggregated := false
+	if agg, ok := inner.(*parser.AggregateExpr); ok {
+		if agg.Op != parser.SUM || agg.Without || !slices.Contains(agg.Grouping, model.BucketLabel) {
+			return "", fmt.Errorf("histogram_quantile of %s has no LogQL equivalent, only the sum by le of the rate of the buckets is supported", agg)
+		}
+		for _, l := range agg.Grouping {
+			if l != model.BucketLabel {
+				groups = append(groups, l)
+			}
+		}
+		aggregated = true
+		inner = unwrapParens(agg.Expr)
+	}
+
+	call, ok := inner.(*parser.Call)
+	if !ok || call.Func.Name != "rate" {
+		return "", fmt.Errorf("histogram_quantile of %s has no LogQL equivalent, only the rate of the buckets is supported", inner)
+	}
+	sel, rng, err := matrixSelector(call.Args[0])
+	if err != nil {
+		return "", err
+	}
+	m, err := c.mapping(sel.Name)
+	if err != nil {
+		return "", err
+	}
+	if m.Type != MetricTypeHistogram || sel.Name != m.Name+"_bucket" {
+		return "", fmt.Errorf("histogram_quantile of %s has no LogQL equivalent, only the rate of the buckets of a histogram is supported", sel.Name)
+	}
+	for _, matcher := range sel.LabelMatchers {
+		if matcher.Name == model.BucketLabel {
+			return "", fmt.Errorf("matcher %s of the buckets of %s has no LogQL equivalent", matcher, m.Name)
+		}
+	}
+
+	s := "quantile_over_time(" + q + ", " + logRange(m, sel, rng, true) + ")"
+	if aggregated {
+		// An empty grouping aggregates all the streams, as the sum by le does.
+		s += " by (" + strings.Join(groups, ", ") + ")"
+	}
+	return s, nil
+}
+
+// counter returns the mapping of a counter, or of the count or sum of a histogram, and
+// whether its value is the unwrapped label rather than the number of log lines.
+func (c *Converter) counter(sel *parser.VectorSelector) (MetricMapping, bool, error) {
+	m, err := c.mapping(sel.Name)
+	if err != nil {
+		return m, false, err
+	}
+
+	switch {
+	case m.Type == MetricTypeCounter:
+		return m, m.Unwrap != "", nil
+	case m.Type == MetricTypeHistogram && sel.Name == m.Name+"_count":
+		return m, false, nil
+	case m.Type == MetricTypeHistogram && sel.Name == m.Name+"_sum":
+		return m, true, nil
+	}
+	return m, false, fmt.Errorf("rate or increase of %s metric %s has no LogQL equivalent", m.Type, sel.Name)
+}
+
+// mapping returns the mapping of a series, which is either the mapping of its name, or the
+// mapping of its histogram if it's a bucket, count or sum series.
+func (c *Converter) mapping(name string) (MetricMapping, error) {
+	if m, ok := c.metrics[name]; ok {
+		return m, nil
+	}
+	for _, suffix := range []string{"_bucket", "_count", "_sum"} {
+		if n, ok := strings.CutSuffix(name, suffix); ok {
+			if m, ok := c.metrics[n]; ok && m.Type == MetricTypeHistogram {
+				return m, nil
+			}
+		}
+	}
+	return MetricMapping{}, fmt.Errorf("no mapping for metric %s", name)
+}
+
+func matrixSelector(expr parser.Expr) (*parser.VectorSelector, time.Duration, error) {
+	ms, ok := unwrapParens(expr).(*parser.MatrixSelector)
+	if !ok {
+		return nil, 0, fmt.Errorf("expression %s has no LogQL equivalent, a range vector selector is required", expr)
+	}
+	sel := ms.VectorSelector.(*parser.VectorSelector)
+	if sel.Timestamp != nil || sel.StartOrEnd != 0 {
+		return nil, 0, fmt.Errorf("@ modifier of %s has no LogQL equivalent", sel)
+	}
+	return sel, ms.Range, nil
+}
+
+// logRange returns the log range of the lines of a metric, filtered by the label matchers
+// of the selector.
+func logRange(m MetricMapping, sel *parser.VectorSelector, rng time.Duration, unwrap bool) string {
+	var sb strings.Builder
+	sb.WriteString(m.Selector)
+	if m.Pipeline != "" {
+		sb.WriteString(" " + m.Pipeline)
+	}
+
+	for _, matcher := range sel.LabelMatchers {
+		if matcher.Name == labels.MetricName {
+			continue
+		}
+		sb.WriteString(" | " + matcher.Name + matcher.Type.String() + strconv.Quote(matcher.Value))
+	}
+
+	if unwrap {
+		sb.WriteString(" | unwrap " + m.Unwrap)
+	}
+
+	sb.WriteString(" [" + model.Duration(rng).String() + "]")
+	if sel.OriginalOffset != 0 {
+		sb.WriteString(" offset " + model.Duration(sel.OriginalOffset).String())
+	}
+	return sb.String()
+}
+
+func grouping(lbls []string, without bool) string {
+	if !without && len(lbls) == 0 {
+		return ""
+	}
+	op := " by"
+	if without {
+		op = " without"
+	}
+	return op + " (" + strings.Join(lbls, ", ") + ")"
+}
+
+func unwrapParens(expr parser.Expr) parser.Expr {
+	for {
+		p, ok := expr.(*parser.ParenExpr)
+		if !ok {
+			return expr
+		}
+		expr = p.Expr
+	}
+}
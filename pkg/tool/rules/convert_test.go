@@ -0,0 +1,192 @@
+package rules
+
+import (
+	"testing"
+
+	"github.com/prometheus/prometheus/model/rulefmt"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/v3/pkg/tool/rules/rwrulefmt"
+)
+
+var testMetricMappings = []MetricMapping{
+	{Name: "http_requests_total", Selector: `{app="nginx"}`, Pipeline: `| pattern "<_> <method> <_> <status> <_>"`},
+	{Name: "http_response_bytes_total", Selector: `{app="nginx"}`, Pipeline: "| logfmt", Unwrap: "bytes"},
+	{Name: "queue_depth", Type: MetricTypeGauge, Selector: `{app="worker"}`, Pipeline: "| json", Unwrap: "depth"},
+	{Name: "request_duration_seconds", Type: MetricTypeHistogram, Selector: `{app="api"}`, Pipeline: "| logfmt", Unwrap: "duration"},
+}
+
+func TestConvertExpr(t *testing.T) {
+	c, err := NewConverter(testMetricMappings)
+	require.NoError(t, err)
+
+	for _, tc := range []struct {
+		name     string
+		expr     string
+		expected string
+		err      string
+	}{
+		{
+			name:     "rate of a counter",
+			expr:     `sum by (status) (rate(http_requests_total{method="GET"}[5m]))`,
+			expected: `sum by (status)(rate({app="nginx"} | pattern "<_> <method> <_> <status> <_>" | method="GET"[5m]))`,
+		},
+		{
+			name:     "increase of a counter",
+			expr:     `increase(http_requests_total{status=~"5.."}[1h] offset 1d)`,
+			expected: `count_over_time({app="nginx"} | pattern "<_> <method> <_> <status> <_>" | status=~`+"`5..`"+`[1h] offset 24h0m0s)`,
+		},
+		{
+			name:     "increase of a counter with a value",
+			expr:     `increase(http_response_bytes_total[10m])`,
+			expected: `sum_over_time({app="nginx"} | logfmt | unwrap bytes[10m])`,
+		},
+		{
+			name:     "ratio alert",
+			expr:     `sum(rate(http_requests_total{status=~"5.."}[5m])) / sum(rate(http_requests_total[5m])) > 0.05`,
+			expected: `((sum(rate({app="nginx"} | pattern "<_> <method> <_> <status> <_>" | status=~`+"`5..`"+`[5m])) / sum(rate({app="nginx"} | pattern "<_> <method> <_> <status> <_>"[5m]))) > 0.05)`,
+		},
+		{
+			name:     "gauge",
+			expr:     `max_over_time(queue_depth[5m]) > bool 100`,
+			expected: `(max_over_time({app="worker"} | json | unwrap depth[5m]) > bool 100)`,
+		},
+		{
+			name:     "histogram quantile",
+			expr:     `histogram_quantile(0.99, sum by (le, route) (rate(request_duration_seconds_bucket[5m])))`,
+			expected: `quantile_over_time(0.99,{app="api"} | logfmt | unwrap duration[5m]) by (route)`,
+		},
+		{
+			name:     "histogram quantile of all streams",
+			expr:     `histogram_quantile(0.5, sum by (le) (rate(request_duration_seconds_bucket[1m])))`,
+			expected: `quantile_over_time(0.5,{app="api"} | logfmt | unwrap duration[1m]) by ()`,
+		},
+		{
+			name:     "histogram average",
+			expr:     `rate(request_duration_seconds_sum[5m]) / rate(request_duration_seconds_count[5m])`,
+			expected: `(rate({app="api"} | logfmt | unwrap duration[5m]) / rate({app="api"} | logfmt[5m]))`,
+		},
+		{
+			name:     "topk",
+			expr:     `topk(3, sum by (status) (increase(http_requests_total[1h])))`,
+			expected: `topk(3,sum by (status)(count_over_time({app="nginx"} | pattern "<_> <method> <_> <status> <_>"[1h])))`,
+		},
+		{
+			name: "unknown metric",
+			expr: `rate(up[5m])`,
+			err:  "no mapping for metric up",
+		},
+		{
+			name: "instant vector",
+			expr: `queue_depth > 100`,
+			err:  "it must be used within a range function",
+		},
+		{
+			name: "unsupported function",
+			expr: `irate(http_requests_total[5m])`,
+			err:  "function irate has no LogQL equivalent",
+		},
+		{
+			name: "gauge function of a counter",
+			expr: `avg_over_time(http_requests_total[5m])`,
+			err:  "avg_over_time of counter metric http_requests_total has no LogQL equivalent",
+		},
+		{
+			name: "subquery",
+			expr: `max_over_time(rate(http_requests_total[5m])[1h:1m])`,
+			err:  "has no LogQL equivalent",
+		},
+		{
+			name: "histogram quantile without the le label",
+			expr: `histogram_quantile(0.9, sum by (route) (rate(request_duration_seconds_bucket[5m])))`,
+			err:  "only the sum by le of the rate of the buckets is supported",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			expr, err := c.ConvertExpr(tc.expr)
+			if tc.err != "" {
+				require.ErrorContains(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, expr)
+		})
+	}
+}
+
+func TestConvert(t *testing.T) {
+	c, err := NewConverter(testMetricMappings)
+	require.NoError(t, err)
+
+	ns := RuleNamespace{
+		Namespace: "nginx",
+		Groups: []rwrulefmt.RuleGroup{
+			{
+				RuleGroup: rulefmt.RuleGroup{
+					Name: "requests",
+					Rules: []rulefmt.Rule{
+						{Record: "status:http_requests:rate5m", Expr: `sum by (status) (rate(http_requests_total[5m]))`},
+						{Alert: "NginxDown", Expr: `up{job="nginx"} == 0`},
+					},
+				},
+			},
+			{
+				RuleGroup: rulefmt.RuleGroup{
+					Name: "exporter",
+					Rules: []rulefmt.Rule{
+						{Record: "job:up:sum", Expr: `sum by (job) (up)`},
+					},
+				},
+			},
+		},
+	}
+
+	converted, errs := c.Convert(ns)
+	require.Len(t, errs, 2)
+	require.EqualError(t, errs[0], `group "requests", rule 1, "NginxDown": instant vector selector up{job="nginx"} has no LogQL equivalent, it must be used within a range function`)
+	require.EqualError(t, errs[1], `group "exporter", rule 0, "job:up:sum": instant vector selector up has no LogQL equivalent, it must be used within a range function`)
+
+	// Groups without any translated rule are left out.
+	require.Len(t, converted.Groups, 1)
+	require.Equal(t, "requests", converted.Groups[0].Name)
+	require.Equal(t, []rulefmt.Rule{
+		{Record: "status:http_requests:rate5m", Expr: `sum by (status)(rate({app="nginx"} | pattern "<_> <method> <_> <status> <_>"[5m]))`},
+	}, converted.Groups[0].Rules)
+
+	// The original namespace is left untouched.
+	require.Equal(t, `sum by (status) (rate(http_requests_total[5m]))`, ns.Groups[0].Rules[0].Expr)
+}
+
+func TestNewConverter(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		mappings []MetricMapping
+		err      string
+	}{
+		{
+			name:     "duplicate",
+			mappings: []MetricMapping{{Name: "a", Selector: `{app="a"}`}, {Name: "a", Selector: `{app="b"}`}},
+			err:      "duplicate metric mapping a",
+		},
+		{
+			name:     "gauge without unwrap",
+			mappings: []MetricMapping{{Name: "a", Type: MetricTypeGauge, Selector: `{app="a"}`}},
+			err:      "metric mapping a of type gauge requires an unwrapped label",
+		},
+		{
+			name:     "unknown type",
+			mappings: []MetricMapping{{Name: "a", Type: "summary", Selector: `{app="a"}`}},
+			err:      `metric mapping a has unknown type "summary"`,
+		},
+		{
+			name:     "invalid selector",
+			mappings: []MetricMapping{{Name: "a", Selector: `app="a"`}},
+			err:      "metric mapping a has an invalid selector or pipeline",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := NewConverter(tc.mappings)
+			require.ErrorContains(t, err, tc.err)
+		})
+	}
+}
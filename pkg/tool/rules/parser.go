@@ -25,8 +25,17 @@ var (
 
 // ParseFiles returns a formatted set of prometheus rule groups
 func ParseFiles(files []string) (map[string]RuleNamespace, error) {
+	return parseFiles(files, ParseLoki)
+}
+
+// ParsePrometheusFiles returns a formatted set of prometheus rule groups whose
+// expressions are PromQL rather than LogQL expressions.
+func ParsePrometheusFiles(files []string) (map[string]RuleNamespace, error) {
+	return parseFiles(files, Parse)
+}
+
+func parseFiles(files []string, parseFn func(string) ([]RuleNamespace, []error)) (map[string]RuleNamespace, error) {
 	ruleSet := map[string]RuleNamespace{}
-	var parseFn = ParseLoki
 
 	for _, f := range files {
 		nss, errs := parseFn(f)
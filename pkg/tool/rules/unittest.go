@@ -0,0 +1,343 @@
+package rules
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/grafana/dskit/user"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/labels"
+	promrules "github.com/prometheus/prometheus/rules"
+	"gopkg.in/yaml.v3"
+
+	"github.com/grafana/loki/v3/pkg/logproto"
+	"github.com/grafana/loki/v3/pkg/logql"
+	"github.com/grafana/loki/v3/pkg/logql/syntax"
+	"github.com/grafana/loki/v3/pkg/ruler"
+	util_log "github.com/grafana/loki/v3/pkg/util/log"
+)
+
+const (
+	defaultEvaluationInterval = model.Duration(time.Minute)
+	unitTestTenant            = "fake"
+)
+
+// UnitTestFile is the format of the rule unit test files, based on the one of
+// `promtool test rules`.
+type UnitTestFile struct {
+	// RuleFiles are relative to the directory of the test file.
+	RuleFiles          []string       `yaml:"rule_files"`
+	EvaluationInterval model.Duration `yaml:"evaluation_interval,omitempty"`
+	Tests              []TestGroup    `yaml:"tests"`
+}
+
+// TestGroup is a set of log streams and the alerts and recording rule samples
+// expected from them.
+type TestGroup struct {
+	Name              string                  `yaml:"name,omitempty"`
+	InputStreams      []InputStream           `yaml:"input_streams"`
+	AlertRuleTests    []AlertTestCase         `yaml:"alert_rule_test,omitempty"`
+	RecordingRuleTest []RecordingRuleTestCase `yaml:"recording_rule_test,omitempty"`
+}
+
+// InputStream is a log stream the rules are evaluated against.
+type InputStream struct {
+	Labels  string       `yaml:"labels"`
+	Entries []InputEntry `yaml:"entries"`
+}
+
+// InputEntry is a log line of an input stream. Its timestamp is relative to
+// the start of the test.
+type InputEntry struct {
+	Timestamp model.Duration `yaml:"ts"`
+	Line      string         `yaml:"line"`
+}
+
+// AlertTestCase is the firing alerts of an alerting rule expected at the
+// evaluation time.
+type AlertTestCase struct {
+	EvalTime  model.Duration `yaml:"eval_time"`
+	Alertname string         `yaml:"alertname"`
+	ExpAlerts []ExpAlert     `yaml:"exp_alerts"`
+}
+
+// ExpAlert is an expected alert. The alertname label is added to the labels.
+type ExpAlert struct {
+	ExpLabels      map[string]string `yaml:"exp_labels"`
+	ExpAnnotations map[string]string `yaml:"exp_annotations"`
+}
+
+// RecordingRuleTestCase is the samples of a recording rule expected at the
+// evaluation time.
+type RecordingRuleTestCase struct {
+	EvalTime   model.Duration `yaml:"eval_time"`
+	Record     string         `yaml:"record"`
+	ExpSamples []ExpSample    `yaml:"exp_samples"`
+}
+
+// ExpSample is an expected sample. Its labels don't include the metric name.
+type ExpSample struct {
+	Labels string  `yaml:"labels"`
+	Value  float64 `yaml:"value"`
+}
+
+// RunUnitTests runs the rule unit tests of the file, and returns the failures.
+func RunUnitTests(filename string) []error {
+	b, err := os.ReadFile(filename)
+	if err != nil {
+		return []error{err}
+	}
+	var file UnitTestFile
+	decoder := yaml.NewDecoder(bytes.NewReader(b))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&file); err != nil {
+		return []error{fmt.Errorf("parsing %s: %w", filename, err)}
+	}
+	if file.EvaluationInterval == 0 {
+		file.EvaluationInterval = defaultEvaluationInterval
+	}
+
+	ruleFiles := make([]string, 0, len(file.RuleFiles))
+	for _, f := range file.RuleFiles {
+		if !filepath.IsAbs(f) {
+			f = filepath.Join(filepath.Dir(filename), f)
+		}
+		ruleFiles = append(ruleFiles, f)
+	}
+	namespaces, err := ParseFiles(ruleFiles)
+	if err != nil {
+		return []error{fmt.Errorf("parsing rule files of %s: %w", filename, err)}
+	}
+
+	var errs []error
+	for i, tg := range file.Tests {
+		name := tg.Name
+		if name == "" {
+			name = fmt.Sprintf("#%d", i)
+		}
+		for _, err := range tg.run(namespaces, time.Duration(file.EvaluationInterval)) {
+			errs = append(errs, fmt.Errorf("%s: test %s: %w", filename, name, err))
+		}
+	}
+	return errs
+}
+
+// buildRules returns the rules of the namespaces, in the order they are
+// evaluated.
+func buildRules(namespaces map[string]RuleNamespace) ([]promrules.Rule, error) {
+	names := make([]string, 0, len(namespaces))
+	for name := range namespaces {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var loader ruler.GroupLoader
+	var res []promrules.Rule
+	for _, name := range names {
+		for _, group := range namespaces[name].Groups {
+			for _, rule := range group.Rules {
+				expr, err := loader.Parse(rule.Expr)
+				if err != nil {
+					return nil, fmt.Errorf("rule %s of group %s: %w", getRuleName(rule), group.Name, err)
+				}
+
+				lbls := map[string]string{}
+				for k, v := range group.Labels {
+					lbls[k] = v
+				}
+				for k, v := range rule.Labels {
+					lbls[k] = v
+				}
+
+				if rule.Alert != "" {
+					res = append(res, promrules.NewAlertingRule(
+						rule.Alert, expr, time.Duration(rule.For), time.Duration(rule.KeepFiringFor),
+						labels.FromMap(lbls), labels.FromMap(rule.Annotations), labels.EmptyLabels(), "",
+						true, util_log.SlogFromGoKit(log.NewNopLogger()),
+					))
+					continue
+				}
+				res = append(res, promrules.NewRecordingRule(rule.Record, expr, labels.FromMap(lbls)))
+			}
+		}
+	}
+	return res, nil
+}
+
+func (tg *TestGroup) streams() ([]logproto.Stream, error) {
+	streams := make([]logproto.Stream, 0, len(tg.InputStreams))
+	for _, s := range tg.InputStreams {
+		lbls, err := syntax.ParseLabels(s.Labels)
+		if err != nil {
+			return nil, fmt.Errorf("invalid labels of input stream %q: %w", s.Labels, err)
+		}
+		stream := logproto.Stream{Labels: lbls.String()}
+		for _, e := range s.Entries {
+			stream.Entries = append(stream.Entries, logproto.Entry{
+				Timestamp: time.Unix(0, 0).Add(time.Duration(e.Timestamp)).UTC(),
+				Line:      e.Line,
+			})
+		}
+		sort.SliceStable(stream.Entries, func(i, j int) bool {
+			return stream.Entries[i].Timestamp.Before(stream.Entries[j].Timestamp)
+		})
+		streams = append(streams, stream)
+	}
+	return streams, nil
+}
+
+// evalTimes returns the times the rules are evaluated at: every interval
+// until the last time something is tested, and the tested times.
+func (tg *TestGroup) evalTimes(interval time.Duration) []time.Duration {
+	var last time.Duration
+	tested := map[time.Duration]struct{}{}
+	for _, tc := range tg.AlertRuleTests {
+		tested[time.Duration(tc.EvalTime)] = struct{}{}
+	}
+	for _, tc := range tg.RecordingRuleTest {
+		tested[time.Duration(tc.EvalTime)] = struct{}{}
+	}
+	for t := range tested {
+		last = max(last, t)
+	}
+	for t := time.Duration(0); t <= last; t += interval {
+		tested[t] = struct{}{}
+	}
+
+	times := make([]time.Duration, 0, len(tested))
+	for t := range tested {
+		times = append(times, t)
+	}
+	slices.Sort(times)
+	return times
+}
+
+func (tg *TestGroup) run(namespaces map[string]RuleNamespace, interval time.Duration) []error {
+	streams, err := tg.streams()
+	if err != nil {
+		return []error{err}
+	}
+	// The alert states are kept by the rules, so they are built for each test.
+	rules, err := buildRules(namespaces)
+	if err != nil {
+		return []error{err}
+	}
+
+	logger := log.NewNopLogger()
+	engine := logql.NewEngine(logql.EngineOpts{}, logql.NewMockQuerier(0, streams), logql.NoLimits, logger)
+	evaluator, err := ruler.NewLocalEvaluator(engine, logger)
+	if err != nil {
+		return []error{err}
+	}
+	queryFunc := ruler.EvaluatorQueryFunc(evaluator, logger)
+	ctx := user.InjectOrgID(context.Background(), unitTestTenant)
+
+	var errs []error
+	for _, t := range tg.evalTimes(interval) {
+		ts := time.Unix(0, 0).Add(t).UTC()
+		recorded := map[string][]ExpSample{}
+		for _, rule := range rules {
+			vec, err := rule.Eval(ctx, 0, ts, queryFunc, &url.URL{}, 0)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("evaluating rule %s at %s: %w", rule.Name(), model.Duration(t), err))
+				continue
+			}
+			if _, ok := rule.(*promrules.RecordingRule); ok {
+				for _, s := range vec {
+					lbls := labels.NewBuilder(s.Metric).Del(labels.MetricName).Labels()
+					recorded[rule.Name()] = append(recorded[rule.Name()], ExpSample{Labels: lbls.String(), Value: s.F})
+				}
+			}
+		}
+
+		for _, tc := range tg.AlertRuleTests {
+			if time.Duration(tc.EvalTime) == t {
+				if err := tc.check(rules); err != nil {
+					errs = append(errs, err)
+				}
+			}
+		}
+		for _, tc := range tg.RecordingRuleTest {
+			if time.Duration(tc.EvalTime) == t {
+				if err := tc.check(recorded[tc.Record]); err != nil {
+					errs = append(errs, err)
+				}
+			}
+		}
+	}
+	return errs
+}
+
+func (tc *AlertTestCase) check(rules []promrules.Rule) error {
+	var got []string
+	for _, rule := range rules {
+		alerting, ok := rule.(*promrules.AlertingRule)
+		if !ok || alerting.Name() != tc.Alertname {
+			continue
+		}
+		for _, a := range alerting.ActiveAlerts() {
+			if a.State == promrules.StateFiring {
+				got = append(got, formatAlert(a.Labels, a.Annotations))
+			}
+		}
+	}
+
+	exp := make([]string, 0, len(tc.ExpAlerts))
+	for _, a := range tc.ExpAlerts {
+		lbls := labels.NewBuilder(labels.FromMap(a.ExpLabels)).Set(labels.AlertName, tc.Alertname).Labels()
+		exp = append(exp, formatAlert(lbls, labels.FromMap(a.ExpAnnotations)))
+	}
+
+	return compareResults(fmt.Sprintf("alertname %s at %s", tc.Alertname, tc.EvalTime), "alerts", exp, got)
+}
+
+func formatAlert(lbls, annotations labels.Labels) string {
+	return fmt.Sprintf("labels: %s, annotations: %s", lbls, annotations)
+}
+
+func (tc *RecordingRuleTestCase) check(recorded []ExpSample) error {
+	got := make([]string, 0, len(recorded))
+	for _, s := range recorded {
+		got = append(got, formatSample(s.Labels, s.Value))
+	}
+
+	exp := make([]string, 0, len(tc.ExpSamples))
+	for _, s := range tc.ExpSamples {
+		lbls, err := syntax.ParseLabels(s.Labels)
+		if err != nil {
+			return fmt.Errorf("record %s at %s: invalid expected labels %q: %w", tc.Record, tc.EvalTime, s.Labels, err)
+		}
+		exp = append(exp, formatSample(lbls.String(), s.Value))
+	}
+
+	return compareResults(fmt.Sprintf("record %s at %s", tc.Record, tc.EvalTime), "samples", exp, got)
+}
+
+func formatSample(lbls string, value float64) string {
+	return fmt.Sprintf("%s %g", lbls, value)
+}
+
+func compareResults(name, kind string, exp, got []string) error {
+	sort.Strings(exp)
+	sort.Strings(got)
+	if slices.Equal(exp, got) {
+		return nil
+	}
+	return fmt.Errorf("%s:\n    expected %s:\n%s\n    got %s:\n%s", name, kind, formatResults(exp), kind, formatResults(got))
+}
+
+func formatResults(results []string) string {
+	if len(results) == 0 {
+		return "        none"
+	}
+	return "        " + strings.Join(results, "\n        ")
+}
@@ -0,0 +1,37 @@
+package rules
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunUnitTests(t *testing.T) {
+	require.Empty(t, RunUnitTests("testdata/unittest/tests.yaml"))
+
+	errs := RunUnitTests("testdata/unittest/tests_failure.yaml")
+	require.Len(t, errs, 2)
+	require.ErrorContains(t, errs[0], `alertname HighErrorRate at 1m:
+    expected alerts:
+        labels: {alertname="HighErrorRate", app="api"}, annotations: {}
+    got alerts:
+        none`)
+	require.ErrorContains(t, errs[1], `record app:errors:count5m at 1m:
+    expected samples:
+        {app="api", team="backend"} 2
+    got samples:
+        {app="api", team="backend"} 1`)
+
+	errs = RunUnitTests("testdata/unittest/missing.yaml")
+	require.Len(t, errs, 1)
+}
+
+func TestTestGroup_EvalTimes(t *testing.T) {
+	tg := TestGroup{
+		AlertRuleTests:    []AlertTestCase{{EvalTime: model.Duration(150 * time.Second)}},
+		RecordingRuleTest: []RecordingRuleTestCase{{EvalTime: model.Duration(time.Minute)}},
+	}
+	require.Equal(t, []time.Duration{0, time.Minute, 2 * time.Minute, 150 * time.Second}, tg.evalTimes(time.Minute))
+}
@@ -383,6 +383,48 @@ var queryTests = []struct {
 			"warnings": ["this is a warning"]
 		  }`, emptyStats),
 	},
+	// native histogram vector test
+	{
+		promql.Vector{
+			{
+				T: 1568404331324,
+				H: &histogram.FloatHistogram{
+					Schema:          3,
+					Count:           3,
+					Sum:             4,
+					PositiveSpans:   []histogram.Span{{Offset: 0, Length: 1}, {Offset: 7, Length: 1}},
+					PositiveBuckets: []float64{2, 1},
+				},
+				Metric: labels.FromStrings("job", "varlogs"),
+			},
+		},
+		fmt.Sprintf(`{
+			"data": {
+			  "resultType": "vector",
+			  "result": [
+				{
+				  "metric": {
+					"job": "varlogs"
+				  },
+				  "histogram": [
+					1568404331.324,
+					{
+					  "count": "3",
+					  "sum": "4",
+					  "buckets": [
+						[0, "0.9170040432046711", "1", "2"],
+						[0, "1.8340080864093422", "2", "1"]
+					  ]
+					}
+				  ]
+				}
+			  ],
+			  "stats" : %s
+			},
+			"status": "success",
+			"warnings": ["this is a warning"]
+		  }`, emptyStats),
+	},
 	// matrix test
 	{
 		promql.Matrix{
@@ -454,6 +496,30 @@ var queryTests = []struct {
 			"warnings": ["this is a warning"]
 		  }`, emptyStats),
 	},
+	{
+		logqlmodel.Table{
+			Columns: []string{"status", "count()", "avg(latency)"},
+			Rows: [][]string{
+				{"200", "10", "0.25"},
+				{"500", "2", ""},
+			},
+		},
+		fmt.Sprintf(`{
+			"data": {
+			  "resultType": "table",
+			  "result": {
+				"columns": ["status", "count()", "avg(latency)"],
+				"rows": [
+					["200", "10", "0.25"],
+					["500", "2", ""]
+				]
+			  },
+			  "stats" : %s
+			},
+			"status": "success",
+			"warnings": ["this is a warning"]
+		  }`, emptyStats),
+	},
 }
 
 // covers responses from /loki/api/v1/labels and /loki/api/v1/label/{name}/values
@@ -712,6 +778,8 @@ func Test_QueryResponseResultType(t *testing.T) {
 			require.IsTypef(t, loghttp.Matrix{}, value, "Incorrect type %d", i)
 		case loghttp.ResultTypeVector:
 			require.IsTypef(t, loghttp.Vector{}, value, "Incorrect type %d", i)
+		case loghttp.ResultTypeTable:
+			require.IsTypef(t, loghttp.Table{}, value, "Incorrect type %d", i)
 		default:
 			require.Fail(t, "Unknown result type %s", value.Type())
 		}
@@ -995,14 +1063,13 @@ func randSeries(rand *rand.Rand) promql.Series {
 	var (
 		seriesMetric      = randLabels(rand)
 		seriesFPoints, _  = quick.Value(reflect.TypeOf([]promql.FPoint{}), rand)
-		seriesHPoints, _  = quick.Value(reflect.TypeOf([]promql.HPoint{}), rand)
 		seriesDropName, _ = quick.Value(reflect.TypeOf(bool(false)), rand)
 	)
 
 	return promql.Series{
 		Metric:     seriesMetric,
 		Floats:     seriesFPoints.Interface().([]promql.FPoint),
-		Histograms: seriesHPoints.Interface().([]promql.HPoint),
+		Histograms: randHPoints(rand),
 		DropName:   seriesDropName.Interface().(bool),
 	}
 }
@@ -1029,11 +1096,44 @@ func randEntries(rand *rand.Rand) []logproto.Entry {
 	return entries
 }
 
+// randHistogram returns nil or a valid native histogram, since the buckets
+// of a histogram generated by quick don't match its spans.
+func randHistogram(rand *rand.Rand) *histogram.FloatHistogram {
+	if rand.Intn(2) == 0 {
+		return nil
+	}
+	h := &histogram.FloatHistogram{
+		Schema:        int32(rand.Intn(9)),
+		ZeroThreshold: 1e-128,
+		ZeroCount:     float64(rand.Intn(10)),
+		Sum:           rand.NormFloat64(),
+	}
+	h.Count = h.ZeroCount
+	if n := rand.Intn(10); n > 0 {
+		h.PositiveSpans = []histogram.Span{{Offset: int32(rand.Intn(20) - 10), Length: uint32(n)}}
+		for i := 0; i < n; i++ {
+			c := float64(rand.Intn(10))
+			h.PositiveBuckets = append(h.PositiveBuckets, c)
+			h.Count += c
+		}
+	}
+	return h
+}
+
+func randHPoints(rand *rand.Rand) []promql.HPoint {
+	var points []promql.HPoint
+	for i := 0; i < rand.Intn(10); i++ {
+		if h := randHistogram(rand); h != nil {
+			points = append(points, promql.HPoint{T: rand.Int63(), H: h})
+		}
+	}
+	return points
+}
+
 func randSample(rand *rand.Rand) promql.Sample {
 	var (
 		sampleT, _        = quick.Value(reflect.TypeOf(int64(0)), rand)
 		sampleF, _        = quick.Value(reflect.TypeOf(float64(0)), rand)
-		sampleH, _        = quick.Value(reflect.TypeOf((*histogram.FloatHistogram)(nil)), rand)
 		sampleMetric      = randLabels(rand)
 		sampleDropName, _ = quick.Value(reflect.TypeOf(bool(false)), rand)
 	)
@@ -1041,7 +1141,7 @@ func randSample(rand *rand.Rand) promql.Sample {
 	return promql.Sample{
 		T: sampleT.Interface().(int64),
 		F: sampleF.Interface().(float64),
-		H: sampleH.Interface().(*histogram.FloatHistogram),
+		H: randHistogram(rand),
 
 		Metric:   sampleMetric,
 		DropName: sampleDropName.Interface().(bool),
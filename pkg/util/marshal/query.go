@@ -10,6 +10,7 @@ import (
 	jsoniter "github.com/json-iterator/go"
 	"github.com/pkg/errors"
 	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/histogram"
 	"github.com/prometheus/prometheus/model/labels"
 	"github.com/prometheus/prometheus/promql"
 	"github.com/prometheus/prometheus/promql/parser"
@@ -76,6 +77,14 @@ func NewResultValue(v parser.Value) (loghttp.ResultValue, error) {
 		}
 
 		value = NewMatrix(m)
+	case loghttp.ResultTypeTable:
+		t, ok := v.(logqlmodel.Table)
+
+		if !ok {
+			return nil, fmt.Errorf("unexpected type %T for table", t)
+		}
+
+		value = NewTable(t)
 	default:
 		return nil, fmt.Errorf("v1 endpoints do not support type %s", v.Type())
 	}
@@ -157,6 +166,10 @@ func NewSample(s promql.Sample) model.Sample {
 		Timestamp: model.Time(s.T),
 		Metric:    NewMetric(s.Metric),
 	}
+	if s.H != nil {
+		ret.Value = 0
+		ret.Histogram = logproto.FromFloatHistogramToModel(s.H)
+	}
 
 	return ret
 }
@@ -184,9 +197,27 @@ func NewSampleStream(s promql.Series) model.SampleStream {
 		ret.Values[i].Value = model.SampleValue(p.F)
 	}
 
+	for _, p := range s.Histograms {
+		if p.H == nil {
+			continue
+		}
+		ret.Histograms = append(ret.Histograms, model.SampleHistogramPair{
+			Timestamp: model.Time(p.T),
+			Histogram: logproto.FromFloatHistogramToModel(p.H),
+		})
+	}
+
 	return ret
 }
 
+// NewTable constructs a Table from a logqlmodel.Table
+func NewTable(t logqlmodel.Table) loghttp.Table {
+	return loghttp.Table{
+		Columns: t.Columns,
+		Rows:    t.Rows,
+	}
+}
+
 // NewMetric constructs a labels.Labels from a model.Metric
 func NewMetric(l labels.Labels) model.Metric {
 	ret := make(map[model.LabelName]model.LabelValue)
@@ -365,6 +396,15 @@ func encodeResult(v parser.Value, s *jsoniter.Stream, encodeFlags httpreq.Encodi
 
 		encodeMatrix(m, s)
 
+	case loghttp.ResultTypeTable:
+		t, ok := v.(logqlmodel.Table)
+
+		if !ok {
+			return fmt.Errorf("unexpected type %T for table", t)
+		}
+
+		encodeTable(t, s)
+
 	default:
 		s.WriteNil()
 		return fmt.Errorf("v1 endpoints do not support type %s", v.Type())
@@ -500,6 +540,11 @@ func encodeSample(sample promql.Sample, s *jsoniter.Stream) {
 	encodeMetric(sample.Metric, s)
 
 	s.WriteMore()
+	if sample.H != nil {
+		s.WriteObjectField("histogram")
+		encodeHistogram(sample.T, sample.H, s)
+		return
+	}
 	s.WriteObjectField("value")
 	encodeValue(sample.T, sample.F, s)
 }
@@ -547,14 +592,104 @@ func encodeSampleStream(stream promql.Series, s *jsoniter.Stream) {
 	s.WriteObjectField("metric")
 	encodeMetric(stream.Metric, s)
 
+	histograms := make([]promql.HPoint, 0, len(stream.Histograms))
+	for _, p := range stream.Histograms {
+		if p.H != nil {
+			histograms = append(histograms, p)
+		}
+	}
+
+	// Like the Prometheus API, values are omitted for series of histograms.
+	if len(stream.Floats) > 0 || len(histograms) == 0 {
+		s.WriteMore()
+		s.WriteObjectField("values")
+		s.WriteArrayStart()
+		for i, p := range stream.Floats {
+			if i > 0 {
+				s.WriteMore()
+			}
+			encodeValue(p.T, p.F, s)
+		}
+		s.WriteArrayEnd()
+	}
+
+	if len(histograms) == 0 {
+		return
+	}
 	s.WriteMore()
-	s.WriteObjectField("values")
+	s.WriteObjectField("histograms")
+	s.WriteArrayStart()
+	for i, p := range histograms {
+		if i > 0 {
+			s.WriteMore()
+		}
+		encodeHistogram(p.T, p.H, s)
+	}
+	s.WriteArrayEnd()
+}
+
+// encodeHistogram writes a native histogram in the format of the Prometheus
+// HTTP API, a timestamp followed by the count, sum and buckets.
+func encodeHistogram(T int64, h *histogram.FloatHistogram, s *jsoniter.Stream) {
+	mh := logproto.FromFloatHistogramToModel(h)
+
+	s.WriteArrayStart()
+	s.WriteRaw(model.Time(T).String())
+	s.WriteMore()
+	s.WriteObjectStart()
+	s.WriteObjectField("count")
+	s.WriteString(mh.Count.String())
+	s.WriteMore()
+	s.WriteObjectField("sum")
+	s.WriteString(mh.Sum.String())
+	s.WriteMore()
+	s.WriteObjectField("buckets")
+	s.WriteArrayStart()
+	for i, b := range mh.Buckets {
+		if i > 0 {
+			s.WriteMore()
+		}
+		s.WriteArrayStart()
+		s.WriteInt32(b.Boundaries)
+		s.WriteMore()
+		s.WriteString(b.Lower.String())
+		s.WriteMore()
+		s.WriteString(b.Upper.String())
+		s.WriteMore()
+		s.WriteString(b.Count.String())
+		s.WriteArrayEnd()
+	}
+	s.WriteArrayEnd()
+	s.WriteObjectEnd()
+	s.WriteArrayEnd()
+}
+
+func encodeTable(t logqlmodel.Table, s *jsoniter.Stream) {
+	s.WriteObjectStart()
+	defer s.WriteObjectEnd()
+
+	s.WriteObjectField("columns")
+	encodeStrings(t.Columns, s)
+
+	s.WriteMore()
+	s.WriteObjectField("rows")
+	s.WriteArrayStart()
+	for i, row := range t.Rows {
+		if i > 0 {
+			s.WriteMore()
+		}
+		encodeStrings(row, s)
+	}
+	s.WriteArrayEnd()
+}
+
+func encodeStrings(values []string, s *jsoniter.Stream) {
 	s.WriteArrayStart()
-	for i, p := range stream.Floats {
+	for i, v := range values {
 		if i > 0 {
 			s.WriteMore()
 		}
-		encodeValue(p.T, p.F, s)
+		s.WriteString(v)
 	}
 	s.WriteArrayEnd()
 }
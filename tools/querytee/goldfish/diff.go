@@ -0,0 +1,49 @@
+package goldfish
+
+import (
+	"net/http"
+	"time"
+)
+
+// MaxDiffReportDifferences is the maximum number of differences listed by a
+// diff report, to keep the stored reports small.
+const MaxDiffReportDifferences = 100
+
+// ResultComparator compares the results of both cells when their hashes
+// differ, tolerating the differences allowed by its comparison policies.
+type ResultComparator interface {
+	// DiffResults returns the differences between the results of the cells, or
+	// nil when they are equivalent.
+	DiffResults(req *http.Request, cellA, cellB []byte, evaluationTime time.Time) (*DiffReport, error)
+}
+
+// DiffReport lists the differences between the results of both cells.
+type DiffReport struct {
+	Summary string `json:"summary"`
+	// Total is the number of differences, of which at most
+	// MaxDiffReportDifferences are listed.
+	Total       int         `json:"total"`
+	Differences []FieldDiff `json:"differences"`
+}
+
+// Add adds the difference to the report.
+func (r *DiffReport) Add(d FieldDiff) {
+	r.Total++
+	if len(r.Differences) < MaxDiffReportDifferences {
+		r.Differences = append(r.Differences, d)
+	}
+}
+
+// FieldDiff is a difference between the results of both cells.
+type FieldDiff struct {
+	// Series holds the labels of the stream or series, if any.
+	Series string `json:"series,omitempty"`
+	// Field is the differing field, e.g. stream, entries, timestamp or line.
+	Field string `json:"field"`
+	// Timestamp is the timestamp of the differing entry in nanoseconds, if
+	// any.
+	Timestamp int64  `json:"timestamp,omitempty"`
+	CellA     any    `json:"cell_a,omitempty"`
+	CellB     any    `json:"cell_b,omitempty"`
+	Message   string `json:"message,omitempty"`
+}
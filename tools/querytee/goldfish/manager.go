@@ -3,6 +3,7 @@ package goldfish
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
@@ -30,12 +31,13 @@ const (
 // Manager coordinates Goldfish sampling and comparison operations.
 // It handles query sampling decisions, response comparison, persistence, and storage of results.
 type Manager struct {
-	config      Config
-	sampler     *Sampler
-	storage     goldfish.Storage
-	resultStore ResultStore
-	logger      log.Logger
-	metrics     *metrics
+	config           Config
+	sampler          *Sampler
+	storage          goldfish.Storage
+	resultStore      ResultStore
+	resultComparator ResultComparator
+	logger           log.Logger
+	metrics          *metrics
 }
 
 type metrics struct {
@@ -87,6 +89,13 @@ func NewManager(config Config, storage goldfish.Storage, resultStore ResultStore
 	return m, nil
 }
 
+// WithResultComparator sets the comparator of the results of both cells when
+// their hashes differ. Without it, any difference of content is a mismatch.
+func (m *Manager) WithResultComparator(c ResultComparator) *Manager {
+	m.resultComparator = c
+	return m
+}
+
 // ShouldSample determines if a query should be sampled based on tenant configuration.
 // Returns false if Goldfish is disabled or if the tenant should not be sampled.
 func (m *Manager) ShouldSample(tenantID string) bool {
@@ -160,6 +169,7 @@ func (m *Manager) ProcessQueryPair(ctx context.Context, req *http.Request, cellA
 
 	comparisonStart := time.Now()
 	result := CompareResponses(sample, m.config.PerformanceTolerance)
+	diff := m.diffResults(req, sample, cellAResp, cellBResp, &result)
 	m.metrics.comparisonDuration.Observe(time.Since(comparisonStart).Seconds())
 	m.metrics.comparisonResults.WithLabelValues(string(result.ComparisonStatus)).Inc()
 
@@ -167,6 +177,11 @@ func (m *Manager) ProcessQueryPair(ctx context.Context, req *http.Request, cellA
 	var persistedA, persistedB *StoredResult
 	if m.resultStore != nil {
 		persistedA, persistedB = m.persistResultPayloads(ctx, sample, cellAResp, cellBResp, result)
+		if diff != nil && m.shouldPersistResults(result) {
+			if stored := m.persistDiffReport(ctx, sample, diff); stored != nil {
+				result.DifferenceDetails["diff_report_uri"] = stored.URI
+			}
+		}
 		if persistedA != nil {
 			sample.CellAResultURI = persistedA.URI
 			sample.CellAResultSize = persistedA.Size
@@ -236,7 +251,7 @@ func (m *Manager) ProcessQueryPair(ctx context.Context, req *http.Request, cellA
 		contentDiffs := 0
 		for key := range result.DifferenceDetails {
 			switch key {
-			case "content_hash", "status_code", "entries_returned", "bytes_processed", "lines_processed":
+			case "content_hash", "content_diff", "status_code", "entries_returned", "bytes_processed", "lines_processed":
 				contentDiffs++
 			case "exec_time_variance":
 				perfDiffs++
@@ -319,6 +334,64 @@ func (m *Manager) persistResultPayloads(ctx context.Context, sample *goldfish.Qu
 	return storedA, storedB
 }
 
+// diffResults compares the results of the cells with the result comparator
+// when their hashes differ. The comparison is a match when the differences are
+// tolerated, otherwise the differences are returned.
+func (m *Manager) diffResults(req *http.Request, sample *goldfish.QuerySample, cellAResp, cellBResp *ResponseData, result *goldfish.ComparisonResult) *DiffReport {
+	if m.resultComparator == nil {
+		return nil
+	}
+	if _, ok := result.DifferenceDetails["content_hash"]; !ok {
+		return nil
+	}
+
+	diff, err := m.resultComparator.DiffResults(req, cellAResp.Body, cellBResp.Body, time.Now())
+	if err != nil {
+		level.Warn(m.logger).Log("msg", "failed to diff query results", "correlation_id", sample.CorrelationID, "err", err)
+		return nil
+	}
+
+	if diff == nil {
+		// The content differences are tolerated by the comparison policies.
+		result.ComparisonStatus = goldfish.ComparisonStatusMatch
+		result.DifferenceDetails["tolerated_content_hash"] = result.DifferenceDetails["content_hash"]
+		delete(result.DifferenceDetails, "content_hash")
+		compareQueryStats(sample.CellAStats, sample.CellBStats, result, m.config.PerformanceTolerance)
+		return nil
+	}
+
+	result.DifferenceDetails["content_diff"] = map[string]any{
+		"summary":     diff.Summary,
+		"differences": diff.Total,
+	}
+	return diff
+}
+
+func (m *Manager) persistDiffReport(ctx context.Context, sample *goldfish.QuerySample, diff *DiffReport) *StoredResult {
+	payload, err := json.Marshal(diff)
+	if err != nil {
+		level.Error(m.logger).Log("msg", "failed to encode diff report", "correlation_id", sample.CorrelationID, "err", err)
+		return nil
+	}
+
+	stored, err := m.resultStore.Store(ctx, payload, StoreOptions{
+		CorrelationID: sample.CorrelationID,
+		CellLabel:     "diff",
+		TenantID:      sample.TenantID,
+		QueryType:     sample.QueryType,
+		Timestamp:     sample.SampledAt,
+		ContentType:   "application/json",
+	})
+	if err != nil {
+		level.Error(m.logger).Log("msg", "failed to persist diff report", "correlation_id", sample.CorrelationID, "err", err)
+		m.metrics.storageOperations.WithLabelValues("store_diff", "error").Inc()
+		return nil
+	}
+
+	m.metrics.storageOperations.WithLabelValues("store_diff", "success").Inc()
+	return stored
+}
+
 func (m *Manager) shouldPersistResults(result goldfish.ComparisonResult) bool {
 	if m.resultStore == nil {
 		return false
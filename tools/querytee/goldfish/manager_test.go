@@ -620,3 +620,88 @@ func (m *mockResultStore) Close(context.Context) error {
 	m.closed = true
 	return nil
 }
+
+type mockResultComparator struct {
+	diff *DiffReport
+}
+
+func (m *mockResultComparator) DiffResults(_ *http.Request, _, _ []byte, _ time.Time) (*DiffReport, error) {
+	return m.diff, nil
+}
+
+func TestManagerResultComparator(t *testing.T) {
+	config := Config{
+		Enabled: true,
+		SamplingConfig: SamplingConfig{
+			DefaultRate: 1.0,
+		},
+		StorageConfig: StorageConfig{
+			Type:             "cloudsql",
+			CloudSQLUser:     "user",
+			CloudSQLDatabase: "db",
+		},
+		ResultsStorage: ResultsStorageConfig{
+			Enabled:     true,
+			Mode:        ResultsPersistenceModeMismatchOnly,
+			Backend:     ResultsBackendGCS,
+			Compression: ResultsCompressionGzip,
+		},
+	}
+	config.ResultsStorage.Bucket.GCS.BucketName = "bucket"
+
+	tests := []struct {
+		name           string
+		diff           *DiffReport
+		expectedStatus goldfish.ComparisonStatus
+		expectedCells  []string
+	}{
+		{
+			name:           "tolerated differences",
+			expectedStatus: goldfish.ComparisonStatusMatch,
+		},
+		{
+			name: "differences",
+			diff: &DiffReport{
+				Summary:     "1 differences between 1 streams of cell A and 1 streams of cell B",
+				Total:       1,
+				Differences: []FieldDiff{{Series: `{app="api"}`, Field: "line", Timestamp: 1, CellA: "a", CellB: "b"}},
+			},
+			expectedStatus: goldfish.ComparisonStatusMismatch,
+			expectedCells:  []string{"cell-a", "cell-b", "diff"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			storage := &mockStorage{}
+			results := &mockResultStore{}
+			manager, err := NewManager(config, storage, results, log.NewNopLogger(), prometheus.NewRegistry())
+			require.NoError(t, err)
+			manager.WithResultComparator(&mockResultComparator{diff: tt.diff})
+
+			req, _ := http.NewRequest("GET", "/loki/api/v1/query_range?query={app=\"api\"}", nil)
+			cellA := &ResponseData{Body: []byte(`{}`), StatusCode: 200, Hash: "hash-a", BackendName: "cell-a"}
+			cellB := &ResponseData{Body: []byte(`{}`), StatusCode: 200, Hash: "hash-b", BackendName: "cell-b"}
+
+			manager.ProcessQueryPair(context.Background(), req, cellA, cellB)
+
+			require.Len(t, storage.results, 1)
+			result := storage.results[0]
+			assert.Equal(t, tt.expectedStatus, result.ComparisonStatus)
+
+			var cells []string
+			for _, call := range results.calls {
+				cells = append(cells, call.opts.CellLabel)
+			}
+			assert.Equal(t, tt.expectedCells, cells)
+
+			if tt.diff == nil {
+				assert.NotContains(t, result.DifferenceDetails, "content_hash")
+				assert.Contains(t, result.DifferenceDetails, "tolerated_content_hash")
+				return
+			}
+			assert.Equal(t, map[string]any{"summary": tt.diff.Summary, "differences": 1}, result.DifferenceDetails["content_diff"])
+			assert.Equal(t, "mock://diff/"+result.CorrelationID, result.DifferenceDetails["diff_report_uri"])
+		})
+	}
+}
@@ -41,7 +41,9 @@ type ProxyConfig struct {
 	SkipSamplesBefore              flagext.Time
 	RequestURLFilter               *regexp.Regexp
 	InstrumentCompares             bool
+	StreamComparisonPolicies       StreamComparisonPolicies
 	Goldfish                       goldfish.Config
+	Replay                         ReplayConfig
 }
 
 func (cfg *ProxyConfig) RegisterFlags(f *flag.FlagSet) {
@@ -62,9 +64,27 @@ func (cfg *ProxyConfig) RegisterFlags(f *flag.FlagSet) {
 		return err
 	})
 	f.BoolVar(&cfg.InstrumentCompares, "proxy.compare-instrument", false, "Reports metrics on comparisons of responses between preferred and non-preferred endpoints for supported routes.")
+	f.BoolVar(&cfg.StreamComparisonPolicies.IgnoreOrderWithinTimestamp, "proxy.compare-streams-ignore-order-within-timestamp", false, "Ignore the order of the log entries of a stream with the same timestamp when comparing responses.")
+	f.BoolVar(&cfg.StreamComparisonPolicies.LimitBoundaryTolerance, "proxy.compare-streams-limit-boundary-tolerance", false, "Ignore the log entries at the oldest (newest for forward queries) timestamp of responses which both reached the limit of the query, as the entries picked at that timestamp can differ.")
+	f.BoolVar(&cfg.StreamComparisonPolicies.NormalizeLabels, "proxy.compare-streams-normalize-labels", false, "Compare log entries by their stream labels merged with their structured metadata and parsed labels, so that responses with and without categorized labels are the same.")
 
 	// Register Goldfish configuration flags
 	cfg.Goldfish.RegisterFlags(f)
+
+	// Register replay configuration flags
+	cfg.Replay.RegisterFlags(f)
+}
+
+// SampleComparisonOptions returns the options of the comparison of the
+// responses.
+func (cfg *ProxyConfig) SampleComparisonOptions() SampleComparisonOptions {
+	return SampleComparisonOptions{
+		Tolerance:         cfg.ValueComparisonTolerance,
+		UseRelativeError:  cfg.UseRelativeError,
+		SkipRecentSamples: cfg.SkipRecentSamples,
+		SkipSamplesBefore: time.Time(cfg.SkipSamplesBefore),
+		StreamPolicies:    cfg.StreamComparisonPolicies,
+	}
 }
 
 type Route struct {
@@ -212,7 +232,8 @@ func NewProxy(cfg ProxyConfig, logger log.Logger, readRoutes, writeRoutes []Rout
 			storage.Close()
 			return nil, errors.Wrap(err, "failed to create goldfish manager")
 		}
-		p.goldfishManager = goldfishManager
+		// Tolerate the differences of content allowed by the comparison policies.
+		p.goldfishManager = goldfishManager.WithResultComparator(NewSamplesComparator(cfg.SampleComparisonOptions()))
 
 		level.Info(logger).Log("msg", "Goldfish enabled",
 			"storage_type", cfg.Goldfish.StorageConfig.Type,
@@ -239,19 +260,7 @@ func (p *Proxy) Start() error {
 	}))
 
 	// register read routes
-	for _, route := range p.readRoutes {
-		var comparator ResponsesComparator
-		if p.cfg.CompareResponses {
-			comparator = route.ResponseComparator
-		}
-		endpoint := NewProxyEndpoint(filterReadDisabledBackends(p.backends, p.cfg.DisableBackendReadProxy), route.RouteName, p.metrics, p.logger, comparator, p.cfg.InstrumentCompares)
-		// Add Goldfish if configured
-		if p.goldfishManager != nil {
-			endpoint.WithGoldfish(p.goldfishManager)
-			level.Info(p.logger).Log("msg", "Goldfish attached to route", "path", route.Path, "methods", strings.Join(route.Methods, ","))
-		}
-		router.Path(route.Path).Methods(route.Methods...).Handler(endpoint)
-	}
+	p.registerReadRoutes(router)
 
 	for _, route := range p.writeRoutes {
 		router.Path(route.Path).Methods(route.Methods...).Handler(NewProxyEndpoint(p.backends, route.RouteName, p.metrics, p.logger, nil, p.cfg.InstrumentCompares))
@@ -295,11 +304,28 @@ func (p *Proxy) Start() error {
 	return nil
 }
 
-func (p *Proxy) Stop() error {
-	if p.srv == nil {
-		return nil
+// registerReadRoutes registers the read routes to the router, and returns
+// their endpoints.
+func (p *Proxy) registerReadRoutes(router *mux.Router) []*ProxyEndpoint {
+	endpoints := make([]*ProxyEndpoint, 0, len(p.readRoutes))
+	for _, route := range p.readRoutes {
+		var comparator ResponsesComparator
+		if p.cfg.CompareResponses {
+			comparator = route.ResponseComparator
+		}
+		endpoint := NewProxyEndpoint(filterReadDisabledBackends(p.backends, p.cfg.DisableBackendReadProxy), route.RouteName, p.metrics, p.logger, comparator, p.cfg.InstrumentCompares)
+		// Add Goldfish if configured
+		if p.goldfishManager != nil {
+			endpoint.WithGoldfish(p.goldfishManager)
+			level.Info(p.logger).Log("msg", "Goldfish attached to route", "path", route.Path, "methods", strings.Join(route.Methods, ","))
+		}
+		router.Path(route.Path).Methods(route.Methods...).Handler(endpoint)
+		endpoints = append(endpoints, endpoint)
 	}
+	return endpoints
+}
 
+func (p *Proxy) Stop() error {
 	// Close Goldfish manager if it exists
 	if p.goldfishManager != nil {
 		if err := p.goldfishManager.Close(); err != nil {
@@ -307,6 +333,10 @@ func (p *Proxy) Stop() error {
 		}
 	}
 
+	if p.srv == nil {
+		return nil
+	}
+
 	return p.srv.Shutdown(context.Background())
 }
 
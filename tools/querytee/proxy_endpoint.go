@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"strconv"
 	"strings"
 	"sync"
@@ -20,6 +21,12 @@ type ResponsesComparator interface {
 	Compare(expected, actual []byte, queryEvaluationTime time.Time) (*ComparisonSummary, error)
 }
 
+// QueryResponsesComparator is a ResponsesComparator which takes the parameters
+// of the query into account, e.g. the limit of log queries.
+type QueryResponsesComparator interface {
+	CompareQuery(params url.Values, expected, actual []byte, queryEvaluationTime time.Time) (*ComparisonSummary, error)
+}
+
 type ComparisonSummary struct {
 	skipped        bool
 	missingMetrics int
@@ -41,6 +48,10 @@ type ProxyEndpoint struct {
 
 	// Goldfish manager for query sampling and comparison
 	goldfishManager *goldfish.Manager
+
+	// Tracks the requests still being sent to the backends, compared or
+	// processed by Goldfish after the response was sent back to the client.
+	inflight sync.WaitGroup
 }
 
 func NewProxyEndpoint(backends []*ProxyBackend, routeName string, metrics *ProxyMetrics, logger log.Logger, comparator ResponsesComparator, instrumentCompares bool) *ProxyEndpoint {
@@ -82,7 +93,11 @@ func (p *ProxyEndpoint) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 
 	// Send the same request to all backends.
 	resCh := make(chan *BackendResponse, len(p.backends))
-	go p.executeBackendRequests(r, resCh, shouldSample)
+	p.inflight.Add(1)
+	go func() {
+		defer p.inflight.Done()
+		p.executeBackendRequests(r, resCh, shouldSample)
+	}()
 
 	// Wait for the first response that's feasible to be sent back to the client.
 	downstreamRes := p.waitBackendResponseForDownstream(resCh)
@@ -99,6 +114,12 @@ func (p *ProxyEndpoint) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	p.metrics.responsesTotal.WithLabelValues(downstreamRes.backend.name, r.Method, p.routeName, detectIssuer(r)).Inc()
 }
 
+// Wait waits until the requests received by the endpoint have been sent to all
+// the backends, and their responses compared.
+func (p *ProxyEndpoint) Wait() {
+	p.inflight.Wait()
+}
+
 func (p *ProxyEndpoint) executeBackendRequests(r *http.Request, resCh chan *BackendResponse, goldfishSample bool) {
 	var (
 		wg                  = sync.WaitGroup{}
@@ -188,7 +209,7 @@ func (p *ProxyEndpoint) executeBackendRequests(r *http.Request, resCh chan *Back
 			actualResponse := responses[i]
 
 			result := comparisonSuccess
-			summary, err := p.compareResponses(expectedResponse, actualResponse, time.Now().UTC())
+			summary, err := p.compareResponses(r, expectedResponse, actualResponse, time.Now().UTC())
 			if err != nil {
 				level.Error(p.logger).Log("msg", "response comparison failed",
 					"backend-name", p.backends[i].name,
@@ -235,7 +256,11 @@ func (p *ProxyEndpoint) executeBackendRequests(r *http.Request, resCh chan *Back
 				"cellA_status", cellAResp.status,
 				"cellB_backend", cellBResp.backend.name,
 				"cellB_status", cellBResp.status)
-			go p.processWithGoldfish(r, cellAResp, cellBResp)
+			p.inflight.Add(1)
+			go func() {
+				defer p.inflight.Done()
+				p.processWithGoldfish(r, cellAResp, cellBResp)
+			}()
 		}
 	}
 }
@@ -275,7 +300,7 @@ func (p *ProxyEndpoint) waitBackendResponseForDownstream(resCh chan *BackendResp
 	return responses[0]
 }
 
-func (p *ProxyEndpoint) compareResponses(expectedResponse, actualResponse *BackendResponse, queryEvalTime time.Time) (*ComparisonSummary, error) {
+func (p *ProxyEndpoint) compareResponses(r *http.Request, expectedResponse, actualResponse *BackendResponse, queryEvalTime time.Time) (*ComparisonSummary, error) {
 	if expectedResponse.err != nil {
 		return &ComparisonSummary{skipped: true}, nil
 	}
@@ -297,6 +322,14 @@ func (p *ProxyEndpoint) compareResponses(expectedResponse, actualResponse *Backe
 		return nil, fmt.Errorf("expected status code %d but got %d", expectedResponse.status, actualResponse.status)
 	}
 
+	if comparator, ok := p.comparator.(QueryResponsesComparator); ok {
+		params := r.URL.Query()
+		if r.Form != nil {
+			params = r.Form
+		}
+		return comparator.CompareQuery(params, expectedResponse.body, actualResponse.body, queryEvalTime)
+	}
+
 	return p.comparator.Compare(expectedResponse.body, actualResponse.body, queryEvalTime)
 }
 
@@ -331,6 +364,9 @@ func detectIssuer(r *http.Request) string {
 	if strings.HasPrefix(r.Header.Get("User-Agent"), "loki-canary") {
 		return canaryIssuer
 	}
+	if strings.HasPrefix(r.Header.Get("User-Agent"), replayUserAgent) {
+		return replayIssuer
+	}
 	return unknownIssuer
 }
 
@@ -12,6 +12,7 @@ const (
 
 	unknownIssuer = "unknown"
 	canaryIssuer  = "loki-canary"
+	replayIssuer  = "replay"
 )
 
 type ProxyMetrics struct {
@@ -0,0 +1,312 @@
+package querytee
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-kit/log/level"
+	"github.com/go-logfmt/logfmt"
+	"github.com/gorilla/mux"
+	"github.com/pkg/errors"
+	"golang.org/x/time/rate"
+)
+
+const (
+	replayUserAgent = "loki-querytee-replay"
+
+	// maxReplayLineSize is the size of the longest line of the capture files,
+	// which can hold long queries.
+	maxReplayLineSize = 1 << 20
+)
+
+type ReplayConfig struct {
+	File        string
+	Rate        float64
+	Concurrency int
+	TimeShift   time.Duration
+}
+
+func (cfg *ReplayConfig) RegisterFlags(f *flag.FlagSet) {
+	f.StringVar(&cfg.File, "replay.file", "", "Replay the queries of the file against the backends instead of proxying live traffic, then exit. The file holds either Loki query logs (the metrics.go or \"executing query\" lines) or JSON queries, one per line.")
+	f.Float64Var(&cfg.Rate, "replay.rate", 1, "The number of queries replayed per second. 0 to replay them as fast as possible.")
+	f.IntVar(&cfg.Concurrency, "replay.concurrency", 1, "The maximum number of queries replayed at the same time.")
+	f.DurationVar(&cfg.TimeShift, "replay.time-shift", 0, "The duration added to the time ranges of the replayed queries, e.g. 24h to query today's logs with the queries recorded yesterday.")
+}
+
+// ReplayQuery is a recorded query. Queries with a time, or with the same start
+// and end, are instant queries.
+type ReplayQuery struct {
+	Tenant    string    `json:"tenant,omitempty"`
+	Path      string    `json:"path,omitempty"`
+	Query     string    `json:"query"`
+	Start     time.Time `json:"start,omitempty"`
+	End       time.Time `json:"end,omitempty"`
+	Time      time.Time `json:"time,omitempty"`
+	Step      string    `json:"step,omitempty"`
+	Limit     int       `json:"limit,omitempty"`
+	Direction string    `json:"direction,omitempty"`
+}
+
+func (q *ReplayQuery) instant() bool {
+	return !q.Time.IsZero() || q.Start.Equal(q.End)
+}
+
+// request returns the request of the query, with its time range shifted.
+func (q *ReplayQuery) request(ctx context.Context, shift time.Duration) (*http.Request, error) {
+	params := url.Values{}
+	params.Set("query", q.Query)
+	if q.Limit > 0 {
+		params.Set("limit", strconv.Itoa(q.Limit))
+	}
+	if q.Direction != "" {
+		params.Set("direction", q.Direction)
+	}
+
+	path := q.Path
+	if q.instant() {
+		ts := q.Time
+		if ts.IsZero() {
+			ts = q.End
+		}
+		params.Set("time", formatReplayTime(ts.Add(shift)))
+		if path == "" {
+			path = "/loki/api/v1/query"
+		}
+	} else {
+		params.Set("start", formatReplayTime(q.Start.Add(shift)))
+		params.Set("end", formatReplayTime(q.End.Add(shift)))
+		if q.Step != "" {
+			params.Set("step", q.Step)
+		}
+		if path == "" {
+			path = "/loki/api/v1/query_range"
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, path+"?"+params.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	if q.Tenant != "" {
+		req.Header.Set("X-Scope-OrgID", q.Tenant)
+	}
+	req.Header.Set("User-Agent", replayUserAgent)
+	return req, nil
+}
+
+func formatReplayTime(t time.Time) string {
+	return strconv.FormatInt(t.UnixNano(), 10)
+}
+
+// parseReplayLine parses a line of a capture file, which is either a JSON
+// query or a Loki query log line. It returns false for the lines which aren't
+// queries.
+func parseReplayLine(line []byte) (ReplayQuery, bool, error) {
+	line = bytes.TrimSpace(line)
+	if len(line) == 0 {
+		return ReplayQuery{}, false, nil
+	}
+	if line[0] == '{' {
+		var q ReplayQuery
+		if err := json.Unmarshal(line, &q); err != nil {
+			return ReplayQuery{}, false, err
+		}
+		if q.Query == "" {
+			return ReplayQuery{}, false, errors.New("missing query")
+		}
+		if q.Time.IsZero() && (q.Start.IsZero() || q.End.IsZero()) {
+			return ReplayQuery{}, false, errors.New("missing time, or start and end")
+		}
+		return q, true, nil
+	}
+	return parseQueryLogLine(line)
+}
+
+// parseQueryLogLine parses the query of a logfmt line logged by Loki when
+// executing a query. The time range of the query is computed from the time of
+// the line and the start and end deltas, or the length of the query.
+func parseQueryLogLine(line []byte) (ReplayQuery, bool, error) {
+	fields := map[string]string{}
+	dec := logfmt.NewDecoder(bytes.NewReader(line))
+	for dec.ScanRecord() {
+		for dec.ScanKeyval() {
+			fields[string(dec.Key())] = string(dec.Value())
+		}
+	}
+	if err := dec.Err(); err != nil {
+		return ReplayQuery{}, false, err
+	}
+
+	if msg, ok := fields["msg"]; ok && msg != "executing query" {
+		return ReplayQuery{}, false, nil
+	}
+	switch fields["query_type"] {
+	case "", "metric", "filter", "limited":
+	default:
+		// Labels, series, stats or volume queries.
+		return ReplayQuery{}, false, nil
+	}
+	if fields["query"] == "" {
+		return ReplayQuery{}, false, nil
+	}
+
+	ts, err := time.Parse(time.RFC3339Nano, fields["ts"])
+	if err != nil {
+		return ReplayQuery{}, false, errors.Wrap(err, "invalid ts")
+	}
+	q := ReplayQuery{
+		Tenant: fields["org_id"],
+		Query:  fields["query"],
+		Step:   fields["step"],
+	}
+	if limit, ok := fields["limit"]; ok {
+		if q.Limit, err = strconv.Atoi(limit); err != nil {
+			return ReplayQuery{}, false, errors.Wrap(err, "invalid limit")
+		}
+	}
+
+	delta := func(key string) (time.Duration, bool, error) {
+		v, ok := fields[key]
+		if !ok {
+			return 0, false, nil
+		}
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return 0, false, errors.Wrapf(err, "invalid %s", key)
+		}
+		return d, true, nil
+	}
+
+	endDelta, _, err := delta("end_delta")
+	if err != nil {
+		return ReplayQuery{}, false, err
+	}
+	q.End = ts.Add(-endDelta)
+
+	rangeType := fields["range_type"]
+	if rangeType == "" {
+		rangeType = fields["type"]
+	}
+	if rangeType == "instant" {
+		q.Time = q.End
+		return q, true, nil
+	}
+
+	startDelta, ok, err := delta("start_delta")
+	if err != nil {
+		return ReplayQuery{}, false, err
+	}
+	if ok {
+		q.Start = ts.Add(-startDelta)
+		return q, true, nil
+	}
+	length, ok, err := delta("length")
+	if err != nil {
+		return ReplayQuery{}, false, err
+	}
+	if !ok {
+		return ReplayQuery{}, false, errors.New("missing start_delta or length")
+	}
+	q.Start = q.End.Add(-length)
+	return q, true, nil
+}
+
+// ReplayStats are the numbers of queries of a replay.
+type ReplayStats struct {
+	Replayed int
+	Failed   int
+	Skipped  int
+}
+
+// Replay sends the queries read from r to the backends of the read routes, at
+// the configured rate, as if they were received by the proxy. The responses
+// are compared and sampled by Goldfish the same way as the live traffic.
+func (p *Proxy) Replay(ctx context.Context, r io.Reader) (ReplayStats, error) {
+	router := mux.NewRouter()
+	endpoints := p.registerReadRoutes(router)
+
+	limit := rate.Inf
+	if p.cfg.Replay.Rate > 0 {
+		limit = rate.Limit(p.cfg.Replay.Rate)
+	}
+	limiter := rate.NewLimiter(limit, 1)
+
+	var (
+		stats ReplayStats
+		mtx   sync.Mutex
+		wg    sync.WaitGroup
+		sem   = make(chan struct{}, max(p.cfg.Replay.Concurrency, 1))
+	)
+	replay := func(q ReplayQuery) {
+		defer func() {
+			<-sem
+			wg.Done()
+		}()
+
+		req, err := q.request(ctx, p.cfg.Replay.TimeShift)
+		if err != nil {
+			level.Warn(p.logger).Log("msg", "Unable to create replay request", "query", q.Query, "err", err)
+			mtx.Lock()
+			stats.Failed++
+			mtx.Unlock()
+			return
+		}
+
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		mtx.Lock()
+		defer mtx.Unlock()
+		stats.Replayed++
+		if rec.Code/100 != 2 {
+			stats.Failed++
+			level.Warn(p.logger).Log("msg", "Replayed query failed", "path", req.URL.Path, "query", q.Query, "tenant", q.Tenant, "status", rec.Code)
+		}
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxReplayLineSize)
+	var err error
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		q, ok, parseErr := parseReplayLine(scanner.Bytes())
+		if parseErr != nil {
+			level.Warn(p.logger).Log("msg", "Skipping invalid replay line", "line", lineNum, "err", parseErr)
+			stats.Skipped++
+			continue
+		}
+		if !ok {
+			continue
+		}
+
+		if err = limiter.Wait(ctx); err != nil {
+			break
+		}
+		sem <- struct{}{}
+		wg.Add(1)
+		go replay(q)
+	}
+	if err == nil {
+		err = scanner.Err()
+	}
+
+	wg.Wait()
+	for _, e := range endpoints {
+		e.Wait()
+	}
+
+	if err != nil {
+		return stats, fmt.Errorf("replay stopped: %w", err)
+	}
+	return stats, nil
+}
@@ -0,0 +1,194 @@
+package querytee
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/atomic"
+)
+
+func Test_parseReplayLine(t *testing.T) {
+	ts := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+
+	tests := map[string]struct {
+		line     string
+		expected ReplayQuery
+		ok       bool
+		err      bool
+	}{
+		"metrics.go range query": {
+			line: `level=info ts=2024-05-01T10:00:00Z caller=metrics.go:237 component=frontend org_id=tenant-a latency=fast query="sum(rate({app=\"api\"}[1m]))" query_hash=123 query_type=metric range_type=range length=1h0m0s start_delta=1h0m1s end_delta=1s step=14s duration=1.2s status=200 limit=100`,
+			expected: ReplayQuery{
+				Tenant: "tenant-a",
+				Query:  `sum(rate({app="api"}[1m]))`,
+				Start:  ts.Add(-time.Hour - time.Second),
+				End:    ts.Add(-time.Second),
+				Step:   "14s",
+				Limit:  100,
+			},
+			ok: true,
+		},
+		"metrics.go instant query": {
+			line: `level=info ts=2024-05-01T10:00:00Z caller=metrics.go:237 org_id=tenant-a query="count_over_time({app=\"api\"}[5m])" query_type=metric range_type=instant length=0s start_delta=2s end_delta=2s step=0s limit=100`,
+			expected: ReplayQuery{
+				Tenant: "tenant-a",
+				Query:  `count_over_time({app="api"}[5m])`,
+				End:    ts.Add(-2 * time.Second),
+				Time:   ts.Add(-2 * time.Second),
+				Step:   "0s",
+				Limit:  100,
+			},
+			ok: true,
+		},
+		"executing query line": {
+			line: `level=info ts=2024-05-01T10:00:00Z caller=roundtrip.go:410 org_id=tenant-b msg="executing query" query="{app=\"api\"} |= \"error\"" type=range length=30m0s step=7s`,
+			expected: ReplayQuery{
+				Tenant: "tenant-b",
+				Query:  `{app="api"} |= "error"`,
+				Start:  ts.Add(-30 * time.Minute),
+				End:    ts,
+				Step:   "7s",
+			},
+			ok: true,
+		},
+		"json query": {
+			line: `{"tenant":"tenant-c","query":"{app=\"api\"}","start":"2024-05-01T09:00:00Z","end":"2024-05-01T10:00:00Z","limit":10,"direction":"forward"}`,
+			expected: ReplayQuery{
+				Tenant:    "tenant-c",
+				Query:     `{app="api"}`,
+				Start:     ts.Add(-time.Hour),
+				End:       ts,
+				Limit:     10,
+				Direction: "forward",
+			},
+			ok: true,
+		},
+		"labels query": {
+			line: `level=info ts=2024-05-01T10:00:00Z caller=metrics.go:237 query="{app=\"api\"}" query_type=labels length=1h0m0s`,
+		},
+		"other log line": {
+			line: `level=info ts=2024-05-01T10:00:00Z caller=engine.go:100 msg="query done" query="{app=\"api\"}"`,
+		},
+		"empty line": {},
+		"missing time range": {
+			line: `level=info ts=2024-05-01T10:00:00Z caller=metrics.go:237 query="{app=\"api\"}" range_type=range`,
+			err:  true,
+		},
+		"json without time": {
+			line: `{"query":"{app=\"api\"}"}`,
+			err:  true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			q, ok, err := parseReplayLine([]byte(tc.line))
+			if tc.err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.ok, ok)
+			if !tc.ok {
+				return
+			}
+			assert.Equal(t, tc.expected.Tenant, q.Tenant)
+			assert.Equal(t, tc.expected.Query, q.Query)
+			assert.True(t, tc.expected.Start.Equal(q.Start), "start %s", q.Start)
+			assert.True(t, tc.expected.End.Equal(q.End), "end %s", q.End)
+			assert.True(t, tc.expected.Time.Equal(q.Time), "time %s", q.Time)
+			assert.Equal(t, tc.expected.Step, q.Step)
+			assert.Equal(t, tc.expected.Limit, q.Limit)
+			assert.Equal(t, tc.expected.Direction, q.Direction)
+		})
+	}
+}
+
+type countingComparator struct {
+	compared atomic.Int32
+}
+
+func (c *countingComparator) Compare(_, _ []byte, _ time.Time) (*ComparisonSummary, error) {
+	c.compared.Inc()
+	return nil, nil
+}
+
+func TestProxy_Replay(t *testing.T) {
+	var (
+		mtx      sync.Mutex
+		requests = map[string][]*http.Request{}
+	)
+	backend := func(name string) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mtx.Lock()
+			requests[name] = append(requests[name], r)
+			mtx.Unlock()
+			_, _ = w.Write([]byte(`{"status":"success","data":{"resultType":"streams","result":[]}}`))
+		}))
+	}
+	backendA, backendB := backend("a"), backend("b")
+	defer backendA.Close()
+	defer backendB.Close()
+
+	comparator := &countingComparator{}
+	routes := []Route{
+		{Path: "/loki/api/v1/query_range", RouteName: "api_v1_query_range", Methods: []string{"GET"}, ResponseComparator: comparator},
+		{Path: "/loki/api/v1/query", RouteName: "api_v1_query", Methods: []string{"GET"}, ResponseComparator: comparator},
+	}
+
+	cfg := ProxyConfig{
+		BackendEndpoints:   backendA.URL + "," + backendB.URL,
+		PreferredBackend:   "0",
+		BackendReadTimeout: time.Second,
+		CompareResponses:   true,
+		Replay: ReplayConfig{
+			Concurrency: 2,
+			TimeShift:   24 * time.Hour,
+		},
+	}
+	p, err := NewProxy(cfg, log.NewNopLogger(), routes, nil, nil)
+	require.NoError(t, err)
+
+	capture := strings.Join([]string{
+		`level=info ts=2024-05-01T10:00:00Z caller=metrics.go:237 org_id=tenant-a query="sum(rate({app=\"api\"}[1m]))" query_type=metric range_type=range length=1h0m0s start_delta=1h0m0s end_delta=0s step=14s limit=100`,
+		`level=info ts=2024-05-01T10:00:00Z caller=metrics.go:237 query="{app=\"api\"}" query_type=labels`,
+		`not a valid line="`,
+		`{"tenant":"tenant-b","query":"count_over_time({app=\"api\"}[5m])","time":"2024-05-01T10:00:00Z"}`,
+	}, "\n")
+
+	stats, err := p.Replay(context.Background(), strings.NewReader(capture))
+	require.NoError(t, err)
+	require.Equal(t, ReplayStats{Replayed: 2, Skipped: 1}, stats)
+	require.Equal(t, int32(2), comparator.compared.Load())
+
+	end := time.Date(2024, 5, 2, 10, 0, 0, 0, time.UTC)
+	for _, name := range []string{"a", "b"} {
+		require.Len(t, requests[name], 2)
+		byPath := map[string]*http.Request{}
+		for _, r := range requests[name] {
+			byPath[r.URL.Path] = r
+			require.Equal(t, replayUserAgent, r.Header.Get("User-Agent"))
+		}
+
+		rangeReq := byPath["/loki/api/v1/query_range"]
+		require.NotNil(t, rangeReq)
+		require.Equal(t, "tenant-a", rangeReq.Header.Get("X-Scope-OrgID"))
+		require.Equal(t, strconv.FormatInt(end.Add(-time.Hour).UnixNano(), 10), rangeReq.URL.Query().Get("start"))
+		require.Equal(t, strconv.FormatInt(end.UnixNano(), 10), rangeReq.URL.Query().Get("end"))
+		require.Equal(t, "14s", rangeReq.URL.Query().Get("step"))
+
+		instantReq := byPath["/loki/api/v1/query"]
+		require.NotNil(t, instantReq)
+		require.Equal(t, "tenant-b", instantReq.Header.Get("X-Scope-OrgID"))
+		require.Equal(t, strconv.FormatInt(end.UnixNano(), 10), instantReq.URL.Query().Get("time"))
+	}
+}
@@ -4,6 +4,10 @@ import (
 	"encoding/json"
 	"fmt"
 	"math"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -11,9 +15,12 @@ import (
 	jsoniter "github.com/json-iterator/go"
 	"github.com/pkg/errors"
 	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/labels"
 
 	"github.com/grafana/loki/v3/pkg/loghttp"
+	"github.com/grafana/loki/v3/pkg/logproto"
 	util_log "github.com/grafana/loki/v3/pkg/util/log"
+	"github.com/grafana/loki/v3/tools/querytee/goldfish"
 )
 
 // SamplesComparatorFunc helps with comparing different types of samples coming from /api/v1/query and /api/v1/query_range routes.
@@ -32,6 +39,33 @@ type SampleComparisonOptions struct {
 	UseRelativeError  bool
 	SkipRecentSamples time.Duration
 	SkipSamplesBefore time.Time
+
+	// Comparison policies of log streams.
+	StreamPolicies StreamComparisonPolicies
+
+	// The limit and direction of the compared log query, set by CompareQuery.
+	limit     int
+	direction logproto.Direction
+}
+
+// StreamComparisonPolicies are the differences of log streams tolerated when
+// comparing responses, which don't come from correctness issues.
+type StreamComparisonPolicies struct {
+	// IgnoreOrderWithinTimestamp ignores the order of the entries of a stream
+	// with the same timestamp.
+	IgnoreOrderWithinTimestamp bool
+	// LimitBoundaryTolerance ignores the entries at the oldest (or newest, for
+	// forward queries) timestamp of the responses which both reached the limit
+	// of the query, as queries pick different entries at that timestamp.
+	LimitBoundaryTolerance bool
+	// NormalizeLabels compares the entries by their stream labels merged with
+	// their structured metadata and parsed labels, so that results with
+	// categorized labels or not are the same.
+	NormalizeLabels bool
+}
+
+func (p StreamComparisonPolicies) enabled() bool {
+	return p.IgnoreOrderWithinTimestamp || p.LimitBoundaryTolerance || p.NormalizeLabels
 }
 
 func (opts *SampleComparisonOptions) SkipSample(sampleTime, evaluationTime time.Time) bool {
@@ -70,32 +104,97 @@ func (s *SamplesComparator) RegisterSamplesType(samplesType string, comparator S
 }
 
 func (s *SamplesComparator) Compare(expectedResponse, actualResponse []byte, evaluationTime time.Time) (*ComparisonSummary, error) {
+	return s.CompareQuery(nil, expectedResponse, actualResponse, evaluationTime)
+}
+
+// CompareQuery compares the responses of the query with the given parameters,
+// which are used by the stream comparison policies.
+func (s *SamplesComparator) CompareQuery(params url.Values, expectedResponse, actualResponse []byte, evaluationTime time.Time) (*ComparisonSummary, error) {
+	expected, actual, err := unmarshalSamplesResponses(expectedResponse, actualResponse)
+	if err != nil {
+		return nil, err
+	}
+
+	comparator, ok := s.sampleTypesComparator[expected.Data.ResultType]
+	if !ok {
+		return nil, fmt.Errorf("resultType %s not registered for comparison", expected.Data.ResultType)
+	}
+
+	return comparator(expected.Data.Result, actual.Data.Result, evaluationTime, s.opts.withQuery(params))
+}
+
+// DiffResults implements goldfish.ResultComparator. It lists the differences
+// between the log streams of the responses, and reports the first difference
+// of the other result types.
+func (s *SamplesComparator) DiffResults(req *http.Request, cellA, cellB []byte, evaluationTime time.Time) (*goldfish.DiffReport, error) {
+	params := req.URL.Query()
+	if req.Form != nil {
+		params = req.Form
+	}
+	opts := s.opts.withQuery(params)
+
+	expected, actual, err := unmarshalSamplesResponses(cellA, cellB)
+	if err != nil {
+		return &goldfish.DiffReport{Summary: err.Error(), Total: 1}, nil
+	}
+
+	if expected.Data.ResultType != loghttp.ResultTypeStream {
+		comparator, ok := s.sampleTypesComparator[expected.Data.ResultType]
+		if !ok {
+			return nil, fmt.Errorf("resultType %s not registered for comparison", expected.Data.ResultType)
+		}
+		if _, err := comparator(expected.Data.Result, actual.Data.Result, evaluationTime, opts); err != nil {
+			report := &goldfish.DiffReport{Summary: err.Error()}
+			report.Add(goldfish.FieldDiff{Field: "result", Message: err.Error()})
+			return report, nil
+		}
+		return nil, nil
+	}
+
+	expectedStreams, actualStreams, err := unmarshalStreams(expected.Data.Result, actual.Data.Result)
+	if err != nil {
+		return nil, err
+	}
+	expectedStreams, actualStreams = prepareStreams(expectedStreams, actualStreams, evaluationTime, opts)
+	report := diffStreams(expectedStreams, actualStreams)
+	if report.Total == 0 {
+		return nil, nil
+	}
+	return report, nil
+}
+
+func unmarshalSamplesResponses(expectedResponse, actualResponse []byte) (*SamplesResponse, *SamplesResponse, error) {
 	var expected, actual SamplesResponse
 
 	err := json.Unmarshal(expectedResponse, &expected)
 	if err != nil {
-		return nil, errors.Wrap(err, "unable to unmarshal expected response")
+		return nil, nil, errors.Wrap(err, "unable to unmarshal expected response")
 	}
 
 	err = json.Unmarshal(actualResponse, &actual)
 	if err != nil {
-		return nil, errors.Wrap(err, "unable to unmarshal actual response")
+		return nil, nil, errors.Wrap(err, "unable to unmarshal actual response")
 	}
 
 	if expected.Status != actual.Status {
-		return nil, fmt.Errorf("expected status %s but got %s", expected.Status, actual.Status)
+		return nil, nil, fmt.Errorf("expected status %s but got %s", expected.Status, actual.Status)
 	}
 
 	if expected.Data.ResultType != actual.Data.ResultType {
-		return nil, fmt.Errorf("expected resultType %s but got %s", expected.Data.ResultType, actual.Data.ResultType)
+		return nil, nil, fmt.Errorf("expected resultType %s but got %s", expected.Data.ResultType, actual.Data.ResultType)
 	}
 
-	comparator, ok := s.sampleTypesComparator[expected.Data.ResultType]
-	if !ok {
-		return nil, fmt.Errorf("resultType %s not registered for comparison", expected.Data.ResultType)
-	}
+	return &expected, &actual, nil
+}
 
-	return comparator(expected.Data.Result, actual.Data.Result, evaluationTime, s.opts)
+// withQuery returns the options with the limit and direction of the query.
+func (opts SampleComparisonOptions) withQuery(params url.Values) SampleComparisonOptions {
+	opts.limit, _ = strconv.Atoi(params.Get("limit"))
+	opts.direction = logproto.BACKWARD
+	if strings.EqualFold(params.Get("direction"), logproto.FORWARD.String()) {
+		opts.direction = logproto.FORWARD
+	}
+	return opts
 }
 
 func compareMatrix(expectedRaw, actualRaw json.RawMessage, evaluationTime time.Time, opts SampleComparisonOptions) (*ComparisonSummary, error) {
@@ -335,18 +434,23 @@ func compareSampleValue(first, second model.SampleValue, opts SampleComparisonOp
 	return math.Abs(f-s) <= opts.Tolerance
 }
 
-func compareStreams(expectedRaw, actualRaw json.RawMessage, evaluationTime time.Time, opts SampleComparisonOptions) (*ComparisonSummary, error) {
+func unmarshalStreams(expectedRaw, actualRaw json.RawMessage) (loghttp.Streams, loghttp.Streams, error) {
 	var expected, actual loghttp.Streams
 
 	err := jsoniter.Unmarshal(expectedRaw, &expected)
 	if err != nil {
-		return nil, errors.Wrap(err, "unable to unmarshal expected streams")
+		return nil, nil, errors.Wrap(err, "unable to unmarshal expected streams")
 	}
 	err = jsoniter.Unmarshal(actualRaw, &actual)
 	if err != nil {
-		return nil, errors.Wrap(err, "unable to unmarshal actual streams")
+		return nil, nil, errors.Wrap(err, "unable to unmarshal actual streams")
 	}
+	return expected, actual, nil
+}
 
+// prepareStreams filters out the entries outside the comparable window, and
+// applies the comparison policies to the streams.
+func prepareStreams(expected, actual loghttp.Streams, evaluationTime time.Time, opts SampleComparisonOptions) (loghttp.Streams, loghttp.Streams) {
 	// Filter out entries outside the comparable window
 	if !opts.SkipSamplesBefore.IsZero() || opts.SkipRecentSamples > 0 {
 		expected = filterStreamsOutsideWindow(expected, func(entryTime time.Time) bool {
@@ -357,6 +461,30 @@ func compareStreams(expectedRaw, actualRaw json.RawMessage, evaluationTime time.
 		})
 	}
 
+	if !opts.StreamPolicies.enabled() {
+		return expected, actual
+	}
+
+	if opts.StreamPolicies.LimitBoundaryTolerance {
+		expected, actual = trimLimitBoundary(expected, actual, opts.limit, opts.direction)
+	}
+	if opts.StreamPolicies.NormalizeLabels {
+		expected, actual = normalizeStreamLabels(expected, opts.direction), normalizeStreamLabels(actual, opts.direction)
+	}
+	if opts.StreamPolicies.IgnoreOrderWithinTimestamp {
+		sortEntriesWithinTimestamp(expected)
+		sortEntriesWithinTimestamp(actual)
+	}
+	return expected, actual
+}
+
+func compareStreams(expectedRaw, actualRaw json.RawMessage, evaluationTime time.Time, opts SampleComparisonOptions) (*ComparisonSummary, error) {
+	expected, actual, err := unmarshalStreams(expectedRaw, actualRaw)
+	if err != nil {
+		return nil, err
+	}
+	expected, actual = prepareStreams(expected, actual, evaluationTime, opts)
+
 	// If both streams are empty after filtering, we can skip comparison
 	if len(expected) == 0 && len(actual) == 0 {
 		return &ComparisonSummary{skipped: true}, nil
@@ -431,3 +559,155 @@ func filterStreamsOutsideWindow(streams loghttp.Streams, skipEntry func(time.Tim
 
 	return result
 }
+
+// trimLimitBoundary removes the entries at the boundary timestamp of the
+// responses when both reached the limit of the query at the same timestamp.
+func trimLimitBoundary(expected, actual loghttp.Streams, limit int, direction logproto.Direction) (loghttp.Streams, loghttp.Streams) {
+	if limit <= 0 || countEntries(expected) != limit || countEntries(actual) != limit {
+		return expected, actual
+	}
+
+	boundary := limitBoundary(expected, direction)
+	if !boundary.Equal(limitBoundary(actual, direction)) {
+		return expected, actual
+	}
+
+	atBoundary := func(entryTime time.Time) bool {
+		return entryTime.Equal(boundary)
+	}
+	return filterStreamsOutsideWindow(expected, atBoundary), filterStreamsOutsideWindow(actual, atBoundary)
+}
+
+func countEntries(streams loghttp.Streams) int {
+	count := 0
+	for _, stream := range streams {
+		count += len(stream.Entries)
+	}
+	return count
+}
+
+// limitBoundary returns the oldest timestamp of the streams for backward
+// queries, and the newest one for forward queries.
+func limitBoundary(streams loghttp.Streams, direction logproto.Direction) time.Time {
+	var boundary time.Time
+	for _, stream := range streams {
+		for _, entry := range stream.Entries {
+			if boundary.IsZero() ||
+				(direction == logproto.BACKWARD && entry.Timestamp.Before(boundary)) ||
+				(direction == logproto.FORWARD && entry.Timestamp.After(boundary)) {
+				boundary = entry.Timestamp
+			}
+		}
+	}
+	return boundary
+}
+
+// normalizeStreamLabels groups the entries by their stream labels merged with
+// their structured metadata and parsed labels.
+func normalizeStreamLabels(streams loghttp.Streams, direction logproto.Direction) loghttp.Streams {
+	var (
+		result      loghttp.Streams
+		streamIndex = map[string]int{}
+	)
+	for _, stream := range streams {
+		for _, entry := range stream.Entries {
+			lbls := make(loghttp.LabelSet, len(stream.Labels)+entry.StructuredMetadata.Len()+entry.Parsed.Len())
+			for name, value := range stream.Labels {
+				lbls[name] = value
+			}
+			entry.StructuredMetadata.Range(func(l labels.Label) {
+				lbls[l.Name] = l.Value
+			})
+			entry.Parsed.Range(func(l labels.Label) {
+				lbls[l.Name] = l.Value
+			})
+			entry.StructuredMetadata, entry.Parsed = labels.EmptyLabels(), labels.EmptyLabels()
+
+			key := lbls.String()
+			idx, ok := streamIndex[key]
+			if !ok {
+				idx = len(result)
+				streamIndex[key] = idx
+				result = append(result, loghttp.Stream{Labels: lbls})
+			}
+			result[idx].Entries = append(result[idx].Entries, entry)
+		}
+	}
+
+	// Entries of different streams can have been grouped together.
+	for _, stream := range result {
+		sort.SliceStable(stream.Entries, func(i, j int) bool {
+			if direction == logproto.FORWARD {
+				return stream.Entries[i].Timestamp.Before(stream.Entries[j].Timestamp)
+			}
+			return stream.Entries[i].Timestamp.After(stream.Entries[j].Timestamp)
+		})
+	}
+	return result
+}
+
+// sortEntriesWithinTimestamp sorts the entries with the same timestamp of the
+// streams by line.
+func sortEntriesWithinTimestamp(streams loghttp.Streams) {
+	for _, stream := range streams {
+		for start := 0; start < len(stream.Entries); {
+			end := start + 1
+			for end < len(stream.Entries) && stream.Entries[end].Timestamp.Equal(stream.Entries[start].Timestamp) {
+				end++
+			}
+			run := stream.Entries[start:end]
+			sort.SliceStable(run, func(i, j int) bool {
+				return run[i].Line < run[j].Line
+			})
+			start = end
+		}
+	}
+}
+
+// diffStreams lists the differences between the streams.
+func diffStreams(expected, actual loghttp.Streams) *goldfish.DiffReport {
+	report := &goldfish.DiffReport{}
+
+	actualStreams := make(map[string]loghttp.Stream, len(actual))
+	for _, stream := range actual {
+		actualStreams[stream.Labels.String()] = stream
+	}
+
+	expectedLabels := make(map[string]struct{}, len(expected))
+	for _, expectedStream := range expected {
+		lbls := expectedStream.Labels.String()
+		expectedLabels[lbls] = struct{}{}
+
+		actualStream, ok := actualStreams[lbls]
+		if !ok {
+			report.Add(goldfish.FieldDiff{Series: lbls, Field: "stream", CellA: len(expectedStream.Entries), Message: "stream missing from cell B"})
+			continue
+		}
+
+		if len(expectedStream.Entries) != len(actualStream.Entries) {
+			report.Add(goldfish.FieldDiff{Series: lbls, Field: "entries", CellA: len(expectedStream.Entries), CellB: len(actualStream.Entries)})
+		}
+		for i := 0; i < min(len(expectedStream.Entries), len(actualStream.Entries)); i++ {
+			expectedEntry, actualEntry := expectedStream.Entries[i], actualStream.Entries[i]
+			if !expectedEntry.Timestamp.Equal(actualEntry.Timestamp) {
+				report.Add(goldfish.FieldDiff{Series: lbls, Field: "timestamp", Timestamp: expectedEntry.Timestamp.UnixNano(), CellA: expectedEntry.Timestamp.UnixNano(), CellB: actualEntry.Timestamp.UnixNano()})
+				continue
+			}
+			if expectedEntry.Line != actualEntry.Line {
+				report.Add(goldfish.FieldDiff{Series: lbls, Field: "line", Timestamp: expectedEntry.Timestamp.UnixNano(), CellA: expectedEntry.Line, CellB: actualEntry.Line})
+			}
+		}
+	}
+
+	for _, actualStream := range actual {
+		lbls := actualStream.Labels.String()
+		if _, ok := expectedLabels[lbls]; !ok {
+			report.Add(goldfish.FieldDiff{Series: lbls, Field: "stream", CellB: len(actualStream.Entries), Message: "stream missing from cell A"})
+		}
+	}
+
+	if report.Total > 0 {
+		report.Summary = fmt.Sprintf("%d differences between %d streams of cell A and %d streams of cell B", report.Total, len(expected), len(actual))
+	}
+	return report
+}
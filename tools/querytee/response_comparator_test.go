@@ -3,11 +3,15 @@ package querytee
 import (
 	"encoding/json"
 	"errors"
+	"net/http/httptest"
+	"net/url"
 	"testing"
 	"time"
 
 	"github.com/prometheus/common/model"
 	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/v3/tools/querytee/goldfish"
 )
 
 func TestCompareMatrix(t *testing.T) {
@@ -702,3 +706,130 @@ func TestCompareStreams_SamplesOutsideComparableWindow(t *testing.T) {
 		})
 	}
 }
+
+func TestCompareStreams_Policies(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		policies StreamComparisonPolicies
+		params   url.Values
+		expected string
+		actual   string
+		err      error
+	}{
+		{
+			name:     "different order within timestamp",
+			expected: `[{"stream":{"foo":"bar"},"values":[["2","b"],["2","a"],["1","c"]]}]`,
+			actual:   `[{"stream":{"foo":"bar"},"values":[["2","a"],["2","b"],["1","c"]]}]`,
+			err:      errors.New("expected line b for timestamp 2 but got a for stream {foo=\"bar\"}"),
+		},
+		{
+			name:     "different order within timestamp ignored",
+			policies: StreamComparisonPolicies{IgnoreOrderWithinTimestamp: true},
+			expected: `[{"stream":{"foo":"bar"},"values":[["2","b"],["2","a"],["1","c"]]}]`,
+			actual:   `[{"stream":{"foo":"bar"},"values":[["2","a"],["2","b"],["1","c"]]}]`,
+		},
+		{
+			name:     "different order across timestamps",
+			policies: StreamComparisonPolicies{IgnoreOrderWithinTimestamp: true},
+			expected: `[{"stream":{"foo":"bar"},"values":[["2","b"],["1","a"]]}]`,
+			actual:   `[{"stream":{"foo":"bar"},"values":[["1","a"],["2","b"]]}]`,
+			err:      errors.New("expected timestamp 2 but got 1 for stream {foo=\"bar\"}"),
+		},
+		{
+			name:     "different entries at limit boundary tolerated",
+			policies: StreamComparisonPolicies{LimitBoundaryTolerance: true},
+			params:   url.Values{"limit": []string{"3"}},
+			expected: `[{"stream":{"foo":"bar"},"values":[["3","c"],["2","b"],["1","a"]]}]`,
+			actual:   `[{"stream":{"foo":"bar"},"values":[["3","c"],["2","b"]]},{"stream":{"foo":"baz"},"values":[["1","x"]]}]`,
+		},
+		{
+			name:     "different entries at limit boundary of forward query tolerated",
+			policies: StreamComparisonPolicies{LimitBoundaryTolerance: true},
+			params:   url.Values{"limit": []string{"2"}, "direction": []string{"forward"}},
+			expected: `[{"stream":{"foo":"bar"},"values":[["1","a"],["2","b"]]}]`,
+			actual:   `[{"stream":{"foo":"bar"},"values":[["1","a"],["2","c"]]}]`,
+		},
+		{
+			name:     "limit not reached",
+			policies: StreamComparisonPolicies{LimitBoundaryTolerance: true},
+			params:   url.Values{"limit": []string{"100"}},
+			expected: `[{"stream":{"foo":"bar"},"values":[["2","b"],["1","a"]]}]`,
+			actual:   `[{"stream":{"foo":"bar"},"values":[["2","b"],["1","c"]]}]`,
+			err:      errors.New("expected line a for timestamp 1 but got c for stream {foo=\"bar\"}"),
+		},
+		{
+			name:     "different boundaries at limit",
+			policies: StreamComparisonPolicies{LimitBoundaryTolerance: true},
+			params:   url.Values{"limit": []string{"2"}},
+			expected: `[{"stream":{"foo":"bar"},"values":[["3","c"],["2","b"]]}]`,
+			actual:   `[{"stream":{"foo":"bar"},"values":[["3","c"],["1","a"]]}]`,
+			err:      errors.New("expected timestamp 2 but got 1 for stream {foo=\"bar\"}"),
+		},
+		{
+			name:     "categorized labels",
+			expected: `[{"stream":{"foo":"bar","trace_id":"1"},"values":[["2","b"]]},{"stream":{"foo":"bar","trace_id":"2"},"values":[["1","a"]]}]`,
+			actual:   `[{"stream":{"foo":"bar"},"values":[["2","b",{"structuredMetadata":{"trace_id":"1"}}],["1","a",{"structuredMetadata":{"trace_id":"2"}}]]}]`,
+			err:      errors.New("expected 2 streams but got 1"),
+		},
+		{
+			name:     "categorized labels normalized",
+			policies: StreamComparisonPolicies{NormalizeLabels: true},
+			expected: `[{"stream":{"foo":"bar","trace_id":"1"},"values":[["2","b"]]},{"stream":{"foo":"bar","trace_id":"2","level":"info"},"values":[["1","a"]]}]`,
+			actual:   `[{"stream":{"foo":"bar"},"values":[["2","b",{"structuredMetadata":{"trace_id":"1"}}],["1","a",{"structuredMetadata":{"trace_id":"2"},"parsed":{"level":"info"}}]]}]`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			comparator := NewSamplesComparator(SampleComparisonOptions{StreamPolicies: tc.policies})
+			_, err := comparator.CompareQuery(tc.params,
+				[]byte(`{"status":"success","data":{"resultType":"streams","result":`+tc.expected+`}}`),
+				[]byte(`{"status":"success","data":{"resultType":"streams","result":`+tc.actual+`}}`),
+				time.Now())
+			if tc.err == nil {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			require.Equal(t, tc.err.Error(), err.Error())
+		})
+	}
+}
+
+func TestSamplesComparator_DiffResults(t *testing.T) {
+	comparator := NewSamplesComparator(SampleComparisonOptions{
+		StreamPolicies: StreamComparisonPolicies{IgnoreOrderWithinTimestamp: true},
+	})
+	req := httptest.NewRequest("GET", "/loki/api/v1/query_range?query={foo=\"bar\"}", nil)
+
+	// Tolerated differences.
+	report, err := comparator.DiffResults(req,
+		[]byte(`{"status":"success","data":{"resultType":"streams","result":[{"stream":{"foo":"bar"},"values":[["1","a"],["1","b"]]}]}}`),
+		[]byte(`{"status":"success","data":{"resultType":"streams","result":[{"stream":{"foo":"bar"},"values":[["1","b"],["1","a"]]}]}}`),
+		time.Now())
+	require.NoError(t, err)
+	require.Nil(t, report)
+
+	report, err = comparator.DiffResults(req,
+		[]byte(`{"status":"success","data":{"resultType":"streams","result":[{"stream":{"foo":"bar"},"values":[["2","b"],["1","a"]]},{"stream":{"foo":"baz"},"values":[["1","a"]]}]}}`),
+		[]byte(`{"status":"success","data":{"resultType":"streams","result":[{"stream":{"foo":"bar"},"values":[["2","c"]]},{"stream":{"foo":"qux"},"values":[["1","a"]]}]}}`),
+		time.Now())
+	require.NoError(t, err)
+	require.Equal(t, &goldfish.DiffReport{
+		Summary: "4 differences between 2 streams of cell A and 2 streams of cell B",
+		Total:   4,
+		Differences: []goldfish.FieldDiff{
+			{Series: `{foo="bar"}`, Field: "entries", CellA: 2, CellB: 1},
+			{Series: `{foo="bar"}`, Field: "line", Timestamp: 2, CellA: "b", CellB: "c"},
+			{Series: `{foo="baz"}`, Field: "stream", CellA: 1, Message: "stream missing from cell B"},
+			{Series: `{foo="qux"}`, Field: "stream", CellB: 1, Message: "stream missing from cell A"},
+		},
+	}, report)
+
+	// Other result types report the first difference.
+	report, err = comparator.DiffResults(req,
+		[]byte(`{"status":"success","data":{"resultType":"scalar","result":[1,"1"]}}`),
+		[]byte(`{"status":"success","data":{"resultType":"scalar","result":[1,"2"]}}`),
+		time.Now())
+	require.NoError(t, err)
+	require.Equal(t, 1, report.Total)
+	require.Equal(t, "expected value 1 for timestamp 1 but got 2", report.Differences[0].Message)
+}